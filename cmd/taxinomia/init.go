@@ -0,0 +1,79 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"embed"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// scaffold holds the starter configuration and the sample CSVs it describes
+//
+//go:embed scaffold
+var scaffold embed.FS
+
+// runInit writes the starter configuration to a directory.
+func runInit(args []string) error {
+	flags := flag.NewFlagSet("init", flag.ExitOnError)
+	dir := flags.String("dir", ".", "directory to write the starter configuration to")
+	force := flags.Bool("force", false, "overwrite existing files")
+	flags.Parse(args)
+
+	return writeScaffold(*dir, *force, os.Stdout)
+}
+
+// writeScaffold writes the scaffold files to dir, creating it if needed. Unless force
+// is set, nothing is written if any of the files already exists.
+func writeScaffold(dir string, force bool, out io.Writer) error {
+	entries, err := fs.ReadDir(scaffold, "scaffold")
+	if err != nil {
+		return err
+	}
+	if !force {
+		for _, e := range entries {
+			path := filepath.Join(dir, e.Name())
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists; use --force to overwrite it", path)
+			}
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		data, err := scaffold.ReadFile("scaffold/" + e.Name())
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, e.Name())
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Wrote %s\n", path)
+	}
+	fmt.Fprintf(out, "\nEdit the config to point at your CSV files, then run:\n  taxinomia serve --config=%s\n",
+		filepath.Join(dir, configFileName))
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command taxinomia serves the tables described by a data sources configuration, or the
+// demo, from a single binary embedding the templates, the demo data and a starter
+// configuration.
+//
+// Usage:
+//
+//	taxinomia init [--dir=DIR] [--force]
+//	taxinomia samples [--dir=DIR] [--force] [--list] [NAME...]
+//	taxinomia serve [--config=FILE] [--addr=HOST:PORT]
+//	taxinomia demo [--addr=HOST:PORT]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/google/taxinomia/core/server"
+	"github.com/google/taxinomia/datasources"
+	"github.com/google/taxinomia/demo"
+)
+
+const defaultAddress = "127.0.0.1:8097"
+
+const usage = `Usage:
+  taxinomia init [--dir=DIR] [--force]           Write a starter data_sources.textproto and sample CSVs
+  taxinomia samples [--dir=DIR] [--list] [NAME]  Download public sample datasets and a config serving them
+  taxinomia serve [--config=FILE] [--addr=ADDR]  Serve the tables of a data sources configuration
+  taxinomia demo [--addr=ADDR]                   Serve the demo tables
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "init":
+		err = runInit(os.Args[2:])
+	case "samples":
+		err = runSamples(os.Args[2:])
+	case "serve":
+		err = runServe(os.Args[2:])
+	case "demo":
+		err = runDemo(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runDemo serves the demo, reading its files from the embedded copies when the binary
+// runs away from the source tree.
+func runDemo(args []string) error {
+	flags := flag.NewFlagSet("demo", flag.ExitOnError)
+	addr := flags.String("addr", defaultAddress, "address to listen on")
+	flags.Parse(args)
+
+	readFile, readDir := demo.EmbeddedReaders(os.ReadFile, readDir)
+	srv, products, err := demo.SetupDemoServer(readFile, readDir)
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
+	}
+	return listen(*addr, srv, products)
+}
+
+// listen serves the products on addr until the server fails.
+func listen(addr string, srv *server.Server, products *demo.ProductRegistry) error {
+	fmt.Printf("\nServer starting on http://%s\n", addr)
+	fmt.Printf("Products available:\n")
+	for _, p := range products.GetAll() {
+		fmt.Printf("  - /%s/\n", p.Name)
+	}
+	return http.ListenAndServe(addr, srv.Handler("default", products.Lookup))
+}
+
+// readDir lists a directory of the local filesystem.
+func readDir(path string) ([]datasources.DirEntry, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]datasources.DirEntry, len(entries))
+	for i, e := range entries {
+		result[i] = datasources.DirEntry{Name: e.Name(), IsDir: e.IsDir()}
+	}
+	return result, nil
+}
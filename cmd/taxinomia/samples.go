@@ -0,0 +1,284 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// samplesConfigFileName is the configuration listing the downloaded sample datasets
+const samplesConfigFileName = "samples.textproto"
+
+// sampleDownloadTimeout bounds the download of a single dataset
+const sampleDownloadTimeout = 5 * time.Minute
+
+// sampleDataset is a public dataset that samples downloads into a CSV file and
+// registers in the samples configuration
+type sampleDataset struct {
+	Name        string // Table name, also the name of the CSV file
+	Description string
+	URL         string
+	SHA256      string // Hex SHA-256 of the file at URL, as served (before decompression)
+	Gzip        bool   // The file at URL is gzip-compressed
+	Header      string // Header line prepended to files without one (empty = the file has one)
+	Config      string // Annotations and source blocks of the table
+}
+
+// sampleDatasets lists the datasets samples can download, in the order they are listed
+var sampleDatasets = []sampleDataset{
+	{
+		Name:        "world_cities",
+		Description: "Cities of the world with more than 15,000 inhabitants, from GeoNames",
+		URL:         "https://raw.githubusercontent.com/datasets/world-cities/master/data/world-cities.csv",
+		Config: `annotations {
+  annotations_id: "sample.world_cities"
+  columns { name: "name"       display_name: "City" }
+  columns { name: "country"    display_name: "Country"    entity_type: "sample.country" }
+  columns { name: "subcountry" display_name: "Subdivision" }
+  columns { name: "geonameid"  display_name: "GeoNames ID" entity_type: "sample.city" }
+}
+
+sources {
+  name: "world_cities"
+  annotations_id: "sample.world_cities"
+  domains: "samples"
+  source_type: "csv_typed"
+  config { key: "file_path"  value: "world_cities.csv" }
+  config { key: "has_header" value: "true" }
+  primary_key_entity_type: "sample.city"
+  readme: "# World Cities\n\nCities of the world with more than 15,000 inhabitants, from [GeoNames](https://www.geonames.org/) via [datasets/world-cities](https://github.com/datasets/world-cities).\n"
+}
+`,
+	},
+	{
+		Name:        "borg_jobs",
+		Description: "Job events of the first part of the 2011 Google cluster trace (Borg)",
+		URL:         "https://commondatastorage.googleapis.com/clusterdata-2011-2/job_events/part-00000-of-00500.csv.gz",
+		Gzip:        true,
+		Header:      "time,missing_info,job_id,event_type,user,scheduling_class,job_name,logical_job_name",
+		Config: `annotations {
+  annotations_id: "sample.borg_jobs"
+  columns { name: "time"             display_name: "Time (µs)" }
+  columns { name: "missing_info"     display_name: "Missing Info" }
+  columns { name: "job_id"           display_name: "Job ID" entity_type: "sample.borg_job" }
+  columns {
+    name: "event_type"
+    display_name: "Event"
+    value_labels { key: "0" value: "SUBMIT" }
+    value_labels { key: "1" value: "SCHEDULE" }
+    value_labels { key: "2" value: "EVICT" }
+    value_labels { key: "3" value: "FAIL" }
+    value_labels { key: "4" value: "FINISH" }
+    value_labels { key: "5" value: "KILL" }
+    value_labels { key: "6" value: "LOST" }
+    value_labels { key: "7" value: "UPDATE_PENDING" }
+    value_labels { key: "8" value: "UPDATE_RUNNING" }
+  }
+  columns { name: "user"             display_name: "User" entity_type: "sample.borg_user" }
+  columns { name: "scheduling_class" display_name: "Scheduling Class" }
+  columns { name: "job_name"         display_name: "Job Name" }
+  columns { name: "logical_job_name" display_name: "Logical Job Name" }
+}
+
+sources {
+  name: "borg_jobs"
+  annotations_id: "sample.borg_jobs"
+  domains: "samples"
+  source_type: "csv_typed"
+  config { key: "file_path"  value: "borg_jobs.csv" }
+  config { key: "has_header" value: "true" }
+  readme: "# Borg Job Events\n\nOne row per job event of the [2011 Google cluster trace](https://github.com/google/cluster-data/blob/master/ClusterData2011_2.md). Times are microseconds since the start of the trace; user and job names are obfuscated.\n"
+}
+`,
+	},
+	{
+		Name:        "borg_machines",
+		Description: "Machine events of the 2011 Google cluster trace (Borg)",
+		URL:         "https://commondatastorage.googleapis.com/clusterdata-2011-2/machine_events/part-00000-of-00001.csv.gz",
+		Gzip:        true,
+		Header:      "time,machine_id,event_type,platform_id,cpus,memory",
+		Config: `annotations {
+  annotations_id: "sample.borg_machines"
+  columns { name: "time"        display_name: "Time (µs)" }
+  columns { name: "machine_id"  display_name: "Machine ID" entity_type: "sample.borg_machine" }
+  columns {
+    name: "event_type"
+    display_name: "Event"
+    value_labels { key: "0" value: "ADD" }
+    value_labels { key: "1" value: "REMOVE" }
+    value_labels { key: "2" value: "UPDATE" }
+  }
+  columns { name: "platform_id" display_name: "Platform" }
+  columns { name: "cpus"        display_name: "CPUs (normalized)" }
+  columns { name: "memory"      display_name: "Memory (normalized)" }
+}
+
+sources {
+  name: "borg_machines"
+  annotations_id: "sample.borg_machines"
+  domains: "samples"
+  source_type: "csv_typed"
+  config { key: "file_path"  value: "borg_machines.csv" }
+  config { key: "has_header" value: "true" }
+  readme: "# Borg Machine Events\n\nMachines added to, removed from and updated in the cell of the [2011 Google cluster trace](https://github.com/google/cluster-data/blob/master/ClusterData2011_2.md). CPU and memory capacities are normalized to the largest machine.\n"
+}
+`,
+	},
+}
+
+// runSamples downloads public sample datasets and registers them in a configuration.
+func runSamples(args []string) error {
+	flags := flag.NewFlagSet("samples", flag.ExitOnError)
+	dir := flags.String("dir", "samples", "directory to download the datasets to")
+	force := flags.Bool("force", false, "download datasets that are already present again")
+	list := flags.Bool("list", false, "list the available datasets without downloading")
+	flags.Parse(args)
+
+	if *list {
+		for _, d := range sampleDatasets {
+			fmt.Printf("  %-15s %s\n", d.Name, d.Description)
+		}
+		return nil
+	}
+	return downloadSamples(&http.Client{Timeout: sampleDownloadTimeout}, *dir, flags.Args(), *force, os.Stdout)
+}
+
+// downloadSamples downloads the named datasets (all of them if names is empty) to dir,
+// skipping the ones already present unless force is set, then writes the samples
+// configuration listing every dataset present in dir.
+func downloadSamples(client *http.Client, dir string, names []string, force bool, out io.Writer) error {
+	selected := sampleDatasets
+	if len(names) > 0 {
+		selected = nil
+		for _, name := range names {
+			d, ok := findSampleDataset(name)
+			if !ok {
+				return fmt.Errorf("unknown sample dataset %q; run `taxinomia samples --list` to list them", name)
+			}
+			selected = append(selected, d)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, d := range selected {
+		path := filepath.Join(dir, d.Name+".csv")
+		if _, err := os.Stat(path); err == nil && !force {
+			fmt.Fprintf(out, "%s already downloaded\n", path)
+			continue
+		}
+		fmt.Fprintf(out, "Downloading %s from %s\n", d.Name, d.URL)
+		if err := downloadSample(client, d, path); err != nil {
+			return fmt.Errorf("failed to download %s: %w", d.Name, err)
+		}
+	}
+
+	var config strings.Builder
+	config.WriteString("# SPDX-License-Identifier: Apache-2.0\n# Sample datasets downloaded by `taxinomia samples`, rewritten on every run.\n")
+	for _, d := range sampleDatasets {
+		if _, err := os.Stat(filepath.Join(dir, d.Name+".csv")); err == nil {
+			config.WriteString("\n# " + d.Description + "\n" + d.Config)
+		}
+	}
+	configPath := filepath.Join(dir, samplesConfigFileName)
+	if err := os.WriteFile(configPath, []byte(config.String()), 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Wrote %s\n\nServe the samples with:\n  taxinomia serve --config=%s\n", configPath, configPath)
+	return nil
+}
+
+// findSampleDataset returns the sample dataset with the given name
+func findSampleDataset(name string) (sampleDataset, bool) {
+	for _, d := range sampleDatasets {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return sampleDataset{}, false
+}
+
+// downloadSample downloads a dataset to path as a CSV file with a header line. The file
+// is written under a temporary name first so that a failed download, or one that does not
+// match the pinned checksum, leaves no file.
+func downloadSample(client *http.Client, d sampleDataset, path string) error {
+	resp, err := client.Get(d.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", d.URL, resp.Status)
+	}
+
+	hash := sha256.New()
+	download := io.TeeReader(resp.Body, hash)
+	var body io.Reader = download
+	if d.Gzip {
+		gz, err := gzip.NewReader(download)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+d.Name+"-*.csv")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if d.Header != "" {
+		if _, err := io.WriteString(tmp, d.Header+"\n"); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// Hash whatever follows the end of the gzip stream too
+	if _, err := io.Copy(io.Discard, download); err != nil {
+		return err
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if d.SHA256 == "" {
+		return fmt.Errorf("no SHA-256 is pinned for %s (the downloaded file has %s)", d.URL, sum)
+	}
+	if sum != d.SHA256 {
+		return fmt.Errorf("SHA-256 mismatch for %s: got %s, want %s", d.URL, sum, d.SHA256)
+	}
+	return os.Rename(tmp.Name(), path)
+}
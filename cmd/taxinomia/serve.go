@@ -0,0 +1,170 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/google/taxinomia/core/models"
+	"github.com/google/taxinomia/core/server"
+	"github.com/google/taxinomia/core/views"
+	"github.com/google/taxinomia/datasources"
+	"github.com/google/taxinomia/demo"
+)
+
+// configFileName is the name of the configuration written by init and read by serve
+const configFileName = "data_sources.textproto"
+
+// runServe serves the tables of a data sources configuration.
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	config := flags.String("config", configFileName, "data sources configuration to serve")
+	addr := flags.String("addr", defaultAddress, "address to listen on")
+	locale := flags.String("locale", "", "locale of the pages, e.g. he or ar for right-to-left layouts")
+	strict := flags.Bool("strict-urls", false, "reject table URLs with unknown parameters, e.g. a misspelled filter")
+	flags.Parse(args)
+
+	srv, products, err := setupConfigServer(*config, os.ReadFile, openFile)
+	if err != nil {
+		return err
+	}
+	srv.SetDefaultLocale(*locale)
+	srv.SetStrictURLs(*strict)
+	reloadOnHangup(srv)
+	return listen(*addr, srv, products)
+}
+
+// reloadOnHangup reloads the tables of srv from their sources whenever the process
+// receives SIGHUP, e.g. after the files of the configuration were regenerated.
+func reloadOnHangup(srv *server.Server) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	go func() {
+		for range hangups {
+			if err := srv.ReloadTables(); err != nil {
+				log.Printf("Reload: %v", err)
+				continue
+			}
+			log.Printf("Reloaded all tables")
+		}
+	}()
+}
+
+// openFile opens a file of a data source for streaming. Implements datasources.FileOpener.
+func openFile(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
+// setupConfigServer loads every source of the data sources configuration at configPath
+// and creates a server with a single product listing them. Husk sources are registered
+// with their schema and row count, and queried at their source through the SQL endpoint,
+// streaming their files with openFile. Sources that fail to load are logged and skipped;
+// it fails if none loads.
+func setupConfigServer(configPath string, readFile datasources.FileReader, openFile datasources.FileOpener) (*server.Server, *demo.ProductRegistry, error) {
+	configData, err := readFile(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	dsManager := datasources.NewManager()
+	dsManager.RegisterLoader(datasources.NewCsvLoader())
+	dsManager.RegisterLoader(datasources.NewCsvLoaderTyped())
+	dsManager.RegisterLoader(datasources.NewProtoLoader())
+	dsManager.SetFileReader(readFile)
+	dsManager.SetFileOpener(openFile)
+	if err := dsManager.LoadConfigFromBytes(configData, filepath.Dir(configPath)); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config %s: %w", configPath, err)
+	}
+
+	dataModel := models.NewDataModel()
+	names := dsManager.GetSourceNames()
+	sort.Strings(names)
+	var tableInfos []views.TableInfo
+	for _, name := range names {
+		source := dsManager.GetSource(name)
+		if source.GetHusk() {
+			// Husks keep their rows at the source and are queried with SQL
+			husk, err := dsManager.GetHusk(name)
+			if err != nil {
+				log.Printf("Skipping husk source %s: %v", name, err)
+				continue
+			}
+			tableInfos = append(tableInfos, views.TableInfo{
+				Name:        name,
+				Description: "Husk of a " + source.GetSourceType() + " source, queried with SQL at the source",
+				URL:         "sql?q=" + url.QueryEscape("SELECT * FROM "+name+" LIMIT 100"),
+				RecordCount: husk.RowCount,
+				ColumnCount: len(husk.Columns),
+				Categories:  strings.Join(source.GetDomains(), ", "),
+			})
+			fmt.Printf("Registered husk %s with %d rows\n", name, husk.RowCount)
+			continue
+		}
+		table, err := dsManager.LoadData(name)
+		if err != nil {
+			log.Printf("Skipping source %s: %v", name, err)
+			continue
+		}
+		dataModel.AddTable(name, table)
+		tableInfos = append(tableInfos, views.TableInfo{
+			Name:        name,
+			Description: "Loaded from a " + source.GetSourceType() + " source",
+			URL:         "table?table=" + name,
+			RecordCount: table.Length(),
+			ColumnCount: len(table.GetColumnNames()),
+			Categories:  strings.Join(source.GetDomains(), ", "),
+		})
+		fmt.Printf("Loaded %s with %d rows\n", name, table.Length())
+	}
+	if len(tableInfos) == 0 {
+		return nil, nil, fmt.Errorf("no source of %s could be loaded", configPath)
+	}
+	models.AddSystemTables(dataModel)
+
+	srv, err := server.NewServer(dataModel)
+	if err != nil {
+		return nil, nil, err
+	}
+	demo.SetupDataSourceResolvers(srv, dataModel, dsManager)
+	srv.SetTableReloader(dsManager.Reload)
+
+	var hotGroupings []server.HotGrouping
+	for _, name := range dsManager.GetLoadedSources() {
+		for _, hot := range dsManager.GetSource(name).GetHotGroupings() {
+			hotGroupings = append(hotGroupings, server.HotGrouping{Table: name, Columns: hot.GetColumns(), Priority: int(hot.GetPriority())})
+		}
+	}
+	srv.SetHotGroupings(hotGroupings)
+	srv.StartCacheJanitor(server.DefaultCacheIdleWindow)
+
+	products := demo.NewProductRegistry()
+	products.SetTables(tableInfos)
+	products.Register(&demo.Product{Name: "default", Title: "Taxinomia", Subtitle: configPath})
+	return srv, products, nil
+}
@@ -0,0 +1,306 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInitThenServe(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "starter")
+	if err := writeScaffold(dir, false, io.Discard); err != nil {
+		t.Fatalf("writeScaffold: %v", err)
+	}
+	if err := writeScaffold(dir, false, io.Discard); err == nil {
+		t.Error("writeScaffold overwrote existing files without force")
+	}
+	if err := writeScaffold(dir, true, io.Discard); err != nil {
+		t.Errorf("writeScaffold with force: %v", err)
+	}
+
+	srv, products, err := setupConfigServer(filepath.Join(dir, configFileName), os.ReadFile, openFile)
+	if err != nil {
+		t.Fatalf("setupConfigServer: %v", err)
+	}
+	handler := srv.Handler("default", products.Lookup)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	if rec := get("/"); rec.Code != http.StatusFound || rec.Header().Get("Location") != "/default/" {
+		t.Errorf("GET / = %d to %q, want a redirect to /default/", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := get("/default/"); !strings.Contains(rec.Body.String(), "table?table=projects") {
+		t.Errorf("landing page does not list the projects table:\n%s", rec.Body.String())
+	}
+
+	// Projects join their team through the starter.team entity type
+	rec := get("/default/table?table=projects&columns=project,budget,team.teams.team.site")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET projects = %d: %s", rec.Code, rec.Body.String())
+	}
+	for _, want := range []string{"atlas", "120000", "Tokyo"} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("projects table does not contain %q", want)
+		}
+	}
+}
+
+func TestServeReloadsTables(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "starter")
+	if err := writeScaffold(dir, false, io.Discard); err != nil {
+		t.Fatalf("writeScaffold: %v", err)
+	}
+	srv, products, err := setupConfigServer(filepath.Join(dir, configFileName), os.ReadFile, openFile)
+	if err != nil {
+		t.Fatalf("setupConfigServer: %v", err)
+	}
+	handler := srv.Handler("default", products.Lookup)
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d: %s", path, rec.Code, rec.Body.String())
+		}
+		return rec.Body.String()
+	}
+
+	path := "/default/table?table=projects&columns=project,budget,team.teams.team.site"
+	if body := get(path); strings.Contains(body, "Lisbon") {
+		t.Fatal("teams table contains Lisbon before the reload")
+	}
+	teams := "team,lead,site\nsearch,Ana,Lisbon\ninfra,Ben,Dublin\nmobile,Chen,Tokyo\n"
+	if err := os.WriteFile(filepath.Join(dir, "teams.csv"), []byte(teams), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.ReloadTables(); err != nil {
+		t.Fatalf("ReloadTables: %v", err)
+	}
+	// The cached view recomputes the joined column of the changed table
+	if body := get(path); !strings.Contains(body, "Lisbon") {
+		t.Error("joined column does not show the reloaded site")
+	}
+}
+
+func TestServeSetsUpDataSourceResolvers(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "starter")
+	if err := writeScaffold(dir, false, io.Discard); err != nil {
+		t.Fatalf("writeScaffold: %v", err)
+	}
+	// Team names of the teams table differ in case, which only fuzzy joins match, and
+	// the versions of the teams table are retained
+	configPath := filepath.Join(dir, configFileName)
+	config, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = bytes.Replace(config, []byte(`primary_key_entity_type: "starter.team"`), []byte(`primary_key_entity_type: "starter.team" retention { max_versions: 2 }`), 1)
+	config = append(config, "entity_types { name: \"starter.team\" fuzzy_join {} }\n"...)
+	teams := "team,lead,site\nSEARCH,Ana,Zurich\nInfra,Ben,Dublin\nmobile,Chen,Tokyo\n"
+	for name, content := range map[string]string{configFileName: string(config), "teams.csv": teams} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv, products, err := setupConfigServer(configPath, os.ReadFile, openFile)
+	if err != nil {
+		t.Fatalf("setupConfigServer: %v", err)
+	}
+	handler := srv.Handler("default", products.Lookup)
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d: %s", path, rec.Code, rec.Body.String())
+		}
+		return rec.Body.String()
+	}
+
+	path := "/default/table?table=teams&columns=team,site"
+	if body := get("/default/table?table=projects&columns=project,team,team.teams.team.site"); !strings.Contains(body, "Zurich") {
+		t.Error("fuzzy join does not match the teams of another case")
+	}
+
+	// Views as of the first load show the data before the reload
+	firstLoad := time.Now()
+	if err := os.WriteFile(filepath.Join(dir, "teams.csv"), []byte(strings.Replace(teams, "Zurich", "Lisbon", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.ReloadTables(); err != nil {
+		t.Fatalf("ReloadTables: %v", err)
+	}
+	if body := get(path); !strings.Contains(body, "Lisbon") {
+		t.Error("current view does not show the reloaded site")
+	}
+	if body := get(path + "&asof=" + url.QueryEscape(firstLoad.Format(time.RFC3339Nano))); !strings.Contains(body, "Zurich") || strings.Contains(body, "Lisbon") {
+		t.Error("view as of the first load does not show the site before the reload")
+	}
+}
+
+func TestServeQueriesHusks(t *testing.T) {
+	dir := t.TempDir()
+	config := `sources { name: "orders" source_type: "csv_typed" config { key: "file_path" value: "orders.csv" } }
+sources { name: "events" source_type: "csv_typed" husk: true annotations_id: "events" config { key: "file_path" value: "events.csv" } }
+annotations { annotations_id: "events" columns { name: "kind" low_cardinality: true } }`
+	files := map[string]string{
+		configFileName: config,
+		"orders.csv":   "order_id,amount\no1,100\n",
+		"events.csv":   "kind,latency\nread,10\nwrite,30\nread,20\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv, products, err := setupConfigServer(filepath.Join(dir, configFileName), os.ReadFile, openFile)
+	if err != nil {
+		t.Fatalf("setupConfigServer: %v", err)
+	}
+	handler := srv.Handler("default", products.Lookup)
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d: %s", path, rec.Code, rec.Body.String())
+		}
+		return rec.Body.String()
+	}
+
+	if body := get("/default/"); !strings.Contains(body, "sql?q=SELECT") {
+		t.Errorf("landing page does not link to the husk:\n%s", body)
+	}
+	sql := url.QueryEscape("SELECT kind, AVG(latency) AS latency FROM events GROUP BY kind ORDER BY kind")
+	want := `{"columns":[{"name":"kind","type":"VARCHAR"},{"name":"latency","type":"DOUBLE"}],"rows":[["read",15],["write",30]],"pushed_down":true}`
+	if body := get("/default/sql?q=" + sql); strings.TrimSpace(body) != want {
+		t.Errorf("husk query = %s, want %s", body, want)
+	}
+	if body := get("/default/sql?q=" + url.QueryEscape("SELECT order_id FROM orders")); !strings.Contains(body, `"o1"`) {
+		t.Errorf("query on a loaded table = %s", body)
+	}
+}
+
+func TestServeFailsWithoutSources(t *testing.T) {
+	config := filepath.Join(t.TempDir(), configFileName)
+	if err := os.WriteFile(config, []byte(`sources { name: "missing" source_type: "csv" config { key: "file_path" value: "missing.csv" } }`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := setupConfigServer(config, os.ReadFile, openFile); err == nil {
+		t.Error("setupConfigServer succeeded with no loadable source")
+	}
+}
+
+func TestSamplesThenServe(t *testing.T) {
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	io.WriteString(gz, "0,,3418309,0,user1,3,job1,logical1\n5611824441,,3418309,1,user1,3,job1,logical1\n")
+	gz.Close()
+	cities := "name,country,subcountry,geonameid\nZurich,Switzerland,Zurich,2657896\nDublin,Ireland,Leinster,2964574\n"
+	downloads := 0
+	host := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		switch r.URL.Path {
+		case "/world-cities.csv":
+			io.WriteString(w, cities)
+		case "/job_events.csv.gz":
+			w.Write(gzipped.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer host.Close()
+
+	saved := sampleDatasets
+	defer func() { sampleDatasets = saved }()
+	sampleDatasets = slices.Clone(saved)
+	sampleDatasets[0].URL = host.URL + "/world-cities.csv"
+	sampleDatasets[0].SHA256 = "0000"
+	sampleDatasets[1].URL = host.URL + "/job_events.csv.gz"
+	sampleDatasets[1].SHA256 = sha256Hex(gzipped.Bytes())
+	sampleDatasets[2].URL = host.URL + "/missing.csv.gz"
+
+	dir := t.TempDir()
+	// Downloads that do not match the pinned checksum are rejected
+	if err := downloadSamples(host.Client(), dir, []string{"world_cities"}, false, io.Discard); err == nil || !strings.Contains(err.Error(), "SHA-256 mismatch") {
+		t.Errorf("downloadSamples with a wrong checksum: err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "world_cities.csv")); err == nil {
+		t.Error("download with a wrong checksum left a file behind")
+	}
+	sampleDatasets[0].SHA256 = sha256Hex([]byte(cities))
+	downloads = 0
+
+	if err := downloadSamples(host.Client(), dir, []string{"world_cities", "borg_jobs"}, false, io.Discard); err != nil {
+		t.Fatalf("downloadSamples: %v", err)
+	}
+	// Datasets already present are not downloaded again
+	if err := downloadSamples(host.Client(), dir, []string{"world_cities"}, false, io.Discard); err != nil || downloads != 2 {
+		t.Errorf("second run: err = %v, downloads = %d, want 2", err, downloads)
+	}
+	if err := downloadSamples(host.Client(), dir, []string{"borg_machines"}, false, io.Discard); err == nil {
+		t.Error("downloadSamples succeeded for a missing file")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "borg_machines.csv")); err == nil {
+		t.Error("failed download left a file behind")
+	}
+	if err := downloadSamples(host.Client(), dir, []string{"cities"}, false, io.Discard); err == nil {
+		t.Error("downloadSamples succeeded for an unknown dataset")
+	}
+
+	srv, products, err := setupConfigServer(filepath.Join(dir, samplesConfigFileName), os.ReadFile, openFile)
+	if err != nil {
+		t.Fatalf("setupConfigServer: %v", err)
+	}
+	handler := srv.Handler("default", products.Lookup)
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d: %s", path, rec.Code, rec.Body.String())
+		}
+		return rec.Body.String()
+	}
+	if body := get("/default/table?table=world_cities&columns=name,country"); !strings.Contains(body, "Zurich") {
+		t.Error("world_cities table does not contain Zurich")
+	}
+	// The header is prepended to the trace, whose event codes are labeled
+	if body := get("/default/table?table=borg_jobs&columns=job_id,event_type"); !strings.Contains(body, "SCHEDULE") {
+		t.Error("borg_jobs table does not label the event types")
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -24,6 +24,7 @@ package aggregates
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"time"
 
 	"github.com/google/taxinomia/core/query"
@@ -41,13 +42,15 @@ type AggregateState interface {
 }
 
 // NumericAggState stores intermediate state for numeric column aggregates.
-// It can derive sum, avg, stddev, min, max, and count.
+// It can derive sum, avg, stddev, min, max, and count, and the unique count if it
+// tracks distinct values.
 type NumericAggState struct {
-	Count int64   // Number of values
-	Sum   float64 // Sum of values
-	SumSq float64 // Sum of squared values (for stddev)
-	Min   float64 // Minimum value
-	Max   float64 // Maximum value
+	Count    int64                // Number of values
+	Sum      float64              // Sum of values
+	SumSq    float64              // Sum of squared values (for stddev)
+	Min      float64              // Minimum value
+	Max      float64              // Maximum value
+	Distinct map[float64]struct{} // Set of unique values (nil = not tracked)
 }
 
 // NewNumericAggState creates a new empty numeric aggregate state.
@@ -58,8 +61,19 @@ func NewNumericAggState() *NumericAggState {
 	}
 }
 
+// NewDistinctNumericAggState creates a new empty numeric aggregate state that also
+// tracks distinct values, for identifier columns whose unique count is meaningful.
+func NewDistinctNumericAggState() *NumericAggState {
+	s := NewNumericAggState()
+	s.Distinct = make(map[float64]struct{})
+	return s
+}
+
 // Add adds a single value to the aggregate state.
 func (s *NumericAggState) Add(value float64) {
+	if s.Distinct != nil {
+		s.Distinct[value] = struct{}{}
+	}
 	s.Count++
 	s.Sum += value
 	s.SumSq += value * value
@@ -82,6 +96,14 @@ func (s *NumericAggState) Combine(other AggregateState) {
 	if !ok || o.Count == 0 {
 		return
 	}
+	if o.Distinct != nil {
+		if s.Distinct == nil {
+			s.Distinct = make(map[float64]struct{}, len(o.Distinct))
+		}
+		for value := range o.Distinct {
+			s.Distinct[value] = struct{}{}
+		}
+	}
 	s.Count += o.Count
 	s.Sum += o.Sum
 	s.SumSq += o.SumSq
@@ -134,11 +156,41 @@ func (s *NumericAggState) Format(aggType query.AggregateType) string {
 		return formatNumber(s.Min)
 	case query.AggMax:
 		return formatNumber(s.Max)
+	case query.AggUnique:
+		if s.Distinct == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%d", len(s.Distinct))
 	default:
 		return "-"
 	}
 }
 
+// Value returns the numeric value of the given aggregate type, if it has one.
+func (s *NumericAggState) Value(aggType query.AggregateType) (float64, bool) {
+	if s.Count == 0 {
+		return 0, false
+	}
+	switch aggType {
+	case query.AggCount:
+		return float64(s.Count), true
+	case query.AggSum:
+		return s.Sum, true
+	case query.AggAvg:
+		return s.Avg(), true
+	case query.AggStdDev:
+		return s.StdDev(), true
+	case query.AggMin:
+		return s.Min, true
+	case query.AggMax:
+		return s.Max, true
+	case query.AggUnique:
+		return float64(len(s.Distinct)), s.Distinct != nil
+	default:
+		return 0, false
+	}
+}
+
 // ColumnType returns the column type this state is for.
 func (s *NumericAggState) ColumnType() query.ColumnType {
 	return query.ColumnTypeNumeric
@@ -205,6 +257,24 @@ func (s *BoolAggState) Format(aggType query.AggregateType) string {
 	}
 }
 
+// Value returns the numeric value of the given aggregate type, if it has one.
+// The ratio is left to Format, which shows it as a percentage.
+func (s *BoolAggState) Value(aggType query.AggregateType) (float64, bool) {
+	if s.Count == 0 {
+		return 0, false
+	}
+	switch aggType {
+	case query.AggCount:
+		return float64(s.Count), true
+	case query.AggTrue:
+		return float64(s.TrueCount), true
+	case query.AggFalse:
+		return float64(s.FalseCount), true
+	default:
+		return 0, false
+	}
+}
+
 // ColumnType returns the column type this state is for.
 func (s *BoolAggState) ColumnType() query.ColumnType {
 	return query.ColumnTypeBool
@@ -293,6 +363,21 @@ func (s *StringAggState) Format(aggType query.AggregateType) string {
 	}
 }
 
+// Value returns the numeric value of the given aggregate type, if it has one.
+func (s *StringAggState) Value(aggType query.AggregateType) (float64, bool) {
+	if s.Count == 0 {
+		return 0, false
+	}
+	switch aggType {
+	case query.AggCount:
+		return float64(s.Count), true
+	case query.AggUnique:
+		return float64(s.UniqueCount()), true
+	default:
+		return 0, false
+	}
+}
+
 // ColumnType returns the column type this state is for.
 func (s *StringAggState) ColumnType() query.ColumnType {
 	return query.ColumnTypeString
@@ -518,6 +603,7 @@ func NewColumnAggregator(colName string, colType query.ColumnType) *ColumnAggreg
 type FormattedAggregate struct {
 	Symbol   string // e.g., Σ, μ, ↓, ↑
 	Value    string // Formatted value
+	Raw      string // Unformatted value of numeric aggregates (15 significant digits), empty for others
 	Title    string // Tooltip title
 	IsSorted bool   // Whether this aggregate is the one being sorted by
 }
@@ -537,18 +623,37 @@ func FormatAggregates(state AggregateState, enabledAggs []query.AggregateType) [
 // If sortedColName matches the column being formatted and sortedAggType matches one of the aggregates,
 // that aggregate will have IsSorted=true.
 func FormatAggregatesWithSort(state AggregateState, enabledAggs []query.AggregateType, sortedColName string, sortedAggType query.AggregateType) []FormattedAggregate {
+	return FormatAggregatesWithRules(state, enabledAggs, sortedColName, sortedAggType, nil)
+}
+
+// FormatAggregatesWithRules returns formatted aggregates like FormatAggregatesWithSort,
+// formatting the numeric aggregates that have a rule in formats by that rule.
+func FormatAggregatesWithRules(state AggregateState, enabledAggs []query.AggregateType, sortedColName string, sortedAggType query.AggregateType, formats AggregateFormats) []FormattedAggregate {
 	if state == nil || len(enabledAggs) == 0 {
 		return nil
 	}
+	valuer, _ := state.(interface {
+		Value(query.AggregateType) (float64, bool)
+	})
 	result := make([]FormattedAggregate, 0, len(enabledAggs))
 	for _, aggType := range enabledAggs {
 		isSorted := sortedColName != "" && aggType == sortedAggType
-		result = append(result, FormattedAggregate{
+		agg := FormattedAggregate{
 			Symbol:   query.AggregateSymbol(aggType),
 			Value:    state.Format(aggType),
 			Title:    query.AggregateTitle(aggType),
 			IsSorted: isSorted,
-		})
+		}
+		if valuer != nil {
+			if v, ok := valuer.Value(aggType); ok {
+				// 15 significant digits leave out the noise of float sums (229.97000000000003)
+				agg.Raw = strconv.FormatFloat(v, 'g', 15, 64)
+				if rule, ok := formats.Rule(aggType); ok {
+					agg.Value = rule.Format(v)
+				}
+			}
+		}
+		result = append(result, agg)
 	}
 	return result
 }
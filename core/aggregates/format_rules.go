@@ -0,0 +1,187 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregates
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/google/taxinomia/core/query"
+)
+
+// FormatStyle is how a format rule displays aggregate values
+type FormatStyle string
+
+const (
+	FormatSI          FormatStyle = "si"       // SI suffixes with significant digits (1.23M)
+	FormatFixed       FormatStyle = "fixed"    // Fixed number of decimals (12.50)
+	FormatSignificant FormatStyle = "sig"      // Significant digits (0.00123, 1230)
+	FormatDuration    FormatStyle = "duration" // Seconds as the two largest units (3d4h)
+)
+
+// siSuffixes are the suffixes of successive powers of 1000
+var siSuffixes = []string{"", "k", "M", "G", "T", "P", "E"}
+
+// FormatRule controls how the values of an aggregate are displayed, independently of
+// how the cells of the column are formatted.
+type FormatRule struct {
+	Style  FormatStyle
+	Digits int // Significant digits (si, sig) or decimals (fixed)
+}
+
+// ParseFormatRule parses a format rule: "si", "sig" or "fixed", optionally followed by
+// the number of digits (e.g., "si:2", "fixed:2"), or "duration".
+func ParseFormatRule(spec string) (FormatRule, error) {
+	style, digitsStr, hasDigits := strings.Cut(strings.TrimSpace(spec), ":")
+	rule := FormatRule{Style: FormatStyle(style)}
+	switch rule.Style {
+	case FormatSI, FormatSignificant:
+		rule.Digits = 3
+	case FormatFixed:
+		rule.Digits = 2
+	case FormatDuration:
+		if hasDigits {
+			return FormatRule{}, fmt.Errorf("format %q takes no digits", spec)
+		}
+		return rule, nil
+	default:
+		return FormatRule{}, fmt.Errorf("unknown aggregate format %q (expected si, sig, fixed or duration)", spec)
+	}
+	if hasDigits {
+		digits, err := strconv.Atoi(digitsStr)
+		if err != nil || digits < 0 || digits > 15 || digits == 0 && rule.Style != FormatFixed {
+			return FormatRule{}, fmt.Errorf("invalid digits in aggregate format %q", spec)
+		}
+		rule.Digits = digits
+	}
+	return rule, nil
+}
+
+// Format formats a value by the rule
+func (r FormatRule) Format(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	switch r.Style {
+	case FormatSI:
+		return formatSI(v, r.Digits)
+	case FormatFixed:
+		return strconv.FormatFloat(v, 'f', r.Digits, 64)
+	case FormatSignificant:
+		return formatSignificant(v, r.Digits)
+	case FormatDuration:
+		return formatSeconds(v)
+	default:
+		return formatNumber(v)
+	}
+}
+
+// formatSignificant rounds a value to the given number of significant digits,
+// without trailing zeros after the decimal point
+func formatSignificant(v float64, digits int) string {
+	if v == 0 {
+		return "0"
+	}
+	decimals := digits - 1 - int(math.Floor(math.Log10(math.Abs(v))))
+	if decimals <= 0 {
+		scale := math.Pow(10, float64(-decimals))
+		return strconv.FormatFloat(math.Round(v/scale)*scale, 'f', 0, 64)
+	}
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// formatSI formats a value with significant digits and the SI suffix of its magnitude
+func formatSI(v float64, digits int) string {
+	i := 0
+	for math.Abs(v) >= 1000 && i < len(siSuffixes)-1 {
+		v /= 1000
+		i++
+	}
+	s := formatSignificant(v, digits)
+	// Rounding may carry into the next magnitude (999.9k -> 1000k)
+	if rounded, _ := strconv.ParseFloat(s, 64); math.Abs(rounded) >= 1000 && i < len(siSuffixes)-1 {
+		s = formatSignificant(v/1000, digits)
+		i++
+	}
+	return s + siSuffixes[i]
+}
+
+// formatSeconds formats a number of seconds as its two largest units (3d4h, 1m30s),
+// or in milliseconds below a second
+func formatSeconds(v float64) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	if v < 1 {
+		return sign + formatSignificant(v*1000, 3) + "ms"
+	}
+	units := []struct {
+		suffix  string
+		seconds int64
+	}{{"d", 86400}, {"h", 3600}, {"m", 60}, {"s", 1}}
+	rest := int64(math.Round(v))
+	for i, u := range units {
+		if rest < u.seconds {
+			continue
+		}
+		formatted := fmt.Sprintf("%d%s", rest/u.seconds, u.suffix)
+		if i+1 < len(units) {
+			if n := rest % u.seconds / units[i+1].seconds; n > 0 {
+				formatted += fmt.Sprintf("%d%s", n, units[i+1].suffix)
+			}
+		}
+		return sign + formatted
+	}
+	return "0s"
+}
+
+// AggregateFormats maps aggregate types (e.g., "sum") to the rules formatting their
+// values. The rule of "*" applies to the aggregates without a rule of their own.
+type AggregateFormats map[string]FormatRule
+
+// ParseAggregateFormats parses the format rules of a column's aggregates, as configured
+// in its annotation (e.g., {"sum": "si", "avg": "fixed:2"}).
+func ParseAggregateFormats(specs map[string]string) (AggregateFormats, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	formats := make(AggregateFormats, len(specs))
+	for aggType, spec := range specs {
+		rule, err := ParseFormatRule(spec)
+		if err != nil {
+			return nil, fmt.Errorf("aggregate %q: %w", aggType, err)
+		}
+		formats[aggType] = rule
+	}
+	return formats, nil
+}
+
+// Rule returns the format rule of an aggregate type, if any
+func (f AggregateFormats) Rule(aggType query.AggregateType) (FormatRule, bool) {
+	if rule, ok := f[string(aggType)]; ok {
+		return rule, true
+	}
+	rule, ok := f["*"]
+	return rule, ok
+}
@@ -0,0 +1,81 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregates
+
+import (
+	"testing"
+
+	"github.com/google/taxinomia/core/query"
+)
+
+func TestFormatRule(t *testing.T) {
+	tests := []struct {
+		spec  string
+		value float64
+		want  string
+	}{
+		{"si", 1234567, "1.23M"},
+		{"si:2", 1234567, "1.2M"},
+		{"si", 999.95e3, "1M"},
+		{"si", -4200, "-4.2k"},
+		{"si", 12.5, "12.5"},
+		{"fixed", 12.5, "12.50"},
+		{"fixed:0", 12.5, "12"},
+		{"sig:2", 0.012345, "0.012"},
+		{"sig:2", 98765, "99000"},
+		{"duration", 3*86400 + 4*3600 + 59, "3d4h"},
+		{"duration", 90, "1m30s"},
+		{"duration", 3600, "1h"},
+		{"duration", 0.25, "250ms"},
+	}
+	for _, tt := range tests {
+		rule, err := ParseFormatRule(tt.spec)
+		if err != nil {
+			t.Errorf("ParseFormatRule(%q) failed: %v", tt.spec, err)
+			continue
+		}
+		if got := rule.Format(tt.value); got != tt.want {
+			t.Errorf("%s of %v = %q, want %q", tt.spec, tt.value, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"", "SI", "si:x", "sig:0", "duration:2"} {
+		if _, err := ParseFormatRule(spec); err == nil {
+			t.Errorf("expected ParseFormatRule(%q) to fail", spec)
+		}
+	}
+}
+
+func TestFormatAggregatesWithRules(t *testing.T) {
+	state := NewNumericAggState()
+	for _, v := range []float64{1200000, 34567, 1} {
+		state.Add(v)
+	}
+	formats, err := ParseAggregateFormats(map[string]string{"sum": "si", "*": "fixed:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := FormatAggregatesWithRules(state, []query.AggregateType{query.AggSum, query.AggMin, query.AggCount}, "", query.AggCount, formats)
+	want := []struct{ value, raw string }{{"1.23M", "1234568"}, {"1.0", "1"}, {"3.0", "3"}}
+	for i, w := range want {
+		if got[i].Value != w.value || got[i].Raw != w.raw {
+			t.Errorf("aggregate %d = %q (raw %q), want %q (raw %q)", i, got[i].Value, got[i].Raw, w.value, w.raw)
+		}
+	}
+}
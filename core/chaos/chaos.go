@@ -0,0 +1,220 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package chaos injects artificial latency and failures at well-defined
+// boundaries (data source loading, join resolution, and page rendering) so
+// operators can verify that timeouts, fallbacks, and error pages behave as
+// expected before a real incident exercises them.
+//
+// Fault injection is only active in binaries built with the "chaos" build tag.
+// Such binaries read their fault specification from the TAXINOMIA_CHAOS
+// environment variable, for example:
+//
+//	TAXINOMIA_CHAOS="loader=2s,join=fail@0.25,render=300ms+fail@0.1"
+//
+// Each entry is point=action, where point is one of loader, join, or render and
+// action combines, with "+", a latency (a Go duration) and/or a failure
+// ("fail" to always fail, "fail@p" to fail with probability p).
+package chaos
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/taxinomia/core/errs"
+)
+
+// EnvVar is the environment variable holding the fault specification.
+const EnvVar = "TAXINOMIA_CHAOS"
+
+// Point identifies a boundary where faults can be injected.
+type Point string
+
+// Injection points
+const (
+	Loader Point = "loader" // Before a data source is loaded
+	Join   Point = "join"   // Before joined columns are resolved for a table view
+	Render Point = "render" // Before a page is rendered
+)
+
+// Fault describes the latency and failures injected at a point.
+type Fault struct {
+	Latency     time.Duration // Delay added before the boundary proceeds
+	FailureRate float64       // Probability in [0, 1] that the boundary fails
+}
+
+// String returns the fault in specification syntax (e.g., "300ms+fail@0.1").
+func (f Fault) String() string {
+	var parts []string
+	if f.Latency > 0 {
+		parts = append(parts, f.Latency.String())
+	}
+	switch {
+	case f.FailureRate >= 1:
+		parts = append(parts, "fail")
+	case f.FailureRate > 0:
+		parts = append(parts, "fail@"+strconv.FormatFloat(f.FailureRate, 'g', -1, 64))
+	}
+	return strings.Join(parts, "+")
+}
+
+// Injector injects configured faults at injection points.
+// A nil *Injector injects nothing, so callers can invoke it unconditionally.
+// It is safe for concurrent use.
+type Injector struct {
+	mu     sync.RWMutex
+	faults map[Point]Fault
+
+	// Replaceable for tests
+	sleep  func(time.Duration)
+	random func() float64
+}
+
+// NewInjector creates an injector with no faults configured.
+func NewInjector() *Injector {
+	return &Injector{
+		faults: make(map[Point]Fault),
+		sleep:  time.Sleep,
+		random: rand.Float64,
+	}
+}
+
+// Set configures the fault injected at a point, replacing any previous fault.
+func (i *Injector) Set(point Point, fault Fault) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.faults[point] = fault
+}
+
+// Clear removes the fault configured at a point.
+func (i *Injector) Clear(point Point) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.faults, point)
+}
+
+// Inject applies the fault configured at point: it sleeps for the configured
+// latency and then returns an ErrSourceUnavailable error if the failure
+// triggers. target names what is being processed (e.g., the table) for the
+// error message. Returns nil if no fault is configured or i is nil.
+func (i *Injector) Inject(point Point, target string) error {
+	if i == nil {
+		return nil
+	}
+	i.mu.RLock()
+	fault, ok := i.faults[point]
+	i.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	if fault.Latency > 0 {
+		i.sleep(fault.Latency)
+	}
+	if fault.FailureRate > 0 && i.random() < fault.FailureRate {
+		return errs.New(errs.ErrSourceUnavailable, "injected %s failure for %q", point, target)
+	}
+	return nil
+}
+
+// String returns the configured faults in specification syntax.
+func (i *Injector) String() string {
+	if i == nil {
+		return ""
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	entries := make([]string, 0, len(i.faults))
+	for point, fault := range i.faults {
+		entries = append(entries, string(point)+"="+fault.String())
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// Parse creates an injector from a fault specification (see the package documentation).
+func Parse(spec string) (*Injector, error) {
+	injector := NewInjector()
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, action, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid chaos entry %q: expected point=action", entry)
+		}
+		point := Point(strings.TrimSpace(name))
+		switch point {
+		case Loader, Join, Render:
+		default:
+			return nil, fmt.Errorf("unknown chaos point %q (expected loader, join, or render)", point)
+		}
+		fault, err := parseFault(action)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chaos action for %s: %w", point, err)
+		}
+		injector.Set(point, fault)
+	}
+	return injector, nil
+}
+
+// parseFault parses an action such as "2s", "fail", "fail@0.5", or "300ms+fail@0.1".
+func parseFault(action string) (Fault, error) {
+	var fault Fault
+	for _, part := range strings.Split(action, "+") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "fail":
+			fault.FailureRate = 1
+		case strings.HasPrefix(part, "fail@"):
+			rate, err := strconv.ParseFloat(strings.TrimPrefix(part, "fail@"), 64)
+			if err != nil || rate < 0 || rate > 1 {
+				return Fault{}, fmt.Errorf("failure rate in %q must be a number between 0 and 1", part)
+			}
+			fault.FailureRate = rate
+		default:
+			latency, err := time.ParseDuration(part)
+			if err != nil || latency < 0 {
+				return Fault{}, fmt.Errorf("%q is neither a latency nor a failure", part)
+			}
+			fault.Latency = latency
+		}
+	}
+	return fault, nil
+}
+
+// FromEnv creates an injector from the TAXINOMIA_CHAOS environment variable.
+// Returns nil if the binary was built without the "chaos" build tag or the
+// variable is unset, so production binaries never inject faults.
+func FromEnv() (*Injector, error) {
+	if !Enabled {
+		return nil, nil
+	}
+	spec := os.Getenv(EnvVar)
+	if spec == "" {
+		return nil, nil
+	}
+	return Parse(spec)
+}
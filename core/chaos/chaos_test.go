@@ -0,0 +1,89 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaos
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/taxinomia/core/errs"
+)
+
+func TestParse(t *testing.T) {
+	injector, err := Parse("loader=2s, join=fail@0.25,render=300ms+fail")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got, want := injector.String(), "join=fail@0.25,loader=2s,render=300ms+fail"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	for _, spec := range []string{"loader", "network=1s", "join=fail@2", "render=slow", "loader=-1s"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestInject(t *testing.T) {
+	injector := NewInjector()
+	var slept time.Duration
+	injector.sleep = func(d time.Duration) { slept += d }
+	roll := 0.5
+	injector.random = func() float64 { return roll }
+
+	injector.Set(Loader, Fault{Latency: time.Second})
+	injector.Set(Join, Fault{FailureRate: 0.6})
+	injector.Set(Render, Fault{FailureRate: 0.4})
+
+	if err := injector.Inject(Loader, "orders"); err != nil || slept != time.Second {
+		t.Errorf("expected loader latency without failure, got err=%v slept=%v", err, slept)
+	}
+	err := injector.Inject(Join, "orders")
+	if !errors.Is(err, errs.ErrSourceUnavailable) {
+		t.Errorf("expected injected join failure, got %v", err)
+	}
+	if err := injector.Inject(Render, "orders"); err != nil {
+		t.Errorf("expected render to pass when the roll exceeds the failure rate, got %v", err)
+	}
+
+	injector.Clear(Join)
+	if err := injector.Inject(Join, "orders"); err != nil {
+		t.Errorf("expected no failure after Clear, got %v", err)
+	}
+}
+
+func TestNilInjectorInjectsNothing(t *testing.T) {
+	var injector *Injector
+	if err := injector.Inject(Render, "orders"); err != nil {
+		t.Errorf("nil injector returned %v", err)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(EnvVar, "render=fail")
+	injector, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv failed: %v", err)
+	}
+	if Enabled != (injector != nil) {
+		t.Errorf("FromEnv returned %v with Enabled=%v", injector, Enabled)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package columns
+
+// CohortColumn presents a column with some of its values merged into labeled cohorts.
+// Values mapped to a cohort read as the cohort label, so grouping on the column puts
+// all rows of the cohort's values into one group. Other values are unchanged.
+type CohortColumn struct {
+	source IDataColumn
+	labels map[string]string // value -> cohort label
+}
+
+// NewCohortColumn creates a cohort view of source, where labels maps values to the
+// label of the cohort they are merged into.
+func NewCohortColumn(source IDataColumn, labels map[string]string) *CohortColumn {
+	return &CohortColumn{source: source, labels: labels}
+}
+
+func (c *CohortColumn) ColumnDef() *ColumnDef {
+	return c.source.ColumnDef()
+}
+
+func (c *CohortColumn) Length() int {
+	return c.source.Length()
+}
+
+// GetString returns the cohort label of the value at i, or the value itself if it
+// belongs to no cohort.
+func (c *CohortColumn) GetString(i uint32) (string, error) {
+	value, err := c.source.GetString(i)
+	if err != nil {
+		return "", err
+	}
+	if label, ok := c.labels[value]; ok {
+		return label, nil
+	}
+	return value, nil
+}
+
+func (c *CohortColumn) IsKey() bool {
+	return false
+}
+
+func (c *CohortColumn) CreateJoinedColumn(columnDef *ColumnDef, joiner IJoiner) IJoinedDataColumn {
+	return c.source.CreateJoinedColumn(columnDef, joiner)
+}
+
+// GroupIndices groups rows by their cohort label or value. Rows whose value cannot be
+// read are returned as unmapped.
+func (c *CohortColumn) GroupIndices(indices []uint32, columnView *ColumnView) (map[uint32][]uint32, []uint32) {
+	groupedIndices := map[uint32][]uint32{}
+	valueToGroupKey := map[string]uint32{}
+	var unmapped []uint32
+	for _, i := range indices {
+		value, err := c.GetString(i)
+		if err != nil {
+			unmapped = append(unmapped, i)
+			continue
+		}
+		if groupKey, ok := valueToGroupKey[value]; ok {
+			groupedIndices[groupKey] = append(groupedIndices[groupKey], i)
+		} else {
+			groupKey := uint32(len(valueToGroupKey))
+			valueToGroupKey[value] = groupKey
+			groupedIndices[groupKey] = []uint32{i}
+		}
+	}
+	return groupedIndices, unmapped
+}
@@ -18,7 +18,10 @@ limitations under the License.
 
 package columns
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // Display labels for special values
 const (
@@ -123,6 +126,14 @@ type ColumnDef struct {
 	name        string // must not contain any of the following characters: & = : ,
 	displayName string
 	entityType  string
+	category    string            // optional grouping label for the column picker
+	uncurated   bool              // loaded as a pass-through string column because it has no annotation and an ambiguous type
+	valueLabels map[string]string // optional display labels for stored codes
+	identifier  *bool             // overrides the detection of identifier columns (nil = detect)
+
+	aggregateFormats map[string]string   // optional format rules of aggregates, by aggregate type
+	updatedAt        time.Time           // when the values were last updated (zero = unknown)
+	invalidRows      map[uint32]struct{} // rows holding a placeholder for a value that failed to load
 }
 
 // NewColumnDef creates a new ColumnDef with the given name and display name
@@ -146,6 +157,97 @@ func (cd *ColumnDef) EntityType() string {
 	return cd.entityType
 }
 
+// Category returns the column's annotation category, used to group columns in the column picker.
+func (cd *ColumnDef) Category() string {
+	return cd.category
+}
+
+// SetCategory sets the column's annotation category
+func (cd *ColumnDef) SetCategory(category string) {
+	cd.category = category
+}
+
+// IsUncurated returns true if the column was loaded as a pass-through string column
+// because it has no annotation and its type could not be inferred reliably.
+func (cd *ColumnDef) IsUncurated() bool {
+	return cd.uncurated
+}
+
+// SetUncurated marks the column as uncurated
+func (cd *ColumnDef) SetUncurated(uncurated bool) {
+	cd.uncurated = uncurated
+}
+
+// ValueLabels returns the display labels for stored codes, or nil if the column has none.
+func (cd *ColumnDef) ValueLabels() map[string]string {
+	return cd.valueLabels
+}
+
+// SetValueLabels sets the display labels for stored codes (e.g., "137" -> "OOMKilled")
+func (cd *ColumnDef) SetValueLabels(labels map[string]string) {
+	cd.valueLabels = labels
+}
+
+// Identifier returns whether the column is marked as an identifier, and whether it is
+// marked at all. Unmarked columns are detected by IsIdentifier.
+func (cd *ColumnDef) Identifier() (identifier bool, ok bool) {
+	if cd.identifier == nil {
+		return false, false
+	}
+	return *cd.identifier, true
+}
+
+// SetIdentifier marks the column as an identifier or as a measure, overriding detection
+func (cd *ColumnDef) SetIdentifier(identifier bool) {
+	cd.identifier = &identifier
+}
+
+// AggregateFormats returns the format rules of the column's aggregates by aggregate type
+// (e.g., "sum" -> "si"), or nil if its aggregates use the default formatting.
+func (cd *ColumnDef) AggregateFormats() map[string]string {
+	return cd.aggregateFormats
+}
+
+// SetAggregateFormats sets the format rules of the column's aggregates (see aggregates.ParseFormatRule)
+func (cd *ColumnDef) SetAggregateFormats(formats map[string]string) {
+	cd.aggregateFormats = formats
+}
+
+// UpdatedAt returns when the values of the column were last updated, or the zero time if
+// unknown. Joined columns report the update time of the column they read.
+func (cd *ColumnDef) UpdatedAt() time.Time {
+	return cd.updatedAt
+}
+
+// SetUpdatedAt sets when the values of the column were last updated
+func (cd *ColumnDef) SetUpdatedAt(t time.Time) {
+	cd.updatedAt = t
+}
+
+// SetInvalidRows marks rows whose stored value is a placeholder for a value that could
+// not be loaded, such as the zero value stored for a value that failed type coercion.
+// Aggregates leave these rows out.
+func (cd *ColumnDef) SetInvalidRows(rows []uint32) {
+	cd.invalidRows = make(map[uint32]struct{}, len(rows))
+	for _, row := range rows {
+		cd.invalidRows[row] = struct{}{}
+	}
+}
+
+// IsInvalidRow reports whether the value of a row is a placeholder (see SetInvalidRows)
+func (cd *ColumnDef) IsInvalidRow(row uint32) bool {
+	_, ok := cd.invalidRows[row]
+	return ok
+}
+
+// Label returns the display label of a stored value, or the value itself if it has none.
+func (cd *ColumnDef) Label(value string) string {
+	if label, ok := cd.valueLabels[value]; ok {
+		return label
+	}
+	return value
+}
+
 type IDataColumn interface {
 	ColumnDef() *ColumnDef
 	Length() int
@@ -189,7 +291,7 @@ type IJoinedDataColumn interface {
 	// might need to know the join specifics
 	// fromTable.fromColunm -> toTable.toColumn
 	// the fromColumn will have an index that maps values to indices in the fromTable, these indices can then be used to lookup values in the fromTable. displayed column
-	// Joiner() IJoiner
+	Joiner() IJoiner
 }
 
 type IJoinedColumn[T any] interface {
@@ -30,13 +30,14 @@ import (
 func CompareAtIndex(col IDataColumn, i, j uint32) int {
 	switch c := col.(type) {
 	case *StringColumn:
-		return strings.Compare(c.data[i], c.data[j])
+		return strings.Compare(c.value(i), c.value(j))
 
 	case *Uint32Column:
-		if c.data[i] < c.data[j] {
+		vi, vj := c.value(i), c.value(j)
+		if vi < vj {
 			return -1
 		}
-		if c.data[i] > c.data[j] {
+		if vi > vj {
 			return 1
 		}
 		return 0
@@ -54,19 +55,21 @@ func CompareAtIndex(col IDataColumn, i, j uint32) int {
 		return compareFloat64s(c.data[i], c.data[j])
 
 	case *Int64Column:
-		if c.data[i] < c.data[j] {
+		vi, vj := c.value(i), c.value(j)
+		if vi < vj {
 			return -1
 		}
-		if c.data[i] > c.data[j] {
+		if vi > vj {
 			return 1
 		}
 		return 0
 
 	case *Uint64Column:
-		if c.data[i] < c.data[j] {
+		vi, vj := c.value(i), c.value(j)
+		if vi < vj {
 			return -1
 		}
-		if c.data[i] > c.data[j] {
+		if vi > vj {
 			return 1
 		}
 		return 0
@@ -0,0 +1,149 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package columns
+
+import (
+	"slices"
+	"unsafe"
+)
+
+// Encodings chosen by FinalizeColumn, besides EncodingPlain
+const (
+	EncodingDictionary = "dictionary" // uint32 codes into the distinct values (string columns)
+	EncodingDelta      = "delta"      // Narrow deltas from the smallest value of each block of rows (integer columns)
+)
+
+// minEncodedRows is the number of rows from which FinalizeColumn considers encodings
+// other than plain. Smaller columns take too little memory to be worth encoding.
+const minEncodedRows = 1024
+
+// deltaBlockSize is the number of rows sharing a base value in delta encoding
+const deltaBlockSize = 128
+
+type integer interface {
+	~uint32 | ~int64 | ~uint64
+}
+
+// deltaValues stores integers as unsigned deltas from the smallest value of their
+// block of deltaBlockSize rows, in the narrowest width that fits every delta. Values
+// are read in constant time: the base of the block plus the delta of the row.
+type deltaValues[T integer] struct {
+	bases    []T
+	deltas8  []uint8
+	deltas16 []uint16
+	deltas32 []uint32
+	n        int
+}
+
+// encodeDelta returns the delta encoding of data, or nil if its deltas are not
+// narrower than the values themselves (e.g. random 64-bit IDs).
+func encodeDelta[T integer](data []T) *deltaValues[T] {
+	if len(data) == 0 {
+		return nil
+	}
+	bases := make([]T, (len(data)+deltaBlockSize-1)/deltaBlockSize)
+	var maxDelta uint64
+	for b := range bases {
+		block := data[b*deltaBlockSize : min((b+1)*deltaBlockSize, len(data))]
+		bases[b] = slices.Min(block)
+		for _, v := range block {
+			// Subtraction wraps around, so the difference is exact as an unsigned value
+			maxDelta = max(maxDelta, uint64(v-bases[b]))
+		}
+	}
+
+	var zero T
+	d := &deltaValues[T]{bases: bases, n: len(data)}
+	switch {
+	case maxDelta <= 0xFF && unsafe.Sizeof(zero) > 1:
+		d.deltas8 = make([]uint8, len(data))
+		for i, v := range data {
+			d.deltas8[i] = uint8(v - bases[i/deltaBlockSize])
+		}
+	case maxDelta <= 0xFFFF && unsafe.Sizeof(zero) > 2:
+		d.deltas16 = make([]uint16, len(data))
+		for i, v := range data {
+			d.deltas16[i] = uint16(v - bases[i/deltaBlockSize])
+		}
+	case maxDelta <= 0xFFFFFFFF && unsafe.Sizeof(zero) > 4:
+		d.deltas32 = make([]uint32, len(data))
+		for i, v := range data {
+			d.deltas32[i] = uint32(v - bases[i/deltaBlockSize])
+		}
+	default:
+		return nil
+	}
+	return d
+}
+
+// get returns the value at row i, which must be in bounds
+func (d *deltaValues[T]) get(i uint32) T {
+	base := d.bases[i/deltaBlockSize]
+	switch {
+	case d.deltas8 != nil:
+		return base + T(d.deltas8[i])
+	case d.deltas16 != nil:
+		return base + T(d.deltas16[i])
+	default:
+		return base + T(d.deltas32[i])
+	}
+}
+
+// decode returns the values of all rows
+func (d *deltaValues[T]) decode() []T {
+	data := make([]T, d.n)
+	for i := range data {
+		data[i] = d.get(uint32(i))
+	}
+	return data
+}
+
+// bytes returns the size of the bases and deltas
+func (d *deltaValues[T]) bytes() uint64 {
+	var zero T
+	return sliceBytes(len(d.bases), unsafe.Sizeof(zero)) + uint64(len(d.deltas8)) +
+		sliceBytes(len(d.deltas16), 2) + sliceBytes(len(d.deltas32), 4)
+}
+
+// dictionaryEncode returns the distinct values of data in order of first appearance
+// and the code of each row into them.
+func dictionaryEncode(data []string) ([]string, []uint32) {
+	codeOf := make(map[string]uint32)
+	var dict []string
+	codes := make([]uint32, len(data))
+	for i, v := range data {
+		code, ok := codeOf[v]
+		if !ok {
+			code = uint32(len(dict))
+			codeOf[v] = code
+			dict = append(dict, v)
+		}
+		codes[i] = code
+	}
+	return dict, codes
+}
+
+// stringBytes returns the size of the headers and contents of strings
+func stringBytes(values []string) uint64 {
+	n := sliceBytes(len(values), unsafe.Sizeof(""))
+	for _, s := range values {
+		n += uint64(len(s))
+	}
+	return n
+}
@@ -0,0 +1,144 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package columns
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestStringColumnDictionaryEncoding(t *testing.T) {
+	statuses := []string{"shipped", "pending", "cancelled", "shipped"}
+	col := NewStringColumn(NewColumnDef("status", "Status", ""))
+	for i := range 2000 {
+		col.Append(statuses[i%len(statuses)])
+	}
+	col.FinalizeColumn()
+
+	f := MeasureFootprint(col)
+	if f.Encoding != EncodingDictionary || f.Cardinality != 3 {
+		t.Fatalf("footprint = %+v, want dictionary encoding with 3 distinct values", f)
+	}
+	if f.DataBytes >= f.PlainBytes {
+		t.Errorf("dictionary encoding takes %d bytes, plain %d", f.DataBytes, f.PlainBytes)
+	}
+	if col.Length() != 2000 {
+		t.Errorf("Length() = %d, want 2000", col.Length())
+	}
+	if v, _ := col.GetValue(1002); v != "cancelled" {
+		t.Errorf("GetValue(1002) = %q, want cancelled", v)
+	}
+	if got := len(col.Filter(func(v string) bool { return v == "shipped" })); got != 1000 {
+		t.Errorf("Filter matched %d rows, want 1000", got)
+	}
+	if got := CompareAtIndex(col, 0, 1); got != 1 {
+		t.Errorf("CompareAtIndex(shipped, pending) = %d, want 1", got)
+	}
+
+	// Groups are numbered in order of first appearance among the indices
+	groups, _ := col.GroupIndices([]uint32{1, 0, 5, 4}, nil)
+	if !slices.Equal(groups[0], []uint32{1, 5}) || !slices.Equal(groups[1], []uint32{0, 4}) {
+		t.Errorf("GroupIndices = %v, want pending then shipped", groups)
+	}
+
+	// Appending decodes the column, finalizing encodes it again
+	col.Append("returned")
+	if v, _ := col.GetValue(2000); v != "returned" || MeasureFootprint(col).Encoding != EncodingPlain {
+		t.Errorf("appended value = %q with %s encoding, want returned with plain encoding", v, MeasureFootprint(col).Encoding)
+	}
+	col.FinalizeColumn()
+	if f := MeasureFootprint(col); f.Encoding != EncodingDictionary || f.Cardinality != 4 {
+		t.Errorf("footprint after append = %+v, want dictionary encoding with 4 distinct values", f)
+	}
+}
+
+func TestStringColumnStaysPlain(t *testing.T) {
+	distinct := NewStringColumn(NewColumnDef("name", "Name", ""))
+	small := NewStringColumn(NewColumnDef("status", "Status", ""))
+	for i := range 2000 {
+		distinct.Append(fmt.Sprintf("name-%d", i))
+	}
+	for range 10 {
+		small.Append("shipped")
+	}
+	distinct.FinalizeColumn()
+	small.FinalizeColumn()
+
+	for _, col := range []*StringColumn{distinct, small} {
+		if f := MeasureFootprint(col); f.Encoding != EncodingPlain || f.DataBytes != f.PlainBytes {
+			t.Errorf("%s footprint = %+v, want plain encoding", col.ColumnDef().Name(), f)
+		}
+	}
+}
+
+func TestIntegerColumnDeltaEncoding(t *testing.T) {
+	// Timestamps in seconds, increasing by less than a minute per row
+	ts := NewInt64Column(NewColumnDef("ts", "Timestamp", ""))
+	ids := NewUint32Column(NewColumnDef("id", "ID", "order"))
+	values := make([]int64, 3000)
+	for i := range values {
+		values[i] = 1_700_000_000 + int64(i*37%50) + int64(i)*30
+		ts.Append(values[i])
+		ids.Append(uint32(100_000 + i))
+	}
+	ts.FinalizeColumn()
+	ids.FinalizeColumn()
+
+	f := MeasureFootprint(ts)
+	if f.Encoding != EncodingDelta || f.DataBytes*2 > f.PlainBytes {
+		t.Errorf("ts footprint = %+v, want delta encoding at most half the plain size", f)
+	}
+	for i, want := range values {
+		if got, _ := ts.GetValue(uint32(i)); got != want {
+			t.Fatalf("GetValue(%d) = %d, want %d", i, got, want)
+		}
+	}
+	if got := len(ts.Filter(func(v int64) bool { return v >= values[2000] })); got != 1000 {
+		t.Errorf("Filter matched %d rows, want 1000", got)
+	}
+	if got := CompareAtIndex(ts, 2999, 0); got != 1 {
+		t.Errorf("CompareAtIndex(last, first) = %d, want 1", got)
+	}
+
+	// Key columns keep their value index
+	if f := MeasureFootprint(ids); f.Encoding != EncodingDelta || !ids.IsKey() {
+		t.Errorf("id footprint = %+v, want a delta-encoded key", f)
+	}
+	if idx, err := ids.GetIndex(102_345); err != nil || idx != 2345 {
+		t.Errorf("GetIndex(102345) = %d, %v, want 2345", idx, err)
+	}
+
+	ts.Append(0)
+	if got, _ := ts.GetValue(3000); got != 0 || ts.Length() != 3001 {
+		t.Errorf("appended value = %d with length %d, want 0 with length 3001", got, ts.Length())
+	}
+}
+
+func TestUint64ColumnStaysPlain(t *testing.T) {
+	// Spread over the whole range, deltas are as wide as the values
+	col := NewUint64Column(NewColumnDef("hash", "Hash", ""))
+	for i := range uint64(2000) {
+		col.Append(i * 0x9E3779B97F4A7C15)
+	}
+	col.FinalizeColumn()
+	if f := MeasureFootprint(col); f.Encoding != EncodingPlain {
+		t.Errorf("hash footprint = %+v, want plain encoding", f)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package columns
+
+import (
+	"time"
+	"unsafe"
+)
+
+// Encodings reported in a Footprint, besides the ones chosen by FinalizeColumn
+const (
+	EncodingPlain    = "plain"    // One value stored per row
+	EncodingJoined   = "joined"   // Values are looked up in another table's column
+	EncodingComputed = "computed" // Values are computed on access
+)
+
+// Footprint is the estimated memory held by a column. Sizes count the backing arrays
+// and key indexes of the column; memory shared with other columns is not attributed.
+type Footprint struct {
+	Encoding    string
+	Cardinality int    // Number of distinct values (0 for joined and computed columns)
+	DataBytes   uint64 // Bytes held by the values, as encoded
+	IndexBytes  uint64 // Bytes held by the value index of a key column
+	PlainBytes  uint64 // Bytes the values would take with plain encoding
+
+	// DictionaryBytes estimates DataBytes if the column were dictionary-encoded as
+	// uint32 codes into its distinct values. Only set for string columns.
+	DictionaryBytes uint64
+}
+
+// Bytes returns the total memory held by the column.
+func (f Footprint) Bytes() uint64 {
+	return f.DataBytes + f.IndexBytes
+}
+
+// MeasureFootprint estimates the memory held by col and counts its distinct values.
+// It scans the whole column, so it is meant for diagnostics, not request paths.
+func MeasureFootprint(col IDataColumn) Footprint {
+	switch c := col.(type) {
+	case *StringColumn:
+		f := Footprint{Encoding: EncodingPlain}
+		f.IndexBytes = mapBytes(len(c.valueIndex), unsafe.Sizeof(""), unsafe.Sizeof(0))
+		if c.codes != nil {
+			var valueBytes uint64
+			for _, code := range c.codes {
+				valueBytes += uint64(len(c.dict[code]))
+			}
+			f.Encoding = EncodingDictionary
+			f.Cardinality = len(c.dict)
+			f.DataBytes = stringBytes(c.dict) + sliceBytes(cap(c.codes), unsafe.Sizeof(uint32(0)))
+			f.PlainBytes = sliceBytes(len(c.codes), unsafe.Sizeof("")) + valueBytes
+			f.DictionaryBytes = f.DataBytes
+			return f
+		}
+		distinct := make(map[string]struct{})
+		var valueBytes, distinctBytes uint64
+		for _, s := range c.data {
+			valueBytes += uint64(len(s))
+			if _, ok := distinct[s]; !ok {
+				distinct[s] = struct{}{}
+				distinctBytes += uint64(len(s))
+			}
+		}
+		f.Cardinality = len(distinct)
+		f.DataBytes = sliceBytes(cap(c.data), unsafe.Sizeof("")) + valueBytes
+		f.PlainBytes = f.DataBytes
+		f.DictionaryBytes = sliceBytes(len(c.data), unsafe.Sizeof(uint32(0))) +
+			sliceBytes(len(distinct), unsafe.Sizeof("")) + distinctBytes
+		return f
+	case *Uint32Column:
+		return integerFootprint(c.data, c.delta, c.isKey, len(c.valueIndex))
+	case *Int64Column:
+		return integerFootprint(c.data, c.delta, c.isKey, len(c.valueIndex))
+	case *Uint64Column:
+		return integerFootprint(c.data, c.delta, c.isKey, len(c.valueIndex))
+	case *Float64Column:
+		return plainFootprint(c.data, c.isKey, len(c.valueIndex))
+	case *BoolColumn:
+		return plainFootprint(c.data, c.isKey, len(c.valueIndex))
+	case *DurationColumn:
+		return plainFootprint(c.data, c.isKey, len(c.valueIndex))
+	case *DatetimeColumn:
+		// time.Time is not comparable by value across locations, count distinct instants
+		nanos := make([]int64, len(c.data))
+		for i, t := range c.data {
+			nanos[i] = t.UnixNano()
+		}
+		f := plainFootprint(nanos, c.isKey, len(c.valueIndex))
+		f.DataBytes = sliceBytes(cap(c.data), unsafe.Sizeof(time.Time{}))
+		f.PlainBytes = sliceBytes(len(c.data), unsafe.Sizeof(time.Time{}))
+		return f
+	case IJoinedDataColumn:
+		return Footprint{Encoding: EncodingJoined}
+	default:
+		return Footprint{Encoding: EncodingComputed}
+	}
+}
+
+// integerFootprint measures an integer column, delta-encoded if delta is set
+func integerFootprint[T integer](data []T, delta *deltaValues[T], isKey bool, indexLen int) Footprint {
+	if delta == nil {
+		return plainFootprint(data, isKey, indexLen)
+	}
+	f := plainFootprint(delta.decode(), isKey, indexLen)
+	f.Encoding = EncodingDelta
+	f.DataBytes = delta.bytes()
+	return f
+}
+
+// plainFootprint measures a column storing one comparable value per row, with a
+// value index of indexLen entries.
+func plainFootprint[T comparable](data []T, isKey bool, indexLen int) Footprint {
+	var zero T
+	f := Footprint{Encoding: EncodingPlain}
+	if isKey {
+		f.Cardinality = len(data)
+	} else {
+		distinct := make(map[T]struct{})
+		for _, v := range data {
+			distinct[v] = struct{}{}
+		}
+		f.Cardinality = len(distinct)
+	}
+	f.DataBytes = sliceBytes(cap(data), unsafe.Sizeof(zero))
+	f.PlainBytes = sliceBytes(len(data), unsafe.Sizeof(zero))
+	f.IndexBytes = mapBytes(indexLen, unsafe.Sizeof(zero), unsafe.Sizeof(0))
+	return f
+}
+
+// sliceBytes returns the size of a backing array of n elements.
+func sliceBytes(n int, elemSize uintptr) uint64 {
+	return uint64(n) * uint64(elemSize)
+}
+
+// mapBytes estimates the size of a map with n entries: a control byte per slot
+// next to the key and value, with slots filled up to the 7/8 maximum load factor.
+func mapBytes(n int, keySize, valueSize uintptr) uint64 {
+	return uint64(n) * uint64(keySize+valueSize+1) * 8 / 7
+}
@@ -0,0 +1,66 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package columns
+
+import "testing"
+
+func TestMeasureFootprint(t *testing.T) {
+	status := NewStringColumn(NewColumnDef("status", "Status", ""))
+	for _, v := range []string{"shipped", "pending", "shipped", "shipped"} {
+		status.Append(v)
+	}
+	status.FinalizeColumn()
+
+	f := MeasureFootprint(status)
+	if f.Encoding != EncodingPlain || f.Cardinality != 2 {
+		t.Errorf("status footprint = %+v, want plain encoding with 2 distinct values", f)
+	}
+	if f.IndexBytes != 0 {
+		t.Errorf("status is not a key, got %d index bytes", f.IndexBytes)
+	}
+	if f.DataBytes < 4*16+28 {
+		t.Errorf("status data bytes = %d, want at least the string headers and contents", f.DataBytes)
+	}
+	// 4 codes, 2 string headers and the distinct contents
+	if want := uint64(4*4 + 2*16 + len("shipped") + len("pending")); f.DictionaryBytes != want {
+		t.Errorf("status dictionary bytes = %d, want %d", f.DictionaryBytes, want)
+	}
+
+	ids := NewUint32Column(NewColumnDef("id", "ID", "order"))
+	for i := uint32(0); i < 8; i++ {
+		ids.Append(i)
+	}
+	ids.FinalizeColumn()
+
+	f = MeasureFootprint(ids)
+	if f.Cardinality != 8 || f.DictionaryBytes != 0 {
+		t.Errorf("id footprint = %+v, want 8 distinct values and no dictionary estimate", f)
+	}
+	if f.DataBytes < 8*4 || f.IndexBytes == 0 {
+		t.Errorf("id footprint = %+v, want the values and a key index", f)
+	}
+	if f.Bytes() != f.DataBytes+f.IndexBytes {
+		t.Errorf("Bytes() = %d, want %d", f.Bytes(), f.DataBytes+f.IndexBytes)
+	}
+
+	computed := NewComputedUint32Column(NewColumnDef("c", "C", ""), 8, func(i uint32) (uint32, error) { return i, nil })
+	if f := MeasureFootprint(computed); f.Encoding != EncodingComputed || f.Bytes() != 0 {
+		t.Errorf("computed footprint = %+v, want computed encoding without stored bytes", f)
+	}
+}
@@ -0,0 +1,321 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package columns
+
+import (
+	"hash/fnv"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MatchConfidenceColumn is the reserved column name that selects the match
+// confidence of a join instead of a column of the joined table, e.g.
+// "customer.customers.customer._match_confidence".
+const MatchConfidenceColumn = "_match_confidence"
+
+const (
+	minHashSize = 32 // number of hash functions in a min-hash signature
+	minHashRows = 2  // signature rows per LSH band
+)
+
+// ScoredJoiner is a joiner that reports how confident it is in each match.
+type ScoredJoiner interface {
+	IJoiner
+	LookupWithConfidence(index uint32) (uint32, float64, error)
+}
+
+// NormalizeJoinKey normalizes a string join key for approximate matching:
+// it is lowercased and leading, trailing and repeated whitespace is removed.
+func NormalizeJoinKey(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// FuzzyJoiner joins string columns whose keys don't match exactly.
+// A key matches, in order of preference:
+//   - exactly (confidence 1)
+//   - after normalization with NormalizeJoinKey (confidence 1)
+//   - by trigram similarity of the normalized keys, if Threshold > 0 and the
+//     Jaccard similarity is at least Threshold (confidence = similarity)
+//
+// Similarity candidates are found with min-hash signatures bucketed by
+// locality-sensitive hashing, so lookups don't scan the whole target column.
+type FuzzyJoiner struct {
+	FromColumn IDataColumnT[string]
+	ToColumn   IDataColumnT[string]
+	Threshold  float64
+
+	once       sync.Once
+	normalized map[string]uint32   // normalized key -> first target index
+	trigrams   [][]uint64          // sorted trigram hashes per target index
+	buckets    map[uint64][]uint32 // LSH band hash -> target indices
+
+	mu      sync.Mutex
+	matches map[string]fuzzyMatch // memoized matches by source value
+}
+
+type fuzzyMatch struct {
+	index      uint32
+	confidence float64 // 0 if unmatched
+}
+
+// NewFuzzyJoiner creates a joiner matching string keys approximately.
+// A threshold of 0 disables similarity matching.
+func NewFuzzyJoiner(from, to IDataColumnT[string], threshold float64) *FuzzyJoiner {
+	return &FuzzyJoiner{
+		FromColumn: from,
+		ToColumn:   to,
+		Threshold:  threshold,
+		matches:    make(map[string]fuzzyMatch),
+	}
+}
+
+// Lookup returns the index of the best matching row in the target column.
+func (j *FuzzyJoiner) Lookup(index uint32) (uint32, error) {
+	target, _, err := j.LookupWithConfidence(index)
+	return target, err
+}
+
+// LookupWithConfidence returns the index of the best matching row in the
+// target column along with the confidence of the match in (0, 1].
+func (j *FuzzyJoiner) LookupWithConfidence(index uint32) (uint32, float64, error) {
+	v, err := j.FromColumn.GetValue(index)
+	if err != nil {
+		return 0, 0, err
+	}
+	if target, err := j.ToColumn.GetIndex(v); err == nil {
+		return target, 1, nil
+	}
+
+	j.mu.Lock()
+	m, ok := j.matches[v]
+	j.mu.Unlock()
+	if !ok {
+		m = j.match(v)
+		j.mu.Lock()
+		j.matches[v] = m
+		j.mu.Unlock()
+	}
+	if m.confidence == 0 {
+		return 0, 0, ErrUnmatched
+	}
+	return m.index, m.confidence, nil
+}
+
+// match finds the best approximate match for a value without an exact match.
+func (j *FuzzyJoiner) match(v string) fuzzyMatch {
+	j.once.Do(j.buildIndex)
+
+	key := NormalizeJoinKey(v)
+	if target, ok := j.normalized[key]; ok {
+		return fuzzyMatch{index: target, confidence: 1}
+	}
+	if j.Threshold <= 0 {
+		return fuzzyMatch{}
+	}
+
+	tris := trigramHashes(key)
+	if len(tris) == 0 {
+		return fuzzyMatch{}
+	}
+	var best fuzzyMatch
+	seen := make(map[uint32]bool)
+	for _, band := range bandHashes(minHashSignature(tris)) {
+		for _, candidate := range j.buckets[band] {
+			if seen[candidate] {
+				continue
+			}
+			seen[candidate] = true
+			sim := jaccard(tris, j.trigrams[candidate])
+			if sim >= j.Threshold && (sim > best.confidence || (sim == best.confidence && candidate < best.index)) {
+				best = fuzzyMatch{index: candidate, confidence: sim}
+			}
+		}
+	}
+	return best
+}
+
+// buildIndex indexes the target column by normalized key and, if similarity
+// matching is enabled, by min-hash band.
+func (j *FuzzyJoiner) buildIndex() {
+	n := j.ToColumn.Length()
+	j.normalized = make(map[string]uint32, n)
+	if j.Threshold > 0 {
+		j.trigrams = make([][]uint64, n)
+		j.buckets = make(map[uint64][]uint32)
+	}
+	for i := 0; i < n; i++ {
+		v, err := j.ToColumn.GetValue(uint32(i))
+		if err != nil {
+			continue
+		}
+		key := NormalizeJoinKey(v)
+		if _, exists := j.normalized[key]; !exists {
+			j.normalized[key] = uint32(i)
+		}
+		if j.Threshold <= 0 {
+			continue
+		}
+		tris := trigramHashes(key)
+		if len(tris) == 0 {
+			continue
+		}
+		j.trigrams[i] = tris
+		for _, band := range bandHashes(minHashSignature(tris)) {
+			j.buckets[band] = append(j.buckets[band], uint32(i))
+		}
+	}
+}
+
+// trigramHashes returns the sorted, distinct hashes of the character trigrams
+// of s, padded so that short strings and word boundaries produce trigrams.
+func trigramHashes(s string) []uint64 {
+	if s == "" {
+		return nil
+	}
+	runes := []rune("  " + s + " ")
+	hashes := make([]uint64, 0, len(runes)-2)
+	h := fnv.New64a()
+	for i := 0; i+3 <= len(runes); i++ {
+		h.Reset()
+		h.Write([]byte(string(runes[i : i+3])))
+		hashes = append(hashes, h.Sum64())
+	}
+	slices.Sort(hashes)
+	return slices.Compact(hashes)
+}
+
+// minHashSignature computes the min-hash signature of a set of trigram hashes.
+func minHashSignature(tris []uint64) [minHashSize]uint64 {
+	var sig [minHashSize]uint64
+	for k := range sig {
+		sig[k] = math.MaxUint64
+	}
+	for _, t := range tris {
+		for k := range sig {
+			if h := mix64(t ^ (uint64(k+1) * 0x9e3779b97f4a7c15)); h < sig[k] {
+				sig[k] = h
+			}
+		}
+	}
+	return sig
+}
+
+// bandHashes splits a signature into LSH bands and hashes each band.
+// Strings sharing any band hash are candidates for a similarity match.
+func bandHashes(sig [minHashSize]uint64) []uint64 {
+	bands := make([]uint64, 0, minHashSize/minHashRows)
+	for b := 0; b < minHashSize; b += minHashRows {
+		h := uint64(b)
+		for _, v := range sig[b : b+minHashRows] {
+			h = mix64(h ^ v)
+		}
+		bands = append(bands, h)
+	}
+	return bands
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// jaccard returns the Jaccard similarity of two sorted sets.
+func jaccard(a, b []uint64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for i, k := 0, 0; i < len(a) && k < len(b); {
+		switch {
+		case a[i] == b[k]:
+			common++
+			i++
+			k++
+		case a[i] < b[k]:
+			i++
+		default:
+			k++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// JoinConfidence returns the confidence of the match found by a joiner for a
+// row: the product of the confidences of every hop of a chained join, where
+// exact joiners always have confidence 1. Unmatched rows have confidence 0.
+func JoinConfidence(joiner IJoiner, index uint32) float64 {
+	hops := []IJoiner{joiner}
+	if chained, ok := joiner.(*ChainedJoiner); ok {
+		hops = chained.Joiners
+	}
+	confidence := 1.0
+	current := index
+	for _, hop := range hops {
+		if scored, ok := hop.(ScoredJoiner); ok {
+			next, c, err := scored.LookupWithConfidence(current)
+			if err != nil {
+				return 0
+			}
+			current, confidence = next, confidence*c
+			continue
+		}
+		next, err := hop.Lookup(current)
+		if err != nil {
+			return 0
+		}
+		current = next
+	}
+	return confidence
+}
+
+// NewMatchConfidenceColumn creates the companion column of a join holding the
+// match confidence, rounded to two decimals, of each of the numRows rows of the
+// source table. Unmatched rows are reported as unmatched, like any joined column.
+func NewMatchConfidenceColumn(columnDef *ColumnDef, joiner IJoiner, numRows int) IJoinedDataColumn {
+	confidences := NewFloat64Column(NewColumnDef(columnDef.Name(), columnDef.DisplayName(), ""))
+	for i := 0; i < numRows; i++ {
+		c := JoinConfidence(joiner, uint32(i))
+		if c > 0 {
+			c = max(math.Round(c*100)/100, 0.01) // keep weak matches distinguishable from unmatched rows
+		}
+		confidences.Append(c)
+	}
+	confidences.FinalizeColumn()
+	return NewJoinedFloat64Column(columnDef, matchedRows{confidences}, confidences)
+}
+
+// matchedRows maps each matched row to itself, so that unmatched rows of a
+// match confidence column display as unmatched.
+type matchedRows struct {
+	confidences *Float64Column
+}
+
+func (m matchedRows) Lookup(index uint32) (uint32, error) {
+	if c, err := m.confidences.GetValue(index); err != nil || c == 0 {
+		return 0, ErrUnmatched
+	}
+	return index, nil
+}
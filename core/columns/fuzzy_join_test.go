@@ -0,0 +1,94 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package columns
+
+import (
+	"errors"
+	"testing"
+)
+
+func fuzzyTestColumn(name, entityType string, values ...string) *StringColumn {
+	col := NewStringColumn(NewColumnDef(name, name, entityType))
+	for _, v := range values {
+		col.Append(v)
+	}
+	col.FinalizeColumn()
+	return col
+}
+
+func TestFuzzyJoinerMatching(t *testing.T) {
+	cities := fuzzyTestColumn("city", "city", "Zurich", "Geneva", "Lausanne", "Bern")
+	visits := fuzzyTestColumn("visit_city", "city", "Geneva", "zurich  ", "Lausane", "Paris", "")
+
+	tests := []struct {
+		row        uint32
+		threshold  float64
+		wantIndex  uint32
+		confidence float64 // 0 = unmatched
+	}{
+		{0, 0.5, 1, 1},   // exact
+		{1, 0, 0, 1},     // normalized
+		{2, 0, 0, 0},     // typo without similarity matching
+		{2, 0.5, 2, 0.7}, // typo matched by trigram similarity
+		{2, 0.9, 0, 0},   // below the threshold
+		{3, 0.5, 0, 0},   // no similar key
+		{4, 0.5, 0, 0},   // empty key
+	}
+	for _, tt := range tests {
+		joiner := NewFuzzyJoiner(visits, cities, tt.threshold)
+		index, confidence, err := joiner.LookupWithConfidence(tt.row)
+		if tt.confidence == 0 {
+			if !errors.Is(err, ErrUnmatched) {
+				t.Errorf("row %d, threshold %v: expected unmatched, got index %d (%v)", tt.row, tt.threshold, index, err)
+			}
+			continue
+		}
+		if err != nil || index != tt.wantIndex || confidence < tt.confidence {
+			t.Errorf("row %d, threshold %v: got index %d confidence %v (%v), want index %d confidence >= %v",
+				tt.row, tt.threshold, index, confidence, err, tt.wantIndex, tt.confidence)
+		}
+	}
+}
+
+func TestMatchConfidenceColumn(t *testing.T) {
+	cities := fuzzyTestColumn("city", "city", "Zurich", "Geneva", "Lausanne")
+	visits := fuzzyTestColumn("visit_city", "city", "Geneva", "GENEVA", "Lausane", "Paris")
+	joiner := NewFuzzyJoiner(visits, cities, 0.5)
+
+	col := NewMatchConfidenceColumn(NewColumnDef("conf", "conf", ""), joiner, visits.Length())
+	want := []string{"1", "1", "0.7", ""}
+	for i, w := range want {
+		got, err := col.GetString(uint32(i))
+		if w == "" {
+			if !errors.Is(err, ErrUnmatched) {
+				t.Errorf("row %d: expected unmatched, got %q", i, got)
+			}
+			continue
+		}
+		if err != nil || got != w {
+			t.Errorf("row %d: got %q (%v), want %q", i, got, err, w)
+		}
+	}
+
+	// Exact hops of a chained join have confidence 1
+	chained := NewChainedJoiner(joiner, &Joiner[string]{FromColumn: cities, ToColumn: cities})
+	if got := JoinConfidence(chained, 2); got < 0.7 || got >= 1 {
+		t.Errorf("chained confidence = %v, want the similarity of the fuzzy hop", got)
+	}
+}
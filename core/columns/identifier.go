@@ -0,0 +1,62 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package columns
+
+import (
+	"strings"
+	"unicode"
+)
+
+// IdentifierMinRows is the number of rows from which a column of distinct integers is
+// taken for an identifier. In smaller tables, measures are often distinct by chance.
+const IdentifierMinRows = 1000
+
+// IsIdentifier returns whether a column identifies records (record IDs, codes) rather
+// than measuring them, so that summing or averaging it is meaningless. A mark set with
+// ColumnDef.SetIdentifier wins. Otherwise only integer columns are identifiers, when
+// they have an entity type, value labels, an identifier name (id, order_id, orderId) or
+// distinct values in at least IdentifierMinRows rows.
+func IsIdentifier(col IDataColumn) bool {
+	def := col.ColumnDef()
+	if identifier, ok := def.Identifier(); ok {
+		return identifier
+	}
+	switch col.(type) {
+	case interface{ GetValue(uint32) (uint32, error) },
+		interface{ GetValue(uint32) (int64, error) },
+		interface{ GetValue(uint32) (uint64, error) }:
+	default:
+		return false
+	}
+	return def.EntityType() != "" || len(def.ValueLabels()) > 0 || isIdentifierName(def.Name()) ||
+		col.IsKey() && col.Length() >= IdentifierMinRows
+}
+
+// isIdentifierName returns whether a column name names an identifier: "id", or a name
+// ending in "_id", "-id", ".id", or "Id" or "ID" after a lower case letter
+func isIdentifierName(name string) bool {
+	lower := strings.ToLower(name)
+	if lower == "id" || strings.HasSuffix(lower, "_id") || strings.HasSuffix(lower, "-id") || strings.HasSuffix(lower, ".id") {
+		return true
+	}
+	if n := len(name); n > 2 && (strings.HasSuffix(name, "Id") || strings.HasSuffix(name, "ID")) {
+		return unicode.IsLower(rune(name[n-3]))
+	}
+	return false
+}
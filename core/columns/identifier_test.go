@@ -0,0 +1,68 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package columns
+
+import "testing"
+
+func TestIsIdentifier(t *testing.T) {
+	uint32Col := func(name, entityType string, rows int, distinct bool) *Uint32Column {
+		col := NewUint32Column(NewColumnDef(name, name, entityType))
+		for i := range rows {
+			if distinct {
+				col.Append(uint32(i))
+			} else {
+				col.Append(uint32(i % 10))
+			}
+		}
+		col.FinalizeColumn()
+		return col
+	}
+	labeled := uint32Col("exit_code", "", 20, false)
+	labeled.ColumnDef().SetValueLabels(map[string]string{"137": "OOMKilled"})
+	marked := uint32Col("order_id", "", 20, false)
+	marked.ColumnDef().SetIdentifier(false)
+	measure := NewFloat64Column(NewColumnDef("amount", "Amount", ""))
+	measure.Append(1.5)
+	markedMeasure := NewFloat64Column(NewColumnDef("score", "Score", ""))
+	markedMeasure.ColumnDef().SetIdentifier(true)
+
+	tests := []struct {
+		col  IDataColumn
+		want bool
+	}{
+		{uint32Col("amount", "", 20, false), false},
+		{uint32Col("customer", "customer", 20, false), true}, // Entity type
+		{labeled, true},
+		{uint32Col("id", "", 20, false), true},
+		{uint32Col("order_id", "", 20, false), true},
+		{uint32Col("orderId", "", 20, false), true},
+		{uint32Col("VALID", "", 20, false), false},
+		{uint32Col("paid", "", 20, false), false},
+		{uint32Col("amount", "", 20, true), false},               // Distinct by chance in a small table
+		{uint32Col("amount", "", IdentifierMinRows, true), true}, // Distinct in a large table
+		{marked, false},
+		{measure, false},
+		{markedMeasure, true},
+	}
+	for _, tt := range tests {
+		if got := IsIdentifier(tt.col); got != tt.want {
+			t.Errorf("IsIdentifier(%s, %d rows) = %v, want %v", tt.col.ColumnDef().Name(), tt.col.Length(), got, tt.want)
+		}
+	}
+}
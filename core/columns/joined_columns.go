@@ -45,6 +45,11 @@ func (c *JoinedStringColumn) ColumnDef() *ColumnDef {
 	return c.columnDef
 }
 
+// Joiner returns the joiner mapping rows of the view to rows of the source column
+func (c *JoinedStringColumn) Joiner() IJoiner {
+	return c.joiner
+}
+
 func (c *JoinedStringColumn) CreateJoinedColumn(columnDef *ColumnDef, joiner IJoiner) IJoinedDataColumn {
 	// the joiner is based on the columns on which the join is based
 	return nil
@@ -137,6 +142,11 @@ func (c *JoinedUint32Column) ColumnDef() *ColumnDef {
 	return c.columnDef
 }
 
+// Joiner returns the joiner mapping rows of the view to rows of the source column
+func (c *JoinedUint32Column) Joiner() IJoiner {
+	return c.joiner
+}
+
 func (c *JoinedUint32Column) Length() int {
 	// The length is the same as the source column
 	return c.sourceColumn.Length()
@@ -222,6 +232,11 @@ func (c *JoinedDatetimeColumn) ColumnDef() *ColumnDef {
 	return c.columnDef
 }
 
+// Joiner returns the joiner mapping rows of the view to rows of the source column
+func (c *JoinedDatetimeColumn) Joiner() IJoiner {
+	return c.joiner
+}
+
 func (c *JoinedDatetimeColumn) CreateJoinedColumn(columnDef *ColumnDef, joiner IJoiner) IJoinedDataColumn {
 	return nil
 }
@@ -300,6 +315,11 @@ func (c *JoinedDurationColumn) ColumnDef() *ColumnDef {
 	return c.columnDef
 }
 
+// Joiner returns the joiner mapping rows of the view to rows of the source column
+func (c *JoinedDurationColumn) Joiner() IJoiner {
+	return c.joiner
+}
+
 func (c *JoinedDurationColumn) CreateJoinedColumn(columnDef *ColumnDef, joiner IJoiner) IJoinedDataColumn {
 	return nil
 }
@@ -378,6 +398,11 @@ func (c *JoinedBoolColumn) ColumnDef() *ColumnDef {
 	return c.columnDef
 }
 
+// Joiner returns the joiner mapping rows of the view to rows of the source column
+func (c *JoinedBoolColumn) Joiner() IJoiner {
+	return c.joiner
+}
+
 func (c *JoinedBoolColumn) CreateJoinedColumn(columnDef *ColumnDef, joiner IJoiner) IJoinedDataColumn {
 	return nil
 }
@@ -455,6 +480,11 @@ func (c *JoinedFloat64Column) ColumnDef() *ColumnDef {
 	return c.columnDef
 }
 
+// Joiner returns the joiner mapping rows of the view to rows of the source column
+func (c *JoinedFloat64Column) Joiner() IJoiner {
+	return c.joiner
+}
+
 func (c *JoinedFloat64Column) CreateJoinedColumn(columnDef *ColumnDef, joiner IJoiner) IJoinedDataColumn {
 	return nil
 }
@@ -547,6 +577,11 @@ func (c *JoinedInt64Column) ColumnDef() *ColumnDef {
 	return c.columnDef
 }
 
+// Joiner returns the joiner mapping rows of the view to rows of the source column
+func (c *JoinedInt64Column) Joiner() IJoiner {
+	return c.joiner
+}
+
 func (c *JoinedInt64Column) CreateJoinedColumn(columnDef *ColumnDef, joiner IJoiner) IJoinedDataColumn {
 	return nil
 }
@@ -628,6 +663,11 @@ func (c *JoinedUint64Column) ColumnDef() *ColumnDef {
 	return c.columnDef
 }
 
+// Joiner returns the joiner mapping rows of the view to rows of the source column
+func (c *JoinedUint64Column) Joiner() IJoiner {
+	return c.joiner
+}
+
 func (c *JoinedUint64Column) CreateJoinedColumn(columnDef *ColumnDef, joiner IJoiner) IJoinedDataColumn {
 	return nil
 }
@@ -23,11 +23,14 @@ import (
 )
 
 // StringColumn is optimized for high-cardinality string data where most values are distinct.
-// It stores strings directly without key mapping overhead.
+// It stores strings directly without key mapping overhead, unless FinalizeColumn finds
+// few enough distinct values to dictionary-encode the column.
 type StringColumn struct {
 	// IDataColumnT[string]
 	columnDef  *ColumnDef
 	data       []string
+	dict       []string // Distinct values, replacing data when dictionary-encoded
+	codes      []uint32 // Index into dict of each row
 	isKey      bool
 	valueIndex map[string]int // value -> rowIndex, only populated if isKey is true
 }
@@ -46,22 +49,45 @@ func (c *StringColumn) CreateJoinedColumn(columnDef *ColumnDef, joiner IJoiner)
 }
 
 func (c *StringColumn) Append(value string) {
+	c.decode()
 	c.data = append(c.data, value)
 }
 
 func (c *StringColumn) Length() int {
+	if c.codes != nil {
+		return len(c.codes)
+	}
 	return len(c.data)
 }
 
+// value returns the value at row i, which must be in bounds
+func (c *StringColumn) value(i uint32) string {
+	if c.codes != nil {
+		return c.dict[c.codes[i]]
+	}
+	return c.data[i]
+}
+
+// decode restores the plain storage of a dictionary-encoded column
+func (c *StringColumn) decode() {
+	if c.codes != nil {
+		c.data = make([]string, len(c.codes))
+		for i, code := range c.codes {
+			c.data[i] = c.dict[code]
+		}
+		c.dict, c.codes = nil, nil
+	}
+}
+
 func (c *StringColumn) ColumnDef() *ColumnDef {
 	return c.columnDef
 }
 
 func (c *StringColumn) GetValue(i uint32) (string, error) {
-	if i >= uint32(len(c.data)) {
-		return "", fmt.Errorf("index %d out of bounds (length: %d)", i, len(c.data))
+	if i >= uint32(c.Length()) {
+		return "", fmt.Errorf("index %d out of bounds (length: %d)", i, c.Length())
 	}
-	return c.data[i], nil
+	return c.value(i), nil
 }
 
 func (c *StringColumn) GetIndex(v string) (uint32, error) {
@@ -73,15 +99,28 @@ func (c *StringColumn) GetIndex(v string) (uint32, error) {
 
 // GetString returns the string value at index i
 func (c *StringColumn) GetString(i uint32) (string, error) {
-	if i >= uint32(len(c.data)) {
-		return "", fmt.Errorf("index %d out of bounds (length: %d)", i, len(c.data))
+	if i >= uint32(c.Length()) {
+		return "", fmt.Errorf("index %d out of bounds (length: %d)", i, c.Length())
 	}
-	return c.data[i], nil
+	return c.value(i), nil
 }
 
 // Filter returns indices where the predicate returns true
 func (c *StringColumn) Filter(predicate func(string) bool) []int {
 	indices := make([]int, 0)
+	if c.codes != nil {
+		// Evaluate the predicate once per distinct value
+		matches := make([]bool, len(c.dict))
+		for code, v := range c.dict {
+			matches[code] = predicate(v)
+		}
+		for i, code := range c.codes {
+			if matches[code] {
+				indices = append(indices, i)
+			}
+		}
+		return indices
+	}
 	for i, v := range c.data {
 		if predicate(v) {
 			indices = append(indices, i)
@@ -98,6 +137,8 @@ func (c *StringColumn) IsKey() bool {
 // Finalizeolumn should be called after all data has been added to detect uniqueness
 // and build indexes if the column contains unique values
 func (c *StringColumn) FinalizeColumn() {
+	c.decode()
+
 	// Build a temporary index to check for uniqueness
 	tempIndex := make(map[string]int)
 	isUnique := true
@@ -119,6 +160,14 @@ func (c *StringColumn) FinalizeColumn() {
 	} else {
 		c.valueIndex = nil
 	}
+
+	// Dictionary-encode repeated values when it saves at least a quarter of the memory
+	if !isUnique && len(c.data) >= minEncodedRows {
+		dict, codes := dictionaryEncode(c.data)
+		if 4*(stringBytes(dict)+sliceBytes(len(codes), 4)) <= 3*stringBytes(c.data) {
+			c.dict, c.codes, c.data = dict, codes, nil
+		}
+	}
 }
 
 // type StringJoinedColumn struct {
@@ -138,6 +187,19 @@ func (c *StringColumn) GroupIndices(indices []uint32, columnView *ColumnView) (m
 	// for now assume just default grouping by value
 	groupedIndices := map[uint32][]uint32{}
 	valueToGroupKey := map[string]uint32{}
+	if c.codes != nil {
+		// Group by code, numbering the groups in order of first appearance like values
+		groupKeyOfCode := make([]uint32, len(c.dict)) // group key + 1, 0 if none yet
+		for _, i := range indices {
+			code := c.codes[i]
+			if groupKeyOfCode[code] == 0 {
+				groupKeyOfCode[code] = uint32(len(groupedIndices)) + 1
+			}
+			groupKey := groupKeyOfCode[code] - 1
+			groupedIndices[groupKey] = append(groupedIndices[groupKey], i)
+		}
+		return groupedIndices, nil
+	}
 	for _, i := range indices {
 		value := c.data[i]
 		if groupKey, ok := valueToGroupKey[value]; ok {
@@ -24,10 +24,12 @@ import (
 
 // Uint32Column is optimized for uint32 numeric data.
 // It stores uint32 values directly without key mapping overhead.
+// FinalizeColumn delta-encodes large columns whose nearby rows hold close values.
 type Uint32Column struct {
 	// IDataColumnT[uint32]
 	columnDef  *ColumnDef
 	data       []uint32
+	delta      *deltaValues[uint32] // Replaces data when delta-encoded
 	isKey      bool
 	valueIndex map[uint32]int // value -> rowIndex, only populated if isKey is true
 }
@@ -46,13 +48,33 @@ func (c *Uint32Column) CreateJoinedColumn(columnDef *ColumnDef, joiner IJoiner)
 }
 
 func (c *Uint32Column) Append(value uint32) {
+	c.decode()
 	c.data = append(c.data, value)
 }
 
 func (c *Uint32Column) Length() int {
+	if c.delta != nil {
+		return c.delta.n
+	}
 	return len(c.data)
 }
 
+// value returns the value at row i, which must be in bounds
+func (c *Uint32Column) value(i uint32) uint32 {
+	if c.delta != nil {
+		return c.delta.get(i)
+	}
+	return c.data[i]
+}
+
+// decode restores the plain storage of a delta-encoded column
+func (c *Uint32Column) decode() {
+	if c.delta != nil {
+		c.data = c.delta.decode()
+		c.delta = nil
+	}
+}
+
 func (c *Uint32Column) ColumnDef() *ColumnDef {
 	return c.columnDef
 }
@@ -66,17 +88,17 @@ func (c *Uint32Column) ColumnDef() *ColumnDef {
 
 // GetString returns the string representation of the value at index i
 func (c *Uint32Column) GetString(i uint32) (string, error) {
-	if i >= uint32(len(c.data)) {
-		return "", fmt.Errorf("index %d out of bounds (length: %d)", i, len(c.data))
+	if i >= uint32(c.Length()) {
+		return "", fmt.Errorf("index %d out of bounds (length: %d)", i, c.Length())
 	}
-	return fmt.Sprintf("%d", c.data[i]), nil
+	return fmt.Sprintf("%d", c.value(i)), nil
 }
 
 func (c *Uint32Column) GetValue(i uint32) (uint32, error) {
-	if i >= uint32(len(c.data)) {
-		return 0, fmt.Errorf("index %d out of bounds (length: %d)", i, len(c.data))
+	if i >= uint32(c.Length()) {
+		return 0, fmt.Errorf("index %d out of bounds (length: %d)", i, c.Length())
 	}
-	return c.data[i], nil
+	return c.value(i), nil
 }
 
 // GetIndex returns the index of the given value
@@ -90,8 +112,8 @@ func (c *Uint32Column) GetIndex(v uint32) (uint32, error) {
 // Filter returns indices where the predicate returns true
 func (c *Uint32Column) Filter(predicate func(uint32) bool) []int {
 	indices := make([]int, 0)
-	for i, v := range c.data {
-		if predicate(v) {
+	for i := range c.Length() {
+		if predicate(c.value(uint32(i))) {
 			indices = append(indices, i)
 		}
 	}
@@ -106,6 +128,8 @@ func (c *Uint32Column) IsKey() bool {
 // FinalizeColumn should be called after all data has been added to detect uniqueness
 // and build indexes if the column contains unique values
 func (c *Uint32Column) FinalizeColumn() {
+	c.decode()
+
 	// Build a temporary index to check for uniqueness
 	tempIndex := make(map[uint32]int)
 	isUnique := true
@@ -127,14 +151,20 @@ func (c *Uint32Column) FinalizeColumn() {
 	} else {
 		c.valueIndex = nil
 	}
+
+	if len(c.data) >= minEncodedRows {
+		if delta := encodeDelta(c.data); delta != nil {
+			c.delta, c.data = delta, nil
+		}
+	}
 }
 
 func (c *Uint32Column) GroupIndices(indices []uint32, columnView *ColumnView) (map[uint32][]uint32, []uint32) {
 	// Group indices by their uint32 value
 	groupedIndices := map[uint32][]uint32{}
 	for _, i := range indices {
-		if i < uint32(len(c.data)) {
-			value := c.data[i]
+		if i < uint32(c.Length()) {
+			value := c.value(i)
 			groupedIndices[value] = append(groupedIndices[value], i)
 		}
 	}
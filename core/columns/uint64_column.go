@@ -24,9 +24,11 @@ import (
 
 // Uint64Column is optimized for uint64 numeric data.
 // It stores uint64 values directly without key mapping overhead.
+// FinalizeColumn delta-encodes large columns whose nearby rows hold close values.
 type Uint64Column struct {
 	columnDef  *ColumnDef
 	data       []uint64
+	delta      *deltaValues[uint64] // Replaces data when delta-encoded
 	isKey      bool
 	valueIndex map[uint64]int // value -> rowIndex, only populated if isKey is true
 }
@@ -44,30 +46,50 @@ func (c *Uint64Column) CreateJoinedColumn(columnDef *ColumnDef, joiner IJoiner)
 }
 
 func (c *Uint64Column) Append(value uint64) {
+	c.decode()
 	c.data = append(c.data, value)
 }
 
 func (c *Uint64Column) Length() int {
+	if c.delta != nil {
+		return c.delta.n
+	}
 	return len(c.data)
 }
 
+// value returns the value at row i, which must be in bounds
+func (c *Uint64Column) value(i uint32) uint64 {
+	if c.delta != nil {
+		return c.delta.get(i)
+	}
+	return c.data[i]
+}
+
+// decode restores the plain storage of a delta-encoded column
+func (c *Uint64Column) decode() {
+	if c.delta != nil {
+		c.data = c.delta.decode()
+		c.delta = nil
+	}
+}
+
 func (c *Uint64Column) ColumnDef() *ColumnDef {
 	return c.columnDef
 }
 
 // GetString returns the string representation of the value at index i
 func (c *Uint64Column) GetString(i uint32) (string, error) {
-	if i >= uint32(len(c.data)) {
-		return "", fmt.Errorf("index %d out of bounds (length: %d)", i, len(c.data))
+	if i >= uint32(c.Length()) {
+		return "", fmt.Errorf("index %d out of bounds (length: %d)", i, c.Length())
 	}
-	return fmt.Sprintf("%d", c.data[i]), nil
+	return fmt.Sprintf("%d", c.value(i)), nil
 }
 
 func (c *Uint64Column) GetValue(i uint32) (uint64, error) {
-	if i >= uint32(len(c.data)) {
-		return 0, fmt.Errorf("index %d out of bounds (length: %d)", i, len(c.data))
+	if i >= uint32(c.Length()) {
+		return 0, fmt.Errorf("index %d out of bounds (length: %d)", i, c.Length())
 	}
-	return c.data[i], nil
+	return c.value(i), nil
 }
 
 // GetIndex returns the index of the given value
@@ -81,8 +103,8 @@ func (c *Uint64Column) GetIndex(v uint64) (uint32, error) {
 // Filter returns indices where the predicate returns true
 func (c *Uint64Column) Filter(predicate func(uint64) bool) []int {
 	indices := make([]int, 0)
-	for i, v := range c.data {
-		if predicate(v) {
+	for i := range c.Length() {
+		if predicate(c.value(uint32(i))) {
 			indices = append(indices, i)
 		}
 	}
@@ -97,6 +119,8 @@ func (c *Uint64Column) IsKey() bool {
 // FinalizeColumn should be called after all data has been added to detect uniqueness
 // and build indexes if the column contains unique values
 func (c *Uint64Column) FinalizeColumn() {
+	c.decode()
+
 	// Build a temporary index to check for uniqueness
 	tempIndex := make(map[uint64]int)
 	isUnique := true
@@ -118,6 +142,12 @@ func (c *Uint64Column) FinalizeColumn() {
 	} else {
 		c.valueIndex = nil
 	}
+
+	if len(c.data) >= minEncodedRows {
+		if delta := encodeDelta(c.data); delta != nil {
+			c.delta, c.data = delta, nil
+		}
+	}
 }
 
 func (c *Uint64Column) GroupIndices(indices []uint32, columnView *ColumnView) (map[uint32][]uint32, []uint32) {
@@ -125,10 +155,10 @@ func (c *Uint64Column) GroupIndices(indices []uint32, columnView *ColumnView) (m
 	valueToGroupKey := map[uint64]uint32{}
 
 	for _, i := range indices {
-		if int(i) >= len(c.data) {
+		if int(i) >= c.Length() {
 			continue
 		}
-		value := c.data[i]
+		value := c.value(i)
 
 		if groupKey, ok := valueToGroupKey[value]; ok {
 			groupedIndices[groupKey] = append(groupedIndices[groupKey], i)
@@ -0,0 +1,86 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package errs defines the error kinds shared across the core packages.
+// Errors are created with New or Wrap so that callers can branch on the
+// kind with errors.Is while the message shown to users stays unchanged.
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error kinds. Use errors.Is to test an error against one of these.
+var (
+	// ErrUnknownColumn is returned when a column referenced by a query,
+	// filter, join or expression does not exist.
+	ErrUnknownColumn = errors.New("unknown column")
+
+	// ErrUnknownTable is returned when a referenced table or data source does
+	// not exist.
+	ErrUnknownTable = errors.New("unknown table")
+
+	// ErrJoinNotFound is returned when a join path references a join that
+	// has not been discovered between the two columns.
+	ErrJoinNotFound = errors.New("join not found")
+
+	// ErrTypeMismatch is returned when a value or column has a different
+	// type than the operation requires.
+	ErrTypeMismatch = errors.New("type mismatch")
+
+	// ErrInvalidQuery is returned when a query is malformed or uses features
+	// the engine does not support.
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrSourceUnavailable is returned when a data source cannot be read
+	// or has no loader able to read it.
+	ErrSourceUnavailable = errors.New("data source unavailable")
+)
+
+// Error is an error of a given kind with a user-facing message.
+type Error struct {
+	Kind    error  // One of the error kinds above
+	Message string // User-facing message, without the kind prefix
+	Err     error  // Underlying cause (can be nil)
+}
+
+// New creates an error of the given kind with a formatted message.
+func New(kind error, format string, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap creates an error of the given kind with a formatted message and an underlying cause.
+func Wrap(kind error, err error, format string, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// Error returns the message, followed by the cause if there is one.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns both the kind and the cause so errors.Is and errors.As match either.
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
@@ -0,0 +1,51 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errs
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestNewMatchesKind(t *testing.T) {
+	err := New(ErrUnknownColumn, "column '%s' not found", "price")
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expected error to match ErrUnknownColumn")
+	}
+	if errors.Is(err, ErrUnknownTable) {
+		t.Errorf("expected error not to match ErrUnknownTable")
+	}
+	if err.Error() != "column 'price' not found" {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+}
+
+func TestWrapMatchesKindAndCause(t *testing.T) {
+	err := Wrap(ErrSourceUnavailable, io.ErrUnexpectedEOF, "failed to read CSV file")
+	if !errors.Is(err, ErrSourceUnavailable) {
+		t.Errorf("expected error to match ErrSourceUnavailable")
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected error to match its cause")
+	}
+	if err.Error() != "failed to read CSV file: unexpected EOF" {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+}
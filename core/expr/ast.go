@@ -35,6 +35,7 @@ func (n *StringLit) node() {}
 // Ident represents an identifier (column name)
 type Ident struct {
 	Name string
+	Pos  int // Byte offset in the source
 }
 
 func (n *Ident) node() {}
@@ -44,6 +45,7 @@ type BinaryOp struct {
 	Op    TokenType
 	Left  Node
 	Right Node
+	Pos   int // Byte offset of the operator
 }
 
 func (n *BinaryOp) node() {}
@@ -52,6 +54,7 @@ func (n *BinaryOp) node() {}
 type UnaryOp struct {
 	Op   TokenType
 	Expr Node
+	Pos  int // Byte offset of the operator
 }
 
 func (n *UnaryOp) node() {}
@@ -60,6 +63,7 @@ func (n *UnaryOp) node() {}
 type CallExpr struct {
 	Func string
 	Args []Node
+	Pos  int // Byte offset of the function or method name
 }
 
 func (n *CallExpr) node() {}
@@ -68,6 +72,7 @@ func (n *CallExpr) node() {}
 type AttrAccess struct {
 	Obj  Node
 	Attr string
+	Pos  int // Byte offset of the attribute name
 }
 
 func (n *AttrAccess) node() {}
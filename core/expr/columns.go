@@ -0,0 +1,92 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+*/
+
+package expr
+
+import (
+	"strconv"
+
+	"github.com/google/taxinomia/core/columns"
+)
+
+// ColumnValue returns the value of a column at a row as an expression value.
+// Datetime and duration columns keep their type so that expressions can compare
+// and subtract them; other columns are read as strings and parsed as an int,
+// then a float, falling back to the string itself.
+func ColumnValue(col columns.IDataColumn, rowIndex uint32) (Value, error) {
+	switch dtCol := col.(type) {
+	case *columns.DatetimeColumn:
+		t, err := dtCol.GetValue(rowIndex)
+		if err != nil {
+			return NilValue(), err
+		}
+		return NewDatetime(t.UnixNano()), nil
+	case *columns.JoinedDatetimeColumn:
+		t, err := dtCol.GetValue(rowIndex)
+		if err != nil {
+			return NilValue(), err
+		}
+		return NewDatetime(t.UnixNano()), nil
+	case *columns.ComputedDatetimeColumn:
+		nanos, err := dtCol.GetValue(rowIndex)
+		if err != nil {
+			return NilValue(), err
+		}
+		return NewDatetime(nanos), nil
+	case *columns.DurationColumn:
+		nanos, err := dtCol.Nanoseconds(rowIndex)
+		if err != nil {
+			return NilValue(), err
+		}
+		return NewDuration(nanos), nil
+	case *columns.JoinedDurationColumn:
+		nanos, err := dtCol.Nanoseconds(rowIndex)
+		if err != nil {
+			return NilValue(), err
+		}
+		return NewDuration(nanos), nil
+	case *columns.ComputedDurationColumn:
+		nanos, err := dtCol.Nanoseconds(rowIndex)
+		if err != nil {
+			return NilValue(), err
+		}
+		return NewDuration(nanos), nil
+	}
+
+	strVal, err := col.GetString(rowIndex)
+	if err != nil {
+		return NilValue(), err
+	}
+	// Try to parse as int first, then float
+	if intVal, err := strconv.ParseInt(strVal, 10, 64); err == nil {
+		return NewInt(intVal), nil
+	}
+	if numVal, err := strconv.ParseFloat(strVal, 64); err == nil {
+		return NewFloat(numVal), nil
+	}
+	return NewString(strVal), nil
+}
+
+// ColumnType returns the type a column's values have in expressions, matching
+// ColumnValue: datetime and duration columns keep their type, numeric columns are
+// numbers, and boolean columns are read as the strings "true" and "false". String
+// columns are TypeUnknown, as their values are parsed as numbers when they can be.
+func ColumnType(col columns.IDataColumn) ExprType {
+	switch col.(type) {
+	case *columns.DatetimeColumn, *columns.JoinedDatetimeColumn, *columns.ComputedDatetimeColumn:
+		return TypeDatetime
+	case *columns.DurationColumn, *columns.JoinedDurationColumn, *columns.ComputedDurationColumn:
+		return TypeDuration
+	case *columns.Int64Column, *columns.JoinedInt64Column, *columns.Uint32Column, *columns.JoinedUint32Column,
+		*columns.Uint64Column, *columns.JoinedUint64Column:
+		return TypeInt
+	case *columns.Float64Column, *columns.JoinedFloat64Column:
+		return TypeFloat
+	case *columns.BoolColumn, *columns.JoinedBoolColumn, *columns.ComputedBoolColumn:
+		return TypeString
+	}
+	return TypeUnknown
+}
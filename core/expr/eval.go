@@ -12,6 +12,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/google/taxinomia/core/errs"
 )
 
 // Value represents a runtime value
@@ -240,7 +242,7 @@ func (e *Evaluator) eval(node Node, row uint32) (Value, error) {
 			if val.IsFloat() {
 				return NewFloat(-val.AsFloat()), nil
 			}
-			return NilValue(), fmt.Errorf("cannot negate non-number")
+			return NilValue(), errs.New(errs.ErrTypeMismatch, "cannot negate non-number")
 		case TOKEN_NOT:
 			return NewBool(!val.AsBool()), nil
 		}
@@ -385,7 +387,7 @@ func (e *Evaluator) evalBinaryOp(op TokenType, left, right Value) (Value, error)
 				return NewBool(l >= r), nil
 			}
 		}
-		return NilValue(), fmt.Errorf("cannot compare %v and %v", left.typ, right.typ)
+		return NilValue(), errs.New(errs.ErrTypeMismatch, "cannot compare %v and %v", left.typ, right.typ)
 	}
 
 	// Handle datetime arithmetic
@@ -423,7 +425,7 @@ func (e *Evaluator) evalBinaryOp(op TokenType, left, right Value) (Value, error)
 
 	// Arithmetic operators require numbers
 	if !left.IsNumeric() || !right.IsNumeric() {
-		return NilValue(), fmt.Errorf("arithmetic operations require numbers, got %v and %v", left.typ, right.typ)
+		return NilValue(), errs.New(errs.ErrTypeMismatch, "arithmetic operations require numbers, got %v and %v", left.typ, right.typ)
 	}
 
 	// If both are ints, preserve int type for most operations
@@ -521,7 +523,7 @@ func (e *Evaluator) evalFunc(name string, args []Value) (Value, error) {
 		if args[0].IsString() {
 			return NewInt(int64(len(args[0].AsString()))), nil
 		}
-		return NilValue(), fmt.Errorf("len() argument must be string")
+		return NilValue(), errs.New(errs.ErrTypeMismatch, "len() argument must be string")
 
 	case "str":
 		if len(args) != 1 {
@@ -547,11 +549,11 @@ func (e *Evaluator) evalFunc(name string, args []Value) (Value, error) {
 			// Fall back to float parsing and truncating
 			n, err := strconv.ParseFloat(args[0].AsString(), 64)
 			if err != nil {
-				return NilValue(), fmt.Errorf("cannot convert '%s' to int", args[0].AsString())
+				return NilValue(), errs.New(errs.ErrTypeMismatch, "cannot convert '%s' to int", args[0].AsString())
 			}
 			return NewInt(int64(n)), nil
 		}
-		return NilValue(), fmt.Errorf("int() argument must be number or string")
+		return NilValue(), errs.New(errs.ErrTypeMismatch, "int() argument must be number or string")
 
 	case "float":
 		if len(args) != 1 {
@@ -566,11 +568,11 @@ func (e *Evaluator) evalFunc(name string, args []Value) (Value, error) {
 		if args[0].IsString() {
 			n, err := strconv.ParseFloat(args[0].AsString(), 64)
 			if err != nil {
-				return NilValue(), fmt.Errorf("cannot convert '%s' to float", args[0].AsString())
+				return NilValue(), errs.New(errs.ErrTypeMismatch, "cannot convert '%s' to float", args[0].AsString())
 			}
 			return NewFloat(n), nil
 		}
-		return NilValue(), fmt.Errorf("float() argument must be number or string")
+		return NilValue(), errs.New(errs.ErrTypeMismatch, "float() argument must be number or string")
 
 	case "abs":
 		if len(args) != 1 {
@@ -586,19 +588,19 @@ func (e *Evaluator) evalFunc(name string, args []Value) (Value, error) {
 		if args[0].IsFloat() {
 			return NewFloat(math.Abs(args[0].AsFloat())), nil
 		}
-		return NilValue(), fmt.Errorf("abs() argument must be number")
+		return NilValue(), errs.New(errs.ErrTypeMismatch, "abs() argument must be number")
 
 	case "round":
 		if len(args) < 1 || len(args) > 2 {
 			return NilValue(), fmt.Errorf("round() takes 1 or 2 arguments")
 		}
 		if !args[0].IsNumeric() {
-			return NilValue(), fmt.Errorf("round() first argument must be number")
+			return NilValue(), errs.New(errs.ErrTypeMismatch, "round() first argument must be number")
 		}
 		digits := 0.0
 		if len(args) == 2 {
 			if !args[1].IsNumeric() {
-				return NilValue(), fmt.Errorf("round() second argument must be number")
+				return NilValue(), errs.New(errs.ErrTypeMismatch, "round() second argument must be number")
 			}
 			digits = args[1].AsFloat()
 		}
@@ -641,6 +643,22 @@ func (e *Evaluator) evalFunc(name string, args []Value) (Value, error) {
 		}
 		return maxVal, nil
 
+	case "pct_diff":
+		if len(args) != 2 {
+			return NilValue(), fmt.Errorf("pct_diff() takes 2 arguments")
+		}
+		if !args[0].IsNumeric() || !args[1].IsNumeric() {
+			return NilValue(), errs.New(errs.ErrTypeMismatch, "pct_diff() arguments must be numbers")
+		}
+		a, b := args[0].AsFloat(), args[1].AsFloat()
+		if b == 0 {
+			if a == 0 {
+				return NewFloat(0), nil
+			}
+			return NewFloat(math.Inf(int(math.Copysign(1, a)))), nil
+		}
+		return NewFloat((a - b) / math.Abs(b) * 100), nil
+
 	case "concat":
 		var sb strings.Builder
 		for _, arg := range args {
@@ -981,6 +999,17 @@ func (e *Evaluator) evalFunc(name string, args []Value) (Value, error) {
 		}
 		return NewString(formatDurationCompact(d)), nil
 
+	case "format_time":
+		// format_time(datetime, layout) - formats a UTC datetime with a Go time layout
+		if len(args) != 2 {
+			return NilValue(), fmt.Errorf("format_time() takes 2 arguments")
+		}
+		t, err := parseDatetimeValue(args[0])
+		if err != nil {
+			return NilValue(), fmt.Errorf("format_time(): %w", err)
+		}
+		return NewString(t.UTC().Format(args[1].AsString())), nil
+
 	default:
 		return NilValue(), fmt.Errorf("unknown function: %s", name)
 	}
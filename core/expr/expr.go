@@ -19,7 +19,10 @@ It supports:
 */
 package expr
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 // Expression represents a compiled expression ready for evaluation
 type Expression struct {
@@ -60,20 +63,28 @@ func (e *Expression) Bind(getColumn ColumnGetter) *BoundExpression {
 // The getColumnType function returns the type of each column.
 // Returns an error if type checking fails.
 func (e *Expression) BindWithTypes(getColumn ColumnGetter, getColumnType ColumnTypeGetter) (*BoundExpression, error) {
-	// Perform type checking
+	if _, err := e.Check(getColumnType); err != nil {
+		return nil, err
+	}
+
+	return &BoundExpression{
+		expr:      e,
+		evaluator: NewEvaluator(e.ast, getColumn),
+	}, nil
+}
+
+// Check type checks the expression against the column types without binding it,
+// and returns its result type. Columns of unknown type are not checked.
+func (e *Expression) Check(getColumnType ColumnTypeGetter) (ExprType, error) {
 	tc := NewTypeChecker(getColumnType)
 	resultType, err := tc.Check(e.ast)
 	if err != nil {
-		return nil, fmt.Errorf("type error: %w", err)
+		return TypeUnknown, fmt.Errorf("type error: %w", err)
 	}
 
 	e.resultType = resultType
 	e.typeChecked = true
-
-	return &BoundExpression{
-		expr:      e,
-		evaluator: NewEvaluator(e.ast, getColumn),
-	}, nil
+	return resultType, nil
 }
 
 // Source returns the original expression source
@@ -81,6 +92,69 @@ func (e *Expression) Source() string {
 	return e.source
 }
 
+// Columns returns the names of the columns the expression references, sorted and without duplicates
+func (e *Expression) Columns() []string {
+	seen := make(map[string]bool)
+	var walk func(n Node)
+	walk = func(n Node) {
+		switch n := n.(type) {
+		case *Ident:
+			seen[n.Name] = true
+		case *UnaryOp:
+			walk(n.Expr)
+		case *BinaryOp:
+			walk(n.Left)
+			walk(n.Right)
+		case *CallExpr:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *AttrAccess:
+			walk(n.Obj)
+		}
+	}
+	walk(e.ast)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ColumnRef is a reference to a column in the expression source
+type ColumnRef struct {
+	Name string
+	Pos  int // Byte offset in the source
+}
+
+// ColumnRefs returns every reference to a column, in source order
+func (e *Expression) ColumnRefs() []ColumnRef {
+	var refs []ColumnRef
+	var walk func(n Node)
+	walk = func(n Node) {
+		switch n := n.(type) {
+		case *Ident:
+			refs = append(refs, ColumnRef{Name: n.Name, Pos: n.Pos})
+		case *UnaryOp:
+			walk(n.Expr)
+		case *BinaryOp:
+			walk(n.Left)
+			walk(n.Right)
+		case *CallExpr:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *AttrAccess:
+			walk(n.Obj)
+		}
+	}
+	walk(e.ast)
+	sort.SliceStable(refs, func(i, j int) bool { return refs[i].Pos < refs[j].Pos })
+	return refs
+}
+
 // ResultType returns the result type after type checking.
 // Returns TypeUnknown if type checking has not been performed.
 func (e *Expression) ResultType() ExprType {
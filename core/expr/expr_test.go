@@ -7,8 +7,11 @@ Copyright 2024 The Taxinomia Authors
 package expr
 
 import (
+	"errors"
 	"fmt"
 	"testing"
+
+	"github.com/google/taxinomia/core/errs"
 )
 
 // Test data for benchmarks
@@ -193,6 +196,96 @@ func TestColumnAccess(t *testing.T) {
 	}
 }
 
+func TestExpressionColumns(t *testing.T) {
+	compiled, err := Compile(`round(price * qty, 2) + price + len(name.upper()) + "x"`)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	got := compiled.Columns()
+	want := []string{"name", "price", "qty"}
+	if len(got) != len(want) {
+		t.Fatalf("expected columns %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected columns %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestTypeMismatchError(t *testing.T) {
+	compiled, err := Compile("name - qty")
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	bound := compiled.Bind(makeColumnGetter(0))
+
+	_, err = bound.Eval(0)
+	if err == nil {
+		t.Fatal("expected error subtracting a number from a string")
+	}
+	if !errors.Is(err, errs.ErrTypeMismatch) {
+		t.Errorf("expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{"price = 2", 6},
+		{"price * (qty + 1", 16},
+		{"price qty", 6},
+		{"'open", 0},
+		{"price +", 7},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.expr)
+		if err == nil {
+			t.Errorf("Compile(%q): expected an error", tt.expr)
+			continue
+		}
+		if got := Position(err); got != tt.pos {
+			t.Errorf("Compile(%q): error %q at position %d, want %d", tt.expr, err, got, tt.pos)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	types := map[string]ExprType{"price": TypeFloat, "qty": TypeInt, "name": TypeString}
+	getType := func(name string) ExprType { return types[name] }
+
+	compiled, err := Compile("price * qty > 10")
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	if got, err := compiled.Check(getType); err != nil || got != TypeBool {
+		t.Errorf("Check = %v, %v; want bool", got, err)
+	}
+
+	compiled, err = Compile("qty * 2 - upper(name) + price")
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	_, err = compiled.Check(getType)
+	if !errors.Is(err, errs.ErrTypeMismatch) {
+		t.Fatalf("expected ErrTypeMismatch, got %v", err)
+	}
+	if got := Position(err); got != 8 {
+		t.Errorf("type error at position %d, want 8 (the '-')", got)
+	}
+
+	var refs []string
+	for _, ref := range compiled.ColumnRefs() {
+		refs = append(refs, fmt.Sprintf("%s@%d", ref.Name, ref.Pos))
+	}
+	if got, want := fmt.Sprint(refs), "[qty@0 name@16 price@24]"; got != want {
+		t.Errorf("ColumnRefs = %s, want %s", got, want)
+	}
+}
+
 func TestFunctions(t *testing.T) {
 	tests := []struct {
 		expr     string
@@ -208,6 +301,11 @@ func TestFunctions(t *testing.T) {
 		{`lower("HELLO")`, "hello"},
 		{"int(3.9)", "3"},
 		{"float(5)", "5"},
+		{"pct_diff(15, 10)", "50"},
+		{"pct_diff(5, -10)", "150"},
+		{"pct_diff(0, 0)", "0"},
+		{"pct_diff(3, 0)", "+Inf"},
+		{`format_time("2024-03-15", "2006-01 (Jan)")`, "2024-03 (Mar)"},
 	}
 
 	for _, tt := range tests {
@@ -118,14 +118,14 @@ func (l *Lexer) NextToken() (Token, error) {
 			l.advance()
 			return Token{Type: TOKEN_EQ, Value: "==", Pos: startPos}, nil
 		}
-		return Token{}, fmt.Errorf("unexpected '=' at position %d, did you mean '=='?", startPos)
+		return Token{}, atPos(startPos, fmt.Errorf("unexpected '=' at position %d, did you mean '=='?", startPos))
 	case '!':
 		l.advance()
 		if l.ch == '=' {
 			l.advance()
 			return Token{Type: TOKEN_NE, Value: "!=", Pos: startPos}, nil
 		}
-		return Token{}, fmt.Errorf("unexpected '!' at position %d", startPos)
+		return Token{}, atPos(startPos, fmt.Errorf("unexpected '!' at position %d", startPos))
 	case '<':
 		l.advance()
 		if l.ch == '=' {
@@ -142,7 +142,7 @@ func (l *Lexer) NextToken() (Token, error) {
 		return Token{Type: TOKEN_GT, Value: ">", Pos: startPos}, nil
 	}
 
-	return Token{}, fmt.Errorf("unexpected character '%c' at position %d", l.ch, startPos)
+	return Token{}, atPos(startPos, fmt.Errorf("unexpected character '%c' at position %d", l.ch, startPos))
 }
 
 func (l *Lexer) readNumber(startPos int) (Token, error) {
@@ -194,7 +194,7 @@ func (l *Lexer) readString(startPos int) (Token, error) {
 	}
 
 	if l.ch != quote {
-		return Token{}, fmt.Errorf("unterminated string starting at position %d", startPos)
+		return Token{}, atPos(startPos, fmt.Errorf("unterminated string starting at position %d", startPos))
 	}
 	l.advance()
 
@@ -32,12 +32,24 @@ func (p *Parser) advance() error {
 	return nil
 }
 
+// errorf returns an error at the position of the current token
+func (p *Parser) errorf(format string, args ...any) error {
+	return &PositionError{Pos: p.cur.Pos, Err: fmt.Errorf(format, args...)}
+}
+
 // Parse parses the input and returns the AST
 func (p *Parser) Parse() (Node, error) {
 	if err := p.advance(); err != nil {
 		return nil, err
 	}
-	return p.parseExpr()
+	node, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.cur.Type != TOKEN_EOF {
+		return nil, p.errorf("unexpected token after expression: %v", p.cur.Value)
+	}
+	return node, nil
 }
 
 // Expression parsing with precedence climbing
@@ -63,7 +75,7 @@ func (p *Parser) parseOr() (Node, error) {
 	}
 
 	for p.cur.Type == TOKEN_OR {
-		op := p.cur.Type
+		op, pos := p.cur.Type, p.cur.Pos
 		if err := p.advance(); err != nil {
 			return nil, err
 		}
@@ -71,7 +83,7 @@ func (p *Parser) parseOr() (Node, error) {
 		if err != nil {
 			return nil, err
 		}
-		left = &BinaryOp{Op: op, Left: left, Right: right}
+		left = &BinaryOp{Op: op, Left: left, Right: right, Pos: pos}
 	}
 	return left, nil
 }
@@ -83,7 +95,7 @@ func (p *Parser) parseAnd() (Node, error) {
 	}
 
 	for p.cur.Type == TOKEN_AND {
-		op := p.cur.Type
+		op, pos := p.cur.Type, p.cur.Pos
 		if err := p.advance(); err != nil {
 			return nil, err
 		}
@@ -91,14 +103,14 @@ func (p *Parser) parseAnd() (Node, error) {
 		if err != nil {
 			return nil, err
 		}
-		left = &BinaryOp{Op: op, Left: left, Right: right}
+		left = &BinaryOp{Op: op, Left: left, Right: right, Pos: pos}
 	}
 	return left, nil
 }
 
 func (p *Parser) parseNot() (Node, error) {
 	if p.cur.Type == TOKEN_NOT {
-		op := p.cur.Type
+		op, pos := p.cur.Type, p.cur.Pos
 		if err := p.advance(); err != nil {
 			return nil, err
 		}
@@ -106,7 +118,7 @@ func (p *Parser) parseNot() (Node, error) {
 		if err != nil {
 			return nil, err
 		}
-		return &UnaryOp{Op: op, Expr: expr}, nil
+		return &UnaryOp{Op: op, Expr: expr, Pos: pos}, nil
 	}
 	return p.parseComparison()
 }
@@ -120,7 +132,7 @@ func (p *Parser) parseComparison() (Node, error) {
 	for p.cur.Type == TOKEN_EQ || p.cur.Type == TOKEN_NE ||
 		p.cur.Type == TOKEN_LT || p.cur.Type == TOKEN_GT ||
 		p.cur.Type == TOKEN_LE || p.cur.Type == TOKEN_GE {
-		op := p.cur.Type
+		op, pos := p.cur.Type, p.cur.Pos
 		if err := p.advance(); err != nil {
 			return nil, err
 		}
@@ -128,7 +140,7 @@ func (p *Parser) parseComparison() (Node, error) {
 		if err != nil {
 			return nil, err
 		}
-		left = &BinaryOp{Op: op, Left: left, Right: right}
+		left = &BinaryOp{Op: op, Left: left, Right: right, Pos: pos}
 	}
 	return left, nil
 }
@@ -140,7 +152,7 @@ func (p *Parser) parseAddSub() (Node, error) {
 	}
 
 	for p.cur.Type == TOKEN_PLUS || p.cur.Type == TOKEN_MINUS {
-		op := p.cur.Type
+		op, pos := p.cur.Type, p.cur.Pos
 		if err := p.advance(); err != nil {
 			return nil, err
 		}
@@ -148,7 +160,7 @@ func (p *Parser) parseAddSub() (Node, error) {
 		if err != nil {
 			return nil, err
 		}
-		left = &BinaryOp{Op: op, Left: left, Right: right}
+		left = &BinaryOp{Op: op, Left: left, Right: right, Pos: pos}
 	}
 	return left, nil
 }
@@ -161,7 +173,7 @@ func (p *Parser) parseMulDiv() (Node, error) {
 
 	for p.cur.Type == TOKEN_STAR || p.cur.Type == TOKEN_SLASH ||
 		p.cur.Type == TOKEN_FLOOR_DIV || p.cur.Type == TOKEN_PERCENT {
-		op := p.cur.Type
+		op, pos := p.cur.Type, p.cur.Pos
 		if err := p.advance(); err != nil {
 			return nil, err
 		}
@@ -169,7 +181,7 @@ func (p *Parser) parseMulDiv() (Node, error) {
 		if err != nil {
 			return nil, err
 		}
-		left = &BinaryOp{Op: op, Left: left, Right: right}
+		left = &BinaryOp{Op: op, Left: left, Right: right, Pos: pos}
 	}
 	return left, nil
 }
@@ -182,7 +194,7 @@ func (p *Parser) parsePower() (Node, error) {
 
 	// Power is right-associative
 	if p.cur.Type == TOKEN_POWER {
-		op := p.cur.Type
+		op, pos := p.cur.Type, p.cur.Pos
 		if err := p.advance(); err != nil {
 			return nil, err
 		}
@@ -190,14 +202,14 @@ func (p *Parser) parsePower() (Node, error) {
 		if err != nil {
 			return nil, err
 		}
-		return &BinaryOp{Op: op, Left: left, Right: right}, nil
+		return &BinaryOp{Op: op, Left: left, Right: right, Pos: pos}, nil
 	}
 	return left, nil
 }
 
 func (p *Parser) parseUnary() (Node, error) {
 	if p.cur.Type == TOKEN_MINUS {
-		op := p.cur.Type
+		op, pos := p.cur.Type, p.cur.Pos
 		if err := p.advance(); err != nil {
 			return nil, err
 		}
@@ -205,7 +217,7 @@ func (p *Parser) parseUnary() (Node, error) {
 		if err != nil {
 			return nil, err
 		}
-		return &UnaryOp{Op: op, Expr: expr}, nil
+		return &UnaryOp{Op: op, Expr: expr, Pos: pos}, nil
 	}
 	return p.parsePostfix()
 }
@@ -225,16 +237,16 @@ func (p *Parser) parsePostfix() (Node, error) {
 				if err != nil {
 					return nil, err
 				}
-				node = &CallExpr{Func: ident.Name, Args: args}
+				node = &CallExpr{Func: ident.Name, Args: args, Pos: ident.Pos}
 			} else if attr, ok := node.(*AttrAccess); ok {
 				// Method call like str.upper()
 				args, err := p.parseArgs()
 				if err != nil {
 					return nil, err
 				}
-				node = &CallExpr{Func: "__method__", Args: append([]Node{attr.Obj, &StringLit{Value: attr.Attr}}, args...)}
+				node = &CallExpr{Func: "__method__", Args: append([]Node{attr.Obj, &StringLit{Value: attr.Attr}}, args...), Pos: attr.Pos}
 			} else {
-				return nil, fmt.Errorf("cannot call non-function")
+				return nil, p.errorf("cannot call non-function")
 			}
 		case TOKEN_DOT:
 			// Attribute access
@@ -242,9 +254,9 @@ func (p *Parser) parsePostfix() (Node, error) {
 				return nil, err
 			}
 			if p.cur.Type != TOKEN_IDENT {
-				return nil, fmt.Errorf("expected identifier after '.', got %v", p.cur.Type)
+				return nil, p.errorf("expected identifier after '.', got %v", p.cur.Type)
 			}
-			node = &AttrAccess{Obj: node, Attr: p.cur.Value}
+			node = &AttrAccess{Obj: node, Attr: p.cur.Value, Pos: p.cur.Pos}
 			if err := p.advance(); err != nil {
 				return nil, err
 			}
@@ -281,7 +293,7 @@ func (p *Parser) parseArgs() ([]Node, error) {
 	}
 
 	if p.cur.Type != TOKEN_RPAREN {
-		return nil, fmt.Errorf("expected ')' after arguments")
+		return nil, p.errorf("expected ')' after arguments")
 	}
 	if err := p.advance(); err != nil {
 		return nil, err
@@ -293,7 +305,7 @@ func (p *Parser) parseArgs() ([]Node, error) {
 func (p *Parser) parsePrimary() (Node, error) {
 	switch p.cur.Type {
 	case TOKEN_NUMBER:
-		numStr := p.cur.Value
+		numStr, pos := p.cur.Value, p.cur.Pos
 		if err := p.advance(); err != nil {
 			return nil, err
 		}
@@ -307,7 +319,7 @@ func (p *Parser) parsePrimary() (Node, error) {
 		}
 		val, err := strconv.ParseFloat(numStr, 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid number: %s", numStr)
+			return nil, atPos(pos, fmt.Errorf("invalid number: %s", numStr))
 		}
 		return &NumberLit{Value: val}, nil
 
@@ -319,11 +331,11 @@ func (p *Parser) parsePrimary() (Node, error) {
 		return &StringLit{Value: val}, nil
 
 	case TOKEN_IDENT:
-		name := p.cur.Value
+		name, pos := p.cur.Value, p.cur.Pos
 		if err := p.advance(); err != nil {
 			return nil, err
 		}
-		return &Ident{Name: name}, nil
+		return &Ident{Name: name, Pos: pos}, nil
 
 	case TOKEN_LPAREN:
 		if err := p.advance(); err != nil {
@@ -334,7 +346,7 @@ func (p *Parser) parsePrimary() (Node, error) {
 			return nil, err
 		}
 		if p.cur.Type != TOKEN_RPAREN {
-			return nil, fmt.Errorf("expected ')' after expression")
+			return nil, p.errorf("expected ')' after expression")
 		}
 		if err := p.advance(); err != nil {
 			return nil, err
@@ -342,9 +354,9 @@ func (p *Parser) parsePrimary() (Node, error) {
 		return expr, nil
 
 	case TOKEN_EOF:
-		return nil, fmt.Errorf("unexpected end of expression")
+		return nil, p.errorf("unexpected end of expression")
 
 	default:
-		return nil, fmt.Errorf("unexpected token: %v", p.cur.Value)
+		return nil, p.errorf("unexpected token: %v", p.cur.Value)
 	}
 }
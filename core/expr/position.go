@@ -0,0 +1,42 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+*/
+
+package expr
+
+import "errors"
+
+// PositionError is an error found at a byte offset of the expression source.
+// It wraps the underlying error, so errors.Is still matches its kind.
+type PositionError struct {
+	Pos int // Byte offset in the source
+	Err error
+}
+
+func (e *PositionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// Position returns the byte offset of the source at which err was found, or -1 if
+// the error has no position.
+func Position(err error) int {
+	var posErr *PositionError
+	if errors.As(err, &posErr) {
+		return posErr.Pos
+	}
+	return -1
+}
+
+// atPos attaches a position to err, keeping the position of errors that have one.
+func atPos(pos int, err error) error {
+	if err == nil || Position(err) >= 0 {
+		return err
+	}
+	return &PositionError{Pos: pos, Err: err}
+}
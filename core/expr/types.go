@@ -6,7 +6,11 @@ Copyright 2024 The Taxinomia Authors
 
 package expr
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/google/taxinomia/core/errs"
+)
 
 // ExprType represents the type of an expression
 type ExprType int
@@ -62,7 +66,8 @@ func NewTypeChecker(getColumnType ColumnTypeGetter) *TypeChecker {
 	return &TypeChecker{getColumnType: getColumnType}
 }
 
-// Check performs type checking on the AST and returns the result type
+// Check performs type checking on the AST and returns the result type.
+// Errors carry the position of the offending operator, call or attribute (see Position).
 func (tc *TypeChecker) Check(node Node) (ExprType, error) {
 	return tc.check(node)
 }
@@ -89,7 +94,8 @@ func (tc *TypeChecker) check(node Node) (ExprType, error) {
 		if err != nil {
 			return TypeUnknown, err
 		}
-		return tc.checkUnaryOp(n.Op, exprType)
+		resultType, err := tc.checkUnaryOp(n.Op, exprType)
+		return resultType, atPos(n.Pos, err)
 
 	case *BinaryOp:
 		leftType, err := tc.check(n.Left)
@@ -100,17 +106,20 @@ func (tc *TypeChecker) check(node Node) (ExprType, error) {
 		if err != nil {
 			return TypeUnknown, err
 		}
-		return tc.checkBinaryOp(n.Op, leftType, rightType)
+		resultType, err := tc.checkBinaryOp(n.Op, leftType, rightType)
+		return resultType, atPos(n.Pos, err)
 
 	case *CallExpr:
-		return tc.checkCall(n)
+		resultType, err := tc.checkCall(n)
+		return resultType, atPos(n.Pos, err)
 
 	case *AttrAccess:
 		objType, err := tc.check(n.Obj)
 		if err != nil {
 			return TypeUnknown, err
 		}
-		return tc.checkAttrAccess(objType, n.Attr)
+		resultType, err := tc.checkAttrAccess(objType, n.Attr)
+		return resultType, atPos(n.Pos, err)
 	}
 
 	return TypeUnknown, fmt.Errorf("unknown node type")
@@ -128,7 +137,7 @@ func (tc *TypeChecker) checkUnaryOp(op TokenType, exprType ExprType) (ExprType,
 		if exprType == TypeDuration {
 			return TypeDuration, nil
 		}
-		return TypeUnknown, fmt.Errorf("cannot negate %s", exprType)
+		return TypeUnknown, errs.New(errs.ErrTypeMismatch, "cannot negate %s", exprType)
 	case TOKEN_NOT:
 		return TypeBool, nil
 	}
@@ -151,7 +160,7 @@ func (tc *TypeChecker) checkBinaryOp(op TokenType, left, right ExprType) (ExprTy
 		if left.IsNumeric() && right.IsNumeric() {
 			return TypeBool, nil
 		}
-		return TypeUnknown, fmt.Errorf("cannot compare %s with %s", left, right)
+		return TypeUnknown, errs.New(errs.ErrTypeMismatch, "cannot compare %s with %s", left, right)
 
 	case TOKEN_LT, TOKEN_GT, TOKEN_LE, TOKEN_GE:
 		// Can compare numbers, strings, datetimes, or durations
@@ -161,7 +170,7 @@ func (tc *TypeChecker) checkBinaryOp(op TokenType, left, right ExprType) (ExprTy
 		if left.IsNumeric() && right.IsNumeric() {
 			return TypeBool, nil
 		}
-		return TypeUnknown, fmt.Errorf("cannot compare %s with %s", left, right)
+		return TypeUnknown, errs.New(errs.ErrTypeMismatch, "cannot compare %s with %s", left, right)
 
 	// Logical operators return bool
 	case TOKEN_AND, TOKEN_OR:
@@ -192,7 +201,7 @@ func (tc *TypeChecker) checkBinaryOp(op TokenType, left, right ExprType) (ExprTy
 		if left.IsNumeric() && right.IsNumeric() {
 			return TypeFloat, nil
 		}
-		return TypeUnknown, fmt.Errorf("cannot add %s and %s", left, right)
+		return TypeUnknown, errs.New(errs.ErrTypeMismatch, "cannot add %s and %s", left, right)
 
 	case TOKEN_MINUS:
 		// datetime - datetime = duration
@@ -215,7 +224,7 @@ func (tc *TypeChecker) checkBinaryOp(op TokenType, left, right ExprType) (ExprTy
 		if left.IsNumeric() && right.IsNumeric() {
 			return TypeFloat, nil
 		}
-		return TypeUnknown, fmt.Errorf("cannot subtract %s from %s", right, left)
+		return TypeUnknown, errs.New(errs.ErrTypeMismatch, "cannot subtract %s from %s", right, left)
 
 	case TOKEN_STAR:
 		// int * int = int
@@ -225,21 +234,21 @@ func (tc *TypeChecker) checkBinaryOp(op TokenType, left, right ExprType) (ExprTy
 		if left.IsNumeric() && right.IsNumeric() {
 			return TypeFloat, nil
 		}
-		return TypeUnknown, fmt.Errorf("arithmetic operation requires numbers, got %s and %s", left, right)
+		return TypeUnknown, errs.New(errs.ErrTypeMismatch, "arithmetic operation requires numbers, got %s and %s", left, right)
 
 	case TOKEN_SLASH:
 		// Division always returns float
 		if left.IsNumeric() && right.IsNumeric() {
 			return TypeFloat, nil
 		}
-		return TypeUnknown, fmt.Errorf("arithmetic operation requires numbers, got %s and %s", left, right)
+		return TypeUnknown, errs.New(errs.ErrTypeMismatch, "arithmetic operation requires numbers, got %s and %s", left, right)
 
 	case TOKEN_FLOOR_DIV:
 		// Floor division returns int
 		if left.IsNumeric() && right.IsNumeric() {
 			return TypeInt, nil
 		}
-		return TypeUnknown, fmt.Errorf("arithmetic operation requires numbers, got %s and %s", left, right)
+		return TypeUnknown, errs.New(errs.ErrTypeMismatch, "arithmetic operation requires numbers, got %s and %s", left, right)
 
 	case TOKEN_PERCENT:
 		// int % int = int
@@ -249,14 +258,14 @@ func (tc *TypeChecker) checkBinaryOp(op TokenType, left, right ExprType) (ExprTy
 		if left.IsNumeric() && right.IsNumeric() {
 			return TypeFloat, nil
 		}
-		return TypeUnknown, fmt.Errorf("arithmetic operation requires numbers, got %s and %s", left, right)
+		return TypeUnknown, errs.New(errs.ErrTypeMismatch, "arithmetic operation requires numbers, got %s and %s", left, right)
 
 	case TOKEN_POWER:
 		// Power always returns float
 		if left.IsNumeric() && right.IsNumeric() {
 			return TypeFloat, nil
 		}
-		return TypeUnknown, fmt.Errorf("arithmetic operation requires numbers, got %s and %s", left, right)
+		return TypeUnknown, errs.New(errs.ErrTypeMismatch, "arithmetic operation requires numbers, got %s and %s", left, right)
 	}
 
 	return TypeUnknown, fmt.Errorf("unknown binary operator")
@@ -327,7 +336,7 @@ func (tc *TypeChecker) checkFuncCall(name string, argTypes []ExprType) (ExprType
 			return TypeUnknown, fmt.Errorf("len() takes 1 argument, got %d", len(argTypes))
 		}
 		if argTypes[0] != TypeString && argTypes[0] != TypeUnknown {
-			return TypeUnknown, fmt.Errorf("len() argument must be string, got %s", argTypes[0])
+			return TypeUnknown, errs.New(errs.ErrTypeMismatch, "len() argument must be string, got %s", argTypes[0])
 		}
 		return TypeInt, nil
 
@@ -342,7 +351,7 @@ func (tc *TypeChecker) checkFuncCall(name string, argTypes []ExprType) (ExprType
 			return TypeUnknown, fmt.Errorf("int() takes 1 argument, got %d", len(argTypes))
 		}
 		if !argTypes[0].IsNumeric() && argTypes[0] != TypeString && argTypes[0] != TypeUnknown {
-			return TypeUnknown, fmt.Errorf("int() argument must be number or string, got %s", argTypes[0])
+			return TypeUnknown, errs.New(errs.ErrTypeMismatch, "int() argument must be number or string, got %s", argTypes[0])
 		}
 		return TypeInt, nil
 
@@ -351,7 +360,7 @@ func (tc *TypeChecker) checkFuncCall(name string, argTypes []ExprType) (ExprType
 			return TypeUnknown, fmt.Errorf("float() takes 1 argument, got %d", len(argTypes))
 		}
 		if !argTypes[0].IsNumeric() && argTypes[0] != TypeString && argTypes[0] != TypeUnknown {
-			return TypeUnknown, fmt.Errorf("float() argument must be number or string, got %s", argTypes[0])
+			return TypeUnknown, errs.New(errs.ErrTypeMismatch, "float() argument must be number or string, got %s", argTypes[0])
 		}
 		return TypeFloat, nil
 
@@ -360,7 +369,7 @@ func (tc *TypeChecker) checkFuncCall(name string, argTypes []ExprType) (ExprType
 			return TypeUnknown, fmt.Errorf("abs() takes 1 argument, got %d", len(argTypes))
 		}
 		if !argTypes[0].IsNumeric() && argTypes[0] != TypeUnknown {
-			return TypeUnknown, fmt.Errorf("abs() argument must be number, got %s", argTypes[0])
+			return TypeUnknown, errs.New(errs.ErrTypeMismatch, "abs() argument must be number, got %s", argTypes[0])
 		}
 		// Preserve int type
 		if argTypes[0] == TypeInt {
@@ -373,10 +382,21 @@ func (tc *TypeChecker) checkFuncCall(name string, argTypes []ExprType) (ExprType
 			return TypeUnknown, fmt.Errorf("round() takes 1 or 2 arguments, got %d", len(argTypes))
 		}
 		if !argTypes[0].IsNumeric() && argTypes[0] != TypeUnknown {
-			return TypeUnknown, fmt.Errorf("round() first argument must be number, got %s", argTypes[0])
+			return TypeUnknown, errs.New(errs.ErrTypeMismatch, "round() first argument must be number, got %s", argTypes[0])
 		}
 		if len(argTypes) == 2 && !argTypes[1].IsNumeric() && argTypes[1] != TypeUnknown {
-			return TypeUnknown, fmt.Errorf("round() second argument must be number, got %s", argTypes[1])
+			return TypeUnknown, errs.New(errs.ErrTypeMismatch, "round() second argument must be number, got %s", argTypes[1])
+		}
+		return TypeFloat, nil
+
+	case "pct_diff":
+		if len(argTypes) != 2 {
+			return TypeUnknown, fmt.Errorf("pct_diff() takes 2 arguments, got %d", len(argTypes))
+		}
+		for _, t := range argTypes {
+			if !t.IsNumeric() && t != TypeUnknown {
+				return TypeUnknown, errs.New(errs.ErrTypeMismatch, "pct_diff() arguments must be numbers, got %s", t)
+			}
 		}
 		return TypeFloat, nil
 
@@ -402,7 +422,7 @@ func (tc *TypeChecker) checkFuncCall(name string, argTypes []ExprType) (ExprType
 			return TypeUnknown, fmt.Errorf("%s() takes 1 argument, got %d", name, len(argTypes))
 		}
 		if argTypes[0] != TypeDatetime && !argTypes[0].IsNumeric() && argTypes[0] != TypeString && argTypes[0] != TypeUnknown {
-			return TypeUnknown, fmt.Errorf("%s() argument must be datetime, got %s", name, argTypes[0])
+			return TypeUnknown, errs.New(errs.ErrTypeMismatch, "%s() argument must be datetime, got %s", name, argTypes[0])
 		}
 		return TypeInt, nil
 
@@ -414,10 +434,10 @@ func (tc *TypeChecker) checkFuncCall(name string, argTypes []ExprType) (ExprType
 		} else if len(argTypes) == 2 {
 			// duration(value, unit)
 			if !argTypes[0].IsNumeric() && argTypes[0] != TypeUnknown {
-				return TypeUnknown, fmt.Errorf("duration() first argument must be number, got %s", argTypes[0])
+				return TypeUnknown, errs.New(errs.ErrTypeMismatch, "duration() first argument must be number, got %s", argTypes[0])
 			}
 			if argTypes[1] != TypeString && argTypes[1] != TypeUnknown {
-				return TypeUnknown, fmt.Errorf("duration() second argument must be string, got %s", argTypes[1])
+				return TypeUnknown, errs.New(errs.ErrTypeMismatch, "duration() second argument must be string, got %s", argTypes[1])
 			}
 			return TypeDuration, nil
 		}
@@ -430,13 +450,13 @@ func (tc *TypeChecker) checkFuncCall(name string, argTypes []ExprType) (ExprType
 		// Check first two args are datetime-compatible
 		for i := 0; i < 2; i++ {
 			if argTypes[i] == TypeDuration {
-				return TypeUnknown, fmt.Errorf("date_diff() argument %d must be datetime, got duration", i+1)
+				return TypeUnknown, errs.New(errs.ErrTypeMismatch, "date_diff() argument %d must be datetime, got duration", i+1)
 			}
 		}
 		// If 3 args, third must be string (unit)
 		if len(argTypes) == 3 {
 			if argTypes[2] != TypeString && argTypes[2] != TypeUnknown {
-				return TypeUnknown, fmt.Errorf("date_diff() third argument must be string, got %s", argTypes[2])
+				return TypeUnknown, errs.New(errs.ErrTypeMismatch, "date_diff() third argument must be string, got %s", argTypes[2])
 			}
 			return TypeFloat, nil // Returns float when unit specified
 		}
@@ -448,10 +468,10 @@ func (tc *TypeChecker) checkFuncCall(name string, argTypes []ExprType) (ExprType
 		}
 		// First arg must be datetime, second must be duration
 		if argTypes[0] == TypeDuration {
-			return TypeUnknown, fmt.Errorf("%s() first argument must be datetime, got duration", name)
+			return TypeUnknown, errs.New(errs.ErrTypeMismatch, "%s() first argument must be datetime, got duration", name)
 		}
 		if argTypes[1] == TypeDatetime {
-			return TypeUnknown, fmt.Errorf("%s() second argument must be duration, got datetime", name)
+			return TypeUnknown, errs.New(errs.ErrTypeMismatch, "%s() second argument must be duration, got datetime", name)
 		}
 		return TypeDatetime, nil
 
@@ -461,7 +481,7 @@ func (tc *TypeChecker) checkFuncCall(name string, argTypes []ExprType) (ExprType
 			return TypeUnknown, fmt.Errorf("%s() takes 1 argument, got %d", name, len(argTypes))
 		}
 		if argTypes[0] == TypeDatetime {
-			return TypeUnknown, fmt.Errorf("%s() argument must be duration, got datetime", name)
+			return TypeUnknown, errs.New(errs.ErrTypeMismatch, "%s() argument must be duration, got datetime", name)
 		}
 		return TypeFloat, nil
 
@@ -470,7 +490,19 @@ func (tc *TypeChecker) checkFuncCall(name string, argTypes []ExprType) (ExprType
 			return TypeUnknown, fmt.Errorf("format_duration() takes 1 argument, got %d", len(argTypes))
 		}
 		if argTypes[0] == TypeDatetime {
-			return TypeUnknown, fmt.Errorf("format_duration() argument must be duration, got datetime")
+			return TypeUnknown, errs.New(errs.ErrTypeMismatch, "format_duration() argument must be duration, got datetime")
+		}
+		return TypeString, nil
+
+	case "format_time":
+		if len(argTypes) != 2 {
+			return TypeUnknown, fmt.Errorf("format_time() takes 2 arguments, got %d", len(argTypes))
+		}
+		if argTypes[0] == TypeDuration {
+			return TypeUnknown, errs.New(errs.ErrTypeMismatch, "format_time() first argument must be datetime, got duration")
+		}
+		if argTypes[1] != TypeString && argTypes[1] != TypeUnknown {
+			return TypeUnknown, errs.New(errs.ErrTypeMismatch, "format_time() second argument must be string, got %s", argTypes[1])
 		}
 		return TypeString, nil
 
@@ -0,0 +1,33 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grouping names the types of the grouping hierarchy that tables.TableView
+// returns, e.g. from GetFirstBlock. The grouping itself is implemented in an internal
+// package; the types below are aliases of its types and are part of the supported API.
+package grouping
+
+import "github.com/google/taxinomia/core/internal/grouping"
+
+// Group is a group of rows sharing the value of a grouped column.
+type Group = grouping.Group
+
+// Block is the list of groups of a grouped column under one parent group.
+type Block = grouping.Block
+
+// GroupedColumn is a column of the grouping hierarchy.
+type GroupedColumn = grouping.GroupedColumn
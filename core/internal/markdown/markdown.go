@@ -0,0 +1,207 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package markdown parses the subset of Markdown used for dataset documentation
+// into a document model. The model contains only plain text and URLs; it is
+// rendered to HTML by a safehtml template, which escapes text and sanitizes URLs.
+//
+// Supported syntax: ATX headings (#), paragraphs, unordered (-, *) and ordered (1.)
+// lists, fenced code blocks (```), horizontal rules (---), and the inline forms
+// `code`, **strong**, *emphasis* / _emphasis_ and [links](url). Inline forms do not nest.
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Block kinds
+const (
+	KindHeading   = "heading"
+	KindParagraph = "paragraph"
+	KindList      = "list"
+	KindCode      = "code"
+	KindRule      = "rule"
+)
+
+// Inline kinds
+const (
+	KindText     = "text"
+	KindCodeSpan = "codespan"
+	KindStrong   = "strong"
+	KindEmphasis = "emphasis"
+	KindLink     = "link"
+)
+
+// Document is a parsed Markdown document
+type Document struct {
+	Blocks []Block
+}
+
+// IsEmpty returns true if the document has no blocks
+func (d *Document) IsEmpty() bool {
+	return d == nil || len(d.Blocks) == 0
+}
+
+// Block is a block-level element
+type Block struct {
+	Kind    string     // One of the block kinds
+	Level   int        // Heading level (1-6), for headings
+	Ordered bool       // Whether the list is numbered, for lists
+	Inlines []Inline   // Content of headings and paragraphs
+	Items   [][]Inline // Content of each list item, for lists
+	Text    string     // Verbatim content, for code blocks
+}
+
+// Inline is an inline element
+type Inline struct {
+	Kind string // One of the inline kinds
+	Text string // Text content
+	URL  string // Link target, for links (sanitized when rendered)
+}
+
+var (
+	headingPattern   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	unorderedPattern = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedPattern   = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	rulePattern      = regexp.MustCompile(`^(-\s*){3,}$|^(\*\s*){3,}$|^(_\s*){3,}$`)
+	inlinePattern    = regexp.MustCompile("`([^`]+)`|\\*\\*(.+?)\\*\\*|\\[([^\\]]+)\\]\\(([^)\\s]+)\\)|\\*([^*]+)\\*|\\b_([^_]+)_\\b")
+)
+
+// Parse parses Markdown source into a Document
+func Parse(src string) *Document {
+	doc := &Document{}
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	var paragraph []string
+	var list *Block
+	flush := func() {
+		if len(paragraph) > 0 {
+			doc.Blocks = append(doc.Blocks, Block{Kind: KindParagraph, Inlines: parseInlines(strings.Join(paragraph, " "))})
+			paragraph = nil
+		}
+		if list != nil {
+			doc.Blocks = append(doc.Blocks, *list)
+			list = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			doc.Blocks = append(doc.Blocks, Block{Kind: KindCode, Text: strings.Join(code, "\n")})
+
+		case headingPattern.MatchString(trimmed):
+			flush()
+			m := headingPattern.FindStringSubmatch(trimmed)
+			doc.Blocks = append(doc.Blocks, Block{Kind: KindHeading, Level: len(m[1]), Inlines: parseInlines(m[2])})
+
+		case rulePattern.MatchString(trimmed):
+			flush()
+			doc.Blocks = append(doc.Blocks, Block{Kind: KindRule})
+
+		case unorderedPattern.MatchString(line), orderedPattern.MatchString(line):
+			ordered := !unorderedPattern.MatchString(line)
+			if len(paragraph) > 0 || (list != nil && list.Ordered != ordered) {
+				flush()
+			}
+			if list == nil {
+				list = &Block{Kind: KindList, Ordered: ordered}
+			}
+			var m []string
+			if ordered {
+				m = orderedPattern.FindStringSubmatch(line)
+			} else {
+				m = unorderedPattern.FindStringSubmatch(line)
+			}
+			list.Items = append(list.Items, parseInlines(m[1]))
+
+		default:
+			if list != nil {
+				// Continuation line of the last list item
+				last := len(list.Items) - 1
+				list.Items[last] = appendInlines(list.Items[last], parseInlines(" "+trimmed))
+				continue
+			}
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+	return doc
+}
+
+// appendInlines appends inline elements, merging adjacent text
+func appendInlines(dst, src []Inline) []Inline {
+	if len(dst) > 0 && len(src) > 0 && dst[len(dst)-1].Kind == KindText && src[0].Kind == KindText {
+		dst[len(dst)-1].Text += src[0].Text
+		src = src[1:]
+	}
+	return append(dst, src...)
+}
+
+// parseInlines splits text into inline elements
+func parseInlines(text string) []Inline {
+	var inlines []Inline
+	addText := func(s string) {
+		if s == "" {
+			return
+		}
+		if n := len(inlines); n > 0 && inlines[n-1].Kind == KindText {
+			inlines[n-1].Text += s
+			return
+		}
+		inlines = append(inlines, Inline{Kind: KindText, Text: s})
+	}
+
+	pos := 0
+	for _, m := range inlinePattern.FindAllStringSubmatchIndex(text, -1) {
+		addText(text[pos:m[0]])
+		group := func(n int) string {
+			if m[2*n] < 0 {
+				return ""
+			}
+			return text[m[2*n]:m[2*n+1]]
+		}
+		switch {
+		case m[2] >= 0:
+			inlines = append(inlines, Inline{Kind: KindCodeSpan, Text: group(1)})
+		case m[4] >= 0:
+			inlines = append(inlines, Inline{Kind: KindStrong, Text: group(2)})
+		case m[6] >= 0:
+			inlines = append(inlines, Inline{Kind: KindLink, Text: group(3), URL: group(4)})
+		case m[10] >= 0:
+			inlines = append(inlines, Inline{Kind: KindEmphasis, Text: group(5)})
+		default:
+			inlines = append(inlines, Inline{Kind: KindEmphasis, Text: group(6)})
+		}
+		pos = m[1]
+	}
+	addText(text[pos:])
+	return inlines
+}
@@ -0,0 +1,78 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBlocks(t *testing.T) {
+	doc := Parse("# Orders\n\nOne order per row.\nAmounts are in USD.\n\n- first\n- second\n  continued\n\n1. one\n2. two\n\n---\n\n```\nSELECT *\n```\n")
+
+	kinds := make([]string, len(doc.Blocks))
+	for i, b := range doc.Blocks {
+		kinds[i] = b.Kind
+	}
+	want := []string{KindHeading, KindParagraph, KindList, KindList, KindRule, KindCode}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("expected blocks %v, got %v", want, kinds)
+	}
+
+	if doc.Blocks[0].Level != 1 || doc.Blocks[0].Inlines[0].Text != "Orders" {
+		t.Errorf("unexpected heading: %+v", doc.Blocks[0])
+	}
+	if got := doc.Blocks[1].Inlines[0].Text; got != "One order per row. Amounts are in USD." {
+		t.Errorf("unexpected paragraph text: %q", got)
+	}
+	if list := doc.Blocks[2]; list.Ordered || len(list.Items) != 2 || list.Items[1][0].Text != "second continued" {
+		t.Errorf("unexpected unordered list: %+v", list)
+	}
+	if list := doc.Blocks[3]; !list.Ordered || len(list.Items) != 2 {
+		t.Errorf("unexpected ordered list: %+v", list)
+	}
+	if doc.Blocks[5].Text != "SELECT *" {
+		t.Errorf("unexpected code block: %q", doc.Blocks[5].Text)
+	}
+}
+
+func TestParseInlines(t *testing.T) {
+	got := parseInlines("Use `amount` in **USD**, see [docs](https://example.com/x) and *notes*.")
+	want := []Inline{
+		{Kind: KindText, Text: "Use "},
+		{Kind: KindCodeSpan, Text: "amount"},
+		{Kind: KindText, Text: " in "},
+		{Kind: KindStrong, Text: "USD"},
+		{Kind: KindText, Text: ", see "},
+		{Kind: KindLink, Text: "docs", URL: "https://example.com/x"},
+		{Kind: KindText, Text: " and "},
+		{Kind: KindEmphasis, Text: "notes"},
+		{Kind: KindText, Text: "."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestParseKeepsMarkupAsText(t *testing.T) {
+	doc := Parse("<script>alert(1)</script>")
+	if len(doc.Blocks) != 1 || doc.Blocks[0].Inlines[0].Text != "<script>alert(1)</script>" {
+		t.Errorf("expected raw HTML to be kept as text, got %+v", doc.Blocks)
+	}
+}
@@ -0,0 +1,222 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rendering
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/safehtml"
+	"github.com/google/safehtml/template"
+	"github.com/google/taxinomia/core/aggregates"
+	"github.com/google/taxinomia/core/query"
+	"github.com/google/taxinomia/core/views"
+)
+
+// TemplateFuncs returns the template functions available to the table template.
+//
+// These functions are the supported API for products that override the table template,
+// exposed to them by server.TemplateFuncs: their names, arguments and results are kept
+// stable across releases, while the fields of views.TableViewModel may change. See
+// docs/template_helpers.md for the full reference.
+//
+// URL builders take the view model as their first argument and return a URL for the
+// current view with one change applied:
+//
+//	toggleColumnURL vm column          show or hide a column
+//	toggleGroupingURL vm column        group or ungroup by a column
+//	toggleSortURL vm column            sort by a column, or flip its sort direction
+//	filterURL vm column value          filter a column ("" removes the filter)
+//	clearFilterURL vm column           remove the filter on a column
+//	limitURL vm n                      show n rows (0 shows all rows)
+//
+// Formatting helpers:
+//
+//	formatNumber value                 group digits in thousands ("1234567.5" -> "1,234,567.5")
+//	formatPercent part total           part as a percentage of total ("12.5%")
+//	truncate s n                       shorten s to n characters, ending with "…"
+//	label vm column value              display label of a stored code (value if none)
+//
+// Aggregate accessors take a grouped cell:
+//
+//	aggregate cell column agg          formatted aggregate, e.g. aggregate . "amount" "sum"
+//	rawAggregate cell column agg       unformatted value of a numeric aggregate, e.g. "1234567.5"
+//	aggregates cell column             all formatted aggregates of a column
+//	hasAggregate cell column agg       whether the aggregate is enabled for the column
+//	rowAggregate row column agg        formatted aggregate of a column from any cell of a grouped row
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"add": func(a, b int) int { return a + b },
+
+		"toggleColumnURL":   toggleColumnURL,
+		"toggleGroupingURL": toggleGroupingURL,
+		"toggleSortURL":     toggleSortURL,
+		"filterURL":         filterURL,
+		"clearFilterURL":    clearFilterURL,
+		"limitURL":          limitURL,
+
+		"formatNumber":  formatNumber,
+		"formatPercent": formatPercent,
+		"truncate":      truncate,
+		"label":         label,
+
+		"aggregate":    aggregate,
+		"rawAggregate": rawAggregate,
+		"aggregates":   columnAggregates,
+		"hasAggregate": hasAggregate,
+		"rowAggregate": rowAggregate,
+	}
+}
+
+// withQuery applies build to the view model's query. View models built without a query
+// link to the current page.
+func withQuery(vm views.TableViewModel, build func(q *query.Query) safehtml.URL) safehtml.URL {
+	q := vm.Query()
+	if q == nil {
+		return safehtml.URLSanitized("")
+	}
+	return build(q)
+}
+
+func toggleColumnURL(vm views.TableViewModel, column string) safehtml.URL {
+	return withQuery(vm, func(q *query.Query) safehtml.URL { return q.WithColumnToggled(column) })
+}
+
+func toggleGroupingURL(vm views.TableViewModel, column string) safehtml.URL {
+	return withQuery(vm, func(q *query.Query) safehtml.URL { return q.WithGroupedColumnToggled(column) })
+}
+
+func toggleSortURL(vm views.TableViewModel, column string) safehtml.URL {
+	return withQuery(vm, func(q *query.Query) safehtml.URL { return q.WithSortToggled(column) })
+}
+
+func filterURL(vm views.TableViewModel, column, value string) safehtml.URL {
+	return withQuery(vm, func(q *query.Query) safehtml.URL { return q.WithFilter(column, value) })
+}
+
+func clearFilterURL(vm views.TableViewModel, column string) safehtml.URL {
+	return filterURL(vm, column, "")
+}
+
+func limitURL(vm views.TableViewModel, n int) safehtml.URL {
+	return withQuery(vm, func(q *query.Query) safehtml.URL { return q.WithLimit(n) })
+}
+
+// formatNumber groups the integer digits of a number in thousands. Values that are not
+// numbers are returned unchanged, so the helper can be applied to any cell.
+func formatNumber(value any) string {
+	s := fmt.Sprint(value)
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return s
+	}
+	sign := ""
+	if s[0] == '-' || s[0] == '+' {
+		sign, s = s[:1], s[1:]
+	}
+	intPart, frac := s, ""
+	if i := strings.IndexAny(s, ".eE"); i >= 0 {
+		intPart, frac = s[:i], s[i:]
+	}
+	var b strings.Builder
+	for i, d := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String() + frac
+}
+
+// formatPercent formats part as a percentage of total with one decimal, trimming ".0".
+func formatPercent(part, total any) string {
+	p, err1 := strconv.ParseFloat(fmt.Sprint(part), 64)
+	t, err2 := strconv.ParseFloat(fmt.Sprint(total), 64)
+	if err1 != nil || err2 != nil || t == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strconv.FormatFloat(100*p/t, 'f', 1, 64), ".0") + "%"
+}
+
+// truncate shortens s to at most n characters, replacing the last one with "…".
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n <= 0 || len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+func label(vm views.TableViewModel, column, value string) string {
+	if l, ok := vm.ValueLabels[column][value]; ok {
+		return l
+	}
+	return value
+}
+
+func findAggregate(cell views.GroupedCell, column, agg string) (aggregates.FormattedAggregate, bool) {
+	symbol := query.AggregateSymbol(query.AggregateType(agg))
+	for _, colAggs := range cell.ColumnAggregates {
+		if colAggs.ColumnName != column {
+			continue
+		}
+		for _, a := range colAggs.Aggregates {
+			if a.Symbol == symbol {
+				return a, true
+			}
+		}
+	}
+	return aggregates.FormattedAggregate{}, false
+}
+
+func aggregate(cell views.GroupedCell, column, agg string) string {
+	a, _ := findAggregate(cell, column, agg)
+	return a.Value
+}
+
+func rawAggregate(cell views.GroupedCell, column, agg string) string {
+	a, _ := findAggregate(cell, column, agg)
+	return a.Raw
+}
+
+func columnAggregates(cell views.GroupedCell, column string) []aggregates.FormattedAggregate {
+	for _, colAggs := range cell.ColumnAggregates {
+		if colAggs.ColumnName == column {
+			return colAggs.Aggregates
+		}
+	}
+	return nil
+}
+
+func hasAggregate(cell views.GroupedCell, column, agg string) bool {
+	_, ok := findAggregate(cell, column, agg)
+	return ok
+}
+
+// rowAggregate looks up an aggregate across the cells of a grouped row. Leaf column
+// aggregates are carried by the leaf column's own cell, so templates laying out a group
+// by its grouped cell use this to reach them.
+func rowAggregate(row views.GroupedRow, column, agg string) string {
+	for _, cell := range row.Cells {
+		if a, ok := findAggregate(cell, column, agg); ok {
+			return a.Value
+		}
+	}
+	return ""
+}
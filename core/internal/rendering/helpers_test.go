@@ -0,0 +1,91 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rendering
+
+import (
+	"testing"
+
+	"github.com/google/taxinomia/core/aggregates"
+	"github.com/google/taxinomia/core/views"
+)
+
+func TestFormatHelpers(t *testing.T) {
+	numbers := map[any]string{
+		"1234567":   "1,234,567",
+		"-1234.5":   "-1,234.5",
+		"999":       "999",
+		int64(1000): "1,000",
+		"n/a":       "n/a",
+	}
+	for in, want := range numbers {
+		if got := formatNumber(in); got != want {
+			t.Errorf("formatNumber(%v) = %q, want %q", in, got, want)
+		}
+	}
+	if got := formatPercent(1, 8); got != "12.5%" {
+		t.Errorf("formatPercent(1, 8) = %q, want 12.5%%", got)
+	}
+	if got := formatPercent("3", "3"); got != "100%" {
+		t.Errorf("formatPercent(3, 3) = %q, want 100%%", got)
+	}
+	if got := formatPercent(1, 0); got != "" {
+		t.Errorf("formatPercent(1, 0) = %q, want empty", got)
+	}
+	if got := truncate("taxinomia", 5); got != "taxi…" {
+		t.Errorf("truncate = %q, want taxi…", got)
+	}
+	if got := truncate("tax", 5); got != "tax" {
+		t.Errorf("truncate = %q, want tax", got)
+	}
+}
+
+func TestLabelAndAggregateHelpers(t *testing.T) {
+	vm := views.TableViewModel{ValueLabels: map[string]map[string]string{"status": {"1": "open"}}}
+	if got := label(vm, "status", "1"); got != "open" {
+		t.Errorf("label = %q, want open", got)
+	}
+	if got := label(vm, "status", "2"); got != "2" {
+		t.Errorf("label without a label = %q, want 2", got)
+	}
+
+	cell := views.GroupedCell{ColumnAggregates: []aggregates.ColumnAggregateDisplay{
+		{ColumnName: "amount", Aggregates: []aggregates.FormattedAggregate{{Symbol: "Σ", Value: "400"}, {Symbol: "#", Value: "3"}}},
+	}}
+	if got := aggregate(cell, "amount", "sum"); got != "400" {
+		t.Errorf("aggregate sum = %q, want 400", got)
+	}
+	if got := aggregate(cell, "amount", "avg"); got != "" {
+		t.Errorf("aggregate avg = %q, want empty", got)
+	}
+	if !hasAggregate(cell, "amount", "count") || hasAggregate(cell, "region", "count") {
+		t.Error("hasAggregate reports the wrong aggregates")
+	}
+	row := views.GroupedRow{Cells: []views.GroupedCell{{IsGroupedColumn: true, RawValue: "north"}, cell}}
+	if got := rowAggregate(row, "amount", "count"); got != "3" {
+		t.Errorf("rowAggregate count = %q, want 3", got)
+	}
+	if got := columnAggregates(cell, "amount"); len(got) != 2 {
+		t.Errorf("aggregates = %v, want 2 entries", got)
+	}
+
+	// Without a query, URL builders link to the current page
+	if got := toggleColumnURL(vm, "status").String(); got != "" {
+		t.Errorf("toggleColumnURL without query = %q, want empty", got)
+	}
+}
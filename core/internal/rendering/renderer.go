@@ -0,0 +1,155 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rendering
+
+import (
+	"embed"
+	"io"
+
+	"github.com/google/safehtml"
+	"github.com/google/safehtml/template"
+	"github.com/google/taxinomia/core/internal/markdown"
+	"github.com/google/taxinomia/core/views"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+// TableRenderer handles rendering of table view models to HTML
+type TableRenderer struct {
+	tableTemplate    *template.Template
+	landingTemplate  *template.Template
+	overviewTemplate *template.Template
+	sessionTemplate  *template.Template
+	detailTemplate   *template.Template
+	markdownTemplate *template.Template
+}
+
+// NewTableRenderer creates a new table renderer
+func NewTableRenderer() (*TableRenderer, error) {
+	trustedFS := template.TrustedFSFromEmbed(templateFS)
+
+	// Parse the table template
+	tableTemplate, err := template.New("table.html").Funcs(TemplateFuncs()).ParseFS(trustedFS, "templates/table.html")
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the landing page template
+	landingTemplate, err := template.New("landing.html").ParseFS(trustedFS, "templates/landing.html")
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the overview page template
+	overviewTemplate, err := template.New("overview.html").ParseFS(trustedFS, "templates/overview.html")
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the session recording page template
+	sessionTemplate, err := template.New("session.html").ParseFS(trustedFS, "templates/session.html")
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the standalone detail card template
+	detailTemplate, err := template.New("detail.html").ParseFS(trustedFS, "templates/detail.html")
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the markdown template used for dataset documentation
+	markdownTemplate, err := template.New("markdown.html").ParseFS(trustedFS, "templates/markdown.html")
+	if err != nil {
+		return nil, err
+	}
+
+	return &TableRenderer{
+		tableTemplate:    tableTemplate,
+		landingTemplate:  landingTemplate,
+		overviewTemplate: overviewTemplate,
+		sessionTemplate:  sessionTemplate,
+		detailTemplate:   detailTemplate,
+		markdownTemplate: markdownTemplate,
+	}, nil
+}
+
+// SetTableTemplate replaces the table template with one provided by a product.
+// The template named name is executed for table pages; patterns select the files to parse
+// from fsys. The template functions of TemplateFuncs are available to it.
+func (r *TableRenderer) SetTableTemplate(fsys template.TrustedFS, name string, patterns ...string) error {
+	tableTemplate, err := template.New(name).Funcs(TemplateFuncs()).ParseFS(fsys, patterns...)
+	if err != nil {
+		return err
+	}
+	r.tableTemplate = tableTemplate
+	return nil
+}
+
+// Render renders a TableViewModel to the provided writer
+func (r *TableRenderer) Render(w io.Writer, vm views.TableViewModel) error {
+	return r.tableTemplate.Execute(w, vm)
+}
+
+// StreamsAggregates reports whether the table template can stream aggregates after the page,
+// that is, whether it defines the "aggregateFills" and "tableTail" templates.
+func (r *TableRenderer) StreamsAggregates() bool {
+	return r.tableTemplate.Lookup("aggregateFills") != nil && r.tableTemplate.Lookup("tableTail") != nil
+}
+
+// RenderAggregateFills renders aggregates that were pending when the page was rendered.
+// The page must have been rendered with StreamAggregates set.
+func (r *TableRenderer) RenderAggregateFills(w io.Writer, fills []views.AggregateFill) error {
+	return r.tableTemplate.ExecuteTemplate(w, "aggregateFills", fills)
+}
+
+// RenderTail closes a page rendered with StreamAggregates set.
+func (r *TableRenderer) RenderTail(w io.Writer) error {
+	return r.tableTemplate.ExecuteTemplate(w, "tableTail", nil)
+}
+
+// RenderLanding renders a LandingViewModel to the provided writer
+func (r *TableRenderer) RenderLanding(w io.Writer, vm views.LandingViewModel) error {
+	return r.landingTemplate.Execute(w, vm)
+}
+
+// RenderOverview renders an OverviewViewModel to the provided writer
+func (r *TableRenderer) RenderOverview(w io.Writer, vm views.OverviewViewModel) error {
+	return r.overviewTemplate.Execute(w, vm)
+}
+
+// RenderSession renders a SessionViewModel to the provided writer
+func (r *TableRenderer) RenderSession(w io.Writer, vm views.SessionViewModel) error {
+	return r.sessionTemplate.Execute(w, vm)
+}
+
+// RenderDetail renders a DetailViewModel to the provided writer, as a page or, with
+// Fragment set, as the card alone
+func (r *TableRenderer) RenderDetail(w io.Writer, vm views.DetailViewModel) error {
+	if vm.Fragment {
+		return r.detailTemplate.ExecuteTemplate(w, "detailCard", vm)
+	}
+	return r.detailTemplate.Execute(w, vm)
+}
+
+// RenderMarkdown renders a parsed Markdown document to sanitized HTML
+func (r *TableRenderer) RenderMarkdown(doc *markdown.Document) (safehtml.HTML, error) {
+	return r.markdownTemplate.ExecuteToHTML(doc)
+}
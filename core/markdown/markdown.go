@@ -0,0 +1,64 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package markdown is kept for code written against earlier releases.
+//
+// Deprecated: markdown is an implementation detail of dataset documentation pages and has
+// moved to an internal package. Dataset documentation is rendered by the server from the
+// readme of a table; the aliases below will be removed in a future release.
+package markdown
+
+import "github.com/google/taxinomia/core/internal/markdown"
+
+// Block and inline kinds.
+//
+// Deprecated: the kinds are internal to dataset documentation pages.
+const (
+	KindHeading   = markdown.KindHeading
+	KindParagraph = markdown.KindParagraph
+	KindList      = markdown.KindList
+	KindCode      = markdown.KindCode
+	KindRule      = markdown.KindRule
+	KindText      = markdown.KindText
+	KindCodeSpan  = markdown.KindCodeSpan
+	KindStrong    = markdown.KindStrong
+	KindEmphasis  = markdown.KindEmphasis
+	KindLink      = markdown.KindLink
+)
+
+// Document is a parsed Markdown document.
+//
+// Deprecated: Document is internal to dataset documentation pages.
+type Document = markdown.Document
+
+// Block is a block of a Markdown document.
+//
+// Deprecated: Block is internal to dataset documentation pages.
+type Block = markdown.Block
+
+// Inline is an inline span of a Markdown block.
+//
+// Deprecated: Inline is internal to dataset documentation pages.
+type Inline = markdown.Inline
+
+// Parse parses Markdown source into a document.
+//
+// Deprecated: Parse is internal to dataset documentation pages.
+func Parse(src string) *Document {
+	return markdown.Parse(src)
+}
@@ -20,6 +20,8 @@ package models
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 
 	"github.com/google/taxinomia/core/columns"
 	"github.com/google/taxinomia/core/tables"
@@ -67,19 +69,46 @@ type DataModel struct {
 	// joins between columns in different tables
 	// key is the join key (e.g., "orders.region->regions.region")
 	joins map[string]*Join
+
+	// entity types whose string joins match approximately,
+	// mapped to the similarity threshold (0 = normalized keys only)
+	fuzzyJoins map[string]float64
+
+	// listeners notified when the data of table columns changes
+	changeListeners []ColumnsChangedListener
 }
 
+// ColumnsChangedListener is notified when the data of columns of a table changed,
+// either because the table was replaced (e.g. reloaded) or modified in place.
+type ColumnsChangedListener func(tableName string, changed []string)
+
 // NewDataModel creates a new DataModel instance
 func NewDataModel() *DataModel {
 	return &DataModel{
 		tables:              make(map[string]*tables.DataTable),
 		columnsByEntityType: make(map[string][]TableColumnRef),
 		joins:               make(map[string]*Join),
+		fuzzyJoins:          make(map[string]float64),
 	}
 }
 
-// AddTable adds a table to the data model and automatically registers entity types
+// SetFuzzyJoin makes joins on string columns of an entity type match keys
+// approximately (see columns.FuzzyJoiner). Keys are matched after normalization
+// and, if threshold > 0, by trigram similarity of at least threshold.
+func (dm *DataModel) SetFuzzyJoin(entityType string, threshold float64) {
+	dm.fuzzyJoins[entityType] = threshold
+	dm.discoverJoins()
+}
+
+// AddTable adds a table to the data model and automatically registers entity types.
+// Adding a table under an existing name replaces it; listeners registered with
+// OnColumnsChanged are notified of the columns whose data differs, or of all the
+// columns of a system table.
 func (dm *DataModel) AddTable(name string, table *tables.DataTable) {
+	previous := dm.tables[name]
+	if previous != nil {
+		dm.unregisterEntityTypes(name)
+	}
 	dm.tables[name] = table
 
 	// Automatically register entity types for all columns in the table
@@ -99,6 +128,88 @@ func (dm *DataModel) AddTable(name string, table *tables.DataTable) {
 
 	// Auto-discover and update joins after adding the table
 	dm.discoverJoins()
+
+	if previous != nil {
+		// System tables are rebuilt whenever they are shown: comparing their values would
+		// cost more than recomputing what depends on them, so all their columns change
+		var changed []string
+		if IsSystemTable(name) {
+			changed = allColumnNames(previous, table)
+		} else {
+			changed = changedColumns(previous, table)
+		}
+		if len(changed) > 0 {
+			dm.NotifyColumnsChanged(name, changed...)
+		}
+	}
+}
+
+// OnColumnsChanged registers a listener notified when the data of table columns changes
+func (dm *DataModel) OnColumnsChanged(listener ColumnsChangedListener) {
+	dm.changeListeners = append(dm.changeListeners, listener)
+}
+
+// NotifyColumnsChanged reports that the data of columns of a table changed in place
+// (e.g. rows were appended), so that columns derived from them can be recomputed
+func (dm *DataModel) NotifyColumnsChanged(tableName string, changed ...string) {
+	for _, listener := range dm.changeListeners {
+		listener(tableName, changed)
+	}
+}
+
+// unregisterEntityTypes removes the entity type registrations of a table's columns
+func (dm *DataModel) unregisterEntityTypes(tableName string) {
+	for entityType, refs := range dm.columnsByEntityType {
+		refs = slices.DeleteFunc(refs, func(ref TableColumnRef) bool { return ref.TableName == tableName })
+		if len(refs) == 0 {
+			delete(dm.columnsByEntityType, entityType)
+		} else {
+			dm.columnsByEntityType[entityType] = refs
+		}
+	}
+}
+
+// changedColumns returns the sorted names of the columns whose data differs between
+// two versions of a table, including columns present in only one of them
+func changedColumns(previous, current *tables.DataTable) []string {
+	var changed []string
+	for _, name := range previous.GetColumnNames() {
+		if current.GetColumn(name) == nil {
+			changed = append(changed, name)
+		}
+	}
+	for _, name := range current.GetColumnNames() {
+		if !sameColumnData(previous.GetColumn(name), current.GetColumn(name)) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// allColumnNames returns the sorted names of the columns of either version of a table
+func allColumnNames(previous, current *tables.DataTable) []string {
+	names := append(previous.GetColumnNames(), current.GetColumnNames()...)
+	sort.Strings(names)
+	return slices.Compact(names)
+}
+
+// sameColumnData reports whether two columns hold the same values
+func sameColumnData(a, b columns.IDataColumn) bool {
+	if a == nil || b == nil || a.Length() != b.Length() {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	for i := 0; i < a.Length(); i++ {
+		va, errA := a.GetString(uint32(i))
+		vb, errB := b.GetString(uint32(i))
+		if va != vb || (errA == nil) != (errB == nil) {
+			return false
+		}
+	}
+	return true
 }
 
 // GetTable returns a table by name
@@ -276,11 +387,14 @@ func (dm *DataModel) discoverJoins() {
 	}
 }
 
-func (dm *DataModel) createJoiner(fromColumn columns.IDataColumn, toColumn columns.IDataColumn) columns.IJoiner {
+func (dm *DataModel) createJoiner(fromColumn columns.IDataColumn, toColumn columns.IDataColumn, entityType string) columns.IJoiner {
 	// Both columns must be the same type to create a joiner
 	switch from := fromColumn.(type) {
 	case *columns.StringColumn:
 		if to, ok := toColumn.(*columns.StringColumn); ok {
+			if threshold, fuzzy := dm.fuzzyJoins[entityType]; fuzzy {
+				return columns.NewFuzzyJoiner(from, to, threshold)
+			}
 			return &columns.Joiner[string]{
 				FromColumn: from,
 				ToColumn:   to,
@@ -323,6 +437,12 @@ type Join struct {
 	ToTable  *tables.DataTable
 	ToColumn columns.IDataColumn
 
+	// Names of the source and target tables and columns
+	FromTableName  string
+	FromColumnName string
+	ToTableName    string
+	ToColumnName   string
+
 	// The entity type that connects these columns
 	EntityType string
 
@@ -334,15 +454,25 @@ func (j *Join) GetJoiner() columns.IJoiner {
 	return j.Joiner
 }
 
+// IsFuzzy returns true if the join matches keys approximately
+func (j *Join) IsFuzzy() bool {
+	_, ok := j.Joiner.(*columns.FuzzyJoiner)
+	return ok
+}
+
 // NewJoin creates a new join definition
 func NewJoin(fromTable, fromColumn, toTable, toColumn, entityType string, dm *DataModel) *Join {
 	return &Join{
-		Key:        fmt.Sprintf("%s.%s->%s.%s", fromTable, fromColumn, toTable, toColumn),
-		FromTable:  dm.GetTable(fromTable),
-		FromColumn: dm.GetTable(fromTable).GetColumn(fromColumn),
-		ToTable:    dm.GetTable(toTable),
-		ToColumn:   dm.GetTable(toTable).GetColumn(toColumn),
-		Joiner:     dm.createJoiner(dm.GetTable(fromTable).GetColumn(fromColumn), dm.GetTable(toTable).GetColumn(toColumn)),
-		EntityType: entityType,
+		Key:            fmt.Sprintf("%s.%s->%s.%s", fromTable, fromColumn, toTable, toColumn),
+		FromTable:      dm.GetTable(fromTable),
+		FromColumn:     dm.GetTable(fromTable).GetColumn(fromColumn),
+		ToTable:        dm.GetTable(toTable),
+		ToColumn:       dm.GetTable(toTable).GetColumn(toColumn),
+		FromTableName:  fromTable,
+		FromColumnName: fromColumn,
+		ToTableName:    toTable,
+		ToColumnName:   toColumn,
+		Joiner:         dm.createJoiner(dm.GetTable(fromTable).GetColumn(fromColumn), dm.GetTable(toTable).GetColumn(toColumn), entityType),
+		EntityType:     entityType,
 	}
 }
@@ -0,0 +1,85 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/tables"
+)
+
+func ordersTable(amounts ...uint32) *tables.DataTable {
+	table := tables.NewDataTable()
+	id := columns.NewStringColumn(columns.NewColumnDef("order_id", "Order ID", "order"))
+	amount := columns.NewUint32Column(columns.NewColumnDef("amount", "Amount", ""))
+	for i, a := range amounts {
+		id.Append(string(rune('a' + i)))
+		amount.Append(a)
+	}
+	id.FinalizeColumn()
+	amount.FinalizeColumn()
+	table.AddColumn(id)
+	table.AddColumn(amount)
+	return table
+}
+
+func TestReplacingTableNotifiesChangedColumns(t *testing.T) {
+	dm := NewDataModel()
+	var notified []string
+	dm.OnColumnsChanged(func(tableName string, changed []string) {
+		notified = append(notified, changed...)
+	})
+
+	dm.AddTable("orders", ordersTable(1, 2))
+	if notified != nil {
+		t.Fatalf("adding a new table should not notify, got %v", notified)
+	}
+
+	dm.AddTable("orders", ordersTable(1, 3))
+	if !slices.Equal(notified, []string{"amount"}) {
+		t.Errorf("expected only amount to change, got %v", notified)
+	}
+
+	notified = nil
+	dm.AddTable("orders", ordersTable(1, 3, 4))
+	if !slices.Equal(notified, []string{"amount", "order_id"}) {
+		t.Errorf("expected appended rows to change every column, got %v", notified)
+	}
+
+	if refs := dm.columnsByEntityType["order"]; len(refs) != 1 {
+		t.Errorf("expected replaced table to be registered once, got %v", refs)
+	}
+}
+
+func TestReplacingSystemTableNotifiesAllColumns(t *testing.T) {
+	dm := NewDataModel()
+	var notified []string
+	dm.OnColumnsChanged(func(tableName string, changed []string) {
+		notified = append(notified, changed...)
+	})
+
+	// System tables are not compared, even when their data is the same
+	dm.AddTable(UsageTableName, ordersTable(1, 2))
+	dm.AddTable(UsageTableName, ordersTable(1, 2))
+	if !slices.Equal(notified, []string{"amount", "order_id"}) {
+		t.Errorf("expected every column of the system table to change, got %v", notified)
+	}
+}
@@ -19,7 +19,9 @@ limitations under the License.
 package models
 
 import (
+	"slices"
 	"sort"
+	"time"
 
 	"github.com/google/taxinomia/core/columns"
 	"github.com/google/taxinomia/core/tables"
@@ -28,8 +30,90 @@ import (
 // System table name constants
 const (
 	ColumnsTableName = "_columns"
+	UsageTableName   = "_usage"
+	MemoryTableName  = "_memory"
+
+	DataQualityTableName = "_data_quality"
+	QueryPerfTableName   = "_query_perf"
+	QueryCostTableName   = "_query_cost"
+)
+
+// Usage scopes of the _usage table
+const (
+	UsageScopeTable = "table"
+	UsageScopeView  = "view"
+)
+
+// UsageRecord is the aggregated access statistics of a table, or of one view of a table.
+// User identities are already aggregated into DistinctUsers by the recorder.
+type UsageRecord struct {
+	Table         string
+	Scope         string // UsageScopeTable or UsageScopeView
+	View          string // Empty for the table scope
+	Accesses      uint32
+	DistinctUsers string
+	LastAccess    time.Time
+	Slowest       time.Duration // Slowest access on the day of the last access
+	SlowestAt     time.Time     // Time of the slowest access
+}
+
+// Kinds of rows of the _memory table
+const (
+	MemoryKindColumn     = "column"
+	MemoryKindFilterMask = "filter_mask"
+	MemoryKindGrouping   = "grouping"
 )
 
+// CacheRecord is the memory held by one kind of cache of a table, summed over the
+// cached views of the table.
+type CacheRecord struct {
+	Table   string
+	Kind    string // MemoryKindFilterMask or MemoryKindGrouping
+	Column  string // The grouped column (empty for filter masks)
+	Entries int    // Number of groups, or of cached filter masks
+	Bytes   uint64
+}
+
+// DataQualityRecord is the outcome of one validation rule over the rows of a table,
+// or of the type coercion of one of its columns at load.
+type DataQualityRecord struct {
+	Table      string
+	Rule       string
+	Column     string // The coerced column (empty for validation rules)
+	Expression string
+	Rows       uint32 // Rows checked
+	Violations uint32 // Rows for which the expression is false, or whose value could not be coerced
+	Errors     uint32 // Rows for which the expression failed or is not a boolean
+	Error      string // Why the rule could not be checked at all
+	Examples   string // Values that could not be coerced
+}
+
+// QueryPerfRecord is how one filter of a query was evaluated. Filter values are left
+// out as they may identify what a user looked at.
+type QueryPerfRecord struct {
+	At       time.Time
+	Table    string
+	Column   string
+	Match    string // How the filter value matches: exact, any_of or substring
+	Path     string // tables.FilterPathTyped or tables.FilterPathString
+	Reason   string // Why the string path was taken, empty for the typed path
+	Rows     uint32 // Rows of the table
+	Matched  uint32 // Rows passing this filter and the ones applied before it
+	Duration time.Duration
+}
+
+// QueryCostRecord is the cost of the queries of one user of a product, summed over
+// the queries served since the server started.
+type QueryCostRecord struct {
+	Product       string
+	User          string // Empty for queries without a user
+	Queries       uint32
+	Wall          time.Duration // Wall-clock time spent answering the queries
+	RowsScanned   uint64        // Rows the queries read to filter, group and render their results
+	BytesRendered uint64        // Bytes of the responses
+	LastQuery     time.Time
+}
+
 // BuildColumnsTable creates a system table containing metadata about all columns
 // in the DataModel. Each row represents one column from any table.
 //
@@ -42,6 +126,7 @@ const (
 //   - is_key: string - "true" if column contains unique values, "false" otherwise
 //   - row_count: uint32 - Number of rows in the column
 //   - position: uint32 - Column index within the table
+//   - updated_at: datetime - When the column's values were last updated (empty if unknown)
 func BuildColumnsTable(dm *DataModel) *tables.DataTable {
 	// Create columns for the _columns table
 	// Entity types use "meta." prefix to scope them to the metadata domain
@@ -53,13 +138,14 @@ func BuildColumnsTable(dm *DataModel) *tables.DataTable {
 	isKeyCol := columns.NewStringColumn(columns.NewColumnDef("is_key", "Is Key", ""))
 	rowCountCol := columns.NewUint32Column(columns.NewColumnDef("row_count", "Row Count", ""))
 	positionCol := columns.NewUint32Column(columns.NewColumnDef("position", "Position", ""))
+	updatedAtCol := columns.NewDatetimeColumn(columns.NewColumnDef("updated_at", "Updated At", ""))
 
 	// Get all tables and sort by name for consistent ordering
 	allTables := dm.GetAllTables()
 	tableNames := make([]string, 0, len(allTables))
 	for name := range allTables {
 		// Skip system tables
-		if isSystemTable(name) {
+		if IsSystemTable(name) {
 			continue
 		}
 		tableNames = append(tableNames, name)
@@ -100,6 +186,7 @@ func BuildColumnsTable(dm *DataModel) *tables.DataTable {
 			isKeyCol.Append(isKey)
 			rowCountCol.Append(uint32(col.Length()))
 			positionCol.Append(uint32(position))
+			updatedAtCol.Append(colDef.UpdatedAt())
 		}
 	}
 
@@ -112,6 +199,7 @@ func BuildColumnsTable(dm *DataModel) *tables.DataTable {
 	isKeyCol.FinalizeColumn()
 	rowCountCol.FinalizeColumn()
 	positionCol.FinalizeColumn()
+	updatedAtCol.FinalizeColumn()
 
 	// Create and populate the table
 	columnsTable := tables.NewDataTable()
@@ -123,6 +211,7 @@ func BuildColumnsTable(dm *DataModel) *tables.DataTable {
 	columnsTable.AddColumn(isKeyCol)
 	columnsTable.AddColumn(rowCountCol)
 	columnsTable.AddColumn(positionCol)
+	columnsTable.AddColumn(updatedAtCol)
 
 	return columnsTable
 }
@@ -151,9 +240,374 @@ func getColumnType(col columns.IDataColumn) string {
 	}
 }
 
-// isSystemTable returns true if the table name is a system table
-func isSystemTable(name string) bool {
-	return name == ColumnsTableName
+// BuildUsageTable creates a system table containing the access statistics of the
+// tables in the DataModel. Tables without a record get a table scope row with no
+// accesses, so that unused tables show up as well.
+//
+// Schema:
+//   - table_name: string - The table that was accessed
+//   - scope: string - "table" for all accesses of the table, "view" for one view of it
+//   - view: string - The columns and grouping of the view (empty for the table scope)
+//   - accesses: uint32 - Number of times the table or view was served
+//   - distinct_users: string - Number of distinct users, or "<N" below the reporting threshold
+//   - last_access: datetime - Time of the latest access (empty if never accessed)
+//   - slowest_us: uint64 - Longest time to serve an access on the day of the last access, in microseconds
+func BuildUsageTable(dm *DataModel, records []UsageRecord) *tables.DataTable {
+	accessed := make(map[string]bool)
+	for _, r := range records {
+		accessed[r.Table] = true
+	}
+	all := make([]UsageRecord, 0, len(records))
+	all = append(all, records...)
+	for name := range dm.GetAllTables() {
+		if !IsSystemTable(name) && !accessed[name] {
+			all = append(all, UsageRecord{Table: name, Scope: UsageScopeTable, DistinctUsers: "0"})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].Table != all[j].Table {
+			return all[i].Table < all[j].Table
+		}
+		if all[i].Scope != all[j].Scope {
+			return all[i].Scope == UsageScopeTable
+		}
+		return all[i].View < all[j].View
+	})
+
+	tableNameCol := columns.NewStringColumn(columns.NewColumnDef("table_name", "Table", "meta.table_name"))
+	scopeCol := columns.NewStringColumn(columns.NewColumnDef("scope", "Scope", ""))
+	viewCol := columns.NewStringColumn(columns.NewColumnDef("view", "View", ""))
+	accessesCol := columns.NewUint32Column(columns.NewColumnDef("accesses", "Accesses", ""))
+	usersCol := columns.NewStringColumn(columns.NewColumnDef("distinct_users", "Distinct Users", ""))
+	lastAccessCol := columns.NewDatetimeColumn(columns.NewColumnDef("last_access", "Last Access", ""))
+	slowestCol := columns.NewUint64Column(columns.NewColumnDef("slowest_us", "Slowest (µs)", ""))
+
+	for _, r := range all {
+		tableNameCol.Append(r.Table)
+		scopeCol.Append(r.Scope)
+		viewCol.Append(r.View)
+		accessesCol.Append(r.Accesses)
+		usersCol.Append(r.DistinctUsers)
+		lastAccessCol.Append(r.LastAccess)
+		slowestCol.Append(uint64(r.Slowest.Microseconds()))
+	}
+
+	tableNameCol.FinalizeColumn()
+	scopeCol.FinalizeColumn()
+	viewCol.FinalizeColumn()
+	accessesCol.FinalizeColumn()
+	usersCol.FinalizeColumn()
+	lastAccessCol.FinalizeColumn()
+	slowestCol.FinalizeColumn()
+
+	usageTable := tables.NewDataTable()
+	usageTable.AddColumn(tableNameCol)
+	usageTable.AddColumn(scopeCol)
+	usageTable.AddColumn(viewCol)
+	usageTable.AddColumn(accessesCol)
+	usageTable.AddColumn(usersCol)
+	usageTable.AddColumn(lastAccessCol)
+	usageTable.AddColumn(slowestCol)
+	return usageTable
+}
+
+// BuildMemoryTable creates a system table containing the estimated memory footprint
+// of every column in the DataModel, followed by the caches of each table.
+// Column rows are measured on every call, which scans all columns.
+//
+// Schema:
+//   - table_name: string - The table the column or cache belongs to
+//   - kind: string - "column", "filter_mask" or "grouping"
+//   - column_name: string - The column, or the grouped column (empty for filter masks)
+//   - data_type: string - The data type of the column (empty for caches)
+//   - encoding: string - How the column stores its values: plain, dictionary, delta, joined or computed (empty for caches)
+//   - cardinality: uint32 - Distinct values of the column, groups, or cached filter masks
+//   - bytes: uint64 - Estimated memory held, including the value index of key columns
+//   - plain_bytes: uint64 - Estimated memory of the values with plain encoding (0 for caches)
+//   - dictionary_bytes: uint64 - Estimated memory if dictionary-encoded (string columns only)
+func BuildMemoryTable(dm *DataModel, caches []CacheRecord) *tables.DataTable {
+	tableNameCol := columns.NewStringColumn(columns.NewColumnDef("table_name", "Table", "meta.table_name"))
+	kindCol := columns.NewStringColumn(columns.NewColumnDef("kind", "Kind", ""))
+	columnNameCol := columns.NewStringColumn(columns.NewColumnDef("column_name", "Column", "meta.column_name"))
+	dataTypeCol := columns.NewStringColumn(columns.NewColumnDef("data_type", "Data Type", "meta.data_type"))
+	encodingCol := columns.NewStringColumn(columns.NewColumnDef("encoding", "Encoding", ""))
+	cardinalityCol := columns.NewUint32Column(columns.NewColumnDef("cardinality", "Cardinality", ""))
+	bytesCol := columns.NewUint64Column(columns.NewColumnDef("bytes", "Bytes", ""))
+	plainBytesCol := columns.NewUint64Column(columns.NewColumnDef("plain_bytes", "Plain Bytes", ""))
+	dictionaryBytesCol := columns.NewUint64Column(columns.NewColumnDef("dictionary_bytes", "Dictionary Bytes", ""))
+
+	cachesByTable := make(map[string][]CacheRecord)
+	for _, c := range caches {
+		cachesByTable[c.Table] = append(cachesByTable[c.Table], c)
+	}
+
+	allTables := dm.GetAllTables()
+	tableNames := make([]string, 0, len(allTables))
+	for name := range allTables {
+		if !IsSystemTable(name) {
+			tableNames = append(tableNames, name)
+		}
+	}
+	sort.Strings(tableNames)
+
+	for _, tableName := range tableNames {
+		table := allTables[tableName]
+		colNames := table.GetColumnNames()
+		sort.Strings(colNames)
+		for _, colName := range colNames {
+			col := table.GetColumn(colName)
+			if col == nil {
+				continue
+			}
+			f := columns.MeasureFootprint(col)
+			tableNameCol.Append(tableName)
+			kindCol.Append(MemoryKindColumn)
+			columnNameCol.Append(colName)
+			dataTypeCol.Append(getColumnType(col))
+			encodingCol.Append(f.Encoding)
+			cardinalityCol.Append(uint32(f.Cardinality))
+			bytesCol.Append(f.Bytes())
+			plainBytesCol.Append(f.PlainBytes)
+			dictionaryBytesCol.Append(f.DictionaryBytes)
+		}
+
+		tableCaches := cachesByTable[tableName]
+		sort.SliceStable(tableCaches, func(i, j int) bool {
+			if tableCaches[i].Kind != tableCaches[j].Kind {
+				return tableCaches[i].Kind == MemoryKindFilterMask
+			}
+			return tableCaches[i].Column < tableCaches[j].Column
+		})
+		for _, c := range tableCaches {
+			tableNameCol.Append(tableName)
+			kindCol.Append(c.Kind)
+			columnNameCol.Append(c.Column)
+			dataTypeCol.Append("")
+			encodingCol.Append("")
+			cardinalityCol.Append(uint32(c.Entries))
+			bytesCol.Append(c.Bytes)
+			plainBytesCol.Append(0)
+			dictionaryBytesCol.Append(0)
+		}
+	}
+
+	tableNameCol.FinalizeColumn()
+	kindCol.FinalizeColumn()
+	columnNameCol.FinalizeColumn()
+	dataTypeCol.FinalizeColumn()
+	encodingCol.FinalizeColumn()
+	cardinalityCol.FinalizeColumn()
+	bytesCol.FinalizeColumn()
+	plainBytesCol.FinalizeColumn()
+	dictionaryBytesCol.FinalizeColumn()
+
+	memoryTable := tables.NewDataTable()
+	memoryTable.AddColumn(tableNameCol)
+	memoryTable.AddColumn(kindCol)
+	memoryTable.AddColumn(columnNameCol)
+	memoryTable.AddColumn(dataTypeCol)
+	memoryTable.AddColumn(encodingCol)
+	memoryTable.AddColumn(cardinalityCol)
+	memoryTable.AddColumn(bytesCol)
+	memoryTable.AddColumn(plainBytesCol)
+	memoryTable.AddColumn(dictionaryBytesCol)
+	return memoryTable
+}
+
+// BuildDataQualityTable creates a system table containing the validation rule
+// results and the type coercion failures of the tables, one row per rule or
+// coerced column. Rows are sorted by table, keeping the order of the records
+// within a table.
+//
+// Schema:
+//   - table_name: string - The table the rule was checked against
+//   - rule: string - The name of the rule
+//   - column_name: string - The coerced column (empty for validation rules)
+//   - expression: string - The expression every row is expected to satisfy
+//   - rows: uint32 - Number of rows checked
+//   - violations: uint32 - Number of rows for which the expression is false, or whose value could not be coerced
+//   - errors: uint32 - Number of rows for which the expression could not be evaluated
+//   - error: string - Why the rule could not be checked at all (empty if it was)
+//   - examples: string - Values that could not be coerced
+func BuildDataQualityTable(records []DataQualityRecord) *tables.DataTable {
+	sorted := slices.Clone(records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Table < sorted[j].Table
+	})
+
+	tableNameCol := columns.NewStringColumn(columns.NewColumnDef("table_name", "Table", "meta.table_name"))
+	ruleCol := columns.NewStringColumn(columns.NewColumnDef("rule", "Rule", ""))
+	columnNameCol := columns.NewStringColumn(columns.NewColumnDef("column_name", "Column", ""))
+	expressionCol := columns.NewStringColumn(columns.NewColumnDef("expression", "Expression", ""))
+	rowsCol := columns.NewUint32Column(columns.NewColumnDef("rows", "Rows", ""))
+	violationsCol := columns.NewUint32Column(columns.NewColumnDef("violations", "Violations", ""))
+	errorsCol := columns.NewUint32Column(columns.NewColumnDef("errors", "Errors", ""))
+	errorCol := columns.NewStringColumn(columns.NewColumnDef("error", "Error", ""))
+	examplesCol := columns.NewStringColumn(columns.NewColumnDef("examples", "Examples", ""))
+
+	for _, r := range sorted {
+		tableNameCol.Append(r.Table)
+		ruleCol.Append(r.Rule)
+		columnNameCol.Append(r.Column)
+		expressionCol.Append(r.Expression)
+		rowsCol.Append(r.Rows)
+		violationsCol.Append(r.Violations)
+		errorsCol.Append(r.Errors)
+		errorCol.Append(r.Error)
+		examplesCol.Append(r.Examples)
+	}
+
+	tableNameCol.FinalizeColumn()
+	ruleCol.FinalizeColumn()
+	columnNameCol.FinalizeColumn()
+	expressionCol.FinalizeColumn()
+	rowsCol.FinalizeColumn()
+	violationsCol.FinalizeColumn()
+	errorsCol.FinalizeColumn()
+	errorCol.FinalizeColumn()
+	examplesCol.FinalizeColumn()
+
+	qualityTable := tables.NewDataTable()
+	qualityTable.AddColumn(tableNameCol)
+	qualityTable.AddColumn(ruleCol)
+	qualityTable.AddColumn(columnNameCol)
+	qualityTable.AddColumn(expressionCol)
+	qualityTable.AddColumn(rowsCol)
+	qualityTable.AddColumn(violationsCol)
+	qualityTable.AddColumn(errorsCol)
+	qualityTable.AddColumn(errorCol)
+	qualityTable.AddColumn(examplesCol)
+	return qualityTable
+}
+
+// BuildQueryPerfTable creates a system table containing how the filters of recent
+// queries were evaluated, one row per filter, most recent queries first.
+//
+// Schema:
+//   - time: datetime - When the query was served
+//   - table_name: string - The filtered table
+//   - column_name: string - The filtered column
+//   - match: string - "exact", "any_of" or "substring"
+//   - path: string - "typed" if the stored values were compared, "string" if every value was converted to a string
+//   - reason: string - Why the filter took the string path (empty for the typed path)
+//   - rows: uint32 - Rows of the table
+//   - matched: uint32 - Rows passing this filter and the filters applied before it
+//   - duration_us: uint64 - Time spent evaluating the filter, in microseconds
+func BuildQueryPerfTable(records []QueryPerfRecord) *tables.DataTable {
+	sorted := slices.Clone(records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].At.After(sorted[j].At)
+	})
+
+	timeCol := columns.NewDatetimeColumn(columns.NewColumnDef("time", "Time", ""))
+	tableNameCol := columns.NewStringColumn(columns.NewColumnDef("table_name", "Table", "meta.table_name"))
+	columnNameCol := columns.NewStringColumn(columns.NewColumnDef("column_name", "Column", "meta.column_name"))
+	matchCol := columns.NewStringColumn(columns.NewColumnDef("match", "Match", ""))
+	pathCol := columns.NewStringColumn(columns.NewColumnDef("path", "Path", ""))
+	reasonCol := columns.NewStringColumn(columns.NewColumnDef("reason", "Reason", ""))
+	rowsCol := columns.NewUint32Column(columns.NewColumnDef("rows", "Rows", ""))
+	matchedCol := columns.NewUint32Column(columns.NewColumnDef("matched", "Matched", ""))
+	durationCol := columns.NewUint64Column(columns.NewColumnDef("duration_us", "Duration (µs)", ""))
+
+	for _, r := range sorted {
+		timeCol.Append(r.At)
+		tableNameCol.Append(r.Table)
+		columnNameCol.Append(r.Column)
+		matchCol.Append(r.Match)
+		pathCol.Append(r.Path)
+		reasonCol.Append(r.Reason)
+		rowsCol.Append(r.Rows)
+		matchedCol.Append(r.Matched)
+		durationCol.Append(uint64(r.Duration.Microseconds()))
+	}
+
+	timeCol.FinalizeColumn()
+	tableNameCol.FinalizeColumn()
+	columnNameCol.FinalizeColumn()
+	matchCol.FinalizeColumn()
+	pathCol.FinalizeColumn()
+	reasonCol.FinalizeColumn()
+	rowsCol.FinalizeColumn()
+	matchedCol.FinalizeColumn()
+	durationCol.FinalizeColumn()
+
+	perfTable := tables.NewDataTable()
+	perfTable.AddColumn(timeCol)
+	perfTable.AddColumn(tableNameCol)
+	perfTable.AddColumn(columnNameCol)
+	perfTable.AddColumn(matchCol)
+	perfTable.AddColumn(pathCol)
+	perfTable.AddColumn(reasonCol)
+	perfTable.AddColumn(rowsCol)
+	perfTable.AddColumn(matchedCol)
+	perfTable.AddColumn(durationCol)
+	return perfTable
+}
+
+// BuildQueryCostTable creates a system table containing the cost of the queries of each
+// user of each product, costliest first.
+//
+// Schema:
+//   - product: string - The product the queries were served by
+//   - user: string - The user of the queries (empty for queries without a user)
+//   - queries: uint32 - Number of queries served
+//   - wall_ms: uint64 - Wall-clock time spent answering the queries, in milliseconds
+//   - rows_scanned: uint64 - Rows the queries read to filter, group and render their results
+//   - bytes_rendered: uint64 - Bytes of the responses
+//   - last_query: datetime - When the last query was served
+func BuildQueryCostTable(records []QueryCostRecord) *tables.DataTable {
+	sorted := slices.Clone(records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Wall != sorted[j].Wall {
+			return sorted[i].Wall > sorted[j].Wall
+		}
+		if sorted[i].Product != sorted[j].Product {
+			return sorted[i].Product < sorted[j].Product
+		}
+		return sorted[i].User < sorted[j].User
+	})
+
+	productCol := columns.NewStringColumn(columns.NewColumnDef("product", "Product", ""))
+	userCol := columns.NewStringColumn(columns.NewColumnDef("user", "User", ""))
+	queriesCol := columns.NewUint32Column(columns.NewColumnDef("queries", "Queries", ""))
+	wallCol := columns.NewUint64Column(columns.NewColumnDef("wall_ms", "Wall Time (ms)", ""))
+	rowsCol := columns.NewUint64Column(columns.NewColumnDef("rows_scanned", "Rows Scanned", ""))
+	bytesCol := columns.NewUint64Column(columns.NewColumnDef("bytes_rendered", "Bytes Rendered", ""))
+	lastQueryCol := columns.NewDatetimeColumn(columns.NewColumnDef("last_query", "Last Query", ""))
+
+	for _, r := range sorted {
+		productCol.Append(r.Product)
+		userCol.Append(r.User)
+		queriesCol.Append(r.Queries)
+		wallCol.Append(uint64(r.Wall.Milliseconds()))
+		rowsCol.Append(r.RowsScanned)
+		bytesCol.Append(r.BytesRendered)
+		lastQueryCol.Append(r.LastQuery)
+	}
+
+	productCol.FinalizeColumn()
+	userCol.FinalizeColumn()
+	queriesCol.FinalizeColumn()
+	wallCol.FinalizeColumn()
+	rowsCol.FinalizeColumn()
+	bytesCol.FinalizeColumn()
+	lastQueryCol.FinalizeColumn()
+
+	costTable := tables.NewDataTable()
+	costTable.AddColumn(productCol)
+	costTable.AddColumn(userCol)
+	costTable.AddColumn(queriesCol)
+	costTable.AddColumn(wallCol)
+	costTable.AddColumn(rowsCol)
+	costTable.AddColumn(bytesCol)
+	costTable.AddColumn(lastQueryCol)
+	return costTable
+}
+
+// IsSystemTable returns true if the table name is a system table
+func IsSystemTable(name string) bool {
+	return name == ColumnsTableName || name == UsageTableName || name == MemoryTableName ||
+		name == DataQualityTableName || name == QueryPerfTableName || name == QueryCostTableName
 }
 
 // AddSystemTables creates and adds all system tables to the DataModel.
@@ -161,4 +615,9 @@ func isSystemTable(name string) bool {
 func AddSystemTables(dm *DataModel) {
 	columnsTable := BuildColumnsTable(dm)
 	dm.AddTable(ColumnsTableName, columnsTable)
+	dm.AddTable(UsageTableName, BuildUsageTable(dm, nil))
+	dm.AddTable(MemoryTableName, BuildMemoryTable(dm, nil))
+	dm.AddTable(DataQualityTableName, BuildDataQualityTable(nil))
+	dm.AddTable(QueryPerfTableName, BuildQueryPerfTable(nil))
+	dm.AddTable(QueryCostTableName, BuildQueryCostTable(nil))
 }
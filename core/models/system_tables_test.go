@@ -20,6 +20,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/taxinomia/core/columns"
 	"github.com/google/taxinomia/core/tables"
@@ -198,3 +199,187 @@ func TestColumnsTableExcludesItself(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildUsageTable(t *testing.T) {
+	dm := NewDataModel()
+	for _, name := range []string{"used", "unused"} {
+		table := tables.NewDataTable()
+		col := columns.NewStringColumn(columns.NewColumnDef("id", "ID", ""))
+		col.Append("1")
+		col.FinalizeColumn()
+		table.AddColumn(col)
+		dm.AddTable(name, table)
+	}
+	AddSystemTables(dm)
+
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	usageTable := BuildUsageTable(dm, []UsageRecord{
+		{Table: "used", Scope: UsageScopeView, View: "columns=id", Accesses: 2, DistinctUsers: "<3", LastAccess: at, Slowest: 1500 * time.Microsecond, SlowestAt: at},
+		{Table: "used", Scope: UsageScopeTable, Accesses: 2, DistinctUsers: "<3", LastAccess: at},
+	})
+
+	// Unused tables get a row without accesses, system tables are left out
+	if usageTable.Length() != 3 {
+		t.Fatalf("expected 3 rows, got %d", usageTable.Length())
+	}
+	want := [][]string{
+		{"unused", "table", "", "0", "0", "", "0"},
+		{"used", "table", "", "2", "<3", "2024-05-01 09:00:00", "0"},
+		{"used", "view", "columns=id", "2", "<3", "2024-05-01 09:00:00", "1500"},
+	}
+	names := []string{"table_name", "scope", "view", "accesses", "distinct_users", "last_access", "slowest_us"}
+	for row, values := range want {
+		for i, name := range names {
+			got, _ := usageTable.GetColumn(name).GetString(uint32(row))
+			if got != values[i] {
+				t.Errorf("row %d column %s = %q, want %q", row, name, got, values[i])
+			}
+		}
+	}
+}
+
+func TestBuildMemoryTable(t *testing.T) {
+	dm := NewDataModel()
+	table := tables.NewDataTable()
+	status := columns.NewStringColumn(columns.NewColumnDef("status", "Status", ""))
+	for _, v := range []string{"a", "b", "a"} {
+		status.Append(v)
+	}
+	status.FinalizeColumn()
+	table.AddColumn(status)
+	amount := columns.NewUint32Column(columns.NewColumnDef("amount", "Amount", ""))
+	for _, v := range []uint32{1, 2, 3} {
+		amount.Append(v)
+	}
+	amount.FinalizeColumn()
+	table.AddColumn(amount)
+	dm.AddTable("orders", table)
+	AddSystemTables(dm)
+
+	memoryTable := BuildMemoryTable(dm, []CacheRecord{
+		{Table: "orders", Kind: MemoryKindGrouping, Column: "status", Entries: 2, Bytes: 400},
+		{Table: "orders", Kind: MemoryKindFilterMask, Entries: 1, Bytes: 3},
+	})
+
+	// Columns come first, sorted by name, then the filter masks and groupings.
+	// System tables are left out.
+	want := [][]string{
+		{"orders", "column", "amount", "uint32", "plain", "3"},
+		{"orders", "column", "status", "string", "plain", "2"},
+		{"orders", "filter_mask", "", "", "", "1"},
+		{"orders", "grouping", "status", "", "", "2"},
+	}
+	if memoryTable.Length() != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), memoryTable.Length())
+	}
+	names := []string{"table_name", "kind", "column_name", "data_type", "encoding", "cardinality"}
+	for row, values := range want {
+		for i, name := range names {
+			got, _ := memoryTable.GetColumn(name).GetString(uint32(row))
+			if got != values[i] {
+				t.Errorf("row %d column %s = %q, want %q", row, name, got, values[i])
+			}
+		}
+	}
+	if got, _ := memoryTable.GetColumn("bytes").GetString(3); got != "400" {
+		t.Errorf("grouping bytes = %q, want 400", got)
+	}
+	if got, _ := memoryTable.GetColumn("plain_bytes").GetString(0); got != "12" {
+		t.Errorf("uint32 plain bytes = %q, want 12", got)
+	}
+	if got, _ := memoryTable.GetColumn("dictionary_bytes").GetString(0); got != "0" {
+		t.Errorf("uint32 dictionary bytes = %q, want 0", got)
+	}
+}
+
+func TestBuildQueryPerfTable(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	perfTable := BuildQueryPerfTable([]QueryPerfRecord{
+		{At: at, Table: "orders", Column: "status", Match: "exact", Path: "typed", Rows: 6, Matched: 3, Duration: 1500 * time.Microsecond},
+		{At: at.Add(time.Minute), Table: "orders", Column: "total", Match: "substring", Path: "string", Reason: "computed column", Rows: 6, Matched: 1},
+	})
+
+	// Most recent queries come first
+	want := [][]string{
+		{"orders", "total", "string", "computed column", "1", "0"},
+		{"orders", "status", "typed", "", "3", "1500"},
+	}
+	if perfTable.Length() != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), perfTable.Length())
+	}
+	names := []string{"table_name", "column_name", "path", "reason", "matched", "duration_us"}
+	for row, values := range want {
+		for i, name := range names {
+			got, _ := perfTable.GetColumn(name).GetString(uint32(row))
+			if got != values[i] {
+				t.Errorf("row %d column %s = %q, want %q", row, name, got, values[i])
+			}
+		}
+	}
+	if !IsSystemTable(QueryPerfTableName) {
+		t.Errorf("expected %s to be a system table", QueryPerfTableName)
+	}
+}
+
+func TestBuildQueryCostTable(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	costTable := BuildQueryCostTable([]QueryCostRecord{
+		{Product: "infra", User: "alice", Queries: 2, Wall: 40 * time.Millisecond, RowsScanned: 2000, BytesRendered: 5000, LastQuery: at},
+		{Product: "infra", User: "", Queries: 1, Wall: 90 * time.Millisecond, RowsScanned: 1000, BytesRendered: 2500, LastQuery: at},
+		{Product: "sales", User: "bob", Queries: 3, Wall: 40 * time.Millisecond, RowsScanned: 30, BytesRendered: 900, LastQuery: at},
+	})
+
+	// Costliest users come first
+	want := [][]string{
+		{"infra", "", "1", "90", "1000", "2500"},
+		{"infra", "alice", "2", "40", "2000", "5000"},
+		{"sales", "bob", "3", "40", "30", "900"},
+	}
+	if costTable.Length() != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), costTable.Length())
+	}
+	names := []string{"product", "user", "queries", "wall_ms", "rows_scanned", "bytes_rendered"}
+	for row, values := range want {
+		for i, name := range names {
+			got, _ := costTable.GetColumn(name).GetString(uint32(row))
+			if got != values[i] {
+				t.Errorf("row %d column %s = %q, want %q", row, name, got, values[i])
+			}
+		}
+	}
+	if !IsSystemTable(QueryCostTableName) {
+		t.Errorf("expected %s to be a system table", QueryCostTableName)
+	}
+}
+
+func TestBuildDataQualityTable(t *testing.T) {
+	qualityTable := BuildDataQualityTable([]DataQualityRecord{
+		{Table: "quota", Rule: "within_limit", Expression: "used <= limit", Rows: 3, Violations: 1},
+		{Table: "quota", Rule: "broken", Expression: "used <= quota", Error: "unknown columns: quota"},
+		{Table: "jobs", Rule: "ordered", Expression: "start <= end", Rows: 5, Errors: 2},
+		{Table: "quota", Rule: "coerce to int64", Column: "used", Rows: 3, Violations: 2, Examples: `"n/a", "?"`},
+	})
+
+	// Rows are sorted by table, keeping the rule order within a table
+	want := [][]string{
+		{"jobs", "ordered", "", "5", "0", "2", "", ""},
+		{"quota", "within_limit", "", "3", "1", "0", "", ""},
+		{"quota", "broken", "", "0", "0", "0", "unknown columns: quota", ""},
+		{"quota", "coerce to int64", "used", "3", "2", "0", "", `"n/a", "?"`},
+	}
+	if qualityTable.Length() != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), qualityTable.Length())
+	}
+	names := []string{"table_name", "rule", "column_name", "rows", "violations", "errors", "error", "examples"}
+	for row, values := range want {
+		for i, name := range names {
+			got, _ := qualityTable.GetColumn(name).GetString(uint32(row))
+			if got != values[i] {
+				t.Errorf("row %d column %s = %q, want %q", row, name, got, values[i])
+			}
+		}
+	}
+	if !IsSystemTable(DataQualityTableName) {
+		t.Errorf("expected %s to be a system table", DataQualityTableName)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"slices"
+
+	"github.com/google/safehtml"
+)
+
+// ColumnPreset is a named set of columns defined by the owners of a table
+// (e.g., "capacity view", "billing view")
+type ColumnPreset struct {
+	Name    string   // Name shown in the preset switcher
+	Columns []string // Visible columns, in display order
+}
+
+// WithColumnPreset returns a URL showing the columns of the preset instead of the
+// visible columns. Filters, grouping, sort and aggregates are kept; grouped columns
+// are always visible, so they stay visible even if the preset doesn't list them.
+func (s *Query) WithColumnPreset(preset ColumnPreset) safehtml.URL {
+	newState := s.Clone()
+	newState.Columns = slices.Clone(preset.Columns)
+	for _, col := range s.GroupedColumns {
+		if !slices.Contains(newState.Columns, col) {
+			newState.Columns = append(newState.Columns, col)
+		}
+	}
+	newState.reorderColumns()
+	return newState.ToSafeURL()
+}
+
+// IsColumnPresetActive reports whether the visible columns are those of the preset,
+// in any order. Grouped columns the preset doesn't list are ignored.
+func (s *Query) IsColumnPresetActive(preset ColumnPreset) bool {
+	if len(preset.Columns) == 0 {
+		return false
+	}
+	for _, col := range s.Columns {
+		if !slices.Contains(preset.Columns, col) && !slices.Contains(s.GroupedColumns, col) {
+			return false
+		}
+	}
+	for _, col := range preset.Columns {
+		if !slices.Contains(s.Columns, col) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import "slices"
+
+// DefaultView is the view a table opens with when no columns are requested
+type DefaultView struct {
+	Columns           []string                   // Visible columns, in display order
+	GroupedColumns    []string                   // Columns to group by, outermost first
+	SortOrder         []SortColumn               // Sort order
+	AggregateSettings map[string][]AggregateType // Enabled aggregates per column
+}
+
+// NewDefaultView creates a DefaultView from its configured form: sort entries
+// are "+col" or "-col" and aggregates map columns to comma-separated lists
+// (e.g. "sum,avg"). Invalid sort entries and unknown aggregates are ignored.
+func NewDefaultView(columns, groupedColumns, sort []string, aggregates map[string]string) *DefaultView {
+	view := &DefaultView{
+		Columns:           slices.Clone(columns),
+		GroupedColumns:    slices.Clone(groupedColumns),
+		AggregateSettings: make(map[string][]AggregateType, len(aggregates)),
+	}
+	for _, s := range sort {
+		view.SortOrder = append(view.SortOrder, parseSortOrder(s)...)
+	}
+	for col, aggs := range aggregates {
+		if parsed := parseAggregates(aggs); len(parsed) > 0 {
+			view.AggregateSettings[col] = parsed
+		}
+	}
+	return view
+}
+
+// ApplyDefaultView fills the query from a table's default view. Grouping, sort
+// and aggregates requested explicitly are kept. Grouped columns are always
+// visible, so they are added to the columns if missing.
+func (s *Query) ApplyDefaultView(view *DefaultView) {
+	if view == nil {
+		return
+	}
+	if len(s.GroupedColumns) == 0 {
+		s.GroupedColumns = slices.Clone(view.GroupedColumns)
+	}
+	if len(s.Columns) == 0 {
+		s.Columns = slices.Clone(view.Columns)
+	}
+	for _, col := range s.GroupedColumns {
+		if !slices.Contains(s.Columns, col) {
+			s.Columns = append(s.Columns, col)
+		}
+	}
+	if len(s.SortOrder) == 0 {
+		s.SortOrder = slices.Clone(view.SortOrder)
+	}
+	if s.AggregateSettings == nil {
+		s.AggregateSettings = make(map[string][]AggregateType)
+	}
+	for col, aggs := range view.AggregateSettings {
+		if _, exists := s.AggregateSettings[col]; !exists {
+			s.AggregateSettings[col] = slices.Clone(aggs)
+		}
+	}
+	s.reorderColumns()
+}
@@ -19,11 +19,16 @@ limitations under the License.
 package query
 
 import (
+	"maps"
 	"net/url"
+	"path"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/safehtml"
+	"github.com/google/taxinomia/core/errs"
 )
 
 // ComputedColumnDef represents a computed column definition from URL
@@ -46,6 +51,13 @@ type GroupAggSort struct {
 	Descending    bool          // Sort direction
 }
 
+// Cohort is an ad-hoc bucket that merges several values of a grouped column into one
+// group shown under Label (e.g., "us-east" and "us-central" grouped as "US")
+type Cohort struct {
+	Label  string
+	Values []string
+}
+
 // AggregateType represents a type of aggregate function
 type AggregateType string
 
@@ -193,12 +205,27 @@ type Query struct {
 	SortOrder          []SortColumn                 // Ordered list of sort columns (all visible columns with +/- direction)
 	AggregateSettings  map[string][]AggregateType   // Enabled aggregates per column (columnName -> list of enabled aggregates)
 	GroupAggregateSorts map[string]*GroupAggSort    // Aggregate sort for grouped columns (groupedColumn -> sort spec)
+	RowLinkColumn      string                       // Column whose entity URL a row click navigates to (empty = table default)
+	DiffColumns        []string                     // Two columns compared side by side within each row (empty = no comparison)
+	Cohorts            map[string][]Cohort          // Ad-hoc cohorts merging values of grouped columns (columnName -> labeled buckets)
+	GroupLabels        map[string]string            // Expressions rendering the groups of grouped columns (columnName -> expression)
+	ExampleRows        int                          // Example rows shown in each leaf group of grouped views (0 = none)
+	RandomExampleRows  bool                         // Whether example rows are sampled at random rather than the first rows of each group
 
 	// UI state
 	ShowInfoPane   bool   // Whether the info pane is visible (default: true)
 	InfoPaneTab    string // Active tab in info pane ("url" or "perf")
 	AnimatedColumn string // Column to animate (e.g., just grouped) - transient, not persisted in subsequent URLs
-	SelectedRowID  string // Primary key value of the selected row (empty = no selection)
+	SelectedRowID  string   // Primary key value of the selected row (empty = no selection)
+	ExpandedRows   []string // Keys of flat rows showing their full cell content (primary key value, or row position without one)
+	TargetColumn   string   // Column of the deep-linked cell - transient, not persisted in subsequent URLs
+	TargetRowKey   string   // Row key of the deep-linked cell - transient, not persisted in subsequent URLs
+	Session        string   // ID of the session recording the query states (empty = not recording)
+	AsOf           string   // Time whose retained snapshot of the table the query runs against (empty = current data)
+
+	// Columns identifying records (IDs, codes), whose aggregates default to count and unique
+	// count and whose sum and average are demoted - set by the server, not persisted in URLs
+	IdentifierColumns map[string]bool
 }
 
 // NewQuery creates a Query from a URL
@@ -212,6 +239,8 @@ func NewQuery(u *url.URL) *Query {
 		ColumnWidths:        make(map[string]int),
 		AggregateSettings:   make(map[string][]AggregateType),
 		GroupAggregateSorts: make(map[string]*GroupAggSort),
+		Cohorts:             make(map[string][]Cohort),
+		GroupLabels:         make(map[string]string),
 		Limit:               25,     // Default limit
 		ShowInfoPane:        true,   // Default to showing info pane
 		InfoPaneTab:         "url",  // Default to URL tab
@@ -291,7 +320,7 @@ func NewQuery(u *url.URL) *Query {
 	// Extract computed columns parameter (format: name=operation(col1,col2);name2=operation2(col3,col4))
 	computedStr := q.Get("computed")
 	if computedStr != "" {
-		state.ComputedColumns = parseComputedColumns(computedStr)
+		state.ComputedColumns = ParseComputedColumns(computedStr)
 	}
 
 	// Extract sort parameter (format: +col1,-col2,+col3)
@@ -310,6 +339,23 @@ func NewQuery(u *url.URL) *Query {
 		}
 	}
 
+	// Extract cohort parameters (format: groupon:columnName=label:value1|value2;label2:value3)
+	for key, values := range q {
+		if strings.HasPrefix(key, "groupon:") && len(values) > 0 {
+			columnName := strings.TrimPrefix(key, "groupon:")
+			if cohorts := parseCohorts(values[0]); len(cohorts) > 0 {
+				state.Cohorts[columnName] = cohorts
+			}
+		}
+	}
+
+	// Extract group label parameters (format: grouplabel:columnName=expression)
+	for key, values := range q {
+		if strings.HasPrefix(key, "grouplabel:") && len(values) > 0 && values[0] != "" {
+			state.GroupLabels[strings.TrimPrefix(key, "grouplabel:")] = values[0]
+		}
+	}
+
 	// Extract info pane state parameters
 	infoParam := q.Get("info")
 	if infoParam == "0" {
@@ -326,15 +372,135 @@ func NewQuery(u *url.URL) *Query {
 	// Extract selected row parameter (primary key value)
 	state.SelectedRowID = q.Get("row")
 
+	// Extract expanded rows parameter, repeated once per row since keys may contain
+	// commas (format: expandrows=key1&expandrows=key2)
+	for _, key := range q["expandrows"] {
+		if key != "" {
+			state.ExpandedRows = append(state.ExpandedRows, key)
+		}
+	}
+
+	// Extract deep-linked cell parameter (transient, format: cell=column:rowKey)
+	if column, rowKey, ok := strings.Cut(q.Get("cell"), ":"); ok {
+		state.TargetColumn = column
+		state.TargetRowKey = rowKey
+	}
+
+	// Extract column comparison parameter (format: diff=colA,colB)
+	if diffStr := q.Get("diff"); diffStr != "" {
+		if a, b, ok := strings.Cut(diffStr, ","); ok && a != "" && b != "" && a != b {
+			state.DiffColumns = []string{a, b}
+		}
+	}
+
+	// Extract example rows parameter (format: examples=3 or examples=3:random)
+	if examplesStr := q.Get("examples"); examplesStr != "" {
+		countStr, order, _ := strings.Cut(examplesStr, ":")
+		if count, err := strconv.Atoi(countStr); err == nil && count > 0 && (order == "" || order == "first" || order == "random") {
+			state.ExampleRows = count
+			state.RandomExampleRows = order == "random"
+		}
+	}
+
+	// Extract row link column override (format: rowlink=columnName)
+	state.RowLinkColumn = q.Get("rowlink")
+
+	// Extract session recording parameter
+	state.Session = q.Get("session")
+
+	// Extract time travel parameter (format: asof=2024-01-02 or asof=2024-01-02T15:04:05Z)
+	state.AsOf = q.Get("asof")
+
 	// Reorder columns: filtered columns first, then grouped columns, then others
 	state.reorderColumns()
 
 	return state
 }
 
-// parseComputedColumns parses the computed parameter string into ComputedColumnDef slice
+// parseCohorts parses the labeled buckets of a groupon parameter.
+// Format: label:value1|value2;label2:value3, with "\\", "|", ";" and ":" in labels and values
+// escaped by a backslash. A bucket that lists the label of an earlier bucket absorbs that
+// bucket's values, so a cohort can be merged into a larger one.
+func parseCohorts(cohortStr string) []Cohort {
+	var cohorts []Cohort
+	for _, bucket := range splitEscaped(cohortStr, ';') {
+		parts := splitEscaped(bucket, ':')
+		if len(parts) < 2 || parts[0] == "" {
+			continue
+		}
+		label := unescapeCohort(parts[0])
+		valuesStr := bucket[len(parts[0])+1:]
+		if valuesStr == "" {
+			continue
+		}
+		cohort := Cohort{Label: label}
+		for _, value := range splitEscaped(valuesStr, '|') {
+			value = unescapeCohort(value)
+			if i := slices.IndexFunc(cohorts, func(c Cohort) bool { return c.Label == value }); i >= 0 {
+				cohort.Values = append(cohort.Values, cohorts[i].Values...)
+				cohorts = slices.Delete(cohorts, i, i+1)
+			} else if value != "" && !slices.Contains(cohort.Values, value) {
+				cohort.Values = append(cohort.Values, value)
+			}
+		}
+		// Redefining a label replaces the earlier bucket
+		cohorts = slices.DeleteFunc(cohorts, func(c Cohort) bool { return c.Label == label })
+		cohorts = append(cohorts, cohort)
+	}
+	return cohorts
+}
+
+// formatCohorts formats cohorts as the value of a groupon parameter.
+func formatCohorts(cohorts []Cohort) string {
+	buckets := make([]string, len(cohorts))
+	for i, c := range cohorts {
+		values := make([]string, len(c.Values))
+		for j, value := range c.Values {
+			values[j] = cohortEscaper.Replace(value)
+		}
+		buckets[i] = cohortEscaper.Replace(c.Label) + ":" + strings.Join(values, "|")
+	}
+	return strings.Join(buckets, ";")
+}
+
+// cohortEscaper escapes the separators of groupon parameters in labels and values
+var cohortEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, ";", `\;`, ":", `\:`)
+
+// splitEscaped splits s around the occurrences of sep that are not escaped by a
+// backslash. The parts keep their escapes.
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescapeCohort removes the backslash escapes of a cohort label or value
+func unescapeCohort(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// ParseComputedColumns parses the computed parameter string into ComputedColumnDef slice
 // Format: name=expression (e.g., name=operation(col1,col2) or name=anything)
-func parseComputedColumns(computedStr string) []ComputedColumnDef {
+func ParseComputedColumns(computedStr string) []ComputedColumnDef {
 	var result []ComputedColumnDef
 	definitions := strings.Split(computedStr, ";")
 	for _, def := range definitions {
@@ -357,6 +523,15 @@ func parseComputedColumns(computedStr string) []ComputedColumnDef {
 	return result
 }
 
+// formatComputedColumns formats computed column definitions as a computed parameter
+func formatComputedColumns(defs []ComputedColumnDef) string {
+	computedStrs := make([]string, len(defs))
+	for i, comp := range defs {
+		computedStrs[i] = comp.Name + "=" + comp.Expression
+	}
+	return strings.Join(computedStrs, ";")
+}
+
 // parseSortOrder parses the sort parameter string into SortColumn slice
 // Format: +col1,-col2,+col3 (+ = ascending, - = descending)
 func parseSortOrder(sortStr string) []SortColumn {
@@ -467,9 +642,19 @@ func (s *Query) Clone() *Query {
 		SortOrder:           make([]SortColumn, len(s.SortOrder)),
 		AggregateSettings:   make(map[string][]AggregateType),
 		GroupAggregateSorts: make(map[string]*GroupAggSort),
+		Cohorts:             make(map[string][]Cohort),
+		GroupLabels:         maps.Clone(s.GroupLabels),
+		RowLinkColumn:       s.RowLinkColumn,
+		DiffColumns:         slices.Clone(s.DiffColumns),
+		ExampleRows:         s.ExampleRows,
+		RandomExampleRows:   s.RandomExampleRows,
 		ShowInfoPane:        s.ShowInfoPane,
 		InfoPaneTab:         s.InfoPaneTab,
 		SelectedRowID:       s.SelectedRowID,
+		ExpandedRows:        slices.Clone(s.ExpandedRows),
+		Session:             s.Session,
+		AsOf:                s.AsOf,
+		IdentifierColumns:   s.IdentifierColumns,
 	}
 
 	// Deep copy columns
@@ -514,6 +699,15 @@ func (s *Query) Clone() *Query {
 		}
 	}
 
+	// Deep copy cohorts
+	for colName, cohorts := range s.Cohorts {
+		cohortsCopy := make([]Cohort, len(cohorts))
+		for i, c := range cohorts {
+			cohortsCopy[i] = Cohort{Label: c.Label, Values: slices.Clone(c.Values)}
+		}
+		clone.Cohorts[colName] = cohortsCopy
+	}
+
 	return clone
 }
 
@@ -530,7 +724,12 @@ func (s *Query) ClearTableSpecificState() {
 	s.SortOrder = nil
 	s.AggregateSettings = make(map[string][]AggregateType)
 	s.GroupAggregateSorts = make(map[string]*GroupAggSort)
+	s.Cohorts = make(map[string][]Cohort)
+	s.GroupLabels = make(map[string]string)
+	s.RowLinkColumn = ""
+	s.DiffColumns = nil
 	s.SelectedRowID = ""
+	s.ExpandedRows = nil
 }
 
 // reorderColumns reorders the Columns slice to maintain:
@@ -741,11 +940,7 @@ func (s *Query) ToURL() string {
 
 	// Add computed columns parameter
 	if len(s.ComputedColumns) > 0 {
-		var computedStrs []string
-		for _, comp := range s.ComputedColumns {
-			computedStrs = append(computedStrs, comp.Name+"="+comp.Expression)
-		}
-		q.Set("computed", strings.Join(computedStrs, ";"))
+		q.Set("computed", formatComputedColumns(s.ComputedColumns))
 	}
 
 	// Add sort parameter (format: +col1,-col2,+col3)
@@ -781,6 +976,28 @@ func (s *Query) ToURL() string {
 		q.Set("groupsort:"+groupedCol, sign+aggSort.LeafColumn+":"+string(aggSort.AggType))
 	}
 
+	// Add cohort parameters (format: groupon:columnName=label:value1|value2)
+	for colName, cohorts := range s.Cohorts {
+		if len(cohorts) > 0 {
+			q.Set("groupon:"+colName, formatCohorts(cohorts))
+		}
+	}
+
+	// Add group label parameters (format: grouplabel:columnName=expression)
+	for colName, expression := range s.GroupLabels {
+		q.Set("grouplabel:"+colName, expression)
+	}
+
+	// Add row link column override
+	if s.RowLinkColumn != "" {
+		q.Set("rowlink", s.RowLinkColumn)
+	}
+
+	// Add column comparison
+	if len(s.DiffColumns) == 2 {
+		q.Set("diff", s.DiffColumns[0]+","+s.DiffColumns[1])
+	}
+
 	// Add info pane state parameters
 	if !s.ShowInfoPane {
 		q.Set("info", "0")
@@ -794,6 +1011,30 @@ func (s *Query) ToURL() string {
 		q.Set("row", s.SelectedRowID)
 	}
 
+	// Add expanded rows parameter
+	for _, key := range s.ExpandedRows {
+		q.Add("expandrows", key)
+	}
+
+	// Add example rows parameter
+	if s.ExampleRows > 0 {
+		examples := strconv.Itoa(s.ExampleRows)
+		if s.RandomExampleRows {
+			examples += ":random"
+		}
+		q.Set("examples", examples)
+	}
+
+	// Add session recording parameter
+	if s.Session != "" {
+		q.Set("session", s.Session)
+	}
+
+	// Add time travel parameter
+	if s.AsOf != "" {
+		q.Set("asof", s.AsOf)
+	}
+
 	u.RawQuery = q.Encode()
 	return u.String()
 }
@@ -847,6 +1088,15 @@ func (s *Query) WithLimit(limit int) safehtml.URL {
 	return newState.ToSafeURL()
 }
 
+// WithExampleRows returns a URL showing count example rows in each leaf group of the
+// grouped view (0 = none), sampled at random or the first rows of each group
+func (s *Query) WithExampleRows(count int, random bool) safehtml.URL {
+	newState := s.Clone()
+	newState.ExampleRows = count
+	newState.RandomExampleRows = random && count > 0
+	return newState.ToSafeURL()
+}
+
 // WithGroupedColumnToggled returns a URL with the grouped column toggled
 // If the column is already grouped, it's removed from grouping
 // If the column is not grouped, it's added to the end of the grouping order
@@ -890,13 +1140,26 @@ func (s *Query) IsColumnGrouped(column string) bool {
 	return false
 }
 
+// WithFilter returns a URL with the filter for the column set to value.
+// An empty value removes the filter.
+func (s *Query) WithFilter(column, value string) safehtml.URL {
+	newState := s.Clone()
+	if value == "" {
+		delete(newState.Filters, column)
+	} else {
+		newState.Filters[column] = value
+	}
+	newState.reorderColumns()
+	return newState.ToSafeURL()
+}
+
 // WithFilterAndUngrouped returns a URL that adds a filter for the column and removes it from grouping.
 // The filter uses exact match (value wrapped in quotes) since we're filtering on a specific group value.
 func (s *Query) WithFilterAndUngrouped(column, value string) safehtml.URL {
 	newState := s.Clone()
 
 	// Add the filter with quotes for exact match
-	newState.Filters[column] = `"` + value + `"`
+	newState.Filters[column] = s.groupFilter(column, value)
 
 	// Remove column from grouping
 	newGrouped := make([]string, 0, len(s.GroupedColumns))
@@ -913,6 +1176,60 @@ func (s *Query) WithFilterAndUngrouped(column, value string) safehtml.URL {
 	return newState.ToSafeURL()
 }
 
+// groupFilter returns the filter that selects the rows of a group value: an exact match,
+// or a match on any of the values of a cohort.
+func (s *Query) groupFilter(column, value string) string {
+	if values, ok := s.CohortValues(column, value); ok && len(values) > 1 {
+		return strings.Join(values, "|")
+	} else if ok {
+		return `"` + values[0] + `"`
+	}
+	return `"` + value + `"`
+}
+
+// WithGroupRows returns a URL for the ungrouped rows of one group: the current filters
+// plus an exact filter for each grouped column on the group's value (groupValues maps
+// grouped column to value), with all columns kept visible.
+func (s *Query) WithGroupRows(groupValues map[string]string) safehtml.URL {
+	newState := s.Clone()
+	for column, value := range groupValues {
+		newState.Filters[column] = s.groupFilter(column, value)
+	}
+	newState.GroupedColumns = nil
+	newState.GroupAggregateSorts = make(map[string]*GroupAggSort)
+	newState.reorderColumns()
+	return newState.ToSafeURL()
+}
+
+// CohortValues returns the values merged into the cohort with the given label on a column.
+func (s *Query) CohortValues(column, label string) ([]string, bool) {
+	for _, c := range s.Cohorts[column] {
+		if c.Label == label {
+			return c.Values, true
+		}
+	}
+	return nil, false
+}
+
+// WithCohort returns a URL that merges values of a column into a cohort shown as label.
+// Values that are labels of existing cohorts merge those cohorts into the new one.
+func (s *Query) WithCohort(column, label string, values []string) safehtml.URL {
+	newState := s.Clone()
+	cohorts := append(newState.Cohorts[column], Cohort{Label: label, Values: values})
+	newState.Cohorts[column] = parseCohorts(formatCohorts(cohorts))
+	return newState.ToSafeURL()
+}
+
+// WithoutCohort returns a URL that splits a cohort back into its values.
+func (s *Query) WithoutCohort(column, label string) safehtml.URL {
+	newState := s.Clone()
+	newState.Cohorts[column] = slices.DeleteFunc(newState.Cohorts[column], func(c Cohort) bool { return c.Label == label })
+	if len(newState.Cohorts[column]) == 0 {
+		delete(newState.Cohorts, column)
+	}
+	return newState.ToSafeURL()
+}
+
 // WithSortToggled returns a URL with the sort direction toggled for a column.
 // Clicking cycles: ascending (move to front) -> descending -> ascending
 // The column is moved to the front of the sort order (highest priority).
@@ -994,8 +1311,8 @@ func (s *Query) WithAggregateToggled(column string, aggType AggregateType) safeh
 			}
 			newState.AggregateSettings[column] = newAggs
 		} else {
-			// Was enabled by default (count), explicitly set to empty to disable
-			newState.AggregateSettings[column] = []AggregateType{}
+			// Was enabled by default, explicitly set the other defaults to disable it
+			newState.AggregateSettings[column] = slices.DeleteFunc(s.defaultAggregates(column), func(agg AggregateType) bool { return agg == aggType })
 		}
 
 		// Remove any GroupAggSort that uses this aggregate on this column
@@ -1013,13 +1330,13 @@ func (s *Query) WithAggregateToggled(column string, aggType AggregateType) safeh
 }
 
 // IsAggregateEnabled returns true if the specified aggregate is enabled for a column.
-// Count is enabled by default when no aggregates are explicitly set.
-// If explicitly set to empty, nothing is enabled (user disabled all aggregates).
+// Count (and unique count for identifier columns) is enabled by default when no aggregates
+// are explicitly set. If explicitly set to empty, nothing is enabled (user disabled all aggregates).
 func (s *Query) IsAggregateEnabled(column string, aggType AggregateType) bool {
 	aggs, exists := s.AggregateSettings[column]
 	if !exists {
-		// No explicit settings: count is enabled by default
-		return aggType == AggCount
+		// No explicit settings: the defaults are enabled
+		return slices.Contains(s.defaultAggregates(column), aggType)
 	}
 	// Explicit settings exist (even if empty slice means nothing is enabled)
 	for _, agg := range aggs {
@@ -1031,14 +1348,15 @@ func (s *Query) IsAggregateEnabled(column string, aggType AggregateType) bool {
 }
 
 // GetEnabledAggregates returns the list of enabled aggregates for a column.
-// The results are ordered according to GetAvailableAggregates to match the toggle order in the UI.
-// If no aggregates are explicitly set (no entry in map), returns [AggCount] as the default.
+// The results are ordered according to AvailableAggregates to match the toggle order in the UI.
+// If no aggregates are explicitly set (no entry in map), returns the defaults: [AggCount], and
+// AggUnique for identifier columns.
 // If explicitly set to empty, returns nil (user disabled all aggregates including default count).
 func (s *Query) GetEnabledAggregates(column string, colType ColumnType) []AggregateType {
 	enabled, exists := s.AggregateSettings[column]
 	if !exists {
-		// No explicit settings: default to count
-		return []AggregateType{AggCount}
+		// No explicit settings: use the defaults
+		enabled = s.defaultAggregates(column)
 	}
 	if len(enabled) == 0 {
 		// Explicitly set to empty (user disabled all aggregates)
@@ -1051,8 +1369,8 @@ func (s *Query) GetEnabledAggregates(column string, colType ColumnType) []Aggreg
 		enabledSet[agg] = true
 	}
 
-	// Return aggregates in the canonical order defined by GetAvailableAggregates
-	available := GetAvailableAggregates(colType)
+	// Return aggregates in the canonical order defined by AvailableAggregates
+	available := s.AvailableAggregates(column, colType)
 	result := make([]AggregateType, 0, len(enabled))
 	for _, agg := range available {
 		if enabledSet[agg] {
@@ -1062,6 +1380,34 @@ func (s *Query) GetEnabledAggregates(column string, colType ColumnType) []Aggreg
 	return result
 }
 
+// identifierAggregates are the aggregates of numeric identifier columns: the counts
+// first, and the demoted sum, average and standard deviation last
+var identifierAggregates = []AggregateType{AggCount, AggUnique, AggMin, AggMax, AggSum, AggAvg, AggStdDev}
+
+// AvailableAggregates returns the aggregates available for a column. Numeric identifier
+// columns offer the unique count, and list the sum, average and standard deviation last.
+func (s *Query) AvailableAggregates(column string, colType ColumnType) []AggregateType {
+	if colType == ColumnTypeNumeric && s.IdentifierColumns[column] {
+		return identifierAggregates
+	}
+	return GetAvailableAggregates(colType)
+}
+
+// IsAggregateDemoted returns whether an aggregate is available but meaningless for a
+// column: the sum, average and standard deviation of identifier columns
+func (s *Query) IsAggregateDemoted(column string, aggType AggregateType) bool {
+	return s.IdentifierColumns[column] && (aggType == AggSum || aggType == AggAvg || aggType == AggStdDev)
+}
+
+// defaultAggregates returns the aggregates enabled for a column without explicit settings:
+// count, and unique count for identifier columns
+func (s *Query) defaultAggregates(column string) []AggregateType {
+	if s.IdentifierColumns[column] {
+		return []AggregateType{AggCount, AggUnique}
+	}
+	return []AggregateType{AggCount}
+}
+
 // WithNextGroupAggSort cycles through aggregate sort options for a grouped column.
 // Options cycle through: no aggregate sort -> row count -> subgroup count -> each enabled aggregate of each leaf column -> back to no aggregate sort
 // leafColumns is an ordered list of leaf column names; enabledAggs maps leaf column name to enabled aggregates.
@@ -1141,6 +1487,230 @@ func (s *Query) WithGroupAggSortDirectionToggled(groupedColumn string) safehtml.
 	return newState.ToSafeURL()
 }
 
+// ColumnDiffNames returns the names of the computed delta and percent columns
+// generated when comparing column a against column b.
+func ColumnDiffNames(a, b string) (delta, percent string) {
+	return a + "_vs_" + b + "_delta", a + "_vs_" + b + "_pct"
+}
+
+// ReferencedColumns returns the columns referenced by the query, in the order they first
+// appear in the view, grouping, filters, sorting, aggregates, cohorts, group labels, the
+// column comparison and the row link column. Computed columns are referenced by name, and
+// so are group labels: the columns their expressions use are not included.
+func (s *Query) ReferencedColumns() []string {
+	var referenced []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			referenced = append(referenced, name)
+		}
+	}
+	for _, name := range s.Columns {
+		add(name)
+	}
+	for _, name := range s.GroupedColumns {
+		add(name)
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Filters)) {
+		add(name)
+	}
+	for _, sc := range s.SortOrder {
+		add(sc.Name)
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.AggregateSettings)) {
+		add(name)
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.GroupAggregateSorts)) {
+		add(name)
+		add(s.GroupAggregateSorts[name].LeafColumn)
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Cohorts)) {
+		add(name)
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.GroupLabels)) {
+		add(name)
+	}
+	for _, name := range s.DiffColumns {
+		add(name)
+	}
+	add(s.RowLinkColumn)
+	return referenced
+}
+
+// RemoveColumns removes columns from the view and from every parameter referring to them.
+// A column comparison is dropped if either compared column is removed.
+func (s *Query) RemoveColumns(names []string) {
+	removed := func(name string) bool { return slices.Contains(names, name) }
+	s.Columns = slices.DeleteFunc(s.Columns, removed)
+	s.GroupedColumns = slices.DeleteFunc(s.GroupedColumns, removed)
+	s.SortOrder = slices.DeleteFunc(s.SortOrder, func(sc SortColumn) bool { return removed(sc.Name) })
+	for _, name := range names {
+		delete(s.ColumnWidths, name)
+		delete(s.Filters, name)
+		delete(s.AggregateSettings, name)
+		delete(s.Cohorts, name)
+		delete(s.GroupLabels, name)
+	}
+	for groupedCol, aggSort := range s.GroupAggregateSorts {
+		if removed(groupedCol) || removed(aggSort.LeafColumn) {
+			delete(s.GroupAggregateSorts, groupedCol)
+		}
+	}
+	if slices.ContainsFunc(s.DiffColumns, removed) {
+		s.DiffColumns = nil
+	}
+	if removed(s.RowLinkColumn) {
+		s.RowLinkColumn = ""
+	}
+}
+
+// AddColumnDiffComputedColumns adds the computed delta (a - b) and percent
+// (pct_diff(a, b)) columns for the compared DiffColumns, displayed right after
+// the compared columns. Columns that already exist are left unchanged.
+func (s *Query) AddColumnDiffComputedColumns() {
+	if len(s.DiffColumns) != 2 {
+		return
+	}
+	a, b := s.DiffColumns[0], s.DiffColumns[1]
+	delta, percent := ColumnDiffNames(a, b)
+	defs := []ComputedColumnDef{
+		{Name: delta, Expression: a + " - " + b},
+		{Name: percent, Expression: "round(pct_diff(" + a + ", " + b + "), 1)"},
+	}
+
+	insertAt := len(s.Columns)
+	for i, col := range s.Columns {
+		if col == a || col == b {
+			insertAt = i + 1
+		}
+	}
+	for _, def := range defs {
+		if !slices.ContainsFunc(s.ComputedColumns, func(c ComputedColumnDef) bool { return c.Name == def.Name }) {
+			s.ComputedColumns = append(s.ComputedColumns, def)
+		}
+		if !slices.Contains(s.Columns, def.Name) {
+			s.Columns = slices.Insert(slices.Clip(s.Columns), insertAt, def.Name)
+			insertAt++
+		}
+	}
+}
+
+// WithColumnDiff returns a URL comparing columns a and b within each row
+func (s *Query) WithColumnDiff(a, b string) safehtml.URL {
+	newState := s.Clone()
+	newState.DiffColumns = []string{a, b}
+	return newState.ToSafeURL()
+}
+
+// WithoutColumnDiff returns a URL without the column comparison and its generated columns
+func (s *Query) WithoutColumnDiff() safehtml.URL {
+	newState := s.Clone()
+	if len(s.DiffColumns) == 2 {
+		delta, percent := ColumnDiffNames(s.DiffColumns[0], s.DiffColumns[1])
+		generated := func(name string) bool { return name == delta || name == percent }
+		newState.Columns = slices.DeleteFunc(newState.Columns, generated)
+		newState.ComputedColumns = slices.DeleteFunc(newState.ComputedColumns, func(c ComputedColumnDef) bool { return generated(c.Name) })
+	}
+	newState.DiffColumns = nil
+	return newState.ToSafeURL()
+}
+
+// WithSession returns a URL of the current view recorded in the given session
+// (empty = stop recording)
+func (s *Query) WithSession(id string) safehtml.URL {
+	newState := s.Clone()
+	newState.Session = id
+	return newState.ToSafeURL()
+}
+
+// WithAsOf returns a URL of the current view run against the snapshot of the table
+// current at the given time (empty = current data)
+func (s *Query) WithAsOf(asOf string) safehtml.URL {
+	newState := s.Clone()
+	newState.AsOf = asOf
+	return newState.ToSafeURL()
+}
+
+// asOfLayouts are the accepted formats of the asof parameter, most precise first.
+// Times without a zone are UTC.
+var asOfLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// ParseAsOf parses the value of the asof parameter. A date without a time
+// stands for the start of that day (UTC).
+func ParseAsOf(value string) (time.Time, error) {
+	for _, layout := range asOfLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errs.New(errs.ErrInvalidQuery, "invalid asof time %q (expected e.g. 2024-01-02 or 2024-01-02T15:04:05Z)", value)
+}
+
+// ExportURL returns a URL that downloads the view's table in an export format (e.g.,
+// "sqlite"), restricted to the visible columns and filtered like the view. Only the
+// table's own columns (baseColumns) are exported, but every filter applies, including
+// filters on joined and computed columns, whose definitions the URL carries.
+func (s *Query) ExportURL(format string, baseColumns []string) safehtml.URL {
+	u := &url.URL{Path: path.Join(path.Dir(s.Path), "export")}
+	q := u.Query()
+	q.Set("tables", s.Table)
+	q.Set("format", format)
+	var exported []string
+	for _, col := range s.Columns {
+		if slices.Contains(baseColumns, col) {
+			exported = append(exported, col)
+		}
+	}
+	if len(exported) > 0 {
+		q.Set("columns:"+s.Table, strings.Join(exported, ","))
+	}
+	derived := false
+	for colName, filterValue := range s.Filters {
+		if filterValue != "" {
+			q.Set("filter:"+s.Table+"."+colName, filterValue)
+			derived = derived || !slices.Contains(baseColumns, colName)
+		}
+	}
+	if derived && len(s.ComputedColumns) > 0 {
+		q.Set("computed:"+s.Table, formatComputedColumns(s.ComputedColumns))
+	}
+	u.RawQuery = q.Encode()
+	return safehtml.URLSanitized(u.String())
+}
+
+// DetailURL returns the URL of the standalone detail card of the row of the view's table
+// whose primary key is rowID
+func (s *Query) DetailURL(rowID string) safehtml.URL {
+	u := &url.URL{Path: path.Join(path.Dir(s.Path), "detail")}
+	q := u.Query()
+	q.Set("table", s.Table)
+	q.Set("id", rowID)
+	u.RawQuery = q.Encode()
+	return safehtml.URLSanitized(u.String())
+}
+
+// IsRowExpanded checks if a flat row shows its full cell content
+func (s *Query) IsRowExpanded(key string) bool {
+	return slices.Contains(s.ExpandedRows, key)
+}
+
+// WithExpandedRowToggled returns a URL with the full cell content of a flat row shown or hidden
+func (s *Query) WithExpandedRowToggled(key string) safehtml.URL {
+	newState := s.Clone()
+	if i := slices.Index(newState.ExpandedRows, key); i >= 0 {
+		newState.ExpandedRows = slices.Delete(newState.ExpandedRows, i, i+1)
+	} else {
+		newState.ExpandedRows = append(newState.ExpandedRows, key)
+	}
+	return newState.ToSafeURL()
+}
+
 // WithSelectedRowID returns a URL with the specified row selected by its primary key ID.
 // Use empty string to deselect.
 func (s *Query) WithSelectedRowID(id string) safehtml.URL {
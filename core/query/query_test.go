@@ -19,8 +19,15 @@ limitations under the License.
 package query
 
 import (
+	"errors"
 	"net/url"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/taxinomia/core/errs"
 )
 
 // TestColumnReorderingOnGrouping tests that columns are reordered when grouping is toggled
@@ -184,4 +191,374 @@ func TestColumnReorderingOnFiltering(t *testing.T) {
 			t.Errorf("Expected columns %v, got %v", expectedColumns, q.Columns)
 		}
 	})
-}
\ No newline at end of file
+}
+// TestRowLinkColumnRoundTrip tests that the row link override survives parsing and URL generation
+func TestRowLinkColumnRoundTrip(t *testing.T) {
+	baseURL, _ := url.Parse("/table?table=test&columns=status,region&rowlink=region")
+	q := NewQuery(baseURL)
+	if q.RowLinkColumn != "region" {
+		t.Fatalf("Expected row link column 'region', got %q", q.RowLinkColumn)
+	}
+
+	reparsedURL, _ := url.Parse(q.ToURL())
+	if got := NewQuery(reparsedURL).RowLinkColumn; got != "region" {
+		t.Errorf("Expected row link column to round trip, got %q", got)
+	}
+
+	q.ClearTableSpecificState()
+	if q.RowLinkColumn != "" {
+		t.Errorf("Expected row link column to be cleared, got %q", q.RowLinkColumn)
+	}
+}
+
+func TestExpandedRowsRoundTrip(t *testing.T) {
+	baseURL, _ := url.Parse("/table?table=test&expandrows=r1&expandrows=r2")
+	q := NewQuery(baseURL)
+	if !q.IsRowExpanded("r1") || !q.IsRowExpanded("r2") || q.IsRowExpanded("r3") {
+		t.Fatalf("Expected rows r1 and r2 expanded, got %v", q.ExpandedRows)
+	}
+
+	toggled, _ := url.Parse(q.WithExpandedRowToggled("r1").String())
+	if got := NewQuery(toggled).ExpandedRows; len(got) != 1 || got[0] != "r2" {
+		t.Errorf("Expected toggling r1 to collapse it, got %v", got)
+	}
+
+	// Keys containing commas round-trip as a single row
+	toggled, _ = url.Parse(q.WithExpandedRowToggled("Smith, John").String())
+	if got := NewQuery(toggled).ExpandedRows; len(got) != 3 || got[2] != "Smith, John" {
+		t.Errorf("Expected the comma key to stay a single row, got %v", got)
+	}
+	if !q.IsRowExpanded("r1") {
+		t.Errorf("Expected toggling to leave the original query unchanged")
+	}
+
+	q.ClearTableSpecificState()
+	if len(q.ExpandedRows) != 0 {
+		t.Errorf("Expected expanded rows to be cleared, got %v", q.ExpandedRows)
+	}
+}
+
+func TestWithFilter(t *testing.T) {
+	baseURL, _ := url.Parse("/table?table=test&columns=status,region&filter:status=open")
+	q := NewQuery(baseURL)
+
+	set, _ := url.Parse(q.WithFilter("region", "north").String())
+	if got := NewQuery(set).Filters; got["region"] != "north" || got["status"] != "open" {
+		t.Errorf("Expected filters on region and status, got %v", got)
+	}
+
+	cleared, _ := url.Parse(q.WithFilter("status", "").String())
+	if got := NewQuery(cleared).Filters; len(got) != 0 {
+		t.Errorf("Expected an empty value to remove the filter, got %v", got)
+	}
+	if q.Filters["status"] != "open" {
+		t.Errorf("Expected the original query to be unchanged")
+	}
+}
+
+func TestCohortsRoundTrip(t *testing.T) {
+	baseURL, _ := url.Parse("/table?table=test&grouped=region&groupon:region=" + url.QueryEscape("US:us-east|us-central;EU:eu-west"))
+	q := NewQuery(baseURL)
+	if values, ok := q.CohortValues("region", "US"); !ok || !slices.Equal(values, []string{"us-east", "us-central"}) {
+		t.Fatalf("Expected cohort US of us-east and us-central, got %v", q.Cohorts)
+	}
+
+	reparsedURL, _ := url.Parse(q.ToURL())
+	if got := NewQuery(reparsedURL).Cohorts; !reflect.DeepEqual(got, q.Cohorts) {
+		t.Errorf("Expected cohorts to round trip, got %v", got)
+	}
+
+	// Separators in labels and values are escaped and round trip
+	special, _ := url.Parse(q.WithCohort("region", `A:B;C`, []string{"x|y", `back\slash`, "p;q:r"}).String())
+	if values, ok := NewQuery(special).CohortValues("region", `A:B;C`); !ok || !slices.Equal(values, []string{"x|y", `back\slash`, "p;q:r"}) {
+		t.Errorf("Expected escaped cohort values to round trip, got %v", NewQuery(special).Cohorts)
+	}
+
+	// Merging a cohort into a new one absorbs its values
+	merged, _ := url.Parse(q.WithCohort("region", "Americas", []string{"US", "sa-east"}).String())
+	mq := NewQuery(merged)
+	if values, ok := mq.CohortValues("region", "Americas"); !ok || !slices.Equal(values, []string{"us-east", "us-central", "sa-east"}) {
+		t.Errorf("Expected Americas to absorb US, got %v", mq.Cohorts)
+	}
+	if _, ok := mq.CohortValues("region", "US"); ok {
+		t.Errorf("Expected US to be merged away, got %v", mq.Cohorts)
+	}
+
+	split, _ := url.Parse(q.WithoutCohort("region", "US").String())
+	if got := NewQuery(split).Cohorts["region"]; len(got) != 1 || got[0].Label != "EU" {
+		t.Errorf("Expected only EU after splitting US, got %v", got)
+	}
+
+	filtered, _ := url.Parse(q.WithFilterAndUngrouped("region", "US").String())
+	if got := NewQuery(filtered).Filters["region"]; got != "us-east|us-central" {
+		t.Errorf("Expected the cohort filter to match its values, got %q", got)
+	}
+
+	q.ClearTableSpecificState()
+	if len(q.Cohorts) != 0 {
+		t.Errorf("Expected cohorts to be cleared, got %v", q.Cohorts)
+	}
+}
+
+func TestGroupLabelsRoundTrip(t *testing.T) {
+	label := `format_time(created, "2006-01 (Jan)")`
+	baseURL, _ := url.Parse("/table?table=test&columns=month,amount&grouped=month&grouplabel:month=" + url.QueryEscape(label))
+	q := NewQuery(baseURL)
+	if got := q.GroupLabels["month"]; got != label {
+		t.Fatalf("Expected label expression %q, got %q", label, got)
+	}
+
+	reparsedURL, _ := url.Parse(q.ToURL())
+	if got := NewQuery(reparsedURL).GroupLabels; !reflect.DeepEqual(got, q.GroupLabels) {
+		t.Errorf("Expected group labels to round trip, got %v", got)
+	}
+	if got := q.ReferencedColumns(); !slices.Equal(got, []string{"month", "amount"}) {
+		t.Errorf("Expected the labeled column to be referenced, got %v", got)
+	}
+
+	removed := q.Clone()
+	removed.RemoveColumns([]string{"month"})
+	if len(removed.GroupLabels) != 0 || len(q.GroupLabels) != 1 {
+		t.Errorf("Expected the label of the removed column to be dropped from the clone only, got %v and %v", removed.GroupLabels, q.GroupLabels)
+	}
+
+	q.ClearTableSpecificState()
+	if len(q.GroupLabels) != 0 {
+		t.Errorf("Expected group labels to be cleared, got %v", q.GroupLabels)
+	}
+}
+
+func TestWithGroupRows(t *testing.T) {
+	baseURL, _ := url.Parse("/table?table=test&columns=region,status,amount&grouped=region,status&filter:amount=1&groupsort:region=" + url.QueryEscape("-amount:sum") + "&groupon:region=" + url.QueryEscape("US:us-east|us-central"))
+	q := NewQuery(baseURL)
+
+	rowsURL, _ := url.Parse(q.WithGroupRows(map[string]string{"region": "US", "status": "open"}).String())
+	rows := NewQuery(rowsURL)
+	if len(rows.GroupedColumns) != 0 || len(rows.GroupAggregateSorts) != 0 {
+		t.Errorf("Expected an ungrouped view, got grouped %v, group sorts %v", rows.GroupedColumns, rows.GroupAggregateSorts)
+	}
+	want := map[string]string{"region": "us-east|us-central", "status": `"open"`, "amount": "1"}
+	if !reflect.DeepEqual(rows.Filters, want) {
+		t.Errorf("Expected filters %v, got %v", want, rows.Filters)
+	}
+	if len(rows.Columns) != 3 {
+		t.Errorf("Expected all columns to stay visible, got %v", rows.Columns)
+	}
+}
+
+func TestTargetCellIsTransient(t *testing.T) {
+	baseURL, _ := url.Parse("/table?table=test&cell=status:r1:a")
+	q := NewQuery(baseURL)
+	if q.TargetColumn != "status" || q.TargetRowKey != "r1:a" {
+		t.Fatalf("Expected target cell status/r1:a, got %q/%q", q.TargetColumn, q.TargetRowKey)
+	}
+	if strings.Contains(q.ToURL(), "cell=") {
+		t.Errorf("Expected target cell not to be persisted, got %s", q.ToURL())
+	}
+}
+
+func TestColumnDiffRoundTrip(t *testing.T) {
+	baseURL, _ := url.Parse("/table?table=test&columns=region,budget,actual&diff=budget,actual")
+	q := NewQuery(baseURL)
+	if !equalStringSlices(q.DiffColumns, []string{"budget", "actual"}) {
+		t.Fatalf("Expected diff columns budget,actual, got %v", q.DiffColumns)
+	}
+
+	q.AddColumnDiffComputedColumns()
+	expectedColumns := []string{"region", "budget", "actual", "budget_vs_actual_delta", "budget_vs_actual_pct"}
+	if !equalStringSlices(q.Columns, expectedColumns) {
+		t.Errorf("Expected columns %v, got %v", expectedColumns, q.Columns)
+	}
+	if len(q.ComputedColumns) != 2 || q.ComputedColumns[0].Expression != "budget - actual" {
+		t.Errorf("Expected delta and percent computed columns, got %v", q.ComputedColumns)
+	}
+	// Adding the generated columns again is a no-op
+	q.AddColumnDiffComputedColumns()
+	if len(q.ComputedColumns) != 2 || len(q.Columns) != len(expectedColumns) {
+		t.Errorf("Expected generated columns to be added once, got %v / %v", q.Columns, q.ComputedColumns)
+	}
+
+	cleared, _ := url.Parse(q.WithoutColumnDiff().String())
+	cq := NewQuery(cleared)
+	if len(cq.DiffColumns) != 0 || len(cq.ComputedColumns) != 0 || !equalStringSlices(cq.Columns, []string{"region", "budget", "actual"}) {
+		t.Errorf("Expected comparison to be cleared, got columns %v, diff %v, computed %v", cq.Columns, cq.DiffColumns, cq.ComputedColumns)
+	}
+
+	// Comparing a column with itself is ignored
+	sameURL, _ := url.Parse("/table?table=test&diff=budget,budget")
+	if got := NewQuery(sameURL).DiffColumns; len(got) != 0 {
+		t.Errorf("Expected self comparison to be ignored, got %v", got)
+	}
+}
+
+func TestApplyDefaultView(t *testing.T) {
+	view := NewDefaultView([]string{"amount", "region"}, []string{"status"}, []string{"-amount"}, map[string]string{"amount": "sum"})
+
+	baseURL, _ := url.Parse("/table?table=test")
+	q := NewQuery(baseURL)
+	q.ApplyDefaultView(view)
+	if !equalStringSlices(q.Columns, []string{"status", "amount", "region"}) {
+		t.Errorf("Expected grouped column first, got %v", q.Columns)
+	}
+	if len(q.SortOrder) != 1 || q.SortOrder[0].Name != "amount" || !q.SortOrder[0].Descending {
+		t.Errorf("Expected default sort, got %v", q.SortOrder)
+	}
+	if len(q.AggregateSettings["amount"]) != 1 {
+		t.Errorf("Expected default aggregates, got %v", q.AggregateSettings)
+	}
+
+	// Explicit grouping and aggregates win over the default view
+	baseURL, _ = url.Parse("/table?table=test&grouped=region&agg:amount=avg,max")
+	q = NewQuery(baseURL)
+	q.ApplyDefaultView(view)
+	if !equalStringSlices(q.GroupedColumns, []string{"region"}) || !equalStringSlices(q.Columns, []string{"region", "amount"}) {
+		t.Errorf("Expected explicit grouping to be kept, got grouped %v, columns %v", q.GroupedColumns, q.Columns)
+	}
+	if len(q.AggregateSettings["amount"]) != 2 {
+		t.Errorf("Expected explicit aggregates to be kept, got %v", q.AggregateSettings["amount"])
+	}
+}
+
+func TestWithColumnPreset(t *testing.T) {
+	capacity := ColumnPreset{Name: "capacity", Columns: []string{"cpu", "memory"}}
+	billing := ColumnPreset{Name: "billing", Columns: []string{"cost", "region"}}
+
+	baseURL, _ := url.Parse("/table?table=test&columns=cost,region&grouped=region&filter:status=up&sort=-cost")
+	q := NewQuery(baseURL)
+	presetURL, _ := url.Parse(q.WithColumnPreset(capacity).String())
+	switched := NewQuery(presetURL)
+	if !equalStringSlices(switched.Columns, []string{"region", "cpu", "memory"}) {
+		t.Errorf("Expected the grouped column and the preset columns, got %v", switched.Columns)
+	}
+	if switched.Filters["status"] != "up" || !equalStringSlices(switched.GroupedColumns, []string{"region"}) || len(switched.SortOrder) == 0 {
+		t.Errorf("Expected filters, grouping and sort to be kept, got %v, %v, %v", switched.Filters, switched.GroupedColumns, switched.SortOrder)
+	}
+
+	if !switched.IsColumnPresetActive(capacity) || switched.IsColumnPresetActive(billing) {
+		t.Error("Expected only the capacity preset to be active")
+	}
+	if !q.IsColumnPresetActive(billing) {
+		t.Error("Expected the billing preset to be active in any column order")
+	}
+	switched.Columns = append(switched.Columns, "disk")
+	if switched.IsColumnPresetActive(capacity) {
+		t.Error("Expected a preset not to be active with columns it doesn't list")
+	}
+}
+
+func TestIdentifierAggregates(t *testing.T) {
+	baseURL, _ := url.Parse("/table?table=orders&columns=customer_id,amount&grouped=region")
+	q := NewQuery(baseURL)
+	q.IdentifierColumns = map[string]bool{"customer_id": true}
+
+	if got := q.GetEnabledAggregates("customer_id", ColumnTypeNumeric); !slices.Equal(got, []AggregateType{AggCount, AggUnique}) {
+		t.Errorf("Expected count and unique by default for an identifier, got %v", got)
+	}
+	if got := q.GetEnabledAggregates("amount", ColumnTypeNumeric); !slices.Equal(got, []AggregateType{AggCount}) {
+		t.Errorf("Expected count by default for a measure, got %v", got)
+	}
+	if got := q.AvailableAggregates("customer_id", ColumnTypeNumeric); !slices.Contains(got, AggUnique) || got[len(got)-1] != AggStdDev {
+		t.Errorf("Expected unique count and demoted aggregates last, got %v", got)
+	}
+	if !q.IsAggregateDemoted("customer_id", AggSum) || q.IsAggregateDemoted("customer_id", AggUnique) || q.IsAggregateDemoted("amount", AggSum) {
+		t.Errorf("Expected only the sum of the identifier to be demoted")
+	}
+
+	// Disabling a default keeps the other defaults; an explicit setting wins over them
+	toggled, _ := url.Parse(q.WithAggregateToggled("customer_id", AggUnique).String())
+	if got := NewQuery(toggled).AggregateSettings["customer_id"]; !slices.Equal(got, []AggregateType{AggCount}) {
+		t.Errorf("Expected count to stay enabled, got %v", got)
+	}
+	explicit, _ := url.Parse("/table?table=orders&agg:customer_id=sum")
+	q = NewQuery(explicit)
+	q.IdentifierColumns = map[string]bool{"customer_id": true}
+	if got := q.GetEnabledAggregates("customer_id", ColumnTypeNumeric); !slices.Equal(got, []AggregateType{AggSum}) {
+		t.Errorf("Expected the explicit sum, got %v", got)
+	}
+}
+
+func TestRemoveColumns(t *testing.T) {
+	baseURL, _ := url.Parse("/table?table=test&columns=region,old:120,amount&grouped=region,old&filter:old=x&filter:region=north" +
+		"&sort=-old,region&agg:old=sum&agg:amount=sum&groupsort:region=-old:sum&groupon:old=a:1|2&diff=old,amount&rowlink=old")
+	q := NewQuery(baseURL)
+	if got, want := q.ReferencedColumns(), []string{"region", "old", "amount"}; !equalStringSlices(got, want) {
+		t.Errorf("ReferencedColumns = %v, want %v", got, want)
+	}
+
+	q.RemoveColumns([]string{"old"})
+	cleaned, _ := url.Parse(q.ToURL())
+	cq := NewQuery(cleaned)
+	if got := cq.ReferencedColumns(); !equalStringSlices(got, []string{"region", "amount"}) {
+		t.Errorf("columns referenced after removal = %v (URL %s)", got, q.ToURL())
+	}
+	if !equalStringSlices(cq.GroupedColumns, []string{"region"}) || cq.Filters["region"] != "north" {
+		t.Errorf("expected the grouping and filter on region to remain, got %v %v", cq.GroupedColumns, cq.Filters)
+	}
+	if len(cq.DiffColumns) != 0 || cq.RowLinkColumn != "" || len(cq.GroupAggregateSorts) != 0 {
+		t.Errorf("expected the comparison, row link and group sort on old to be dropped, got %v %q %v", cq.DiffColumns, cq.RowLinkColumn, cq.GroupAggregateSorts)
+	}
+}
+
+func TestAsOf(t *testing.T) {
+	baseURL, _ := url.Parse("/table?table=test&columns=region&asof=2024-01-02")
+	q := NewQuery(baseURL)
+	if q.AsOf != "2024-01-02" {
+		t.Fatalf("AsOf = %q, want 2024-01-02", q.AsOf)
+	}
+	if !strings.Contains(q.ToURL(), "asof=2024-01-02") {
+		t.Errorf("expected asof to be kept in %s", q.ToURL())
+	}
+	if got := q.WithAsOf("").String(); strings.Contains(got, "asof") {
+		t.Errorf("expected the current data URL to drop asof, got %s", got)
+	}
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"2024-01-02T15:04", time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{"2024-01-02T15:04:05", time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2024-01-02T15:04:05+02:00", time.Date(2024, 1, 2, 13, 4, 5, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseAsOf(tt.value)
+		if err != nil {
+			t.Errorf("ParseAsOf(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseAsOf(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+	if _, err := ParseAsOf("yesterday"); !errors.Is(err, errs.ErrInvalidQuery) {
+		t.Errorf("expected an invalid query error, got %v", err)
+	}
+}
+
+func TestUnknownParameters(t *testing.T) {
+	u, _ := url.Parse("/table?table=orders&colums=region&fitler:status=shipped&filter:region=north&limit=10&user=ann&xyz=1")
+	var got []string
+	for _, p := range UnknownParameters(u) {
+		got = append(got, p.String())
+	}
+	want := []string{
+		"colums (did you mean columns?)",
+		"fitler:status (did you mean filter:status?)",
+		"xyz",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("UnknownParameters = %q, want %q", got, want)
+	}
+
+	// A per-column prefix without a column is not a parameter
+	u, _ = url.Parse("/table?table=orders&filter:=x")
+	if unknown := UnknownParameters(u); len(unknown) != 1 || unknown[0].Name != "filter:" {
+		t.Errorf("expected filter: to be unknown, got %v", unknown)
+	}
+
+	u, _ = url.Parse("/table?table=orders&columns=a&grouped=a&agg:a=sum&groupsort:a=+b:sum&groupon:a=x:1&grouplabel:a=a&scrollY=10")
+	if unknown := UnknownParameters(u); len(unknown) != 0 {
+		t.Errorf("expected no unknown parameters, got %v", unknown)
+	}
+}
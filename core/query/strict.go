@@ -0,0 +1,133 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// knownParameters are the parameters of table URLs, including those read by the server
+// rather than by NewQuery (user, types, strict) and those only kept by the page (scrollY).
+var knownParameters = []string{
+	"table", "columns", "expanded", "grouped", "limit", "computed", "sort",
+	"info", "infotab", "_anim", "row", "expandrows", "cell", "diff", "rowlink",
+	"session", "asof", "examples", "user", "types", "strict", "scrollY",
+}
+
+// knownParameterPrefixes are the prefixes of per-column parameters (e.g., filter:status).
+var knownParameterPrefixes = []string{"filter:", "agg:", "groupsort:", "groupon:", "grouplabel:"}
+
+// UnknownParameter is a parameter of a table URL that is not read by any part of the
+// query, such as a misspelled fitler:status
+type UnknownParameter struct {
+	Name        string   // Parameter name as given in the URL
+	Suggestions []string // Nearest known parameters, empty if none is close
+}
+
+// String formats the parameter with its suggestions, e.g. "fitler:status (did you mean filter:status?)"
+func (p UnknownParameter) String() string {
+	if len(p.Suggestions) == 0 {
+		return p.Name
+	}
+	return fmt.Sprintf("%s (did you mean %s?)", p.Name, strings.Join(p.Suggestions, " or "))
+}
+
+// UnknownParameters returns the parameters of a table URL that NewQuery and the server
+// ignore, sorted by name. Lenient parsing silently drops them; strict mode rejects them.
+func UnknownParameters(u *url.URL) []UnknownParameter {
+	var unknown []UnknownParameter
+	for name := range u.Query() {
+		if isKnownParameter(name) {
+			continue
+		}
+		unknown = append(unknown, UnknownParameter{Name: name, Suggestions: suggestParameters(name)})
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Name < unknown[j].Name })
+	return unknown
+}
+
+// isKnownParameter reports whether a parameter name is read by NewQuery or the server
+func isKnownParameter(name string) bool {
+	if slices.Contains(knownParameters, name) {
+		return true
+	}
+	for _, prefix := range knownParameterPrefixes {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestParameters returns the known parameters nearest to an unknown name by edit
+// distance, at most a third of the name's length away. Per-column parameters are
+// suggested with the column of the unknown name (fitler:status -> filter:status).
+func suggestParameters(name string) []string {
+	candidates := slices.Clone(knownParameters)
+	column := name
+	if _, after, ok := strings.Cut(name, ":"); ok {
+		column = after
+	}
+	if column != "" {
+		for _, prefix := range knownParameterPrefixes {
+			candidates = append(candidates, prefix+column)
+		}
+	}
+
+	maxDistance := max(1, len(name)/3)
+	var suggestions []string
+	for _, candidate := range candidates {
+		d := editDistance(name, candidate)
+		if d > maxDistance {
+			continue
+		}
+		if d < maxDistance {
+			maxDistance = d
+			suggestions = suggestions[:0]
+		}
+		suggestions = append(suggestions, candidate)
+	}
+	sort.Strings(suggestions)
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
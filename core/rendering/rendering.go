@@ -0,0 +1,48 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rendering is kept for code written against earlier releases.
+//
+// Deprecated: rendering is an implementation detail of the server and has moved to an
+// internal package. Products customize table pages with server.Server.SetTableTemplate and
+// server.TemplateFuncs; the declarations below will be removed in a future release.
+package rendering
+
+import (
+	"github.com/google/safehtml/template"
+	"github.com/google/taxinomia/core/internal/rendering"
+)
+
+// TableRenderer renders the pages of the server.
+//
+// Deprecated: TableRenderer is internal to the server.
+type TableRenderer = rendering.TableRenderer
+
+// NewTableRenderer creates a new table renderer.
+//
+// Deprecated: TableRenderer is internal to the server.
+func NewTableRenderer() (*TableRenderer, error) {
+	return rendering.NewTableRenderer()
+}
+
+// TemplateFuncs returns the template functions available to the table template.
+//
+// Deprecated: Use server.TemplateFuncs.
+func TemplateFuncs() template.FuncMap {
+	return rendering.TemplateFuncs()
+}
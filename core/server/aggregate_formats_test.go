@@ -0,0 +1,41 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestAggregateFormats(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	amount := srv.DataModel.GetTable("orders").GetColumn("amount").ColumnDef()
+	amount.SetAggregateFormats(map[string]string{"sum": "si:2", "avg": "fixed:2"})
+
+	resp := srv.GetTable(t, "orders", "columns=region,amount&grouped=region&agg:amount=sum,avg")
+	resp.AssertStatus(t, http.StatusOK)
+	// The group of north sums 400 and averages 133.33...; changed values show the raw value
+	// in their tooltip
+	resp.AssertContains(t, `Σ400 `)
+	resp.AssertContains(t, `μ<span title="133.333333333333">133.33</span>`)
+	// South averages 100, shown with the decimals of the rule
+	resp.AssertContains(t, `μ<span title="100">100.00</span>`)
+}
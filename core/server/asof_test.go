@@ -0,0 +1,66 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/taxinomia/core/tables"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestAsOfSnapshot(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	loadedAt := time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+	srv.SetTableSnapshotResolver(func(tableName string, at time.Time) (*tables.DataTable, time.Time, error) {
+		if tableName != "orders" || at.Before(loadedAt) {
+			return nil, time.Time{}, fmt.Errorf("no retained version of %s", tableName)
+		}
+		snapshot := tables.NewDataTable()
+		snapshot.AddColumn(testsupport.StringColumn("order_id", "Order ID", "order", "o1", "o2"))
+		snapshot.AddColumn(testsupport.StringColumn("region", "Region", "region", "north", "south"))
+		snapshot.AddColumn(testsupport.StringColumn("status", "Status", "", "pending", "pending"))
+		snapshot.AddColumn(testsupport.Uint32Column("amount", "Amount", 100, 250))
+		return snapshot, loadedAt, nil
+	})
+
+	// The whole query, filters included, runs against the snapshot
+	resp := srv.GetTable(t, "orders", "columns=order_id,status&filter:status=pending&asof=2024-01-03")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("td", "data-cell-column", "order_id"); !slices.Equal(got, []string{"o1", "o2"}) {
+		t.Errorf("snapshot order ids = %v, want [o1 o2]", got)
+	}
+	resp.AssertContains(t, `data-banner="snapshot"`)
+	resp.AssertContains(t, "2024-01-02T08:00:00Z")
+
+	// The current data is served from its own cached view
+	resp = srv.GetTable(t, "orders", "columns=order_id,status&filter:status=pending")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("td", "data-cell-column", "order_id"); !slices.Equal(got, []string{"o2", "o6"}) {
+		t.Errorf("current order ids = %v, want [o2 o6]", got)
+	}
+	resp.AssertNotContains(t, `data-banner="snapshot"`)
+
+	srv.GetTable(t, "orders", "columns=order_id&asof=2023-12-31").AssertStatus(t, http.StatusNotFound)
+	srv.GetTable(t, "orders", "columns=order_id&asof=yesterday").AssertStatus(t, http.StatusBadRequest)
+}
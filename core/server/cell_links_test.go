@@ -0,0 +1,49 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestCellDeepLink(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.SetPrimaryKeyResolver(func(tableName string) string {
+		if tableName == "orders" {
+			return "order"
+		}
+		return ""
+	})
+
+	resp := srv.GetTable(t, "orders", "columns=order_id,status&cell=status:o3")
+	resp.AssertStatus(t, http.StatusOK)
+	if targets := resp.Elements("td", "id", "target-cell"); len(targets) != 1 || targets[0] != "shipped" {
+		t.Fatalf("expected the status cell of o3 to be the target, got %q", targets)
+	}
+	resp.AssertContains(t, `data-row-key="o3"`)
+	resp.AssertContains(t, `id="cell-actions"`)
+
+	// Targets that are not displayed are ignored
+	resp = srv.GetTable(t, "orders", "columns=order_id,status&cell=amount:o3")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertNotContains(t, `id="target-cell"`)
+}
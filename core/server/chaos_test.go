@@ -0,0 +1,55 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/taxinomia/core/chaos"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestInjectedFaults(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	faults := chaos.NewInjector()
+	srv.SetFaultInjector(faults)
+
+	// A join fault falls back to rendering the view without its joined columns
+	params := "columns=order_id,region.regions.region.name"
+	srv.GetTable(t, "orders", params).AssertContains(t, "North")
+	faults.Set(chaos.Join, chaos.Fault{FailureRate: 1})
+	resp := srv.GetTable(t, "orders", params)
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, `data-banner="removed-columns"`)
+	if got := resp.Elements("td", "data-cell-column", "region.regions.region.name"); len(got) != 0 {
+		t.Errorf("expected the joined column to be skipped, got %v", got)
+	}
+
+	faults.Clear(chaos.Join)
+	srv.GetTable(t, "orders", params).AssertContains(t, "North")
+	faults.Set(chaos.Render, chaos.Fault{Latency: time.Millisecond, FailureRate: 1})
+	resp = srv.GetTable(t, "orders", "")
+	resp.AssertStatus(t, http.StatusServiceUnavailable)
+	resp.AssertContains(t, "injected render failure")
+
+	faults.Clear(chaos.Render)
+	srv.GetTable(t, "orders", "").AssertStatus(t, http.StatusOK)
+}
@@ -0,0 +1,48 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestCohortGrouping(t *testing.T) {
+	srv := testsupport.NewServer(t)
+
+	resp := srv.GetTable(t, "orders", "columns=region,amount&grouped=region&agg:amount=sum&groupon:region="+url.QueryEscape("Coast:north|west"))
+	resp.AssertStatus(t, http.StatusOK)
+	cells := resp.Elements("td", "data-column", "region")
+	if len(cells) != 2 || !strings.HasPrefix(cells[0], "Coast") || !strings.HasPrefix(cells[1], "south") {
+		t.Fatalf("expected groups Coast and south, got %v", cells)
+	}
+	if !strings.Contains(cells[0], "[4]") {
+		t.Errorf("expected the Coast cohort to hold 4 orders, got %q", cells[0])
+	}
+	if amounts := resp.Elements("td", "data-column", "amount"); len(amounts) != 2 || !strings.Contains(amounts[0], "700") {
+		t.Errorf("expected the Coast cohort to sum to 700, got %v", amounts)
+	}
+	resp.AssertContains(t, `class="cohort-split"`)
+	// Filtering on the cohort filters on all of its values
+	resp.AssertContains(t, "filter%3Aregion=north%7Cwest")
+}
@@ -0,0 +1,54 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/tables"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestColumnDiff(t *testing.T) {
+	dm := testsupport.NewDataModel()
+	budgets := tables.NewDataTable()
+	budgets.AddColumn(testsupport.StringColumn("team", "Team", "", "infra", "web", "data"))
+	budgets.AddColumn(testsupport.Uint32Column("budget", "Budget", 100, 50, 80))
+	budgets.AddColumn(testsupport.Uint32Column("actual", "Actual", 120, 50, 60))
+	dm.AddTable("budgets", budgets)
+	srv := testsupport.NewServerWithDataModel(t, dm)
+
+	resp := srv.GetTable(t, "budgets", "columns=team,budget,actual&diff=actual,budget")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, `id="diff-banner"`)
+	resp.AssertContains(t, `data-col-name="actual_vs_budget_delta"`)
+	resp.AssertContains(t, `data-col-name="actual_vs_budget_pct"`)
+	if got := resp.Elements("td", "data-cell-column", "actual_vs_budget_pct"); len(got) != 3 || got[0] != "20" || got[1] != "0" || got[2] != "-25" {
+		t.Errorf("expected percent differences 20, 0, -25, got %q", got)
+	}
+	if got := resp.Elements("td", "class", " diff-cell"); len(got) != 4 {
+		t.Errorf("expected the compared cells of the two differing rows to be highlighted, got %q", got)
+	}
+
+	// Comparing non-numeric columns highlights differences without generated columns
+	resp = srv.GetTable(t, "budgets", "columns=team,budget&diff=team,budget")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertNotContains(t, "team_vs_budget_delta")
+}
@@ -0,0 +1,59 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestColumnFreshness(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	loadedAt := time.Now().Add(-10 * time.Minute)
+	for _, table := range []string{"orders", "regions"} {
+		for _, colName := range srv.DataModel.GetTable(table).GetColumnNames() {
+			updatedAt := loadedAt
+			if table == "regions" {
+				updatedAt = loadedAt.Add(-3 * time.Hour)
+			}
+			srv.DataModel.GetTable(table).GetColumn(colName).ColumnDef().SetUpdatedAt(updatedAt)
+		}
+	}
+
+	// The joined column reads a table loaded three hours before orders
+	resp := srv.GetTable(t, "orders", "columns=order_id,region.regions.region.name")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, fmt.Sprintf(`<span class="th-content" title="Updated %s (10m ago)">Order ID</span>`, loadedAt.Format(time.DateTime)))
+	resp.AssertContains(t, fmt.Sprintf(`title="Updated %s (3h ago), 3h older than the table">⏱</span>`, loadedAt.Add(-3*time.Hour).Format(time.DateTime)))
+	if got := strings.Count(resp.Body, `class="th-stale"`); got != 1 {
+		t.Errorf("expected one stale column, got %d", got)
+	}
+
+	resp = srv.GetTable(t, "_columns", "columns=table_name,column_name,updated_at&filter:column_name="+url.QueryEscape(`"name"`))
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("td", "data-cell-column", "updated_at"); len(got) != 1 || !strings.HasPrefix(got[0], loadedAt.Add(-3*time.Hour).UTC().Format("2006-01-02")) {
+		t.Errorf("updated_at of regions.name = %v, want %v", got, loadedAt.Add(-3*time.Hour))
+	}
+}
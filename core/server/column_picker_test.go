@@ -0,0 +1,65 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/taxinomia/core/tables"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestWideTableColumnPicker(t *testing.T) {
+	dm := testsupport.NewDataModel()
+	wide := tables.NewDataTable()
+	for i := 0; i < 60; i++ {
+		wide.AddColumn(testsupport.StringColumn(fmt.Sprintf("billing_%02d", i), fmt.Sprintf("billing_%02d", i), "", "x"))
+		wide.AddColumn(testsupport.StringColumn(fmt.Sprintf("shipping_%02d", i), fmt.Sprintf("shipping_%02d", i), "", "x"))
+	}
+	wide.AddColumn(testsupport.StringColumn("id", "id", "", "x"))
+	dm.AddTable("wide", wide)
+	srv := testsupport.NewServerWithDataModel(t, dm)
+
+	// 121 columns stay below the default threshold and are listed directly
+	resp := srv.GetTable(t, "wide", "columns=id")
+	resp.AssertStatus(t, http.StatusOK)
+	if groups := resp.Elements("a", "class", "column-group-header"); len(groups) != 0 {
+		t.Fatalf("expected no groups below the default threshold, got %q", groups)
+	}
+	resp.AssertContains(t, "billing_07")
+
+	srv.SetColumnPickerThreshold(100)
+	resp = srv.GetTable(t, "wide", "columns=id")
+	resp.AssertStatus(t, http.StatusOK)
+	groups := resp.Elements("a", "class", "column-group-header")
+	want := []string{"+ billing (1–50) 50", "+ billing (51–60) 10", "+ shipping (1–50) 50", "+ shipping (51–60) 10", "+ Other 1/1"}
+	if !slices.Equal(groups, want) {
+		t.Fatalf("expected groups %q, got %q", want, groups)
+	}
+	resp.AssertNotContains(t, "billing_07")
+
+	resp = srv.GetTable(t, "wide", "columns=id&expanded=@billing/1")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, "billing_07")
+	resp.AssertNotContains(t, "billing_55")
+	resp.AssertNotContains(t, "shipping_07")
+}
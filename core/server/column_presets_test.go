@@ -0,0 +1,58 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/query"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestColumnPresets(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.SetColumnPresetsResolver(func(tableName string) []query.ColumnPreset {
+		if tableName == "orders" {
+			return []query.ColumnPreset{
+				{Name: "Amounts", Columns: []string{"order_id", "amount"}},
+				{Name: "Places", Columns: []string{"order_id", "region"}},
+			}
+		}
+		return nil
+	})
+
+	// Grouped columns are ignored to find the active preset
+	resp := srv.GetTable(t, "orders", "columns=status,amount,order_id&grouped=status&filter:region=north")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("a", "class", "column-preset active"); len(got) != 1 || got[0] != "Amounts" {
+		t.Errorf("expected the Amounts preset to be active, got %q", got)
+	}
+	if got := resp.Elements("a", "class", "column-preset"); len(got) != 1 || got[0] != "Places" {
+		t.Errorf("expected a link to the Places preset, got %q", got)
+	}
+
+	resp = srv.GetTable(t, "orders", "columns=status,amount")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertNotContains(t, "column-preset active")
+
+	resp = srv.GetTable(t, "regions", "")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertNotContains(t, "data-column-presets")
+}
@@ -0,0 +1,62 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"embed"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/google/safehtml/template"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+//go:embed testdata/custom_table.html
+var customTemplateFS embed.FS
+
+func TestCustomTableTemplate(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	if err := srv.SetTableTemplate(template.TrustedFSFromEmbed(customTemplateFS), "custom_table.html", "testdata/custom_table.html"); err != nil {
+		t.Fatalf("SetTableTemplate: %v", err)
+	}
+
+	resp := srv.GetTable(t, "orders", "columns=region,amount&grouped=region&agg:amount=sum")
+	resp.AssertStatus(t, http.StatusOK)
+
+	if got, want := resp.Elements("p", "class", "group-total"), []string{"north=400", "south=200", "west=300"}; !slices.Equal(got, want) {
+		t.Errorf("group totals = %v, want %v", got, want)
+	}
+
+	links := resp.Elements("a", "class", "toggle-grouping")
+	if len(links) != 2 {
+		t.Fatalf("got %d grouping links, want 2", len(links))
+	}
+	u, err := url.Parse(links[0])
+	if err != nil {
+		t.Fatalf("parse grouping link: %v", err)
+	}
+	if got := u.Query().Get("grouped"); got != "" {
+		t.Errorf("ungroup link keeps grouped=%q", got)
+	}
+	if got := u.Query().Get("agg:amount"); got != "sum" {
+		t.Errorf("ungroup link dropped aggregates, agg:amount=%q", got)
+	}
+}
@@ -0,0 +1,52 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/taxinomia/core/models"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestDataQualityTable(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.SetDataQualityResolver(func(tableName string) []models.DataQualityRecord {
+		if tableName != "orders" {
+			return nil
+		}
+		return []models.DataQualityRecord{
+			{Table: tableName, Rule: "positive_amount", Expression: "amount > 0", Rows: testsupport.OrdersRowCount},
+			{Table: tableName, Rule: "small_amount", Expression: "amount < 200", Rows: testsupport.OrdersRowCount, Violations: 2},
+		}
+	})
+
+	resp := srv.GetTable(t, "_data_quality", "columns=table_name,rule,violations")
+	resp.AssertStatus(t, http.StatusOK)
+	rules := resp.Elements("td", "data-cell-column", "rule")
+	violations := resp.Elements("td", "data-cell-column", "violations")
+	if !slices.Equal(rules, []string{"positive_amount", "small_amount"}) {
+		t.Fatalf("rules = %v, want positive_amount and small_amount", rules)
+	}
+	if !slices.Equal(violations, []string{"0", "2"}) {
+		t.Errorf("violations = %v, want [0 2]", violations)
+	}
+}
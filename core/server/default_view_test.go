@@ -0,0 +1,57 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/query"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestTableDefaultView(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.SetTableDefaultViewResolver(func(tableName string) *query.DefaultView {
+		if tableName == "orders" {
+			return query.NewDefaultView([]string{"order_id", "amount"}, []string{"status"}, []string{"-amount"}, map[string]string{"amount": "sum"})
+		}
+		return nil
+	})
+
+	resp := srv.GetTable(t, "orders", "")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("th", "data-is-grouped", "true"); len(got) != 1 {
+		t.Errorf("expected the default view to group by status, got %q", got)
+	}
+	resp.AssertContains(t, `data-col-name="amount"`)
+	resp.AssertNotContains(t, `data-col-name="region"`)
+
+	// Requested columns replace the default view
+	resp = srv.GetTable(t, "orders", "columns=region")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertNotContains(t, `data-is-grouped="true"`)
+
+	// Product default columns take precedence
+	srv.Product.SetDefaultColumns("orders", []string{"region"})
+	resp = srv.GetTable(t, "orders", "")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertNotContains(t, `data-is-grouped="true"`)
+	resp.AssertContains(t, `data-col-name="region"`)
+}
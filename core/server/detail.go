@@ -0,0 +1,80 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/query"
+	"github.com/google/taxinomia/core/views"
+)
+
+// HandleDetailRequest serves the detail card of one row, identified by its table and
+// primary key value (table=...&id=...), as a page or with format=fragment as an HTML
+// fragment for embedding in other tools
+func (s *Server) HandleDetailRequest(w io.Writer, requestURL *url.URL, product ProductConfig, setHeader func(key, value string)) *TableHandlerResult {
+	params := requestURL.Query()
+	tableName := params.Get("table")
+	rowID := params.Get("id")
+	if tableName == "" || rowID == "" {
+		return &TableHandlerResult{StatusCode: 400, Message: "Table and id parameters are required"}
+	}
+
+	fragment := false
+	switch format := params.Get("format"); format {
+	case "fragment":
+		fragment = true
+	case "":
+	default:
+		return &TableHandlerResult{StatusCode: 400, Message: fmt.Sprintf("Unknown detail format '%s'", format)}
+	}
+
+	table := s.dataModel.GetTable(tableName)
+	if table == nil {
+		return errorResult(errs.New(errs.ErrUnknownTable, "Table '%s' not found", tableName))
+	}
+	var primaryKeyEntityType string
+	if s.primaryKeyResolver != nil {
+		primaryKeyEntityType = s.primaryKeyResolver(tableName)
+	}
+	if primaryKeyEntityType == "" {
+		return &TableHandlerResult{StatusCode: 400, Message: fmt.Sprintf("Table '%s' has no primary key", tableName)}
+	}
+
+	vm, ok := views.BuildDetailViewModel(table, tableName, rowID, primaryKeyEntityType, query.NewQuery(requestURL),
+		s.urlResolver, s.allURLsResolver, s.hierarchyContextBuilder, s.relatedTablesResolver)
+	if !ok {
+		return &TableHandlerResult{StatusCode: 404, Message: fmt.Sprintf("Row '%s' not found in table '%s'", rowID, tableName)}
+	}
+	vm.PageLocale = s.pageLocale(params.Get("user"))
+	vm.Title = product.GetTitle()
+	vm.Subtitle = product.GetSubtitle()
+	vm.Fragment = fragment
+
+	setHeader("Content-Type", "text/html; charset=utf-8")
+	if err := s.renderer.RenderDetail(w, vm); err != nil {
+		log.Printf("Detail card rendering error: %v", err)
+		return errorResult(err)
+	}
+	return nil
+}
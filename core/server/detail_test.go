@@ -0,0 +1,63 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestDetailCard(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.SetURLResolver(func(entityType, value string) string {
+		return "/entity/" + entityType + "/" + value
+	})
+	srv.SetPrimaryKeyResolver(func(tableName string) string {
+		if tableName == "orders" {
+			return "order"
+		}
+		return ""
+	})
+
+	resp := srv.Get(t, "/"+testsupport.ProductName+"/detail?table=orders&id=o4")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, "<!DOCTYPE html>")
+	resp.AssertContains(t, `<a href="/entity/region/west">west</a>`)
+	resp.AssertContains(t, `href="table?limit=25&amp;row=o4&amp;table=orders"`)
+	if got := resp.Elements("div", "title", "amount"); !slices.Equal(got, []string{"Amount"}) {
+		t.Errorf("amount field labels = %v, want [Amount]", got)
+	}
+	resp.AssertContains(t, `<div class="detail-card-value">300</div>`)
+
+	// The fragment is the card alone
+	resp = srv.Get(t, "/"+testsupport.ProductName+"/detail?table=orders&id=o4&format=fragment")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertNotContains(t, "<html")
+	resp.AssertContains(t, `<div class="taxinomia-detail" data-table="orders" data-row-id="o4">`)
+
+	// The detail panel of a selected row links to the card
+	srv.GetTable(t, "orders", "columns=order_id,status&row=o4").AssertContains(t, `href="/test/detail?id=o4&amp;table=orders"`)
+
+	srv.Get(t, "/"+testsupport.ProductName+"/detail?table=orders&id=o9").AssertStatus(t, http.StatusNotFound)
+	srv.Get(t, "/"+testsupport.ProductName+"/detail?table=regions&id=north").AssertStatus(t, http.StatusBadRequest)
+	srv.Get(t, "/"+testsupport.ProductName+"/detail?table=orders&id=o4&format=json").AssertStatus(t, http.StatusBadRequest)
+}
@@ -0,0 +1,54 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestEntityBadges(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.SetURLResolver(func(entityType, value string) string {
+		return "/entity/" + entityType + "/" + value
+	})
+	srv.SetEntityHierarchiesResolver(func() [][]string {
+		return [][]string{{"region", "order"}}
+	})
+
+	// Badges cover the entity columns of the table even when they are not displayed, root first
+	resp := srv.GetTable(t, "orders", "columns=status,amount")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("td", "class", "entity-badges"); len(got) != testsupport.OrdersRowCount {
+		t.Fatalf("expected %d badge cells, got %d", testsupport.OrdersRowCount, len(got))
+	}
+	resp.AssertContains(t, `<a href="/entity/region/north" class="entity-badge" title="Region: north"><span class="entity-badge-label">Region</span> north</a><span class="entity-badge-separator">›</span><a href="/entity/order/o1" class="entity-badge"`)
+
+	// Grouped views have no breadcrumbs
+	resp = srv.GetTable(t, "orders", "columns=region,amount&grouped=region")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertNotContains(t, `class="entity-badges"`)
+
+	srv.SetEntityBadges(false)
+	resp = srv.GetTable(t, "orders", "columns=status,amount")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertNotContains(t, `class="entity-badges"`)
+}
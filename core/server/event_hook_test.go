@@ -0,0 +1,84 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/taxinomia/core/server"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestEventHook(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	var events []server.Event
+	srv.SetEventHook(func(e server.Event) { events = append(events, e) }, nil)
+	kinds := func() []string {
+		var got []string
+		for _, e := range events {
+			got = append(got, string(e.Kind)+":"+e.Attrs["column"])
+		}
+		events = nil
+		return got
+	}
+
+	srv.GetTable(t, "orders", "columns=order_id,status&user=alice").AssertStatus(t, http.StatusOK)
+	if len(events) == 1 && (events[0].User != "alice" || events[0].Table != "orders") {
+		t.Errorf("view event = %+v, want user alice and table orders", events[0])
+	}
+	if got := kinds(); !slices.Equal(got, []string{"view_rendered:"}) {
+		t.Errorf("plain view events = %v", got)
+	}
+
+	srv.GetTable(t, "orders", "columns=order_id,status,region.regions.region.name&filter:status=shipped").AssertStatus(t, http.StatusOK)
+	if got := kinds(); !slices.Equal(got, []string{"join_added:region.regions.region.name", "filter_applied:status", "view_rendered:"}) {
+		t.Errorf("join and filter events = %v", got)
+	}
+
+	// Unchanged filters and joins are not reported again, and filter values never are
+	srv.GetTable(t, "orders", "columns=order_id,status,region.regions.region.name&filter:status=shipped").AssertStatus(t, http.StatusOK)
+	last := events[len(events)-1]
+	if got := kinds(); !slices.Equal(got, []string{"view_rendered:"}) {
+		t.Errorf("repeated view events = %v", got)
+	}
+	if last.Attrs["rows"] != "3" {
+		t.Errorf("view event = %+v, want 3 rows", last)
+	}
+	for _, v := range last.Attrs {
+		if strings.Contains(v, "shipped") {
+			t.Errorf("event attributes %v leak a filter value", last.Attrs)
+		}
+	}
+
+	srv.Get(t, "/test/export?tables=orders&format=sqlite").AssertStatus(t, http.StatusOK)
+	if len(events) != 1 || events[0].Kind != server.EventExportTaken || events[0].Attrs["tables"] != "orders" || events[0].Attrs["format"] != "sqlite" {
+		t.Errorf("export events = %+v", events)
+	}
+	events = nil
+
+	// Sampled out kinds are not passed to the hook
+	srv.SetEventHook(func(e server.Event) { events = append(events, e) }, map[server.EventKind]float64{server.EventViewRendered: 0})
+	srv.GetTable(t, "orders", "columns=order_id,amount").AssertStatus(t, http.StatusOK)
+	if len(events) != 0 {
+		t.Errorf("expected view events to be sampled out, got %+v", events)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// EventKind identifies an interaction reported to the event hook
+type EventKind string
+
+// Interaction events. Attributes of each kind are listed next to it.
+const (
+	EventViewRendered  EventKind = "view_rendered"  // view (see ViewSignature), rows, duration_ms
+	EventFilterApplied EventKind = "filter_applied" // column
+	EventJoinAdded     EventKind = "join_added"     // column (the joined column path)
+	EventExportTaken   EventKind = "export_taken"   // tables, format
+)
+
+// Event is a structured record of a user interaction. Filter values are not reported,
+// so that events can be fed to analytics systems with a broader audience than the data.
+type Event struct {
+	Kind  EventKind         `json:"kind"`
+	At    time.Time         `json:"at"`
+	User  string            `json:"user,omitempty"`  // Value of the user parameter (empty if none)
+	Table string            `json:"table,omitempty"` // Table of the view (empty for exports)
+	Attrs map[string]string `json:"attrs,omitempty"` // Kind-specific attributes
+}
+
+// EventHook receives interaction events. It is called synchronously on the request
+// path, from concurrent requests: hooks that send events elsewhere should queue them.
+type EventHook func(Event)
+
+// NewJSONEventHook returns a hook writing each event to w as a line of JSON.
+// Write errors are ignored, as events must not fail requests.
+func NewJSONEventHook(w io.Writer) EventHook {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		enc.Encode(e)
+	}
+}
+
+// SetEventHook sets the hook receiving interaction events (nil = no events, the default).
+// sampleRates maps event kinds to the share in [0, 1] of their events passed to the hook;
+// events of other kinds are all passed.
+func (s *Server) SetEventHook(hook EventHook, sampleRates map[EventKind]float64) {
+	s.eventHook = hook
+	s.eventSampleRates = sampleRates
+}
+
+// emit passes an event to the event hook, subject to sampling
+func (s *Server) emit(kind EventKind, user, table string, attrs map[string]string) {
+	if s.eventHook == nil {
+		return
+	}
+	if rate, ok := s.eventSampleRates[kind]; ok && rand.Float64() >= rate {
+		return
+	}
+	s.eventHook(Event{Kind: kind, At: time.Now(), User: user, Table: table, Attrs: attrs})
+}
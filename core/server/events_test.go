@@ -0,0 +1,39 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONEventHook(t *testing.T) {
+	var out strings.Builder
+	hook := NewJSONEventHook(&out)
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	hook(Event{Kind: EventFilterApplied, At: at, Table: "orders", Attrs: map[string]string{"column": "status"}})
+	hook(Event{Kind: EventExportTaken, At: at, Attrs: map[string]string{"tables": "orders", "format": "csv"}})
+
+	want := `{"kind":"filter_applied","at":"2024-05-01T09:00:00Z","table":"orders","attrs":{"column":"status"}}` + "\n" +
+		`{"kind":"export_taken","at":"2024-05-01T09:00:00Z","attrs":{"format":"csv","tables":"orders"}}` + "\n"
+	if out.String() != want {
+		t.Errorf("event log = %q, want %q", out.String(), want)
+	}
+}
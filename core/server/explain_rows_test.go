@@ -0,0 +1,80 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+var explainLinkPattern = regexp.MustCompile(`href="([^"]*)" class="explain-link"`)
+
+func TestExplainAggregateRows(t *testing.T) {
+	srv := testsupport.NewServer(t)
+
+	params := "columns=region,status,amount,double,region.regions.region.population&grouped=region,status" +
+		"&agg:amount=sum&agg:double=sum&agg:region.regions.region.population=sum" +
+		"&computed=" + url.QueryEscape("double=amount * 2")
+	resp := srv.GetTable(t, "orders", params)
+	resp.AssertStatus(t, http.StatusOK)
+
+	var links []string
+	for _, m := range explainLinkPattern.FindAllStringSubmatch(resp.Body, -1) {
+		links = append(links, html.UnescapeString(m[1]))
+	}
+	// The north group cell, then the amount, computed and joined cells of north/pending
+	if len(links) < 4 {
+		t.Fatalf("expected at least 4 explain links, got %d", len(links))
+	}
+
+	north := srv.Get(t, links[0])
+	north.AssertStatus(t, http.StatusOK)
+	if got := north.Attr("data-total-rows"); got != "3" {
+		t.Errorf("expected the 3 north orders, got %s", got)
+	}
+	north.AssertNotContains(t, `data-is-grouped="true"`)
+
+	for _, link := range links[1:4] {
+		u, err := url.Parse(link)
+		if err != nil {
+			t.Fatalf("parse explain link: %v", err)
+		}
+		q := u.Query()
+		if q.Get("filter:region") != `"north"` || q.Get("filter:status") != `"pending"` || q.Get("grouped") != "" {
+			t.Errorf("expected an ungrouped view filtered on north/pending, got %s", link)
+		}
+		if q.Get("computed") == "" || !strings.Contains(q.Get("columns"), "region.regions.region.population") {
+			t.Errorf("expected computed and joined columns to stay in the view, got %s", link)
+		}
+		pending := srv.Get(t, link)
+		if got := pending.Attr("data-total-rows"); got != "2" {
+			t.Errorf("expected the 2 pending north orders, got %s", got)
+		}
+		if got := pending.Elements("td", "data-cell-column", "amount"); !slices.Equal(got, []string{"250", "50"}) {
+			t.Errorf("expected amounts 250 and 50, got %v", got)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/expr"
+	"github.com/google/taxinomia/core/models"
+	"github.com/google/taxinomia/core/query"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// MaxExportTables is the maximum number of tables in one export bundle
+const MaxExportTables = 10
+
+// ExportManifestFile is the name of the manifest in an export bundle
+const ExportManifestFile = "manifest.json"
+
+// ExportManifest describes an export bundle: the version of each table it holds,
+// how the tables were filtered, and how they join.
+type ExportManifest struct {
+	CreatedAt time.Time       `json:"created_at"`
+	Tables    []ExportedTable `json:"tables"`
+	Joins     []ExportedJoin  `json:"joins"`
+}
+
+// ExportedTable is one table of an export bundle
+type ExportedTable struct {
+	Name     string            `json:"name"`
+	File     string            `json:"file"`
+	Version  int               `json:"version,omitempty"`   // 0 if unknown
+	LoadedAt string            `json:"loaded_at,omitempty"` // Empty if unknown
+	Rows     int               `json:"rows"`
+	Filters  map[string]string `json:"filters,omitempty"`
+}
+
+// ExportedJoin is a join between two tables of an export bundle
+type ExportedJoin struct {
+	FromTable  string `json:"from_table"`
+	FromColumn string `json:"from_column"`
+	ToTable    string `json:"to_table"`
+	ToColumn   string `json:"to_column"`
+	EntityType string `json:"entity_type"`
+	Fuzzy      bool   `json:"fuzzy,omitempty"`
+}
+
+// exportSnapshot holds the tables and joins of an export, captured together so
+// that a table reloaded during the export does not mix versions.
+type exportSnapshot struct {
+	names  []string
+	tables map[string]*tables.DataTable
+	joins  []*models.Join
+}
+
+// HandleExportRequest writes a zip bundle with one CSV file per requested table and
+// a manifest. The "tables" parameter lists the tables; "filter:table.column=value"
+// parameters filter them like table view filters, including filters on joined columns
+// and on computed columns defined by a "computed:table=name=expr;..." parameter. Rows whose join key refers to a
+// row filtered out of another exported table are dropped as well, so that the
+// bundle is referentially consistent (e.g., jobs of one cell with only their tasks).
+// "columns:table=a,b" parameters restrict the exported columns of a table, and
+// "format=sqlite" writes the tables into one SQLite database instead of a bundle.
+func (s *Server) HandleExportRequest(w io.Writer, requestURL *url.URL, product ProductConfig, setHeader func(key, value string)) *TableHandlerResult {
+	start := time.Now()
+	params := requestURL.Query()
+
+	var names []string
+	for _, name := range strings.Split(params.Get("tables"), ",") {
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return &TableHandlerResult{StatusCode: 400, Message: "Tables parameter is required"}
+	}
+	if len(names) > MaxExportTables {
+		return &TableHandlerResult{StatusCode: 400, Message: fmt.Sprintf("Too many tables (max %d)", MaxExportTables)}
+	}
+	format := params.Get("format")
+	if format != "" && format != "csv" && format != "sqlite" {
+		return &TableHandlerResult{StatusCode: 400, Message: fmt.Sprintf("Unknown export format '%s'", format)}
+	}
+
+	snapshot, err := s.snapshotTables(names)
+	if err != nil {
+		return errorResult(err)
+	}
+
+	filters, err := parseExportFilters(params, snapshot)
+	if err != nil {
+		return errorResult(err)
+	}
+	views, err := s.exportViews(params, snapshot, filters)
+	if err != nil {
+		return errorResult(err)
+	}
+
+	columns, err := parseExportColumns(params, snapshot)
+	if err != nil {
+		return errorResult(err)
+	}
+
+	kept := selectExportRows(snapshot, views, filters)
+	rowsScanned := 0
+	for _, name := range snapshot.names {
+		rowsScanned += views[name].TakeRowsScanned()
+		for _, ok := range kept[name] {
+			if ok {
+				rowsScanned++
+			}
+		}
+	}
+	out := &countingWriter{w: w}
+	defer func() { s.recordQueryCost(product, params.Get("user"), start, rowsScanned, out.n) }()
+
+	exportFormat := format
+	if exportFormat == "" {
+		exportFormat = "csv"
+	}
+	s.emit(EventExportTaken, params.Get("user"), "", map[string]string{
+		"tables": strings.Join(snapshot.names, ","),
+		"format": exportFormat,
+	})
+	if format == "sqlite" {
+		return writeSQLiteExport(out, snapshot, kept, columns, setHeader)
+	}
+
+	manifest := ExportManifest{CreatedAt: time.Now().UTC()}
+	for _, join := range snapshot.joins {
+		manifest.Joins = append(manifest.Joins, ExportedJoin{
+			FromTable:  join.FromTableName,
+			FromColumn: join.FromColumnName,
+			ToTable:    join.ToTableName,
+			ToColumn:   join.ToColumnName,
+			EntityType: join.EntityType,
+			Fuzzy:      join.IsFuzzy(),
+		})
+	}
+
+	setHeader("Content-Type", "application/zip")
+	setHeader("Content-Disposition", `attachment; filename="export.zip"`)
+	bundle := zip.NewWriter(out)
+	for _, name := range snapshot.names {
+		exported := ExportedTable{Name: name, File: name + ".csv", Filters: filters[name]}
+		if s.versionResolver != nil {
+			exported.Version = s.versionResolver(name)
+		}
+		if s.freshnessResolver != nil {
+			if loadedAt := s.freshnessResolver(name); !loadedAt.IsZero() {
+				exported.LoadedAt = loadedAt.UTC().Format(time.RFC3339)
+			}
+		}
+		file, err := bundle.Create(exported.File)
+		if err != nil {
+			return errorResult(err)
+		}
+		if exported.Rows, err = writeTableCSV(file, snapshot.tables[name], columns[name], kept[name]); err != nil {
+			log.Printf("Export of %s failed: %v", name, err)
+			return errorResult(err)
+		}
+		manifest.Tables = append(manifest.Tables, exported)
+	}
+
+	file, err := bundle.Create(ExportManifestFile)
+	if err != nil {
+		return errorResult(err)
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return errorResult(err)
+	}
+	if err := bundle.Close(); err != nil {
+		return errorResult(err)
+	}
+	return nil
+}
+
+// snapshotTables captures the named tables and the joins between them
+func (s *Server) snapshotTables(names []string) (*exportSnapshot, error) {
+	snapshot := &exportSnapshot{names: names, tables: make(map[string]*tables.DataTable, len(names))}
+	for _, name := range names {
+		table := s.dataModel.GetTable(name)
+		if table == nil {
+			return nil, errs.New(errs.ErrUnknownTable, "Table '%s' not found", name)
+		}
+		snapshot.tables[name] = table
+	}
+	for _, join := range s.dataModel.GetJoins() {
+		// Joins discovered before a reload still point to the previous table
+		if snapshot.tables[join.FromTableName] == join.FromTable && snapshot.tables[join.ToTableName] == join.ToTable &&
+			join.FromTable != nil && join.ToTable != nil {
+			snapshot.joins = append(snapshot.joins, join)
+		}
+	}
+	sort.Slice(snapshot.joins, func(i, j int) bool { return snapshot.joins[i].Key < snapshot.joins[j].Key })
+	return snapshot, nil
+}
+
+// parseExportFilters extracts the "filter:table.column=value" parameters per table
+func parseExportFilters(params url.Values, snapshot *exportSnapshot) (map[string]map[string]string, error) {
+	filters := make(map[string]map[string]string)
+	for key, values := range params {
+		ref, ok := strings.CutPrefix(key, "filter:")
+		if !ok || len(values) == 0 {
+			continue
+		}
+		tableName, colName, ok := strings.Cut(ref, ".")
+		if !ok || snapshot.tables[tableName] == nil {
+			return nil, errs.New(errs.ErrUnknownColumn, "Filter column '%s' is not in an exported table", ref)
+		}
+		if filters[tableName] == nil {
+			filters[tableName] = make(map[string]string)
+		}
+		filters[tableName][colName] = values[0]
+	}
+	return filters, nil
+}
+
+// exportViews returns a table view per exported table with the joined and computed
+// columns its filters need, and checks that every filter column exists in the view
+func (s *Server) exportViews(params url.Values, snapshot *exportSnapshot, filters map[string]map[string]string) (map[string]*tables.TableView, error) {
+	views := make(map[string]*tables.TableView, len(snapshot.names))
+	for _, name := range snapshot.names {
+		tableView := tables.NewTableView(snapshot.tables[name], name)
+		views[name] = tableView
+		computed := query.ParseComputedColumns(params.Get("computed:" + name))
+
+		// Join the columns that filters and computed expressions refer to
+		var joined []string
+		for colName := range filters[name] {
+			joined = append(joined, colName)
+		}
+		for _, comp := range computed {
+			compiled, err := expr.Compile(comp.Expression)
+			if err != nil {
+				return nil, errs.New(errs.ErrInvalidQuery, "Computed column '%s' of table '%s': %v", comp.Name, name, err)
+			}
+			joined = append(joined, compiled.Columns()...)
+		}
+		for colName, err := range tableView.UpdateJoinedColumns(joined, s.dataModel) {
+			return nil, errs.New(errs.ErrUnknownColumn, "Joined column '%s' of table '%s': %v", colName, name, err)
+		}
+		for _, comp := range computed {
+			if err := s.createComputedColumn(tableView, comp.Name, comp.Expression); err != nil {
+				return nil, errs.New(errs.ErrInvalidQuery, "Computed column '%s' of table '%s': %v", comp.Name, name, err)
+			}
+		}
+
+		for colName := range filters[name] {
+			if tableView.GetColumn(colName) == nil {
+				return nil, errs.New(errs.ErrUnknownColumn, "Column '%s' not found in table '%s'", colName, name)
+			}
+		}
+	}
+	return views, nil
+}
+
+// parseExportColumns extracts the "columns:table=a,b" parameters: the columns to export
+// per table, in order. Tables without the parameter export all their columns.
+func parseExportColumns(params url.Values, snapshot *exportSnapshot) (map[string][]string, error) {
+	columns := make(map[string][]string)
+	for key, values := range params {
+		tableName, ok := strings.CutPrefix(key, "columns:")
+		if !ok || len(values) == 0 {
+			continue
+		}
+		table := snapshot.tables[tableName]
+		if table == nil {
+			return nil, errs.New(errs.ErrUnknownColumn, "Columns requested for '%s', which is not an exported table", tableName)
+		}
+		for _, colName := range strings.Split(values[0], ",") {
+			if colName == "" || slices.Contains(columns[tableName], colName) {
+				continue
+			}
+			if table.GetColumn(colName) == nil {
+				return nil, errs.New(errs.ErrUnknownColumn, "Column '%s' not found in table '%s'", colName, tableName)
+			}
+			columns[tableName] = append(columns[tableName], colName)
+		}
+	}
+	return columns, nil
+}
+
+// exportColumns returns the columns to export from a table: the requested ones, or
+// all columns sorted by name
+func exportColumns(table *tables.DataTable, requested []string) []string {
+	if len(requested) > 0 {
+		return requested
+	}
+	colNames := table.GetColumnNames()
+	sort.Strings(colNames)
+	return colNames
+}
+
+// selectExportRows returns the rows to export per table: the rows passing the table's
+// filters, minus rows whose join key refers to a row that is not exported. Rows whose
+// key matches no row at all are kept, as they were not consistent to begin with.
+func selectExportRows(snapshot *exportSnapshot, views map[string]*tables.TableView, filters map[string]map[string]string) map[string][]bool {
+	kept := make(map[string][]bool, len(snapshot.names))
+	for _, name := range snapshot.names {
+		rows := make([]bool, snapshot.tables[name].Length())
+		tableView := views[name]
+		tableView.ApplyFilters(filters[name])
+		for _, i := range tableView.GetFilteredIndices() {
+			rows[i] = true
+		}
+		kept[name] = rows
+	}
+
+	// Propagate exclusions along joins until no more rows are dropped
+	for changed := true; changed; {
+		changed = false
+		for _, join := range snapshot.joins {
+			from, to := kept[join.FromTableName], kept[join.ToTableName]
+			for i, ok := range from {
+				if !ok {
+					continue
+				}
+				if target, err := join.Joiner.Lookup(uint32(i)); err == nil && !to[target] {
+					from[i] = false
+					changed = true
+				}
+			}
+		}
+	}
+	return kept
+}
+
+// writeTableCSV writes the kept rows of a table as CSV with a header of the exported
+// column names, and returns the number of rows written
+func writeTableCSV(w io.Writer, table *tables.DataTable, requested []string, kept []bool) (int, error) {
+	colNames := exportColumns(table, requested)
+
+	out := csv.NewWriter(w)
+	if err := out.Write(colNames); err != nil {
+		return 0, err
+	}
+	rows := 0
+	record := make([]string, len(colNames))
+	for i, ok := range kept {
+		if !ok {
+			continue
+		}
+		for c, colName := range colNames {
+			value, err := table.GetColumn(colName).GetString(uint32(i))
+			if err != nil {
+				return rows, err
+			}
+			record[c] = value
+		}
+		if err := out.Write(record); err != nil {
+			return rows, err
+		}
+		rows++
+	}
+	out.Flush()
+	return rows, out.Error()
+}
@@ -0,0 +1,101 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/taxinomia/core/server"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestExportBundle(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.SetTableVersionResolver(func(tableName string) int { return 7 })
+
+	filter := url.QueryEscape(`"north"`)
+	resp := srv.Get(t, "/"+testsupport.ProductName+"/export?tables=orders,regions&filter:regions.region="+filter)
+	resp.AssertStatus(t, http.StatusOK)
+	if ct := resp.Header.Get("Content-Type"); ct != "application/zip" {
+		t.Fatalf("expected a zip bundle, got %q", ct)
+	}
+
+	bundle, err := zip.NewReader(strings.NewReader(resp.Body), int64(len(resp.Body)))
+	if err != nil {
+		t.Fatalf("failed to open bundle: %v", err)
+	}
+	files := make(map[string][][]string)
+	var manifest server.ExportManifest
+	for _, f := range bundle.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		if f.Name == server.ExportManifestFile {
+			err = json.NewDecoder(r).Decode(&manifest)
+		} else {
+			files[f.Name], err = csv.NewReader(r).ReadAll()
+		}
+		r.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", f.Name, err)
+		}
+	}
+
+	// Orders are restricted to the exported region
+	orders := files["orders.csv"]
+	if len(orders) != 4 || !slices.Equal(orders[0], []string{"amount", "order_id", "region", "status"}) {
+		t.Fatalf("expected header and 3 north orders, got %q", orders)
+	}
+	for _, row := range orders[1:] {
+		if row[2] != "north" {
+			t.Errorf("expected only north orders, got %q", row)
+		}
+	}
+	if regions := files["regions.csv"]; len(regions) != 2 || regions[1][2] != "north" {
+		t.Errorf("expected only the north region, got %q", regions)
+	}
+
+	if len(manifest.Tables) != 2 || manifest.Tables[0].Name != "orders" || manifest.Tables[0].Rows != 3 || manifest.Tables[0].Version != 7 {
+		t.Errorf("unexpected manifest tables %+v", manifest.Tables)
+	}
+	if manifest.Tables[1].Filters["region"] != `"north"` {
+		t.Errorf("expected the regions filter in the manifest, got %+v", manifest.Tables[1])
+	}
+	if len(manifest.Joins) != 1 || manifest.Joins[0] != (server.ExportedJoin{
+		FromTable: "orders", FromColumn: "region", ToTable: "regions", ToColumn: "region", EntityType: "region",
+	}) {
+		t.Errorf("unexpected manifest joins %+v", manifest.Joins)
+	}
+}
+
+func TestExportBundleErrors(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.Get(t, "/"+testsupport.ProductName+"/export").AssertStatus(t, http.StatusBadRequest)
+	srv.Get(t, "/"+testsupport.ProductName+"/export?tables=missing").AssertStatus(t, http.StatusNotFound)
+	srv.Get(t, "/"+testsupport.ProductName+"/export?tables=orders&filter:regions.region=north").AssertStatus(t, http.StatusBadRequest)
+	srv.Get(t, "/"+testsupport.ProductName+"/export?tables=orders&filter:orders.missing=x").AssertStatus(t, http.StatusBadRequest)
+}
@@ -0,0 +1,89 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/sqlite"
+	"github.com/google/taxinomia/core/sqlquery"
+)
+
+// writeSQLiteExport writes the kept rows of the exported tables into one SQLite
+// database. Columns keep their types: integers and floats are stored as numbers,
+// booleans as 0 and 1, and times as UTC text. Entity columns, which tables join on,
+// are indexed; the index is unique for key columns.
+func writeSQLiteExport(w io.Writer, snapshot *exportSnapshot, kept map[string][]bool, requested map[string][]string, setHeader func(key, value string)) *TableHandlerResult {
+	dbTables := make([]sqlite.Table, 0, len(snapshot.names))
+	for _, name := range snapshot.names {
+		table := snapshot.tables[name]
+		colNames := exportColumns(table, requested[name])
+		dbTable := sqlite.Table{Name: name}
+		cols := make([]columns.IDataColumn, len(colNames))
+		for i, colName := range colNames {
+			col := table.GetColumn(colName)
+			cols[i] = col
+			dbTable.Columns = append(dbTable.Columns, sqlite.Column{Name: colName, Type: sqliteType(sqlquery.ColumnKind(col))})
+			if col.ColumnDef().EntityType() != "" {
+				dbTable.Indexes = append(dbTable.Indexes, sqlite.Index{
+					Name:   "idx_" + name + "_" + colName,
+					Column: colName,
+					Unique: col.IsKey(),
+				})
+			}
+		}
+		for i, ok := range kept[name] {
+			if !ok {
+				continue
+			}
+			row := make([]any, len(cols))
+			for c, col := range cols {
+				row[c] = sqlquery.ColumnValue(col, uint32(i))
+			}
+			dbTable.Rows = append(dbTable.Rows, row)
+		}
+		dbTables = append(dbTables, dbTable)
+	}
+
+	// The database is built before anything is sent, so that a failure can still be
+	// reported with a status code
+	var db bytes.Buffer
+	if err := sqlite.Write(&db, dbTables); err != nil {
+		return errorResult(err)
+	}
+	setHeader("Content-Type", "application/vnd.sqlite3")
+	setHeader("Content-Disposition", `attachment; filename="export.db"`)
+	if _, err := db.WriteTo(w); err != nil {
+		return errorResult(err)
+	}
+	return nil
+}
+
+// sqliteType returns the SQLite column type of a kind of value
+func sqliteType(kind sqlquery.Kind) sqlite.Type {
+	switch kind {
+	case sqlquery.KindInt64, sqlquery.KindBool:
+		return sqlite.Integer
+	case sqlquery.KindFloat64:
+		return sqlite.Real
+	}
+	return sqlite.Text
+}
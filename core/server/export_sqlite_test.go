@@ -0,0 +1,137 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestExportSQLite(t *testing.T) {
+	srv := testsupport.NewServer(t)
+
+	// The table page links to the export of its filtered view
+	filter := url.QueryEscape(`"north"`)
+	resp := srv.GetTable(t, "orders", "columns=order_id,amount,region.regions.region.name&filter:region="+filter)
+	resp.AssertStatus(t, http.StatusOK)
+	var exportURL string
+	if m := regexp.MustCompile(`href="([^"]*format=sqlite[^"]*)"`).FindStringSubmatch(resp.Body); m != nil {
+		exportURL = html.UnescapeString(m[1])
+	}
+	link, err := url.Parse(exportURL)
+	if err != nil || link.Path != "/"+testsupport.ProductName+"/export" {
+		t.Fatalf("expected a SQLite export link, got %q", exportURL)
+	}
+	params := link.Query()
+	if params.Get("tables") != "orders" || params.Get("columns:orders") != "order_id,amount" || params.Get("filter:orders.region") != `"north"` {
+		t.Errorf("unexpected export parameters %v", params)
+	}
+
+	resp = srv.Get(t, exportURL)
+	resp.AssertStatus(t, http.StatusOK)
+	if ct := resp.Header.Get("Content-Type"); ct != "application/vnd.sqlite3" {
+		t.Fatalf("expected a SQLite database, got %q", ct)
+	}
+	if !strings.HasPrefix(resp.Body, "SQLite format 3\x00") {
+		t.Fatalf("expected a SQLite header, got %q", resp.Body[:min(16, len(resp.Body))])
+	}
+
+	// SQLite reads the database back, if it is installed
+	if _, err := exec.LookPath("sqlite3"); err == nil {
+		path := filepath.Join(t.TempDir(), "export.db")
+		if err := os.WriteFile(path, []byte(resp.Body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		out, err := exec.Command("sqlite3", path, "PRAGMA integrity_check;",
+			"SELECT sql FROM sqlite_schema ORDER BY rowid;",
+			"SELECT order_id, amount, typeof(amount) FROM orders ORDER BY order_id;").CombinedOutput()
+		want := `ok
+CREATE TABLE "orders" ("order_id" TEXT, "amount" INTEGER)
+CREATE UNIQUE INDEX "idx_orders_order_id" ON "orders" ("order_id")
+o1|100|integer
+o2|250|integer
+o6|50|integer
+`
+		if err != nil || string(out) != want {
+			t.Errorf("sqlite3: %v:\n%s", err, out)
+		}
+	}
+
+	srv.Get(t, "/"+testsupport.ProductName+"/export?tables=orders&format=xml").AssertStatus(t, http.StatusBadRequest)
+	srv.Get(t, "/"+testsupport.ProductName+"/export?tables=orders&format=sqlite&columns:orders=missing").AssertStatus(t, http.StatusBadRequest)
+	srv.Get(t, "/"+testsupport.ProductName+"/export?tables=orders&format=sqlite&columns:regions=name").AssertStatus(t, http.StatusBadRequest)
+}
+
+func TestExportDerivedFilters(t *testing.T) {
+	srv := testsupport.NewServer(t)
+
+	// Filters on joined and computed columns are part of the export link
+	name, double := url.QueryEscape(`"South"`), url.QueryEscape(`"250"`)
+	resp := srv.GetTable(t, "orders", "columns=order_id,amount,double,region.regions.region.name&computed=double=amount*2&filter:region.regions.region.name="+name+"&filter:double="+double)
+	resp.AssertStatus(t, http.StatusOK)
+	var exportURL string
+	if m := regexp.MustCompile(`href="([^"]*format=sqlite[^"]*)"`).FindStringSubmatch(resp.Body); m != nil {
+		exportURL = html.UnescapeString(m[1])
+	}
+	link, err := url.Parse(exportURL)
+	if err != nil {
+		t.Fatalf("expected a SQLite export link, got %q", exportURL)
+	}
+	params := link.Query()
+	if params.Get("columns:orders") != "order_id,amount" || params.Get("filter:orders.region.regions.region.name") != `"South"` ||
+		params.Get("filter:orders.double") != `"250"` || params.Get("computed:orders") != "double=amount*2" {
+		t.Errorf("unexpected export parameters %v", params)
+	}
+
+	// The bundle only holds the rows of the view
+	params.Del("format")
+	resp = srv.Get(t, link.Path+"?"+params.Encode())
+	resp.AssertStatus(t, http.StatusOK)
+	bundle, err := zip.NewReader(strings.NewReader(resp.Body), int64(len(resp.Body)))
+	if err != nil {
+		t.Fatalf("failed to open bundle: %v", err)
+	}
+	r, err := bundle.Open("orders.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	orders, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"order_id", "amount"}, {"o5", "125"}}; !slices.EqualFunc(orders, want, slices.Equal) {
+		t.Errorf("exported orders = %q, want %q", orders, want)
+	}
+
+	srv.Get(t, "/"+testsupport.ProductName+"/export?tables=orders&filter:orders.double=x&computed:orders=double=amount*").AssertStatus(t, http.StatusBadRequest)
+	srv.Get(t, "/"+testsupport.ProductName+"/export?tables=orders&filter:orders.region.regions.region.missing=x").AssertStatus(t, http.StatusBadRequest)
+}
@@ -0,0 +1,131 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/expr"
+	"github.com/google/taxinomia/core/query"
+)
+
+// ExpressionCheck is the response of the expression endpoint: whether an expression
+// compiles and type checks against the columns of a table, and what it returns
+type ExpressionCheck struct {
+	Expression string            `json:"expression"`
+	Valid      bool              `json:"valid"`
+	ResultType string            `json:"result_type,omitempty"` // Type of the result, set when valid ("unknown" if it depends on string columns)
+	Columns    []string          `json:"columns,omitempty"`     // Columns the expression references, sorted
+	Errors     []ExpressionError `json:"errors,omitempty"`
+}
+
+// ExpressionError is a problem found in an expression
+type ExpressionError struct {
+	Message  string `json:"message"`
+	Position int    `json:"position"` // Byte offset in the expression, -1 if the error is not tied to a position
+}
+
+// expressionTypes maps the names accepted by the expect parameter to expression types
+var expressionTypes = map[string]expr.ExprType{
+	"int":      expr.TypeInt,
+	"float":    expr.TypeFloat,
+	"string":   expr.TypeString,
+	"bool":     expr.TypeBool,
+	"datetime": expr.TypeDatetime,
+	"duration": expr.TypeDuration,
+}
+
+// HandleExpressionRequest validates and type checks an expression (expr=...) against
+// the columns of a table (table=...) and writes an ExpressionCheck as JSON, so that
+// editors can report problems while users type. Computed columns defined with the
+// computed parameter of the table URL can be referenced. With expect=bool (or another
+// type name), an expression whose result has another type is invalid, as for filter
+// expressions and validation rules. Invalid expressions are not request errors: they
+// are answered with status 200 and their errors.
+func (s *Server) HandleExpressionRequest(w io.Writer, requestURL *url.URL, product ProductConfig, setHeader func(key, value string)) *TableHandlerResult {
+	start := time.Now()
+	params := requestURL.Query()
+	tableName := params.Get("table")
+	source := params.Get("expr")
+	if tableName == "" || source == "" {
+		return &TableHandlerResult{StatusCode: 400, Message: "Table and expr parameters are required"}
+	}
+	var expect expr.ExprType
+	if name := params.Get("expect"); name != "" {
+		var ok bool
+		if expect, ok = expressionTypes[name]; !ok {
+			return &TableHandlerResult{StatusCode: 400, Message: fmt.Sprintf("Unknown expected type '%s'", name)}
+		}
+	}
+
+	table := s.dataModel.GetTable(tableName)
+	if table == nil {
+		return errorResult(errs.New(errs.ErrUnknownTable, "Table '%s' not found", tableName))
+	}
+	computed := make(map[string]bool)
+	for _, comp := range query.NewQuery(requestURL).ComputedColumns {
+		computed[comp.Name] = true
+	}
+
+	check := ExpressionCheck{Expression: source}
+	compiled, err := expr.Compile(source)
+	if err != nil {
+		check.Errors = append(check.Errors, ExpressionError{Message: err.Error(), Position: expr.Position(err)})
+	} else {
+		check.Columns = compiled.Columns()
+		for _, ref := range compiled.ColumnRefs() {
+			if table.GetColumn(ref.Name) == nil && !computed[ref.Name] {
+				check.Errors = append(check.Errors, ExpressionError{Message: fmt.Sprintf("column '%s' not found", ref.Name), Position: ref.Pos})
+			}
+		}
+
+		// Computed columns and string columns are typed per value, so they are not checked
+		resultType, err := compiled.Check(func(name string) expr.ExprType {
+			if col := table.GetColumn(name); col != nil {
+				return expr.ColumnType(col)
+			}
+			return expr.TypeUnknown
+		})
+		switch {
+		case err != nil:
+			check.Errors = append(check.Errors, ExpressionError{Message: err.Error(), Position: expr.Position(err)})
+		case expect != expr.TypeUnknown && resultType != expr.TypeUnknown && resultType != expect &&
+			!(expect == expr.TypeFloat && resultType == expr.TypeInt):
+			check.Errors = append(check.Errors, ExpressionError{Message: fmt.Sprintf("expression returns %s, expected %s", resultType, expect), Position: -1})
+		}
+		if len(check.Errors) == 0 {
+			check.ResultType = resultType.String()
+		}
+	}
+	check.Valid = len(check.Errors) == 0
+
+	// Checking an expression reads no rows
+	setHeader("Content-Type", "application/json")
+	out := &countingWriter{w: w}
+	if err := json.NewEncoder(out).Encode(check); err != nil {
+		return errorResult(err)
+	}
+	s.recordQueryCost(product, params.Get("user"), start, 0, out.n)
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/taxinomia/core/server"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestExpressionCheck(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	check := func(params string) server.ExpressionCheck {
+		t.Helper()
+		resp := srv.Get(t, "/"+testsupport.ProductName+"/expression?table=orders&"+params)
+		resp.AssertStatus(t, http.StatusOK)
+		var got server.ExpressionCheck
+		if err := json.Unmarshal([]byte(resp.Body), &got); err != nil {
+			t.Fatalf("invalid response %q: %v", resp.Body, err)
+		}
+		return got
+	}
+
+	got := check("expr=" + url.QueryEscape("amount * 2 > 100") + "&expect=bool")
+	if !got.Valid || got.ResultType != "bool" || !slices.Equal(got.Columns, []string{"amount"}) {
+		t.Errorf("valid expression: got %+v", got)
+	}
+
+	// Syntax errors, unknown columns and type errors are reported at their position
+	tests := []struct {
+		expr    string
+		message string
+		pos     int
+	}{
+		{"amount + (status", "expected ')' after expression", 16},
+		{"amount + missing", "column 'missing' not found", 9},
+		{"amount - 'x'", "cannot subtract string from int", 7},
+	}
+	for _, tt := range tests {
+		got := check("expr=" + url.QueryEscape(tt.expr))
+		if got.Valid || len(got.Errors) != 1 || !strings.Contains(got.Errors[0].Message, tt.message) || got.Errors[0].Position != tt.pos {
+			t.Errorf("%q: got %+v, want the error %q at %d", tt.expr, got, tt.message, tt.pos)
+		}
+	}
+
+	// Filter expressions must return a boolean
+	got = check("expr=" + url.QueryEscape("amount * 2") + "&expect=bool")
+	if got.Valid || len(got.Errors) != 1 || got.Errors[0].Message != "expression returns int, expected bool" {
+		t.Errorf("non-boolean filter: got %+v", got)
+	}
+
+	// Computed columns of the view can be referenced
+	got = check("expr=" + url.QueryEscape("double + 1") + "&computed=" + url.QueryEscape("double=amount * 2"))
+	if !got.Valid {
+		t.Errorf("computed column reference: got %+v", got)
+	}
+
+	srv.Get(t, "/"+testsupport.ProductName+"/expression?table=orders").AssertStatus(t, http.StatusBadRequest)
+	srv.Get(t, "/"+testsupport.ProductName+"/expression?table=orders&expr=amount&expect=list").AssertStatus(t, http.StatusBadRequest)
+	srv.Get(t, "/"+testsupport.ProductName+"/expression?table=missing&expr=amount").AssertStatus(t, http.StatusNotFound)
+}
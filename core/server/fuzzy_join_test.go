@@ -0,0 +1,54 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/tables"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestFuzzyJoin(t *testing.T) {
+	dm := testsupport.NewDataModel()
+	cities := tables.NewDataTable()
+	cities.AddColumn(testsupport.StringColumn("city", "City", "city", "Zurich", "Geneva", "Lausanne"))
+	cities.AddColumn(testsupport.StringColumn("canton", "Canton", "", "ZH", "GE", "VD"))
+	dm.AddTable("cities", cities)
+	visits := tables.NewDataTable()
+	visits.AddColumn(testsupport.StringColumn("visit_city", "City", "city", "zurich ", "Lausane", "Paris"))
+	dm.AddTable("visits", visits)
+	dm.SetFuzzyJoin("city", 0.5)
+	srv := testsupport.NewServerWithDataModel(t, dm)
+
+	resp := srv.GetTable(t, "visits", "columns=visit_city,visit_city.cities.city.canton,visit_city.cities.city._match_confidence")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("td", "data-cell-column", "visit_city.cities.city.canton"); len(got) != 3 || got[0] != "ZH" || got[1] != "VD" || got[2] == "VD" {
+		t.Errorf("expected fuzzy matches ZH, VD and an unmatched row, got %q", got)
+	}
+	if got := resp.Elements("td", "data-cell-column", "visit_city.cities.city._match_confidence"); len(got) != 3 || got[0] != "1" || got[1] != "0.7" {
+		t.Errorf("expected match confidences 1 and 0.7, got %q", got)
+	}
+
+	// The confidence column is offered in the column picker of fuzzy joins
+	resp = srv.GetTable(t, "visits", "columns=visit_city&expanded=visit_city,visit_city/cities.city")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, "Match confidence")
+}
@@ -0,0 +1,51 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestGroupExampleRows(t *testing.T) {
+	srv := testsupport.NewServer(t)
+
+	resp := srv.GetTable(t, "orders", "columns=region,amount&grouped=region&examples=2")
+	resp.AssertStatus(t, http.StatusOK)
+	lists := resp.Elements("ul", "class", "group-examples")
+	if len(lists) != testsupport.RegionsRowCount {
+		t.Fatalf("expected example rows for %d groups, got %d: %v", testsupport.RegionsRowCount, len(lists), lists)
+	}
+	// The first two north orders have amounts 100 and 250.
+	resp.AssertContains(t, "<li>100</li>")
+	resp.AssertContains(t, "<li>250</li>")
+	resp.AssertNotContains(t, "<li>50</li>")
+
+	random := srv.GetTable(t, "orders", "columns=region,amount&grouped=region&examples=2:random")
+	random.AssertStatus(t, http.StatusOK)
+	again := srv.GetTable(t, "orders", "columns=region,amount&grouped=region&examples=2:random")
+	if got, want := again.Elements("ul", "class", "group-examples"), random.Elements("ul", "class", "group-examples"); !slices.Equal(got, want) {
+		t.Errorf("random examples are not deterministic: %v vs %v", got, want)
+	}
+
+	srv.GetTable(t, "orders", "columns=region,amount&grouped=region&examples=11").AssertStatus(t, http.StatusBadRequest)
+}
@@ -0,0 +1,51 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestGroupLabelExpression(t *testing.T) {
+	srv := testsupport.NewServer(t)
+
+	// Labels can use the columns grouped above, and filter on the grouped values
+	resp := srv.GetTable(t, "orders", "columns=region,status,amount&grouped=region,status&grouplabel:region="+
+		url.QueryEscape(`region.upper()`)+"&grouplabel:status="+url.QueryEscape(`region + "/" + status`))
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, "NORTH [2/3]")
+	resp.AssertContains(t, "north/pending [2]")
+	resp.AssertContains(t, `data-value="pending"`)
+	resp.AssertContains(t, `value="region.upper()"`)
+
+	// Invalid expressions are reported and their groups show the grouped values
+	for expression, message := range map[string]string{
+		`region.upper(`: "syntax error",
+		`missing + "!"`: "column &#39;missing&#39; not found",
+	} {
+		resp = srv.GetTable(t, "orders", "columns=region,amount&grouped=region&grouplabel:region="+url.QueryEscape(expression))
+		resp.AssertStatus(t, http.StatusOK)
+		resp.AssertContains(t, message)
+		resp.AssertContains(t, "north [3]")
+	}
+}
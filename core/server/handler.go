@@ -0,0 +1,122 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ProductLookup returns the product named by the first segment of a URL path, or nil
+// if there is no such product.
+type ProductLookup func(name string) ProductConfig
+
+// Handler returns an http.Handler routing the URLs of all products to the request
+// handlers of the server. The root path redirects to defaultProduct.
+// URL format: /{product}/, /{product}/table, /{product}/overview, /{product}/export, /{product}/session,
+// /{product}/detail, /{product}/expression or /{product}/sql
+func (s *Server) Handler(defaultProduct string, lookup ProductLookup) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Tables are not reloaded while a request is served
+		s.reloadMu.RLock()
+		defer s.reloadMu.RUnlock()
+
+		// Parse product name and action from path
+		productName, action := parseProductPath(r.URL.Path)
+
+		// Redirect root to default product
+		if productName == "" {
+			http.Redirect(w, r, "/"+defaultProduct+"/", http.StatusFound)
+			return
+		}
+
+		product := lookup(productName)
+		if product == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch action {
+		case "table":
+			result := s.HandleTableRequest(w, r.URL, product, w.Header().Set)
+			if result != nil {
+				http.Error(w, result.Message, result.StatusCode)
+			}
+		case "overview":
+			s.HandleOverviewRequest(w, r.URL, product, w.Header().Set)
+		case "export":
+			result := s.HandleExportRequest(w, r.URL, product, w.Header().Set)
+			if result != nil {
+				http.Error(w, result.Message, result.StatusCode)
+			}
+		case "session":
+			result := s.HandleSessionRequest(w, r.URL, product, w.Header().Set)
+			if result != nil {
+				http.Error(w, result.Message, result.StatusCode)
+			}
+		case "detail":
+			result := s.HandleDetailRequest(w, r.URL, product, w.Header().Set)
+			if result != nil {
+				http.Error(w, result.Message, result.StatusCode)
+			}
+		case "expression":
+			result := s.HandleExpressionRequest(w, r.URL, product, w.Header().Set)
+			if result != nil {
+				http.Error(w, result.Message, result.StatusCode)
+			}
+		case "sql":
+			result := s.HandleSQLRequest(w, r.URL, product, w.Header().Set)
+			if result != nil {
+				http.Error(w, result.Message, result.StatusCode)
+			}
+		default:
+			s.HandleLandingRequest(w, r.URL, product, w.Header().Set)
+		}
+	})
+}
+
+// parseProductPath extracts the product name and action from a URL path.
+// Returns (productName, action) where action is "landing", "table", "overview", "export", "session",
+// "detail", "expression" or "sql".
+// Returns empty productName if path is "/" to trigger redirect.
+func parseProductPath(path string) (string, string) {
+	// Remove leading slash and split
+	path = strings.TrimPrefix(path, "/")
+	parts := strings.SplitN(path, "/", 2)
+
+	// Root path - return empty to trigger redirect
+	if len(parts) == 0 || parts[0] == "" {
+		return "", "landing"
+	}
+
+	// First part is always the product name
+	productName := parts[0]
+	action := "landing"
+
+	if len(parts) > 1 && parts[1] != "" {
+		// Remove trailing slash and check for action
+		secondPart := strings.TrimSuffix(parts[1], "/")
+		if secondPart == "table" || secondPart == "overview" || secondPart == "export" || secondPart == "session" ||
+			secondPart == "detail" || secondPart == "expression" || secondPart == "sql" {
+			action = secondPart
+		}
+	}
+
+	return productName, action
+}
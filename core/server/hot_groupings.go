@@ -0,0 +1,86 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/taxinomia/core/tables"
+)
+
+// HotGrouping is a grouping of a table that is precomputed when the table loads or is
+// reloaded, so that the views grouped by it skip grouping the rows on their first request.
+// Precomputed groupings are reused by unfiltered views only.
+type HotGrouping struct {
+	Table    string
+	Columns  []string // Grouped columns of the table itself, outermost first (sorted ascending)
+	Priority int      // Groupings with a higher priority are precomputed first
+}
+
+// SetHotGroupings sets the groupings to precompute and precomputes them right away, in
+// priority order. They are precomputed again whenever their table is reloaded.
+func (s *Server) SetHotGroupings(groupings []HotGrouping) {
+	s.hotGroupings = slices.Clone(groupings)
+	slices.SortStableFunc(s.hotGroupings, func(a, b HotGrouping) int {
+		return b.Priority - a.Priority
+	})
+	s.precomputedGroupings = make(map[string][]*tables.PrecomputedGrouping)
+	s.precomputeGroupings("")
+}
+
+// precomputeGroupings precomputes the hot groupings of a table over its current data,
+// or of all tables if tableName is empty. Groupings that cannot be built are logged and
+// skipped.
+func (s *Server) precomputeGroupings(tableName string) {
+	if tableName != "" {
+		delete(s.precomputedGroupings, tableName)
+	}
+	for _, hot := range s.hotGroupings {
+		if tableName != "" && hot.Table != tableName {
+			continue
+		}
+		table := s.dataModel.GetTable(hot.Table)
+		if table == nil {
+			log.Printf("Skipping hot grouping of %s by %v: table not found", hot.Table, hot.Columns)
+			continue
+		}
+		start := time.Now()
+		p, err := tables.PrecomputeGrouping(table, hot.Columns, nil)
+		if err != nil {
+			log.Printf("Skipping hot grouping of %s by %v: %v", hot.Table, hot.Columns, err)
+			continue
+		}
+		s.precomputedGroupings[hot.Table] = append(s.precomputedGroupings[hot.Table], p)
+		log.Printf("Precomputed %d groups of %s by %v in %v", p.GroupCount(), hot.Table, hot.Columns, time.Since(start))
+	}
+}
+
+// PrecomputedGroupings returns the groupings precomputed for a table, in priority order.
+// A table replaced by identical data notifies no change; its groupings are precomputed
+// again over the new table here.
+func (s *Server) PrecomputedGroupings(tableName string) []*tables.PrecomputedGrouping {
+	groupings := s.precomputedGroupings[tableName]
+	if len(groupings) > 0 && groupings[0].Table() != s.dataModel.GetTable(tableName) {
+		s.precomputeGroupings(tableName)
+		groupings = s.precomputedGroupings[tableName]
+	}
+	return groupings
+}
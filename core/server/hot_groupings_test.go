@@ -0,0 +1,69 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/server"
+	"github.com/google/taxinomia/core/tables"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestHotGroupings(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.SetHotGroupings([]server.HotGrouping{
+		{Table: "orders", Columns: []string{"status"}, Priority: 1},
+		{Table: "orders", Columns: []string{"region"}, Priority: 2},
+		{Table: "orders", Columns: []string{"missing"}, Priority: 3},
+	})
+
+	// Invalid groupings are skipped; the others are kept in priority order
+	precomputed := srv.PrecomputedGroupings("orders")
+	if len(precomputed) != 2 || precomputed[0].Columns()[0] != "region" || precomputed[1].Columns()[0] != "status" {
+		t.Fatalf("expected the region and status groupings, got %d groupings", len(precomputed))
+	}
+
+	resp := srv.GetTable(t, "orders", "columns=region,amount&grouped=region")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("td", "data-column", "region"); len(got) != testsupport.RegionsRowCount {
+		t.Errorf("expected %d region groups, got %v", testsupport.RegionsRowCount, got)
+	}
+
+	// Reloading the table precomputes its groupings over the new data
+	reloaded := tables.NewDataTable()
+	reloaded.AddColumn(testsupport.StringColumn("order_id", "Order ID", "order", "o1", "o2"))
+	reloaded.AddColumn(testsupport.StringColumn("region", "Region", "region", "north", "north"))
+	reloaded.AddColumn(testsupport.StringColumn("status", "Status", "", "shipped", "pending"))
+	reloaded.AddColumn(testsupport.Uint32Column("amount", "Amount", 100, 250))
+	srv.DataModel.AddTable("orders", reloaded)
+	precomputed = srv.PrecomputedGroupings("orders")
+	if len(precomputed) != 2 || precomputed[0].Table() != reloaded {
+		t.Fatal("expected the groupings to be precomputed over the reloaded table")
+	}
+	if got := precomputed[0].GroupCount(); got != 1 {
+		t.Errorf("expected 1 region group after reload, got %d", got)
+	}
+	resp = srv.GetTable(t, "orders", "columns=region,amount&grouped=region")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("td", "data-column", "region"); len(got) != 1 {
+		t.Errorf("expected 1 region group after reload, got %v", got)
+	}
+}
@@ -0,0 +1,48 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestIdentifierAggregates(t *testing.T) {
+	dm := testsupport.NewDataModel()
+	orders := dm.GetTable("orders")
+	orders.AddColumn(testsupport.Uint32Column("customer_id", "Customer", 7, 7, 8, 9, 8, 9))
+	srv := testsupport.NewServerWithDataModel(t, dm)
+
+	resp := srv.GetTable(t, "orders", "columns=region,customer_id,amount&grouped=region")
+	resp.AssertStatus(t, http.StatusOK)
+
+	// Customer IDs are counted, not summed; amounts keep their defaults
+	resp.AssertContains(t, `class="agg-toggle-btn enabled" title="Unique Values">◇</a>`)
+	resp.AssertContains(t, `class="agg-toggle-btn demoted" title="Sum (not meaningful for identifiers)">Σ</a>`)
+	resp.AssertContains(t, `class="agg-toggle-btn" title="Sum">Σ</a>`)
+	resp.AssertContains(t, `#3 ◇2 `) // Three orders of two customers in the north region
+
+	// An explicit selection wins over the defaults
+	resp = srv.GetTable(t, "orders", "columns=region,customer_id,amount&grouped=region&agg:customer_id=sum")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, `class="agg-toggle-btn enabled demoted" title="Sum (not meaningful for identifiers)">Σ</a>`)
+	resp.AssertNotContains(t, `◇2 `)
+}
@@ -0,0 +1,101 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/taxinomia/core/tables"
+)
+
+// DefaultCacheIdleWindow is how long a table view can go without requests before its
+// filter mask and groupings are released.
+const DefaultCacheIdleWindow = 30 * time.Minute
+
+type viewAccess struct {
+	view       *tables.TableView
+	lastAccess time.Time
+	released   bool // Caches released since the last access
+}
+
+// CacheJanitor records when cached table views were last accessed and releases the
+// caches of the views left idle, so that rarely viewed tables do not hold their filter
+// masks and groupings forever. Views are released at most once per idle period.
+// It is safe for concurrent use.
+type CacheJanitor struct {
+	mu    sync.Mutex
+	views map[string]*viewAccess // cache key -> last accessed view
+}
+
+// NewCacheJanitor creates a janitor tracking no views.
+func NewCacheJanitor() *CacheJanitor {
+	return &CacheJanitor{views: make(map[string]*viewAccess)}
+}
+
+// Touch records an access of the view cached under cacheKey. It must be called before
+// the view is used, so that the view is not released while a request uses it.
+func (j *CacheJanitor) Touch(cacheKey string, view *tables.TableView, at time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.views[cacheKey] = &viewAccess{view: view, lastAccess: at}
+}
+
+// ReleaseIdle releases the caches of the views not accessed since idleSince and
+// returns how many views were released and the estimated bytes freed.
+func (j *CacheJanitor) ReleaseIdle(idleSince time.Time) (int, uint64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	released := 0
+	var bytes uint64
+	for _, access := range j.views {
+		if access.released || !access.lastAccess.Before(idleSince) {
+			continue
+		}
+		bytes += access.view.ReleaseCaches()
+		access.released = true
+		released++
+	}
+	return released, bytes
+}
+
+// StartCacheJanitor starts releasing the caches of table views not accessed within the
+// idle window, checking every quarter of the window. It returns a function stopping it.
+func (s *Server) StartCacheJanitor(idle time.Duration) (stop func()) {
+	ticker := time.NewTicker(max(idle/4, time.Second))
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				if views, bytes := s.janitor.ReleaseIdle(now.Add(-idle)); views > 0 {
+					log.Printf("Released the caches of %d table views idle for %v (%d bytes)", views, idle, bytes)
+				}
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/tables"
+)
+
+func newStatusView(t *testing.T) *tables.TableView {
+	t.Helper()
+	status := columns.NewStringColumn(columns.NewColumnDef("status", "Status", ""))
+	for _, v := range []string{"shipped", "pending", "shipped"} {
+		status.Append(v)
+	}
+	status.FinalizeColumn()
+	table := tables.NewDataTable()
+	table.AddColumn(status)
+	view := tables.NewTableView(table, "orders")
+	view.ApplyFilters(map[string]string{"status": "shipped"})
+	view.GroupTable([]string{"status"}, nil, nil, nil)
+	return view
+}
+
+func TestCacheJanitorReleasesIdleViews(t *testing.T) {
+	j := NewCacheJanitor()
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	idle, busy := newStatusView(t), newStatusView(t)
+	j.Touch("orders", idle, start)
+	j.Touch("alice:orders", busy, start.Add(time.Hour))
+
+	views, bytes := j.ReleaseIdle(start.Add(30 * time.Minute))
+	if views != 1 || bytes == 0 {
+		t.Fatalf("ReleaseIdle released %d views and %d bytes, want 1 view", views, bytes)
+	}
+	if f := idle.CacheFootprint(); f.Bytes() != 0 || idle.AppliedFilters() != nil {
+		t.Errorf("idle view still holds caches: %+v", f)
+	}
+	if busy.CacheFootprint().Bytes() == 0 {
+		t.Error("recently accessed view lost its caches")
+	}
+
+	// Released views are not released again until they are accessed
+	if views, _ := j.ReleaseIdle(start.Add(30 * time.Minute)); views != 0 {
+		t.Errorf("ReleaseIdle released %d views twice", views)
+	}
+	idle.ApplyFilters(map[string]string{"status": "pending"})
+	j.Touch("orders", idle, start.Add(2*time.Hour))
+	if views, _ := j.ReleaseIdle(start.Add(3 * time.Hour)); views != 2 {
+		t.Errorf("ReleaseIdle released %d views, want both", views)
+	}
+	if idle.GetFilteredRowCount() != 3 {
+		t.Errorf("released view shows %d rows, want all 3", idle.GetFilteredRowCount())
+	}
+}
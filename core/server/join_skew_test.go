@@ -0,0 +1,60 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/tables"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestJoinSkewWarning(t *testing.T) {
+	dm := testsupport.NewDataModel()
+	hosts, events := make([]string, 20), make([]string, 40)
+	for i := range hosts {
+		hosts[i] = fmt.Sprintf("h%d", i)
+	}
+	for i := range events {
+		events[i] = hosts[i%20]
+		if i < 30 {
+			events[i] = "unknown"
+		}
+	}
+	hosts[0] = "unknown"
+	hostsTable := tables.NewDataTable()
+	hostsTable.AddColumn(testsupport.StringColumn("host", "Host", "host", hosts...))
+	dm.AddTable("hosts", hostsTable)
+	eventsTable := tables.NewDataTable()
+	eventsTable.AddColumn(testsupport.StringColumn("host", "Host", "host", events...))
+	dm.AddTable("events", eventsTable)
+	srv := testsupport.NewServerWithDataModel(t, dm)
+
+	resp := srv.GetTable(t, "events", "columns=host,host.hosts.host.host")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, `class="th-join-warning"`)
+	resp.AssertContains(t, "75% of matched rows (30 of 40) join to the same hosts row")
+
+	// Lookups into a small table are not flagged
+	resp = srv.GetTable(t, "orders", "columns=order_id,region.regions.region.name")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertNotContains(t, `class="th-join-warning"`)
+}
@@ -0,0 +1,62 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestMemoryTable(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.GetTable(t, "orders", "columns=order_id,region,amount&grouped=region&filter:status=shipped").AssertStatus(t, http.StatusOK)
+
+	resp := srv.GetTable(t, "_memory", "columns=table_name,kind,column_name,cardinality,bytes")
+	resp.AssertStatus(t, http.StatusOK)
+
+	tableNames := resp.Elements("td", "data-cell-column", "table_name")
+	kinds := resp.Elements("td", "data-cell-column", "kind")
+	columnNames := resp.Elements("td", "data-cell-column", "column_name")
+	cardinalities := resp.Elements("td", "data-cell-column", "cardinality")
+	got := make(map[string]string)
+	for i := range tableNames {
+		got[tableNames[i]+"|"+kinds[i]+"|"+columnNames[i]] = cardinalities[i]
+	}
+	want := map[string]string{
+		"orders|column|region":   "3",
+		"orders|column|status":   "3",
+		"orders|column|order_id": "6",
+		"orders|filter_mask|":    "1",
+		"orders|grouping|region": "2",
+		"regions|column|region":  "3",
+	}
+	for key, cardinality := range want {
+		if got[key] != cardinality {
+			t.Errorf("cardinality of %s = %q, want %q (rows %v)", key, got[key], cardinality, got)
+		}
+	}
+	for key := range got {
+		if strings.HasPrefix(key, "_") {
+			t.Errorf("system table %s should not be reported", key)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/taxinomia/core/models"
+	"github.com/google/taxinomia/core/views"
+)
+
+// overviewListSize is the number of entries shown in the overview page lists
+const overviewListSize = 10
+
+// HandleOverviewRequest processes the deployment overview page request
+func (s *Server) HandleOverviewRequest(w io.Writer, requestURL *url.URL, product ProductConfig, setHeader func(key, value string)) error {
+	setHeader("Content-Type", "text/html; charset=utf-8")
+
+	now := time.Now()
+	vm := views.OverviewViewModel{
+		PageLocale: s.pageLocale(requestURL.Query().Get("user")),
+		Title:      product.GetTitle(),
+		Subtitle:   product.GetSubtitle(),
+	}
+
+	// Totals and freshness over all user tables
+	for name, table := range s.dataModel.GetAllTables() {
+		if models.IsSystemTable(name) {
+			continue
+		}
+		vm.TableCount++
+		vm.TotalRows += table.Length()
+		vm.TotalColumns += len(table.GetColumnNames())
+
+		freshness := views.TableFreshness{Name: name, URL: tableURL(name), Rows: table.Length()}
+		if s.freshnessResolver != nil {
+			if loadedAt := s.freshnessResolver(name); !loadedAt.IsZero() {
+				freshness.LoadedAt = loadedAt.Format(time.DateTime)
+				freshness.Age = views.FormatAge(now.Sub(loadedAt))
+			}
+		}
+		vm.Freshness = append(vm.Freshness, freshness)
+	}
+	sort.Slice(vm.Freshness, func(i, j int) bool {
+		a, b := vm.Freshness[i], vm.Freshness[j]
+		if (a.LoadedAt == "") != (b.LoadedAt == "") {
+			return a.LoadedAt != ""
+		}
+		if a.LoadedAt != b.LoadedAt {
+			return a.LoadedAt < b.LoadedAt
+		}
+		return a.Name < b.Name
+	})
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	vm.HeapInUse = formatBytes(mem.HeapInuse)
+
+	// View counts and slow queries come from the statistics behind the _usage table, which
+	// identify views by their columns and grouping and never by their filter values
+	records := s.usage.Records()
+	for _, r := range mostViewed(records, overviewListSize) {
+		vm.MostViewed = append(vm.MostViewed, views.TableViewsInfo{Name: r.Table, URL: tableURL(r.Table), Views: int(r.Accesses)})
+	}
+	for _, r := range slowestViews(records, now, overviewListSize) {
+		vm.SlowestQueries = append(vm.SlowestQueries, views.SlowQueryInfo{
+			Table:      r.Table,
+			View:       r.View,
+			URL:        viewURL(r.Table, r.View),
+			DurationMs: fmt.Sprintf("%.2f", float64(r.Slowest.Microseconds())/1000.0),
+			At:         r.SlowestAt.Format(time.TimeOnly),
+		})
+	}
+
+	if err := s.renderer.RenderOverview(w, vm); err != nil {
+		log.Printf("Overview page rendering error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// tableURL returns the table view URL relative to the product pages
+func tableURL(tableName string) string {
+	return "table?table=" + url.QueryEscape(tableName)
+}
+
+// mostViewed returns up to limit table usage records ordered by descending access count
+func mostViewed(records []models.UsageRecord, limit int) []models.UsageRecord {
+	var result []models.UsageRecord
+	for _, r := range records {
+		if r.Scope == models.UsageScopeTable {
+			result = append(result, r)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Accesses != result[j].Accesses {
+			return result[i].Accesses > result[j].Accesses
+		}
+		return result[i].Table < result[j].Table
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// slowestViews returns up to limit view usage records accessed on the day of now,
+// ordered by the descending duration of their slowest access of the day
+func slowestViews(records []models.UsageRecord, now time.Time, limit int) []models.UsageRecord {
+	today := now.Format(time.DateOnly)
+	var result []models.UsageRecord
+	for _, r := range records {
+		if r.Scope == models.UsageScopeView && r.SlowestAt.Format(time.DateOnly) == today {
+			result = append(result, r)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Slowest != result[j].Slowest {
+			return result[i].Slowest > result[j].Slowest
+		}
+		return result[i].SlowestAt.Before(result[j].SlowestAt)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// viewURL returns the URL of a view of a table, given by its ViewSignature, relative to
+// the product pages
+func viewURL(tableName, view string) string {
+	params := url.Values{"table": {tableName}}
+	for _, param := range strings.Split(view, "&") {
+		if name, value, ok := strings.Cut(param, "="); ok && value != "" {
+			params.Set(name, value)
+		}
+	}
+	return "table?" + params.Encode()
+}
+
+// formatBytes formats a byte count with a binary unit (e.g., "12.3 MiB")
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,60 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestOverviewPage(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	loadedAt := time.Now().Add(-3 * time.Hour)
+	srv.SetTableFreshnessResolver(func(tableName string) time.Time {
+		if tableName == "orders" {
+			return loadedAt
+		}
+		return time.Time{}
+	})
+	srv.GetTable(t, "orders", "").AssertStatus(t, http.StatusOK)
+	srv.GetTable(t, "orders", "columns=order_id,status&filter:status=shipped&user=alice").AssertStatus(t, http.StatusOK)
+	srv.GetTable(t, "regions", "").AssertStatus(t, http.StatusOK)
+
+	resp := srv.Get(t, "/"+testsupport.ProductName+"/overview")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("div", "id", "stat-tables"); len(got) != 1 || got[0] != "2" {
+		t.Errorf("expected 2 tables, got %q", got)
+	}
+	if got := resp.Elements("div", "id", "stat-rows"); len(got) != 1 || got[0] != strconv.Itoa(testsupport.RegionsRowCount+testsupport.OrdersRowCount) {
+		t.Errorf("expected %d rows, got %q", testsupport.RegionsRowCount+testsupport.OrdersRowCount, got)
+	}
+	resp.AssertContains(t, `<a href="table?table=orders">orders</a></td><td class="number">2</td>`)
+	resp.AssertContains(t, "3h ago")
+	// Slow queries link to their view, without the filters and user of the request
+	resp.AssertContains(t, `href="table?columns=status%2Corder_id&amp;table=orders"`)
+	resp.AssertNotContains(t, "shipped")
+	resp.AssertNotContains(t, "alice")
+
+	// The landing page links to the overview
+	srv.Get(t, "/"+testsupport.ProductName+"/").AssertContains(t, `href="overview"`)
+}
@@ -0,0 +1,87 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/taxinomia/core/models"
+)
+
+func TestSlowestViewsOfTheDay(t *testing.T) {
+	day := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	var records []models.UsageRecord
+	for i := 1; i <= overviewListSize+5; i++ {
+		records = append(records, models.UsageRecord{Table: "orders", Scope: models.UsageScopeView, View: "columns=a",
+			Slowest: time.Duration(i) * time.Millisecond, SlowestAt: day})
+	}
+	records = append(records,
+		models.UsageRecord{Table: "orders", Scope: models.UsageScopeTable, Slowest: time.Hour, SlowestAt: day},
+		models.UsageRecord{Table: "orders", Scope: models.UsageScopeView, View: "columns=b", Slowest: time.Hour, SlowestAt: day.Add(-24 * time.Hour)})
+
+	slowest := slowestViews(records, day, overviewListSize)
+	if len(slowest) != overviewListSize {
+		t.Fatalf("expected %d slow views, got %d", overviewListSize, len(slowest))
+	}
+	if slowest[0].Slowest != time.Duration(overviewListSize+5)*time.Millisecond {
+		t.Errorf("expected the slowest view first, got %+v", slowest[0])
+	}
+	for i := 1; i < len(slowest); i++ {
+		if slowest[i].Slowest > slowest[i-1].Slowest {
+			t.Fatalf("slow views not sorted by descending duration: %v", slowest)
+		}
+	}
+	if got := slowestViews(records, day.Add(24*time.Hour), overviewListSize); len(got) != 0 {
+		t.Errorf("expected no slow views on a new day, got %d", len(got))
+	}
+}
+
+func TestMostViewed(t *testing.T) {
+	records := []models.UsageRecord{
+		{Table: "items", Scope: models.UsageScopeTable, Accesses: 1},
+		{Table: "orders", Scope: models.UsageScopeTable, Accesses: 3},
+		{Table: "orders", Scope: models.UsageScopeView, View: "columns=a", Accesses: 3},
+		{Table: "regions", Scope: models.UsageScopeTable, Accesses: 2},
+	}
+	got := mostViewed(records, 2)
+	if len(got) != 2 || got[0].Table != "orders" || got[1].Table != "regions" {
+		t.Errorf("mostViewed(2) = %v, want orders then regions", got)
+	}
+}
+
+func TestViewURL(t *testing.T) {
+	if got, want := viewURL("orders", "columns=region,amount&grouped=region"), "table?columns=region%2Camount&grouped=region&table=orders"; got != want {
+		t.Errorf("viewURL = %q, want %q", got, want)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[uint64]string{
+		512:                    "512 B",
+		2048:                   "2.0 KiB",
+		5 * 1024 * 1024:        "5.0 MiB",
+		3 * 1024 * 1024 * 1024: "3.0 GiB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+	"github.com/google/taxinomia/core/users"
+)
+
+// userStore is a users.UserStore of fixed profiles.
+type userStore map[string]*users.UserProfile
+
+func (s userStore) GetUser(name string) *users.UserProfile {
+	return s[name]
+}
+
+func TestPageDirection(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.GetTable(t, "orders", "columns=order_id").AssertContains(t, `<html lang="en" dir="ltr">`)
+
+	// The preferred locale of a user overrides the default locale of the server
+	srv.SetDefaultLocale("ar-EG")
+	srv.SetUserStore(userStore{"noa": {Domains: []string{"demo"}, Locale: "he-IL"}, "sam": {Domains: []string{"demo"}}})
+	for user, want := range map[string]string{
+		"noa": `<html lang="he-IL" dir="rtl">`,
+		"sam": `<html lang="ar-EG" dir="rtl">`,
+		"":    `<html lang="ar-EG" dir="rtl">`,
+	} {
+		srv.GetTable(t, "orders", "columns=order_id&user="+user).AssertContains(t, want)
+		srv.Get(t, "/"+testsupport.ProductName+"/?user="+user).AssertContains(t, want)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+var aggregateFillPattern = regexp.MustCompile(`(?s)<template class="aggregate-fill" data-target="([^"]*)">(.*?)</template>`)
+
+func TestProgressiveAggregates(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.SetProgressiveAggregateMinRows(1)
+
+	resp := srv.GetTable(t, "orders", "columns=region,status,amount&grouped=region,status&agg:amount=sum")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, `leaf-agg-text agg-pending`)
+
+	// The grouped cells stay placeholders, the aggregates follow the page
+	page, streamed, ok := strings.Cut(resp.Body, `<template class="aggregate-fill"`)
+	if !ok {
+		t.Fatal("expected aggregates to be streamed after the page")
+	}
+	if strings.Contains(page, `<div class="leaf-agg-text">`) {
+		t.Error("expected no aggregates in the page itself")
+	}
+	if !strings.HasSuffix(strings.TrimSpace(resp.Body), "</html>") {
+		t.Error("expected the page to be closed after the streamed aggregates")
+	}
+	if !strings.Contains(streamed, "fillAggregates()") {
+		t.Error("expected the streamed aggregates to be applied to the page")
+	}
+
+	fills := make(map[string]string)
+	for _, m := range aggregateFillPattern.FindAllStringSubmatch(resp.Body, -1) {
+		fills[m[1]] = m[2]
+		if !strings.Contains(page, `data-aggregates-id="`+m[1]+`"`) {
+			t.Errorf("fill %s has no placeholder", m[1])
+		}
+	}
+	// 3 region cells plus the amount cells of 4 region/status groups
+	if len(fills) != 7 {
+		t.Fatalf("expected 7 fills, got %d", len(fills))
+	}
+	var regionSums []string
+	for id, content := range fills {
+		if !strings.HasSuffix(id, "-amount") {
+			regionSums = append(regionSums, regexp.MustCompile(`Σ\S+`).FindString(content))
+		}
+	}
+	slices.Sort(regionSums)
+	if !slices.Equal(regionSums, []string{"Σ200", "Σ300", "Σ400"}) {
+		t.Errorf("expected region sums 200, 300 and 400, got %v", regionSums)
+	}
+
+	// Sorting groups by an aggregate needs the aggregates before rendering
+	sorted := srv.GetTable(t, "orders", "columns=region,amount&grouped=region&agg:amount=sum&groupsort:region=-amount:sum")
+	sorted.AssertStatus(t, http.StatusOK)
+	sorted.AssertNotContains(t, `class="aggregate-fill"`)
+}
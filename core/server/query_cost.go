@@ -0,0 +1,131 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/taxinomia/core/models"
+)
+
+// QueryCost is the cost of answering one query.
+type QueryCost struct {
+	Wall          time.Duration // Wall-clock time spent answering the query; Go has no per-request CPU time
+	RowsScanned   uint64        // Rows the query read to filter, group and render its result
+	BytesRendered uint64        // Bytes of the response
+}
+
+type queryCostKey struct {
+	product string
+	user    string
+}
+
+// QueryCostStats sums the cost of the queries of each user of each product, so that
+// shared deployments can attribute load and set quotas.
+// It is safe for concurrent use.
+type QueryCostStats struct {
+	mu      sync.Mutex
+	entries map[queryCostKey]*models.QueryCostRecord
+}
+
+// NewQueryCostStats creates an empty query cost recorder.
+func NewQueryCostStats() *QueryCostStats {
+	return &QueryCostStats{entries: make(map[queryCostKey]*models.QueryCostRecord)}
+}
+
+// Record adds the cost of a query of a user of a product served at the given time.
+// An empty user is accounted as its own user.
+func (qc *QueryCostStats) Record(product, user string, cost QueryCost, at time.Time) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	key := queryCostKey{product: product, user: user}
+	entry := qc.entries[key]
+	if entry == nil {
+		entry = &models.QueryCostRecord{Product: product, User: user}
+		qc.entries[key] = entry
+	}
+	entry.Queries++
+	entry.Wall += cost.Wall
+	entry.RowsScanned += cost.RowsScanned
+	entry.BytesRendered += cost.BytesRendered
+	if at.After(entry.LastQuery) {
+		entry.LastQuery = at
+	}
+}
+
+// Records returns the summed cost of the queries of every user of every product.
+func (qc *QueryCostStats) Records() []models.QueryCostRecord {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	records := make([]models.QueryCostRecord, 0, len(qc.entries))
+	for _, entry := range qc.entries {
+		records = append(records, *entry)
+	}
+	return records
+}
+
+// Cost returns the summed cost of the queries of a user of a product, and whether
+// the user has queried the product.
+func (qc *QueryCostStats) Cost(product, user string) (models.QueryCostRecord, bool) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	entry := qc.entries[queryCostKey{product: product, user: user}]
+	if entry == nil {
+		return models.QueryCostRecord{}, false
+	}
+	return *entry, true
+}
+
+// recordQueryCost records the cost of a query of a user of a product that started at
+// start. Queries served without a product are accounted under an empty product.
+func (s *Server) recordQueryCost(product ProductConfig, user string, start time.Time, rowsScanned int, bytesRendered uint64) {
+	productName := ""
+	if product != nil {
+		productName = product.GetName()
+	}
+	s.queryCosts.Record(productName, user, QueryCost{
+		Wall:          time.Since(start),
+		RowsScanned:   uint64(rowsScanned),
+		BytesRendered: bytesRendered,
+	}, time.Now())
+}
+
+// countingWriter counts the bytes written through it. It can be flushed whenever
+// the underlying writer can.
+type countingWriter struct {
+	w io.Writer
+	n uint64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += uint64(n)
+	return n, err
+}
+
+func (cw *countingWriter) Flush() {
+	if f, ok := cw.w.(flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,61 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestQueryCostTable(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.GetTable(t, "orders", "columns=order_id,status&filter:status=shipped&user=alice").AssertStatus(t, http.StatusOK)
+	srv.GetTable(t, "orders", "columns=order_id,amount&user=alice").AssertStatus(t, http.StatusOK)
+	srv.GetTable(t, "regions", "columns=region&user=bob").AssertStatus(t, http.StatusOK)
+
+	// The filter reads every order and the 3 shipped orders are rendered, then the
+	// unfiltered view renders every order
+	cost, ok := srv.QueryCosts().Cost(testsupport.ProductName, "alice")
+	if want := uint64(testsupport.OrdersRowCount + 3 + testsupport.OrdersRowCount); !ok || cost.Queries != 2 || cost.RowsScanned != want || cost.BytesRendered == 0 {
+		t.Fatalf("alice's cost = %+v, want 2 queries reading %d rows with a rendered size", cost, want)
+	}
+
+	resp := srv.GetTable(t, "_query_cost", "columns=product,user,queries,rows_scanned&sort=user")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("td", "data-cell-column", "user"); !slices.Equal(got, []string{"alice", "bob"}) {
+		t.Fatalf("users = %v, want [alice bob]", got)
+	}
+	if got := resp.Elements("td", "data-cell-column", "queries"); !slices.Equal(got, []string{"2", "1"}) {
+		t.Errorf("queries = %v, want [2 1]", got)
+	}
+	if got := resp.Elements("td", "data-cell-column", "rows_scanned"); !slices.Equal(got, []string{"15", "3"}) {
+		t.Errorf("rows scanned = %v, want [15 3]", got)
+	}
+
+	// Exports and expression checks are accounted too
+	srv.Get(t, "/"+testsupport.ProductName+"/export?tables=orders&filter:orders.status=shipped&user=carol").AssertStatus(t, http.StatusOK)
+	srv.Get(t, "/"+testsupport.ProductName+"/expression?table=orders&expr=amount&user=carol").AssertStatus(t, http.StatusOK)
+	cost, ok = srv.QueryCosts().Cost(testsupport.ProductName, "carol")
+	if want := uint64(testsupport.OrdersRowCount + 3); !ok || cost.Queries != 2 || cost.RowsScanned != want || cost.BytesRendered == 0 {
+		t.Errorf("carol's cost = %+v, want 2 queries reading %d rows with a rendered size", cost, want)
+	}
+}
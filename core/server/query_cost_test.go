@@ -0,0 +1,57 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestQueryCostStatsSumsPerProductAndUser(t *testing.T) {
+	qc := NewQueryCostStats()
+	day := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	qc.Record("infra", "alice", QueryCost{Wall: 10 * time.Millisecond, RowsScanned: 100, BytesRendered: 1000}, day)
+	qc.Record("infra", "alice", QueryCost{Wall: 5 * time.Millisecond, RowsScanned: 50, BytesRendered: 400}, day.Add(time.Hour))
+	qc.Record("sales", "alice", QueryCost{Wall: time.Millisecond, RowsScanned: 10, BytesRendered: 20}, day)
+	qc.Record("infra", "", QueryCost{Wall: time.Millisecond}, day)
+
+	if got := len(qc.Records()); got != 3 {
+		t.Fatalf("expected 3 records, got %d", got)
+	}
+	cost, ok := qc.Cost("infra", "alice")
+	if !ok || cost.Queries != 2 || cost.Wall != 15*time.Millisecond || cost.RowsScanned != 150 ||
+		cost.BytesRendered != 1400 || !cost.LastQuery.Equal(day.Add(time.Hour)) {
+		t.Errorf("unexpected cost %+v", cost)
+	}
+	if _, ok := qc.Cost("sales", "bob"); ok {
+		t.Error("expected no cost for a user without queries")
+	}
+}
+
+func TestCountingWriter(t *testing.T) {
+	var buf bytes.Buffer
+	cw := &countingWriter{w: &buf}
+	cw.Write([]byte("hello "))
+	cw.Write([]byte("world"))
+	cw.Flush()
+	if cw.n != 11 || buf.String() != "hello world" {
+		t.Errorf("counted %d bytes of %q, want 11", cw.n, buf.String())
+	}
+}
@@ -0,0 +1,78 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/taxinomia/core/models"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// MaxQueryPerfRecords is the number of filter evaluations kept for the _query_perf
+// table. Older ones are dropped first.
+const MaxQueryPerfRecords = 1000
+
+// QueryPerfLog records how the filters of recent queries were evaluated.
+// It is safe for concurrent use.
+type QueryPerfLog struct {
+	mu      sync.Mutex
+	records []models.QueryPerfRecord // Ring buffer of at most MaxQueryPerfRecords records
+	next    int                      // Index of the oldest record once the buffer is full
+}
+
+// NewQueryPerfLog creates an empty query performance log.
+func NewQueryPerfLog() *QueryPerfLog {
+	return &QueryPerfLog{}
+}
+
+// Record records the filters of a query of a table served at the given time.
+func (ql *QueryPerfLog) Record(table string, perf []tables.FilterPerf, at time.Time) {
+	ql.mu.Lock()
+	defer ql.mu.Unlock()
+
+	for _, p := range perf {
+		record := models.QueryPerfRecord{
+			At:       at,
+			Table:    table,
+			Column:   p.Column,
+			Match:    p.Match,
+			Path:     p.Path,
+			Reason:   p.Reason,
+			Rows:     uint32(p.Rows),
+			Matched:  uint32(p.Matched),
+			Duration: p.Duration,
+		}
+		if len(ql.records) < MaxQueryPerfRecords {
+			ql.records = append(ql.records, record)
+			continue
+		}
+		ql.records[ql.next] = record
+		ql.next = (ql.next + 1) % MaxQueryPerfRecords
+	}
+}
+
+// Records returns the recorded filter evaluations.
+func (ql *QueryPerfLog) Records() []models.QueryPerfRecord {
+	ql.mu.Lock()
+	defer ql.mu.Unlock()
+	return slices.Clone(ql.records)
+}
@@ -0,0 +1,45 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestQueryPerfTable(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.GetTable(t, "orders", "columns=order_id,status&filter:status="+url.QueryEscape(`"shipped"`)).AssertStatus(t, http.StatusOK)
+
+	resp := srv.GetTable(t, "_query_perf", "columns=table_name,column_name,path,matched")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("td", "data-cell-column", "column_name"); !slices.Equal(got, []string{"status"}) {
+		t.Fatalf("filtered columns = %v, want [status]", got)
+	}
+	if got := resp.Elements("td", "data-cell-column", "path"); !slices.Equal(got, []string{"typed"}) {
+		t.Errorf("paths = %v, want [typed]", got)
+	}
+	if got := resp.Elements("td", "data-cell-column", "matched"); !slices.Equal(got, []string{"3"}) {
+		t.Errorf("matched = %v, want [3]", got)
+	}
+}
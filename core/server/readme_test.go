@@ -0,0 +1,49 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestTableReadmePanel(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.SetTableReadmeResolver(func(tableName string) string {
+		if tableName == "orders" {
+			return "# Orders\n\nAmounts are in **USD**. See [the wiki](https://wiki.example.com/orders).\n\n<script>alert(1)</script>"
+		}
+		return ""
+	})
+
+	resp := srv.GetTable(t, "orders", "")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, `class="readme-panel"`)
+	resp.AssertContains(t, `<strong>USD</strong>`)
+	resp.AssertContains(t, `href="https://wiki.example.com/orders"`)
+	resp.AssertContains(t, `&lt;script&gt;alert(1)&lt;/script&gt;`)
+	resp.AssertNotContains(t, `<script>alert(1)</script>`)
+
+	// Tables without documentation get no panel
+	resp = srv.GetTable(t, "regions", "")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertNotContains(t, `class="readme-panel"`)
+}
@@ -0,0 +1,68 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/taxinomia/core/models"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// TableReloader is a function that loads a table again from its source and returns
+// its new version.
+type TableReloader func(tableName string) (*tables.DataTable, error)
+
+// SetTableReloader sets the function ReloadTables loads new table versions with
+func (s *Server) SetTableReloader(reloader TableReloader) {
+	s.reloader = reloader
+}
+
+// ReloadTables loads every user table of the data model again and replaces it. Cached
+// views keep their computed and joined columns, except those derived from columns whose
+// data changed. Tables that fail to reload keep serving their previous version, and the
+// failures are returned together. Requests served through Handler wait for the reload.
+func (s *Server) ReloadTables() error {
+	if s.reloader == nil {
+		return errors.New("no table reloader set")
+	}
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	var names []string
+	for name := range s.dataModel.GetAllTables() {
+		if !models.IsSystemTable(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var failures []error
+	for _, name := range names {
+		table, err := s.reloader(name)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("failed to reload %s: %w", name, err))
+			continue
+		}
+		s.dataModel.AddTable(name, table)
+	}
+	return errors.Join(failures...)
+}
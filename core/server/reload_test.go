@@ -0,0 +1,57 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/taxinomia/core/tables"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestReloadRecomputesDependentColumns(t *testing.T) {
+	dm := testsupport.NewDataModel()
+	srv := testsupport.NewServerWithDataModel(t, dm)
+	params := "columns=order_id,double,region.regions.region.name&computed=" + url.QueryEscape("double=amount * 2")
+
+	resp := srv.GetTable(t, "orders", params)
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("td", "data-cell-column", "double"); len(got) != testsupport.OrdersRowCount || got[0] != "200" {
+		t.Fatalf("expected doubled amounts, got %q", got)
+	}
+
+	// Reload orders with new amounts
+	orders := tables.NewDataTable()
+	orders.AddColumn(testsupport.StringColumn("order_id", "Order ID", "order", "o1", "o2", "o3", "o4", "o5", "o6"))
+	orders.AddColumn(testsupport.StringColumn("region", "Region", "region", "north", "north", "south", "west", "south", "north"))
+	orders.AddColumn(testsupport.StringColumn("status", "Status", "", "shipped", "pending", "shipped", "cancelled", "shipped", "pending"))
+	orders.AddColumn(testsupport.Uint32Column("amount", "Amount", 1, 2, 3, 4, 5, 6))
+	dm.AddTable("orders", orders)
+
+	resp = srv.GetTable(t, "orders", params)
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("td", "data-cell-column", "double"); len(got) != testsupport.OrdersRowCount || got[0] != "2" || got[5] != "12" {
+		t.Errorf("expected doubled amounts of the reloaded table, got %q", got)
+	}
+	if got := resp.Elements("td", "data-cell-column", "region.regions.region.name"); len(got) != testsupport.OrdersRowCount || got[0] != "North" {
+		t.Errorf("expected joined region names, got %q", got)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestRemovedColumnsDegradeGracefully(t *testing.T) {
+	srv := testsupport.NewServer(t)
+
+	resp := srv.GetTable(t, "orders", "columns=region,order_id,legacy_code,amount&grouped=region,legacy_code&filter:legacy_code=x&sort=-legacy_code")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, "Column <strong>legacy_code</strong> no longer exists (removed from view)")
+	// The rest of the view renders, with its grouping and without the filter on the removed column
+	if got := resp.Elements("td", "data-column", "region"); len(got) != testsupport.RegionsRowCount {
+		t.Errorf("expected %d region groups, got %d", testsupport.RegionsRowCount, len(got))
+	}
+	m := regexp.MustCompile(`<a href="([^"]*)">Open the updated link</a>`).FindStringSubmatch(resp.Body)
+	if m == nil {
+		t.Fatal("expected a link to the view without the removed column")
+	}
+	sanitized := html.UnescapeString(m[1])
+	if strings.Contains(sanitized, "legacy_code") || !strings.Contains(sanitized, "grouped=region") {
+		t.Errorf("sanitized URL = %q, want the view grouped by region without legacy_code", sanitized)
+	}
+	srv.Get(t, sanitized).AssertNotContains(t, "no longer exists")
+
+	// A view of only removed columns falls back to the default view
+	resp = srv.GetTable(t, "orders", "columns=legacy_code")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, "Column <strong>legacy_code</strong> no longer exists")
+	if got := resp.Elements("td", "data-cell-column", "order_id"); len(got) != testsupport.OrdersRowCount {
+		t.Errorf("expected the default view with %d rows, got %d", testsupport.OrdersRowCount, len(got))
+	}
+}
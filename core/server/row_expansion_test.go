@@ -0,0 +1,48 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/tables"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestRowExpansion(t *testing.T) {
+	dm := testsupport.NewDataModel()
+	events := tables.NewDataTable()
+	events.AddColumn(testsupport.StringColumn("event", "Event", "", "click", "view"))
+	events.AddColumn(testsupport.StringColumn("payload", "Payload", "", `{"x":1,"y":[2]}`, "{}"))
+	dm.AddTable("events", events)
+	srv := testsupport.NewServerWithDataModel(t, dm)
+
+	resp := srv.GetTable(t, "events", "columns=event,payload")
+	resp.AssertStatus(t, http.StatusOK)
+	if toggles := resp.Elements("a", "class", "row-expand-toggle"); len(toggles) != 2 {
+		t.Fatalf("expected an expand toggle on both JSON rows, got %q", toggles)
+	}
+	resp.AssertNotContains(t, `class="expanded-cell"`)
+
+	resp = srv.GetTable(t, "events", "columns=event,payload&expandrows=0")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, `class="expanded-cell"`)
+	resp.AssertContains(t, "{\n  &#34;x&#34;: 1,\n  &#34;y&#34;: [\n    2\n  ]\n}")
+}
@@ -0,0 +1,50 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestRowLinkColumn(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.SetURLResolver(func(entityType, value string) string {
+		return "/entity/" + entityType + "/" + value
+	})
+	srv.SetRowLinkColumnResolver(func(tableName string) string {
+		if tableName == "orders" {
+			return "order_id"
+		}
+		return ""
+	})
+
+	// Table default applies even when the column is not displayed
+	resp := srv.GetTable(t, "orders", "columns=status,amount")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, `data-row-url="/entity/order/o1"`)
+
+	// The query parameter overrides the table default
+	resp = srv.GetTable(t, "orders", "columns=status,amount&rowlink=region")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, `data-row-url="/entity/region/north"`)
+	resp.AssertNotContains(t, `data-row-url="/entity/order/o1"`)
+}
@@ -19,19 +19,28 @@ limitations under the License.
 package server
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/url"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/safehtml/template"
+	"github.com/google/taxinomia/core/chaos"
 	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/errs"
 	"github.com/google/taxinomia/core/expr"
+	"github.com/google/taxinomia/core/internal/grouping"
+	"github.com/google/taxinomia/core/internal/markdown"
+	"github.com/google/taxinomia/core/internal/rendering"
 	"github.com/google/taxinomia/core/models"
 	"github.com/google/taxinomia/core/query"
-	"github.com/google/taxinomia/core/rendering"
 	"github.com/google/taxinomia/core/tables"
 	"github.com/google/taxinomia/core/users"
 	"github.com/google/taxinomia/core/views"
@@ -42,8 +51,16 @@ const (
 	MaxComputedColumns = 10 // Maximum number of computed columns per request
 	MaxFilters         = 20 // Maximum number of filter expressions per request
 	MaxGroupingLevels  = 5  // Maximum number of grouping levels per request
+	MaxExampleRows     = 10 // Maximum number of example rows per group
 )
 
+// DefaultColumnCount is the number of leading columns shown for a table without a default view
+const DefaultColumnCount = 4
+
+// DefaultProgressiveAggregateMinRows is the number of filtered rows from which grouped views
+// are sent before their aggregates are computed
+const DefaultProgressiveAggregateMinRows = 200000
+
 // ProductConfig defines the configuration interface for a product.
 // Products provide their own tables, landing page settings, and default columns.
 type ProductConfig interface {
@@ -60,18 +77,114 @@ type PrimaryKeyResolver func(tableName string) string
 // EntityTypeDescriptionResolver is a function that returns the description for an entity type.
 type EntityTypeDescriptionResolver func(entityType string) string
 
+// TableReadmeResolver is a function that returns the Markdown documentation for a table,
+// or empty string if the table has none.
+type TableReadmeResolver func(tableName string) string
+
+// TableFreshnessResolver is a function that returns when a table's data was loaded,
+// or the zero time if unknown.
+type TableFreshnessResolver func(tableName string) time.Time
+
+// RowLinkColumnResolver is a function that returns the column whose entity URL a row click
+// navigates to for a table, or empty string if row clicks should select the row instead.
+type RowLinkColumnResolver func(tableName string) string
+
+// TableDefaultViewResolver is a function that returns the view a table opens with when
+// no columns are requested, or nil if the table has no configured default view.
+type TableDefaultViewResolver func(tableName string) *query.DefaultView
+
+// ColumnPresetsResolver is a function that returns the column presets of a table,
+// or nil if the table has none.
+type ColumnPresetsResolver func(tableName string) []query.ColumnPreset
+
+// EntityHierarchiesResolver is a function that returns the entity types of each configured
+// hierarchy, ordered from root to leaf.
+type EntityHierarchiesResolver func() [][]string
+
+// TableVersionResolver is a function that returns the version number of the current
+// data of a table, or 0 if unknown.
+type TableVersionResolver func(tableName string) int
+
+// TableSnapshotResolver is a function that returns the retained snapshot of a table that
+// was current at the given time, and when that snapshot was loaded.
+type TableSnapshotResolver func(tableName string, at time.Time) (*tables.DataTable, time.Time, error)
+
+// DataQualityResolver is a function that returns the validation rule results of a table,
+// or nil if the table has no validation rules.
+type DataQualityResolver func(tableName string) []models.DataQualityRecord
+
 // Server represents the application server with all its dependencies
 type Server struct {
 	dataModel          *models.DataModel
 	renderer           *rendering.TableRenderer
 	tableViewCache     map[string]*tables.TableView
 	userStore          users.UserStore
+	defaultLocale      string // Locale of the pages of users without a preferred locale (empty = views.DefaultLocale)
 	urlResolver               views.URLResolver                // Optional resolver for entity type URLs
+	batchURLResolver          views.BatchURLResolver           // Optional resolver for many entity type URLs at once
 	allURLsResolver           views.AllURLsResolver            // Optional resolver for all entity type URLs (for detail panel)
 	primaryKeyResolver        PrimaryKeyResolver               // Optional resolver for table primary key entity types
 	entityTypeDescResolver    EntityTypeDescriptionResolver    // Optional resolver for entity type descriptions
 	hierarchyContextBuilder   views.HierarchyContextBuilder    // Optional builder for hierarchy contexts in detail panel
 	relatedTablesResolver     views.RelatedTablesResolver      // Optional resolver for related tables in detail panel
+	rowLinkColumnResolver     RowLinkColumnResolver            // Optional resolver for the default row link column of a table
+	readmeResolver            TableReadmeResolver              // Optional resolver for table documentation
+	freshnessResolver         TableFreshnessResolver           // Optional resolver for table load times (overview page)
+	defaultViewResolver       TableDefaultViewResolver         // Optional resolver for the default view of a table
+	columnPresetsResolver     ColumnPresetsResolver            // Optional resolver for the column presets of a table
+	versionResolver           TableVersionResolver             // Optional resolver for table versions (export manifests)
+	dataQualityResolver       DataQualityResolver              // Optional resolver for validation rule results (_data_quality)
+	snapshotResolver          TableSnapshotResolver            // Optional resolver for retained table snapshots (asof)
+	hierarchiesResolver       EntityHierarchiesResolver        // Optional resolver for hierarchy levels (entity badge order)
+	reloader                  TableReloader                    // Optional loader of new table versions (ReloadTables)
+	sqlResolver               SQLResolver                      // Optional runner of SQL statements on tables outside the data model
+
+	// Held for reading while Handler serves a request, and for writing while tables are reloaded
+	reloadMu sync.RWMutex
+
+	// Groupings precomputed when tables load, and the precomputed groups of each table
+	hotGroupings         []HotGrouping
+	precomputedGroupings map[string][]*tables.PrecomputedGrouping
+
+	// Access statistics for the _usage system table and the overview page
+	usage *UsageStats
+
+	// Filter paths and timings of recent queries for the _query_perf system table
+	queryPerf *QueryPerfLog
+
+	// Cost of the queries of each user of each product for the _query_cost system table
+	queryCosts *QueryCostStats
+
+	// Query states of recorded sessions
+	sessions *SessionRecorder
+
+	// Last accesses of cached table views, to release the caches of idle ones
+	janitor *CacheJanitor
+
+	// Optional hook receiving interaction events (nil = disabled), with per-kind sampling
+	eventHook        EventHook
+	eventSampleRates map[EventKind]float64
+
+	// Processors changing the view models of table pages before rendering, in registration order
+	viewModelProcessors []ViewModelProcessor
+
+	// Optional fault injection for resilience testing (nil = disabled)
+	faults *chaos.Injector
+
+	// Grouped views of at least this many rows stream their aggregates after the page (0 = never)
+	progressiveAggregateMinRows int
+
+	// Whether table requests with unknown URL parameters are rejected (overridden by strict=0|1)
+	strictURLs bool
+
+	// Order of column roles for tables opened without a default view (nil = table order)
+	columnOrder []views.ColumnRole
+
+	// Whether flat rows of tables with several entity columns show a breadcrumb of entity badges
+	entityBadges bool
+
+	// Number of table columns above which the column picker groups them
+	columnPickerThreshold int
 
 	// Caches for computed columns
 	exprCache         map[string]*expr.Expression   // expression string -> compiled expression
@@ -86,14 +199,51 @@ func NewServer(dataModel *models.DataModel) (*Server, error) {
 		return nil, fmt.Errorf("failed to create renderer: %w", err)
 	}
 
-	return &Server{
+	s := &Server{
 		dataModel:         dataModel,
 		renderer:          renderer,
 		tableViewCache:    make(map[string]*tables.TableView),
 		exprCache:         make(map[string]*expr.Expression),
 		computedColState:  make(map[string]map[string]string),
 		computedColErrors: make(map[string]map[string]string),
-	}, nil
+		usage:             NewUsageStats(),
+		queryPerf:         NewQueryPerfLog(),
+		queryCosts:        NewQueryCostStats(),
+		sessions:          NewSessionRecorder(),
+		janitor:           NewCacheJanitor(),
+
+		precomputedGroupings: make(map[string][]*tables.PrecomputedGrouping),
+
+		progressiveAggregateMinRows: DefaultProgressiveAggregateMinRows,
+		columnOrder:                 views.DefaultColumnOrder,
+		entityBadges:                true,
+		columnPickerThreshold:       views.DefaultColumnPickerGroupThreshold,
+	}
+	dataModel.OnColumnsChanged(s.invalidateColumns)
+	return s, nil
+}
+
+// invalidateColumns drops the computed and joined columns of cached table views that
+// depend on changed columns, so that only those are recomputed on the next request.
+// Views over the changed table are rebound to its current version.
+func (s *Server) invalidateColumns(tableName string, changed []string) {
+	refs := make([]tables.ColumnRef, len(changed))
+	for i, col := range changed {
+		refs[i] = tables.ColumnRef{Table: tableName, Column: col}
+	}
+	for cacheKey, tableView := range s.tableViewCache {
+		if tableView.TableName() == tableName {
+			if table := s.dataModel.GetTable(tableName); table != nil {
+				tableView.SetBaseTable(table)
+			}
+		}
+		for _, name := range tableView.InvalidateColumns(refs) {
+			log.Printf("Invalidated column %s of %s after %s changed", name, cacheKey, tableName)
+			delete(s.computedColState[cacheKey], name)
+			delete(s.computedColErrors[cacheKey], name)
+		}
+	}
+	s.precomputeGroupings(tableName)
 }
 
 // SetUserStore sets the user store for authentication
@@ -101,11 +251,35 @@ func (s *Server) SetUserStore(store users.UserStore) {
 	s.userStore = store
 }
 
+// SetDefaultLocale sets the BCP 47 locale of the pages of users without a preferred
+// locale in their profile, e.g. "he" for a deployment serving a Hebrew-speaking team.
+// Pages in right-to-left languages are laid out right to left.
+func (s *Server) SetDefaultLocale(locale string) {
+	s.defaultLocale = locale
+}
+
+// pageLocale returns the locale of the pages of a user: the preferred locale of the
+// user's profile if set, otherwise the default locale of the server.
+func (s *Server) pageLocale(userName string) views.PageLocale {
+	if s.userStore != nil && userName != "" {
+		if locale := s.userStore.GetUser(userName).GetLocale(); locale != "" {
+			return views.NewPageLocale(locale)
+		}
+	}
+	return views.NewPageLocale(s.defaultLocale)
+}
+
 // SetURLResolver sets the URL resolver for entity type links
 func (s *Server) SetURLResolver(resolver views.URLResolver) {
 	s.urlResolver = resolver
 }
 
+// SetBatchURLResolver sets the resolver used to precompute entity type links in bulk.
+// It can be set alongside or instead of the URL resolver.
+func (s *Server) SetBatchURLResolver(resolver views.BatchURLResolver) {
+	s.batchURLResolver = resolver
+}
+
 // SetAllURLsResolver sets the resolver for all entity type URLs (used in detail panel)
 func (s *Server) SetAllURLsResolver(resolver views.AllURLsResolver) {
 	s.allURLsResolver = resolver
@@ -131,6 +305,195 @@ func (s *Server) SetRelatedTablesResolver(resolver views.RelatedTablesResolver)
 	s.relatedTablesResolver = resolver
 }
 
+// SetRowLinkColumnResolver sets the resolver for the column a row click navigates to.
+// The "rowlink" query parameter overrides the resolved column for a single view.
+func (s *Server) SetRowLinkColumnResolver(resolver RowLinkColumnResolver) {
+	s.rowLinkColumnResolver = resolver
+}
+
+// SetTableReadmeResolver sets the resolver for the Markdown documentation shown above a table
+func (s *Server) SetTableReadmeResolver(resolver TableReadmeResolver) {
+	s.readmeResolver = resolver
+}
+
+// SetTableFreshnessResolver sets the resolver for table load times shown on the overview page
+func (s *Server) SetTableFreshnessResolver(resolver TableFreshnessResolver) {
+	s.freshnessResolver = resolver
+}
+
+// SetTableDefaultViewResolver sets the resolver for the view a table opens with.
+// Default columns of the product take precedence over the resolved view.
+func (s *Server) SetTableDefaultViewResolver(resolver TableDefaultViewResolver) {
+	s.defaultViewResolver = resolver
+}
+
+// SetColumnPresetsResolver sets the resolver for the column presets a table view can switch between
+func (s *Server) SetColumnPresetsResolver(resolver ColumnPresetsResolver) {
+	s.columnPresetsResolver = resolver
+}
+
+// SetEntityHierarchiesResolver sets the resolver for the levels of the configured
+// hierarchies, which order the entity badges of rows from root to leaf
+func (s *Server) SetEntityHierarchiesResolver(resolver EntityHierarchiesResolver) {
+	s.hierarchiesResolver = resolver
+}
+
+// SetTableVersionResolver sets the resolver for the table versions recorded in export bundles
+func (s *Server) SetTableVersionResolver(resolver TableVersionResolver) {
+	s.versionResolver = resolver
+}
+
+// SetDataQualityResolver sets the resolver for the validation rule results reported in
+// the _data_quality system table
+func (s *Server) SetDataQualityResolver(resolver DataQualityResolver) {
+	s.dataQualityResolver = resolver
+}
+
+// SetTableSnapshotResolver sets the resolver for the retained table snapshots that
+// queries with an asof time run against
+func (s *Server) SetTableSnapshotResolver(resolver TableSnapshotResolver) {
+	s.snapshotResolver = resolver
+}
+
+// SetTableTemplate replaces the table page template with a product's own template.
+// Custom templates should build their links and values with the template helpers
+// (docs/template_helpers.md) rather than rely on the fields of views.TableViewModel.
+func (s *Server) SetTableTemplate(fsys template.TrustedFS, name string, patterns ...string) error {
+	return s.renderer.SetTableTemplate(fsys, name, patterns...)
+}
+
+// TemplateFuncs returns the functions available to table templates set with
+// SetTableTemplate. Their names, arguments and results are kept stable across releases;
+// see docs/template_helpers.md for the full reference.
+func TemplateFuncs() template.FuncMap {
+	return rendering.TemplateFuncs()
+}
+
+// SetFaultInjector sets the injector of artificial latency and failures at the
+// join resolution and render boundaries of table requests (for resilience testing)
+func (s *Server) SetFaultInjector(injector *chaos.Injector) {
+	s.faults = injector
+}
+
+// SetProgressiveAggregateMinRows sets the number of filtered rows from which grouped views
+// are rendered before their aggregates are computed. The aggregates are then streamed into
+// the page as each top-level group completes. 0 disables progressive rendering.
+func (s *Server) SetProgressiveAggregateMinRows(minRows int) {
+	s.progressiveAggregateMinRows = minRows
+}
+
+// SetStrictURLs sets whether table requests with unknown URL parameters, such as a
+// misspelled fitler:status, are rejected with a 400 instead of the parameters being
+// ignored. A request can override the setting with strict=1 or strict=0.
+func (s *Server) SetStrictURLs(strict bool) {
+	s.strictURLs = strict
+}
+
+// SetColumnOrder sets the order of column roles in which the columns of tables without
+// default columns or a default view are shown (views.DefaultColumnOrder by default).
+// nil shows the columns in the order of the table.
+func (s *Server) SetColumnOrder(order []views.ColumnRole) {
+	s.columnOrder = order
+}
+
+// SetColumnPickerThreshold sets the number of table columns above which the column picker
+// groups columns by category or name prefix instead of listing them all
+// (views.DefaultColumnPickerGroupThreshold by default).
+func (s *Server) SetColumnPickerThreshold(columns int) {
+	s.columnPickerThreshold = columns
+}
+
+// SetEntityBadges sets whether the flat rows of tables with several entity columns show
+// their entities, displayed or not, as a breadcrumb of linked badges (enabled by default)
+func (s *Server) SetEntityBadges(enabled bool) {
+	s.entityBadges = enabled
+}
+
+// Usage returns the table access statistics recorded by the server
+func (s *Server) Usage() *UsageStats {
+	return s.usage
+}
+
+// QueryCosts returns the cost of the queries served to each user of each product
+func (s *Server) QueryCosts() *QueryCostStats {
+	return s.queryCosts
+}
+
+// Sessions returns the session recordings of the server
+func (s *Server) Sessions() *SessionRecorder {
+	return s.sessions
+}
+
+// refreshColumnsTable rebuilds the _columns system table from the columns of the tables,
+// which change as tables are loaded and reloaded
+func (s *Server) refreshColumnsTable() {
+	s.dataModel.AddTable(models.ColumnsTableName, models.BuildColumnsTable(s.dataModel))
+}
+
+// refreshUsageTable rebuilds the _usage system table from the recorded access statistics
+func (s *Server) refreshUsageTable() {
+	s.dataModel.AddTable(models.UsageTableName, models.BuildUsageTable(s.dataModel, s.usage.Records()))
+}
+
+// refreshDataQualityTable rebuilds the _data_quality system table from the validation
+// rule results of the tables, which change as tables are reloaded
+func (s *Server) refreshDataQualityTable() {
+	var records []models.DataQualityRecord
+	if s.dataQualityResolver != nil {
+		for name := range s.dataModel.GetAllTables() {
+			if !models.IsSystemTable(name) {
+				records = append(records, s.dataQualityResolver(name)...)
+			}
+		}
+	}
+	s.dataModel.AddTable(models.DataQualityTableName, models.BuildDataQualityTable(records))
+}
+
+// refreshQueryPerfTable rebuilds the _query_perf system table from the recorded filter evaluations
+func (s *Server) refreshQueryPerfTable() {
+	s.dataModel.AddTable(models.QueryPerfTableName, models.BuildQueryPerfTable(s.queryPerf.Records()))
+}
+
+// refreshQueryCostTable rebuilds the _query_cost system table from the recorded query costs
+func (s *Server) refreshQueryCostTable() {
+	s.dataModel.AddTable(models.QueryCostTableName, models.BuildQueryCostTable(s.queryCosts.Records()))
+}
+
+// refreshMemoryTable rebuilds the _memory system table from the current columns and
+// the caches of all cached table views
+func (s *Server) refreshMemoryTable() {
+	type cacheID struct{ table, kind, column string }
+	totals := make(map[cacheID]*models.CacheRecord)
+	var order []cacheID
+	add := func(id cacheID, entries int, bytes uint64) {
+		record := totals[id]
+		if record == nil {
+			record = &models.CacheRecord{Table: id.table, Kind: id.kind, Column: id.column}
+			totals[id] = record
+			order = append(order, id)
+		}
+		record.Entries += entries
+		record.Bytes += bytes
+	}
+	for _, tableView := range s.tableViewCache {
+		if models.IsSystemTable(tableView.TableName()) {
+			continue
+		}
+		footprint := tableView.CacheFootprint()
+		if footprint.FilterMaskBytes > 0 {
+			add(cacheID{tableView.TableName(), models.MemoryKindFilterMask, ""}, 1, footprint.FilterMaskBytes)
+		}
+		for _, g := range footprint.Groupings {
+			add(cacheID{tableView.TableName(), models.MemoryKindGrouping, g.Column}, g.Groups, g.Bytes)
+		}
+	}
+	records := make([]models.CacheRecord, 0, len(order))
+	for _, id := range order {
+		records = append(records, *totals[id])
+	}
+	s.dataModel.AddTable(models.MemoryTableName, models.BuildMemoryTable(s.dataModel, records))
+}
+
 // makeCacheKey creates a cache key combining user and table name
 // This ensures each user has their own TableView with their own computed columns
 func (s *Server) makeCacheKey(userName, tableName string) string {
@@ -147,10 +510,32 @@ type TableHandlerResult struct {
 	Message    string
 }
 
+// StatusForError maps an error to the HTTP status code the server responds with.
+// Errors of a known kind (see package errs) map to client or availability errors;
+// anything else is an internal error.
+func StatusForError(err error) int {
+	switch {
+	case errors.Is(err, errs.ErrUnknownTable), errors.Is(err, errs.ErrJoinNotFound):
+		return 404
+	case errors.Is(err, errs.ErrUnknownColumn), errors.Is(err, errs.ErrTypeMismatch), errors.Is(err, errs.ErrInvalidQuery):
+		return 400
+	case errors.Is(err, errs.ErrSourceUnavailable):
+		return 503
+	default:
+		return 500
+	}
+}
+
+// errorResult builds a TableHandlerResult for an error, deriving the status code from its kind.
+func errorResult(err error) *TableHandlerResult {
+	return &TableHandlerResult{Error: err, StatusCode: StatusForError(err), Message: err.Error()}
+}
+
 // ValidationResult holds validation errors for filters and computed columns
 type ValidationResult struct {
 	ComputedColumnErrors map[string]string // columnName -> error message
 	FilterErrors         map[string]string // columnName -> error message
+	GroupLabelErrors     map[string]string // grouped columnName -> error message
 }
 
 // NewValidationResult creates a new ValidationResult
@@ -158,14 +543,37 @@ func NewValidationResult() *ValidationResult {
 	return &ValidationResult{
 		ComputedColumnErrors: make(map[string]string),
 		FilterErrors:         make(map[string]string),
+		GroupLabelErrors:     make(map[string]string),
 	}
 }
 
 // HasErrors returns true if there are any validation errors
 func (v *ValidationResult) HasErrors() bool {
-	return len(v.ComputedColumnErrors) > 0 || len(v.FilterErrors) > 0
+	return len(v.ComputedColumnErrors) > 0 || len(v.FilterErrors) > 0 || len(v.GroupLabelErrors) > 0
+}
+
+// missingColumns returns the columns referenced by the query that do not exist in the table
+// view. Computed columns are not reported, their errors are reported separately.
+func missingColumns(tableView *tables.TableView, q *query.Query) []string {
+	var missing []string
+	for _, colName := range q.ReferencedColumns() {
+		if tableView.GetColumn(colName) != nil {
+			continue
+		}
+		if slices.ContainsFunc(q.ComputedColumns, func(c query.ComputedColumnDef) bool { return c.Name == colName }) {
+			continue
+		}
+		missing = append(missing, colName)
+	}
+	return missing
 }
 
+// unresolvedJoins is a join resolver that resolves nothing, so every joined column is skipped
+type unresolvedJoins struct{}
+
+func (unresolvedJoins) GetJoin(key string) interface{}         { return nil }
+func (unresolvedJoins) GetTable(name string) *tables.DataTable { return nil }
+
 // validateFilters checks if filter columns exist in the table view
 func (s *Server) validateFilters(tableView *tables.TableView, filters map[string]string) map[string]string {
 	errors := make(map[string]string)
@@ -206,6 +614,19 @@ func (tc *TimingCollector) TotalMs() string {
 	return fmt.Sprintf("%.2f", float64(time.Since(tc.start).Microseconds())/1000.0)
 }
 
+// isStrict reports whether a table request rejects unknown URL parameters: as its
+// strict parameter says if present, else as the server is configured.
+func (s *Server) isStrict(requestURL *url.URL) bool {
+	switch requestURL.Query().Get("strict") {
+	case "1":
+		return true
+	case "0":
+		return false
+	default:
+		return s.strictURLs
+	}
+}
+
 // HandleTableRequest processes a table request and writes the response
 // Returns an error result if the request is invalid, nil on success
 func (s *Server) HandleTableRequest(w io.Writer, requestURL *url.URL, product ProductConfig, setHeader func(key, value string)) *TableHandlerResult {
@@ -216,6 +637,17 @@ func (s *Server) HandleTableRequest(w io.Writer, requestURL *url.URL, product Pr
 	q := query.NewQuery(requestURL)
 	timing.Record("Parse Query", time.Since(parseStart))
 
+	// In strict mode, reject parameters that parsing would silently ignore
+	if s.isStrict(requestURL) {
+		if unknown := query.UnknownParameters(requestURL); len(unknown) > 0 {
+			names := make([]string, len(unknown))
+			for i, p := range unknown {
+				names[i] = p.String()
+			}
+			return &TableHandlerResult{StatusCode: 400, Message: "Unknown URL parameters: " + strings.Join(names, ", ")}
+		}
+	}
+
 	// Get user from URL parameter - cache is user-specific
 	userName := requestURL.Query().Get("user")
 	cacheKey := s.makeCacheKey(userName, q.Table)
@@ -235,29 +667,66 @@ func (s *Server) HandleTableRequest(w io.Writer, requestURL *url.URL, product Pr
 	if len(q.GroupedColumns) > MaxGroupingLevels {
 		return &TableHandlerResult{StatusCode: 400, Message: fmt.Sprintf("Too many grouping levels (max %d)", MaxGroupingLevels)}
 	}
+	if q.ExampleRows > MaxExampleRows {
+		return &TableHandlerResult{StatusCode: 400, Message: fmt.Sprintf("Too many example rows per group (max %d)", MaxExampleRows)}
+	}
+
+	// The _columns table reflects the columns and their update times as of this request
+	if q.Table == models.ColumnsTableName {
+		s.refreshColumnsTable()
+	}
+	// The _usage table reflects the accesses recorded up to this request
+	if q.Table == models.UsageTableName {
+		s.refreshUsageTable()
+	}
+	// The _memory table reflects the columns and caches as of this request
+	if q.Table == models.MemoryTableName {
+		s.refreshMemoryTable()
+	}
+	// The _data_quality table reflects the validation results of the loaded data
+	if q.Table == models.DataQualityTableName {
+		s.refreshDataQualityTable()
+	}
+	// The _query_perf table reflects the filters evaluated up to this request
+	if q.Table == models.QueryPerfTableName {
+		s.refreshQueryPerfTable()
+	}
+	// The _query_cost table reflects the queries served up to this request
+	if q.Table == models.QueryCostTableName {
+		s.refreshQueryCostTable()
+	}
 
 	// Get the table from data model
 	table := s.dataModel.GetTable(q.Table)
 	if table == nil {
-		return &TableHandlerResult{StatusCode: 404, Message: fmt.Sprintf("Table '%s' not found", q.Table)}
+		return errorResult(errs.New(errs.ErrUnknownTable, "Table '%s' not found", q.Table))
 	}
 
-	// Get default columns from product, or use first few columns if not defined
-	defaultColumns := product.GetDefaultColumns(q.Table)
-	if len(defaultColumns) == 0 {
-		// Use first 4 columns as default
-		allCols := table.GetColumnNames()
-		if len(allCols) > 4 {
-			defaultColumns = allCols[:4]
-		} else {
-			defaultColumns = allCols
+	// Run the whole query against the snapshot of the table current at the asof time.
+	// Snapshots are cached apart from the current data, one view per snapshot.
+	var snapshotLoadedAt time.Time
+	if q.AsOf != "" {
+		at, err := query.ParseAsOf(q.AsOf)
+		if err != nil {
+			return &TableHandlerResult{Error: err, StatusCode: 400, Message: err.Error()}
 		}
+		if s.snapshotResolver == nil {
+			return &TableHandlerResult{StatusCode: 404, Message: fmt.Sprintf("Table '%s' has no retained snapshots", q.Table)}
+		}
+		snapshot, loadedAt, err := s.snapshotResolver(q.Table, at)
+		if err != nil {
+			return &TableHandlerResult{Error: err, StatusCode: 404, Message: err.Error()}
+		}
+		table = snapshot
+		snapshotLoadedAt = loadedAt
+		cacheKey = fmt.Sprintf("%s@%d", cacheKey, loadedAt.UnixNano())
 	}
 
-	// Use default columns if none specified
+	// Open the table with its default view if no columns are specified
 	if len(q.Columns) == 0 {
-		q.Columns = defaultColumns
+		q.ApplyDefaultView(s.defaultView(product, q.Table, table))
 	}
+	viewSignature := ViewSignature(q)
 
 	// Convert expanded paths to map for compatibility
 	expandedPaths := make(map[string]bool)
@@ -272,16 +741,57 @@ func (s *Server) HandleTableRequest(w io.Writer, requestURL *url.URL, product Pr
 		GroupedColumns: q.GroupedColumns,
 	}
 
+	// Flat rows carry a breadcrumb of the entities of the table
+	if s.entityBadges && len(q.GroupedColumns) == 0 {
+		var hierarchies [][]string
+		if s.hierarchiesResolver != nil {
+			hierarchies = s.hierarchiesResolver()
+		}
+		view.BadgeColumns = views.EntityBadgeColumns(table, hierarchies)
+	}
+
 	// Get or create a cached TableView for this user+table combination
 	cacheStart := time.Now()
 	tableView := views.GetOrCreateTableView(cacheKey, table, s.tableViewCache)
+	s.janitor.Touch(cacheKey, tableView, time.Now())
+	tableView.SetPrecomputedGroupings(s.PrecomputedGroupings(q.Table))
 	timing.Record("Get TableView", time.Since(cacheStart))
 
 	// Update joined columns to match the current request
 	joinStart := time.Now()
-	views.ProcessJoinsAndUpdateColumns(tableView, &view, s.dataModel)
+	joinedBefore := tableView.GetJoinedColumnNames()
+	var joinResolver tables.JoinResolver = s.dataModel
+	joinFault := s.faults.Inject(chaos.Join, q.Table)
+	if joinFault != nil {
+		// An injected fault takes the same path as joins that cannot be resolved: the
+		// joined columns are skipped and the view renders without them
+		for _, colName := range joinedBefore {
+			tableView.RemoveJoinedColumn(colName)
+		}
+		joinResolver = unresolvedJoins{}
+	}
+	for colName, err := range views.ProcessJoinsAndUpdateColumns(tableView, &view, joinResolver) {
+		if joinFault != nil {
+			err = joinFault
+		}
+		log.Printf("Skipping joined column %s: %v", colName, err)
+	}
+	for _, colName := range tableView.GetJoinedColumnNames() {
+		if !slices.Contains(joinedBefore, colName) {
+			s.emit(EventJoinAdded, userName, q.Table, map[string]string{"column": colName})
+		}
+	}
 	timing.Record("Process Joins", time.Since(joinStart))
 
+	// Generate delta and percent columns when comparing two numeric columns
+	if len(q.DiffColumns) == 2 &&
+		tableView.GetColumnType(q.DiffColumns[0]) == query.ColumnTypeNumeric &&
+		tableView.GetColumnType(q.DiffColumns[1]) == query.ColumnTypeNumeric {
+		q.AddColumnDiffComputedColumns()
+		view.Columns = q.Columns
+		tableView.VisibleColumns = view.Columns
+	}
+
 	// Create validation result to collect errors
 	validation := NewValidationResult()
 
@@ -290,16 +800,45 @@ func (s *Server) HandleTableRequest(w io.Writer, requestURL *url.URL, product Pr
 	validation.ComputedColumnErrors = s.updateComputedColumns(tableView, q, cacheKey)
 	timing.Record("Computed Columns", time.Since(computedStart))
 
+	// Columns referenced by the URL that no longer exist (e.g., shared links after a schema
+	// change) are removed from the view, which renders without them
+	removedColumns := missingColumns(tableView, q)
+	if len(removedColumns) > 0 {
+		q.RemoveColumns(removedColumns)
+		if len(q.Columns) == 0 {
+			q.ApplyDefaultView(s.defaultView(product, q.Table, table))
+		}
+		view.Columns = q.Columns
+		view.GroupedColumns = q.GroupedColumns
+		views.ProcessJoinsAndUpdateColumns(tableView, &view, joinResolver)
+	}
+
+	// Identifier columns default to count aggregates and demote sum and average
+	q.IdentifierColumns = make(map[string]bool)
+	for _, colName := range view.Columns {
+		if tableView.IsIdentifierColumn(colName) {
+			q.IdentifierColumns[colName] = true
+		}
+	}
+
 	// Validate filter columns exist before applying
 	validation.FilterErrors = s.validateFilters(tableView, q.Filters)
 
 	// Apply filters to the table view (even with errors, apply valid filters)
 	filterStart := time.Now()
+	filtersBefore := tableView.AppliedFilters()
 	tableView.ApplyFilters(q.Filters)
+	s.queryPerf.Record(q.Table, tableView.TakeFilterPerf(), filterStart)
+	for colName, value := range q.Filters {
+		if previous, ok := filtersBefore[colName]; (!ok || previous != value) && validation.FilterErrors[colName] == "" {
+			s.emit(EventFilterApplied, userName, q.Table, map[string]string{"column": colName})
+		}
+	}
 	timing.Record("Apply Filters", time.Since(filterStart))
 
 	// Apply grouping if grouped columns are specified
 	groupStart := time.Now()
+	tableView.SetCohorts(q.Cohorts)
 	if len(q.GroupedColumns) > 0 {
 		// Build ascending map from sort order for grouped columns
 		ascMap := make(map[string]bool)
@@ -315,11 +854,16 @@ func (s *Server) HandleTableRequest(w io.Writer, requestURL *url.URL, product Pr
 		}
 		// Call GroupTableWithLimit - it will use the cached filter mask
 		// Pass display limit for top-K optimization when sorting groups
-		tableView.GroupTableWithLimit(q.GroupedColumns, []string{}, make(map[string]tables.Compare), ascMap, q.Limit)
+		if s.streamsAggregates(w, tableView, q) {
+			tableView.GroupTableDeferringAggregates(q.GroupedColumns, ascMap, q.Limit)
+		} else {
+			tableView.GroupTableWithLimit(q.GroupedColumns, []string{}, make(map[string]tables.Compare), ascMap, q.Limit)
+		}
 
 	} else {
 		tableView.ClearGroupings()
 	}
+	validation.GroupLabelErrors = s.updateGroupLabels(tableView, q)
 	timing.Record("Grouping", time.Since(groupStart))
 
 	// Build the view model from the table view
@@ -329,17 +873,39 @@ func (s *Server) HandleTableRequest(w io.Writer, requestURL *url.URL, product Pr
 	if s.primaryKeyResolver != nil {
 		primaryKeyEntityType = s.primaryKeyResolver(q.Table)
 	}
+	rowLinkColumn := q.RowLinkColumn
+	if rowLinkColumn == "" && s.rowLinkColumnResolver != nil {
+		rowLinkColumn = s.rowLinkColumnResolver(q.Table)
+	}
 	var entityTypeDescResolver views.EntityTypeDescriptionResolver
 	if s.entityTypeDescResolver != nil {
 		entityTypeDescResolver = views.EntityTypeDescriptionResolver(s.entityTypeDescResolver)
 	}
-	viewModel := views.BuildViewModel(s.dataModel, q.Table, tableView, view, title, q, validation.ComputedColumnErrors, validation.FilterErrors, s.urlResolver, s.allURLsResolver, primaryKeyEntityType, entityTypeDescResolver, s.hierarchyContextBuilder, s.relatedTablesResolver)
+	viewModel := views.BuildViewModel(s.dataModel, q.Table, tableView, view, title, q, views.ViewModelOptions{
+		ComputedColumnErrors:    validation.ComputedColumnErrors,
+		FilterErrors:            validation.FilterErrors,
+		URLResolver:             s.urlResolver,
+		BatchURLResolver:        s.batchURLResolver,
+		AllURLsResolver:         s.allURLsResolver,
+		PrimaryKeyEntityType:    primaryKeyEntityType,
+		RowLinkColumn:           rowLinkColumn,
+		EntityTypeDescResolver:  entityTypeDescResolver,
+		HierarchyContextBuilder: s.hierarchyContextBuilder,
+		RelatedTablesResolver:   s.relatedTablesResolver,
+		ColumnPickerThreshold:   s.columnPickerThreshold,
+	})
 	timing.Record("Build ViewModel", time.Since(vmStart))
 
 	// Set timing information
 	viewModel.RenderTimeMs = timing.TotalMs()
 	viewModel.TimingBreakdown = timing.GetEntries()
 
+	for colName, errMsg := range validation.GroupLabelErrors {
+		viewModel.GroupLabelErrors[colName] = views.ValidationError{Message: errMsg, Expression: q.GroupLabels[colName]}
+	}
+
+	viewModel.PageLocale = s.pageLocale(userName)
+
 	// Set info pane state from Query (already parsed from URL)
 	viewModel.ShowInfoPane = q.ShowInfoPane
 	viewModel.InfoPaneTab = q.InfoPaneTab
@@ -350,19 +916,125 @@ func (s *Server) HandleTableRequest(w io.Writer, requestURL *url.URL, product Pr
 	// Parse column types display state from URL
 	viewModel.ShowColumnTypes = requestURL.Query().Get("types") == "1"
 
+	// Report the columns removed from the view, with the link to the view without them
+	if len(removedColumns) > 0 {
+		viewModel.RemovedColumns = removedColumns
+		viewModel.SanitizedURL = q.ToSafeURL()
+	}
+
+	// Report the snapshot the view was run against, with the link back to the current data
+	if q.AsOf != "" {
+		viewModel.AsOf = q.AsOf
+		viewModel.SnapshotLoadedAt = snapshotLoadedAt.Format(time.RFC3339)
+		viewModel.CurrentDataURL = q.WithAsOf("")
+	}
+
+	// Record the view if the session is recorded, and set up recording and replay controls
+	s.setSessionState(&viewModel, q, requestURL)
+
+	// Render the table documentation panel
+	if s.readmeResolver != nil {
+		if readme := s.readmeResolver(q.Table); readme != "" {
+			html, err := s.renderer.RenderMarkdown(markdown.Parse(readme))
+			if err != nil {
+				log.Printf("Readme rendering error for table %s: %v", q.Table, err)
+			} else {
+				viewModel.Readme = html
+				viewModel.HasReadme = true
+			}
+		}
+	}
+
+	// Link the column presets, which keep the filters and grouping of the view
+	if s.columnPresetsResolver != nil {
+		for _, preset := range s.columnPresetsResolver(q.Table) {
+			viewModel.ColumnPresets = append(viewModel.ColumnPresets, views.ColumnPresetLink{
+				Name:   preset.Name,
+				URL:    q.WithColumnPreset(preset),
+				Active: q.IsColumnPresetActive(preset),
+			})
+		}
+	}
+
+	// Let the registered processors change the view model
+	if err := s.processViewModel(&viewModel, ViewModelContext{Table: q.Table, User: userName, Query: q}); err != nil {
+		return errorResult(err)
+	}
+
 	// Set content type and render
 	renderStart := time.Now()
+	if err := s.faults.Inject(chaos.Render, q.Table); err != nil {
+		return errorResult(err)
+	}
 	setHeader("Content-Type", "text/html; charset=utf-8")
-	if err := s.renderer.Render(w, viewModel); err != nil {
+	viewModel.StreamAggregates = tableView.AggregatesPending()
+	out := &countingWriter{w: w}
+	if err := s.renderer.Render(out, viewModel); err != nil {
 		log.Printf("Template rendering error: %v", err)
-		return &TableHandlerResult{Error: err}
+		return errorResult(err)
+	}
+	if viewModel.StreamAggregates {
+		s.streamAggregates(out, viewModel, tableView)
 	}
 	// Note: render timing not included in page since it happens after ViewModel is built
 	_ = renderStart
 
+	s.recordQueryCost(product, userName, timing.start, tableView.TakeRowsScanned()+len(viewModel.Rows), out.n)
+	s.emit(EventViewRendered, userName, q.Table, map[string]string{
+		"view":        viewSignature,
+		"rows":        strconv.Itoa(tableView.GetFilteredRowCount()),
+		"duration_ms": strconv.FormatInt(time.Since(timing.start).Milliseconds(), 10),
+	})
+	if !models.IsSystemTable(q.Table) {
+		s.usage.Record(q.Table, viewSignature, userName, time.Since(timing.start), time.Now())
+	}
 	return nil
 }
 
+// flusher is implemented by writers that can send buffered output to the client,
+// such as http.ResponseWriter.
+type flusher interface {
+	Flush()
+}
+
+// streamsAggregates reports whether the aggregates of the grouped view requested by q are
+// streamed after the page: the view is large, no group is sorted by an aggregate and the
+// response can be flushed.
+func (s *Server) streamsAggregates(w io.Writer, tableView *tables.TableView, q *query.Query) bool {
+	if s.progressiveAggregateMinRows <= 0 || len(q.GroupAggregateSorts) > 0 || !s.renderer.StreamsAggregates() {
+		return false
+	}
+	if _, ok := w.(flusher); !ok {
+		return false
+	}
+	return tableView.GetFilteredRowCount() >= s.progressiveAggregateMinRows
+}
+
+// streamAggregates flushes the page rendered with pending aggregates, then computes the
+// aggregates in parallel and streams them into the page as each top-level group completes.
+// The page has already been sent, so errors are logged rather than returned.
+func (s *Server) streamAggregates(w io.Writer, vm views.TableViewModel, tableView *tables.TableView) {
+	f := w.(flusher)
+	f.Flush()
+	filler := views.NewAggregateFiller(vm, tableView)
+	var renderErr error
+	tableView.ComputeAggregatesProgressively(runtime.NumCPU(), func(group *grouping.Group) {
+		if renderErr != nil {
+			return
+		}
+		if fills := filler.Fills(group); len(fills) > 0 {
+			renderErr = s.renderer.RenderAggregateFills(w, fills)
+			f.Flush()
+		}
+	})
+	if renderErr == nil {
+		renderErr = s.renderer.RenderTail(w)
+	}
+	if renderErr != nil {
+		log.Printf("Aggregate streaming error: %v", renderErr)
+	}
+}
+
 // HandleLandingRequest processes the landing page request
 func (s *Server) HandleLandingRequest(w io.Writer, requestURL *url.URL, product ProductConfig, setHeader func(key, value string)) error {
 	setHeader("Content-Type", "text/html; charset=utf-8")
@@ -372,8 +1044,9 @@ func (s *Server) HandleLandingRequest(w io.Writer, requestURL *url.URL, product
 
 	// Create a copy of the landing view model to filter tables
 	vm := views.LandingViewModel{
-		Title:    product.GetTitle(),
-		Subtitle: product.GetSubtitle(),
+		PageLocale: s.pageLocale(userName),
+		Title:      product.GetTitle(),
+		Subtitle:   product.GetSubtitle(),
 	}
 
 	// If we have a user store and a user parameter, filter tables by domain
@@ -404,6 +1077,32 @@ func (s *Server) HandleLandingRequest(w io.Writer, requestURL *url.URL, product
 	return nil
 }
 
+// defaultView returns the view a table opens with: the product's default columns if
+// defined, otherwise the table's configured default view, otherwise its first few columns
+// in the server's column order.
+func (s *Server) defaultView(product ProductConfig, tableName string, table *tables.DataTable) *query.DefaultView {
+	if columns := product.GetDefaultColumns(tableName); len(columns) > 0 {
+		return &query.DefaultView{Columns: columns}
+	}
+	if s.defaultViewResolver != nil {
+		if view := s.defaultViewResolver(tableName); view != nil && len(view.Columns) > 0 {
+			return view
+		}
+	}
+	allCols := table.GetColumnNames()
+	if s.columnOrder != nil {
+		primaryKeyEntityType := ""
+		if s.primaryKeyResolver != nil {
+			primaryKeyEntityType = s.primaryKeyResolver(tableName)
+		}
+		allCols = views.OrderColumns(table, primaryKeyEntityType, s.columnOrder)
+	}
+	if len(allCols) > DefaultColumnCount {
+		allCols = allCols[:DefaultColumnCount]
+	}
+	return &query.DefaultView{Columns: allCols}
+}
+
 // updateComputedColumns manages computed columns with caching.
 // Only recompiles expressions and recreates columns when something has changed.
 // The cacheKey is user+table specific to ensure users have isolated computed columns.
@@ -467,6 +1166,48 @@ func (s *Server) updateComputedColumns(tableView *tables.TableView, q *query.Que
 	return errors
 }
 
+// columnGetter returns a column getter for expressions that retrieves values from the table view.
+func columnGetter(tableView *tables.TableView) expr.ColumnGetter {
+	return func(colName string, rowIndex uint32) (expr.Value, error) {
+		col := tableView.GetColumn(colName)
+		if col == nil {
+			return expr.NilValue(), errs.New(errs.ErrUnknownColumn, "column '%s' not found", colName)
+		}
+
+		return expr.ColumnValue(col, rowIndex)
+	}
+}
+
+// updateGroupLabels sets the label expressions of the grouped columns on the table view,
+// using cached compiled expressions. Returns the errors of expressions that fail to compile
+// or use unknown columns (columnName -> error); their groups show the grouped values.
+func (s *Server) updateGroupLabels(tableView *tables.TableView, q *query.Query) map[string]string {
+	errors := make(map[string]string)
+	labels := make(map[string]tables.GroupLabelFunc)
+	for colName, expression := range q.GroupLabels {
+		if !slices.Contains(q.GroupedColumns, colName) {
+			continue
+		}
+		compiled, ok := s.exprCache[expression]
+		if !ok {
+			var err error
+			compiled, err = expr.Compile(expression)
+			if err != nil {
+				errors[colName] = fmt.Sprintf("syntax error: %v", err)
+				continue
+			}
+			s.exprCache[expression] = compiled
+		}
+		if i := slices.IndexFunc(compiled.Columns(), func(name string) bool { return tableView.GetColumn(name) == nil }); i >= 0 {
+			errors[colName] = fmt.Sprintf("column '%s' not found", compiled.Columns()[i])
+			continue
+		}
+		labels[colName] = compiled.Bind(columnGetter(tableView)).EvalString
+	}
+	tableView.SetGroupLabels(labels)
+	return errors
+}
+
 // createComputedColumn creates a single computed column, using cached compiled expressions.
 // Returns an error if the expression fails to compile or evaluate.
 func (s *Server) createComputedColumn(tableView *tables.TableView, name, expression string) error {
@@ -486,6 +1227,7 @@ func (s *Server) createComputedColumn(tableView *tables.TableView, name, express
 		}
 		s.exprCache[expression] = compiled
 	}
+	tableView.SetComputedColumnLineage(name, compiled.Columns())
 
 	// Get a reference column to determine length
 	var length int
@@ -500,69 +1242,8 @@ func (s *Server) createComputedColumn(tableView *tables.TableView, name, express
 		return nil
 	}
 
-	// Create a column getter function that retrieves values from the table view
-	getColumn := func(colName string, rowIndex uint32) (expr.Value, error) {
-		col := tableView.GetColumn(colName)
-		if col == nil {
-			return expr.NilValue(), fmt.Errorf("column '%s' not found", colName)
-		}
-
-		// Handle datetime columns - return datetime type for type-aware operations
-		switch dtCol := col.(type) {
-		case *columns.DatetimeColumn:
-			t, err := dtCol.GetValue(rowIndex)
-			if err != nil {
-				return expr.NilValue(), err
-			}
-			return expr.NewDatetime(t.UnixNano()), nil
-		case *columns.JoinedDatetimeColumn:
-			t, err := dtCol.GetValue(rowIndex)
-			if err != nil {
-				return expr.NilValue(), err
-			}
-			return expr.NewDatetime(t.UnixNano()), nil
-		case *columns.ComputedDatetimeColumn:
-			nanos, err := dtCol.GetValue(rowIndex)
-			if err != nil {
-				return expr.NilValue(), err
-			}
-			return expr.NewDatetime(nanos), nil
-		case *columns.DurationColumn:
-			nanos, err := dtCol.Nanoseconds(rowIndex)
-			if err != nil {
-				return expr.NilValue(), err
-			}
-			return expr.NewDuration(nanos), nil
-		case *columns.JoinedDurationColumn:
-			nanos, err := dtCol.Nanoseconds(rowIndex)
-			if err != nil {
-				return expr.NilValue(), err
-			}
-			return expr.NewDuration(nanos), nil
-		case *columns.ComputedDurationColumn:
-			nanos, err := dtCol.Nanoseconds(rowIndex)
-			if err != nil {
-				return expr.NilValue(), err
-			}
-			return expr.NewDuration(nanos), nil
-		}
-
-		strVal, err := col.GetString(rowIndex)
-		if err != nil {
-			return expr.NilValue(), err
-		}
-		// Try to parse as int first, then float
-		if intVal, err := strconv.ParseInt(strVal, 10, 64); err == nil {
-			return expr.NewInt(intVal), nil
-		}
-		if numVal, err := strconv.ParseFloat(strVal, 64); err == nil {
-			return expr.NewFloat(numVal), nil
-		}
-		return expr.NewString(strVal), nil
-	}
-
-	// Bind the expression to the column getter
-	bound := compiled.Bind(getColumn)
+	// Bind the expression to the columns of the table view
+	bound := compiled.Bind(columnGetter(tableView))
 
 	// Create the computed column definition
 	colDef := columns.NewColumnDef(name, name, "")
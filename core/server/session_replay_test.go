@@ -0,0 +1,74 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestSessionRecordingAndReplay(t *testing.T) {
+	srv := testsupport.NewServer(t)
+
+	// Not recording: the page offers to start a session carried in the URL
+	resp := srv.GetTable(t, "orders", "columns=order_id,region")
+	resp.AssertStatus(t, http.StatusOK)
+	if !regexp.MustCompile(`href="[^"]*session=[0-9a-f]{16}[^"]*"[^>]*>● Record session`).MatchString(resp.Body) {
+		t.Error("expected a link starting a session recording")
+	}
+
+	srv.GetTable(t, "orders", "columns=order_id,region&session=s1").AssertStatus(t, http.StatusOK)
+	srv.GetTable(t, "orders", "columns=order_id,region&session=s1").AssertStatus(t, http.StatusOK)
+	resp = srv.GetTable(t, "orders", "columns=order_id,region,status&grouped=region&filter:status=shipped&session=s1")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, "Recording session · 2 steps")
+	// Links of the page keep recording
+	resp.AssertContains(t, "session=s1")
+
+	resp = srv.Get(t, "/test/session?id=s1")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := strings.Count(resp.Body, "<tr data-step="); got != 2 {
+		t.Errorf("expected 2 recorded steps, got %d", got)
+	}
+	resp.AssertContains(t, "grouped by region; filtered on status=shipped")
+	resp.AssertNotContains(t, "session=s1")
+	resp.AssertContains(t, `href="table?columns=order_id%2Cregion&amp;table=orders&amp;replay=s1.1"`)
+
+	resp = srv.Get(t, "/test/session?id=s1&format=text")
+	resp.AssertStatus(t, http.StatusOK)
+	want := "/test/table?columns=order_id%2Cregion&table=orders\n" +
+		"/test/table?columns=order_id%2Cregion%2Cstatus&filter%3Astatus=shipped&grouped=region&table=orders\n"
+	if resp.Body != want {
+		t.Errorf("text export = %q, want %q", resp.Body, want)
+	}
+
+	// Replaying a step navigates between the recorded steps without recording
+	resp = srv.GetTable(t, "orders", "columns=order_id,region&replay=s1.1")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, "Replaying step <strong>1</strong> of 2")
+	resp.AssertContains(t, `replay=s1.2">Next ›`)
+	resp.AssertNotContains(t, "‹ Previous")
+
+	srv.Get(t, "/test/session?id=unknown").AssertStatus(t, http.StatusNotFound)
+	srv.Get(t, "/test/session?id=s1&format=pdf").AssertStatus(t, http.StatusBadRequest)
+}
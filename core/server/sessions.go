@@ -0,0 +1,299 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/safehtml"
+	"github.com/google/taxinomia/core/query"
+	"github.com/google/taxinomia/core/views"
+)
+
+// Limits of the session recorder
+const (
+	// MaxSessions is the number of sessions kept; the least recently updated session
+	// is dropped when a new one starts
+	MaxSessions = 100
+	// MaxSessionSteps is the number of steps recorded per session; later steps are
+	// counted but not recorded
+	MaxSessionSteps = 200
+)
+
+// SessionStep is a query state a user walked through in a recorded session
+type SessionStep struct {
+	Table string
+	Query string // Raw query of the table request, without the session parameter
+	At    time.Time
+}
+
+// Session is the recorded sequence of query states of one session
+type Session struct {
+	ID      string
+	Steps   []SessionStep
+	Dropped int // Steps beyond MaxSessionSteps that were not recorded
+	Updated time.Time
+}
+
+// SessionRecorder records the query states of sessions started by users.
+// Sessions are identified by random IDs carried in the session URL parameter and
+// are only kept in memory. It is safe for concurrent use.
+type SessionRecorder struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewSessionRecorder creates an empty session recorder.
+func NewSessionRecorder() *SessionRecorder {
+	return &SessionRecorder{sessions: make(map[string]*Session)}
+}
+
+// NewSessionID returns a random session ID that is hard to guess, as a recording
+// reveals the filter values of the session.
+func NewSessionID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Record appends a step to a session, creating the session on its first step.
+// A step repeating the previous one (e.g., a reload) is not recorded.
+func (sr *SessionRecorder) Record(id string, step SessionStep) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	session := sr.sessions[id]
+	if session == nil {
+		if len(sr.sessions) >= MaxSessions {
+			sr.evictOldest()
+		}
+		session = &Session{ID: id}
+		sr.sessions[id] = session
+	}
+	session.Updated = step.At
+	if n := len(session.Steps); n > 0 && session.Steps[n-1].Query == step.Query {
+		return
+	}
+	if len(session.Steps) >= MaxSessionSteps {
+		session.Dropped++
+		return
+	}
+	session.Steps = append(session.Steps, step)
+}
+
+// Get returns a copy of a recorded session.
+func (sr *SessionRecorder) Get(id string) (Session, bool) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	session := sr.sessions[id]
+	if session == nil {
+		return Session{}, false
+	}
+	result := *session
+	result.Steps = append([]SessionStep(nil), session.Steps...)
+	return result, true
+}
+
+// evictOldest drops the least recently updated session. Must be called with mu held.
+func (sr *SessionRecorder) evictOldest() {
+	var oldest *Session
+	for _, session := range sr.sessions {
+		if oldest == nil || session.Updated.Before(oldest.Updated) {
+			oldest = session
+		}
+	}
+	if oldest != nil {
+		delete(sr.sessions, oldest.ID)
+	}
+}
+
+// validSessionID returns true if id can name a session: up to 64 letters, digits,
+// dashes or underscores. Other values of the session parameter are ignored.
+func validSessionID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// sessionURL returns the URL of the page listing the steps of a session, relative to
+// the product pages
+func sessionURL(id string) string {
+	return "session?id=" + url.QueryEscape(id)
+}
+
+// stepURL returns the URL of a recorded step relative to the product pages
+func stepURL(step SessionStep) string {
+	return "table?" + step.Query
+}
+
+// replayURL returns the URL of step n (1-based) of a session with replay navigation
+func replayURL(id string, step SessionStep, n int) string {
+	return stepURL(step) + "&replay=" + url.QueryEscape(id+"."+strconv.Itoa(n))
+}
+
+// parseReplay parses the replay parameter (format: sessionID.step)
+func parseReplay(value string) (id string, n int, ok bool) {
+	i := strings.LastIndex(value, ".")
+	if i < 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(value[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return value[:i], n, true
+}
+
+// setSessionState records the view in its session, if recording, and sets the
+// recording controls and replay navigation of the view model
+func (s *Server) setSessionState(vm *views.TableViewModel, q *query.Query, requestURL *url.URL) {
+	params := requestURL.Query()
+	replay := params.Get("replay")
+	params.Del("session")
+	params.Del("replay")
+
+	if validSessionID(q.Session) {
+		s.sessions.Record(q.Session, SessionStep{Table: q.Table, Query: params.Encode(), At: time.Now()})
+		session, _ := s.sessions.Get(q.Session)
+		vm.SessionID = q.Session
+		vm.SessionSteps = len(session.Steps)
+		vm.SessionStopURL = q.WithSession("")
+		vm.SessionURL = safehtml.URLSanitized(sessionURL(q.Session))
+	} else {
+		vm.SessionStartURL = q.WithSession(NewSessionID())
+	}
+
+	id, n, ok := parseReplay(replay)
+	if !ok {
+		return
+	}
+	session, ok := s.sessions.Get(id)
+	if !ok || n < 1 || n > len(session.Steps) {
+		return
+	}
+	vm.ReplayStep = n
+	vm.ReplaySteps = len(session.Steps)
+	if n > 1 {
+		vm.ReplayPrevURL = safehtml.URLSanitized(replayURL(id, session.Steps[n-2], n-1))
+	}
+	if n < len(session.Steps) {
+		vm.ReplayNextURL = safehtml.URLSanitized(replayURL(id, session.Steps[n], n+1))
+	}
+	if vm.SessionID == "" {
+		vm.SessionURL = safehtml.URLSanitized(sessionURL(id))
+	}
+}
+
+// HandleSessionRequest serves the steps of a recorded session as a page of links, or
+// with format=text as a plain text list of links, one per line
+func (s *Server) HandleSessionRequest(w io.Writer, requestURL *url.URL, product ProductConfig, setHeader func(key, value string)) *TableHandlerResult {
+	params := requestURL.Query()
+	id := params.Get("id")
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		return &TableHandlerResult{StatusCode: 404, Message: fmt.Sprintf("Session '%s' not found", id)}
+	}
+
+	format := params.Get("format")
+	switch format {
+	case "text":
+		// Links are absolute paths so that they can be pasted anywhere on the same host
+		base := path.Dir(requestURL.Path)
+		setHeader("Content-Type", "text/plain; charset=utf-8")
+		setHeader("Content-Disposition", fmt.Sprintf(`attachment; filename="session-%s.txt"`, session.ID))
+		for _, step := range session.Steps {
+			if _, err := fmt.Fprintf(w, "%s/%s\n", base, stepURL(step)); err != nil {
+				return errorResult(err)
+			}
+		}
+		return nil
+	case "":
+	default:
+		return &TableHandlerResult{StatusCode: 400, Message: fmt.Sprintf("Unknown session format '%s'", format)}
+	}
+
+	vm := views.SessionViewModel{
+		PageLocale: s.pageLocale(params.Get("user")),
+		Title:      product.GetTitle(),
+		Subtitle:   product.GetSubtitle(),
+		SessionID:  session.ID,
+		Dropped:    session.Dropped,
+		TextURL:    sessionURL(session.ID) + "&format=text",
+	}
+	for i, step := range session.Steps {
+		vm.Steps = append(vm.Steps, views.SessionStepInfo{
+			Number:      i + 1,
+			Table:       step.Table,
+			Description: describeStep(step),
+			At:          step.At.Format(time.TimeOnly),
+			URL:         stepURL(step),
+			ReplayURL:   replayURL(session.ID, step, i+1),
+		})
+	}
+	setHeader("Content-Type", "text/html; charset=utf-8")
+	if err := s.renderer.RenderSession(w, vm); err != nil {
+		log.Printf("Session page rendering error: %v", err)
+		return errorResult(err)
+	}
+	return nil
+}
+
+// describeStep summarizes the grouping, filters and sorting of a recorded step
+func describeStep(step SessionStep) string {
+	u := &url.URL{RawQuery: step.Query}
+	q := query.NewQuery(u)
+
+	var parts []string
+	if len(q.GroupedColumns) > 0 {
+		parts = append(parts, "grouped by "+strings.Join(q.GroupedColumns, ", "))
+	}
+	if len(q.Filters) > 0 {
+		filters := make([]string, 0, len(q.Filters))
+		for col, value := range q.Filters {
+			filters = append(filters, col+"="+value)
+		}
+		sort.Strings(filters)
+		parts = append(parts, "filtered on "+strings.Join(filters, ", "))
+	}
+	if sortParam := u.Query().Get("sort"); sortParam != "" {
+		parts = append(parts, "sorted by "+strings.ReplaceAll(sortParam, ",", ", "))
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%d columns", len(q.Columns))
+	}
+	return strings.Join(parts, "; ")
+}
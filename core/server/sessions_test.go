@@ -0,0 +1,84 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSessionRecorderSkipsRepeatedSteps(t *testing.T) {
+	sr := NewSessionRecorder()
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	for _, q := range []string{"table=orders", "table=orders", "grouped=region&table=orders", "table=orders"} {
+		sr.Record("s1", SessionStep{Table: "orders", Query: q, At: at})
+	}
+
+	session, ok := sr.Get("s1")
+	if !ok {
+		t.Fatal("session s1 not recorded")
+	}
+	var got []string
+	for _, step := range session.Steps {
+		got = append(got, step.Query)
+	}
+	want := []string{"table=orders", "grouped=region&table=orders", "table=orders"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("steps = %v, want %v", got, want)
+	}
+	if _, ok := sr.Get("s2"); ok {
+		t.Error("unknown session should not be found")
+	}
+}
+
+func TestSessionRecorderLimits(t *testing.T) {
+	sr := NewSessionRecorder()
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < MaxSessionSteps+3; i++ {
+		sr.Record("long", SessionStep{Table: "orders", Query: fmt.Sprintf("limit=%d", i), At: at})
+	}
+	session, _ := sr.Get("long")
+	if len(session.Steps) != MaxSessionSteps || session.Dropped != 3 {
+		t.Errorf("got %d steps and %d dropped, want %d and 3", len(session.Steps), session.Dropped, MaxSessionSteps)
+	}
+
+	// The least recently updated session is dropped first
+	for i := 1; i < MaxSessions; i++ {
+		sr.Record(fmt.Sprintf("s%d", i), SessionStep{Table: "orders", Query: "table=orders", At: at.Add(time.Duration(i) * time.Second)})
+	}
+	sr.Record("new", SessionStep{Table: "orders", Query: "table=orders", At: at.Add(time.Hour)})
+	if _, ok := sr.Get("long"); ok {
+		t.Error("expected the oldest session to be evicted")
+	}
+	if _, ok := sr.Get("s1"); !ok {
+		t.Error("expected newer sessions to be kept")
+	}
+}
+
+func TestDescribeStep(t *testing.T) {
+	step := SessionStep{Query: "columns=region,status,amount&filter:status=shipped&grouped=region&sort=-amount&table=orders"}
+	want := "grouped by region; filtered on status=shipped; sorted by -amount"
+	if got := describeStep(step); got != want {
+		t.Errorf("describeStep = %q, want %q", got, want)
+	}
+	if got := describeStep(SessionStep{Query: "columns=region,amount&table=orders"}); got != "2 columns" {
+		t.Errorf("describeStep of a plain view = %q, want %q", got, "2 columns")
+	}
+}
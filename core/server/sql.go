@@ -0,0 +1,96 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/google/taxinomia/core/sqlquery"
+)
+
+// SQLResolver runs a SQL statement on a table that is not in the data model, e.g. at
+// the source of a table that is too large to load (see datasources.Manager.Query)
+type SQLResolver func(sql string) (*sqlquery.Result, error)
+
+// SetSQLResolver sets the function that runs SQL statements on tables that are not in
+// the data model
+func (s *Server) SetSQLResolver(resolver SQLResolver) {
+	s.sqlResolver = resolver
+}
+
+// SQLResult is the response of the SQL endpoint
+type SQLResult struct {
+	Columns    []SQLColumn `json:"columns"`
+	Rows       [][]any     `json:"rows"`
+	PushedDown bool        `json:"pushed_down,omitempty"` // Whether the rows were computed at the source of the table
+}
+
+// SQLColumn is a column of a SQLResult
+type SQLColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// HandleSQLRequest runs a SQL statement (q=...) and writes its result as JSON. The
+// statement is run on the table of the data model it names, or with the SQL resolver if
+// the data model has no such table. See package sqlquery for the supported statements.
+// Rows read at the source of a table are not counted in the cost of the query.
+func (s *Server) HandleSQLRequest(w io.Writer, requestURL *url.URL, product ProductConfig, setHeader func(key, value string)) *TableHandlerResult {
+	start := time.Now()
+	sql := requestURL.Query().Get("q")
+	if sql == "" {
+		return &TableHandlerResult{StatusCode: 400, Message: "Q parameter is required"}
+	}
+	stmt, err := sqlquery.Parse(sql)
+	if err != nil {
+		return errorResult(err)
+	}
+
+	var result *sqlquery.Result
+	if s.dataModel.GetTable(stmt.Table) != nil || s.sqlResolver == nil {
+		plan, err := sqlquery.Resolve(s.dataModel, stmt)
+		if err != nil {
+			return errorResult(err)
+		}
+		result, err = plan.Execute()
+		if err != nil {
+			return errorResult(err)
+		}
+	} else if result, err = s.sqlResolver(sql); err != nil {
+		return errorResult(err)
+	}
+
+	response := SQLResult{Rows: result.Rows, PushedDown: result.PushedDown}
+	for _, col := range result.Columns {
+		response.Columns = append(response.Columns, SQLColumn{Name: col.Name, Type: col.Kind.String()})
+	}
+	if response.Rows == nil {
+		response.Rows = [][]any{}
+	}
+	setHeader("Content-Type", "application/json")
+	out := &countingWriter{w: w}
+	if err := json.NewEncoder(out).Encode(response); err != nil {
+		return errorResult(err)
+	}
+	s.recordQueryCost(product, requestURL.Query().Get("user"), start, result.RowsScanned, out.n)
+	return nil
+}
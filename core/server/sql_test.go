@@ -0,0 +1,101 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/models"
+	"github.com/google/taxinomia/core/sqlquery"
+	"github.com/google/taxinomia/core/tables"
+)
+
+func TestHandleSQLRequest(t *testing.T) {
+	orders := tables.NewDataTable()
+	region := columns.NewStringColumn(columns.NewColumnDef("region", "Region", ""))
+	for _, v := range []string{"north", "south", "north"} {
+		region.Append(v)
+	}
+	region.FinalizeColumn()
+	orders.AddColumn(region)
+	dm := models.NewDataModel()
+	dm.AddTable("orders", orders)
+	s, err := NewServer(dm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := func(sql string) (SQLResult, *TableHandlerResult) {
+		var buf bytes.Buffer
+		u := &url.URL{Path: "/p/sql", RawQuery: url.Values{"q": {sql}}.Encode()}
+		result := s.HandleSQLRequest(&buf, u, nil, func(string, string) {})
+		var response SQLResult
+		if result == nil {
+			if err := json.Unmarshal(buf.Bytes(), &response); err != nil {
+				t.Fatalf("%s: %v", sql, err)
+			}
+		}
+		return response, result
+	}
+
+	// Tables of the data model are queried locally
+	got, result := query("SELECT region, COUNT(*) AS n FROM orders GROUP BY region ORDER BY n DESC")
+	want := SQLResult{
+		Columns: []SQLColumn{{Name: "region", Type: "VARCHAR"}, {Name: "n", Type: "BIGINT"}},
+		Rows:    [][]any{{"north", 2.0}, {"south", 1.0}},
+	}
+	if result != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v (%+v), want %+v", got, result, want)
+	}
+	if cost, ok := s.QueryCosts().Cost("", ""); !ok || cost.Queries != 1 || cost.RowsScanned != 3 || cost.BytesRendered == 0 {
+		t.Errorf("cost = %+v, want 1 query grouping 3 rows", cost)
+	}
+	if _, result := query("SELECT * FROM husks"); result == nil || result.StatusCode != 404 {
+		t.Errorf("unknown table without resolver: got %+v", result)
+	}
+
+	// Other tables are queried with the resolver
+	s.SetSQLResolver(func(sql string) (*sqlquery.Result, error) {
+		stmt, err := sqlquery.Parse(sql)
+		if err != nil || stmt.Table != "husks" {
+			return nil, errs.New(errs.ErrUnknownTable, "Table '%s' not found", stmt.Table)
+		}
+		return &sqlquery.Result{Columns: []sqlquery.Column{{Name: "count(*)", Kind: sqlquery.KindInt64}}, Rows: [][]any{{int64(7)}}, PushedDown: true}, nil
+	})
+	got, result = query("SELECT COUNT(*) FROM husks")
+	if result != nil || !got.PushedDown || !reflect.DeepEqual(got.Rows, [][]any{{7.0}}) {
+		t.Errorf("resolved query: got %+v (%+v)", got, result)
+	}
+	for sql, status := range map[string]int{
+		"":                            400,
+		"SELECT FROM orders":          400,
+		"SELECT missing FROM orders":  400,
+		"SELECT COUNT(*) FROM others": 404,
+	} {
+		if _, result := query(sql); result == nil || result.StatusCode != status {
+			t.Errorf("%q: got %+v, want status %d", sql, result, status)
+		}
+	}
+}
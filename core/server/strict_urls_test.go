@@ -0,0 +1,43 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestStrictURLs(t *testing.T) {
+	srv := testsupport.NewServer(t)
+
+	// Unknown parameters are ignored unless the request is strict
+	srv.GetTable(t, "orders", "columns=order_id&fitler:status=shipped").AssertStatus(t, http.StatusOK)
+	resp := srv.GetTable(t, "orders", "columns=order_id&fitler:status=shipped&strict=1")
+	resp.AssertStatus(t, http.StatusBadRequest)
+	resp.AssertContains(t, "fitler:status (did you mean filter:status?)")
+
+	srv.SetStrictURLs(true)
+	resp = srv.GetTable(t, "orders", "colums=order_id")
+	resp.AssertStatus(t, http.StatusBadRequest)
+	resp.AssertContains(t, "colums (did you mean columns?)")
+	srv.GetTable(t, "orders", "columns=order_id&filter:status=shipped").AssertStatus(t, http.StatusOK)
+	srv.GetTable(t, "orders", "colums=order_id&strict=0").AssertStatus(t, http.StatusOK)
+}
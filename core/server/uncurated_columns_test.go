@@ -0,0 +1,38 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestUncuratedColumnBadge(t *testing.T) {
+	dm := testsupport.NewDataModel()
+	dm.GetTable("orders").GetColumn("status").ColumnDef().SetUncurated(true)
+	srv := testsupport.NewServerWithDataModel(t, dm)
+
+	resp := srv.GetTable(t, "orders", "columns=order_id,status")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("span", "class", "uncurated-badge"); len(got) != 1 {
+		t.Errorf("expected one uncurated badge in the column picker, got %q", got)
+	}
+}
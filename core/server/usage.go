@@ -0,0 +1,143 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/taxinomia/core/models"
+	"github.com/google/taxinomia/core/query"
+)
+
+// MinReportedUsers is the smallest distinct user count reported exactly in the
+// _usage table. Smaller counts are reported as "<MinReportedUsers" so that the
+// users behind a rarely used table or view cannot be singled out.
+const MinReportedUsers = 3
+
+type usageKey struct {
+	table string
+	view  string // Empty for the table scope
+}
+
+type usageEntry struct {
+	accesses   uint32
+	lastAccess time.Time
+	slowest    time.Duration // Slowest access on the day of slowestAt
+	slowestAt  time.Time
+	users      map[[sha256.Size]byte]struct{}
+}
+
+// UsageStats records per-table and per-view access counts, last access times and the
+// slowest access of the day.
+// User names are never stored: they are hashed with a per-process random salt and
+// only the number of distinct hashes is reported.
+// It is safe for concurrent use.
+type UsageStats struct {
+	mu      sync.Mutex
+	salt    []byte
+	entries map[usageKey]*usageEntry
+}
+
+// NewUsageStats creates an empty usage statistics recorder.
+func NewUsageStats() *UsageStats {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	return &UsageStats{salt: salt, entries: make(map[usageKey]*usageEntry)}
+}
+
+// ViewSignature describes the view of a query by its columns and grouping.
+// Filters are left out as their values may identify what a user looked at.
+func ViewSignature(q *query.Query) string {
+	signature := "columns=" + strings.Join(q.Columns, ",")
+	if len(q.GroupedColumns) > 0 {
+		signature += "&grouped=" + strings.Join(q.GroupedColumns, ",")
+	}
+	return signature
+}
+
+// Record records an access of a view of a table that took duration to serve. An empty
+// user is counted as an access but not as a distinct user.
+func (us *UsageStats) Record(table, view, user string, duration time.Duration, at time.Time) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	var hash [sha256.Size]byte
+	if user != "" {
+		hash = sha256.Sum256(append(append([]byte{}, us.salt...), user...))
+	}
+	for _, key := range []usageKey{{table: table}, {table: table, view: view}} {
+		entry := us.entries[key]
+		if entry == nil {
+			entry = &usageEntry{users: make(map[[sha256.Size]byte]struct{})}
+			us.entries[key] = entry
+		}
+		entry.accesses++
+		if at.After(entry.lastAccess) {
+			entry.lastAccess = at
+		}
+		// The slowest access is kept per day, starting over with the first access of a day
+		if day := at.Format(time.DateOnly); day > entry.slowestAt.Format(time.DateOnly) ||
+			(day == entry.slowestAt.Format(time.DateOnly) && duration > entry.slowest) {
+			entry.slowest = duration
+			entry.slowestAt = at
+		}
+		if user != "" {
+			entry.users[hash] = struct{}{}
+		}
+	}
+}
+
+// Records returns the aggregated usage of every accessed table and view.
+func (us *UsageStats) Records() []models.UsageRecord {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	records := make([]models.UsageRecord, 0, len(us.entries))
+	for key, entry := range us.entries {
+		scope := models.UsageScopeView
+		if key.view == "" {
+			scope = models.UsageScopeTable
+		}
+		records = append(records, models.UsageRecord{
+			Table:         key.table,
+			Scope:         scope,
+			View:          key.view,
+			Accesses:      entry.accesses,
+			DistinctUsers: reportedUsers(len(entry.users)),
+			LastAccess:    entry.lastAccess,
+			Slowest:       entry.slowest,
+			SlowestAt:     entry.slowestAt,
+		})
+	}
+	return records
+}
+
+// reportedUsers formats a distinct user count, hiding counts below MinReportedUsers.
+func reportedUsers(n int) string {
+	if n > 0 && n < MinReportedUsers {
+		return fmt.Sprintf("<%d", MinReportedUsers)
+	}
+	return strconv.Itoa(n)
+}
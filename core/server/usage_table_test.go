@@ -0,0 +1,57 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestUsageTable(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	srv.GetTable(t, "orders", "columns=order_id,amount&user=alice").AssertStatus(t, http.StatusOK)
+	srv.GetTable(t, "orders", "columns=order_id,amount&user=bob").AssertStatus(t, http.StatusOK)
+	srv.GetTable(t, "orders", "columns=order_id,status&user=carol").AssertStatus(t, http.StatusOK)
+
+	resp := srv.GetTable(t, "_usage", "columns=table_name,scope,view,accesses,distinct_users")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertNotContains(t, "alice")
+
+	tableNames := resp.Elements("td", "data-cell-column", "table_name")
+	accesses := resp.Elements("td", "data-cell-column", "accesses")
+	users := resp.Elements("td", "data-cell-column", "distinct_users")
+	viewNames := resp.Elements("td", "data-cell-column", "view")
+	want := map[string][2]string{
+		"orders|":                        {"3", "3"},
+		"orders|columns=order_id,amount": {"2", "<3"},
+		"orders|columns=order_id,status": {"1", "<3"},
+		"regions|":                       {"0", "0"},
+	}
+	if len(tableNames) != len(want) {
+		t.Fatalf("expected %d usage rows, got tables %q views %q", len(want), tableNames, viewNames)
+	}
+	for i := range tableNames {
+		key := tableNames[i] + "|" + viewNames[i]
+		if got := [2]string{accesses[i], users[i]}; got != want[key] {
+			t.Errorf("usage of %s = %v, want %v", key, got, want[key])
+		}
+	}
+}
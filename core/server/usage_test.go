@@ -0,0 +1,95 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/taxinomia/core/models"
+	"github.com/google/taxinomia/core/query"
+)
+
+func TestUsageStatsAggregatesTablesAndViews(t *testing.T) {
+	us := NewUsageStats()
+	day := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	us.Record("orders", "columns=a", "alice", time.Millisecond, day)
+	us.Record("orders", "columns=a", "bob", time.Millisecond, day.Add(time.Hour))
+	us.Record("orders", "columns=b", "alice", time.Millisecond, day.Add(2*time.Hour))
+	us.Record("orders", "columns=b", "carol", time.Millisecond, day.Add(3*time.Hour))
+	us.Record("orders", "columns=b", "", time.Millisecond, day.Add(4*time.Hour))
+
+	byView := make(map[string]models.UsageRecord)
+	for _, r := range us.Records() {
+		byView[r.Scope+":"+r.View] = r
+	}
+	if len(byView) != 3 {
+		t.Fatalf("expected one table and two view records, got %+v", byView)
+	}
+
+	table := byView["table:"]
+	if table.Accesses != 5 || table.DistinctUsers != "3" || !table.LastAccess.Equal(day.Add(4*time.Hour)) {
+		t.Errorf("unexpected table record %+v", table)
+	}
+	if view := byView["view:columns=a"]; view.Accesses != 2 || view.DistinctUsers != "<3" {
+		t.Errorf("expected distinct users below the threshold to be hidden, got %+v", view)
+	}
+	if view := byView["view:columns=b"]; view.Accesses != 3 || view.DistinctUsers != "<3" {
+		t.Errorf("expected anonymous accesses not to count as users, got %+v", view)
+	}
+}
+
+func TestUsageStatsKeepsSlowestAccessOfTheDay(t *testing.T) {
+	us := NewUsageStats()
+	day := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	us.Record("orders", "columns=a", "", 5*time.Millisecond, day)
+	us.Record("orders", "columns=a", "", 9*time.Millisecond, day.Add(time.Hour))
+	us.Record("orders", "columns=a", "", 2*time.Millisecond, day.Add(2*time.Hour))
+	for _, r := range us.Records() {
+		if r.Slowest != 9*time.Millisecond || !r.SlowestAt.Equal(day.Add(time.Hour)) {
+			t.Errorf("expected the 9ms access to be the slowest, got %+v", r)
+		}
+	}
+
+	// The first access of a new day starts over
+	us.Record("orders", "columns=a", "", time.Millisecond, day.Add(24*time.Hour))
+	for _, r := range us.Records() {
+		if r.Slowest != time.Millisecond {
+			t.Errorf("expected the slowest access to reset on a new day, got %+v", r)
+		}
+	}
+}
+
+func TestUsageStatsDoesNotKeepUserNames(t *testing.T) {
+	us := NewUsageStats()
+	us.Record("orders", "columns=a", "alice", time.Millisecond, time.Now())
+	for _, r := range us.Records() {
+		if r.DistinctUsers == "alice" || r.View == "alice" {
+			t.Errorf("user name leaked into %+v", r)
+		}
+	}
+}
+
+func TestViewSignature(t *testing.T) {
+	u, _ := url.Parse("/table?table=orders&columns=region,amount&grouped=region&filter:status=shipped")
+	if got, want := ViewSignature(query.NewQuery(u)), "columns=region,amount&grouped=region"; got != want {
+		t.Errorf("ViewSignature = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,56 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestValueLabels(t *testing.T) {
+	dm := testsupport.NewDataModel()
+	dm.GetTable("orders").GetColumn("status").ColumnDef().SetValueLabels(map[string]string{
+		"cancelled": "Cancelled by customer",
+	})
+	srv := testsupport.NewServerWithDataModel(t, dm)
+
+	// Flat cells show the label
+	resp := srv.GetTable(t, "orders", "columns=order_id,status")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Elements("td", "data-cell-column", "status"); len(got) != testsupport.OrdersRowCount || got[3] != "Cancelled by customer" || got[0] != "shipped" {
+		t.Errorf("expected labelled statuses, got %q", got)
+	}
+
+	// Filters accept both the code and the label
+	for _, filter := range []string{"cancelled", "customer"} {
+		resp = srv.GetTable(t, "orders", "columns=order_id,status&filter:status="+url.QueryEscape(filter))
+		if got := resp.Elements("td", "data-cell-column", "order_id"); len(got) != 1 || got[0] != "o4" {
+			t.Errorf("filter %q: expected order o4, got %q", filter, got)
+		}
+	}
+
+	// Group labels show the label but filter on the code
+	resp = srv.GetTable(t, "orders", "columns=status,amount&grouped=status")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, "Cancelled by customer [1]")
+	resp.AssertContains(t, `data-value="cancelled"`)
+}
@@ -0,0 +1,56 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+
+	"github.com/google/taxinomia/core/query"
+	"github.com/google/taxinomia/core/views"
+)
+
+// ViewModelContext is the request a table page's view model was built for
+type ViewModelContext struct {
+	Table string       // Table of the page
+	User  string       // Value of the user parameter (empty if none)
+	Query *query.Query // Query of the page, for building links; processors must not change it
+}
+
+// ViewModelProcessor changes the view model of a table page before it is rendered, e.g.
+// to add badges to cells, reorder columns or redact values. It is called on the request
+// path, from concurrent requests. Returning an error fails the request instead of
+// rendering the page, so that a failed redaction never shows the values it hides.
+type ViewModelProcessor func(ctx ViewModelContext, viewModel *views.TableViewModel) error
+
+// AddViewModelProcessor registers a processor of the view models of table pages.
+// Processors run in registration order, after the view model is complete. Aggregates
+// streamed after the page of large grouped views are not passed to processors.
+func (s *Server) AddViewModelProcessor(processor ViewModelProcessor) {
+	s.viewModelProcessors = append(s.viewModelProcessors, processor)
+}
+
+// processViewModel passes a view model to the registered processors
+func (s *Server) processViewModel(viewModel *views.TableViewModel, ctx ViewModelContext) error {
+	for i, processor := range s.viewModelProcessors {
+		if err := processor(ctx, viewModel); err != nil {
+			return fmt.Errorf("view model processor %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
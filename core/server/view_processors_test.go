@@ -0,0 +1,67 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/taxinomia/core/server"
+	"github.com/google/taxinomia/core/testsupport"
+	"github.com/google/taxinomia/core/views"
+)
+
+func TestViewModelProcessors(t *testing.T) {
+	srv := testsupport.NewServer(t)
+	var tables []string
+	srv.AddViewModelProcessor(func(ctx server.ViewModelContext, viewModel *views.TableViewModel) error {
+		tables = append(tables, ctx.Table+"/"+ctx.User)
+		for _, row := range viewModel.Rows {
+			row["order_id"] = "REDACTED"
+		}
+		return nil
+	})
+	// Processors run in registration order
+	srv.AddViewModelProcessor(func(ctx server.ViewModelContext, viewModel *views.TableViewModel) error {
+		if viewModel.Rows[0]["order_id"] != "REDACTED" {
+			return fmt.Errorf("expected the rows to be redacted first")
+		}
+		viewModel.Title += " (redacted)"
+		return nil
+	})
+
+	resp := srv.GetTable(t, "orders", "columns=order_id,amount&user=ann")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, "(redacted)")
+	resp.AssertContains(t, "REDACTED")
+	resp.AssertNotContains(t, ">o1<")
+	if !slices.Equal(tables, []string{"orders/ann"}) {
+		t.Errorf("expected the processors to see the table and user of the request, got %v", tables)
+	}
+
+	// A failing processor fails the request rather than rendering an unprocessed page
+	srv.AddViewModelProcessor(func(server.ViewModelContext, *views.TableViewModel) error {
+		return fmt.Errorf("redaction service unavailable")
+	})
+	resp = srv.GetTable(t, "orders", "columns=order_id,amount")
+	resp.AssertStatus(t, http.StatusInternalServerError)
+	resp.AssertNotContains(t, "REDACTED")
+}
@@ -0,0 +1,237 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import "encoding/binary"
+
+const (
+	pageSize      = 4096
+	headerSize    = 100     // Size of the database header at the start of page 1
+	sqliteVersion = 3046000 // SQLite version recorded as the last writer
+
+	// Page types
+	indexInterior = 0x02
+	tableInterior = 0x05
+	indexLeaf     = 0x0a
+	tableLeaf     = 0x0d
+
+	// Payload sizes above which cells spill to overflow pages
+	tableMaxLocal = pageSize - 35
+	indexMaxLocal = (pageSize-12)*64/255 - 23
+	minLocal      = (pageSize-12)*32/255 - 23
+)
+
+// database holds the pages of a database under construction
+type database struct {
+	pages [][]byte // pages[i] is page i+1
+}
+
+// allocate appends a page and returns its number
+func (db *database) allocate(page []byte) uint32 {
+	db.pages = append(db.pages, page)
+	return uint32(len(db.pages))
+}
+
+// tableLeafCell builds the cell of a row in a table leaf page
+func (db *database) tableLeafCell(rowid int64, record []byte) []byte {
+	cell := appendVarint(nil, uint64(len(record)))
+	cell = appendVarint(cell, uint64(rowid))
+	return append(cell, db.spill(record, tableMaxLocal)...)
+}
+
+// indexCell builds the cell of an index entry, without the child pointer of interior
+// pages. Leaf and interior index cells store their payload the same way.
+func (db *database) indexCell(record []byte) []byte {
+	cell := appendVarint(nil, uint64(len(record)))
+	return append(cell, db.spill(record, indexMaxLocal)...)
+}
+
+// spill returns the part of a payload stored in its cell. A payload larger than
+// maxLocal keeps a prefix in the cell, followed by the number of the first page of a
+// chain of overflow pages holding the rest.
+func (db *database) spill(payload []byte, maxLocal int) []byte {
+	if len(payload) <= maxLocal {
+		return payload
+	}
+	local := minLocal + (len(payload)-minLocal)%(pageSize-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	local4 := make([]byte, local+4)
+	copy(local4, payload[:local])
+	binary.BigEndian.PutUint32(local4[local:], uint32(len(db.pages)+1))
+
+	for rest := payload[local:]; len(rest) > 0; {
+		page := make([]byte, pageSize)
+		rest = rest[copy(page[4:], rest):]
+		if len(rest) > 0 {
+			binary.BigEndian.PutUint32(page, uint32(len(db.pages)+2))
+		}
+		db.allocate(page)
+	}
+	return local4
+}
+
+// treePage is a b-tree page being built
+type treePage struct {
+	cells [][]byte
+	right uint32 // Right-most child of an interior page
+	size  int    // Bytes used by the cells and their pointers
+}
+
+// fits returns whether a cell fits in a page with the given space for cells
+func (p *treePage) fits(cell []byte, space int) bool {
+	return p.size+len(cell)+2 <= space
+}
+
+func (p *treePage) add(cell []byte) {
+	p.cells = append(p.cells, cell)
+	p.size += len(cell) + 2
+}
+
+// cellSpace returns the space for cells and their pointers in a page. Pages of the
+// schema tree leave room for the database header, as the root of that tree is page 1.
+func cellSpace(pageHeader int, schema bool) int {
+	if schema {
+		return pageSize - headerSize - pageHeader
+	}
+	return pageSize - pageHeader
+}
+
+// buildTable bulk-loads a table b-tree from the cells of its rows, in rowid order
+// starting at 1, and returns its root page number. The schema table is rooted at page 1.
+func (db *database) buildTable(cells [][]byte, schema bool) uint32 {
+	space := cellSpace(8, schema)
+	pages := []*treePage{{}}
+	var separators [][]byte
+	for i, cell := range cells {
+		page := pages[len(pages)-1]
+		if len(page.cells) > 0 && !page.fits(cell, space) {
+			// Interior cells hold the largest rowid of the page to their left
+			separators = append(separators, appendVarint(nil, uint64(i)))
+			page = &treePage{}
+			pages = append(pages, page)
+		}
+		page.add(cell)
+	}
+	return db.buildTree(tableLeaf, tableInterior, pages, separators, schema)
+}
+
+// buildIndex bulk-loads an index b-tree from the cells of its entries, in key order,
+// and returns its root page number. Unlike tables, the entries separating two pages
+// move up to the parent page instead of staying in the leaves.
+func (db *database) buildIndex(cells [][]byte) uint32 {
+	space := cellSpace(8, false)
+	pages := []*treePage{{}}
+	var separators [][]byte
+	for _, cell := range cells {
+		page := pages[len(pages)-1]
+		if len(page.cells) > 0 && !page.fits(cell, space) {
+			separators = append(separators, cell)
+			pages = append(pages, &treePage{})
+			continue
+		}
+		page.add(cell)
+	}
+	// A separator taken by the last entry would leave the last leaf empty
+	if last := len(pages) - 1; last > 0 && len(pages[last].cells) == 0 {
+		prev := pages[last-1]
+		pages[last].add(separators[last-1])
+		separators[last-1] = prev.cells[len(prev.cells)-1]
+		prev.cells = prev.cells[:len(prev.cells)-1]
+	}
+	return db.buildTree(indexLeaf, indexInterior, pages, separators, false)
+}
+
+// buildTree writes the pages of a b-tree level by level, from the leaves to the root,
+// and returns the root page number. separators[i] is the key between pages i and i+1,
+// as stored in an interior cell after the child pointer.
+func (db *database) buildTree(leafType, interiorType byte, pages []*treePage, separators [][]byte, schema bool) uint32 {
+	pageType := leafType
+	for len(pages) > 1 {
+		children := make([]uint32, len(pages))
+		for i, page := range pages {
+			children[i] = db.allocate(encodePage(pageType, page, 0))
+		}
+		pages, separators = buildInteriorLevel(children, separators, cellSpace(12, schema))
+		pageType = interiorType
+	}
+	if schema {
+		db.pages[0] = encodePage(pageType, pages[0], headerSize)
+		return 1
+	}
+	return db.allocate(encodePage(pageType, pages[0], 0))
+}
+
+// buildInteriorLevel packs child pages into interior pages, and returns them with the
+// separators between them
+func buildInteriorLevel(children []uint32, separators [][]byte, space int) ([]*treePage, [][]byte) {
+	pages := []*treePage{{right: children[0]}}
+	var up [][]byte
+	for i, separator := range separators {
+		page := pages[len(pages)-1]
+		cell := interiorCell(page.right, separator)
+		if len(page.cells) > 0 && !page.fits(cell, space) {
+			up = append(up, separator)
+			pages = append(pages, &treePage{right: children[i+1]})
+			continue
+		}
+		page.add(cell)
+		page.right = children[i+1]
+	}
+	// An interior page needs a cell besides its right-most child: take one from the
+	// previous page
+	if last := len(pages) - 1; last > 0 && len(pages[last].cells) == 0 {
+		prev := pages[last-1]
+		moved := prev.cells[len(prev.cells)-1]
+		pages[last].add(interiorCell(prev.right, up[last-1]))
+		prev.cells = prev.cells[:len(prev.cells)-1]
+		prev.right = binary.BigEndian.Uint32(moved)
+		up[last-1] = moved[4:]
+	}
+	return pages, up
+}
+
+// interiorCell builds the cell of an interior page: the left child and a separator
+func interiorCell(child uint32, separator []byte) []byte {
+	cell := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(separator)), child)
+	return append(cell, separator...)
+}
+
+// encodePage lays out a b-tree page: the page header and cell pointers after offset,
+// and the cells at the end of the page
+func encodePage(pageType byte, page *treePage, offset int) []byte {
+	data := make([]byte, pageSize)
+	header := data[offset:]
+	header[0] = pageType
+	binary.BigEndian.PutUint16(header[3:], uint16(len(page.cells)))
+	pointers := 8
+	if pageType == tableInterior || pageType == indexInterior {
+		binary.BigEndian.PutUint32(header[8:], page.right)
+		pointers = 12
+	}
+	content := pageSize
+	for i, cell := range page.cells {
+		content -= len(cell)
+		copy(data[content:], cell)
+		binary.BigEndian.PutUint16(header[pointers+2*i:], uint16(content))
+	}
+	binary.BigEndian.PutUint16(header[5:], uint16(content))
+	return data
+}
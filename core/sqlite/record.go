@@ -0,0 +1,185 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// TimeFormat is the layout of times stored as text
+const TimeFormat = "2006-01-02 15:04:05.999"
+
+// normalize converts a value to the type stored in records: nil, int64, float64,
+// string or []byte
+func normalize(value any) (any, error) {
+	switch v := value.(type) {
+	case nil, int64, string, []byte:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case float64:
+		if math.IsNaN(v) {
+			return nil, nil // SQLite has no NaN; it stores NULL
+		}
+		return v, nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case time.Time:
+		return v.UTC().Format(TimeFormat), nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", value)
+}
+
+// encodeRecord encodes normalized values in the record format: a header with the
+// serial type of each value, followed by the values
+func encodeRecord(values []any) []byte {
+	var types, body []byte
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+			types = appendVarint(types, 0)
+		case int64:
+			serialType, width := integerSerialType(v)
+			types = appendVarint(types, serialType)
+			for i := width - 1; i >= 0; i-- {
+				body = append(body, byte(v>>(8*i)))
+			}
+		case float64:
+			types = appendVarint(types, 7)
+			bits := math.Float64bits(v)
+			for i := 7; i >= 0; i-- {
+				body = append(body, byte(bits>>(8*i)))
+			}
+		case string:
+			types = appendVarint(types, uint64(2*len(v)+13))
+			body = append(body, v...)
+		case []byte:
+			types = appendVarint(types, uint64(2*len(v)+12))
+			body = append(body, v...)
+		}
+	}
+
+	// The header size counts its own varint
+	headerSize := len(types) + 1
+	for len(types)+varintLen(uint64(headerSize)) != headerSize {
+		headerSize = len(types) + varintLen(uint64(headerSize))
+	}
+	record := appendVarint(make([]byte, 0, headerSize+len(body)), uint64(headerSize))
+	record = append(record, types...)
+	return append(record, body...)
+}
+
+// integerSerialType returns the serial type of an integer and its width in bytes
+func integerSerialType(v int64) (uint64, int) {
+	switch {
+	case v == 0:
+		return 8, 0
+	case v == 1:
+		return 9, 0
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return 1, 1
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return 2, 2
+	case v >= -1<<23 && v < 1<<23:
+		return 3, 3
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return 4, 4
+	case v >= -1<<47 && v < 1<<47:
+		return 5, 6
+	}
+	return 6, 8
+}
+
+// appendVarint appends a SQLite varint: big-endian groups of 7 bits, with the high bit
+// set on all bytes but the last. A ninth byte, if needed, holds 8 bits.
+func appendVarint(dst []byte, v uint64) []byte {
+	if v > 0x00ffffffffffffff {
+		var buf [9]byte
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return append(dst, buf[:]...)
+	}
+	var buf [8]byte
+	i := len(buf) - 1
+	buf[i] = byte(v & 0x7f)
+	for v >>= 7; v > 0; v >>= 7 {
+		i--
+		buf[i] = byte(v&0x7f) | 0x80
+	}
+	return append(dst, buf[i:]...)
+}
+
+// varintLen returns the number of bytes of a varint
+func varintLen(v uint64) int {
+	n := 1
+	for v >>= 7; v > 0 && n < 9; v >>= 7 {
+		n++
+	}
+	return n
+}
+
+// compareValues orders normalized values like SQLite's BINARY collation: NULL, then
+// numbers, then text, then blobs
+func compareValues(a, b any) int {
+	if c := cmp.Compare(storageClass(a), storageClass(b)); c != 0 {
+		return c
+	}
+	switch a := a.(type) {
+	case int64:
+		if b, ok := b.(int64); ok {
+			return cmp.Compare(a, b)
+		}
+		return cmp.Compare(float64(a), b.(float64))
+	case float64:
+		if b, ok := b.(int64); ok {
+			return cmp.Compare(a, float64(b))
+		}
+		return cmp.Compare(a, b.(float64))
+	case string:
+		return strings.Compare(a, b.(string))
+	case []byte:
+		return bytes.Compare(a, b.([]byte))
+	}
+	return 0
+}
+
+// storageClass ranks the storage classes of normalized values in sort order
+func storageClass(v any) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case int64, float64:
+		return 1
+	case string:
+		return 2
+	}
+	return 3
+}
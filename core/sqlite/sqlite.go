@@ -0,0 +1,243 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sqlite writes tables to SQLite database files.
+//
+// The writer does not use a SQLite library: it bulk-loads each table into a rowid b-tree
+// and each index into an index b-tree, following the database file format
+// (https://www.sqlite.org/fileformat2.html). The whole database is built in memory and
+// then written out, so it suits exports of tables that already fit in memory.
+package sqlite
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Type is the declared type of a column
+type Type string
+
+const (
+	Integer Type = "INTEGER"
+	Real    Type = "REAL"
+	Text    Type = "TEXT"
+	Blob    Type = "BLOB"
+)
+
+// Column is a column of a table
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Index is an index on one column of a table
+type Index struct {
+	Name   string
+	Column string
+	Unique bool // Written as a plain index if the column holds duplicate values
+}
+
+// Table is a table to write. Each row holds one value per column: nil, int, int64,
+// float64, bool, string, []byte or time.Time. Booleans are stored as 0 and 1, times
+// as UTC text ("2006-01-02 15:04:05.999"), which SQLite's date functions read.
+type Table struct {
+	Name    string
+	Columns []Column
+	Indexes []Index
+	Rows    [][]any
+}
+
+// Write writes a database holding the tables and their indexes
+func Write(w io.Writer, tables []Table) error {
+	if err := validate(tables); err != nil {
+		return err
+	}
+
+	db := &database{pages: [][]byte{nil}} // Page 1 holds the schema and is built last
+	var schema [][]byte
+	for _, table := range tables {
+		entries, err := db.writeTable(table)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			schema = append(schema, db.tableLeafCell(int64(len(schema)+1), encodeRecord(entry)))
+		}
+	}
+	db.buildTable(schema, true)
+	db.writeHeader()
+
+	for _, page := range db.pages {
+		if _, err := w.Write(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate checks the names of the tables, columns and indexes, which SQLite compares
+// case-insensitively, and the width of the rows
+func validate(tables []Table) error {
+	names := make(map[string]bool)
+	unique := func(kind, name string) error {
+		if name == "" {
+			return fmt.Errorf("%s name is empty", kind)
+		}
+		if strings.HasPrefix(strings.ToLower(name), "sqlite_") {
+			return fmt.Errorf("%s name '%s' is reserved", kind, name)
+		}
+		if names[strings.ToLower(name)] {
+			return fmt.Errorf("%s name '%s' is already used", kind, name)
+		}
+		names[strings.ToLower(name)] = true
+		return nil
+	}
+
+	for _, table := range tables {
+		if err := unique("table", table.Name); err != nil {
+			return err
+		}
+		if len(table.Columns) == 0 {
+			return fmt.Errorf("table '%s' has no columns", table.Name)
+		}
+		columns := make(map[string]bool)
+		for _, col := range table.Columns {
+			if col.Name == "" || columns[strings.ToLower(col.Name)] {
+				return fmt.Errorf("table '%s' has an empty or duplicate column name '%s'", table.Name, col.Name)
+			}
+			columns[strings.ToLower(col.Name)] = true
+		}
+		for _, index := range table.Indexes {
+			if err := unique("index", index.Name); err != nil {
+				return err
+			}
+			if !slices.ContainsFunc(table.Columns, func(col Column) bool { return col.Name == index.Column }) {
+				return fmt.Errorf("index '%s' refers to unknown column '%s' of table '%s'", index.Name, index.Column, table.Name)
+			}
+		}
+		for i, row := range table.Rows {
+			if len(row) != len(table.Columns) {
+				return fmt.Errorf("row %d of table '%s' has %d values, expected %d", i, table.Name, len(row), len(table.Columns))
+			}
+		}
+	}
+	return nil
+}
+
+// writeTable writes the b-trees of a table and its indexes, and returns their
+// sqlite_schema rows
+func (db *database) writeTable(table Table) ([][]any, error) {
+	cells := make([][]byte, len(table.Rows))
+	values := make([]any, len(table.Columns))
+	indexed := make([]int, len(table.Indexes))
+	for i, index := range table.Indexes {
+		indexed[i] = slices.IndexFunc(table.Columns, func(col Column) bool { return col.Name == index.Column })
+	}
+	keys := make([][]indexEntry, len(table.Indexes))
+	for i, row := range table.Rows {
+		rowid := int64(i + 1)
+		for c, value := range row {
+			v, err := normalize(value)
+			if err != nil {
+				return nil, fmt.Errorf("row %d of table '%s', column '%s': %w", i, table.Name, table.Columns[c].Name, err)
+			}
+			values[c] = v
+		}
+		for k, c := range indexed {
+			keys[k] = append(keys[k], indexEntry{value: values[c], rowid: rowid})
+		}
+		cells[i] = db.tableLeafCell(rowid, encodeRecord(values))
+	}
+
+	root := db.buildTable(cells, false)
+	columnDefs := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		columnDefs[i] = quote(col.Name) + " " + string(col.Type)
+	}
+	entries := [][]any{{"table", table.Name, table.Name, int64(root),
+		fmt.Sprintf("CREATE TABLE %s (%s)", quote(table.Name), strings.Join(columnDefs, ", "))}}
+
+	for k, index := range table.Indexes {
+		entries = append(entries, db.writeIndex(table.Name, index, keys[k]))
+	}
+	return entries, nil
+}
+
+// writeIndex writes the b-tree of an index and returns its sqlite_schema row
+func (db *database) writeIndex(tableName string, index Index, entries []indexEntry) []any {
+	slices.SortFunc(entries, func(a, b indexEntry) int {
+		if c := compareValues(a.value, b.value); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.rowid, b.rowid)
+	})
+	create := "CREATE INDEX"
+	if index.Unique && !hasDuplicates(entries) {
+		create = "CREATE UNIQUE INDEX"
+	}
+
+	cells := make([][]byte, len(entries))
+	for i, entry := range entries {
+		cells[i] = db.indexCell(encodeRecord([]any{entry.value, entry.rowid}))
+	}
+	root := db.buildIndex(cells)
+	return []any{"index", index.Name, tableName, int64(root),
+		fmt.Sprintf("%s %s ON %s (%s)", create, quote(index.Name), quote(tableName), quote(index.Column))}
+}
+
+// indexEntry is the key of a row in an index
+type indexEntry struct {
+	value any
+	rowid int64
+}
+
+// hasDuplicates returns whether sorted index entries repeat a value. NULLs do not
+// count, as a unique index accepts any number of them.
+func hasDuplicates(entries []indexEntry) bool {
+	for i := 1; i < len(entries); i++ {
+		if entries[i].value != nil && compareValues(entries[i-1].value, entries[i].value) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// quote quotes an identifier for SQL
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// writeHeader fills in the database header at the start of page 1
+func (db *database) writeHeader() {
+	header := db.pages[0][:headerSize]
+	copy(header, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(header[16:], pageSize)
+	header[18], header[19] = 1, 1 // Legacy (rollback journal) read and write versions
+	header[21], header[22], header[23] = 64, 32, 32
+	binary.BigEndian.PutUint32(header[24:], 1) // File change counter
+	binary.BigEndian.PutUint32(header[28:], uint32(len(db.pages)))
+	binary.BigEndian.PutUint32(header[40:], 1) // Schema cookie
+	binary.BigEndian.PutUint32(header[44:], 4) // Schema format
+	binary.BigEndian.PutUint32(header[56:], 1) // UTF-8
+	binary.BigEndian.PutUint32(header[92:], 1) // The page count is valid for change 1
+	binary.BigEndian.PutUint32(header[96:], sqliteVersion)
+}
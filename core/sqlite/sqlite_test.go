@@ -0,0 +1,310 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendVarint(t *testing.T) {
+	tests := []struct {
+		value uint64
+		want  []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x81, 0x00}},
+		{16383, []byte{0xff, 0x7f}},
+		{1 << 56, []byte{0x80, 0xc0, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}},
+		{math.MaxUint64, bytes.Repeat([]byte{0xff}, 9)},
+	}
+	for _, tt := range tests {
+		got := appendVarint(nil, tt.value)
+		if !bytes.Equal(got, tt.want) || varintLen(tt.value) != len(tt.want) {
+			t.Errorf("varint(%d) = %x (len %d), want %x", tt.value, got, varintLen(tt.value), tt.want)
+		}
+		if value, n := readVarint(got); value != tt.value || n != len(got) {
+			t.Errorf("readVarint(%x) = %d, %d", got, value, n)
+		}
+	}
+}
+
+func TestEncodeRecord(t *testing.T) {
+	got := encodeRecord([]any{nil, int64(0), int64(1), int64(-2), int64(300), "ab", []byte{7}, 1.5})
+	want := []byte{
+		9, 0, 8, 9, 1, 2, 2*2 + 13, 1*2 + 12, 7, // Header
+		0xfe, 0x01, 0x2c, 'a', 'b', 7, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0,
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeRecord = %x, want %x", got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	// Enough rows and long values for interior pages, overflow pages and a
+	// multi-level index
+	var rows [][]any
+	for i := range 5000 {
+		var name any = fmt.Sprintf("item-%05d", (i*7)%5000)
+		if i%500 == 0 {
+			name = nil
+		}
+		rows = append(rows, []any{i, name, float64(i) / 4, i%2 == 0, time.Unix(int64(i), 0), strings.Repeat("x", i%3000)})
+	}
+	items := Table{
+		Name: "items",
+		Columns: []Column{
+			{"id", Integer}, {"name", Text}, {"weight", Real}, {"even", Integer}, {"seen", Text}, {"notes", Text},
+		},
+		Indexes: []Index{{"items_name", "name", true}, {"items_even", "even", true}, {"items_notes", "notes", false}},
+		Rows:    rows,
+	}
+	empty := Table{Name: "empty", Columns: []Column{{"a", Text}}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, []Table{items, empty}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	data := buf.Bytes()
+	if len(data)%pageSize != 0 || int(binary.BigEndian.Uint32(data[28:])) != len(data)/pageSize {
+		t.Fatalf("database size %d does not match the page count in the header", len(data))
+	}
+
+	schema := readTable(t, data, 1)
+	if len(schema) != 5 {
+		t.Fatalf("expected 5 schema entries, got %d", len(schema))
+	}
+	wantSQL := []string{
+		`CREATE TABLE "items" ("id" INTEGER, "name" TEXT, "weight" REAL, "even" INTEGER, "seen" TEXT, "notes" TEXT)`,
+		`CREATE UNIQUE INDEX "items_name" ON "items" ("name")`,
+		`CREATE INDEX "items_even" ON "items" ("even")`, // Not unique: written as a plain index
+		`CREATE INDEX "items_notes" ON "items" ("notes")`,
+		`CREATE TABLE "empty" ("a" TEXT)`,
+	}
+	for i, entry := range schema {
+		if entry[4] != wantSQL[i] {
+			t.Errorf("schema entry %d: got %q, want %q", i, entry[4], wantSQL[i])
+		}
+	}
+
+	got := readTable(t, data, uint32(schema[0][3].(int64)))
+	if len(got) != len(rows) {
+		t.Fatalf("expected %d rows, got %d", len(rows), len(got))
+	}
+	for _, i := range []int{0, 1, 1234, 2999, 4999} {
+		want := make([]any, len(rows[i]))
+		for c, value := range rows[i] {
+			want[c], _ = normalize(value)
+		}
+		if !reflect.DeepEqual(got[i], want) {
+			t.Errorf("row %d: got %v, want %v", i, got[i], want)
+		}
+	}
+
+	// Index entries are sorted by value, then rowid
+	for _, entry := range schema[1:4] {
+		keys := readIndex(t, data, uint32(entry[3].(int64)))
+		if len(keys) != len(rows) {
+			t.Errorf("%s: expected %d entries, got %d", entry[1], len(rows), len(keys))
+		}
+		for i := 1; i < len(keys); i++ {
+			if c := compareValues(keys[i-1][0], keys[i][0]); c > 0 || c == 0 && keys[i-1][1].(int64) >= keys[i][1].(int64) {
+				t.Fatalf("%s: entries %d and %d are out of order", entry[1], i-1, i)
+			}
+		}
+	}
+
+	if rows := readTable(t, data, uint32(schema[4][3].(int64))); len(rows) != 0 {
+		t.Errorf("expected an empty table, got %d rows", len(rows))
+	}
+
+	// SQLite itself agrees, if it is installed
+	if _, err := exec.LookPath("sqlite3"); err == nil {
+		path := filepath.Join(t.TempDir(), "test.db")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		out, err := exec.Command("sqlite3", path, "PRAGMA integrity_check; SELECT count(*) FROM items WHERE name > 'item-04990';").CombinedOutput()
+		if err != nil || string(out) != "ok\n9\n" {
+			t.Errorf("sqlite3: %v: %s", err, out)
+		}
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	columns := []Column{{"a", Text}}
+	tests := []struct {
+		name   string
+		tables []Table
+		want   string
+	}{
+		{"duplicate table", []Table{{Name: "t", Columns: columns}, {Name: "T", Columns: columns}}, "already used"},
+		{"reserved name", []Table{{Name: "sqlite_t", Columns: columns}}, "reserved"},
+		{"no columns", []Table{{Name: "t"}}, "no columns"},
+		{"duplicate column", []Table{{Name: "t", Columns: []Column{{"a", Text}, {"A", Text}}}}, "duplicate column"},
+		{"unknown index column", []Table{{Name: "t", Columns: columns, Indexes: []Index{{Name: "i", Column: "b"}}}}, "unknown column"},
+		{"index named like a table", []Table{{Name: "t", Columns: columns, Indexes: []Index{{Name: "t", Column: "a"}}}}, "already used"},
+		{"short row", []Table{{Name: "t", Columns: columns, Rows: [][]any{{}}}}, "has 0 values"},
+		{"unsupported value", []Table{{Name: "t", Columns: columns, Rows: [][]any{{struct{}{}}}}}, "unsupported value type"},
+	}
+	for _, tt := range tests {
+		err := Write(&bytes.Buffer{}, tt.tables)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+// readTable returns the records of a table b-tree in rowid order
+func readTable(t *testing.T, data []byte, root uint32) [][]any {
+	var rows [][]any
+	walkTree(t, data, root, func(cell []byte, pageType byte) {
+		size, n := readVarint(cell)
+		_, m := readVarint(cell[n:])
+		rows = append(rows, decodeRecord(t, payload(data, cell[n+m:], int(size), tableMaxLocal)))
+	})
+	return rows
+}
+
+// readIndex returns the records of an index b-tree in order
+func readIndex(t *testing.T, data []byte, root uint32) [][]any {
+	var keys [][]any
+	walkTree(t, data, root, func(cell []byte, pageType byte) {
+		if pageType == indexInterior {
+			cell = cell[4:]
+		}
+		size, n := readVarint(cell)
+		keys = append(keys, decodeRecord(t, payload(data, cell[n:], int(size), indexMaxLocal)))
+	})
+	return keys
+}
+
+// walkTree visits the cells holding data in key order: all cells of leaves, and the
+// cells of index interior pages between their children
+func walkTree(t *testing.T, data []byte, pageNumber uint32, visit func(cell []byte, pageType byte)) {
+	t.Helper()
+	page := data[(pageNumber-1)*pageSize : pageNumber*pageSize]
+	header := page
+	if pageNumber == 1 {
+		header = page[headerSize:]
+	}
+	pageType := header[0]
+	cellCount := int(binary.BigEndian.Uint16(header[3:]))
+	pointers := header[8:]
+	if pageType == tableInterior || pageType == indexInterior {
+		pointers = header[12:]
+	}
+	for i := range cellCount {
+		cell := page[binary.BigEndian.Uint16(pointers[2*i:]):]
+		switch pageType {
+		case tableLeaf, indexLeaf:
+			visit(cell, pageType)
+		case tableInterior:
+			walkTree(t, data, binary.BigEndian.Uint32(cell), visit)
+		case indexInterior:
+			walkTree(t, data, binary.BigEndian.Uint32(cell), visit)
+			visit(cell, pageType)
+		default:
+			t.Fatalf("page %d has unexpected type %#x", pageNumber, pageType)
+		}
+	}
+	if pageType == tableInterior || pageType == indexInterior {
+		walkTree(t, data, binary.BigEndian.Uint32(header[8:]), visit)
+	}
+}
+
+// payload reassembles a payload from its local part and overflow pages
+func payload(data, local []byte, size, maxLocal int) []byte {
+	if size <= maxLocal {
+		return local[:size]
+	}
+	localSize := minLocal + (size-minLocal)%(pageSize-4)
+	if localSize > maxLocal {
+		localSize = minLocal
+	}
+	result := append([]byte{}, local[:localSize]...)
+	for next := binary.BigEndian.Uint32(local[localSize:]); len(result) < size; {
+		page := data[(next-1)*pageSize : next*pageSize]
+		result = append(result, page[4:min(pageSize, 4+size-len(result))]...)
+		next = binary.BigEndian.Uint32(page)
+	}
+	return result
+}
+
+// decodeRecord decodes a record into normalized values
+func decodeRecord(t *testing.T, record []byte) []any {
+	t.Helper()
+	headerSize, n := readVarint(record)
+	types, body := record[n:headerSize], record[headerSize:]
+	var values []any
+	for len(types) > 0 {
+		serialType, m := readVarint(types)
+		types = types[m:]
+		switch {
+		case serialType == 0:
+			values = append(values, nil)
+		case serialType == 8 || serialType == 9:
+			values = append(values, int64(serialType-8))
+		case serialType <= 6:
+			width := []int{0, 1, 2, 3, 4, 6, 8}[serialType]
+			v := int64(int8(body[0]))
+			for _, b := range body[1:width] {
+				v = v<<8 | int64(b)
+			}
+			values = append(values, v)
+			body = body[width:]
+		case serialType == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(body)))
+			body = body[8:]
+		case serialType >= 12:
+			size := int(serialType-12) / 2
+			if serialType%2 == 1 {
+				values = append(values, string(body[:size]))
+			} else {
+				values = append(values, body[:size])
+			}
+			body = body[size:]
+		default:
+			t.Fatalf("unexpected serial type %d", serialType)
+		}
+	}
+	return values
+}
+
+// readVarint decodes a varint and returns its value and size
+func readVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := range 8 {
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return v<<8 | uint64(b[8]), 9
+}
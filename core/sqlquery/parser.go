@@ -0,0 +1,475 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sqlquery translates a subset of SQL SELECT statements to the filter, group and
+// aggregate pipeline of table views, so that SQL clients (e.g., BI tools connecting over
+// Arrow Flight SQL) can query the tables directly.
+//
+// Supported statements have the form
+//
+//	SELECT items FROM table [WHERE conditions] [GROUP BY columns] [ORDER BY names] [LIMIT n]
+//
+// where items are columns, * or the aggregates COUNT(*), COUNT(col), COUNT(DISTINCT col),
+// SUM, AVG, MIN and MAX, each with an optional AS alias. Conditions are joined by AND and
+// compare a column with =, IN or LIKE.
+package sqlquery
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/google/taxinomia/core/errs"
+)
+
+// Func is an aggregate function of a select item
+type Func string
+
+// Aggregate functions
+const (
+	FuncNone  Func = "" // Plain column
+	FuncCount Func = "count"
+	FuncSum   Func = "sum"
+	FuncAvg   Func = "avg"
+	FuncMin   Func = "min"
+	FuncMax   Func = "max"
+)
+
+// Statement is a parsed SELECT statement
+type Statement struct {
+	Table   string
+	Items   []SelectItem
+	Where   []Condition
+	GroupBy []string
+	OrderBy []OrderItem
+	Limit   int // 0 = no limit
+}
+
+// SelectItem is one item of the select list
+type SelectItem struct {
+	Star     bool   // * or COUNT(*)
+	Column   string // Column name (empty for *)
+	Func     Func   // Aggregate function (FuncNone for a plain column)
+	Distinct bool   // COUNT(DISTINCT column)
+	Alias    string // Name given with AS (empty if none)
+}
+
+// Name returns the name of the item's result column: its alias, its column for a plain
+// column, or the aggregate as written (e.g., "sum(amount)").
+func (i SelectItem) Name() string {
+	if i.Alias != "" {
+		return i.Alias
+	}
+	if i.Func == FuncNone {
+		return i.Column
+	}
+	arg := i.Column
+	if i.Star {
+		arg = "*"
+	} else if i.Distinct {
+		arg = "distinct " + arg
+	}
+	return string(i.Func) + "(" + arg + ")"
+}
+
+// Condition compares a column with values. Op is "=", "IN" or "LIKE".
+type Condition struct {
+	Column string
+	Op     string
+	Values []string
+}
+
+// OrderItem is one key of the ORDER BY clause, naming a result column
+type OrderItem struct {
+	Name string
+	Desc bool
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// is reports whether the token is the given keyword or symbol (keywords are case-insensitive)
+func (t token) is(word string) bool {
+	return (t.kind == tokenIdent || t.kind == tokenSymbol) && strings.EqualFold(t.text, word)
+}
+
+// tokenize splits a statement into identifiers, string literals, numbers and symbols
+func tokenize(sql string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'':
+			// String literal; a doubled quote stands for a quote
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(sql); j++ {
+				if sql[j] == '\'' {
+					if j+1 < len(sql) && sql[j+1] == '\'' {
+						b.WriteByte('\'')
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteByte(sql[j])
+			}
+			if j >= len(sql) {
+				return nil, errs.New(errs.ErrInvalidQuery, "Unterminated string at position %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: b.String(), pos: i})
+			i = j + 1
+		case c == '"' || c == '`':
+			// Quoted identifier
+			end := strings.IndexByte(sql[i+1:], c)
+			if end < 0 {
+				return nil, errs.New(errs.ErrInvalidQuery, "Unterminated identifier at position %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenQuotedIdent, text: sql[i+1 : i+1+end], pos: i})
+			i += end + 2
+		case isDigit(c) || (c == '-' && i+1 < len(sql) && isDigit(sql[i+1])):
+			j := i + 1
+			for j < len(sql) && (isDigit(sql[j]) || sql[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: sql[i:j], pos: i})
+			i = j
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(sql) && (isIdentStart(sql[j]) || isDigit(sql[j]) || sql[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: sql[i:j], pos: i})
+			i = j
+		case strings.IndexByte(",()*=;", c) >= 0:
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(c), pos: i})
+			i++
+		default:
+			return nil, errs.New(errs.ErrInvalidQuery, "Unexpected character '%c' at position %d", c, i)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(sql)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// keywords cannot be used as unquoted column names or aliases
+var keywords = map[string]bool{
+	"select": true, "from": true, "where": true, "and": true, "or": true, "not": true,
+	"in": true, "like": true, "group": true, "order": true, "by": true, "asc": true,
+	"desc": true, "limit": true, "as": true, "distinct": true, "join": true,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the given keyword or symbol
+func (p *parser) accept(word string) bool {
+	if p.peek().is(word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(word string) error {
+	if !p.accept(word) {
+		return p.unexpected("'" + word + "'")
+	}
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return errs.New(errs.ErrInvalidQuery, "Expected %s at end of query", expected)
+	}
+	return errs.New(errs.ErrInvalidQuery, "Expected %s at position %d, found '%s'", expected, t.pos, t.text)
+}
+
+// name reads a table, column or alias name
+func (p *parser) name(what string) (string, error) {
+	t := p.peek()
+	if t.kind == tokenQuotedIdent || (t.kind == tokenIdent && !keywords[strings.ToLower(t.text)]) {
+		p.pos++
+		return t.text, nil
+	}
+	return "", p.unexpected(what)
+}
+
+// literal reads a string, number or boolean value
+func (p *parser) literal() (string, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenString || t.kind == tokenNumber:
+		p.pos++
+		return t.text, nil
+	case t.is("true") || t.is("false"):
+		p.pos++
+		return strings.ToLower(t.text), nil
+	}
+	return "", p.unexpected("a value")
+}
+
+// Parse parses a SELECT statement
+func Parse(sql string) (*Statement, error) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	stmt := &Statement{}
+
+	if err := p.expect("select"); err != nil {
+		return nil, err
+	}
+	for {
+		item, err := p.selectItem()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Items = append(stmt.Items, item)
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if err := p.expect("from"); err != nil {
+		return nil, err
+	}
+	if stmt.Table, err = p.name("a table name"); err != nil {
+		return nil, err
+	}
+
+	if p.accept("where") {
+		for {
+			cond, err := p.condition()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Where = append(stmt.Where, cond)
+			if !p.accept("and") {
+				break
+			}
+		}
+	}
+
+	if p.accept("group") {
+		if err := p.expect("by"); err != nil {
+			return nil, err
+		}
+		for {
+			col, err := p.name("a column name")
+			if err != nil {
+				return nil, err
+			}
+			stmt.GroupBy = append(stmt.GroupBy, col)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	if p.accept("order") {
+		if err := p.expect("by"); err != nil {
+			return nil, err
+		}
+		for {
+			item, err := p.orderItem()
+			if err != nil {
+				return nil, err
+			}
+			stmt.OrderBy = append(stmt.OrderBy, item)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	if p.accept("limit") {
+		t := p.next()
+		limit, err := strconv.Atoi(t.text)
+		if t.kind != tokenNumber || err != nil || limit < 0 {
+			return nil, errs.New(errs.ErrInvalidQuery, "Invalid LIMIT '%s'", t.text)
+		}
+		stmt.Limit = limit
+	}
+
+	p.accept(";")
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected("end of query")
+	}
+	return stmt, nil
+}
+
+// selectItem parses *, a column or an aggregate, with an optional alias
+func (p *parser) selectItem() (SelectItem, error) {
+	var item SelectItem
+	if p.accept("*") {
+		item.Star = true
+		return item, nil
+	}
+
+	t := p.peek()
+	if t.kind == tokenIdent && p.tokens[p.pos+1].is("(") {
+		item.Func = Func(strings.ToLower(t.text))
+		switch item.Func {
+		case FuncCount, FuncSum, FuncAvg, FuncMin, FuncMax:
+		default:
+			return item, errs.New(errs.ErrInvalidQuery, "Unsupported function '%s' at position %d", t.text, t.pos)
+		}
+		p.pos += 2
+		if item.Func == FuncCount && p.accept("*") {
+			item.Star = true
+		} else {
+			item.Distinct = item.Func == FuncCount && p.accept("distinct")
+			col, err := p.name("a column name")
+			if err != nil {
+				return item, err
+			}
+			item.Column = col
+		}
+		if err := p.expect(")"); err != nil {
+			return item, err
+		}
+	} else {
+		col, err := p.name("a column name")
+		if err != nil {
+			return item, err
+		}
+		item.Column = col
+	}
+
+	if p.accept("as") {
+		alias, err := p.name("an alias")
+		if err != nil {
+			return item, err
+		}
+		item.Alias = alias
+	}
+	return item, nil
+}
+
+// condition parses "column = value", "column IN (values)" or "column LIKE pattern"
+func (p *parser) condition() (Condition, error) {
+	col, err := p.name("a column name")
+	if err != nil {
+		return Condition{}, err
+	}
+	cond := Condition{Column: col}
+	switch {
+	case p.accept("="):
+		cond.Op = "="
+	case p.accept("like"):
+		cond.Op = "LIKE"
+	case p.accept("in"):
+		cond.Op = "IN"
+		if err := p.expect("("); err != nil {
+			return cond, err
+		}
+		for {
+			value, err := p.literal()
+			if err != nil {
+				return cond, err
+			}
+			cond.Values = append(cond.Values, value)
+			if !p.accept(",") {
+				break
+			}
+		}
+		return cond, p.expect(")")
+	default:
+		return cond, p.unexpected("=, IN or LIKE")
+	}
+	value, err := p.literal()
+	if err != nil {
+		return cond, err
+	}
+	cond.Values = []string{value}
+	return cond, nil
+}
+
+// orderItem parses a result column name or position with an optional direction
+func (p *parser) orderItem() (OrderItem, error) {
+	var item OrderItem
+	if t := p.peek(); t.kind == tokenNumber {
+		// Position in the select list, resolved when planning
+		p.pos++
+		item.Name = t.text
+	} else {
+		name, err := p.orderName()
+		if err != nil {
+			return item, err
+		}
+		item.Name = name
+	}
+	if p.accept("desc") {
+		item.Desc = true
+	} else {
+		p.accept("asc")
+	}
+	return item, nil
+}
+
+// orderName reads a result column name, which may be an aggregate written out in full
+func (p *parser) orderName() (string, error) {
+	t := p.peek()
+	if t.kind == tokenIdent && p.tokens[p.pos+1].is("(") {
+		item, err := p.selectItem()
+		if err != nil {
+			return "", err
+		}
+		return item.Name(), nil
+	}
+	return p.name("a column name")
+}
@@ -0,0 +1,534 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlquery
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/taxinomia/core/aggregates"
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/internal/grouping"
+	"github.com/google/taxinomia/core/models"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// Kind is the type of a result column
+type Kind int
+
+// Result column kinds. Values of a column are nil or of the Go type noted for its kind.
+const (
+	KindString    Kind = iota // string
+	KindInt64                 // int64
+	KindFloat64               // float64
+	KindBool                  // bool
+	KindTimestamp             // time.Time
+)
+
+// String returns the SQL name of the kind
+func (k Kind) String() string {
+	switch k {
+	case KindInt64:
+		return "BIGINT"
+	case KindFloat64:
+		return "DOUBLE"
+	case KindBool:
+		return "BOOLEAN"
+	case KindTimestamp:
+		return "TIMESTAMP"
+	}
+	return "VARCHAR"
+}
+
+// Column is a column of a result
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// Result holds the rows of an executed statement
+type Result struct {
+	Columns     []Column
+	Rows        [][]any // One value per column; nil for a missing value
+	PushedDown  bool    // Whether the rows were computed at the source of the table
+	RowsScanned int     // Rows read in memory to compute the result, not counting rows read at the source
+}
+
+// Plan is a statement resolved against a table, ready to be executed
+type Plan struct {
+	Statement *Statement
+	Columns   []Column // Columns of the result
+
+	table     *tables.DataTable
+	items     []SelectItem // Select list with * expanded
+	filters   map[string]string
+	leafCols  []string // Columns whose aggregates are computed
+	orderCols []int    // Result column of each ORDER BY key
+}
+
+// Prepare parses a statement and resolves it against the tables of the data model
+func Prepare(dm *models.DataModel, sql string) (*Plan, error) {
+	stmt, err := Parse(sql)
+	if err != nil {
+		return nil, err
+	}
+	return Resolve(dm, stmt)
+}
+
+// Resolve checks a statement against the tables of the data model and determines the
+// columns of its result
+func Resolve(dm *models.DataModel, stmt *Statement) (*Plan, error) {
+	table := dm.GetTable(stmt.Table)
+	if table == nil {
+		return nil, errs.New(errs.ErrUnknownTable, "Table '%s' not found", stmt.Table)
+	}
+	return ResolveTable(table, stmt)
+}
+
+// ResolveTable checks a statement against a table, whatever the table the statement
+// names, and determines the columns of its result
+func ResolveTable(table *tables.DataTable, stmt *Statement) (*Plan, error) {
+	p := &Plan{Statement: stmt, table: table}
+
+	hasAggregates := false
+	for _, item := range stmt.Items {
+		if item.Func != FuncNone {
+			hasAggregates = true
+		}
+	}
+	grouped := hasAggregates || len(stmt.GroupBy) > 0
+	for _, col := range stmt.GroupBy {
+		if table.GetColumn(col) == nil {
+			return nil, errs.New(errs.ErrUnknownColumn, "Column '%s' not found in table '%s'", col, stmt.Table)
+		}
+	}
+
+	for _, item := range stmt.Items {
+		if item.Star && item.Func == FuncNone {
+			if grouped {
+				return nil, errs.New(errs.ErrInvalidQuery, "SELECT * cannot be combined with aggregates or GROUP BY")
+			}
+			for _, col := range sortedColumnNames(table) {
+				p.items = append(p.items, SelectItem{Column: col})
+			}
+			continue
+		}
+		if !item.Star && table.GetColumn(item.Column) == nil {
+			return nil, errs.New(errs.ErrUnknownColumn, "Column '%s' not found in table '%s'", item.Column, stmt.Table)
+		}
+		if item.Func == FuncNone && grouped && !slices.Contains(stmt.GroupBy, item.Column) {
+			return nil, errs.New(errs.ErrInvalidQuery, "Column '%s' must appear in GROUP BY or be aggregated", item.Column)
+		}
+		p.items = append(p.items, item)
+	}
+
+	for _, item := range p.items {
+		kind, err := p.itemKind(item)
+		if err != nil {
+			return nil, err
+		}
+		p.Columns = append(p.Columns, Column{Name: item.Name(), Kind: kind})
+		if item.Func != FuncNone && !item.Star && !slices.Contains(p.leafCols, item.Column) {
+			p.leafCols = append(p.leafCols, item.Column)
+		}
+	}
+
+	var err error
+	if p.filters, err = translateConditions(table, stmt.Where); err != nil {
+		return nil, err
+	}
+	for _, key := range stmt.OrderBy {
+		col, err := p.orderColumn(key.Name)
+		if err != nil {
+			return nil, err
+		}
+		p.orderCols = append(p.orderCols, col)
+	}
+	return p, nil
+}
+
+// itemKind determines the kind of a select item's values and checks its aggregate applies
+// to the column
+func (p *Plan) itemKind(item SelectItem) (Kind, error) {
+	if item.Func == FuncCount {
+		if item.Distinct {
+			if _, ok := p.table.GetColumn(item.Column).(*columns.StringColumn); !ok {
+				return 0, errs.New(errs.ErrTypeMismatch, "COUNT(DISTINCT) is only supported for string columns, not '%s'", item.Column)
+			}
+		}
+		return KindInt64, nil
+	}
+	kind := ColumnKind(p.table.GetColumn(item.Column))
+	if slices.Contains(p.Statement.GroupBy, item.Column) && item.Func != FuncNone {
+		return 0, errs.New(errs.ErrInvalidQuery, "Grouped column '%s' can only be counted", item.Column)
+	}
+	switch item.Func {
+	case FuncSum, FuncAvg:
+		if kind != KindInt64 && kind != KindFloat64 {
+			return 0, errs.New(errs.ErrTypeMismatch, "%s requires a numeric column, '%s' is %s", strings.ToUpper(string(item.Func)), item.Column, kind)
+		}
+		return KindFloat64, nil
+	case FuncMin, FuncMax:
+		switch kind {
+		case KindInt64, KindFloat64:
+			return KindFloat64, nil
+		case KindBool:
+			return 0, errs.New(errs.ErrTypeMismatch, "%s is not supported for boolean column '%s'", strings.ToUpper(string(item.Func)), item.Column)
+		case KindString:
+			if _, ok := p.table.GetColumn(item.Column).(*columns.StringColumn); !ok {
+				return 0, errs.New(errs.ErrTypeMismatch, "%s is not supported for column '%s'", strings.ToUpper(string(item.Func)), item.Column)
+			}
+		}
+	}
+	return kind, nil
+}
+
+// orderColumn resolves an ORDER BY key to a result column: by name, by the aggregate of an
+// aliased item, or by its 1-based position in the select list
+func (p *Plan) orderColumn(name string) (int, error) {
+	for i, col := range p.Columns {
+		if col.Name == name {
+			return i, nil
+		}
+	}
+	for i, item := range p.items {
+		item.Alias = ""
+		if item.Name() == name {
+			return i, nil
+		}
+	}
+	if pos, err := strconv.Atoi(name); err == nil {
+		if pos < 1 || pos > len(p.Columns) {
+			return 0, errs.New(errs.ErrInvalidQuery, "ORDER BY position %d is not in the select list", pos)
+		}
+		return pos - 1, nil
+	}
+	return 0, errs.New(errs.ErrInvalidQuery, "ORDER BY '%s' must name a selected column", name)
+}
+
+// translateConditions turns WHERE conditions into table view filters. Each column takes
+// one condition, since a view filters a column on a single expression.
+func translateConditions(table *tables.DataTable, conds []Condition) (map[string]string, error) {
+	filters := make(map[string]string)
+	for _, cond := range conds {
+		if table.GetColumn(cond.Column) == nil {
+			return nil, errs.New(errs.ErrUnknownColumn, "Filter column '%s' not found", cond.Column)
+		}
+		if _, ok := filters[cond.Column]; ok {
+			return nil, errs.New(errs.ErrInvalidQuery, "Only one condition per column is supported, '%s' has several", cond.Column)
+		}
+		for _, v := range cond.Values {
+			if strings.Contains(v, "|") {
+				return nil, errs.New(errs.ErrInvalidQuery, "Values containing '|' cannot be filtered on")
+			}
+		}
+		switch cond.Op {
+		case "=":
+			filters[cond.Column] = `"` + cond.Values[0] + `"`
+		case "IN":
+			if len(cond.Values) == 1 {
+				filters[cond.Column] = `"` + cond.Values[0] + `"`
+			} else {
+				filters[cond.Column] = strings.Join(cond.Values, "|")
+			}
+		case "LIKE":
+			// Views match substrings, so only '%text%' and patterns without wildcards translate
+			pattern := cond.Values[0]
+			if inner, ok := strings.CutPrefix(pattern, "%"); ok && strings.HasSuffix(inner, "%") && len(inner) > 1 {
+				inner = strings.TrimSuffix(inner, "%")
+				if strings.ContainsAny(inner, "%_") {
+					return nil, errs.New(errs.ErrInvalidQuery, "Unsupported LIKE pattern '%s'", pattern)
+				}
+				filters[cond.Column] = inner
+			} else if !strings.ContainsAny(pattern, "%_") {
+				filters[cond.Column] = `"` + pattern + `"`
+			} else {
+				return nil, errs.New(errs.ErrInvalidQuery, "Unsupported LIKE pattern '%s', use '%%text%%'", pattern)
+			}
+		}
+	}
+	return filters, nil
+}
+
+// Execute filters, groups and aggregates the table and returns the rows of the result
+func (p *Plan) Execute() (*Result, error) {
+	view := tables.NewTableView(p.table, p.Statement.Table)
+	view.ApplyFilters(p.filters)
+
+	result := &Result{Columns: p.Columns}
+	switch {
+	case len(p.Statement.GroupBy) > 0:
+		view.VisibleColumns = append(slices.Clone(p.Statement.GroupBy), p.leafCols...)
+		view.GroupTable(p.Statement.GroupBy, nil, make(map[string]tables.Compare), make(map[string]bool))
+		if block := view.GetFirstBlock(); block != nil {
+			p.appendGroupRows(view, block, result)
+		}
+	case len(p.leafCols) > 0 || p.hasCountStar():
+		indices := view.GetFilteredIndices()
+		p.appendAggregateRow(view, view.AggregateFilteredRows(p.leafCols), indices, result)
+		result.RowsScanned = len(indices)
+	default:
+		rows := view.GetFilteredIndices()
+		result.RowsScanned = len(rows)
+		for _, row := range rows {
+			values := make([]any, len(p.items))
+			for i, item := range p.items {
+				values[i] = ColumnValue(p.table.GetColumn(item.Column), row)
+			}
+			result.Rows = append(result.Rows, values)
+		}
+	}
+
+	result.RowsScanned += view.TakeRowsScanned()
+	p.sortRows(result.Rows)
+	if limit := p.Statement.Limit; limit > 0 && len(result.Rows) > limit {
+		result.Rows = result.Rows[:limit]
+	}
+	return result, nil
+}
+
+// MatchingRows returns the rows of the table that match the WHERE conditions
+func (p *Plan) MatchingRows() []uint32 {
+	view := tables.NewTableView(p.table, p.Statement.Table)
+	view.ApplyFilters(p.filters)
+	return view.GetFilteredIndices()
+}
+
+func (p *Plan) hasCountStar() bool {
+	for _, item := range p.items {
+		if item.Star {
+			return true
+		}
+	}
+	return false
+}
+
+// appendGroupRows adds one row per group of the last GROUP BY column
+func (p *Plan) appendGroupRows(view *tables.TableView, block *grouping.Block, result *Result) {
+	for _, group := range block.Groups {
+		if group.ChildBlock != nil {
+			p.appendGroupRows(view, group.ChildBlock, result)
+			continue
+		}
+		p.appendAggregateRow(view, group.Aggregates, group.Indices, result)
+	}
+}
+
+// appendAggregateRow adds a row for a group of rows with the given aggregates
+func (p *Plan) appendAggregateRow(view *tables.TableView, states map[string]aggregates.AggregateState, indices []uint32, result *Result) {
+	values := make([]any, len(p.items))
+	for i, item := range p.items {
+		switch {
+		case item.Func == FuncNone:
+			// Grouped column: all rows of the group share its value
+			if len(indices) > 0 {
+				values[i] = ColumnValue(p.table.GetColumn(item.Column), indices[0])
+			}
+		case item.Star:
+			values[i] = int64(len(indices))
+		default:
+			values[i] = aggregateValue(states[item.Column], item)
+		}
+	}
+	result.Rows = append(result.Rows, values)
+}
+
+// aggregateValue extracts the value of an aggregate from its state (nil if it has no values)
+func aggregateValue(state aggregates.AggregateState, item SelectItem) any {
+	switch s := state.(type) {
+	case *aggregates.NumericAggState:
+		if item.Func == FuncCount {
+			return s.Count
+		}
+		if s.Count == 0 {
+			return nil
+		}
+		switch item.Func {
+		case FuncSum:
+			return s.Sum
+		case FuncAvg:
+			return s.Avg()
+		case FuncMin:
+			return s.Min
+		case FuncMax:
+			return s.Max
+		}
+	case *aggregates.StringAggState:
+		switch {
+		case item.Func == FuncCount && item.Distinct:
+			return int64(s.UniqueCount())
+		case item.Func == FuncCount:
+			return s.Count
+		case !s.HasValues:
+			return nil
+		case item.Func == FuncMin:
+			return s.Min
+		case item.Func == FuncMax:
+			return s.Max
+		}
+	case *aggregates.BoolAggState:
+		if item.Func == FuncCount {
+			return s.Count
+		}
+	case *aggregates.DatetimeAggState:
+		if item.Func == FuncCount {
+			return s.Count
+		}
+		if s.Count == 0 {
+			return nil
+		}
+		switch item.Func {
+		case FuncMin:
+			return s.MinTime()
+		case FuncMax:
+			return s.MaxTime()
+		}
+	}
+	if item.Func == FuncCount {
+		return int64(0)
+	}
+	return nil
+}
+
+// sortRows orders the rows by the ORDER BY keys. Missing values sort first.
+func (p *Plan) sortRows(rows [][]any) {
+	if len(p.orderCols) == 0 {
+		return
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		for k, col := range p.orderCols {
+			c := compareValues(rows[a][col], rows[b][col])
+			if p.Statement.OrderBy[k].Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
+
+func compareValues(a, b any) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		}
+		return 1
+	}
+	switch av := a.(type) {
+	case string:
+		return cmp.Compare(av, b.(string))
+	case int64:
+		return cmp.Compare(av, b.(int64))
+	case float64:
+		return cmp.Compare(av, b.(float64))
+	case bool:
+		bv := b.(bool)
+		switch {
+		case av == bv:
+			return 0
+		case !av:
+			return -1
+		}
+		return 1
+	case time.Time:
+		return av.Compare(b.(time.Time))
+	}
+	return 0
+}
+
+// TableColumns returns the columns of a table as result columns, sorted by name
+func TableColumns(table *tables.DataTable) []Column {
+	var cols []Column
+	for _, name := range sortedColumnNames(table) {
+		cols = append(cols, Column{Name: name, Kind: ColumnKind(table.GetColumn(name))})
+	}
+	return cols
+}
+
+func sortedColumnNames(table *tables.DataTable) []string {
+	names := table.GetColumnNames()
+	sort.Strings(names)
+	return names
+}
+
+// ColumnKind returns the kind of a column's values. Columns without a SQL counterpart
+// (e.g., durations) are returned as strings.
+func ColumnKind(col columns.IDataColumn) Kind {
+	switch col.(type) {
+	case interface{ GetValue(uint32) (uint32, error) },
+		interface{ GetValue(uint32) (int64, error) },
+		interface{ GetValue(uint32) (uint64, error) }:
+		return KindInt64
+	case interface{ GetValue(uint32) (float64, error) }:
+		return KindFloat64
+	case interface{ GetValue(uint32) (bool, error) }:
+		return KindBool
+	case interface {
+		GetValue(uint32) (time.Time, error)
+	}:
+		return KindTimestamp
+	}
+	return KindString
+}
+
+// ColumnValue returns the value of a row as the Go type of the column's kind, or nil if
+// the value cannot be read or failed to load
+func ColumnValue(col columns.IDataColumn, row uint32) any {
+	if col.ColumnDef().IsInvalidRow(row) {
+		return nil
+	}
+	var value any
+	var err error
+	switch c := col.(type) {
+	case interface{ GetValue(uint32) (uint32, error) }:
+		var v uint32
+		v, err = c.GetValue(row)
+		value = int64(v)
+	case interface{ GetValue(uint32) (int64, error) }:
+		value, err = c.GetValue(row)
+	case interface{ GetValue(uint32) (uint64, error) }:
+		var v uint64
+		v, err = c.GetValue(row)
+		value = int64(v)
+	case interface{ GetValue(uint32) (float64, error) }:
+		value, err = c.GetValue(row)
+	case interface{ GetValue(uint32) (bool, error) }:
+		value, err = c.GetValue(row)
+	case interface {
+		GetValue(uint32) (time.Time, error)
+	}:
+		value, err = c.GetValue(row)
+	default:
+		value, err = col.GetString(row)
+	}
+	if err != nil {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,114 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlquery
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/taxinomia/core/errs"
+)
+
+// Pushable reports whether the statement can be run at the source of its table instead
+// of on its rows: it groups or aggregates the rows, so the source returns one row per
+// group, and its conditions compare with = or IN. LIKE conditions are not pushed down,
+// since sources match patterns differently from the substring filters of table views.
+func (p *Plan) Pushable() bool {
+	grouped := len(p.Statement.GroupBy) > 0
+	for _, item := range p.items {
+		if item.Func != FuncNone {
+			grouped = true
+		}
+	}
+	if !grouped {
+		return false
+	}
+	for _, cond := range p.Statement.Where {
+		if cond.Op == "LIKE" {
+			return false
+		}
+	}
+	return true
+}
+
+// PushdownStatement returns the statement run at the source: the statement without its
+// ORDER BY and LIMIT clauses, which ExecuteRows applies to the rows of the source.
+func (p *Plan) PushdownStatement() *Statement {
+	stmt := *p.Statement
+	stmt.Items = slices.Clone(p.items)
+	stmt.OrderBy = nil
+	stmt.Limit = 0
+	return &stmt
+}
+
+// ExecuteRows returns the result of the statement from the rows computed at the source of
+// its table for PushdownStatement. Each row has one value per result column, of the Go type
+// of its kind or nil; integers are accepted for DOUBLE columns. The rows are ordered and
+// limited as the statement requires.
+func (p *Plan) ExecuteRows(rows [][]any) (*Result, error) {
+	result := &Result{Columns: p.Columns, PushedDown: true}
+	for i, row := range rows {
+		if len(row) != len(p.Columns) {
+			return nil, errs.New(errs.ErrTypeMismatch, "Row %d of the source has %d values, expected %d", i, len(row), len(p.Columns))
+		}
+		values := make([]any, len(row))
+		for c, value := range row {
+			v, ok := kindValue(p.Columns[c].Kind, value)
+			if !ok {
+				return nil, errs.New(errs.ErrTypeMismatch, "Value %v of column '%s' in row %d of the source is not %s", value, p.Columns[c].Name, i, p.Columns[c].Kind)
+			}
+			values[c] = v
+		}
+		result.Rows = append(result.Rows, values)
+	}
+
+	p.sortRows(result.Rows)
+	if limit := p.Statement.Limit; limit > 0 && len(result.Rows) > limit {
+		result.Rows = result.Rows[:limit]
+	}
+	return result, nil
+}
+
+// kindValue returns a value as the Go type of a kind, and false if it isn't of that kind
+func kindValue(kind Kind, value any) (any, bool) {
+	if value == nil {
+		return nil, true
+	}
+	switch kind {
+	case KindInt64:
+		v, ok := value.(int64)
+		return v, ok
+	case KindFloat64:
+		switch v := value.(type) {
+		case float64:
+			return v, true
+		case int64:
+			return float64(v), true
+		}
+		return nil, false
+	case KindBool:
+		v, ok := value.(bool)
+		return v, ok
+	case KindTimestamp:
+		v, ok := value.(time.Time)
+		return v, ok
+	}
+	v, ok := value.(string)
+	return v, ok
+}
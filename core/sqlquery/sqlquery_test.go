@@ -0,0 +1,197 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlquery_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/sqlquery"
+	"github.com/google/taxinomia/core/testsupport"
+)
+
+func TestParse(t *testing.T) {
+	stmt, err := sqlquery.Parse(`select region, SUM(amount) AS total, count(*) FROM "orders" ` +
+		`WHERE status IN ('shipped', 'pending') AND order_id LIKE '%o%' GROUP BY region ORDER BY total DESC, 1 LIMIT 10;`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := &sqlquery.Statement{
+		Table: "orders",
+		Items: []sqlquery.SelectItem{
+			{Column: "region"},
+			{Column: "amount", Func: sqlquery.FuncSum, Alias: "total"},
+			{Star: true, Func: sqlquery.FuncCount},
+		},
+		Where: []sqlquery.Condition{
+			{Column: "status", Op: "IN", Values: []string{"shipped", "pending"}},
+			{Column: "order_id", Op: "LIKE", Values: []string{"%o%"}},
+		},
+		GroupBy: []string{"region"},
+		OrderBy: []sqlquery.OrderItem{{Name: "total", Desc: true}, {Name: "1"}},
+		Limit:   10,
+	}
+	if !reflect.DeepEqual(stmt, want) {
+		t.Errorf("Parse:\n got %+v\nwant %+v", stmt, want)
+	}
+	if got := stmt.Items[2].Name(); got != "count(*)" {
+		t.Errorf("expected count(*) to be named count(*), got %q", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, sql := range []string{
+		"",
+		"SELECT FROM orders",
+		"SELECT region FROM",
+		"SELECT region FROM orders WHERE amount > 5",
+		"SELECT region FROM orders WHERE region = 'north' OR region = 'south'",
+		"SELECT median(amount) FROM orders",
+		"SELECT region FROM orders LIMIT x",
+		"SELECT region FROM orders WHERE region = 'north",
+		"SELECT region FROM orders JOIN regions",
+	} {
+		if _, err := sqlquery.Parse(sql); !errors.Is(err, errs.ErrInvalidQuery) {
+			t.Errorf("Parse(%q): expected an invalid query error, got %v", sql, err)
+		}
+	}
+}
+
+func TestExecute(t *testing.T) {
+	dm := testsupport.NewDataModel()
+	tests := []struct {
+		sql     string
+		columns []sqlquery.Column
+		rows    [][]any
+	}{
+		{
+			sql:     "SELECT order_id, amount FROM orders WHERE region = 'north' ORDER BY amount DESC LIMIT 2",
+			columns: []sqlquery.Column{{"order_id", sqlquery.KindString}, {"amount", sqlquery.KindInt64}},
+			rows:    [][]any{{"o2", int64(250)}, {"o1", int64(100)}},
+		},
+		{
+			sql:     "SELECT region, SUM(amount) AS total, COUNT(*) FROM orders GROUP BY region ORDER BY total DESC",
+			columns: []sqlquery.Column{{"region", sqlquery.KindString}, {"total", sqlquery.KindFloat64}, {"count(*)", sqlquery.KindInt64}},
+			rows:    [][]any{{"north", 400.0, int64(3)}, {"west", 300.0, int64(1)}, {"south", 200.0, int64(2)}},
+		},
+		{
+			sql:     "SELECT region, status, MAX(amount) FROM orders WHERE status IN ('shipped', 'pending') GROUP BY region, status ORDER BY 1, 2",
+			columns: []sqlquery.Column{{"region", sqlquery.KindString}, {"status", sqlquery.KindString}, {"max(amount)", sqlquery.KindFloat64}},
+			rows:    [][]any{{"north", "pending", 250.0}, {"north", "shipped", 100.0}, {"south", "shipped", 125.0}},
+		},
+		{
+			sql:     "SELECT COUNT(DISTINCT region), AVG(amount), MIN(order_id) FROM orders WHERE status LIKE 'shipped'",
+			columns: []sqlquery.Column{{"count(distinct region)", sqlquery.KindInt64}, {"avg(amount)", sqlquery.KindFloat64}, {"min(order_id)", sqlquery.KindString}},
+			rows:    [][]any{{int64(2), 100.0, "o1"}},
+		},
+		{
+			sql:     "SELECT * FROM regions WHERE name LIKE '%ES%'",
+			columns: []sqlquery.Column{{"name", sqlquery.KindString}, {"population", sqlquery.KindInt64}, {"region", sqlquery.KindString}},
+			rows:    [][]any{{"West", int64(500), "west"}},
+		},
+	}
+	for _, tt := range tests {
+		plan, err := sqlquery.Prepare(dm, tt.sql)
+		if err != nil {
+			t.Errorf("Prepare(%q): %v", tt.sql, err)
+			continue
+		}
+		if !reflect.DeepEqual(plan.Columns, tt.columns) {
+			t.Errorf("%q: expected columns %v, got %v", tt.sql, tt.columns, plan.Columns)
+		}
+		result, err := plan.Execute()
+		if err != nil {
+			t.Errorf("Execute(%q): %v", tt.sql, err)
+			continue
+		}
+		if !reflect.DeepEqual(result.Rows, tt.rows) {
+			t.Errorf("%q: expected rows %v, got %v", tt.sql, tt.rows, result.Rows)
+		}
+	}
+}
+
+func TestPrepareErrors(t *testing.T) {
+	dm := testsupport.NewDataModel()
+	tests := []struct {
+		sql  string
+		kind error
+	}{
+		{"SELECT region FROM customers", errs.ErrUnknownTable},
+		{"SELECT country FROM orders", errs.ErrUnknownColumn},
+		{"SELECT region FROM orders WHERE country = 'x'", errs.ErrUnknownColumn},
+		{"SELECT status, COUNT(*) FROM orders GROUP BY region", errs.ErrInvalidQuery},
+		{"SELECT *, COUNT(*) FROM orders", errs.ErrInvalidQuery},
+		{"SELECT SUM(status) FROM orders", errs.ErrTypeMismatch},
+		{"SELECT region FROM orders WHERE region = 'north' AND region = 'south'", errs.ErrInvalidQuery},
+		{"SELECT region FROM orders WHERE region LIKE 'no%'", errs.ErrInvalidQuery},
+		{"SELECT region FROM orders ORDER BY amount", errs.ErrInvalidQuery},
+	}
+	for _, tt := range tests {
+		if _, err := sqlquery.Prepare(dm, tt.sql); !errors.Is(err, tt.kind) {
+			t.Errorf("Prepare(%q): expected %v, got %v", tt.sql, tt.kind, err)
+		}
+	}
+}
+
+func TestPushdown(t *testing.T) {
+	dm := testsupport.NewDataModel()
+	for sql, want := range map[string]bool{
+		"SELECT region, SUM(amount) FROM orders WHERE status IN ('shipped') GROUP BY region": true,
+		"SELECT COUNT(*) FROM orders":                            true,
+		"SELECT region FROM orders GROUP BY region":              true,
+		"SELECT order_id FROM orders":                            false,
+		"SELECT COUNT(*) FROM orders WHERE status LIKE '%ship%'": false,
+	} {
+		plan, err := sqlquery.Prepare(dm, sql)
+		if err != nil {
+			t.Fatalf("Prepare(%q): %v", sql, err)
+		}
+		if got := plan.Pushable(); got != want {
+			t.Errorf("Pushable(%q) = %v, want %v", sql, got, want)
+		}
+	}
+
+	plan, err := sqlquery.Prepare(dm, "SELECT region, SUM(amount) AS total FROM orders GROUP BY region ORDER BY total DESC LIMIT 2")
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	if stmt := plan.PushdownStatement(); stmt.OrderBy != nil || stmt.Limit != 0 || plan.Statement.Limit != 2 {
+		t.Errorf("expected ORDER BY and LIMIT to be left out of the pushed down statement only, got %+v", stmt)
+	}
+	// Integer sums of the source are accepted for DOUBLE columns
+	result, err := plan.ExecuteRows([][]any{{"north", int64(400)}, {"south", 200.0}, {"west", 300.0}, {nil, nil}})
+	if err != nil {
+		t.Fatalf("ExecuteRows: %v", err)
+	}
+	if want := [][]any{{"north", 400.0}, {"west", 300.0}}; !reflect.DeepEqual(result.Rows, want) || !result.PushedDown {
+		t.Errorf("expected the pushed down rows ordered and limited to %v, got %v", want, result.Rows)
+	}
+
+	for _, rows := range [][][]any{
+		{{"north"}},
+		{{"north", "400"}},
+		{{int64(1), 400.0}},
+	} {
+		if _, err := plan.ExecuteRows(rows); !errors.Is(err, errs.ErrTypeMismatch) {
+			t.Errorf("ExecuteRows(%v): expected a type mismatch, got %v", rows, err)
+		}
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"testing"
 
 	"github.com/google/taxinomia/core/columns"
-	"github.com/google/taxinomia/core/grouping"
+	"github.com/google/taxinomia/core/internal/grouping"
 )
 
 // TestToAsciiDemo demonstrates the ASCII table display with the user's example data
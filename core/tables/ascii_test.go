@@ -5,7 +5,7 @@ import (
 	"testing"
 
 	"github.com/google/taxinomia/core/columns"
-	"github.com/google/taxinomia/core/grouping"
+	"github.com/google/taxinomia/core/internal/grouping"
 )
 
 // TestToAscii demonstrates the ASCII table display
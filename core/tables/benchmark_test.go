@@ -78,7 +78,7 @@ import (
 
 	"github.com/google/taxinomia/core/columns"
 	"github.com/google/taxinomia/core/expr"
-	"github.com/google/taxinomia/core/grouping"
+	"github.com/google/taxinomia/core/internal/grouping"
 )
 
 // createLargeTable creates a table with the specified number of rows and columns
@@ -0,0 +1,95 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tables
+
+import (
+	"sort"
+	"unsafe"
+
+	"github.com/google/taxinomia/core/aggregates"
+	"github.com/google/taxinomia/core/internal/grouping"
+)
+
+// GroupingFootprint is the estimated memory held by the groups of one grouped column
+type GroupingFootprint struct {
+	Column string
+	Groups int
+	Bytes  uint64 // Groups, their row indices, blocks and aggregate states
+}
+
+// CacheFootprint is the estimated memory held by the caches of a table view
+type CacheFootprint struct {
+	FilterMaskBytes uint64 // 0 if no filter is applied
+	Groupings       []GroupingFootprint
+}
+
+// Bytes returns the total memory held by the caches.
+func (f CacheFootprint) Bytes() uint64 {
+	bytes := f.FilterMaskBytes
+	for _, g := range f.Groupings {
+		bytes += g.Bytes
+	}
+	return bytes
+}
+
+// CacheFootprint estimates the memory held by the cached filter mask and grouping
+// structures of the view. Groupings are ordered by grouping level.
+func (t *TableView) CacheFootprint() CacheFootprint {
+	f := CacheFootprint{FilterMaskBytes: uint64(cap(t.filterMask))}
+	for name, gc := range t.groupedColumns {
+		g := GroupingFootprint{Column: name, Groups: gc.GetGroupCount()}
+		for _, block := range gc.Blocks {
+			g.Bytes += uint64(unsafe.Sizeof(grouping.Block{})) + uint64(cap(block.Groups))*uint64(unsafe.Sizeof(&grouping.Group{}))
+			for _, group := range block.Groups {
+				g.Bytes += uint64(unsafe.Sizeof(grouping.Group{})) + uint64(cap(group.Indices))*uint64(unsafe.Sizeof(uint32(0)))
+				for _, state := range group.Aggregates {
+					g.Bytes += aggregateStateBytes(state)
+				}
+			}
+		}
+		f.Groupings = append(f.Groupings, g)
+	}
+	level := make(map[string]int, len(t.groupingOrder))
+	for i, name := range t.groupingOrder {
+		level[name] = i
+	}
+	sort.Slice(f.Groupings, func(i, j int) bool {
+		return level[f.Groupings[i].Column] < level[f.Groupings[j].Column]
+	})
+	return f
+}
+
+// aggregateStateBytes estimates the memory held by an aggregate state and its map entry.
+// Values tracked for unique counts are counted by their headers, as their contents are
+// shared with the column.
+func aggregateStateBytes(state aggregates.AggregateState) uint64 {
+	const entryBytes = uint64(unsafe.Sizeof("") + unsafe.Sizeof(state) + 1)
+	switch s := state.(type) {
+	case *aggregates.NumericAggState:
+		return entryBytes + uint64(unsafe.Sizeof(*s)) + uint64(len(s.Distinct))*uint64(unsafe.Sizeof(float64(0))+1)
+	case *aggregates.StringAggState:
+		return entryBytes + uint64(unsafe.Sizeof(*s)) + uint64(len(s.UniqueSet))*uint64(unsafe.Sizeof("")+1)
+	case *aggregates.BoolAggState:
+		return entryBytes + uint64(unsafe.Sizeof(*s))
+	case *aggregates.DatetimeAggState:
+		return entryBytes + uint64(unsafe.Sizeof(*s))
+	default:
+		return entryBytes
+	}
+}
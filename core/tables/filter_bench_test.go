@@ -213,6 +213,51 @@ func BenchmarkFilterSelectivity(b *testing.B) {
 	})
 }
 
+// BenchmarkFilterPaths compares the typed and string paths of the same filters on the same
+// columns, so the cost of a string fallback reported in _query_perf can be put in numbers.
+func BenchmarkFilterPaths(b *testing.B) {
+	const size = 1000000
+	_, stringView := createStringBenchTable(size)
+	_, uint32View := createUint32BenchTable(size)
+
+	cases := []struct {
+		name   string
+		view   *TableView
+		column string
+		filter string
+	}{
+		{"String_Exact", stringView, "status", `"Active"`},
+		{"String_AnyOf", stringView, "status", "Active|Pending"},
+		{"String_Substring", stringView, "status", "active"},
+		{"Uint32_Exact", uint32View, "amount", `"100"`},
+		{"Uint32_AnyOf", uint32View, "amount", "100|200|300"},
+	}
+	for _, c := range cases {
+		col := c.view.GetColumn(c.column)
+		b.Run(c.name+"/"+FilterPathTyped, func(b *testing.B) {
+			if _, reason := typedFilter(col, c.filter); reason != "" {
+				b.Fatalf("no typed path: %s", reason)
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				typedFilter(col, c.filter)
+			}
+			b.ReportMetric(float64(size)*float64(b.N)/b.Elapsed().Seconds()/1e6, "Mrows/sec")
+		})
+		b.Run(c.name+"/"+FilterPathString, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.view.filterMask = make([]bool, size)
+				for j := range c.view.filterMask {
+					c.view.filterMask[j] = true
+				}
+				c.view.applyStringFilter(col, c.filter)
+			}
+			b.ReportMetric(float64(size)*float64(b.N)/b.Elapsed().Seconds()/1e6, "Mrows/sec")
+		})
+	}
+}
+
 // Helper functions to create benchmark tables
 
 func createStringBenchTable(rows int) (*DataTable, *TableView) {
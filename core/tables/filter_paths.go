@@ -0,0 +1,144 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tables
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/taxinomia/core/columns"
+)
+
+// Paths taken by the filters of ApplyFilters
+const (
+	FilterPathTyped  = "typed"  // Values compared as stored, once per distinct value for dictionary-encoded columns
+	FilterPathString = "string" // Every row value converted to a string and compared
+)
+
+// Kinds of match of a filter value
+const (
+	FilterMatchExact     = "exact"     // Quoted value, matched case-sensitively
+	FilterMatchAnyOf     = "any_of"    // Pipe-separated values, any matched exactly
+	FilterMatchSubstring = "substring" // Value contained in the row value, case-insensitively
+)
+
+// FilterPerf records how one filter of ApplyFilters was evaluated and how long it took
+type FilterPerf struct {
+	Column   string
+	Match    string // FilterMatchExact, FilterMatchAnyOf or FilterMatchSubstring
+	Path     string
+	Reason   string // Why the filter fell back to the string path, empty for the typed path
+	Rows     int    // Rows of the table
+	Matched  int    // Rows passing this filter and the filters applied before it
+	Duration time.Duration
+}
+
+// typedFilter returns the rows of col matching filterValue, compared on the stored values
+// of the column with the same semantics as the string comparison of ApplyFilters. If the
+// filter cannot be evaluated on the stored values, it returns the reason instead.
+func typedFilter(col columns.IDataColumn, filterValue string) ([]int, string) {
+	if len(col.ColumnDef().ValueLabels()) > 0 {
+		return nil, "values have display labels, which match too"
+	}
+
+	// Pipe-separated and quoted values match exactly, others are substrings
+	var exact []string
+	substring := ""
+	switch filterMatch(filterValue) {
+	case FilterMatchAnyOf:
+		exact = strings.Split(filterValue, "|")
+	case FilterMatchExact:
+		exact = []string{filterValue[1 : len(filterValue)-1]}
+	default:
+		substring = strings.ToLower(filterValue)
+	}
+
+	switch c := col.(type) {
+	case *columns.StringColumn:
+		if exact == nil {
+			return c.Filter(func(v string) bool { return strings.Contains(strings.ToLower(v), substring) }), ""
+		}
+		set := make(map[string]bool, len(exact))
+		for _, v := range exact {
+			set[v] = true
+		}
+		return c.Filter(func(v string) bool { return set[v] }), ""
+	case *columns.Uint32Column:
+		if exact == nil {
+			return nil, "substring match on a numeric column"
+		}
+		return c.Filter(exactIntegers(exact, func(s string) (uint32, bool) {
+			v, err := strconv.ParseUint(s, 10, 32)
+			return uint32(v), err == nil && strconv.FormatUint(v, 10) == s
+		})), ""
+	case *columns.Int64Column:
+		if exact == nil {
+			return nil, "substring match on a numeric column"
+		}
+		return c.Filter(exactIntegers(exact, func(s string) (int64, bool) {
+			v, err := strconv.ParseInt(s, 10, 64)
+			return v, err == nil && strconv.FormatInt(v, 10) == s
+		})), ""
+	case *columns.Uint64Column:
+		if exact == nil {
+			return nil, "substring match on a numeric column"
+		}
+		return c.Filter(exactIntegers(exact, func(s string) (uint64, bool) {
+			v, err := strconv.ParseUint(s, 10, 64)
+			return v, err == nil && strconv.FormatUint(v, 10) == s
+		})), ""
+	case *columns.Float64Column:
+		return nil, "no typed comparison for float64 columns"
+	case *columns.BoolColumn:
+		return nil, "no typed comparison for bool columns"
+	case *columns.DatetimeColumn:
+		return nil, "no typed comparison for datetime columns"
+	case *columns.DurationColumn:
+		return nil, "no typed comparison for duration columns"
+	case columns.IJoinedDataColumn:
+		return nil, "joined column, values are looked up in another table"
+	default:
+		return nil, "computed column, values are computed for every row"
+	}
+}
+
+// filterMatch returns how a filter value matches row values
+func filterMatch(filterValue string) string {
+	switch {
+	case strings.Contains(filterValue, "|"):
+		return FilterMatchAnyOf
+	case len(filterValue) >= 2 && filterValue[0] == '"' && filterValue[len(filterValue)-1] == '"':
+		return FilterMatchExact
+	default:
+		return FilterMatchSubstring
+	}
+}
+
+// exactIntegers returns a predicate matching the integers written as values. Values that
+// parse only from a non-canonical form (e.g. "007") match no row, like their strings.
+func exactIntegers[T comparable](values []string, parse func(string) (T, bool)) func(T) bool {
+	set := make(map[T]bool, len(values))
+	for _, s := range values {
+		if v, ok := parse(s); ok {
+			set[v] = true
+		}
+	}
+	return func(v T) bool { return set[v] }
+}
@@ -0,0 +1,166 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tables
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/taxinomia/core/columns"
+)
+
+// filterPathsView returns a view over 2000 rows, enough for the status column to be
+// dictionary-encoded and the amount column delta-encoded.
+func filterPathsView() *TableView {
+	statuses := []string{"Shipped", "pending", "cancelled", "shipped"}
+	status := columns.NewStringColumn(columns.NewColumnDef("status", "Status", ""))
+	amount := columns.NewUint32Column(columns.NewColumnDef("amount", "Amount", ""))
+	price := columns.NewFloat64Column(columns.NewColumnDef("price", "Price", ""))
+	for i := range 2000 {
+		status.Append(statuses[i%len(statuses)])
+		amount.Append(uint32(i % 10))
+		price.Append(float64(i%10) / 2)
+	}
+	status.FinalizeColumn()
+	amount.FinalizeColumn()
+	price.FinalizeColumn()
+
+	table := NewDataTable()
+	table.AddColumn(status)
+	table.AddColumn(amount)
+	table.AddColumn(price)
+	return NewTableView(table, "orders")
+}
+
+func TestFilterPaths(t *testing.T) {
+	view := filterPathsView()
+
+	tests := []struct {
+		column, filter string
+		path, match    string
+		want           int
+	}{
+		{"status", "shipped", FilterPathTyped, FilterMatchSubstring, 1000},
+		{"status", `"shipped"`, FilterPathTyped, FilterMatchExact, 500},
+		{"status", "pending|cancelled", FilterPathTyped, FilterMatchAnyOf, 1000},
+		{"amount", `"7"`, FilterPathTyped, FilterMatchExact, 200},
+		{"amount", "1|2|x", FilterPathTyped, FilterMatchAnyOf, 400},
+		{"amount", `"007"`, FilterPathTyped, FilterMatchExact, 0}, // the string of 7 is "7"
+		{"amount", "7", FilterPathString, FilterMatchSubstring, 200},
+		{"price", "3.5", FilterPathString, FilterMatchSubstring, 200},
+	}
+	for _, tt := range tests {
+		view.ApplyFilters(map[string]string{tt.column: tt.filter})
+		if got := view.GetFilteredRowCount(); got != tt.want {
+			t.Errorf("filter %s=%s matched %d rows, want %d", tt.column, tt.filter, got, tt.want)
+		}
+		perf := view.TakeFilterPerf()
+		if len(perf) != 1 {
+			t.Fatalf("filter %s=%s recorded %d filters, want 1", tt.column, tt.filter, len(perf))
+		}
+		p := perf[0]
+		if p.Path != tt.path || p.Match != tt.match || p.Rows != 2000 || p.Matched != tt.want {
+			t.Errorf("filter %s=%s recorded %+v, want %s path with %s match", tt.column, tt.filter, p, tt.path, tt.match)
+		}
+		if (p.Reason == "") != (p.Path == FilterPathTyped) {
+			t.Errorf("filter %s=%s reason = %q on the %s path", tt.column, tt.filter, p.Reason, p.Path)
+		}
+	}
+
+	// Reusing the cached mask records nothing
+	view.ApplyFilters(map[string]string{"price": "3.5"})
+	if perf := view.TakeFilterPerf(); perf != nil {
+		t.Errorf("cached filter recorded %+v", perf)
+	}
+}
+
+func TestFilterPathsMatchStringPath(t *testing.T) {
+	view := filterPathsView()
+	for _, filter := range []string{"ship", "SHIP", `"Shipped"`, `""`, "shipped|pending", "|", "x"} {
+		view.ApplyFilters(map[string]string{"status": filter})
+		typed := view.GetFilteredIndices()
+
+		want := 0
+		exact := strings.Trim(filter, `"`)
+		for i := range uint32(2000) {
+			v, _ := view.GetColumn("status").GetString(i)
+			switch {
+			case strings.Contains(filter, "|"):
+				for _, f := range strings.Split(filter, "|") {
+					if v == f {
+						want++
+						break
+					}
+				}
+			case strings.HasPrefix(filter, `"`):
+				if v == exact {
+					want++
+				}
+			case strings.Contains(strings.ToLower(v), strings.ToLower(filter)):
+				want++
+			}
+		}
+		if len(typed) != want {
+			t.Errorf("filter %s matched %d rows, string comparison matches %d", filter, len(typed), want)
+		}
+	}
+}
+
+func TestFilterPerfMultipleFilters(t *testing.T) {
+	view := filterPathsView()
+	view.ApplyFilters(map[string]string{"status": `"shipped"`, "amount": `"3"`, "price": "1.5"})
+	perf := view.TakeFilterPerf()
+	var got []string
+	for _, p := range perf {
+		got = append(got, fmt.Sprintf("%s:%s", p.Column, p.Path))
+	}
+	if strings.Join(got, ",") != "amount:typed,price:string,status:typed" {
+		t.Errorf("recorded %v, want one entry per filter sorted by column", got)
+	}
+
+	// Matched counts narrow down in the order the filters were applied, to the final count
+	fewest := perf[0].Matched
+	for _, p := range perf {
+		fewest = min(fewest, p.Matched)
+	}
+	if fewest != view.GetFilteredRowCount() {
+		t.Errorf("fewest matched rows = %d, want the %d filtered rows", fewest, view.GetFilteredRowCount())
+	}
+}
+
+func TestTakeRowsScanned(t *testing.T) {
+	view := filterPathsView()
+	view.ApplyFilters(map[string]string{"status": `"shipped"`, "amount": `"3"`})
+	view.GroupTable([]string{"price"}, nil, make(map[string]Compare), make(map[string]bool))
+
+	// Each filter reads the whole table and the grouping reads the matching rows
+	if got, want := view.TakeRowsScanned(), 2*2000+view.GetFilteredRowCount(); got != want {
+		t.Errorf("rows scanned = %d, want %d", got, want)
+	}
+	if got := view.TakeRowsScanned(); got != 0 {
+		t.Errorf("rows scanned after taking = %d, want 0", got)
+	}
+
+	// Reusing the cached mask reads nothing
+	view.ApplyFilters(map[string]string{"status": `"shipped"`, "amount": `"3"`})
+	if got := view.TakeRowsScanned(); got != 0 {
+		t.Errorf("cached filters scanned %d rows, want 0", got)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tables
+
+// Thresholds above which a joined column is reported as skewed
+const (
+	// JoinSkewShare is the share of matched rows mapping to a single target row
+	// above which a join is considered skewed
+	JoinSkewShare = 0.5
+	// JoinSkewMinTargets is the number of distinct target rows a join must reach
+	// before it can be considered skewed. Lookups into small tables (e.g., a few
+	// statuses) are naturally concentrated.
+	JoinSkewMinTargets = 10
+)
+
+// JoinSkew describes how concentrated the rows of a joined column are on a single target row
+type JoinSkew struct {
+	Matched  int    // Rows of the view that matched a target row
+	Targets  int    // Distinct target rows matched
+	TopCount int    // Rows mapping to the most common target row
+	TopRow   uint32 // A row of the view mapping to the most common target row
+}
+
+// Share returns the share of matched rows mapping to the most common target row
+func (s JoinSkew) Share() float64 {
+	if s.Matched == 0 {
+		return 0
+	}
+	return float64(s.TopCount) / float64(s.Matched)
+}
+
+// IsSkewed returns true if a single target row attracts a suspicious share of the matched rows
+func (s JoinSkew) IsSkewed() bool {
+	return s.Targets >= JoinSkewMinTargets && s.Share() > JoinSkewShare
+}
+
+// JoinSkew measures how the rows of the view spread over the target rows of a joined
+// column. The result is cached until the joined column is removed.
+// Returns false if the column is not a joined column of the view.
+func (tv *TableView) JoinSkew(name string) (JoinSkew, bool) {
+	col, ok := tv.joins[name]
+	if !ok {
+		return JoinSkew{}, false
+	}
+	if skew, ok := tv.joinSkews[name]; ok {
+		return skew, true
+	}
+
+	var skew JoinSkew
+	counts := make(map[uint32]int)
+	joiner := col.Joiner()
+	for i := 0; i < tv.NumRows(); i++ {
+		target, err := joiner.Lookup(uint32(i))
+		if err != nil {
+			continue
+		}
+		skew.Matched++
+		counts[target]++
+		if counts[target] > skew.TopCount {
+			skew.TopCount = counts[target]
+			skew.TopRow = uint32(i)
+		}
+	}
+	skew.Targets = len(counts)
+	tv.joinSkews[name] = skew
+	return skew, true
+}
@@ -0,0 +1,78 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tables
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/taxinomia/core/columns"
+)
+
+// hostJoinView returns a view of events joined to a hosts table of 20 hosts,
+// where the given number of events (out of 40) belong to host h0.
+func hostJoinView(t *testing.T, onFirstHost int) (*TableView, string) {
+	t.Helper()
+	hosts := columns.NewStringColumn(columns.NewColumnDef("host", "Host", "host"))
+	for i := 0; i < 20; i++ {
+		hosts.Append(fmt.Sprintf("h%d", i))
+	}
+	hosts.FinalizeColumn()
+
+	events := columns.NewStringColumn(columns.NewColumnDef("host", "Host", "host"))
+	for i := 0; i < 40; i++ {
+		if i < onFirstHost {
+			events.Append("h0")
+		} else {
+			events.Append(fmt.Sprintf("h%d", i%20))
+		}
+	}
+	events.FinalizeColumn()
+	table := NewDataTable()
+	table.AddColumn(events)
+
+	tv := NewTableView(table, "events")
+	joined := "host.hosts.host.host"
+	tv.AddJoinedColumn(hosts.CreateJoinedColumn(columns.NewColumnDef(joined, joined, ""), &columns.Joiner[string]{FromColumn: events, ToColumn: hosts}))
+	return tv, joined
+}
+
+func TestJoinSkew(t *testing.T) {
+	tv, joined := hostJoinView(t, 30)
+	skew, ok := tv.JoinSkew(joined)
+	if !ok {
+		t.Fatalf("expected %s to be a joined column", joined)
+	}
+	// 30 events on h0, the other 10 on h10 to h19
+	if skew.Matched != 40 || skew.TopCount != 30 || skew.Targets != 11 || !skew.IsSkewed() {
+		t.Errorf("expected a skewed join, got %+v", skew)
+	}
+	if v, _ := tv.GetColumn(joined).GetString(skew.TopRow); v != "h0" {
+		t.Errorf("expected the top row to join to h0, got %q", v)
+	}
+
+	tv, joined = hostJoinView(t, 0)
+	if skew, _ := tv.JoinSkew(joined); skew.IsSkewed() {
+		t.Errorf("expected an evenly spread join not to be skewed, got %+v", skew)
+	}
+
+	if _, ok := tv.JoinSkew("host"); ok {
+		t.Errorf("expected no skew for a base column")
+	}
+}
@@ -0,0 +1,129 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tables
+
+import "strings"
+
+// ColumnRef identifies a column of a table
+type ColumnRef struct {
+	Table  string
+	Column string
+}
+
+// TableName returns the name of the table the view is over
+func (tv *TableView) TableName() string {
+	return tv.tableName
+}
+
+// SetComputedColumnLineage records the columns of the view a computed column is computed from
+func (tv *TableView) SetComputedColumnLineage(name string, sources []string) {
+	tv.computedLineage[name] = sources
+}
+
+// Lineage returns the columns a derived column of the view reads directly:
+// the columns referenced by a computed column, or the columns a joined column
+// passes through (the join keys of each hop and the selected column).
+// Returns nil for base table columns and unknown columns.
+func (tv *TableView) Lineage(name string) []ColumnRef {
+	if sources, ok := tv.computedLineage[name]; ok {
+		refs := make([]ColumnRef, len(sources))
+		for i, source := range sources {
+			refs[i] = ColumnRef{Table: tv.tableName, Column: source}
+		}
+		return refs
+	}
+	if _, ok := tv.joins[name]; !ok {
+		return nil
+	}
+
+	// Format: fromColumn.toTable.toColumn[.fromColumn2.toTable2.toColumn2...].selectedColumn
+	parts := strings.Split(name, ".")
+	var refs []ColumnRef
+	table := tv.tableName
+	for hop := 0; hop+3 < len(parts); hop += 3 {
+		refs = append(refs,
+			ColumnRef{Table: table, Column: parts[hop]},
+			ColumnRef{Table: parts[hop+1], Column: parts[hop+2]})
+		table = parts[hop+1]
+	}
+	return append(refs, ColumnRef{Table: table, Column: parts[len(parts)-1]})
+}
+
+// SetBaseTable replaces the underlying table of the view, e.g. after it was reloaded.
+// Derived columns are kept; use InvalidateColumns to drop those whose sources changed.
+func (tv *TableView) SetBaseTable(table *DataTable) {
+	tv.baseTable = table
+	tv.resetCaches()
+}
+
+// InvalidateColumns removes the computed and joined columns of the view that
+// depend, directly or through other derived columns, on any of the changed
+// columns, so that they are recomputed when next requested. Returns the names
+// of the removed columns.
+func (tv *TableView) InvalidateColumns(changed []ColumnRef) []string {
+	affected := make(map[ColumnRef]bool, len(changed))
+	for _, ref := range changed {
+		affected[ref] = true
+	}
+
+	derived := make([]string, 0, len(tv.joins)+len(tv.computedColumns))
+	for name := range tv.joins {
+		derived = append(derived, name)
+	}
+	for name := range tv.computedColumns {
+		derived = append(derived, name)
+	}
+
+	// Propagate through the lineage graph until no more columns are affected
+	var invalidated []string
+	for progress := true; progress; {
+		progress = false
+		for _, name := range derived {
+			self := ColumnRef{Table: tv.tableName, Column: name}
+			if affected[self] {
+				continue
+			}
+			for _, source := range tv.Lineage(name) {
+				if affected[source] {
+					affected[self] = true
+					invalidated = append(invalidated, name)
+					progress = true
+					break
+				}
+			}
+		}
+	}
+
+	for _, name := range invalidated {
+		tv.RemoveJoinedColumn(name)
+		tv.RemoveComputedColumn(name)
+	}
+	if len(invalidated) > 0 {
+		tv.resetCaches()
+	}
+	return invalidated
+}
+
+// resetCaches drops the cached filter mask and grouping, which may have been
+// computed from data that changed
+func (tv *TableView) resetCaches() {
+	tv.filterMask = nil
+	tv.lastFilters = nil
+	tv.ClearGroupings()
+}
@@ -0,0 +1,76 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tables
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/taxinomia/core/columns"
+)
+
+func TestInvalidateColumnsFollowsLineage(t *testing.T) {
+	table := NewDataTable()
+	amount := columns.NewUint32Column(columns.NewColumnDef("amount", "Amount", ""))
+	region := columns.NewStringColumn(columns.NewColumnDef("region", "Region", ""))
+	for _, r := range []string{"north", "south"} {
+		amount.Append(10)
+		region.Append(r)
+	}
+	amount.FinalizeColumn()
+	region.FinalizeColumn()
+	table.AddColumn(amount)
+	table.AddColumn(region)
+
+	tv := NewTableView(table, "orders")
+	joined := "region.regions.region.name"
+	tv.AddJoinedColumn(columns.NewJoinedStringColumn(columns.NewColumnDef(joined, joined, ""), &columns.Joiner[string]{FromColumn: region, ToColumn: region}, region))
+	for name, sources := range map[string][]string{
+		"double":       {"amount"},
+		"double_plus":  {"double"},
+		"region_label": {joined},
+		"constant":     nil,
+	} {
+		tv.AddComputedColumn(name, nil)
+		tv.SetComputedColumnLineage(name, sources)
+	}
+
+	got := tv.InvalidateColumns([]ColumnRef{{Table: "orders", Column: "amount"}})
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"double", "double_plus"}) {
+		t.Errorf("amount change invalidated %v, want double and double_plus", got)
+	}
+	if tv.GetColumn("double_plus") != nil || tv.GetColumn(joined) == nil {
+		t.Errorf("expected only dependents of amount to be removed")
+	}
+
+	// Joined columns depend on the columns of the joined table they read
+	got = tv.InvalidateColumns([]ColumnRef{{Table: "regions", Column: "population"}})
+	if len(got) != 0 {
+		t.Errorf("unrelated change invalidated %v", got)
+	}
+	got = tv.InvalidateColumns([]ColumnRef{{Table: "regions", Column: "name"}})
+	slices.Sort(got)
+	if !slices.Equal(got, []string{joined, "region_label"}) {
+		t.Errorf("regions.name change invalidated %v, want the joined column and region_label", got)
+	}
+	if _, ok := tv.computedColumns["constant"]; !ok {
+		t.Errorf("expected constant column to be kept")
+	}
+}
@@ -0,0 +1,176 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tables
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/internal/grouping"
+)
+
+// PrecomputedGrouping is a grouping of all rows of a table built ahead of requests,
+// e.g. right after the table loads. Views over the same table reuse it for unfiltered
+// groupings by the same columns and sort directions instead of grouping the rows. Each
+// view gets its own copy of the groups, so that views never see each other's
+// aggregates or sort orders; only the row indices of the groups are shared.
+type PrecomputedGrouping struct {
+	table          *DataTable
+	key            string
+	groupingOrder  []string
+	groupedColumns map[string]*grouping.GroupedColumn
+	firstBlock     *grouping.Block
+}
+
+// PrecomputeGrouping groups all rows of a table by the given columns, outermost first.
+// Columns missing from asc are sorted ascending. Only columns of the table itself can
+// be grouped: joined and computed columns belong to individual views.
+func PrecomputeGrouping(table *DataTable, groupingOrder []string, asc map[string]bool) (*PrecomputedGrouping, error) {
+	if len(groupingOrder) == 0 {
+		return nil, errs.New(errs.ErrInvalidQuery, "no columns to group by")
+	}
+	for _, col := range groupingOrder {
+		if table.GetColumn(col) == nil {
+			return nil, errs.New(errs.ErrUnknownColumn, "column '%s' not found", col)
+		}
+	}
+
+	tv := NewTableView(table, "")
+	tv.groupRows(groupingOrder, asc, 0)
+
+	return &PrecomputedGrouping{
+		table:          table,
+		key:            groupingKey(groupingOrder, asc),
+		groupingOrder:  slices.Clone(groupingOrder),
+		groupedColumns: tv.groupedColumns,
+		firstBlock:     tv.firstBlock,
+	}, nil
+}
+
+// Columns returns the grouped columns, outermost first
+func (p *PrecomputedGrouping) Columns() []string {
+	return p.groupingOrder
+}
+
+// Table returns the table the grouping was computed over
+func (p *PrecomputedGrouping) Table() *DataTable {
+	return p.table
+}
+
+// GroupCount returns the number of groups of the outermost column
+func (p *PrecomputedGrouping) GroupCount() int {
+	return len(p.firstBlock.Groups)
+}
+
+// SetPrecomputedGroupings sets the precomputed groupings the view may reuse. Groupings
+// of other tables, such as a previous version of the view's table, are never used.
+func (t *TableView) SetPrecomputedGroupings(groupings []*PrecomputedGrouping) {
+	t.precomputed = groupings
+}
+
+// matchingPrecomputedGrouping returns the precomputed grouping that can stand in for
+// grouping the view by groupingOrder, or nil. Filtered rows and cohorts change the
+// groups, so only unfiltered views without cohorts can reuse one.
+func (t *TableView) matchingPrecomputedGrouping(groupingOrder []string, asc map[string]bool) *PrecomputedGrouping {
+	if len(t.precomputed) == 0 || t.filterMask != nil || len(t.cohorts) > 0 {
+		return nil
+	}
+	key := groupingKey(groupingOrder, asc)
+	for _, p := range t.precomputed {
+		if p.table == t.baseTable && p.key == key && t.groupsBaseColumns(groupingOrder) {
+			return p
+		}
+	}
+	return nil
+}
+
+// groupsBaseColumns reports whether the view resolves each column to the table's own
+// column rather than to a joined or computed column of the same name.
+func (t *TableView) groupsBaseColumns(cols []string) bool {
+	for _, col := range cols {
+		if t.GetColumn(col) != t.baseTable.GetColumn(col) {
+			return false
+		}
+	}
+	return true
+}
+
+// restoreGrouping makes a copy of a precomputed grouping the current grouping of the
+// view. The view computes aggregates into its groups and sorts its blocks, which must
+// not change the precomputed grouping that other views, possibly on other goroutines,
+// copy and read.
+func (t *TableView) restoreGrouping(p *PrecomputedGrouping, groupingOrder []string) {
+	columns := make(map[*grouping.GroupedColumn]*grouping.GroupedColumn, len(p.groupedColumns))
+	t.groupedColumns = make(map[string]*grouping.GroupedColumn, len(p.groupedColumns))
+	for name, gc := range p.groupedColumns {
+		clone := &grouping.GroupedColumn{
+			DataColumn: gc.DataColumn,
+			ColumnView: t.columnViews[name],
+			Level:      gc.Level,
+			Blocks:     make([]*grouping.Block, 0, len(gc.Blocks)),
+			Tag:        gc.Tag,
+		}
+		columns[gc] = clone
+		t.groupedColumns[name] = clone
+	}
+	t.firstBlock = cloneBlock(p.firstBlock, nil, columns)
+	t.groupingOrder = groupingOrder
+}
+
+// cloneBlock copies a block and the groups below it, without their aggregates. The
+// copies are appended to the blocks of the copied grouped columns in the order of a
+// depth-first walk, which is the order the blocks were created in.
+func cloneBlock(b *grouping.Block, parent *grouping.Group, columns map[*grouping.GroupedColumn]*grouping.GroupedColumn) *grouping.Block {
+	clone := &grouping.Block{
+		Groups:        make([]*grouping.Group, len(b.Groups)),
+		ParentGroup:   parent,
+		GroupedColumn: columns[b.GroupedColumn],
+	}
+	clone.GroupedColumn.Blocks = append(clone.GroupedColumn.Blocks, clone)
+	for i, g := range b.Groups {
+		group := &grouping.Group{
+			GroupKey:    g.GroupKey,
+			Indices:     g.Indices,
+			ParentGroup: parent,
+			Block:       clone,
+			IsComplete:  g.IsComplete,
+		}
+		if g.ChildBlock != nil {
+			group.ChildBlock = cloneBlock(g.ChildBlock, group, columns)
+		}
+		clone.Groups[i] = group
+	}
+	return clone
+}
+
+// groupingKey identifies a grouping by its columns and their sort directions
+func groupingKey(groupingOrder []string, asc map[string]bool) string {
+	var b strings.Builder
+	for _, col := range groupingOrder {
+		if ascending, ok := asc[col]; ok && !ascending {
+			b.WriteByte('-')
+		} else {
+			b.WriteByte('+')
+		}
+		b.WriteString(col)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
@@ -0,0 +1,170 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tables
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/internal/grouping"
+	"github.com/google/taxinomia/core/query"
+)
+
+func TestPrecomputedGroupingIsReused(t *testing.T) {
+	groupingOrder := []string{"region", "status"}
+	want := make(map[string]float64)
+	eager := amountsView()
+	eager.GroupTable(groupingOrder, nil, make(map[string]Compare), make(map[string]bool))
+	groupSums(t, eager.firstBlock, "", want)
+
+	tv := amountsView()
+	p, err := PrecomputeGrouping(tv.baseTable, groupingOrder, nil)
+	if err != nil {
+		t.Fatalf("PrecomputeGrouping failed: %v", err)
+	}
+	if p.GroupCount() != 3 {
+		t.Errorf("expected 3 region groups, got %d", p.GroupCount())
+	}
+	tv.SetPrecomputedGroupings([]*PrecomputedGrouping{p})
+
+	// Explicitly ascending sorts match the precomputed default
+	tv.GroupTable(groupingOrder, nil, make(map[string]Compare), map[string]bool{"region": true, "status": true})
+	if !reusesGrouping(tv, p) {
+		t.Fatal("expected the precomputed groups to be reused")
+	}
+	got := make(map[string]float64)
+	groupSums(t, tv.firstBlock, "", got)
+	if !maps.Equal(got, want) {
+		t.Errorf("sums of reused groups = %v, want %v", got, want)
+	}
+}
+
+// reusesGrouping reports whether the view groups its rows with a copy of p: its own
+// groups over the row indices of p, in any order.
+func reusesGrouping(tv *TableView, p *PrecomputedGrouping) bool {
+	if tv.firstBlock == nil || tv.firstBlock == p.firstBlock || len(tv.firstBlock.Groups) != len(p.firstBlock.Groups) {
+		return false
+	}
+	rows := make(map[*uint32]*grouping.Group)
+	for _, g := range p.firstBlock.Groups {
+		rows[&g.Indices[0]] = g
+	}
+	for _, g := range tv.firstBlock.Groups {
+		if shared, ok := rows[&g.Indices[0]]; !ok || shared == g {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPrecomputedGroupingIsCopiedPerView(t *testing.T) {
+	groupingOrder := []string{"region"}
+	amounts := amountsView()
+	p, err := PrecomputeGrouping(amounts.baseTable, groupingOrder, nil)
+	if err != nil {
+		t.Fatalf("PrecomputeGrouping failed: %v", err)
+	}
+	amounts.VisibleColumns = []string{"region", "amount"}
+	amounts.SetPrecomputedGroupings([]*PrecomputedGrouping{p})
+	statuses := NewTableView(amounts.baseTable, "statuses")
+	statuses.VisibleColumns = []string{"region", "status"}
+	statuses.SetPrecomputedGroupings([]*PrecomputedGrouping{p})
+
+	// Views with different leaf columns alternate on the same precomputed grouping, and
+	// the first view sorts its groups by an aggregate
+	amounts.GroupTable(groupingOrder, nil, make(map[string]Compare), make(map[string]bool))
+	amounts.SortGroupsByAggregate(map[string]*query.GroupAggSort{
+		"region": {GroupedColumn: "region", LeafColumn: "amount", AggType: query.AggSum, Descending: true},
+	})
+	statuses.GroupTable(groupingOrder, nil, make(map[string]Compare), make(map[string]bool))
+	amounts.GroupTable(groupingOrder, nil, make(map[string]Compare), make(map[string]bool))
+	if !reusesGrouping(amounts, p) || !reusesGrouping(statuses, p) {
+		t.Fatal("expected both views to reuse the precomputed groups")
+	}
+
+	sums := make(map[string]float64)
+	groupSums(t, amounts.firstBlock, "", sums)
+	if want := map[string]float64{"/r0": 570, "/r1": 590, "/r2": 610}; !maps.Equal(sums, want) {
+		t.Errorf("sums = %v, want %v", sums, want)
+	}
+	for _, g := range statuses.firstBlock.Groups {
+		if _, ok := g.Aggregates["amount"]; ok {
+			t.Errorf("group %s of the status view has an amount aggregate", g.GetValue())
+		}
+	}
+	for _, g := range p.firstBlock.Groups {
+		if g.Aggregates != nil {
+			t.Errorf("precomputed group %s has aggregates", g.GetValue())
+		}
+	}
+	for _, tt := range []struct {
+		name  string
+		block *grouping.Block
+		want  []string
+	}{
+		{"amount view", amounts.firstBlock, []string{"r2", "r1", "r0"}},
+		{"status view", statuses.firstBlock, []string{"r0", "r1", "r2"}},
+		{"precomputed grouping", p.firstBlock, []string{"r0", "r1", "r2"}},
+	} {
+		var values []string
+		for _, g := range tt.block.Groups {
+			values = append(values, g.GetValue())
+		}
+		if !slices.Equal(values, tt.want) {
+			t.Errorf("%s: groups in order %v, want %v", tt.name, values, tt.want)
+		}
+	}
+}
+
+func TestPrecomputedGroupingIsNotReused(t *testing.T) {
+	groupingOrder := []string{"region"}
+	tests := []struct {
+		name  string
+		setup func(tv *TableView)
+		asc   map[string]bool
+	}{
+		{"filtered view", func(tv *TableView) { tv.ApplyFilters(map[string]string{"status": "s1"}) }, nil},
+		{"other sort direction", func(tv *TableView) {}, map[string]bool{"region": false}},
+		{"other table", func(tv *TableView) { tv.baseTable = amountsView().baseTable }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tv := amountsView()
+			p, err := PrecomputeGrouping(tv.baseTable, groupingOrder, nil)
+			if err != nil {
+				t.Fatalf("PrecomputeGrouping failed: %v", err)
+			}
+			tv.SetPrecomputedGroupings([]*PrecomputedGrouping{p})
+			tt.setup(tv)
+			tv.GroupTable(groupingOrder, nil, make(map[string]Compare), tt.asc)
+			if reusesGrouping(tv, p) {
+				t.Error("expected the rows to be grouped again")
+			}
+		})
+	}
+}
+
+func TestPrecomputeGroupingUnknownColumn(t *testing.T) {
+	if _, err := PrecomputeGrouping(amountsView().baseTable, []string{"missing"}, nil); !errors.Is(err, errs.ErrUnknownColumn) {
+		t.Errorf("expected an unknown column error, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tables
+
+import (
+	"sync"
+
+	"github.com/google/taxinomia/core/internal/grouping"
+)
+
+// GroupTableDeferringAggregates groups the table like GroupTableWithLimit but leaves the
+// aggregates to ComputeAggregatesProgressively, so that groups can be rendered before their
+// aggregates are known. If the grouping is unchanged, its aggregates are already computed
+// and AggregatesPending reports false.
+func (t *TableView) GroupTableDeferringAggregates(groupingOrder []string, asc map[string]bool, displayLimit int) {
+	if t.groupingEqual(groupingOrder, asc) {
+		return
+	}
+	t.groupRows(groupingOrder, asc, displayLimit)
+	t.aggregatesPending = true
+}
+
+// AggregatesPending reports whether the groups were built without their aggregates.
+func (t *TableView) AggregatesPending() bool {
+	return t.aggregatesPending
+}
+
+// ComputeAggregatesProgressively computes the pending aggregates of each top-level group
+// and its descendants, using up to workers goroutines. done is called on the calling
+// goroutine for each top-level group as soon as its aggregates are complete, in completion
+// order. It returns once all aggregates are computed.
+//
+// Workers only read column data and write the aggregates of the groups they own, so the
+// aggregates of a group handed to done are not modified afterwards.
+func (t *TableView) ComputeAggregatesProgressively(workers int, done func(group *grouping.Group)) {
+	if !t.aggregatesPending {
+		return
+	}
+	t.aggregatesPending = false
+	leafColumns, columnTypes := t.leafColumnTypes()
+	if t.firstBlock == nil || len(leafColumns) == 0 {
+		return
+	}
+
+	groups := t.firstBlock.Groups
+	work := make(chan *grouping.Group)
+	finished := make(chan *grouping.Group)
+	var wg sync.WaitGroup
+	for w := 0; w < max(1, min(workers, len(groups))); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range work {
+				t.computeGroupAggregates(group, leafColumns, columnTypes)
+				finished <- group
+			}
+		}()
+	}
+	go func() {
+		for _, group := range groups {
+			work <- group
+		}
+		close(work)
+		wg.Wait()
+		close(finished)
+	}()
+
+	for group := range finished {
+		if done != nil {
+			done(group)
+		}
+	}
+}
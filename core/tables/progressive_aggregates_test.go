@@ -0,0 +1,118 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tables
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/taxinomia/core/aggregates"
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/internal/grouping"
+)
+
+// amountsView returns a view of 60 rows over 3 regions and 4 statuses with an amount column.
+func amountsView() *TableView {
+	region := columns.NewStringColumn(columns.NewColumnDef("region", "Region", ""))
+	status := columns.NewStringColumn(columns.NewColumnDef("status", "Status", ""))
+	amount := columns.NewUint32Column(columns.NewColumnDef("amount", "Amount", ""))
+	for i := 0; i < 60; i++ {
+		region.Append(fmt.Sprintf("r%d", i%3))
+		status.Append(fmt.Sprintf("s%d", i%4))
+		amount.Append(uint32(i))
+	}
+	region.FinalizeColumn()
+	status.FinalizeColumn()
+	amount.FinalizeColumn()
+	table := NewDataTable()
+	table.AddColumn(region)
+	table.AddColumn(status)
+	table.AddColumn(amount)
+
+	tv := NewTableView(table, "amounts")
+	tv.VisibleColumns = []string{"region", "status", "amount"}
+	return tv
+}
+
+// groupSums collects the amount sums of all groups by their path of values.
+func groupSums(t *testing.T, block *grouping.Block, prefix string, sums map[string]float64) {
+	t.Helper()
+	for _, group := range block.Groups {
+		path := prefix + "/" + group.GetValue()
+		state, ok := group.Aggregates["amount"].(*aggregates.NumericAggState)
+		if !ok {
+			t.Fatalf("group %s has no amount aggregate", path)
+		}
+		sums[path] = state.Sum
+		if group.ChildBlock != nil {
+			groupSums(t, group.ChildBlock, path, sums)
+		}
+	}
+}
+
+func TestComputeAggregatesProgressively(t *testing.T) {
+	groupingOrder := []string{"region", "status"}
+	want := make(map[string]float64)
+	eager := amountsView()
+	eager.GroupTable(groupingOrder, nil, make(map[string]Compare), make(map[string]bool))
+	groupSums(t, eager.firstBlock, "", want)
+
+	tv := amountsView()
+	tv.GroupTableDeferringAggregates(groupingOrder, make(map[string]bool), 0)
+	if !tv.AggregatesPending() {
+		t.Fatal("expected aggregates to be pending")
+	}
+	if tv.firstBlock.Groups[0].Aggregates != nil {
+		t.Fatal("expected groups without aggregates before computing them")
+	}
+
+	done := make(map[string]int)
+	tv.ComputeAggregatesProgressively(2, func(group *grouping.Group) {
+		done[group.GetValue()]++
+		if group.Aggregates == nil || group.ChildBlock.Groups[0].Aggregates == nil {
+			t.Errorf("group %s handed over before its aggregates were computed", group.GetValue())
+		}
+	})
+	if len(done) != 3 || done["r0"] != 1 || done["r1"] != 1 || done["r2"] != 1 {
+		t.Errorf("expected each top-level group to complete once, got %v", done)
+	}
+	if tv.AggregatesPending() {
+		t.Error("expected no pending aggregates after computing them")
+	}
+
+	got := make(map[string]float64)
+	groupSums(t, tv.firstBlock, "", got)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d groups, got %d", len(want), len(got))
+	}
+	for path, sum := range want {
+		if got[path] != sum {
+			t.Errorf("group %s: expected sum %v, got %v", path, sum, got[path])
+		}
+	}
+}
+
+func TestGroupTableComputesDeferredAggregates(t *testing.T) {
+	tv := amountsView()
+	tv.GroupTableDeferringAggregates([]string{"region"}, make(map[string]bool), 0)
+	tv.GroupTable([]string{"region"}, nil, make(map[string]Compare), make(map[string]bool))
+	if tv.AggregatesPending() || tv.firstBlock.Groups[0].Aggregates == nil {
+		t.Error("expected regrouping the same way to compute the pending aggregates")
+	}
+}
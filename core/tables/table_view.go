@@ -22,13 +22,15 @@ import (
 	"container/heap"
 	"errors"
 	"fmt"
+	"maps"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/google/taxinomia/core/aggregates"
 	"github.com/google/taxinomia/core/columns"
-	"github.com/google/taxinomia/core/grouping"
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/internal/grouping"
 	"github.com/google/taxinomia/core/query"
 )
 
@@ -91,10 +93,14 @@ type TableView struct {
 	tableName       string
 	VisibleColumns  []string
 	joins           map[string]columns.IJoinedDataColumn
+	joinSkews       map[string]JoinSkew // joined column -> measured fan-in, computed on demand
 	computedColumns map[string]columns.IDataColumn
+	computedLineage map[string][]string // computed column -> columns it is computed from
 
 	groupedColumns map[string]*grouping.GroupedColumn
 	groupingOrder  []string
+	cohorts        map[string]map[string]string // grouped column -> value -> cohort label
+	groupLabels    map[string]GroupLabelFunc    // grouped column -> renders the label of a group
 	blocksByColumn map[string][]*grouping.Block
 	columnViews    map[string]*columns.ColumnView
 	firstBlock     *grouping.Block
@@ -102,11 +108,17 @@ type TableView struct {
 	// Filtering
 	filterMask  []bool            // Cached filter mask (nil = no filter, all rows shown)
 	lastFilters map[string]string // Filters that produced current mask (for change detection)
+	filterPerf  []FilterPerf      // How the filters of the current mask were evaluated, until taken
+	rowsScanned int               // Rows read to filter and group the view, until taken
 
 	// Grouping cache tracking
 	lastGroupingOrder   []string          // Grouping order when grouping was computed
 	lastGroupingFilters map[string]string // Filter state when grouping was computed
 	lastGroupingSortAsc map[string]bool   // Sort direction when grouping was computed
+	aggregatesPending   bool              // Groups are built but their aggregates are not computed yet
+
+	// Groupings of the whole table built ahead of requests, reused instead of grouping the rows
+	precomputed []*PrecomputedGrouping
 }
 
 // ApplyFilters builds and caches a filter mask based on the provided filters
@@ -122,11 +134,16 @@ type TableView struct {
 // cache locality when accessing column data sequentially.
 //
 // Caching: Skips recomputation if filters are identical to the previous call.
+//
+// Filters on base string and integer columns compare the stored values (see typedFilter);
+// the others convert every row value to a string. The path taken by each filter and its
+// timing are kept for TakeFilterPerf.
 func (t *TableView) ApplyFilters(filters map[string]string) {
 	// Check if filters are unchanged - skip recomputation
 	if t.filtersEqual(filters) {
 		return
 	}
+	t.filterPerf = nil
 
 	// If no filters, clear the mask
 	if len(filters) == 0 {
@@ -152,60 +169,120 @@ func (t *TableView) ApplyFilters(filters map[string]string) {
 			return
 		}
 
-		// Check for multi-value filter (pipe-separated exact matches)
-		if strings.Contains(filterValue, "|") {
-			// Multi-value OR filter - match any of the pipe-separated values (exact match)
-			values := strings.Split(filterValue, "|")
-			valueSet := make(map[string]bool, len(values))
-			for _, v := range values {
-				valueSet[v] = true
+		start := time.Now()
+		perf := FilterPerf{Column: colName, Match: filterMatch(filterValue), Path: FilterPathTyped, Rows: len(t.filterMask)}
+
+		matches, reason := typedFilter(col, filterValue)
+		if reason == "" {
+			keep := make([]bool, len(t.filterMask))
+			for _, i := range matches {
+				keep[i] = true
+			}
+			for i := range t.filterMask {
+				t.filterMask[i] = t.filterMask[i] && keep[i]
+			}
+		} else {
+			perf.Path, perf.Reason = FilterPathString, reason
+			t.applyStringFilter(col, filterValue)
+		}
+
+		for _, passes := range t.filterMask {
+			if passes {
+				perf.Matched++
+			}
+		}
+		perf.Duration = time.Since(start)
+		t.filterPerf = append(t.filterPerf, perf)
+		t.rowsScanned += perf.Rows
+	}
+
+	// Save the filters that produced this mask
+	t.lastFilters = make(map[string]string, len(filters))
+	for k, v := range filters {
+		t.lastFilters[k] = v
+	}
+}
+
+// applyStringFilter clears the rows of the filter mask whose value, converted to a string,
+// does not match filterValue.
+func (t *TableView) applyStringFilter(col columns.IDataColumn, filterValue string) {
+	// Values with a display label match on either the stored code or the label
+	labels := col.ColumnDef().ValueLabels()
+
+	// Check for multi-value filter (pipe-separated exact matches)
+	if strings.Contains(filterValue, "|") {
+		// Multi-value OR filter - match any of the pipe-separated values (exact match)
+		values := strings.Split(filterValue, "|")
+		valueSet := make(map[string]bool, len(values))
+		for _, v := range values {
+			valueSet[v] = true
+		}
+		for i := 0; i < t.baseTable.Length(); i++ {
+			if !t.filterMask[i] {
+				continue
+			}
+			rowValue, err := col.GetString(uint32(i))
+			if err != nil || !matchesValue(rowValue, labels, func(v string) bool { return valueSet[v] }) {
+				t.filterMask[i] = false
 			}
+		}
+	} else {
+		// Single value filter - determine filter type
+		isExactMatch := len(filterValue) >= 2 && filterValue[0] == '"' && filterValue[len(filterValue)-1] == '"'
+
+		if isExactMatch {
+			// Exact match (case-sensitive) - strip quotes
+			exactValue := filterValue[1 : len(filterValue)-1]
 			for i := 0; i < t.baseTable.Length(); i++ {
 				if !t.filterMask[i] {
 					continue
 				}
 				rowValue, err := col.GetString(uint32(i))
-				if err != nil || !valueSet[rowValue] {
+				if err != nil || !matchesValue(rowValue, labels, func(v string) bool { return v == exactValue }) {
 					t.filterMask[i] = false
 				}
 			}
 		} else {
-			// Single value filter - determine filter type
-			isExactMatch := len(filterValue) >= 2 && filterValue[0] == '"' && filterValue[len(filterValue)-1] == '"'
-
-			if isExactMatch {
-				// Exact match (case-sensitive) - strip quotes
-				exactValue := filterValue[1 : len(filterValue)-1]
-				for i := 0; i < t.baseTable.Length(); i++ {
-					if !t.filterMask[i] {
-						continue
-					}
-					rowValue, err := col.GetString(uint32(i))
-					if err != nil || rowValue != exactValue {
-						t.filterMask[i] = false
-					}
+			// Substring match (case-insensitive)
+			substringValue := strings.ToLower(filterValue)
+			for i := 0; i < t.baseTable.Length(); i++ {
+				if !t.filterMask[i] {
+					continue
 				}
-			} else {
-				// Substring match (case-insensitive)
-				substringValue := strings.ToLower(filterValue)
-				for i := 0; i < t.baseTable.Length(); i++ {
-					if !t.filterMask[i] {
-						continue
-					}
-					rowValue, err := col.GetString(uint32(i))
-					if err != nil || !strings.Contains(strings.ToLower(rowValue), substringValue) {
-						t.filterMask[i] = false
-					}
+				rowValue, err := col.GetString(uint32(i))
+				if err != nil || !matchesValue(rowValue, labels, func(v string) bool { return strings.Contains(strings.ToLower(v), substringValue) }) {
+					t.filterMask[i] = false
 				}
 			}
 		}
 	}
+}
 
-	// Save the filters that produced this mask
-	t.lastFilters = make(map[string]string, len(filters))
-	for k, v := range filters {
-		t.lastFilters[k] = v
+// TakeFilterPerf returns how the filters of the current mask were evaluated, sorted by
+// column, and forgets them. It returns nil if they were already taken.
+func (t *TableView) TakeFilterPerf() []FilterPerf {
+	perf := t.filterPerf
+	t.filterPerf = nil
+	sort.SliceStable(perf, func(i, j int) bool { return perf[i].Column < perf[j].Column })
+	return perf
+}
+
+// TakeRowsScanned returns the number of rows read to filter and group the view since it
+// was last taken, and resets it. Filters and groupings reused from a previous request
+// read no rows.
+func (t *TableView) TakeRowsScanned() int {
+	n := t.rowsScanned
+	t.rowsScanned = 0
+	return n
+}
+
+// matchesValue reports whether a row value, or its display label if it has one, satisfies match.
+func matchesValue(value string, labels map[string]string, match func(string) bool) bool {
+	if match(value) {
+		return true
 	}
+	label, ok := labels[value]
+	return ok && match(label)
 }
 
 // filtersEqual checks if the provided filters match the last applied filters
@@ -227,6 +304,12 @@ func (t *TableView) ClearFilters() {
 	t.lastFilters = nil
 }
 
+// AppliedFilters returns the filters that produced the current filter mask (nil = no filter).
+// The map is replaced, not modified, when other filters are applied.
+func (t *TableView) AppliedFilters() map[string]string {
+	return t.lastFilters
+}
+
 // GetFilteredRowCount returns the number of rows that pass the current filter
 // Returns total row count if no filter is active
 func (t *TableView) GetFilteredRowCount() int {
@@ -314,6 +397,18 @@ func (t *TableView) ClearGroupings() {
 	t.lastGroupingOrder = nil
 	t.lastGroupingFilters = nil
 	t.lastGroupingSortAsc = nil
+	t.aggregatesPending = false
+}
+
+// ReleaseCaches drops the filter mask and the groupings of the view with their
+// aggregates, and returns the estimated bytes they held. The view keeps its joined and
+// computed columns; filters and groupings are rebuilt by the next request using them.
+func (t *TableView) ReleaseCaches() uint64 {
+	bytes := t.CacheFootprint().Bytes()
+	t.ClearFilters()
+	t.ClearGroupings()
+	t.blocksByColumn = make(map[string][]*grouping.Block)
+	return bytes
 }
 
 func (t *TableView) GroupTable(groupingOrder []string, aggregatedColumns []string, compare map[string]Compare, asc map[string]bool) {
@@ -326,6 +421,23 @@ func (t *TableView) GroupTable(groupingOrder []string, aggregatedColumns []strin
 func (t *TableView) GroupTableWithLimit(groupingOrder []string, aggregatedColumns []string, compare map[string]Compare, asc map[string]bool, displayLimit int) {
 	// Check if grouping inputs are unchanged - skip recomputation
 	if t.groupingEqual(groupingOrder, asc) {
+		// A grouping built by GroupTableDeferringAggregates may still lack its aggregates
+		if t.aggregatesPending {
+			t.computeAllAggregates()
+		}
+		return
+	}
+	t.groupRows(groupingOrder, asc, displayLimit)
+	t.computeAllAggregates()
+}
+
+// groupRows builds the group hierarchy for the filtered rows and records the state that
+// produced it. Aggregates are computed separately.
+func (t *TableView) groupRows(groupingOrder []string, asc map[string]bool, displayLimit int) {
+	// Reuse a grouping precomputed for the table, if any matches
+	if p := t.matchingPrecomputedGrouping(groupingOrder, asc); p != nil {
+		t.restoreGrouping(p, groupingOrder)
+		t.recordGroupingState(groupingOrder, asc)
 		return
 	}
 
@@ -351,6 +463,8 @@ func (t *TableView) GroupTableWithLimit(groupingOrder []string, aggregatedColumn
 		}
 	}
 
+	t.rowsScanned += len(indices)
+
 	// Process first column
 	// groupedTable.columns = columns
 	parentBlocks := t.groupFirstColumnInTable(indices)
@@ -365,15 +479,11 @@ func (t *TableView) GroupTableWithLimit(groupingOrder []string, aggregatedColumn
 	// Process subsequent columns
 	t.groupSubsequentColumnsInTable(indices, t.groupingOrder[1:], parentBlocks, asc)
 
-	// Compute aggregates for all groups
-	leafColumns := t.GetLeafColumns()
-	columnTypes := make(map[string]query.ColumnType)
-	for _, colName := range leafColumns {
-		columnTypes[colName] = t.GetColumnType(colName)
-	}
-	t.ComputeAggregates(leafColumns, columnTypes)
+	t.recordGroupingState(groupingOrder, asc)
+}
 
-	// Save the state that produced this grouping
+// recordGroupingState saves the state that produced the current grouping
+func (t *TableView) recordGroupingState(groupingOrder []string, asc map[string]bool) {
 	t.lastGroupingOrder = make([]string, len(groupingOrder))
 	copy(t.lastGroupingOrder, groupingOrder)
 	t.lastGroupingFilters = make(map[string]string, len(t.lastFilters))
@@ -386,6 +496,23 @@ func (t *TableView) GroupTableWithLimit(groupingOrder []string, aggregatedColumn
 	}
 }
 
+// computeAllAggregates computes the aggregates of the visible leaf columns for all groups.
+func (t *TableView) computeAllAggregates() {
+	leafColumns, columnTypes := t.leafColumnTypes()
+	t.ComputeAggregates(leafColumns, columnTypes)
+	t.aggregatesPending = false
+}
+
+// leafColumnTypes returns the leaf columns and their aggregate column types.
+func (t *TableView) leafColumnTypes() ([]string, map[string]query.ColumnType) {
+	leafColumns := t.GetLeafColumns()
+	columnTypes := make(map[string]query.ColumnType)
+	for _, colName := range leafColumns {
+		columnTypes[colName] = t.GetColumnType(colName)
+	}
+	return leafColumns, columnTypes
+}
+
 // sortGroupsInBlock sorts the groups within a block based on their values
 func (t *TableView) sortGroupsInBlock(block *grouping.Block, descending bool) {
 	t.sortGroupsInBlockTopK(block, descending, 0) // 0 = no limit, sort all
@@ -497,7 +624,7 @@ func (t *TableView) groupingEqual(groupingOrder []string, asc map[string]bool) b
 func (t *TableView) groupFirstColumnInTable(indices []uint32) []*grouping.Block {
 	firstColumn := t.groupingOrder[0]
 	columnView := t.columnViews[firstColumn]
-	dataColumn := t.GetColumn(firstColumn)
+	dataColumn := t.groupingColumn(firstColumn)
 
 	g := &grouping.GroupedColumn{
 		DataColumn: dataColumn,
@@ -538,7 +665,7 @@ func (t *TableView) groupSubsequentColumnsInTable(indices []uint32, columns []st
 
 	// for following columns, each parent group spawns a child block
 	for level, col := range columns {
-		dataColumn := t.GetColumn(col)
+		dataColumn := t.groupingColumn(col)
 		columnView := t.columnViews[col]
 
 		g := &grouping.GroupedColumn{
@@ -588,13 +715,69 @@ func (t *TableView) groupSubsequentColumnsInTable(indices []uint32, columns []st
 	}
 }
 
+// SetCohorts sets the ad-hoc cohorts that merge values of grouped columns into labeled
+// groups. Changing the cohorts invalidates the current grouping.
+func (t *TableView) SetCohorts(cohorts map[string][]query.Cohort) {
+	labels := make(map[string]map[string]string, len(cohorts))
+	for col, colCohorts := range cohorts {
+		if len(colCohorts) == 0 {
+			continue
+		}
+		labels[col] = make(map[string]string)
+		for _, c := range colCohorts {
+			for _, v := range c.Values {
+				labels[col][v] = c.Label
+			}
+		}
+	}
+	if maps.EqualFunc(labels, t.cohorts, maps.Equal) {
+		return
+	}
+	t.cohorts = labels
+	t.lastGroupingOrder = nil
+}
+
+// GroupLabelFunc renders the label of a group from one of its rows.
+type GroupLabelFunc func(row uint32) (string, error)
+
+// SetGroupLabels sets the functions rendering the labels of the groups of grouped columns,
+// replacing the previous ones. Labels only change how groups are displayed: the grouping,
+// its sort order and the filters of the groups keep using the grouped values.
+func (t *TableView) SetGroupLabels(labels map[string]GroupLabelFunc) {
+	t.groupLabels = labels
+}
+
+// GroupLabel renders the label of a group of a grouped column from the first row of the
+// group. All rows of a group share the grouped value, so the label can use it along with
+// any column constant within the group, like the columns grouped above it. ok is false if
+// the column has no label function.
+func (t *TableView) GroupLabel(column string, group *grouping.Group) (label string, ok bool, err error) {
+	render, ok := t.groupLabels[column]
+	if !ok || len(group.Indices) == 0 {
+		return "", false, nil
+	}
+	label, err = render(group.Indices[0])
+	return label, true, err
+}
+
+// groupingColumn returns the column grouped for col, merging its cohorts if it has any.
+func (t *TableView) groupingColumn(col string) columns.IDataColumn {
+	dataColumn := t.GetColumn(col)
+	if labels := t.cohorts[col]; len(labels) > 0 && dataColumn != nil {
+		return columns.NewCohortColumn(dataColumn, labels)
+	}
+	return dataColumn
+}
+
 // NewTableView creates a new TableView wrapping a DataTable
 func NewTableView(baseTable *DataTable, tableName string) *TableView {
 	return &TableView{
 		baseTable:       baseTable,
 		tableName:       tableName,
 		joins:           make(map[string]columns.IJoinedDataColumn),
+		joinSkews:       make(map[string]JoinSkew),
 		computedColumns: make(map[string]columns.IDataColumn),
+		computedLineage: make(map[string][]string),
 		columnViews:     make(map[string]*columns.ColumnView),
 		groupedColumns:  make(map[string]*grouping.GroupedColumn),
 		blocksByColumn:  make(map[string][]*grouping.Block),
@@ -611,6 +794,7 @@ func (tv *TableView) AddComputedColumn(name string, col columns.IDataColumn) {
 // RemoveComputedColumn removes a computed column from the view
 func (tv *TableView) RemoveComputedColumn(name string) {
 	delete(tv.computedColumns, name)
+	delete(tv.computedLineage, name)
 	delete(tv.columnViews, name)
 }
 
@@ -622,11 +806,13 @@ func (tv *TableView) GetBaseTable() *DataTable {
 // AddJoinedColumn adds a joined column to this view
 func (tv *TableView) AddJoinedColumn(joinedCol columns.IJoinedDataColumn) {
 	tv.joins[joinedCol.ColumnDef().Name()] = joinedCol
+	delete(tv.joinSkews, joinedCol.ColumnDef().Name())
 }
 
 // RemoveJoinedColumn removes a joined column from this view
 func (tv *TableView) RemoveJoinedColumn(name string) {
 	delete(tv.joins, name)
+	delete(tv.joinSkews, name)
 }
 
 // GetColumn retrieves a column by name, checking base table, joined columns, and computed columns
@@ -682,7 +868,8 @@ func (tv *TableView) GetJoinedColumnNames() []string {
 // Joined columns are identified by the format:
 // - Single hop: fromColumn.toTable.toColumn.selectedColumn (4 parts)
 // - Multi hop: fromColumn.toTable.toColumn.fromColumn2.toTable2.toColumn2.selectedColumn (7 parts for 2 hops, etc.)
-func (tv *TableView) UpdateJoinedColumns(columnNames []string, resolver JoinResolver) {
+// Returns the columns that could not be joined, keyed by column name.
+func (tv *TableView) UpdateJoinedColumns(columnNames []string, resolver JoinResolver) map[string]error {
 	// Debug: Print processing info
 	fmt.Printf("\n=== UpdateJoinedColumns Debug Info ===\n")
 	fmt.Printf("Table: %s\n", tv.tableName)
@@ -716,35 +903,40 @@ func (tv *TableView) UpdateJoinedColumns(columnNames []string, resolver JoinReso
 	}
 
 	// Add needed joined columns that aren't already in the view
+	joinErrors := make(map[string]error)
 	for colName := range neededJoinedColumns {
 		// Skip if already exists
 		if tv.joins[colName] != nil {
 			continue
 		}
 
-		joinedColumn := tv.createChainedJoinedColumn(colName, resolver)
-		if joinedColumn != nil {
-			fmt.Printf("Adding joined column %s to table view\n", colName)
-			tv.AddJoinedColumn(joinedColumn)
+		joinedColumn, err := tv.createChainedJoinedColumn(colName, resolver)
+		if err != nil {
+			fmt.Printf("Could not join column %s: %v\n", colName, err)
+			joinErrors[colName] = err
+			continue
 		}
+		fmt.Printf("Adding joined column %s to table view\n", colName)
+		tv.AddJoinedColumn(joinedColumn)
 	}
 
 	// Debug: Print final state
 	fmt.Printf("Joined Columns in TableView: %v\n", tv.GetJoinedColumnNames())
 	fmt.Printf("All Columns in TableView: %v\n", tv.GetAllColumnNames())
 	fmt.Printf("===============================================\n\n")
+	return joinErrors
 }
 
 // createChainedJoinedColumn creates a joined column that may chain through multiple tables
 // Format: fromColumn.toTable.toColumn.fromColumn2.toTable2.toColumn2...selectedColumn
-func (tv *TableView) createChainedJoinedColumn(colName string, resolver JoinResolver) columns.IJoinedDataColumn {
+func (tv *TableView) createChainedJoinedColumn(colName string, resolver JoinResolver) (columns.IJoinedDataColumn, error) {
 	parts := strings.Split(colName, ".")
 	numParts := len(parts)
 
 	// Calculate number of hops: (numParts - 1) / 3
 	numHops := (numParts - 1) / 3
 	if numHops < 1 {
-		return nil
+		return nil, errs.New(errs.ErrJoinNotFound, "invalid join path: %s", colName)
 	}
 
 	type JoinWithJoiner interface {
@@ -771,38 +963,44 @@ func (tv *TableView) createChainedJoinedColumn(colName string, resolver JoinReso
 		foundJoin := resolver.GetJoin(joinKey)
 
 		if foundJoin == nil {
-			fmt.Printf("Could not find join for key: %s\n", joinKey)
-			return nil
+			return nil, errs.New(errs.ErrJoinNotFound, "could not find join for key: %s", joinKey)
 		}
 
 		joinWithJoiner, ok := foundJoin.(JoinWithJoiner)
 		if !ok {
-			fmt.Printf("Join does not have GetJoiner method: %s\n", joinKey)
-			return nil
+			return nil, errs.New(errs.ErrJoinNotFound, "join does not have GetJoiner method: %s", joinKey)
 		}
 
 		joiner := joinWithJoiner.GetJoiner()
 		if joiner == nil {
-			fmt.Printf("Join has nil joiner: %s\n", joinKey)
-			return nil
+			return nil, errs.New(errs.ErrJoinNotFound, "join has nil joiner: %s", joinKey)
 		}
 		joiners = append(joiners, joiner)
 		lastTargetTable = toTable
 		currentTableName = toTable
 	}
 
+	var joiner columns.IJoiner
+	if len(joiners) == 1 {
+		joiner = joiners[0]
+	} else {
+		joiner = columns.NewChainedJoiner(joiners...)
+	}
+
 	// Get the final target column (last part of the path)
 	selectedColName := parts[numParts-1]
+	if selectedColName == columns.MatchConfidenceColumn {
+		colDef := columns.NewColumnDef(colName, fmt.Sprintf("%s → match confidence", lastTargetTable), "")
+		return columns.NewMatchConfidenceColumn(colDef, joiner, tv.NumRows()), nil
+	}
 	targetTable := resolver.GetTable(lastTargetTable)
 	if targetTable == nil {
-		fmt.Printf("Could not find target table: %s\n", lastTargetTable)
-		return nil
+		return nil, errs.New(errs.ErrUnknownTable, "could not find target table: %s", lastTargetTable)
 	}
 
 	targetDataCol := targetTable.GetColumn(selectedColName)
 	if targetDataCol == nil {
-		fmt.Printf("Could not find target column: %s.%s\n", lastTargetTable, selectedColName)
-		return nil
+		return nil, errs.New(errs.ErrUnknownColumn, "could not find target column: %s.%s", lastTargetTable, selectedColName)
 	}
 
 	// Create the final joined column with either a single joiner or a chained joiner
@@ -811,15 +1009,9 @@ func (tv *TableView) createChainedJoinedColumn(colName string, resolver JoinReso
 		fmt.Sprintf("%s → %s", lastTargetTable, targetDataCol.ColumnDef().DisplayName()),
 		"",
 	)
+	colDef.SetUpdatedAt(targetDataCol.ColumnDef().UpdatedAt())
 
-	var joiner columns.IJoiner
-	if len(joiners) == 1 {
-		joiner = joiners[0]
-	} else {
-		joiner = columns.NewChainedJoiner(joiners...)
-	}
-
-	return targetDataCol.CreateJoinedColumn(colDef, joiner)
+	return targetDataCol.CreateJoinedColumn(colDef, joiner), nil
 }
 
 // IsGrouped returns true if the table has active grouping
@@ -833,8 +1025,8 @@ func (tv *TableView) IsColGrouped(colName string) bool {
 	return ok
 }
 
-// GetFirstBlock returns the first block of the grouping hierarchy
-// Returns nil if no grouping is active
+// GetFirstBlock returns the first block of the grouping hierarchy (see core/grouping for
+// its type). Returns nil if no grouping is active
 func (tv *TableView) GetFirstBlock() *grouping.Block {
 	return tv.firstBlock
 }
@@ -904,6 +1096,21 @@ func (tv *TableView) ComputeAggregates(leafColumns []string, columnTypes map[str
 	tv.computeAggregatesForBlock(tv.firstBlock, leafColumns, columnTypes)
 }
 
+// AggregateFilteredRows computes aggregates of the given columns over all rows passing the
+// current filters, as if they formed a single group.
+func (tv *TableView) AggregateFilteredRows(leafColumns []string) map[string]aggregates.AggregateState {
+	columnTypes := make(map[string]query.ColumnType, len(leafColumns))
+	for _, colName := range leafColumns {
+		columnTypes[colName] = tv.GetColumnType(colName)
+	}
+	group := &grouping.Group{
+		Indices:    tv.GetFilteredIndices(),
+		Aggregates: make(map[string]aggregates.AggregateState),
+	}
+	tv.computeLeafAggregates(group, leafColumns, columnTypes)
+	return group.Aggregates
+}
+
 // computeAggregatesForBlock recursively computes aggregates for a block and its children.
 // Returns after processing all groups in the block.
 func (tv *TableView) computeAggregatesForBlock(block *grouping.Block, leafColumns []string, columnTypes map[string]query.ColumnType) {
@@ -912,21 +1119,26 @@ func (tv *TableView) computeAggregatesForBlock(block *grouping.Block, leafColumn
 	}
 
 	for _, group := range block.Groups {
-		// First, process child block (if any) - bottom-up
-		if group.ChildBlock != nil {
-			tv.computeAggregatesForBlock(group.ChildBlock, leafColumns, columnTypes)
-		}
+		tv.computeGroupAggregates(group, leafColumns, columnTypes)
+	}
+}
 
-		// Now compute aggregates for this group
-		group.Aggregates = make(map[string]aggregates.AggregateState)
+// computeGroupAggregates computes aggregates for a group and all its descendants.
+func (tv *TableView) computeGroupAggregates(group *grouping.Group, leafColumns []string, columnTypes map[string]query.ColumnType) {
+	// First, process child block (if any) - bottom-up
+	if group.ChildBlock != nil {
+		tv.computeAggregatesForBlock(group.ChildBlock, leafColumns, columnTypes)
+	}
 
-		if group.ChildBlock == nil {
-			// Leaf group: compute from indices
-			tv.computeLeafAggregates(group, leafColumns, columnTypes)
-		} else {
-			// Parent group: combine from children
-			tv.combineChildAggregates(group, leafColumns, columnTypes)
-		}
+	// Now compute aggregates for this group
+	group.Aggregates = make(map[string]aggregates.AggregateState)
+
+	if group.ChildBlock == nil {
+		// Leaf group: compute from indices
+		tv.computeLeafAggregates(group, leafColumns, columnTypes)
+	} else {
+		// Parent group: combine from children
+		tv.combineChildAggregates(group, leafColumns, columnTypes)
 	}
 }
 
@@ -940,9 +1152,17 @@ func (tv *TableView) computeLeafAggregates(group *grouping.Group, leafColumns []
 		}
 
 		state := aggregates.CreateAggState(colType)
+		if colType == query.ColumnTypeNumeric && columns.IsIdentifier(col) {
+			state = aggregates.NewDistinctNumericAggState()
+		}
 
-		// Add each value from the group's indices
+		// Add each value from the group's indices, except placeholders of values that
+		// failed to load
+		def := col.ColumnDef()
 		for _, idx := range group.Indices {
+			if def.IsInvalidRow(idx) {
+				continue
+			}
 			switch colType {
 			case query.ColumnTypeNumeric:
 				if numState, ok := state.(*aggregates.NumericAggState); ok {
@@ -1115,6 +1335,13 @@ func (tv *TableView) GetColumnType(colName string) query.ColumnType {
 	return query.ColumnTypeString
 }
 
+// IsIdentifierColumn returns whether a column identifies records rather than measuring
+// them (see columns.IsIdentifier)
+func (tv *TableView) IsIdentifierColumn(colName string) bool {
+	col := tv.GetColumn(colName)
+	return col != nil && columns.IsIdentifier(col)
+}
+
 // GetColumnTypeName returns the Go struct name for a column (e.g., "StringColumn", "Uint32Column").
 func (tv *TableView) GetColumnTypeName(colName string) string {
 	col := tv.GetColumn(colName)
@@ -1299,6 +1526,8 @@ func (tv *TableView) getAggregateNumericValue(state aggregates.AggregateState, a
 			return s.Min
 		case query.AggMax:
 			return s.Max
+		case query.AggUnique:
+			return float64(len(s.Distinct))
 		}
 	case *aggregates.BoolAggState:
 		switch aggType {
@@ -22,7 +22,7 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/google/taxinomia/core/grouping"
+	"github.com/google/taxinomia/core/internal/grouping"
 )
 
 // ToAscii returns a string representation of the grouped table with ASCII borders
@@ -23,7 +23,7 @@ import (
 	"testing"
 
 	"github.com/google/taxinomia/core/columns"
-	"github.com/google/taxinomia/core/grouping"
+	"github.com/google/taxinomia/core/internal/grouping"
 )
 
 // TestGroupTable tests the GroupTable functionality
@@ -172,6 +172,39 @@ func TestGroupTableWithMask(t *testing.T) {
 	}
 }
 
+// TestFilterValueLabels tests that filters match both stored codes and their display labels
+func TestFilterValueLabels(t *testing.T) {
+	table := NewDataTable()
+
+	exitCol := columns.NewStringColumn(columns.NewColumnDef("exit_code", "Exit Code", ""))
+	for _, v := range []string{"0", "137", "1", "137"} {
+		exitCol.Append(v)
+	}
+	exitCol.FinalizeColumn()
+	exitCol.ColumnDef().SetValueLabels(map[string]string{"0": "OK", "137": "OOMKilled"})
+	table.AddColumn(exitCol)
+
+	tableView := NewTableView(table, "test_table")
+
+	tests := []struct {
+		filter string
+		want   int
+	}{
+		{"137", 2},           // code substring
+		{"oom", 2},           // label substring
+		{"\"OOMKilled\"", 2}, // exact label
+		{"\"137\"", 2},       // exact code
+		{"OK|1", 2},          // label or code
+		{"\"1\"", 1},         // exact code without label
+	}
+	for _, tt := range tests {
+		tableView.ApplyFilters(map[string]string{"exit_code": tt.filter})
+		if got := tableView.GetFilteredRowCount(); got != tt.want {
+			t.Errorf("filter %q: expected %d rows, got %d", tt.filter, tt.want, got)
+		}
+	}
+}
+
 // TestFilterExactMatch tests exact match filtering with quotes
 func TestFilterExactMatch(t *testing.T) {
 	// Create a test table
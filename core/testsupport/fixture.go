@@ -0,0 +1,199 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testsupport provides an in-process Taxinomia server backed by a small,
+// deterministic data model, together with helpers to execute query URLs against
+// it and assert on the rendered responses. It is intended for integration tests
+// of code that embeds or extends Taxinomia.
+package testsupport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/models"
+	"github.com/google/taxinomia/core/server"
+	"github.com/google/taxinomia/core/tables"
+	"github.com/google/taxinomia/core/views"
+)
+
+// ProductName is the product under which the fixture server serves its tables.
+const ProductName = "test"
+
+// Fixture row counts, exposed so tests can assert on them without hardcoding numbers.
+const (
+	RegionsRowCount = 3
+	OrdersRowCount  = 6
+)
+
+// NewDataModel returns a deterministic data model with two tables:
+//
+//   - regions: region (key, entity type "region"), name, population
+//   - orders: order_id (key, entity type "order"), region (entity type "region"), status, amount
+//
+// orders.region joins to regions.region, so joined columns such as
+// "region.regions.region.name" can be requested.
+func NewDataModel() *models.DataModel {
+	dm := models.NewDataModel()
+
+	regions := tables.NewDataTable()
+	regions.AddColumn(StringColumn("region", "Region", "region", "north", "south", "west"))
+	regions.AddColumn(StringColumn("name", "Name", "", "North", "South", "West"))
+	regions.AddColumn(Uint32Column("population", "Population", 1200, 800, 500))
+	dm.AddTable("regions", regions)
+
+	orders := tables.NewDataTable()
+	orders.AddColumn(StringColumn("order_id", "Order ID", "order", "o1", "o2", "o3", "o4", "o5", "o6"))
+	orders.AddColumn(StringColumn("region", "Region", "region", "north", "north", "south", "west", "south", "north"))
+	orders.AddColumn(StringColumn("status", "Status", "", "shipped", "pending", "shipped", "cancelled", "shipped", "pending"))
+	orders.AddColumn(Uint32Column("amount", "Amount", 100, 250, 75, 300, 125, 50))
+	dm.AddTable("orders", orders)
+
+	return dm
+}
+
+// StringColumn returns a finalized string column with the given values.
+func StringColumn(name, displayName, entityType string, values ...string) *columns.StringColumn {
+	col := columns.NewStringColumn(columns.NewColumnDef(name, displayName, entityType))
+	for _, v := range values {
+		col.Append(v)
+	}
+	col.FinalizeColumn()
+	return col
+}
+
+// Uint32Column returns a finalized uint32 column with the given values.
+func Uint32Column(name, displayName string, values ...uint32) *columns.Uint32Column {
+	col := columns.NewUint32Column(columns.NewColumnDef(name, displayName, ""))
+	for _, v := range values {
+		col.Append(v)
+	}
+	col.FinalizeColumn()
+	return col
+}
+
+// Product is a minimal server.ProductConfig listing every table of a data model.
+type Product struct {
+	dataModel      *models.DataModel
+	defaultColumns map[string][]string
+}
+
+// NewProduct creates a product exposing all tables of the data model.
+func NewProduct(dm *models.DataModel) *Product {
+	return &Product{dataModel: dm, defaultColumns: make(map[string][]string)}
+}
+
+// SetDefaultColumns sets the columns shown for a table when the query does not specify any.
+func (p *Product) SetDefaultColumns(tableName string, cols []string) {
+	p.defaultColumns[tableName] = cols
+}
+
+// GetName returns the product name
+func (p *Product) GetName() string { return ProductName }
+
+// GetTitle returns the landing page title
+func (p *Product) GetTitle() string { return "Test Fixture" }
+
+// GetSubtitle returns the landing page subtitle
+func (p *Product) GetSubtitle() string { return "Deterministic tables for integration tests" }
+
+// GetTables returns landing page entries for all tables in the data model, sorted
+// by name so that pages render the same on every run
+func (p *Product) GetTables() []views.TableInfo {
+	all := p.dataModel.GetAllTables()
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var infos []views.TableInfo
+	for _, name := range names {
+		table := all[name]
+		infos = append(infos, views.TableInfo{
+			Name:        name,
+			URL:         "/" + ProductName + "/table?table=" + name,
+			RecordCount: table.Length(),
+			ColumnCount: len(table.GetColumnNames()),
+		})
+	}
+	return infos
+}
+
+// GetDefaultColumns returns the default columns for a table
+func (p *Product) GetDefaultColumns(tableName string) []string {
+	return p.defaultColumns[tableName]
+}
+
+// Server is an in-process Taxinomia server for tests.
+type Server struct {
+	*server.Server
+	DataModel *models.DataModel
+	Product   *Product
+}
+
+// NewServer creates a fixture server over NewDataModel. It fails the test if
+// the server cannot be created.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	return NewServerWithDataModel(t, NewDataModel())
+}
+
+// NewServerWithDataModel creates a fixture server over the given data model.
+func NewServerWithDataModel(t testing.TB, dm *models.DataModel) *Server {
+	t.Helper()
+	srv, err := server.NewServer(dm)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	return &Server{Server: srv, DataModel: dm, Product: NewProduct(dm)}
+}
+
+// Handler returns the http.Handler of the server (see server.Server.Handler), serving
+// the fixture product.
+func (s *Server) Handler() http.Handler {
+	return s.Server.Handler(ProductName, func(name string) server.ProductConfig {
+		if name != ProductName {
+			return nil
+		}
+		return s.Product
+	})
+}
+
+// Get executes a GET request for the given URL (path and query, for example
+// "/test/table?table=orders&limit=5") and returns the recorded response.
+func (s *Server) Get(t testing.TB, url string) *Response {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	return &Response{StatusCode: rec.Code, Header: rec.Header(), Body: rec.Body.String()}
+}
+
+// GetTable executes a table request for the given table with additional
+// query parameters (for example "columns=region,amount&grouped=region").
+func (s *Server) GetTable(t testing.TB, table, params string) *Response {
+	t.Helper()
+	url := "/" + ProductName + "/table?table=" + table
+	if params != "" {
+		url += "&" + params
+	}
+	return s.Get(t, url)
+}
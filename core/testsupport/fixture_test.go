@@ -0,0 +1,91 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testsupport
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestLandingPageListsTables(t *testing.T) {
+	srv := NewServer(t)
+
+	resp := srv.Get(t, "/"+ProductName+"/")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, "Test Fixture")
+	resp.AssertContains(t, "orders")
+	resp.AssertContains(t, "regions")
+}
+
+func TestProductTablesSortedByName(t *testing.T) {
+	infos := NewProduct(NewDataModel()).GetTables()
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	if len(names) != 2 || names[0] != "orders" || names[1] != "regions" {
+		t.Errorf("expected tables [orders regions], got %v", names)
+	}
+}
+
+func TestTableRequestRendersRows(t *testing.T) {
+	srv := NewServer(t)
+
+	resp := srv.GetTable(t, "orders", "columns=order_id,status,amount")
+	resp.AssertStatus(t, http.StatusOK)
+	if got := resp.Attr("data-total-rows"); got != strconv.Itoa(OrdersRowCount) {
+		t.Errorf("expected %d total rows, got %q", OrdersRowCount, got)
+	}
+	resp.AssertContains(t, "o6")
+}
+
+func TestGroupedTableRequest(t *testing.T) {
+	srv := NewServer(t)
+
+	resp := srv.GetTable(t, "orders", "columns=region,amount&grouped=region")
+	resp.AssertStatus(t, http.StatusOK)
+	cells := resp.Elements("td", "data-column", "region")
+	if len(cells) != RegionsRowCount {
+		t.Fatalf("expected %d region groups, got %d: %v", RegionsRowCount, len(cells), cells)
+	}
+}
+
+func TestJoinedColumn(t *testing.T) {
+	srv := NewServer(t)
+
+	resp := srv.GetTable(t, "orders", "columns=order_id,region.regions.region.name")
+	resp.AssertStatus(t, http.StatusOK)
+	resp.AssertContains(t, "North")
+}
+
+func TestUnknownTableReturnsNotFound(t *testing.T) {
+	srv := NewServer(t)
+
+	resp := srv.GetTable(t, "missing", "")
+	resp.AssertStatus(t, http.StatusNotFound)
+	resp.AssertContains(t, "missing")
+}
+
+func TestUnknownProductReturnsNotFound(t *testing.T) {
+	srv := NewServer(t)
+
+	resp := srv.Get(t, "/other/table?table=orders")
+	resp.AssertStatus(t, http.StatusNotFound)
+}
@@ -0,0 +1,107 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testsupport
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Response is a recorded response from the fixture server.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// AssertStatus fails the test if the response status code differs from want.
+func (r *Response) AssertStatus(t testing.TB, want int) {
+	t.Helper()
+	if r.StatusCode != want {
+		t.Fatalf("expected status %d, got %d: %s", want, r.StatusCode, truncate(r.Body))
+	}
+}
+
+// AssertContains fails the test if the body does not contain s.
+func (r *Response) AssertContains(t testing.TB, s string) {
+	t.Helper()
+	if !strings.Contains(r.Body, s) {
+		t.Errorf("expected response to contain %q", s)
+	}
+}
+
+// AssertNotContains fails the test if the body contains s.
+func (r *Response) AssertNotContains(t testing.TB, s string) {
+	t.Helper()
+	if strings.Contains(r.Body, s) {
+		t.Errorf("expected response not to contain %q", s)
+	}
+}
+
+// DecodeJSON unmarshals the body into v, failing the test on invalid JSON.
+func (r *Response) DecodeJSON(t testing.TB, v any) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(r.Body), v); err != nil {
+		t.Fatalf("failed to decode JSON response: %v: %s", err, truncate(r.Body))
+	}
+}
+
+// Attr returns the unescaped value of the first occurrence of the named
+// attribute in the body (for example "data-total-rows"), or "" if absent.
+func (r *Response) Attr(name string) string {
+	re := regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `="([^"]*)"`)
+	m := re.FindStringSubmatch(r.Body)
+	if m == nil {
+		return ""
+	}
+	return html.UnescapeString(m[1])
+}
+
+// Elements returns the text content of every tag element whose attr attribute
+// equals value, in document order. Nested markup is stripped and whitespace is
+// trimmed. Elements nested inside an element of the same tag are not supported.
+func (r *Response) Elements(tag, attr, value string) []string {
+	re := regexp.MustCompile(`(?s)<` + regexp.QuoteMeta(tag) + `\b[^>]*\s` + regexp.QuoteMeta(attr) + `="` +
+		regexp.QuoteMeta(html.EscapeString(value)) + `"[^>]*>(.*?)</` + regexp.QuoteMeta(tag) + `>`)
+	var texts []string
+	for _, m := range re.FindAllStringSubmatch(r.Body, -1) {
+		texts = append(texts, Text(m[1]))
+	}
+	return texts
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// Text strips markup from an HTML fragment and returns its unescaped, trimmed text.
+func Text(fragment string) string {
+	return strings.TrimSpace(html.UnescapeString(tagPattern.ReplaceAllString(fragment, "")))
+}
+
+// truncate shortens a body for inclusion in failure messages.
+func truncate(body string) string {
+	const max = 500
+	if len(body) > max {
+		return body[:max] + "..."
+	}
+	return body
+}
@@ -43,7 +43,11 @@ type UserProfile struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Domains the user has access to.
 	// Only tables belonging to these domains will be visible.
-	Domains       []string `protobuf:"bytes,1,rep,name=domains,proto3" json:"domains,omitempty"`
+	Domains []string `protobuf:"bytes,1,rep,name=domains,proto3" json:"domains,omitempty"`
+	// Preferred locale of the user interface as a BCP 47 tag (e.g., "he-IL").
+	// Overrides the default locale of the server; right-to-left languages
+	// switch the layout to right to left.
+	Locale        string `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -85,13 +89,21 @@ func (x *UserProfile) GetDomains() []string {
 	return nil
 }
 
+func (x *UserProfile) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
 var File_core_users_user_proto protoreflect.FileDescriptor
 
 const file_core_users_user_proto_rawDesc = "" +
 	"\n" +
-	"\x15core/users/user.proto\x12\x0ftaxinomia.users\"'\n" +
+	"\x15core/users/user.proto\x12\x0ftaxinomia.users\"?\n" +
 	"\vUserProfile\x12\x18\n" +
-	"\adomains\x18\x01 \x03(\tR\adomainsB(Z&github.com/google/taxinomia/core/usersb\x06proto3"
+	"\adomains\x18\x01 \x03(\tR\adomains\x12\x16\n" +
+	"\x06locale\x18\x02 \x01(\tR\x06localeB(Z&github.com/google/taxinomia/core/usersb\x06proto3"
 
 var (
 	file_core_users_user_proto_rawDescOnce sync.Once
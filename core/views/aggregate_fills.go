@@ -0,0 +1,120 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import (
+	"fmt"
+
+	"github.com/google/taxinomia/core/internal/grouping"
+	"github.com/google/taxinomia/core/query"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// AggregateFill carries the aggregates of a grouped cell that was rendered before they were
+// computed. The renderer streams it after the page to replace the placeholder with id ID.
+type AggregateFill struct {
+	ID   string
+	Cell GroupedCell // Cell with the computed aggregates
+}
+
+// AggregateFiller builds the fills for the placeholders of a view model whose groups were
+// rendered before their aggregates were computed.
+type AggregateFiller struct {
+	tableView *tables.TableView
+	q         *query.Query
+	pending   map[string]bool // IDs of the placeholders on the page
+}
+
+// NewAggregateFiller returns a filler for the pending aggregates of vm.
+func NewAggregateFiller(vm TableViewModel, tableView *tables.TableView) *AggregateFiller {
+	pending := make(map[string]bool)
+	for _, row := range vm.GroupedRows {
+		for _, cell := range row.Cells {
+			if cell.AggregatesPending {
+				pending[cell.AggregatesID] = true
+			}
+		}
+	}
+	return &AggregateFiller{tableView: tableView, q: vm.query, pending: pending}
+}
+
+// Fills returns the fills for a group and its descendants, whose aggregates must be computed.
+// Groups cut off by the display limit have no placeholder and are skipped.
+func (f *AggregateFiller) Fills(group *grouping.Group) []AggregateFill {
+	var fills []AggregateFill
+	f.appendFills(group, &fills)
+	return fills
+}
+
+func (f *AggregateFiller) appendFills(group *grouping.Group, fills *[]AggregateFill) {
+	colName := group.Block.GroupedColumn.DataColumn.ColumnDef().Name()
+	aggSort := f.q.GetGroupAggSort(colName)
+	explainURL := groupRowsURL(f.q, f.tableView, group)
+
+	if group.ChildBlock != nil {
+		if id := aggregatesID(group, ""); f.pending[id] {
+			*fills = append(*fills, AggregateFill{ID: id, Cell: GroupedCell{
+				IsGroupedColumn:  true,
+				ColumnName:       colName,
+				ColumnAggregates: groupAggregates(f.tableView, group, f.q, f.tableView.GetLeafColumns(), aggSort),
+				ExplainURL:       explainURL,
+			}})
+		}
+		for _, child := range group.ChildBlock.Groups {
+			f.appendFills(child, fills)
+		}
+		return
+	}
+	for _, leafColName := range f.tableView.GetLeafColumns() {
+		if id := aggregatesID(group, leafColName); f.pending[id] {
+			*fills = append(*fills, AggregateFill{ID: id, Cell: GroupedCell{
+				ColumnName:       leafColName,
+				ColumnAggregates: groupAggregates(f.tableView, group, f.q, []string{leafColName}, aggSort),
+				ExplainURL:       explainURL,
+			}})
+		}
+	}
+}
+
+// aggregatesDeferred reports whether the aggregates of a group for the given leaf columns are
+// enabled but still being computed.
+func aggregatesDeferred(tableView *tables.TableView, group *grouping.Group, q *query.Query, leafColumns []string) bool {
+	if group.Aggregates != nil || !tableView.AggregatesPending() {
+		return false
+	}
+	for _, leafColName := range leafColumns {
+		if len(q.GetEnabledAggregates(leafColName, tableView.GetColumnType(leafColName))) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// aggregatesID identifies the aggregates of a group in a leaf column ("" for the grouped
+// column cell). Groups of a level partition the rows, so the first row identifies the group.
+func aggregatesID(group *grouping.Group, leafColumn string) string {
+	if len(group.Indices) == 0 {
+		return ""
+	}
+	id := fmt.Sprintf("agg-%d-%d", group.Block.GroupedColumn.Level, group.Indices[0])
+	if leafColumn != "" {
+		id += "-" + leafColumn
+	}
+	return id
+}
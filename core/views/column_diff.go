@@ -0,0 +1,44 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import "strconv"
+
+// buildDiffRows reports for each row whether the values of columns a and b differ.
+func buildDiffRows(rows []map[string]string, a, b string) []bool {
+	diffRows := make([]bool, len(rows))
+	for i, row := range rows {
+		diffRows[i] = valuesDiffer(row[a], row[b])
+	}
+	return diffRows
+}
+
+// valuesDiffer reports whether two displayed cell values differ. Values that
+// both parse as numbers are compared numerically, so "5" and "5.0" are equal.
+func valuesDiffer(a, b string) bool {
+	if a == b {
+		return false
+	}
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return fa != fb
+	}
+	return true
+}
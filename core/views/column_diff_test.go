@@ -0,0 +1,38 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import "testing"
+
+func TestBuildDiffRows(t *testing.T) {
+	rows := []map[string]string{
+		{"a": "10", "b": "10.0"},
+		{"a": "10", "b": "12"},
+		{"a": "north", "b": "north"},
+		{"a": "north", "b": "south"},
+		{"a": "", "b": "3"},
+	}
+	got := buildDiffRows(rows, "a", "b")
+	want := []bool{false, true, false, true, true}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("row %d (%v): differ = %v, want %v", i, rows[i], got[i], want[i])
+		}
+	}
+}
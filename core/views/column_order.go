@@ -0,0 +1,132 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import (
+	"sort"
+
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// ColumnRole is the part a column plays in a table, used to order the columns of
+// tables opened without an explicit column order
+type ColumnRole int
+
+const (
+	ColumnRolePrimaryKey  ColumnRole = iota // The key column of the table's primary entity type
+	ColumnRoleEntity                        // Columns linking to entities (foreign keys)
+	ColumnRoleCategorical                   // Booleans, labeled codes and low-cardinality strings
+	ColumnRoleMetric                        // Numeric columns that are not identifiers
+	ColumnRoleOther                         // Everything else (free text, timestamps, ...)
+)
+
+// DefaultColumnOrder orders keys first, then entity links, categories and metrics
+var DefaultColumnOrder = []ColumnRole{
+	ColumnRolePrimaryKey, ColumnRoleEntity, ColumnRoleCategorical, ColumnRoleMetric, ColumnRoleOther,
+}
+
+// categoricalSampleRows is the number of leading rows sampled to detect low-cardinality columns
+const categoricalSampleRows = 1000
+
+// categoricalMaxValues is the maximum number of distinct sampled values of a categorical column
+const categoricalMaxValues = 50
+
+// OrderColumns returns the columns of a table ordered by role, in the given order of roles.
+// Columns of roles missing from the order come last. Columns of the same role are sorted
+// by name. primaryKeyEntityType is the entity type of the table's primary key, or empty
+// to take the first unique entity or identifier column as the key.
+func OrderColumns(table *tables.DataTable, primaryKeyEntityType string, order []ColumnRole) []string {
+	names := table.GetColumnNames()
+	sort.Strings(names)
+
+	rank := make(map[ColumnRole]int, len(order))
+	for i, role := range order {
+		if _, ok := rank[role]; !ok {
+			rank[role] = i
+		}
+	}
+	ranks := make(map[string]int, len(names))
+	hasPrimaryKey := false
+	for _, name := range names {
+		role := columnRole(table.GetColumn(name), primaryKeyEntityType, hasPrimaryKey)
+		hasPrimaryKey = hasPrimaryKey || role == ColumnRolePrimaryKey
+		if r, ok := rank[role]; ok {
+			ranks[name] = r
+		} else {
+			ranks[name] = len(order)
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool { return ranks[names[i]] < ranks[names[j]] })
+	return names
+}
+
+// columnRole returns the role of a column. Only one column of a table is its primary key.
+func columnRole(col columns.IDataColumn, primaryKeyEntityType string, hasPrimaryKey bool) ColumnRole {
+	def := col.ColumnDef()
+	entityType := def.EntityType()
+	if !hasPrimaryKey && col.IsKey() {
+		if primaryKeyEntityType != "" && entityType == primaryKeyEntityType ||
+			primaryKeyEntityType == "" && (entityType != "" || columns.IsIdentifier(col)) {
+			return ColumnRolePrimaryKey
+		}
+	}
+	if entityType != "" {
+		return ColumnRoleEntity
+	}
+	if len(def.ValueLabels()) > 0 {
+		return ColumnRoleCategorical
+	}
+	switch col.(type) {
+	case interface{ GetValue(uint32) (bool, error) }:
+		return ColumnRoleCategorical
+	case interface{ GetValue(uint32) (string, error) }:
+		if isLowCardinality(col) {
+			return ColumnRoleCategorical
+		}
+		return ColumnRoleOther
+	case interface{ GetValue(uint32) (uint32, error) },
+		interface{ GetValue(uint32) (int64, error) },
+		interface{ GetValue(uint32) (uint64, error) },
+		interface{ GetValue(uint32) (float64, error) }:
+		if columns.IsIdentifier(col) {
+			return ColumnRoleOther
+		}
+		return ColumnRoleMetric
+	}
+	return ColumnRoleOther
+}
+
+// isLowCardinality returns whether the sampled rows of a column repeat their values,
+// with at most categoricalMaxValues distinct values
+func isLowCardinality(col columns.IDataColumn) bool {
+	rows := min(col.Length(), categoricalSampleRows)
+	distinct := make(map[string]bool)
+	for i := 0; i < rows; i++ {
+		v, err := col.GetString(uint32(i))
+		if err != nil {
+			continue
+		}
+		distinct[v] = true
+		if len(distinct) > categoricalMaxValues {
+			return false
+		}
+	}
+	return len(distinct) > 0 && 2*len(distinct) <= rows
+}
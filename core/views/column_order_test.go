@@ -0,0 +1,67 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/tables"
+)
+
+func TestOrderColumns(t *testing.T) {
+	table := tables.NewDataTable()
+	stringColumn := func(name, entityType string, values ...string) {
+		col := columns.NewStringColumn(columns.NewColumnDef(name, name, entityType))
+		for _, v := range values {
+			col.Append(v)
+		}
+		col.FinalizeColumn()
+		table.AddColumn(col)
+	}
+	stringColumn("note", "", "a", "b", "c", "d", "e", "f")
+	stringColumn("status", "", "open", "closed", "open", "open", "closed", "open")
+	stringColumn("customer", "customer", "c1", "c2", "c1", "c3", "c2", "c1")
+	id := columns.NewUint32Column(columns.NewColumnDef("id", "id", ""))
+	amount := columns.NewFloat64Column(columns.NewColumnDef("amount", "amount", ""))
+	active := columns.NewBoolColumn(columns.NewColumnDef("active", "active", ""))
+	for i := range 6 {
+		id.Append(uint32(i + 1))
+		amount.Append(float64(i) * 1.5)
+		active.Append(i%2 == 0)
+	}
+	id.FinalizeColumn()
+	table.AddColumn(id)
+	table.AddColumn(amount)
+	table.AddColumn(active)
+
+	got := OrderColumns(table, "", DefaultColumnOrder)
+	want := []string{"id", "customer", "active", "status", "amount", "note"}
+	if !slices.Equal(got, want) {
+		t.Errorf("OrderColumns = %v, want %v", got, want)
+	}
+
+	// Roles missing from the order come last, by name
+	got = OrderColumns(table, "", []ColumnRole{ColumnRoleMetric, ColumnRolePrimaryKey})
+	want = []string{"amount", "id", "active", "customer", "note", "status"}
+	if !slices.Equal(got, want) {
+		t.Errorf("OrderColumns = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,172 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/safehtml"
+	"github.com/google/taxinomia/core/query"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// DefaultColumnPickerGroupThreshold is the default number of table columns above which
+// the column picker groups columns instead of listing them all. Only expanded groups get
+// full ColumnInfo, so wide tables don't build the whole picker tree on every request.
+const DefaultColumnPickerGroupThreshold = 500
+
+// ColumnPickerPageSize is the maximum number of columns in one picker group.
+// Larger groups are split into pages that expand independently.
+const ColumnPickerPageSize = 50
+
+// columnGroupPathPrefix marks expansion paths that belong to picker groups rather than columns
+const columnGroupPathPrefix = "@"
+
+// otherColumnsGroup is the label for columns without a category or shared name prefix
+const otherColumnsGroup = "Other"
+
+// ColumnGroup is a collapsible group of columns in the column picker of a wide table
+type ColumnGroup struct {
+	Name         string       // Annotation category or shared column name prefix (with page range if split)
+	ColumnNames  []string     // Columns in this group, sorted by display name
+	Columns      []ColumnInfo // Column info for the group, only populated when expanded
+	VisibleCount int          // Number of columns of this group in the view
+	IsExpanded   bool         // Whether this group is expanded
+	Path         string       // Path for URL encoding (e.g., "@billing/1")
+	ToggleURL    safehtml.URL // URL to toggle expansion
+}
+
+// columnGroupKey returns the picker group of a column: its annotation category if set,
+// otherwise the part of its name before the first "_" or ".".
+func columnGroupKey(tableView *tables.TableView, colName string) string {
+	if col := tableView.GetColumn(colName); col != nil {
+		if category := col.ColumnDef().Category(); category != "" {
+			return category
+		}
+	}
+	if i := strings.IndexAny(colName, "_."); i > 0 {
+		return colName[:i]
+	}
+	return ""
+}
+
+// buildColumnGroups groups the given table columns for the column picker.
+// Returns nil if the table has no more than threshold columns, which are listed directly.
+func buildColumnGroups(tableView *tables.TableView, columnNames []string, threshold int, visibleCols map[string]bool, expanded map[string]bool, q *query.Query) []ColumnGroup {
+	if len(columnNames) <= threshold {
+		return nil
+	}
+
+	// Group by key; groups of a single column go to "Other"
+	byKey := make(map[string][]string)
+	for _, colName := range columnNames {
+		key := columnGroupKey(tableView, colName)
+		byKey[key] = append(byKey[key], colName)
+	}
+	var keys []string
+	var other []string
+	for key, names := range byKey {
+		if key == "" || len(names) < 2 {
+			other = append(other, names...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(other) > 0 {
+		keys = append(keys, otherColumnsGroup)
+		byKey[otherColumnsGroup] = other
+	}
+
+	displayName := func(colName string) string {
+		if col := tableView.GetColumn(colName); col != nil {
+			return col.ColumnDef().DisplayName()
+		}
+		return colName
+	}
+
+	var groups []ColumnGroup
+	for _, key := range keys {
+		names := byKey[key]
+		sort.Slice(names, func(i, j int) bool {
+			return displayName(names[i]) < displayName(names[j])
+		})
+
+		numPages := (len(names) + ColumnPickerPageSize - 1) / ColumnPickerPageSize
+		for page := 0; page < numPages; page++ {
+			start := page * ColumnPickerPageSize
+			end := min(start+ColumnPickerPageSize, len(names))
+
+			name := key
+			if numPages > 1 {
+				name = fmt.Sprintf("%s (%d–%d)", key, start+1, end)
+			}
+			// Expansion paths are comma-separated in the URL
+			path := fmt.Sprintf("%s%s/%d", columnGroupPathPrefix, strings.ReplaceAll(key, ",", " "), page+1)
+
+			group := ColumnGroup{
+				Name:        name,
+				ColumnNames: names[start:end],
+				IsExpanded:  expanded[path],
+				Path:        path,
+				ToggleURL:   BuildToggleExpansionURL(q, path),
+			}
+			for _, colName := range group.ColumnNames {
+				if visibleCols[colName] {
+					group.VisibleCount++
+				}
+			}
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// expandedGroupColumns returns the columns of all expanded groups
+func expandedGroupColumns(groups []ColumnGroup) map[string]bool {
+	result := make(map[string]bool)
+	for _, group := range groups {
+		if group.IsExpanded {
+			for _, colName := range group.ColumnNames {
+				result[colName] = true
+			}
+		}
+	}
+	return result
+}
+
+// populateColumnGroups fills in the ColumnInfo of expanded groups from the built column infos
+func populateColumnGroups(groups []ColumnGroup, allColumns []ColumnInfo) {
+	infos := make(map[string]ColumnInfo, len(allColumns))
+	for _, info := range allColumns {
+		infos[info.Name] = info
+	}
+	for i := range groups {
+		if !groups[i].IsExpanded {
+			continue
+		}
+		for _, colName := range groups[i].ColumnNames {
+			if info, ok := infos[colName]; ok {
+				groups[i].Columns = append(groups[i].Columns, info)
+			}
+		}
+	}
+}
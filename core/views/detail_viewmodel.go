@@ -0,0 +1,138 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import (
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/query"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// DetailViewModel contains data for the standalone detail card of one row, served as a
+// page or as an HTML fragment other tools embed
+type DetailViewModel struct {
+	PageLocale
+	Title    string
+	Subtitle string
+
+	Table         string
+	RowID         string             // Primary key value of the row
+	EntityType    string             // Primary key entity type of the table
+	Fields        []SelectedRowField // All fields of the row, keys and entities first
+	Hierarchies   []HierarchyContext // Hierarchy contexts of the row's primary key
+	RelatedTables []RelatedTable     // Tables that can be filtered by the row's primary key
+	TableURL      string             // The table view with this row selected
+
+	Fragment bool // Render only the card, without the page around it
+}
+
+// BuildDetailViewModel builds the detail card of the row of a table whose primary key,
+// the column of type primaryKeyEntityType, is rowID. q is the request, whose
+// non-table-specific state the links of the card keep. Returns false if the table has no
+// such column or row.
+func BuildDetailViewModel(table *tables.DataTable, tableName, rowID, primaryKeyEntityType string, q *query.Query, urlResolver URLResolver, allURLsResolver AllURLsResolver, hierarchyContextBuilder HierarchyContextBuilder, relatedTablesResolver RelatedTablesResolver) (DetailViewModel, bool) {
+	if primaryKeyEntityType == "" {
+		return DetailViewModel{}, false
+	}
+	columnNames := OrderColumns(table, primaryKeyEntityType, DefaultColumnOrder)
+	columnEntityTypes := make(map[string]string)
+	primaryKeyColumn := ""
+	for _, colName := range columnNames {
+		if entityType := table.GetColumn(colName).ColumnDef().EntityType(); entityType != "" {
+			columnEntityTypes[colName] = entityType
+			if entityType == primaryKeyEntityType && primaryKeyColumn == "" {
+				primaryKeyColumn = colName
+			}
+		}
+	}
+	if primaryKeyColumn == "" {
+		return DetailViewModel{}, false
+	}
+	rowIdx, ok := findRow(table.GetColumn(primaryKeyColumn), rowID)
+	if !ok {
+		return DetailViewModel{}, false
+	}
+
+	vm := DetailViewModel{
+		Table:      tableName,
+		RowID:      rowID,
+		EntityType: primaryKeyEntityType,
+		Fields:     make([]SelectedRowField, 0, len(columnNames)),
+	}
+	rowData := make(map[string]string, len(columnNames))
+	for _, colName := range columnNames {
+		col := table.GetColumn(colName)
+		value, _ := col.GetString(rowIdx)
+		rowData[colName] = value
+		vm.Fields = append(vm.Fields, newSelectedRowField(colName, col.ColumnDef().DisplayName(), value, columnEntityTypes[colName], urlResolver, allURLsResolver))
+	}
+
+	if hierarchyContextBuilder != nil {
+		vm.Hierarchies = hierarchyContextBuilder(q, primaryKeyEntityType, rowID, rowData, columnEntityTypes)
+	}
+	if relatedTablesResolver != nil {
+		vm.RelatedTables = relatedTablesResolver(q, tableName, primaryKeyEntityType, rowID)
+	}
+
+	tableQuery := q.Clone()
+	tableQuery.Path = "table"
+	tableQuery.Table = tableName
+	tableQuery.ClearTableSpecificState()
+	tableQuery.SelectedRowID = rowID
+	vm.TableURL = tableQuery.ToURL()
+	return vm, true
+}
+
+// newSelectedRowField builds the detail panel field of a cell, with the URLs of its entity
+func newSelectedRowField(colName, displayName, value, entityType string, urlResolver URLResolver, allURLsResolver AllURLsResolver) SelectedRowField {
+	if displayName == "" {
+		displayName = colName
+	}
+	field := SelectedRowField{
+		ColumnName:  colName,
+		DisplayName: displayName,
+		Value:       value,
+		EntityType:  entityType,
+	}
+	if entityType != "" && value != "" {
+		if urlResolver != nil {
+			field.ValueURL = urlResolver(entityType, value)
+		}
+		if allURLsResolver != nil {
+			field.EntityURLs = allURLsResolver(entityType, value)
+		}
+	}
+	return field
+}
+
+// findRow returns the index of the row whose value in col is value, using the value
+// index of key columns when the column keeps one
+func findRow(col columns.IDataColumn, value string) (uint32, bool) {
+	if indexed, ok := col.(interface{ GetIndex(string) (uint32, error) }); ok {
+		if idx, err := indexed.GetIndex(value); err == nil {
+			return idx, true
+		}
+	}
+	for i := 0; i < col.Length(); i++ {
+		if v, err := col.GetString(uint32(i)); err == nil && v == value {
+			return uint32(i), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,72 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import "strings"
+
+// DefaultLocale is the locale of views when neither the user nor the server sets one.
+const DefaultLocale = "en"
+
+// Text directions of the rendered pages, as values of the HTML dir attribute.
+const (
+	DirectionLTR = "ltr"
+	DirectionRTL = "rtl"
+)
+
+// rtlLanguages lists the languages written right to left, by ISO 639 code.
+var rtlLanguages = map[string]bool{
+	"ar": true, "arc": true, "ckb": true, "dv": true, "fa": true, "he": true, "iw": true,
+	"ps": true, "sd": true, "syr": true, "ug": true, "ur": true, "yi": true,
+}
+
+// rtlScripts lists the scripts written right to left, by ISO 15924 code, for languages
+// written in several scripts (e.g., "az-Arab").
+var rtlScripts = map[string]bool{
+	"arab": true, "hebr": true, "syrc": true, "thaa": true, "nkoo": true, "adlm": true,
+}
+
+// TextDirection returns the direction of text in a BCP 47 locale: DirectionRTL for
+// languages or scripts written right to left, DirectionLTR otherwise.
+func TextDirection(locale string) string {
+	subtags := strings.Split(strings.ToLower(strings.ReplaceAll(locale, "_", "-")), "-")
+	if rtlLanguages[subtags[0]] {
+		return DirectionRTL
+	}
+	for _, subtag := range subtags[1:] {
+		if len(subtag) == 4 && rtlScripts[subtag] {
+			return DirectionRTL
+		}
+	}
+	return DirectionLTR
+}
+
+// PageLocale is the language and text direction of a rendered page, embedded in the view
+// models of all pages.
+type PageLocale struct {
+	Lang string // BCP 47 locale, the lang attribute of the page
+	Dir  string // DirectionLTR or DirectionRTL, the dir attribute of the page
+}
+
+// NewPageLocale returns the page locale of a BCP 47 locale, DefaultLocale if empty.
+func NewPageLocale(locale string) PageLocale {
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	return PageLocale{Lang: locale, Dir: TextDirection(locale)}
+}
@@ -0,0 +1,41 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import "testing"
+
+func TestTextDirection(t *testing.T) {
+	tests := map[string]string{
+		"":           DirectionLTR,
+		"en":         DirectionLTR,
+		"en-US":      DirectionLTR,
+		"he":         DirectionRTL,
+		"he-IL":      DirectionRTL,
+		"ar_EG":      DirectionRTL,
+		"fa-IR":      DirectionRTL,
+		"az-Arab-IR": DirectionRTL,
+		"az-Latn":    DirectionLTR,
+		"hr":         DirectionLTR,
+	}
+	for locale, want := range tests {
+		if got := TextDirection(locale); got != want {
+			t.Errorf("TextDirection(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
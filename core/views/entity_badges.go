@@ -0,0 +1,104 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import (
+	"sort"
+
+	"github.com/google/taxinomia/core/tables"
+)
+
+// EntityBadge is one entity in the breadcrumb of badges of a flat row
+type EntityBadge struct {
+	Column string // Column holding the entity
+	Label  string // Display name of the column (e.g., "Rack")
+	Value  string // Entity value
+	URL    string // Entity URL (empty if none resolves)
+}
+
+// EntityBadgeColumns returns the entity-typed columns of a table that make up the badge
+// breadcrumb of its rows: columns whose entity type is a level of a hierarchy come first,
+// root first, then the other entity columns by name. hierarchies lists the entity types
+// of each hierarchy from root to leaf. Returns nil for tables with fewer than two entity
+// columns, whose rows need no breadcrumb.
+func EntityBadgeColumns(table *tables.DataTable, hierarchies [][]string) []string {
+	type rank struct{ hierarchy, level int }
+	levels := make(map[string]rank)
+	for h, hierarchy := range hierarchies {
+		for l, entityType := range hierarchy {
+			if _, ok := levels[entityType]; !ok {
+				levels[entityType] = rank{h, l}
+			}
+		}
+	}
+
+	var badgeColumns []string
+	ranks := make(map[string]rank)
+	for _, name := range table.GetColumnNames() {
+		entityType := table.GetColumn(name).ColumnDef().EntityType()
+		if entityType == "" {
+			continue
+		}
+		badgeColumns = append(badgeColumns, name)
+		if r, ok := levels[entityType]; ok {
+			ranks[name] = r
+		} else {
+			ranks[name] = rank{len(hierarchies), 0}
+		}
+	}
+	if len(badgeColumns) < 2 {
+		return nil
+	}
+	sort.Slice(badgeColumns, func(i, j int) bool {
+		ri, rj := ranks[badgeColumns[i]], ranks[badgeColumns[j]]
+		if ri != rj {
+			return ri.hierarchy < rj.hierarchy || ri.hierarchy == rj.hierarchy && ri.level < rj.level
+		}
+		return badgeColumns[i] < badgeColumns[j]
+	})
+	return badgeColumns
+}
+
+// buildEntityBadges builds the breadcrumb of each row from its values of the badge
+// columns. Empty values are left out.
+func buildEntityBadges(tableView *tables.TableView, rows []map[string]string, badgeColumns []string, urlResolver URLResolver) [][]EntityBadge {
+	labels := make(map[string]string, len(badgeColumns))
+	entityTypes := make(map[string]string, len(badgeColumns))
+	for _, colName := range badgeColumns {
+		def := tableView.GetColumn(colName).ColumnDef()
+		labels[colName] = def.DisplayName()
+		entityTypes[colName] = def.EntityType()
+	}
+
+	badges := make([][]EntityBadge, len(rows))
+	for i, row := range rows {
+		for _, colName := range badgeColumns {
+			value := row[colName]
+			if value == "" {
+				continue
+			}
+			badge := EntityBadge{Column: colName, Label: labels[colName], Value: value}
+			if urlResolver != nil {
+				badge.URL = urlResolver(entityTypes[colName], value)
+			}
+			badges[i] = append(badges[i], badge)
+		}
+	}
+	return badges
+}
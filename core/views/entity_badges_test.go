@@ -0,0 +1,60 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/tables"
+)
+
+func TestEntityBadgeColumns(t *testing.T) {
+	table := tables.NewDataTable()
+	for _, def := range [][2]string{{"server", "server"}, {"zone", "zone"}, {"owner", "user"}, {"note", ""}, {"rack", "rack"}} {
+		col := columns.NewStringColumn(columns.NewColumnDef(def[0], def[0], def[1]))
+		col.Append("x")
+		col.FinalizeColumn()
+		table.AddColumn(col)
+	}
+
+	// Hierarchy levels come first, root first, then the other entity columns by name
+	got := EntityBadgeColumns(table, [][]string{{"zone", "rack", "server"}})
+	want := []string{"zone", "rack", "server", "owner"}
+	if !slices.Equal(got, want) {
+		t.Errorf("EntityBadgeColumns = %v, want %v", got, want)
+	}
+
+	got = EntityBadgeColumns(table, nil)
+	want = []string{"owner", "rack", "server", "zone"}
+	if !slices.Equal(got, want) {
+		t.Errorf("EntityBadgeColumns without hierarchies = %v, want %v", got, want)
+	}
+
+	// A single entity column needs no breadcrumb
+	single := tables.NewDataTable()
+	col := columns.NewStringColumn(columns.NewColumnDef("server", "server", "server"))
+	col.Append("s1")
+	col.FinalizeColumn()
+	single.AddColumn(col)
+	if got := EntityBadgeColumns(single, nil); got != nil {
+		t.Errorf("EntityBadgeColumns of a single entity column = %v, want nil", got)
+	}
+}
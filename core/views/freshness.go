@@ -0,0 +1,83 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// StaleColumnThreshold is how much older than the table's own columns a column must be
+// to be marked as stale, so that tables loaded one after the other at startup do not
+// mark each other's joined columns.
+const StaleColumnThreshold = 5 * time.Minute
+
+// FormatAge formats a duration as a coarse age (e.g., "5m ago", "3h ago", "2d ago")
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// columnFreshness describes when the values of each of colNames were last updated, and
+// marks the ones more than StaleColumnThreshold older than the newest of the table's own
+// columns, such as joined columns of a table that was not reloaded with this one.
+// Columns without an update time are left out.
+func columnFreshness(tableView *tables.TableView, colNames []string, now time.Time) (map[string]string, map[string]bool) {
+	var tableUpdatedAt time.Time
+	for _, colName := range tableView.GetColumnNames() {
+		col := tableView.GetColumn(colName)
+		if _, joined := col.(columns.IJoinedDataColumn); joined {
+			continue
+		}
+		if t := col.ColumnDef().UpdatedAt(); t.After(tableUpdatedAt) {
+			tableUpdatedAt = t
+		}
+	}
+
+	updated := make(map[string]string)
+	stale := make(map[string]bool)
+	for _, colName := range colNames {
+		col := tableView.GetColumn(colName)
+		if col == nil {
+			continue
+		}
+		t := col.ColumnDef().UpdatedAt()
+		if t.IsZero() {
+			continue
+		}
+		updated[colName] = fmt.Sprintf("Updated %s (%s)", t.Format(time.DateTime), FormatAge(now.Sub(t)))
+		if tableUpdatedAt.Sub(t) > StaleColumnThreshold {
+			stale[colName] = true
+			updated[colName] += fmt.Sprintf(", %s older than the table", strings.TrimSuffix(FormatAge(tableUpdatedAt.Sub(t)), " ago"))
+		}
+	}
+	return updated, stale
+}
@@ -0,0 +1,112 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+
+	"github.com/google/safehtml"
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/query"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// exampleIndices picks count example rows among the row indices of a group: its first
+// rows, or with random a sample kept in group order. The sample is seeded by the group's
+// rows, so that reloading the same view shows the same examples.
+func exampleIndices(indices []uint32, count int, random bool) []uint32 {
+	if count <= 0 || len(indices) == 0 {
+		return nil
+	}
+	if count >= len(indices) {
+		return slices.Clone(indices)
+	}
+	if !random {
+		return slices.Clone(indices[:count])
+	}
+
+	rng := rand.New(rand.NewPCG(uint64(indices[0]), uint64(len(indices))))
+	picked := make(map[int]bool, count)
+	positions := make([]int, 0, count)
+	for len(positions) < count {
+		pos := rng.IntN(len(indices))
+		if !picked[pos] {
+			picked[pos] = true
+			positions = append(positions, pos)
+		}
+	}
+	slices.Sort(positions)
+
+	sample := make([]uint32, count)
+	for i, pos := range positions {
+		sample[i] = indices[pos]
+	}
+	return sample
+}
+
+// exampleValues returns the labeled values of a column at the example rows.
+func exampleValues(tableView *tables.TableView, colName string, rows []uint32) []string {
+	col := tableView.GetColumn(colName)
+	if col == nil || len(rows) == 0 {
+		return nil
+	}
+	values := make([]string, len(rows))
+	for i, row := range rows {
+		value, err := col.GetString(row)
+		switch {
+		case errors.Is(err, columns.ErrUnmatched):
+			values[i] = columns.UnmatchedLabel
+		case err != nil:
+			values[i] = columns.ErrorLabel
+		default:
+			values[i] = col.ColumnDef().Label(value)
+		}
+	}
+	return values
+}
+
+// DefaultExampleRows is the number of example rows per group offered by the example rows
+// toggle of grouped views, when the query does not ask for another number
+const DefaultExampleRows = 3
+
+// ExampleRowsOption is a choice of the example rows toggle of grouped views
+type ExampleRowsOption struct {
+	Label  string
+	Title  string
+	URL    safehtml.URL
+	Active bool
+}
+
+// buildExampleRowsOptions returns the choices of the example rows toggle: no examples,
+// the first rows of each group, or a random sample of each group.
+func buildExampleRowsOptions(q *query.Query) []ExampleRowsOption {
+	count := q.ExampleRows
+	if count == 0 {
+		count = DefaultExampleRows
+	}
+	n := strconv.Itoa(count)
+	return []ExampleRowsOption{
+		{Label: "off", Title: "Show groups without example rows", URL: q.WithExampleRows(0, false), Active: q.ExampleRows == 0},
+		{Label: n + " first", Title: "Show the first " + n + " rows of each group", URL: q.WithExampleRows(count, false), Active: q.ExampleRows > 0 && !q.RandomExampleRows},
+		{Label: n + " random", Title: "Show " + n + " random rows of each group", URL: q.WithExampleRows(count, true), Active: q.ExampleRows > 0 && q.RandomExampleRows},
+	}
+}
@@ -0,0 +1,51 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExampleIndices(t *testing.T) {
+	group := []uint32{3, 7, 8, 12, 20, 21, 40}
+
+	if got := exampleIndices(group, 3, false); !slices.Equal(got, []uint32{3, 7, 8}) {
+		t.Errorf("first examples = %v, want [3 7 8]", got)
+	}
+	if got := exampleIndices(group, 10, true); !slices.Equal(got, group) {
+		t.Errorf("examples of a small group = %v, want the whole group", got)
+	}
+	if got := exampleIndices(group, 0, false); got != nil {
+		t.Errorf("zero examples = %v, want none", got)
+	}
+
+	sample := exampleIndices(group, 3, true)
+	if len(sample) != 3 || !slices.IsSorted(sample) {
+		t.Fatalf("random examples = %v, want 3 rows in group order", sample)
+	}
+	for _, row := range sample {
+		if !slices.Contains(group, row) {
+			t.Errorf("random example %d is not in the group", row)
+		}
+	}
+	if again := exampleIndices(group, 3, true); !slices.Equal(again, sample) {
+		t.Errorf("random examples changed between calls: %v, then %v", sample, again)
+	}
+}
@@ -20,6 +20,7 @@ package views
 
 // LandingViewModel contains data for the landing page template
 type LandingViewModel struct {
+	PageLocale
 	Title    string
 	Subtitle string
 	Tables   []TableInfo
@@ -0,0 +1,61 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+// OverviewViewModel contains data for the deployment overview page
+type OverviewViewModel struct {
+	PageLocale
+	Title    string
+	Subtitle string
+
+	// Deployment totals (system tables excluded)
+	TableCount   int
+	TotalRows    int
+	TotalColumns int
+	HeapInUse    string // Go heap memory in use, human-readable
+
+	MostViewed     []TableViewsInfo // Tables ordered by descending view count
+	SlowestQueries []SlowQueryInfo  // Views with the slowest requests of the day
+	Freshness      []TableFreshness // Load time of each table, oldest first
+}
+
+// TableViewsInfo describes how often a table has been viewed
+type TableViewsInfo struct {
+	Name  string
+	URL   string
+	Views int
+}
+
+// SlowQueryInfo describes the slowest request of the day of a view
+type SlowQueryInfo struct {
+	Table      string
+	View       string // Columns and grouping of the view, without filters
+	URL        string
+	DurationMs string
+	At         string // Time of day the request was served
+}
+
+// TableFreshness describes when a table's data was loaded
+type TableFreshness struct {
+	Name     string
+	URL      string
+	Rows     int
+	LoadedAt string // Empty if the load time is unknown
+	Age      string // Human-readable time since load
+}
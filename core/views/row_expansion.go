@@ -0,0 +1,112 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/safehtml"
+	"github.com/google/taxinomia/core/query"
+)
+
+// ExpandableCellLength is the number of characters above which a cell value is
+// treated as long text that the fixed-width table cell truncates.
+const ExpandableCellLength = 40
+
+// RowExpansion describes whether a flat row can reveal, or currently reveals,
+// the full content of its long-text and JSON cells.
+type RowExpansion struct {
+	Expandable bool              // Whether any cell holds long text or JSON
+	Expanded   bool              // Whether the row shows its full cell content
+	ToggleURL  safehtml.URL      // URL that expands or collapses the row
+	Cells      map[string]string // Full cell content of an expanded row, with JSON pretty-printed
+}
+
+// rowKeys returns the stable key of each flat row (parallel to rows): its primary key
+// value, or its position when the table has no primary key.
+func rowKeys(rows []map[string]string, rowIDs []string) []string {
+	keys := make([]string, len(rows))
+	for i := range rows {
+		if i < len(rowIDs) && rowIDs[i] != "" {
+			keys[i] = rowIDs[i]
+		} else {
+			keys[i] = strconv.Itoa(i)
+		}
+	}
+	return keys
+}
+
+// buildRowExpansions computes the expansion state of each flat row (parallel to rows).
+func buildRowExpansions(q *query.Query, rows []map[string]string, keys []string, columns []string) []RowExpansion {
+	expansions := make([]RowExpansion, len(rows))
+	for i, row := range rows {
+		exp := &expansions[i]
+		for _, colName := range columns {
+			if isExpandableValue(row[colName]) {
+				exp.Expandable = true
+				break
+			}
+		}
+		if !exp.Expandable {
+			continue
+		}
+		exp.ToggleURL = q.WithExpandedRowToggled(keys[i])
+		if !q.IsRowExpanded(keys[i]) {
+			continue
+		}
+		exp.Expanded = true
+		exp.Cells = make(map[string]string, len(columns))
+		for _, colName := range columns {
+			exp.Cells[colName] = expandedCellValue(row[colName])
+		}
+	}
+	return expansions
+}
+
+// isExpandableValue reports whether a cell value is too long or structured to read in a truncated cell.
+func isExpandableValue(value string) bool {
+	return utf8.RuneCountInString(value) > ExpandableCellLength ||
+		strings.Contains(value, "\n") ||
+		isJSONValue(value)
+}
+
+// isJSONValue reports whether a value is a JSON object or array.
+func isJSONValue(value string) bool {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return false
+	}
+	return json.Valid([]byte(trimmed))
+}
+
+// expandedCellValue returns the full content of a cell, pretty-printing JSON objects and arrays.
+func expandedCellValue(value string) string {
+	if !isJSONValue(value) {
+		return value
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(strings.TrimSpace(value)), "", "  "); err != nil {
+		return value
+	}
+	return buf.String()
+}
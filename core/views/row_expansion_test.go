@@ -0,0 +1,78 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/taxinomia/core/query"
+)
+
+func TestBuildRowExpansions(t *testing.T) {
+	u, _ := url.Parse("/table?table=events&expandrows=e2")
+	q := query.NewQuery(u)
+	rows := []map[string]string{
+		{"id": "e1", "payload": "short"},
+		{"id": "e2", "payload": `{"kind":"click","tags":["a"]}`},
+		{"id": "e3", "payload": strings.Repeat("x", ExpandableCellLength+1)},
+	}
+	exps := buildRowExpansions(q, rows, []string{"e1", "e2", "e3"}, []string{"id", "payload"})
+
+	if exps[0].Expandable {
+		t.Errorf("row with short values should not be expandable")
+	}
+	if !exps[1].Expandable || !exps[1].Expanded {
+		t.Fatalf("JSON row should be expandable and expanded, got %+v", exps[1])
+	}
+	want := "{\n  \"kind\": \"click\",\n  \"tags\": [\n    \"a\"\n  ]\n}"
+	if got := exps[1].Cells["payload"]; got != want {
+		t.Errorf("expanded JSON = %q, want %q", got, want)
+	}
+	if !strings.Contains(exps[1].ToggleURL.String(), "table=events") || strings.Contains(exps[1].ToggleURL.String(), "expandrows") {
+		t.Errorf("toggle URL of expanded row should collapse it, got %s", exps[1].ToggleURL)
+	}
+	if !exps[2].Expandable || exps[2].Expanded || exps[2].Cells != nil {
+		t.Errorf("long text row should be expandable but collapsed, got %+v", exps[2])
+	}
+	if !strings.Contains(exps[2].ToggleURL.String(), "expandrows=e2&expandrows=e3") {
+		t.Errorf("toggle URL should add the row to the expanded rows, got %s", exps[2].ToggleURL)
+	}
+}
+
+func TestBuildRowExpansionsWithoutPrimaryKey(t *testing.T) {
+	u, _ := url.Parse("/table?table=events&expandrows=1")
+	q := query.NewQuery(u)
+	rows := []map[string]string{
+		{"note": "line one\nline two"},
+		{"note": "[1, 2]"},
+	}
+	keys := rowKeys(rows, nil)
+	if keys[0] != "0" || keys[1] != "1" {
+		t.Fatalf("rows without primary key should be keyed by position, got %q", keys)
+	}
+	exps := buildRowExpansions(q, rows, keys, []string{"note"})
+	if exps[0].Expanded || !exps[1].Expanded {
+		t.Errorf("only the second row should be expanded")
+	}
+	if got := exps[1].Cells["note"]; got != "[\n  1,\n  2\n]" {
+		t.Errorf("expanded JSON array = %q", got)
+	}
+}
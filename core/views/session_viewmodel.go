@@ -0,0 +1,41 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+// SessionViewModel contains data for the page listing the steps of a recorded session
+type SessionViewModel struct {
+	PageLocale
+	Title    string
+	Subtitle string
+
+	SessionID string
+	Steps     []SessionStepInfo
+	Dropped   int    // Steps not recorded because the session reached its step limit
+	TextURL   string // Download of the steps as a plain text list of links
+}
+
+// SessionStepInfo describes one step of a recorded session
+type SessionStepInfo struct {
+	Number      int
+	Table       string
+	Description string // Grouping, filters and sorting of the step
+	At          string // Time of day the step was recorded
+	URL         string // The recorded view
+	ReplayURL   string // The recorded view with replay navigation
+}
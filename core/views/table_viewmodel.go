@@ -21,12 +21,15 @@ package views
 import (
 	"fmt"
 	"net/url"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/google/safehtml"
 	"github.com/google/taxinomia/core/aggregates"
-	"github.com/google/taxinomia/core/grouping"
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/internal/grouping"
 	"github.com/google/taxinomia/core/models"
 	"github.com/google/taxinomia/core/query"
 	"github.com/google/taxinomia/core/tables"
@@ -34,9 +37,13 @@ import (
 
 // TableViewModel contains the data from the table formatted for template consumption
 type TableViewModel struct {
+	PageLocale
 	Title                     string
 	PrimaryKeyEntityType      string // The entity type that serves as primary key for this table
 	PrimaryKeyDescription     string // Description of the primary key entity type
+	Readme                    safehtml.HTML // Rendered Markdown documentation for the table
+	HasReadme                 bool          // Whether the table has documentation to show
+	ColumnPresets             []ColumnPresetLink // Column presets defined by the table owners
 	Headers              []string           // Column display names
 	Columns         []string               // Column names (for data access)
 	ColumnWidths    map[string]int         // Column widths in pixels (from URL)
@@ -44,7 +51,9 @@ type TableViewModel struct {
 	RowURLs         []map[string]string    // URLs for each cell in flat rows (parallel to Rows)
 	GroupedRows     []GroupedRow           // Hierarchical rows for grouped display
 	IsGrouped       bool                   // Whether the table is currently grouped
-	AllColumns      []ColumnInfo           // All available columns with metadata
+	ExampleRowsOptions []ExampleRowsOption // Choices of example rows per group (grouped views only)
+	AllColumns      []ColumnInfo           // All available columns with metadata (for wide tables, only those in view or in expanded groups)
+	ColumnGroups    []ColumnGroup          // Column picker groups for wide tables (nil = picker lists AllColumns)
 	ComputedColumns []ComputedColumnInfo   // Computed columns defined by the user
 	CurrentQuery    string                 // Current query string
 	CurrentURL      safehtml.URL           // Current URL for building toggle links
@@ -52,6 +61,7 @@ type TableViewModel struct {
 	ColumnFilters    map[string]string // Filter values for each column (from URL parameters like filter:columnA=abc)
 	ColumnFormulas   map[string]string // Formula for computed columns (columnName -> formula like "concat(a, b)")
 	IsComputedColumn map[string]bool   // Tracks which columns are computed (for UI, even if formula is empty)
+	GroupLabels      map[string]string // Label expressions of grouped columns (columnName -> expression)
 
 	// Pagination info
 	TotalRows     int  // Total number of rows in the table
@@ -62,6 +72,16 @@ type TableViewModel struct {
 	// Validation errors
 	ComputedColumnErrors map[string]ValidationError // Errors for computed columns (columnName -> error)
 	FilterErrors         map[string]ValidationError // Errors for filters (columnName -> error)
+	GroupLabelErrors     map[string]ValidationError // Errors for group label expressions (columnName -> error)
+
+	// Columns referenced by the URL that no longer exist, removed from the view
+	RemovedColumns []string
+	SanitizedURL   safehtml.URL // URL of the view without the removed columns
+
+	// Retained snapshot the view was run against (empty AsOf = current data)
+	AsOf             string       // The asof time as given in the URL
+	SnapshotLoadedAt string       // When the snapshot was loaded (RFC 3339)
+	CurrentDataURL   safehtml.URL // URL of the view on the current data
 
 	// Performance metrics
 	RenderTimeMs   string        // Time to render the page in milliseconds (formatted)
@@ -77,19 +97,72 @@ type TableViewModel struct {
 	// Column types display (controlled via URL)
 	ShowColumnTypes bool              // Whether to show the column types row
 	ColumnTypes     map[string]string // Column internal types (columnName -> type string)
+	ColumnUpdatedAt map[string]string // When the values of each column were last updated (empty = unknown)
+	StaleColumns    map[string]bool   // Columns much older than the table's own columns, e.g. joined from a table not reloaded
 
 	// Entity type info for URL resolution
 	ColumnEntityTypes map[string]string // Column entity types (columnName -> entityType)
 
+	// Display labels for stored codes (columnName -> code -> label), applied when rendering cells
+	ValueLabels map[string]map[string]string
+
 	// Row identification
 	PrimaryKeyColumn string   // Column name containing the primary key values
 	RowIDs           []string // Primary key value for each row (parallel to Rows)
+	RowKeys          []string // Stable key for each row: primary key value, or position without one (parallel to Rows)
+
+	// Side-by-side column comparison (empty = no comparison)
+	DiffColumns  []string     // The two compared columns
+	DiffRows     []bool       // Whether the compared values differ in each row (parallel to Rows)
+	ClearDiffURL safehtml.URL // URL that removes the comparison and its generated columns
+
+	SQLiteExportURL safehtml.URL // URL that downloads the filtered rows of the table as a SQLite database
+
+	// Session recording (empty SessionID = not recording)
+	SessionID       string       // ID of the session recording the views
+	SessionSteps    int          // Number of steps recorded so far
+	SessionStartURL safehtml.URL // URL that starts recording a new session from this view
+	SessionStopURL  safehtml.URL // URL of this view without recording
+	SessionURL      safehtml.URL // Page listing the steps of the recorded or replayed session
+
+	// Session replay (ReplayStep = 0 when not replaying)
+	ReplayStep    int          // Step of the replayed session shown (1-based)
+	ReplaySteps   int          // Number of steps of the replayed session
+	ReplayPrevURL safehtml.URL // Previous step (unset on the first step)
+	ReplayNextURL safehtml.URL // Next step (unset on the last step)
+
+	// Deep-linked cell (empty = no target)
+	TargetRowKey string // Row key of the cell to scroll to and highlight
+	TargetColumn string // Column of the cell to scroll to and highlight
+
+	// Row links
+	RowLinkColumn string   // Column whose entity URL a row click navigates to (empty = row click selects the row)
+	RowLinkURLs   []string // Row click target URL for each row (parallel to Rows, empty = select the row)
+
+	// Breadcrumb of the entities of each row, shown in a synthesized column (nil = no breadcrumbs)
+	EntityBadges [][]EntityBadge // Entity badges for each row, root first (parallel to Rows)
+
+	// Row expansion of long text and JSON cells
+	RowExpansions []RowExpansion // Expansion state for each row (parallel to Rows)
 
 	// Row selection state
 	SelectedRowID           string               // Primary key value of selected row (empty = no selection)
 	SelectedRowData         []SelectedRowField   // Fields of the selected row for detail panel
+	SelectedRowDetailURL    safehtml.URL         // Standalone detail card of the selected row
 	SelectedItemHierarchies []HierarchyContext   // Hierarchy contexts for the selected item's primary key
 	RelatedTables           []RelatedTable       // Tables that can be filtered by the selected item's entity type
+
+	// StreamAggregates is set when pending aggregates are streamed after the page, which is
+	// then left open for them (see views.AggregateFiller)
+	StreamAggregates bool
+
+	// query is the state the view model was built from, used by template helpers to build URLs
+	query *query.Query
+}
+
+// Query returns the query the view model was built from, or nil if it was built without one.
+func (vm TableViewModel) Query() *query.Query {
+	return vm.query
 }
 
 // SelectedRowField represents a single field in the selected row for the detail panel
@@ -191,6 +264,13 @@ type ComputedColumnInfo struct {
 	ToggleURL   safehtml.URL // URL to toggle column visibility
 }
 
+// ColumnPresetLink is a column preset of the table in the preset switcher
+type ColumnPresetLink struct {
+	Name   string       // Name of the preset
+	URL    safehtml.URL // URL showing the columns of the preset with the current filters and grouping
+	Active bool         // Whether the visible columns are those of the preset
+}
+
 // GroupedRow represents a single row in the grouped table display
 type GroupedRow struct {
 	Cells []GroupedCell // Only cells that should be rendered (no skipped cells)
@@ -215,9 +295,21 @@ type GroupedCell struct {
 	// ColumnAggregates holds the formatted aggregates for all leaf columns in this group.
 	// Each entry represents one leaf column with its name and enabled aggregates.
 	ColumnAggregates []aggregates.ColumnAggregateDisplay
+	// ExplainURL lists the ungrouped rows of the group, the rows its aggregates were computed from.
+	ExplainURL safehtml.URL
+	// IsCohort indicates the cell is an ad-hoc cohort merging several values of the grouped column.
+	IsCohort       bool
+	SplitCohortURL safehtml.URL // URL that splits the cohort back into its values
+	// AggregatesPending indicates the aggregates are still being computed. They are streamed
+	// after the page and replace the placeholder with id AggregatesID.
+	AggregatesPending bool
+	AggregatesID      string
 	// IsIncomplete indicates the cell belongs to a group that was truncated due to display limits.
 	// Such cells should be visually distinguished (e.g., light grey background).
 	IsIncomplete bool
+	// Examples holds the values of the leaf column at the example rows of a leaf group,
+	// shown under its aggregates when the query asks for example rows.
+	Examples []string
 }
 
 // AggregateToggle represents a single aggregate toggle button for the UI
@@ -226,6 +318,7 @@ type AggregateToggle struct {
 	Symbol    string              // Display symbol (e.g., "Σ", "μ")
 	Title     string              // Tooltip text
 	IsEnabled bool                // Whether this aggregate is currently enabled
+	Demoted   bool                // Whether this aggregate is meaningless for the column (e.g., the sum of record IDs)
 	ToggleURL safehtml.URL        // URL to toggle this aggregate
 }
 
@@ -238,6 +331,8 @@ type ColumnInfo struct {
 	IsFiltered        bool               // Whether column has an active filter
 	HasEntityType     bool               // Whether column defines an entity type
 	IsKey             bool               // Whether column has all unique values
+	IsUncurated       bool               // Whether column was loaded as a pass-through string column without annotation
+	JoinWarning       string             // Warning shown in the header of a joined column with a skewed join (empty if none)
 	JoinTargets       []JoinTarget       // Tables/columns this column can join to
 	IsExpanded        bool               // Whether this column's join list is expanded
 	Path              string             // Path for URL encoding (e.g., "column1")
@@ -295,7 +390,7 @@ func getColumnType(colName string, tableView *tables.TableView) query.ColumnType
 
 // buildAggregateToggles creates the aggregate toggle buttons for a column
 func buildAggregateToggles(colName string, colType query.ColumnType, q *query.Query) []AggregateToggle {
-	availableAggs := query.GetAvailableAggregates(colType)
+	availableAggs := q.AvailableAggregates(colName, colType)
 	toggles := make([]AggregateToggle, 0, len(availableAggs))
 
 	for _, aggType := range availableAggs {
@@ -304,6 +399,7 @@ func buildAggregateToggles(colName string, colType query.ColumnType, q *query.Qu
 			Symbol:    query.AggregateSymbol(aggType),
 			Title:     query.AggregateTitle(aggType),
 			IsEnabled: q.IsAggregateEnabled(colName, aggType),
+			Demoted:   q.IsAggregateDemoted(colName, aggType),
 			ToggleURL: q.WithAggregateToggled(colName, aggType),
 		})
 	}
@@ -361,8 +457,10 @@ func buildJoinTargetsForColumn(dataModel *models.DataModel, tableName, columnNam
 		toColumnKey := toParts[1]
 
 		// Check if this join involves our column
+		outgoing := false
 		if fromTableKey == tableName && fromColumnKey == columnName {
 			// This is an outgoing join
+			outgoing = true
 			targetTableName = toTableKey
 			targetColumnName = toColumnKey
 		} else if toTableKey == tableName && toColumnKey == columnName {
@@ -487,6 +585,19 @@ func buildJoinTargetsForColumn(dataModel *models.DataModel, tableName, columnNam
 				sort.Slice(availableColumns, func(i, j int) bool {
 					return availableColumns[i].DisplayName < availableColumns[j].DisplayName
 				})
+
+				// Fuzzy joins also offer the confidence of each match
+				if outgoing && join.IsFuzzy() {
+					columnFullName := fmt.Sprintf("%s.%s.%s.%s", columnNamePrefix, targetTableName, targetColumnName, columns.MatchConfidenceColumn)
+					availableColumns = append(availableColumns, ColumnSummary{
+						Name:         columns.MatchConfidenceColumn,
+						DisplayName:  "Match confidence",
+						TableName:    targetTableName,
+						Path:         fmt.Sprintf("%s/%s", targetPath, columns.MatchConfidenceColumn),
+						AddColumnURL: BuildAddColumnURL(q, columnFullName),
+						IsSelected:   slices.Contains(q.Columns, columnFullName),
+					})
+				}
 				target.AvailableColumns = availableColumns
 			} else {
 				// Not expanded, but mark that columns are available
@@ -500,27 +611,44 @@ func buildJoinTargetsForColumn(dataModel *models.DataModel, tableName, columnNam
 	return joinTargets
 }
 
+// ViewModelOptions holds the optional inputs of BuildViewModel. Zero fields are ignored.
+type ViewModelOptions struct {
+	ComputedColumnErrors    map[string]string             // Errors of computed columns, keyed by column name
+	FilterErrors            map[string]string             // Errors of filters, keyed by column name
+	URLResolver             URLResolver                   // Resolves entity type URLs
+	BatchURLResolver        BatchURLResolver              // Resolves many entity URLs in one call
+	AllURLsResolver         AllURLsResolver               // Resolves all URLs of an entity type (for the detail panel)
+	PrimaryKeyEntityType    string                        // Entity type that serves as the table's primary key
+	RowLinkColumn           string                        // Column whose entity URL a row click navigates to
+	EntityTypeDescResolver  EntityTypeDescriptionResolver // Resolves entity type descriptions
+	HierarchyContextBuilder HierarchyContextBuilder       // Builds hierarchy contexts for the selected item
+	RelatedTablesResolver   RelatedTablesResolver         // Finds tables that can be filtered by the selected item's entity type
+	ColumnPickerThreshold   int                           // Columns above which the picker groups them (0 = DefaultColumnPickerGroupThreshold)
+}
+
 // BuildViewModel creates a ViewModel from a TableView using the specified View
-// computedColErrors and filterErrors are maps of column/filter names to error messages
-// urlResolver is an optional function to resolve entity type URLs (can be nil)
-// allURLsResolver is an optional function to resolve all URLs for an entity type (for detail panel)
-// primaryKeyEntityType is the entity type that serves as the table's primary key (can be empty)
-// entityTypeDescResolver is an optional function to resolve entity type descriptions (can be nil)
-// hierarchyContextBuilder builds hierarchy contexts for the selected item (can be nil)
-// relatedTablesResolver finds tables that can be filtered by the selected item's entity type (can be nil)
-func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *tables.TableView, view View, title string, q *query.Query, computedColErrors, filterErrors map[string]string, urlResolver URLResolver, allURLsResolver AllURLsResolver, primaryKeyEntityType string, entityTypeDescResolver EntityTypeDescriptionResolver, hierarchyContextBuilder HierarchyContextBuilder, relatedTablesResolver RelatedTablesResolver) TableViewModel {
+func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *tables.TableView, view View, title string, q *query.Query, opts ViewModelOptions) TableViewModel {
 	// Generate currentURL from Query
 	currentURL := q.ToSafeURL()
 
+	// Memoize URL resolution for this request - the same values recur in many cells
+	var urlCache *URLCache
+	urlResolver := opts.URLResolver
+	if opts.URLResolver != nil || opts.BatchURLResolver != nil {
+		urlCache = NewURLCache(opts.URLResolver, opts.BatchURLResolver)
+		urlResolver = urlCache.Resolve
+	}
+
 	// Resolve primary key description if we have a resolver and entity type
 	var primaryKeyDescription string
-	if primaryKeyEntityType != "" && entityTypeDescResolver != nil {
-		primaryKeyDescription = entityTypeDescResolver(primaryKeyEntityType)
+	if opts.PrimaryKeyEntityType != "" && opts.EntityTypeDescResolver != nil {
+		primaryKeyDescription = opts.EntityTypeDescResolver(opts.PrimaryKeyEntityType)
 	}
 
 	vm := TableViewModel{
+		query:                     q,
 		Title:                     title,
-		PrimaryKeyEntityType:      primaryKeyEntityType,
+		PrimaryKeyEntityType:      opts.PrimaryKeyEntityType,
 		PrimaryKeyDescription:     primaryKeyDescription,
 		Headers:                   []string{},
 		Columns:              []string{},
@@ -534,12 +662,14 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 		IsComputedColumn:     make(map[string]bool),
 		ComputedColumnErrors: make(map[string]ValidationError),
 		FilterErrors:         make(map[string]ValidationError),
+		GroupLabelErrors:     make(map[string]ValidationError),
 		ColumnTypes:          make(map[string]string),
 		ColumnEntityTypes:    make(map[string]string),
+		ValueLabels:          make(map[string]map[string]string),
 	}
 
 	// Convert error strings to ValidationError structs
-	for colName, errMsg := range computedColErrors {
+	for colName, errMsg := range opts.ComputedColumnErrors {
 		// Find the expression for this column
 		expr := ""
 		for _, comp := range q.ComputedColumns {
@@ -553,7 +683,7 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 			Expression: expr,
 		}
 	}
-	for colName, errMsg := range filterErrors {
+	for colName, errMsg := range opts.FilterErrors {
 		vm.FilterErrors[colName] = ValidationError{
 			Message:    errMsg,
 			Expression: q.Filters[colName],
@@ -562,6 +692,7 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 
 	// Copy filter parameters from Query
 	vm.ColumnFilters = q.Filters
+	vm.SQLiteExportURL = q.ExportURL("sqlite", tableView.GetColumnNames())
 
 	// Copy column widths from Query
 	for colName, width := range q.ColumnWidths {
@@ -576,8 +707,12 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 
 	// Build a map of grouped columns for quick lookup
 	groupedColsMap := make(map[string]bool)
+	vm.GroupLabels = make(map[string]string)
 	for _, colName := range q.GroupedColumns {
 		groupedColsMap[colName] = true
+		if expression, ok := q.GroupLabels[colName]; ok {
+			vm.GroupLabels[colName] = expression
+		}
 	}
 
 	// Build leaf columns list (visible, non-grouped columns) and enabled aggregates for aggregate sort toggle
@@ -593,6 +728,15 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 
 	// Build all columns info (base table columns only)
 	allColumnNames := tableView.GetColumnNames()
+
+	// Wide tables get a grouped column picker; only expanded groups need full column info
+	pickerThreshold := opts.ColumnPickerThreshold
+	if pickerThreshold == 0 {
+		pickerThreshold = DefaultColumnPickerGroupThreshold
+	}
+	vm.ColumnGroups = buildColumnGroups(tableView, allColumnNames, pickerThreshold, visibleCols, view.Expanded, q)
+	expandedPickerColumns := expandedGroupColumns(vm.ColumnGroups)
+
 	for _, colName := range allColumnNames {
 		col := tableView.GetColumn(colName)
 		if col != nil {
@@ -602,10 +746,20 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 			if hasEntityType {
 				vm.ColumnEntityTypes[colName] = entityType
 				// Track the primary key column
-				if entityType == primaryKeyEntityType {
+				if entityType == opts.PrimaryKeyEntityType {
 					vm.PrimaryKeyColumn = colName
 				}
 			}
+			if labels := col.ColumnDef().ValueLabels(); len(labels) > 0 {
+				vm.ValueLabels[colName] = labels
+			}
+
+			// Skip collapsed picker columns of wide tables unless the view uses them
+			if vm.ColumnGroups != nil && !expandedPickerColumns[colName] && !visibleCols[colName] && !q.IsColumnGrouped(colName) {
+				if _, isFiltered := q.Filters[colName]; !isFiltered {
+					continue
+				}
+			}
 
 			// Use the column's IsKey property
 			isKey := col.IsKey()
@@ -657,6 +811,7 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 				IsFiltered:          isFiltered,
 				HasEntityType:       hasEntityType,
 				IsKey:               isKey && hasEntityType, // Only mark as key if it's also an entity type
+				IsUncurated:         col.ColumnDef().IsUncurated(),
 				JoinTargets:         joinTargets,
 				IsExpanded:          isExpanded,
 				Path:                colName,
@@ -732,6 +887,7 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 						IsGrouped:           q.IsColumnGrouped(colName),
 						HasEntityType:       false, // Joined columns don't have entity types in this context
 						IsKey:               false,
+						JoinWarning:         joinSkewWarning(tableView, colName, lastTable),
 						JoinTargets:         nil,
 						IsExpanded:          false,
 						Path:                colName,
@@ -814,6 +970,7 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 	sort.Slice(vm.AllColumns, func(i, j int) bool {
 		return vm.AllColumns[i].DisplayName < vm.AllColumns[j].DisplayName
 	})
+	populateColumnGroups(vm.ColumnGroups, vm.AllColumns)
 
 	// Build a map of computed column names for quick lookup
 	computedColNames := make(map[string]bool)
@@ -857,17 +1014,40 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 		vm.ColumnTypes[colName] = tableView.GetColumnTypeName(colName)
 	}
 
+	// Tell when the values of each column were updated, and which lag behind the table
+	vm.ColumnUpdatedAt, vm.StaleColumns = columnFreshness(tableView, vm.Columns, time.Now())
+
 	// Get filtered row count and rows from TableView
 	totalRows := tableView.GetFilteredRowCount()
 	vm.TotalRows = totalRows
 
+	// The row link column must be fetched even when it is not displayed
+	rowColumns := view.Columns
+	if opts.RowLinkColumn != "" && tableView.GetColumn(opts.RowLinkColumn) != nil {
+		vm.RowLinkColumn = opts.RowLinkColumn
+		if !slices.Contains(rowColumns, opts.RowLinkColumn) {
+			rowColumns = append(slices.Clip(rowColumns), opts.RowLinkColumn)
+		}
+	}
+
+	// The badge columns must be fetched even when they are not displayed
+	var badgeColumns []string
+	for _, colName := range view.BadgeColumns {
+		if tableView.GetColumn(colName) != nil {
+			badgeColumns = append(badgeColumns, colName)
+			if !slices.Contains(rowColumns, colName) {
+				rowColumns = append(slices.Clip(rowColumns), colName)
+			}
+		}
+	}
+
 	// Get filtered rows with limit and sorting applied
 	if len(q.SortOrder) > 0 {
 		// Use sorted version with heap-based top-K selection
-		vm.Rows = tableView.GetFilteredRowsSorted(view.Columns, q.SortOrder, q.Limit)
+		vm.Rows = tableView.GetFilteredRowsSorted(rowColumns, q.SortOrder, q.Limit)
 	} else {
 		// No sorting - use basic filtered rows
-		vm.Rows = tableView.GetFilteredRows(view.Columns, q.Limit)
+		vm.Rows = tableView.GetFilteredRows(rowColumns, q.Limit)
 	}
 	vm.DisplayedRows = len(vm.Rows)
 	vm.CurrentLimit = q.Limit
@@ -881,6 +1061,30 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 		}
 	}
 
+	// Build RowKeys for each row (stable identity for row expansion and cell deep links)
+	vm.RowKeys = rowKeys(vm.Rows, vm.RowIDs)
+
+	// Build expansion state for rows with long text or JSON cells
+	vm.RowExpansions = buildRowExpansions(q, vm.Rows, vm.RowKeys, vm.Columns)
+
+	// Highlight rows where the compared columns differ
+	if len(q.DiffColumns) == 2 && slices.Contains(vm.Columns, q.DiffColumns[0]) && slices.Contains(vm.Columns, q.DiffColumns[1]) {
+		vm.DiffColumns = q.DiffColumns
+		vm.DiffRows = buildDiffRows(vm.Rows, q.DiffColumns[0], q.DiffColumns[1])
+		vm.ClearDiffURL = q.WithoutColumnDiff()
+	}
+
+	// Highlight the deep-linked cell if its row and column are displayed
+	if q.TargetColumn != "" && slices.Contains(vm.Columns, q.TargetColumn) && slices.Contains(vm.RowKeys, q.TargetRowKey) {
+		vm.TargetColumn = q.TargetColumn
+		vm.TargetRowKey = q.TargetRowKey
+	}
+
+	// Precompute URLs for all entity values in the flat rows, one batch per entity type
+	if urlCache != nil {
+		prefetchRowURLs(urlCache, vm.Rows, vm.ColumnEntityTypes)
+	}
+
 	// Build RowURLs for flat rows if URL resolver is provided
 	if urlResolver != nil && len(vm.ColumnEntityTypes) > 0 {
 		vm.RowURLs = make([]map[string]string, len(vm.Rows))
@@ -899,6 +1103,30 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 		}
 	}
 
+	// Build the entity breadcrumbs of the rows
+	if len(badgeColumns) > 0 {
+		if urlCache != nil {
+			badgeEntityTypes := make(map[string]string, len(badgeColumns))
+			for _, colName := range badgeColumns {
+				badgeEntityTypes[colName] = tableView.GetColumn(colName).ColumnDef().EntityType()
+			}
+			prefetchRowURLs(urlCache, vm.Rows, badgeEntityTypes)
+		}
+		vm.EntityBadges = buildEntityBadges(tableView, vm.Rows, badgeColumns, urlResolver)
+	}
+
+	// Build RowLinkURLs from the row link column's entity type
+	if urlResolver != nil && vm.RowLinkColumn != "" {
+		if entityType := tableView.GetColumn(vm.RowLinkColumn).ColumnDef().EntityType(); entityType != "" {
+			vm.RowLinkURLs = make([]string, len(vm.Rows))
+			for i, row := range vm.Rows {
+				if value := row[vm.RowLinkColumn]; value != "" {
+					vm.RowLinkURLs[i] = urlResolver(entityType, value)
+				}
+			}
+		}
+	}
+
 	// Build selected row data for detail panel (flat rows only)
 	// Find the row by matching the primary key ID
 	if q.SelectedRowID != "" && len(vm.RowIDs) > 0 {
@@ -914,6 +1142,7 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 		if selectedRow != nil {
 			vm.SelectedRowID = q.SelectedRowID
 			vm.SelectedRowData = make([]SelectedRowField, 0, len(view.Columns))
+			vm.SelectedRowDetailURL = q.DetailURL(q.SelectedRowID)
 
 			// Build column name to display name map
 			colDisplayNames := make(map[string]string)
@@ -924,35 +1153,12 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 			}
 
 			for _, colName := range view.Columns {
-				displayName := colDisplayNames[colName]
-				if displayName == "" {
-					displayName = colName
-				}
-				value := selectedRow[colName]
-				entityType := vm.ColumnEntityTypes[colName]
-				var valueURL string
-				var entityURLs []EntityURL
-				if entityType != "" && value != "" {
-					if urlResolver != nil {
-						valueURL = urlResolver(entityType, value)
-					}
-					if allURLsResolver != nil {
-						entityURLs = allURLsResolver(entityType, value)
-					}
-				}
-				vm.SelectedRowData = append(vm.SelectedRowData, SelectedRowField{
-					ColumnName:  colName,
-					DisplayName: displayName,
-					Value:       value,
-					ValueURL:    valueURL,
-					EntityType:  entityType,
-					EntityURLs:  entityURLs,
-				})
+				vm.SelectedRowData = append(vm.SelectedRowData, newSelectedRowField(colName, colDisplayNames[colName], selectedRow[colName], vm.ColumnEntityTypes[colName], urlResolver, opts.AllURLsResolver))
 			}
 
 			// Build hierarchy contexts for the selected item
 			// The primary key value is the SelectedRowID itself
-			if hierarchyContextBuilder != nil && primaryKeyEntityType != "" {
+			if opts.HierarchyContextBuilder != nil && opts.PrimaryKeyEntityType != "" {
 				// Build a complete row data map that includes ALL columns with entity types,
 				// not just visible columns. This is needed because hierarchy ancestors might
 				// be in columns that aren't currently displayed.
@@ -984,9 +1190,9 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 					}
 				}
 
-				vm.SelectedItemHierarchies = hierarchyContextBuilder(
+				vm.SelectedItemHierarchies = opts.HierarchyContextBuilder(
 					q,
-					primaryKeyEntityType,
+					opts.PrimaryKeyEntityType,
 					q.SelectedRowID,
 					fullRowData,
 					vm.ColumnEntityTypes,
@@ -994,11 +1200,11 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 			}
 
 			// Build related tables list
-			if relatedTablesResolver != nil && primaryKeyEntityType != "" {
-				vm.RelatedTables = relatedTablesResolver(
+			if opts.RelatedTablesResolver != nil && opts.PrimaryKeyEntityType != "" {
+				vm.RelatedTables = opts.RelatedTablesResolver(
 					q,
 					tableName,
-					primaryKeyEntityType,
+					opts.PrimaryKeyEntityType,
 					q.SelectedRowID,
 				)
 			}
@@ -1020,6 +1226,7 @@ func BuildViewModel(dataModel *models.DataModel, tableName string, tableView *ta
 		vm.TotalRows = groupResult.TotalRows
 		vm.DisplayedRows = groupResult.ShownRows
 		vm.HasMoreRows = groupResult.ShownRows < groupResult.TotalRows
+		vm.ExampleRowsOptions = buildExampleRowsOptions(q)
 	}
 
 	vm.ColumnStats = buildColumnStats(tableView)
@@ -1092,10 +1299,43 @@ func GetOrCreateTableView(tableName string, table *tables.DataTable, tableViewCa
 // 1. Joined columns (format: fromColumn.toTable.toColumn.selectedColumn) are properly added
 // 2. Joined columns no longer needed are removed
 // 3. Updates the provided TableView in place
-func ProcessJoinsAndUpdateColumns(tableView *tables.TableView, view *View, dataModel *models.DataModel) {
+// Returns the joined columns that could not be created, keyed by column name.
+func ProcessJoinsAndUpdateColumns(tableView *tables.TableView, view *View, resolver tables.JoinResolver) map[string]error {
 	// Update joined columns using the TableView's method
-	tableView.UpdateJoinedColumns(view.Columns, dataModel)
+	joinErrors := tableView.UpdateJoinedColumns(view.Columns, resolver)
 	tableView.VisibleColumns = view.Columns
+	return joinErrors
+}
+
+// joinSkewWarning returns a warning for a joined column if a single target row
+// attracts a suspicious share of the rows, or "" if the join looks balanced.
+func joinSkewWarning(tableView *tables.TableView, colName, targetTable string) string {
+	skew, ok := tableView.JoinSkew(colName)
+	if !ok || !skew.IsSkewed() {
+		return ""
+	}
+	value, err := tableView.GetColumn(colName).GetString(skew.TopRow)
+	if err != nil {
+		value = columns.ErrorLabel
+	}
+	return fmt.Sprintf("%.0f%% of matched rows (%d of %d) join to the same %s row, shown as %q. Aggregates over this join may be skewed.",
+		skew.Share()*100, skew.TopCount, skew.Matched, targetTable, value)
+}
+
+// prefetchRowURLs resolves the URLs of all entity-typed values in rows into the cache,
+// grouping values by entity type so a batch resolver is called once per entity type.
+func prefetchRowURLs(urlCache *URLCache, rows []map[string]string, columnEntityTypes map[string]string) {
+	valuesByEntityType := make(map[string][]string)
+	for colName, entityType := range columnEntityTypes {
+		for _, row := range rows {
+			if value, ok := row[colName]; ok && value != "" {
+				valuesByEntityType[entityType] = append(valuesByEntityType[entityType], value)
+			}
+		}
+	}
+	for entityType, values := range valuesByEntityType {
+		urlCache.Prefetch(entityType, values)
+	}
 }
 
 // BuildAddColumnURL creates a URL that toggles a column
@@ -1357,8 +1597,20 @@ func walkGroupHierarchy(tableView *tables.TableView, block *grouping.Block, rows
 		if limit > 0 && *rowCount >= limit {
 			return true
 		}
-		// Get the raw value for filtering
+		// Get the raw value for filtering, and its label for display: the group label
+		// expression of the column if it has one, otherwise the value label
 		rawValue := group.GetValue()
+		displayValue := rawValue
+		cohortValues, isCohort := q.CohortValues(colName, rawValue)
+		var labelErr error
+		if label, ok, err := tableView.GroupLabel(colName, group); ok && !isCohort {
+			displayValue, labelErr = label, err
+			if err != nil {
+				displayValue = columns.ErrorLabel
+			}
+		} else if col := tableView.GetColumn(colName); col != nil && !isCohort {
+			displayValue = col.ColumnDef().Label(rawValue)
+		}
 		numRows := len(group.Indices)
 		numSubgroups := group.NumSubgroups()
 
@@ -1369,6 +1621,15 @@ func walkGroupHierarchy(tableView *tables.TableView, block *grouping.Block, rows
 		} else {
 			tooltip = "[rows]"
 		}
+		if labelErr != nil {
+			tooltip = "Label error: " + labelErr.Error() + " " + tooltip
+		}
+		explainURL := groupRowsURL(q, tableView, group)
+		var splitCohortURL safehtml.URL
+		if isCohort {
+			tooltip = "Cohort of " + strings.Join(cohortValues, ", ") + " " + tooltip
+			splitCohortURL = q.WithoutCohort(colName, rawValue)
+		}
 
 		// Build column aggregates for this group
 		// Only show aggregates in grouped column cells that are NOT the last grouped column,
@@ -1376,26 +1637,11 @@ func walkGroupHierarchy(tableView *tables.TableView, block *grouping.Block, rows
 		// Also check if this grouped column has an aggregate sort to mark the sorted aggregate.
 		var columnAggs []aggregates.ColumnAggregateDisplay
 		aggSort := q.GetGroupAggSort(colName)
-		if group.Aggregates != nil && group.ChildBlock != nil {
-			for _, leafColName := range tableView.GetLeafColumns() {
-				state := group.Aggregates[leafColName]
-				colType := tableView.GetColumnType(leafColName)
-				enabledAggs := q.GetEnabledAggregates(leafColName, colType)
-				if len(enabledAggs) > 0 && state != nil {
-					// Mark the sorted aggregate if this is the sorted leaf column
-					var sortedCol string
-					var sortedAgg query.AggregateType
-					if aggSort != nil && leafColName == aggSort.LeafColumn {
-						sortedCol = leafColName
-						sortedAgg = aggSort.AggType
-					}
-					columnAggs = append(columnAggs, aggregates.ColumnAggregateDisplay{
-						ColumnName: leafColName,
-						Aggregates: aggregates.FormatAggregatesWithSort(state, enabledAggs, sortedCol, sortedAgg),
-					})
-				}
-			}
+		if group.ChildBlock != nil {
+			columnAggs = groupAggregates(tableView, group, q, tableView.GetLeafColumns(), aggSort)
 		}
+		// Aggregates still being computed are filled in after the page is sent
+		aggregatesPending := group.ChildBlock != nil && aggregatesDeferred(tableView, group, q, tableView.GetLeafColumns())
 
 		// Add the grouped column cell for this group
 		// IsValueSorted is true if column is sorted and not using aggregate sort
@@ -1406,14 +1652,14 @@ func walkGroupHierarchy(tableView *tables.TableView, block *grouping.Block, rows
 
 		// Resolve URL for the cell value if entity type is defined
 		var valueURL string
-		if urlResolver != nil && rawValue != "" {
+		if urlResolver != nil && rawValue != "" && !isCohort {
 			if entityType, ok := columnEntityTypes[colName]; ok {
 				valueURL = urlResolver(entityType, rawValue)
 			}
 		}
 
 		groupedCell := GroupedCell{
-			Value:                 rawValue,
+			Value:                 displayValue,
 			ValueURL:              valueURL,
 			NumRows:               numRows,
 			NumSubgroups:          numSubgroups,
@@ -1427,40 +1673,42 @@ func walkGroupHierarchy(tableView *tables.TableView, block *grouping.Block, rows
 			IsRowCountSorted:      isRowCountSorted,
 			IsSubgroupCountSorted: isSubgroupCountSorted,
 			ColumnAggregates:      columnAggs,
+			ExplainURL:            explainURL,
+			IsCohort:              isCohort,
+			SplitCohortURL:        splitCohortURL,
+			AggregatesPending:     aggregatesPending,
+			AggregatesID:          aggregatesID(group, ""),
 			IsIncomplete:          false, // Set by fixRowspans based on rowspan reduction
 		}
 		(*rows)[len(*rows)-1].Cells = append((*rows)[len(*rows)-1].Cells, groupedCell)
 
 		if group.ChildBlock == nil {
+			// Example rows of the leaf group, each counted against the row limit, so that
+			// groups get fewer examples when the limit is close
+			var examples []uint32
+			if len(tableView.GetOtherLeafColumns())+len(tableView.GetFilteredLeafColumns()) > 0 {
+				count := q.ExampleRows
+				if limit > 0 {
+					count = min(count, limit-*rowCount-1)
+				}
+				examples = exampleIndices(group.Indices, count, q.RandomExampleRows)
+			}
+
 			// Leaf group - add cells for "other" (non-filtered) leaf columns with their aggregates
 			for _, leafColName := range tableView.GetOtherLeafColumns() {
 				// Build aggregates for this specific leaf column
-				var leafAggs []aggregates.ColumnAggregateDisplay
-				if group.Aggregates != nil {
-					state := group.Aggregates[leafColName]
-					colType := tableView.GetColumnType(leafColName)
-					enabledAggs := q.GetEnabledAggregates(leafColName, colType)
-					if len(enabledAggs) > 0 && state != nil {
-						// Mark the sorted aggregate if this is the sorted leaf column
-						var sortedCol string
-						var sortedAgg query.AggregateType
-						if aggSort != nil && leafColName == aggSort.LeafColumn {
-							sortedCol = leafColName
-							sortedAgg = aggSort.AggType
-						}
-						leafAggs = append(leafAggs, aggregates.ColumnAggregateDisplay{
-							ColumnName: leafColName,
-							Aggregates: aggregates.FormatAggregatesWithSort(state, enabledAggs, sortedCol, sortedAgg),
-						})
-					}
-				}
+				leafAggs := groupAggregates(tableView, group, q, []string{leafColName}, aggSort)
 				(*rows)[len(*rows)-1].Cells = append((*rows)[len(*rows)-1].Cells, GroupedCell{
-					Value:            "",
-					Rowspan:          group.Height(),
-					Title:            "",
-					ColumnName:       leafColName,
-					ColumnAggregates: leafAggs,
-					IsIncomplete:     false, // Set by fixRowspans based on rowspan reduction
+					Value:             "",
+					Rowspan:           group.Height(),
+					Title:             "",
+					ColumnName:        leafColName,
+					ColumnAggregates:  leafAggs,
+					ExplainURL:        explainURL,
+					AggregatesPending: aggregatesDeferred(tableView, group, q, []string{leafColName}),
+					AggregatesID:      aggregatesID(group, leafColName),
+					IsIncomplete:      false, // Set by fixRowspans based on rowspan reduction
+					Examples:          exampleValues(tableView, leafColName, examples),
 				})
 			}
 
@@ -1469,38 +1717,24 @@ func walkGroupHierarchy(tableView *tables.TableView, block *grouping.Block, rows
 			cells := make([]GroupedCell, len(filteredLeafCols))
 			for i, leafColName := range filteredLeafCols {
 				// Build aggregates for this specific leaf column
-				var leafAggs []aggregates.ColumnAggregateDisplay
-				if group.Aggregates != nil {
-					state := group.Aggregates[leafColName]
-					colType := tableView.GetColumnType(leafColName)
-					enabledAggs := q.GetEnabledAggregates(leafColName, colType)
-					if len(enabledAggs) > 0 && state != nil {
-						// Mark the sorted aggregate if this is the sorted leaf column
-						var sortedCol string
-						var sortedAgg query.AggregateType
-						if aggSort != nil && leafColName == aggSort.LeafColumn {
-							sortedCol = leafColName
-							sortedAgg = aggSort.AggType
-						}
-						leafAggs = append(leafAggs, aggregates.ColumnAggregateDisplay{
-							ColumnName: leafColName,
-							Aggregates: aggregates.FormatAggregatesWithSort(state, enabledAggs, sortedCol, sortedAgg),
-						})
-					}
-				}
+				leafAggs := groupAggregates(tableView, group, q, []string{leafColName}, aggSort)
 				cells[i] = GroupedCell{
-					Value:            "",
-					Rowspan:          group.Height(),
-					Title:            "",
-					ColumnName:       leafColName,
-					ColumnAggregates: leafAggs,
-					IsIncomplete:     false, // Set by fixRowspans based on rowspan reduction
+					Value:             "",
+					Rowspan:           group.Height(),
+					Title:             "",
+					ColumnName:        leafColName,
+					ColumnAggregates:  leafAggs,
+					ExplainURL:        explainURL,
+					AggregatesPending: aggregatesDeferred(tableView, group, q, []string{leafColName}),
+					AggregatesID:      aggregatesID(group, leafColName),
+					IsIncomplete:      false, // Set by fixRowspans based on rowspan reduction
+					Examples:          exampleValues(tableView, leafColName, examples),
 				}
 			}
 			(*rows)[len(*rows)-1].Cells = append(cells, (*rows)[len(*rows)-1].Cells...)
 
-			// Increment row count for this leaf group
-			*rowCount++
+			// Increment row count for this leaf group and its example rows
+			*rowCount += 1 + len(examples)
 
 			// Start a new row for the next group
 			*rows = append(*rows, GroupedRow{Cells: []GroupedCell{}})
@@ -1515,6 +1749,60 @@ func walkGroupHierarchy(tableView *tables.TableView, block *grouping.Block, rows
 	return false
 }
 
+// groupAggregates formats the enabled aggregates of the given leaf columns for a group.
+// The aggregate of aggSort's leaf column is marked as sorted.
+func groupAggregates(tableView *tables.TableView, group *grouping.Group, q *query.Query, leafColumns []string, aggSort *query.GroupAggSort) []aggregates.ColumnAggregateDisplay {
+	if group.Aggregates == nil {
+		return nil
+	}
+	var columnAggs []aggregates.ColumnAggregateDisplay
+	for _, leafColName := range leafColumns {
+		state := group.Aggregates[leafColName]
+		colType := tableView.GetColumnType(leafColName)
+		enabledAggs := q.GetEnabledAggregates(leafColName, colType)
+		if len(enabledAggs) > 0 && state != nil {
+			// Mark the sorted aggregate if this is the sorted leaf column
+			var sortedCol string
+			var sortedAgg query.AggregateType
+			if aggSort != nil && leafColName == aggSort.LeafColumn {
+				sortedCol = leafColName
+				sortedAgg = aggSort.AggType
+			}
+			columnAggs = append(columnAggs, aggregates.ColumnAggregateDisplay{
+				ColumnName: leafColName,
+				Aggregates: aggregates.FormatAggregatesWithRules(state, enabledAggs, sortedCol, sortedAgg, aggregateFormats(tableView, leafColName)),
+			})
+		}
+	}
+	return columnAggs
+}
+
+// aggregateFormats returns the format rules of a column's aggregates. Data sources reject
+// invalid rules when loading; any other invalid rules fall back to the default formatting.
+func aggregateFormats(tableView *tables.TableView, colName string) aggregates.AggregateFormats {
+	col := tableView.GetColumn(colName)
+	if col == nil {
+		return nil
+	}
+	formats, _ := aggregates.ParseAggregateFormats(col.ColumnDef().AggregateFormats())
+	return formats
+}
+
+// groupRowsURL builds the URL of the view listing the rows of a group: the current view
+// ungrouped and filtered on the values of the group and all its ancestor groups.
+// Joined and computed columns stay visible, so rows that contributed nothing to an
+// aggregate (e.g., without a join match) show up with an empty cell.
+func groupRowsURL(q *query.Query, tableView *tables.TableView, group *grouping.Group) safehtml.URL {
+	groupingOrder := tableView.GetGroupingOrder()
+	groupValues := make(map[string]string)
+	for g := group; g != nil; g = g.ParentGroup {
+		if level := g.Block.GroupedColumn.Level; level < len(groupingOrder) {
+			groupValues[groupingOrder[level]] = g.GetValue()
+		}
+	}
+	return q.WithGroupRows(groupValues)
+}
+
 func buildColumnStats(tableView *tables.TableView) []string {
 	stats := make([]string, len(tableView.VisibleColumns))
 	filteredRows := tableView.GetFilteredRowCount()
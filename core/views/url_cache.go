@@ -0,0 +1,98 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+// BatchURLResolver is a function that resolves URLs for many values of one entity type at once.
+// Returns a map from value to URL; values without a URL may be omitted.
+type BatchURLResolver func(entityType string, values []string) map[string]string
+
+// urlKey identifies a resolved URL in a URLCache
+type urlKey struct {
+	entityType string
+	value      string
+}
+
+// URLCache memoizes entity URL resolution per (entityType, value).
+// A cache is meant to live for a single request: large views resolve the same
+// handful of values thousands of times, but resolvers may change between requests.
+type URLCache struct {
+	resolver      URLResolver
+	batchResolver BatchURLResolver
+	urls          map[urlKey]string
+}
+
+// NewURLCache creates a URLCache over the given resolvers. Either can be nil;
+// the batch resolver is used for Prefetch and the single-value resolver for
+// misses, falling back to the other when one is not set.
+func NewURLCache(resolver URLResolver, batchResolver BatchURLResolver) *URLCache {
+	return &URLCache{
+		resolver:      resolver,
+		batchResolver: batchResolver,
+		urls:          make(map[urlKey]string),
+	}
+}
+
+// Resolve returns the URL for an entity value, resolving and caching it on first use.
+// Returns an empty string if no URL is available. Resolve has the URLResolver signature.
+func (c *URLCache) Resolve(entityType, value string) string {
+	key := urlKey{entityType, value}
+	if url, ok := c.urls[key]; ok {
+		return url
+	}
+
+	var url string
+	if c.resolver != nil {
+		url = c.resolver(entityType, value)
+	} else if c.batchResolver != nil {
+		url = c.batchResolver(entityType, []string{value})[value]
+	}
+	c.urls[key] = url
+	return url
+}
+
+// Prefetch resolves all values of an entity type that are not cached yet,
+// using a single batch call when a batch resolver is available.
+func (c *URLCache) Prefetch(entityType string, values []string) {
+	var missing []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		if _, ok := c.urls[urlKey{entityType, value}]; !ok {
+			missing = append(missing, value)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	if c.batchResolver == nil {
+		for _, value := range missing {
+			c.Resolve(entityType, value)
+		}
+		return
+	}
+
+	resolved := c.batchResolver(entityType, missing)
+	for _, value := range missing {
+		c.urls[urlKey{entityType, value}] = resolved[value]
+	}
+}
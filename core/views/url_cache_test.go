@@ -0,0 +1,79 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package views
+
+import "testing"
+
+func TestURLCacheMemoizesResolve(t *testing.T) {
+	calls := 0
+	cache := NewURLCache(func(entityType, value string) string {
+		calls++
+		return "/" + entityType + "/" + value
+	}, nil)
+
+	for i := 0; i < 3; i++ {
+		if got := cache.Resolve("region", "north"); got != "/region/north" {
+			t.Fatalf("Resolve = %q, want /region/north", got)
+		}
+	}
+	cache.Resolve("zone", "north")
+	if calls != 2 {
+		t.Errorf("expected 2 resolver calls, got %d", calls)
+	}
+}
+
+func TestURLCachePrefetchUsesBatchResolver(t *testing.T) {
+	singleCalls, batchCalls := 0, 0
+	cache := NewURLCache(
+		func(entityType, value string) string {
+			singleCalls++
+			return "/single/" + value
+		},
+		func(entityType string, values []string) map[string]string {
+			batchCalls++
+			urls := make(map[string]string)
+			for _, v := range values {
+				if v != "none" {
+					urls[v] = "/batch/" + v
+				}
+			}
+			return urls
+		},
+	)
+
+	cache.Prefetch("region", []string{"north", "south", "north", "", "none"})
+	if batchCalls != 1 {
+		t.Fatalf("expected 1 batch call, got %d", batchCalls)
+	}
+	if got := cache.Resolve("region", "south"); got != "/batch/south" {
+		t.Errorf("Resolve(south) = %q, want /batch/south", got)
+	}
+	if got := cache.Resolve("region", "none"); got != "" {
+		t.Errorf("Resolve(none) = %q, want empty", got)
+	}
+	if singleCalls != 0 {
+		t.Errorf("expected prefetched values not to call the single resolver, got %d calls", singleCalls)
+	}
+
+	// Already cached values are not fetched again
+	cache.Prefetch("region", []string{"north", "south"})
+	if batchCalls != 1 {
+		t.Errorf("expected no additional batch call, got %d", batchCalls)
+	}
+}
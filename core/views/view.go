@@ -27,5 +27,6 @@ type View struct {
 	Columns        []string                    // Column names in display order (including joined columns like "fromColumn.toTable.toColumn.selectedColumn")
 	Expanded       map[string]bool             // Set of expanded paths (e.g., "column1", "column1/table2.column2")
 	GroupedColumns []string                    // Column names to group by, in grouping order
+	BadgeColumns   []string                    // Entity columns shown as a breadcrumb of badges in flat rows, root first (nil = none)
 	columnViews    map[string]*columns.ColumnView
 }
@@ -0,0 +1,160 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasources
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// CoercionPolicyKey is the source config key selecting what a loader does with values
+// that cannot be converted to the type of their column.
+const CoercionPolicyKey = "coercion_policy"
+
+// CoercionPolicy is what a loader does with values it cannot convert to the type of
+// their column.
+type CoercionPolicy string
+
+const (
+	// CoercionZero loads such values as the zero value of their column, like empty
+	// values (the default). Columns have no null value, so their rows are marked
+	// invalid in the column definition (see columns.ColumnDef.SetInvalidRows):
+	// aggregates leave them out and SQL statements return them as NULL.
+	CoercionZero CoercionPolicy = "zero"
+	// CoercionShadow loads them like CoercionZero and keeps the raw strings in a shadow
+	// string column named after the column with ShadowColumnSuffix. Only columns with
+	// values that could not be converted get a shadow column.
+	CoercionShadow CoercionPolicy = "shadow"
+	// CoercionFail fails the load.
+	CoercionFail CoercionPolicy = "fail"
+)
+
+// ShadowColumnSuffix is appended to the name of a column to name its shadow column.
+const ShadowColumnSuffix = "_raw"
+
+// maxCoercionExamples is the number of distinct failed values kept per column.
+const maxCoercionExamples = 5
+
+// ParseCoercionPolicy parses the coercion_policy config value. An empty value is
+// CoercionZero.
+func ParseCoercionPolicy(s string) (CoercionPolicy, error) {
+	switch policy := CoercionPolicy(s); policy {
+	case "":
+		return CoercionZero, nil
+	case CoercionZero, CoercionShadow, CoercionFail:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown %s %q (expected zero, shadow or fail)", CoercionPolicyKey, s)
+	}
+}
+
+// CoercionFailure counts the values of a column that could not be converted to its type.
+type CoercionFailure struct {
+	Column   string
+	Type     ColumnType
+	Rows     int      // Rows loaded
+	Count    int      // Values that could not be converted
+	Examples []string // Distinct values that could not be converted, in load order
+}
+
+// CoercionReporter is implemented by loaders that convert source values to typed
+// columns. LoadWithCoercionReport loads like Load and also reports, per column, the
+// values that could not be converted; the Manager exposes them with
+// GetCoercionFailures.
+type CoercionReporter interface {
+	LoadWithCoercionReport(config map[string]string, columns []*EnrichedColumn, readFile FileReader) (*tables.DataTable, []CoercionFailure, error)
+}
+
+// columnCoercion collects the values of one column that could not be converted.
+type columnCoercion struct {
+	policy  CoercionPolicy
+	column  *EnrichedColumn
+	failure CoercionFailure
+	rows    []uint32 // Failed rows
+	raw     []string // Raw values of the failed rows, kept under CoercionShadow once a value fails
+}
+
+func newColumnCoercion(policy CoercionPolicy, column *EnrichedColumn, rows int) *columnCoercion {
+	return &columnCoercion{
+		policy:  policy,
+		column:  column,
+		failure: CoercionFailure{Column: column.Name, Type: column.Type, Rows: rows},
+	}
+}
+
+// reject records that the value of a row could not be converted.
+func (c *columnCoercion) reject(row int, value string) {
+	c.failure.Count++
+	c.rows = append(c.rows, uint32(row))
+	if len(c.failure.Examples) < maxCoercionExamples && !slices.Contains(c.failure.Examples, value) {
+		c.failure.Examples = append(c.failure.Examples, value)
+	}
+	if c.policy == CoercionShadow {
+		if c.raw == nil {
+			c.raw = make([]string, c.failure.Rows)
+		}
+		c.raw[row] = value
+	}
+}
+
+// finish marks the failed rows of the column in the table as invalid, so that aggregates
+// leave out their zero values, adds the shadow column under CoercionShadow if a value
+// could not be converted, and returns the failure to report, if any. Under CoercionFail
+// it returns an error if a value could not be converted.
+func (c *columnCoercion) finish(table *tables.DataTable) (*CoercionFailure, error) {
+	if c.policy == CoercionFail && c.failure.Count > 0 {
+		return nil, errs.New(errs.ErrTypeMismatch, "column %q has %d values that are not %s, e.g. %s",
+			c.column.Name, c.failure.Count, c.column.Type, quoteExamples(c.failure.Examples))
+	}
+	if len(c.rows) > 0 {
+		table.GetColumn(c.column.Name).ColumnDef().SetInvalidRows(c.rows)
+	}
+	if c.raw != nil {
+		name := c.column.Name + ShadowColumnSuffix
+		col := columns.NewStringColumn(columns.NewColumnDef(name, c.column.DisplayName+" (raw)", ""))
+		for _, value := range c.raw {
+			col.Append(value)
+		}
+		col.FinalizeColumn()
+		table.AddColumn(col)
+	}
+	if c.failure.Count == 0 {
+		return nil, nil
+	}
+	return &c.failure, nil
+}
+
+// quoteExamples formats failed values for messages and reports.
+func quoteExamples(examples []string) string {
+	quoted := make([]string, len(examples))
+	for i, example := range examples {
+		quoted[i] = fmt.Sprintf("%q", example)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// QuotedExamples returns the failed values quoted and separated by commas.
+func (f CoercionFailure) QuotedExamples() string {
+	return quoteExamples(f.Examples)
+}
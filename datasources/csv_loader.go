@@ -22,9 +22,11 @@ import (
 	"bytes"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"strconv"
 
 	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/errs"
 	"github.com/google/taxinomia/core/tables"
 )
 
@@ -70,7 +72,7 @@ func (l *CsvLoader) DiscoverSchema(config map[string]string, readFile FileReader
 	// Read file
 	data, err := readFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV file: %w", err)
+		return nil, errs.Wrap(errs.ErrSourceUnavailable, err, "failed to read CSV file")
 	}
 
 	// Create CSV reader
@@ -128,7 +130,7 @@ func (l *CsvLoader) Load(config map[string]string, enrichedColumns []*EnrichedCo
 	// Read file
 	data, err := readFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV file: %w", err)
+		return nil, errs.Wrap(errs.ErrSourceUnavailable, err, "failed to read CSV file")
 	}
 
 	// Create CSV reader
@@ -172,8 +174,9 @@ func (l *CsvLoader) Load(config map[string]string, enrichedColumns []*EnrichedCo
 		}
 	}
 
-	// Add columns to table
+	// Add columns to table, detecting key columns
 	for _, col := range stringCols {
+		col.FinalizeColumn()
 		table.AddColumn(col)
 	}
 
@@ -189,6 +192,8 @@ func (l *CsvLoader) Load(config map[string]string, enrichedColumns []*EnrichedCo
 // Optional config keys:
 //   - has_header: "true" or "false" (default: "true")
 //   - delimiter: Field delimiter (default: ",")
+//   - coercion_policy: What to do with values that do not parse as the type of
+//     their column: "zero", "shadow" or "fail" (default: "zero")
 type CsvLoaderTyped struct{}
 
 // NewCsvLoaderTyped creates a new typed CSV loader.
@@ -209,6 +214,17 @@ func (l *CsvLoaderTyped) DiscoverSchema(config map[string]string, readFile FileR
 		return nil, fmt.Errorf("file_path is required")
 	}
 
+	// Read file
+	data, err := readFile(filePath)
+	if err != nil {
+		return nil, errs.Wrap(errs.ErrSourceUnavailable, err, "failed to read CSV file")
+	}
+	return l.discoverSchema(config, bytes.NewReader(data), -1)
+}
+
+// discoverSchema discovers the table schema of CSV data, inferring the column types
+// from its first maxRows data rows, or from all rows if maxRows is negative.
+func (l *CsvLoaderTyped) discoverSchema(config map[string]string, data io.Reader, maxRows int) (*TableSchema, error) {
 	hasHeader := true
 	if h := config["has_header"]; h == "false" {
 		hasHeader = false
@@ -219,20 +235,25 @@ func (l *CsvLoaderTyped) DiscoverSchema(config map[string]string, readFile FileR
 		delimiter = rune(d[0])
 	}
 
-	// Read file
-	data, err := readFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV file: %w", err)
-	}
-
 	// Create CSV reader
-	reader := csv.NewReader(bytes.NewReader(data))
+	reader := csv.NewReader(data)
 	reader.Comma = delimiter
 
-	// Read all records
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	// Read the header and up to maxRows records
+	var records [][]string
+	dataStart := 0
+	if hasHeader {
+		dataStart = 1
+	}
+	for maxRows < 0 || len(records) < dataStart+maxRows {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		records = append(records, record)
 	}
 
 	if len(records) == 0 {
@@ -241,11 +262,9 @@ func (l *CsvLoaderTyped) DiscoverSchema(config map[string]string, readFile FileR
 
 	// Determine column names
 	var columnNames []string
-	dataStart := 0
 
 	if hasHeader {
 		columnNames = records[0]
-		dataStart = 1
 	} else {
 		for i := range records[0] {
 			columnNames = append(columnNames, fmt.Sprintf("col_%d", i))
@@ -268,7 +287,7 @@ func (l *CsvLoaderTyped) DiscoverSchema(config map[string]string, readFile FileR
 	}
 
 	// Infer column types by sampling data
-	colTypes := l.inferColumnTypes(columnNames, dataRecords)
+	colTypes, ambiguous := l.inferColumnTypes(columnNames, dataRecords)
 
 	// Build schema
 	schema := &TableSchema{
@@ -276,8 +295,9 @@ func (l *CsvLoaderTyped) DiscoverSchema(config map[string]string, readFile FileR
 	}
 	for i, name := range columnNames {
 		schema.Columns[i] = &ColumnSchema{
-			Name: name,
-			Type: colTypes[i],
+			Name:      name,
+			Type:      colTypes[i],
+			Ambiguous: ambiguous[i],
 		}
 	}
 
@@ -286,9 +306,16 @@ func (l *CsvLoaderTyped) DiscoverSchema(config map[string]string, readFile FileR
 
 // Load loads a CSV file with typed columns.
 func (l *CsvLoaderTyped) Load(config map[string]string, enrichedColumns []*EnrichedColumn, readFile FileReader) (*tables.DataTable, error) {
+	table, _, err := l.LoadWithCoercionReport(config, enrichedColumns, readFile)
+	return table, err
+}
+
+// LoadWithCoercionReport loads a CSV file with typed columns and reports the values
+// that could not be converted to the type of their column.
+func (l *CsvLoaderTyped) LoadWithCoercionReport(config map[string]string, enrichedColumns []*EnrichedColumn, readFile FileReader) (*tables.DataTable, []CoercionFailure, error) {
 	filePath := config["file_path"]
 	if filePath == "" {
-		return nil, fmt.Errorf("file_path is required")
+		return nil, nil, fmt.Errorf("file_path is required")
 	}
 
 	hasHeader := true
@@ -301,10 +328,15 @@ func (l *CsvLoaderTyped) Load(config map[string]string, enrichedColumns []*Enric
 		delimiter = rune(d[0])
 	}
 
+	policy, err := ParseCoercionPolicy(config[CoercionPolicyKey])
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Read file
 	data, err := readFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV file: %w", err)
+		return nil, nil, errs.Wrap(errs.ErrSourceUnavailable, err, "failed to read CSV file")
 	}
 
 	// Create CSV reader
@@ -314,11 +346,11 @@ func (l *CsvLoaderTyped) Load(config map[string]string, enrichedColumns []*Enric
 	// Read all records
 	records, err := reader.ReadAll()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV: %w", err)
+		return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
 	}
 
 	if len(records) == 0 {
-		return nil, fmt.Errorf("CSV file is empty")
+		return nil, nil, fmt.Errorf("CSV file is empty")
 	}
 
 	// Determine data start
@@ -329,57 +361,72 @@ func (l *CsvLoaderTyped) Load(config map[string]string, enrichedColumns []*Enric
 
 	dataRecords := records[dataStart:]
 	if len(dataRecords) == 0 {
-		return nil, fmt.Errorf("CSV file has no data rows")
+		return nil, nil, fmt.Errorf("CSV file has no data rows")
 	}
 
-	// Create data table
+	return typedCsvTable(dataRecords, enrichedColumns, policy)
+}
+
+// typedCsvTable converts the data records of a CSV file to a table with a column of the
+// enriched type per field, and reports the values that could not be converted.
+func typedCsvTable(dataRecords [][]string, enrichedColumns []*EnrichedColumn, policy CoercionPolicy) (*tables.DataTable, []CoercionFailure, error) {
 	table := tables.NewDataTable()
+	var failures []CoercionFailure
 
-	// Create and populate columns based on enriched types
+	// Create and populate columns based on enriched types. Empty and missing
+	// values are loaded as zero values; other values that do not parse are
+	// handled according to the coercion policy.
 	for i, enriched := range enrichedColumns {
 		colDef := columns.NewColumnDef(enriched.Name, enriched.DisplayName, enriched.EntityType)
+		coercion := newColumnCoercion(policy, enriched, len(dataRecords))
 
 		switch enriched.Type {
 		case TypeInt64:
 			col := columns.NewInt64Column(colDef)
-			for _, record := range dataRecords {
-				if i < len(record) {
-					if v, err := strconv.ParseInt(record[i], 10, 64); err == nil {
-						col.Append(v)
+			for row, record := range dataRecords {
+				var v int64
+				if i < len(record) && record[i] != "" {
+					if n, err := strconv.ParseInt(record[i], 10, 64); err == nil {
+						v = n
 					} else {
-						col.Append(0)
+						coercion.reject(row, record[i])
 					}
-				} else {
-					col.Append(0)
 				}
+				col.Append(v)
 			}
+			col.FinalizeColumn()
 			table.AddColumn(col)
 
 		case TypeFloat64:
 			col := columns.NewFloat64Column(colDef)
-			for _, record := range dataRecords {
-				if i < len(record) {
-					if v, err := strconv.ParseFloat(record[i], 64); err == nil {
-						col.Append(v)
+			for row, record := range dataRecords {
+				var v float64
+				if i < len(record) && record[i] != "" {
+					if f, err := strconv.ParseFloat(record[i], 64); err == nil {
+						v = f
 					} else {
-						col.Append(0.0)
+						coercion.reject(row, record[i])
 					}
-				} else {
-					col.Append(0.0)
 				}
+				col.Append(v)
 			}
+			col.FinalizeColumn()
 			table.AddColumn(col)
 
 		case TypeBool:
 			col := columns.NewBoolColumn(colDef)
-			for _, record := range dataRecords {
-				if i < len(record) {
-					v := record[i] == "true" || record[i] == "1" || record[i] == "yes"
-					col.Append(v)
-				} else {
-					col.Append(false)
+			for row, record := range dataRecords {
+				var v bool
+				if i < len(record) && record[i] != "" {
+					if b, ok := parseCsvBool(record[i]); ok {
+						v = b
+					} else {
+						coercion.reject(row, record[i])
+					}
 				}
+				col.Append(v)
 			}
+			col.FinalizeColumn()
 			table.AddColumn(col)
 
 		default: // TypeString
@@ -391,25 +438,51 @@ func (l *CsvLoaderTyped) Load(config map[string]string, enrichedColumns []*Enric
 					col.Append("")
 				}
 			}
+			col.FinalizeColumn()
 			table.AddColumn(col)
+			continue
+		}
+
+		failure, err := coercion.finish(table)
+		if err != nil {
+			return nil, nil, err
+		}
+		if failure != nil {
+			failures = append(failures, *failure)
 		}
 	}
 
-	return table, nil
+	return table, failures, nil
 }
 
-// inferColumnTypes samples data to determine column types.
-func (l *CsvLoaderTyped) inferColumnTypes(columnNames []string, records [][]string) []ColumnType {
+// parseCsvBool parses the boolean values type inference accepts.
+func parseCsvBool(s string) (bool, bool) {
+	switch s {
+	case "true", "1", "yes":
+		return true, true
+	case "false", "0", "no":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// inferColumnTypes samples data to determine column types, and whether each
+// inferred type is ambiguous.
+func (l *CsvLoaderTyped) inferColumnTypes(columnNames []string, records [][]string) ([]ColumnType, []bool) {
 	types := make([]ColumnType, len(columnNames))
+	ambiguous := make([]bool, len(columnNames))
 
 	for i := range columnNames {
-		types[i] = l.inferColumnType(i, records)
+		types[i], ambiguous[i] = l.inferColumnType(i, records)
 	}
 
-	return types
+	return types, ambiguous
 }
 
-func (l *CsvLoaderTyped) inferColumnType(colIdx int, records [][]string) ColumnType {
+// inferColumnType returns the type of a column and whether it is ambiguous:
+// the sample has no values at all, or only some of its values are numeric.
+func (l *CsvLoaderTyped) inferColumnType(colIdx int, records [][]string) (ColumnType, bool) {
 	// Sample up to 100 rows
 	sampleSize := len(records)
 	if sampleSize > 100 {
@@ -419,6 +492,8 @@ func (l *CsvLoaderTyped) inferColumnType(colIdx int, records [][]string) ColumnT
 	isInt := true
 	isFloat := true
 	isBool := true
+	values := 0
+	numeric := 0
 
 	for i := 0; i < sampleSize; i++ {
 		if colIdx >= len(records[i]) {
@@ -428,6 +503,10 @@ func (l *CsvLoaderTyped) inferColumnType(colIdx int, records [][]string) ColumnT
 		if val == "" {
 			continue // Skip empty values
 		}
+		values++
+		if _, err := strconv.ParseFloat(val, 64); err == nil {
+			numeric++
+		}
 
 		// Check int
 		if isInt {
@@ -451,14 +530,17 @@ func (l *CsvLoaderTyped) inferColumnType(colIdx int, records [][]string) ColumnT
 		}
 	}
 
+	if values == 0 {
+		return TypeString, true
+	}
 	if isInt {
-		return TypeInt64
+		return TypeInt64, false
 	}
 	if isFloat {
-		return TypeFloat64
+		return TypeFloat64, false
 	}
 	if isBool {
-		return TypeBool
+		return TypeBool, false
 	}
-	return TypeString
+	return TypeString, numeric > 0
 }
@@ -0,0 +1,432 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasources
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/sqlquery"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// csvChunkRows is the number of rows of a CSV file converted to a table at a time when
+// statements are run on the file without loading it
+var csvChunkRows = 65536
+
+// LoadAggregates runs a grouped or aggregated statement on a CSV file without loading it:
+// the rows are converted to tables csvChunkRows at a time, the statement is run on each
+// chunk, and the aggregates of the chunks are merged.
+func (l *CsvLoaderTyped) LoadAggregates(config map[string]string, stmt *sqlquery.Statement, enrichedColumns []*EnrichedColumn, openFile FileOpener) ([][]any, error) {
+	agg := newChunkAggregation(stmt)
+	var read []*EnrichedColumn
+	for _, col := range enrichedColumns {
+		if slices.Contains(agg.columns(), col.Name) {
+			read = append(read, col)
+		}
+	}
+	err := scanCsvChunks(config, read, openFile, func(chunk *tables.DataTable, _ [][]string) error {
+		return agg.add(chunk)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return agg.rows(), nil
+}
+
+// SampleSchema discovers the schema of a CSV file like DiscoverSchema, inferring the
+// column types from the first csvChunkRows rows only.
+func (l *CsvLoaderTyped) SampleSchema(config map[string]string, openFile FileOpener) (*TableSchema, error) {
+	filePath := config["file_path"]
+	if filePath == "" {
+		return nil, fmt.Errorf("file_path is required")
+	}
+	file, err := openFile(filePath)
+	if err != nil {
+		return nil, errs.Wrap(errs.ErrSourceUnavailable, err, "failed to open CSV file")
+	}
+	defer file.Close()
+	return l.discoverSchema(config, file, csvChunkRows)
+}
+
+// CountRows counts the data rows of a CSV file.
+func (l *CsvLoaderTyped) CountRows(config map[string]string, openFile FileOpener) (int, error) {
+	rows := 0
+	err := scanCsvChunks(config, nil, openFile, func(chunk *tables.DataTable, records [][]string) error {
+		rows += len(records)
+		return nil
+	})
+	return rows, err
+}
+
+// LoadRows returns the rows of a CSV file that match the conditions of a statement, with
+// the given columns. Rows are matched csvChunkRows at a time, and reading stops at the
+// LIMIT of a statement without ORDER BY.
+func (l *CsvLoaderTyped) LoadRows(config map[string]string, stmt *sqlquery.Statement, enrichedColumns []*EnrichedColumn, openFile FileOpener) (*tables.DataTable, error) {
+	policy, err := chunkCoercionPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	filter := &sqlquery.Statement{Table: stmt.Table, Items: []sqlquery.SelectItem{{Star: true}}, Where: stmt.Where}
+	var matched [][]string
+	err = scanCsvChunks(config, enrichedColumns, openFile, func(chunk *tables.DataTable, records [][]string) error {
+		plan, err := sqlquery.ResolveTable(chunk, filter)
+		if err != nil {
+			return err
+		}
+		for _, row := range plan.MatchingRows() {
+			matched = append(matched, records[row])
+		}
+		if stmt.Limit > 0 && len(stmt.OrderBy) == 0 && len(matched) >= stmt.Limit {
+			return errStopScan
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	table, _, err := typedCsvTable(matched, enrichedColumns, policy)
+	return table, err
+}
+
+// errStopScan is returned by the function called for each chunk to stop reading the file
+var errStopScan = errors.New("stop scan")
+
+// chunkCoercionPolicy returns the coercion policy of the chunks of a CSV file
+func chunkCoercionPolicy(config map[string]string) (CoercionPolicy, error) {
+	policy, err := ParseCoercionPolicy(config[CoercionPolicyKey])
+	if policy == CoercionShadow {
+		// Shadow columns are not part of the schema statements are resolved against
+		policy = CoercionZero
+	}
+	return policy, err
+}
+
+// scanCsvChunks streams the data rows of a CSV file and calls fn for every csvChunkRows
+// rows with a table of the given columns and the fields of the rows for these columns, so
+// that only one chunk of the file is held in memory at a time. Reading stops without
+// error when fn returns errStopScan.
+func scanCsvChunks(config map[string]string, enrichedColumns []*EnrichedColumn, openFile FileOpener, fn func(chunk *tables.DataTable, records [][]string) error) error {
+	filePath := config["file_path"]
+	if filePath == "" {
+		return fmt.Errorf("file_path is required")
+	}
+	delimiter := ','
+	if d := config["delimiter"]; d != "" {
+		delimiter = rune(d[0])
+	}
+	policy, err := chunkCoercionPolicy(config)
+	if err != nil {
+		return err
+	}
+
+	file, err := openFile(filePath)
+	if err != nil {
+		return errs.Wrap(errs.ErrSourceUnavailable, err, "failed to open CSV file")
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.Comma = delimiter
+	reader.ReuseRecord = true
+
+	// Columns are named by the header, or col_0, col_1, etc. without one
+	var header []string
+	if config["has_header"] != "false" {
+		if header, err = reader.Read(); err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+	}
+	fields := make([]int, len(enrichedColumns))
+	for i, col := range enrichedColumns {
+		fields[i] = slices.Index(header, col.Name)
+		if header == nil {
+			fmt.Sscanf(col.Name, "col_%d", &fields[i])
+		}
+		if fields[i] < 0 {
+			return errs.New(errs.ErrUnknownColumn, "column %q is not in CSV file %s", col.Name, filePath)
+		}
+	}
+
+	records := make([][]string, 0, csvChunkRows)
+	flush := func() error {
+		chunk, _, err := typedCsvTable(records, enrichedColumns, policy)
+		if err != nil {
+			return err
+		}
+		err = fn(chunk, records)
+		records = make([][]string, 0, csvChunkRows)
+		return err
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+		projected := make([]string, len(fields))
+		for i, field := range fields {
+			if field < len(record) {
+				projected[i] = record[field]
+			}
+		}
+		records = append(records, projected)
+		if len(records) == csvChunkRows {
+			if err := flush(); err != nil {
+				return ignoreStop(err)
+			}
+		}
+	}
+	if len(records) > 0 {
+		return ignoreStop(flush())
+	}
+	return nil
+}
+
+// ignoreStop returns nil for errStopScan
+func ignoreStop(err error) error {
+	if err == errStopScan {
+		return nil
+	}
+	return err
+}
+
+// chunkAggregation runs a grouped or aggregated statement on the chunks of a source and
+// merges the results, so that the rows of the source are never all loaded at once. Each
+// chunk computes COUNT, SUM, MIN and MAX, which are merged as they are, and the SUM and
+// COUNT of each AVG. COUNT(DISTINCT) items are merged from the distinct values of each
+// chunk, listed by a statement grouped on the counted column.
+type chunkAggregation struct {
+	stmt     *sqlquery.Statement
+	partial  *sqlquery.Statement         // GROUP BY columns, then the partial aggregates
+	parts    [][]int                     // Per item, the items of partial it is computed from
+	distinct map[int]*sqlquery.Statement // Per COUNT(DISTINCT) item, the statement listing its values
+	groups   map[string]*chunkGroup
+	order    []string // Keys of the groups, in the order they were first seen
+}
+
+// chunkGroup is a group of the rows of all chunks
+type chunkGroup struct {
+	keys     []any                // Values of the GROUP BY columns
+	values   []any                // Merged value of each item of the partial statement
+	distinct map[int]map[any]bool // Values counted by each COUNT(DISTINCT) item
+}
+
+func newChunkAggregation(stmt *sqlquery.Statement) *chunkAggregation {
+	a := &chunkAggregation{
+		stmt:     stmt,
+		partial:  &sqlquery.Statement{Table: stmt.Table, Where: stmt.Where, GroupBy: stmt.GroupBy},
+		parts:    make([][]int, len(stmt.Items)),
+		distinct: make(map[int]*sqlquery.Statement),
+		groups:   make(map[string]*chunkGroup),
+	}
+	for _, col := range stmt.GroupBy {
+		a.partial.Items = append(a.partial.Items, sqlquery.SelectItem{Column: col})
+	}
+	part := func(item sqlquery.SelectItem) int {
+		item.Alias = ""
+		i := slices.Index(a.partial.Items, item)
+		if i < 0 {
+			i = len(a.partial.Items)
+			a.partial.Items = append(a.partial.Items, item)
+		}
+		return i
+	}
+	// Every chunk reports the groups it has rows of, even without other aggregates
+	part(sqlquery.SelectItem{Star: true, Func: sqlquery.FuncCount})
+
+	for i, item := range stmt.Items {
+		switch {
+		case item.Func == sqlquery.FuncNone:
+			// Taken from the keys of the group
+		case item.Func == sqlquery.FuncCount && item.Distinct:
+			groupBy := stmt.GroupBy
+			if !slices.Contains(groupBy, item.Column) {
+				groupBy = append(slices.Clone(groupBy), item.Column)
+			}
+			listing := &sqlquery.Statement{Table: stmt.Table, Where: stmt.Where, GroupBy: groupBy}
+			for _, col := range groupBy {
+				listing.Items = append(listing.Items, sqlquery.SelectItem{Column: col})
+			}
+			a.distinct[i] = listing
+		case item.Func == sqlquery.FuncAvg:
+			a.parts[i] = []int{
+				part(sqlquery.SelectItem{Column: item.Column, Func: sqlquery.FuncSum}),
+				part(sqlquery.SelectItem{Column: item.Column, Func: sqlquery.FuncCount}),
+			}
+		default:
+			a.parts[i] = []int{part(item)}
+		}
+	}
+	return a
+}
+
+// columns returns the columns the statement reads
+func (a *chunkAggregation) columns() []string {
+	names := slices.Clone(a.stmt.GroupBy)
+	for _, item := range a.stmt.Items {
+		names = append(names, item.Column)
+	}
+	for _, cond := range a.stmt.Where {
+		names = append(names, cond.Column)
+	}
+	return names
+}
+
+// add merges the aggregates of a chunk
+func (a *chunkAggregation) add(chunk *tables.DataTable) error {
+	result, err := executeOn(chunk, a.partial)
+	if err != nil {
+		return err
+	}
+	keyCount := len(a.stmt.GroupBy)
+	for _, row := range result.Rows {
+		g := a.group(row[:keyCount])
+		for i := keyCount; i < len(row); i++ {
+			g.values[i] = mergePartial(a.partial.Items[i].Func, g.values[i], row[i])
+		}
+	}
+
+	for i, listing := range a.distinct {
+		result, err := executeOn(chunk, listing)
+		if err != nil {
+			return err
+		}
+		counted := slices.Index(listing.GroupBy, a.stmt.Items[i].Column)
+		for _, row := range result.Rows {
+			g := a.group(row[:keyCount])
+			if g.distinct[i] == nil {
+				g.distinct[i] = make(map[any]bool)
+			}
+			g.distinct[i][row[counted]] = true
+		}
+	}
+	return nil
+}
+
+// executeOn runs a statement on a chunk
+func executeOn(chunk *tables.DataTable, stmt *sqlquery.Statement) (*sqlquery.Result, error) {
+	plan, err := sqlquery.ResolveTable(chunk, stmt)
+	if err != nil {
+		return nil, err
+	}
+	return plan.Execute()
+}
+
+// group returns the group with the given GROUP BY values, adding it if it is new
+func (a *chunkAggregation) group(keys []any) *chunkGroup {
+	key := fmt.Sprintf("%#v", keys)
+	g, ok := a.groups[key]
+	if !ok {
+		g = &chunkGroup{keys: slices.Clone(keys), values: make([]any, len(a.partial.Items)), distinct: make(map[int]map[any]bool)}
+		a.groups[key] = g
+		a.order = append(a.order, key)
+	}
+	return g
+}
+
+// rows returns one row per group with the value of each item of the statement
+func (a *chunkAggregation) rows() [][]any {
+	rows := [][]any{}
+	for _, key := range a.order {
+		rows = append(rows, a.row(a.groups[key]))
+	}
+	// A statement without GROUP BY has a row even if the source has no rows
+	if len(rows) == 0 && len(a.stmt.GroupBy) == 0 {
+		rows = append(rows, a.row(&chunkGroup{values: make([]any, len(a.partial.Items))}))
+	}
+	return rows
+}
+
+// row returns the values of the items of the statement for a group
+func (a *chunkAggregation) row(g *chunkGroup) []any {
+	row := make([]any, len(a.stmt.Items))
+	for i, item := range a.stmt.Items {
+		switch {
+		case item.Func == sqlquery.FuncNone:
+			row[i] = g.keys[slices.Index(a.stmt.GroupBy, item.Column)]
+		case a.distinct[i] != nil:
+			row[i] = int64(len(g.distinct[i]))
+		case item.Func == sqlquery.FuncAvg:
+			sum, count := g.values[a.parts[i][0]], g.values[a.parts[i][1]]
+			if sum != nil && count != nil && count.(int64) > 0 {
+				row[i] = sum.(float64) / float64(count.(int64))
+			}
+		default:
+			row[i] = g.values[a.parts[i][0]]
+		}
+		if item.Func == sqlquery.FuncCount && row[i] == nil {
+			row[i] = int64(0)
+		}
+	}
+	return row
+}
+
+// mergePartial merges the value of an aggregate in a chunk into its value in the
+// previous chunks. Missing values are ignored.
+func mergePartial(f sqlquery.Func, merged, value any) any {
+	if merged == nil {
+		return value
+	}
+	if value == nil {
+		return merged
+	}
+	switch f {
+	case sqlquery.FuncCount:
+		return merged.(int64) + value.(int64)
+	case sqlquery.FuncSum:
+		return merged.(float64) + value.(float64)
+	case sqlquery.FuncMin:
+		if compareChunkValues(value, merged) < 0 {
+			return value
+		}
+	case sqlquery.FuncMax:
+		if compareChunkValues(value, merged) > 0 {
+			return value
+		}
+	}
+	return merged
+}
+
+// compareChunkValues compares two values of the same kind for MIN and MAX
+func compareChunkValues(a, b any) int {
+	switch a := a.(type) {
+	case float64:
+		b := b.(float64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case string:
+		return strings.Compare(a, b.(string))
+	}
+	return 0
+}
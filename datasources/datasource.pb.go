@@ -48,9 +48,29 @@ type ColumnAnnotation struct {
 	DisplayName string `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
 	// Entity type for join support (e.g., "customer_id", "order_id").
 	// Columns with matching entity types across tables can be joined.
-	EntityType    string `protobuf:"bytes,3,opt,name=entity_type,json=entityType,proto3" json:"entity_type,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	EntityType string `protobuf:"bytes,3,opt,name=entity_type,json=entityType,proto3" json:"entity_type,omitempty"`
+	// Category used to group the column in the column picker of wide tables
+	// (e.g., "billing", "shipping"). If empty, columns are grouped by name prefix.
+	Category string `protobuf:"bytes,4,opt,name=category,proto3" json:"category,omitempty"`
+	// Display labels for stored codes (e.g., "137" -> "OOMKilled"). Labels replace
+	// the codes in cells and group labels; filters accept both codes and labels.
+	ValueLabels map[string]string `protobuf:"bytes,5,rep,name=value_labels,json=valueLabels,proto3" json:"value_labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Whether the column identifies records (record IDs, codes) rather than
+	// measuring them. Aggregates of identifier columns default to count and unique
+	// count, and their sum and average are demoted. If unset, integer columns are
+	// detected from their entity type, value labels, name and distinct values.
+	Identifier *bool `protobuf:"varint,6,opt,name=identifier,proto3,oneof" json:"identifier,omitempty"`
+	// Whether the column has few distinct values (e.g., status, region). Husk
+	// tables can only be grouped on low-cardinality columns, whose groups are
+	// aggregated at the source.
+	LowCardinality bool `protobuf:"varint,7,opt,name=low_cardinality,json=lowCardinality,proto3" json:"low_cardinality,omitempty"`
+	// Format rules of the column's aggregates by aggregate type (e.g., "sum" ->
+	// "si", "avg" -> "fixed:2"), or "*" for all aggregates without a rule. Rules
+	// are "si", "sig" or "fixed" with optional digits (e.g., "si:2"), or
+	// "duration" for values in seconds.
+	AggregateFormats map[string]string `protobuf:"bytes,8,rep,name=aggregate_formats,json=aggregateFormats,proto3" json:"aggregate_formats,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *ColumnAnnotation) Reset() {
@@ -104,6 +124,41 @@ func (x *ColumnAnnotation) GetEntityType() string {
 	return ""
 }
 
+func (x *ColumnAnnotation) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *ColumnAnnotation) GetValueLabels() map[string]string {
+	if x != nil {
+		return x.ValueLabels
+	}
+	return nil
+}
+
+func (x *ColumnAnnotation) GetIdentifier() bool {
+	if x != nil && x.Identifier != nil {
+		return *x.Identifier
+	}
+	return false
+}
+
+func (x *ColumnAnnotation) GetLowCardinality() bool {
+	if x != nil {
+		return x.LowCardinality
+	}
+	return false
+}
+
+func (x *ColumnAnnotation) GetAggregateFormats() map[string]string {
+	if x != nil {
+		return x.AggregateFormats
+	}
+	return nil
+}
+
 // ColumnAnnotations defines annotations for columns in a data source.
 // The actual column schema (names, types) is discovered from the data source;
 // these annotations add display names and entity types on top.
@@ -113,7 +168,18 @@ type ColumnAnnotations struct {
 	AnnotationsId string `protobuf:"bytes,1,opt,name=annotations_id,json=annotationsId,proto3" json:"annotations_id,omitempty"`
 	// Column annotations. Only columns listed here have their metadata overridden;
 	// unlisted columns use defaults (column name as display name, no entity type).
-	Columns       []*ColumnAnnotation `protobuf:"bytes,2,rep,name=columns,proto3" json:"columns,omitempty"`
+	Columns []*ColumnAnnotation `protobuf:"bytes,2,rep,name=columns,proto3" json:"columns,omitempty"`
+	// Row-level checks across columns, evaluated when a source using these
+	// annotations is loaded. Violations are counted per rule in the
+	// _data_quality system table.
+	Validations []*ValidationRule `protobuf:"bytes,3,rep,name=validations,proto3" json:"validations,omitempty"`
+	// If set, a string column with this name is added to loaded tables listing
+	// the rules each row violates (empty for rows that pass every rule).
+	ViolationsColumn string `protobuf:"bytes,4,opt,name=violations_column,json=violationsColumn,proto3" json:"violations_column,omitempty"`
+	// Named sets of columns (e.g., "capacity", "billing") that the view of a
+	// table using these annotations can switch between, keeping its filters
+	// and grouping.
+	Presets       []*ColumnPreset `protobuf:"bytes,5,rep,name=presets,proto3" json:"presets,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -162,6 +228,140 @@ func (x *ColumnAnnotations) GetColumns() []*ColumnAnnotation {
 	return nil
 }
 
+func (x *ColumnAnnotations) GetValidations() []*ValidationRule {
+	if x != nil {
+		return x.Validations
+	}
+	return nil
+}
+
+func (x *ColumnAnnotations) GetViolationsColumn() string {
+	if x != nil {
+		return x.ViolationsColumn
+	}
+	return ""
+}
+
+func (x *ColumnAnnotations) GetPresets() []*ColumnPreset {
+	if x != nil {
+		return x.Presets
+	}
+	return nil
+}
+
+// ValidationRule is a boolean expression that every row is expected to satisfy
+// (e.g., "used <= limit", "start_time <= end_time"). Expressions use the same
+// language as computed columns and reference columns by name.
+type ValidationRule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the rule, reported in the data-quality table and violations column.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Boolean expression evaluated for every row.
+	Expression    string `protobuf:"bytes,2,opt,name=expression,proto3" json:"expression,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidationRule) Reset() {
+	*x = ValidationRule{}
+	mi := &file_datasource_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidationRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidationRule) ProtoMessage() {}
+
+func (x *ValidationRule) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidationRule.ProtoReflect.Descriptor instead.
+func (*ValidationRule) Descriptor() ([]byte, []int) {
+	return file_datasource_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ValidationRule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ValidationRule) GetExpression() string {
+	if x != nil {
+		return x.Expression
+	}
+	return ""
+}
+
+// ColumnPreset is a named set of columns defined by the owners of a table
+// (e.g., "capacity view", "health view").
+type ColumnPreset struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the preset, shown in the preset switcher of the table view.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Visible columns, in display order.
+	Columns       []string `protobuf:"bytes,2,rep,name=columns,proto3" json:"columns,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ColumnPreset) Reset() {
+	*x = ColumnPreset{}
+	mi := &file_datasource_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ColumnPreset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ColumnPreset) ProtoMessage() {}
+
+func (x *ColumnPreset) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ColumnPreset.ProtoReflect.Descriptor instead.
+func (*ColumnPreset) Descriptor() ([]byte, []int) {
+	return file_datasource_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ColumnPreset) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ColumnPreset) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
 // DataSource defines a single data source.
 type DataSource struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -183,13 +383,38 @@ type DataSource struct {
 	// This identifies the main entity type that this table is about.
 	// For example: "region" for a regions table, "customer_id" for a customers table.
 	PrimaryKeyEntityType string `protobuf:"bytes,6,opt,name=primary_key_entity_type,json=primaryKeyEntityType,proto3" json:"primary_key_entity_type,omitempty"`
-	unknownFields        protoimpl.UnknownFields
-	sizeCache            protoimpl.SizeCache
+	// Column whose entity URL a click on a whole row navigates to.
+	// The column must have an entity type with a URL template. When empty,
+	// clicking a row selects it and opens the detail panel instead.
+	// Can be overridden per view with the "rowlink" query parameter.
+	RowLinkColumn string `protobuf:"bytes,7,opt,name=row_link_column,json=rowLinkColumn,proto3" json:"row_link_column,omitempty"`
+	// Markdown documentation for the table, shown in an expandable panel above it.
+	// Use it to document caveats, units, and freshness.
+	Readme string `protobuf:"bytes,8,opt,name=readme,proto3" json:"readme,omitempty"`
+	// Path to a Markdown file with the table documentation, relative to the
+	// config file. Used when readme is empty.
+	ReadmeFile string `protobuf:"bytes,9,opt,name=readme_file,json=readmeFile,proto3" json:"readme_file,omitempty"`
+	// Retention of previously loaded versions of this source. Each (re)load
+	// creates a new version; older versions are kept for listing and restoring
+	// until the policy prunes them. When unset, only the current version is kept.
+	Retention *RetentionPolicy `protobuf:"bytes,10,opt,name=retention,proto3" json:"retention,omitempty"`
+	// View the table opens with when no columns are requested.
+	// When unset, the product's default columns (or the first columns) are shown.
+	DefaultView *DefaultView `protobuf:"bytes,11,opt,name=default_view,json=defaultView,proto3" json:"default_view,omitempty"`
+	// Groupings precomputed when the table loads or is reloaded, so that the
+	// most common grouped views skip grouping the rows on their first request.
+	HotGroupings []*HotGrouping `protobuf:"bytes,12,rep,name=hot_groupings,json=hotGroupings,proto3" json:"hot_groupings,omitempty"`
+	// Register only the schema and row count of the source, for sources too large
+	// to load. Rows are fetched from the source for each query, whose loader must
+	// implement HuskLoader.
+	Husk          bool `protobuf:"varint,13,opt,name=husk,proto3" json:"husk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DataSource) Reset() {
 	*x = DataSource{}
-	mi := &file_datasource_proto_msgTypes[2]
+	mi := &file_datasource_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -201,7 +426,7 @@ func (x *DataSource) String() string {
 func (*DataSource) ProtoMessage() {}
 
 func (x *DataSource) ProtoReflect() protoreflect.Message {
-	mi := &file_datasource_proto_msgTypes[2]
+	mi := &file_datasource_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -214,7 +439,7 @@ func (x *DataSource) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DataSource.ProtoReflect.Descriptor instead.
 func (*DataSource) Descriptor() ([]byte, []int) {
-	return file_datasource_proto_rawDescGZIP(), []int{2}
+	return file_datasource_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *DataSource) GetName() string {
@@ -259,6 +484,242 @@ func (x *DataSource) GetPrimaryKeyEntityType() string {
 	return ""
 }
 
+func (x *DataSource) GetRowLinkColumn() string {
+	if x != nil {
+		return x.RowLinkColumn
+	}
+	return ""
+}
+
+func (x *DataSource) GetReadme() string {
+	if x != nil {
+		return x.Readme
+	}
+	return ""
+}
+
+func (x *DataSource) GetReadmeFile() string {
+	if x != nil {
+		return x.ReadmeFile
+	}
+	return ""
+}
+
+func (x *DataSource) GetRetention() *RetentionPolicy {
+	if x != nil {
+		return x.Retention
+	}
+	return nil
+}
+
+func (x *DataSource) GetDefaultView() *DefaultView {
+	if x != nil {
+		return x.DefaultView
+	}
+	return nil
+}
+
+func (x *DataSource) GetHotGroupings() []*HotGrouping {
+	if x != nil {
+		return x.HotGroupings
+	}
+	return nil
+}
+
+func (x *DataSource) GetHusk() bool {
+	if x != nil {
+		return x.Husk
+	}
+	return false
+}
+
+// HotGrouping is a grouping of a table precomputed at load time.
+type HotGrouping struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Grouped columns of the table, outermost first.
+	Columns []string `protobuf:"bytes,1,rep,name=columns,proto3" json:"columns,omitempty"`
+	// Groupings with a higher priority are precomputed first.
+	Priority      int32 `protobuf:"varint,2,opt,name=priority,proto3" json:"priority,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HotGrouping) Reset() {
+	*x = HotGrouping{}
+	mi := &file_datasource_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HotGrouping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HotGrouping) ProtoMessage() {}
+
+func (x *HotGrouping) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HotGrouping.ProtoReflect.Descriptor instead.
+func (*HotGrouping) Descriptor() ([]byte, []int) {
+	return file_datasource_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HotGrouping) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *HotGrouping) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+// DefaultView defines the columns, grouping, sort and aggregates a table opens with.
+type DefaultView struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Visible columns, in display order.
+	Columns []string `protobuf:"bytes,1,rep,name=columns,proto3" json:"columns,omitempty"`
+	// Columns to group by, outermost first.
+	GroupedColumns []string `protobuf:"bytes,2,rep,name=grouped_columns,json=groupedColumns,proto3" json:"grouped_columns,omitempty"`
+	// Sort order, each column prefixed with + (ascending) or - (descending),
+	// e.g. "-amount".
+	Sort []string `protobuf:"bytes,3,rep,name=sort,proto3" json:"sort,omitempty"`
+	// Enabled aggregates per column, as a comma-separated list
+	// (e.g. "amount" -> "sum,avg").
+	Aggregates    map[string]string `protobuf:"bytes,4,rep,name=aggregates,proto3" json:"aggregates,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DefaultView) Reset() {
+	*x = DefaultView{}
+	mi := &file_datasource_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DefaultView) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DefaultView) ProtoMessage() {}
+
+func (x *DefaultView) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DefaultView.ProtoReflect.Descriptor instead.
+func (*DefaultView) Descriptor() ([]byte, []int) {
+	return file_datasource_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DefaultView) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *DefaultView) GetGroupedColumns() []string {
+	if x != nil {
+		return x.GroupedColumns
+	}
+	return nil
+}
+
+func (x *DefaultView) GetSort() []string {
+	if x != nil {
+		return x.Sort
+	}
+	return nil
+}
+
+func (x *DefaultView) GetAggregates() map[string]string {
+	if x != nil {
+		return x.Aggregates
+	}
+	return nil
+}
+
+// RetentionPolicy limits how many loaded versions of a data source are kept.
+// A version is pruned when it exceeds either limit. The current version is
+// never pruned.
+type RetentionPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Maximum number of versions to keep, including the current one (0 = no limit).
+	MaxVersions uint32 `protobuf:"varint,1,opt,name=max_versions,json=maxVersions,proto3" json:"max_versions,omitempty"`
+	// Maximum age in days of a retained version (0 = no limit).
+	MaxAgeDays    uint32 `protobuf:"varint,2,opt,name=max_age_days,json=maxAgeDays,proto3" json:"max_age_days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetentionPolicy) Reset() {
+	*x = RetentionPolicy{}
+	mi := &file_datasource_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetentionPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetentionPolicy) ProtoMessage() {}
+
+func (x *RetentionPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetentionPolicy.ProtoReflect.Descriptor instead.
+func (*RetentionPolicy) Descriptor() ([]byte, []int) {
+	return file_datasource_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RetentionPolicy) GetMaxVersions() uint32 {
+	if x != nil {
+		return x.MaxVersions
+	}
+	return 0
+}
+
+func (x *RetentionPolicy) GetMaxAgeDays() uint32 {
+	if x != nil {
+		return x.MaxAgeDays
+	}
+	return 0
+}
+
 // URLTemplate defines a single URL template with a display name.
 // Templates can use placeholders like {value}, {column}, {table}.
 type URLTemplate struct {
@@ -277,7 +738,7 @@ type URLTemplate struct {
 
 func (x *URLTemplate) Reset() {
 	*x = URLTemplate{}
-	mi := &file_datasource_proto_msgTypes[3]
+	mi := &file_datasource_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -289,7 +750,7 @@ func (x *URLTemplate) String() string {
 func (*URLTemplate) ProtoMessage() {}
 
 func (x *URLTemplate) ProtoReflect() protoreflect.Message {
-	mi := &file_datasource_proto_msgTypes[3]
+	mi := &file_datasource_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -302,7 +763,7 @@ func (x *URLTemplate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use URLTemplate.ProtoReflect.Descriptor instead.
 func (*URLTemplate) Descriptor() ([]byte, []int) {
-	return file_datasource_proto_rawDescGZIP(), []int{3}
+	return file_datasource_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *URLTemplate) GetName() string {
@@ -336,14 +797,17 @@ type EntityTypeDefinition struct {
 	Description string `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
 	// URL templates for navigating to related views.
 	// Multiple templates allow different actions (view, edit, filter, etc.).
-	Urls          []*URLTemplate `protobuf:"bytes,3,rep,name=urls,proto3" json:"urls,omitempty"`
+	Urls []*URLTemplate `protobuf:"bytes,3,rep,name=urls,proto3" json:"urls,omitempty"`
+	// Approximate matching for joins on string columns of this entity type.
+	// When unset, joins match keys exactly.
+	FuzzyJoin     *FuzzyJoin `protobuf:"bytes,4,opt,name=fuzzy_join,json=fuzzyJoin,proto3" json:"fuzzy_join,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *EntityTypeDefinition) Reset() {
 	*x = EntityTypeDefinition{}
-	mi := &file_datasource_proto_msgTypes[4]
+	mi := &file_datasource_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -355,7 +819,7 @@ func (x *EntityTypeDefinition) String() string {
 func (*EntityTypeDefinition) ProtoMessage() {}
 
 func (x *EntityTypeDefinition) ProtoReflect() protoreflect.Message {
-	mi := &file_datasource_proto_msgTypes[4]
+	mi := &file_datasource_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -368,7 +832,7 @@ func (x *EntityTypeDefinition) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EntityTypeDefinition.ProtoReflect.Descriptor instead.
 func (*EntityTypeDefinition) Descriptor() ([]byte, []int) {
-	return file_datasource_proto_rawDescGZIP(), []int{4}
+	return file_datasource_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *EntityTypeDefinition) GetName() string {
@@ -392,6 +856,66 @@ func (x *EntityTypeDefinition) GetUrls() []*URLTemplate {
 	return nil
 }
 
+func (x *EntityTypeDefinition) GetFuzzyJoin() *FuzzyJoin {
+	if x != nil {
+		return x.FuzzyJoin
+	}
+	return nil
+}
+
+// FuzzyJoin configures approximate matching of string join keys.
+//
+// Keys that don't match exactly are compared after normalization (case and
+// surrounding/repeated whitespace are ignored). Optionally, keys that still
+// don't match are compared by trigram similarity, using min-hash signatures
+// to find candidates. The confidence of each match is available as the
+// "_match_confidence" column of the join.
+type FuzzyJoin struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Minimum trigram (Jaccard) similarity in (0, 1] for a similarity match.
+	// 0 disables similarity matching and only normalized keys are matched.
+	SimilarityThreshold float64 `protobuf:"fixed64,1,opt,name=similarity_threshold,json=similarityThreshold,proto3" json:"similarity_threshold,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *FuzzyJoin) Reset() {
+	*x = FuzzyJoin{}
+	mi := &file_datasource_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FuzzyJoin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FuzzyJoin) ProtoMessage() {}
+
+func (x *FuzzyJoin) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FuzzyJoin.ProtoReflect.Descriptor instead.
+func (*FuzzyJoin) Descriptor() ([]byte, []int) {
+	return file_datasource_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FuzzyJoin) GetSimilarityThreshold() float64 {
+	if x != nil {
+		return x.SimilarityThreshold
+	}
+	return 0
+}
+
 // Hierarchy defines an ordered sequence of entity types representing a
 // containment or parent-child relationship.
 //
@@ -418,7 +942,7 @@ type Hierarchy struct {
 
 func (x *Hierarchy) Reset() {
 	*x = Hierarchy{}
-	mi := &file_datasource_proto_msgTypes[5]
+	mi := &file_datasource_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -430,7 +954,7 @@ func (x *Hierarchy) String() string {
 func (*Hierarchy) ProtoMessage() {}
 
 func (x *Hierarchy) ProtoReflect() protoreflect.Message {
-	mi := &file_datasource_proto_msgTypes[5]
+	mi := &file_datasource_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -443,7 +967,7 @@ func (x *Hierarchy) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Hierarchy.ProtoReflect.Descriptor instead.
 func (*Hierarchy) Descriptor() ([]byte, []int) {
-	return file_datasource_proto_rawDescGZIP(), []int{5}
+	return file_datasource_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *Hierarchy) GetName() string {
@@ -486,7 +1010,7 @@ type DataSourcesConfig struct {
 
 func (x *DataSourcesConfig) Reset() {
 	*x = DataSourcesConfig{}
-	mi := &file_datasource_proto_msgTypes[6]
+	mi := &file_datasource_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -498,7 +1022,7 @@ func (x *DataSourcesConfig) String() string {
 func (*DataSourcesConfig) ProtoMessage() {}
 
 func (x *DataSourcesConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_datasource_proto_msgTypes[6]
+	mi := &file_datasource_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -511,7 +1035,7 @@ func (x *DataSourcesConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DataSourcesConfig.ProtoReflect.Descriptor instead.
 func (*DataSourcesConfig) Descriptor() ([]byte, []int) {
-	return file_datasource_proto_rawDescGZIP(), []int{6}
+	return file_datasource_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *DataSourcesConfig) GetAnnotations() []*ColumnAnnotations {
@@ -546,15 +1070,40 @@ var File_datasource_proto protoreflect.FileDescriptor
 
 const file_datasource_proto_rawDesc = "" +
 	"\n" +
-	"\x10datasource.proto\x12\x15taxinomia.datasources\"j\n" +
+	"\x10datasource.proto\x12\x15taxinomia.datasources\"\xb1\x04\n" +
 	"\x10ColumnAnnotation\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
 	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\x12\x1f\n" +
 	"\ventity_type\x18\x03 \x01(\tR\n" +
-	"entityType\"}\n" +
+	"entityType\x12\x1a\n" +
+	"\bcategory\x18\x04 \x01(\tR\bcategory\x12[\n" +
+	"\fvalue_labels\x18\x05 \x03(\v28.taxinomia.datasources.ColumnAnnotation.ValueLabelsEntryR\vvalueLabels\x12#\n" +
+	"\n" +
+	"identifier\x18\x06 \x01(\bH\x00R\n" +
+	"identifier\x88\x01\x01\x12'\n" +
+	"\x0flow_cardinality\x18\a \x01(\bR\x0elowCardinality\x12j\n" +
+	"\x11aggregate_formats\x18\b \x03(\v2=.taxinomia.datasources.ColumnAnnotation.AggregateFormatsEntryR\x10aggregateFormats\x1a>\n" +
+	"\x10ValueLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aC\n" +
+	"\x15AggregateFormatsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\r\n" +
+	"\v_identifier\"\xb2\x02\n" +
 	"\x11ColumnAnnotations\x12%\n" +
 	"\x0eannotations_id\x18\x01 \x01(\tR\rannotationsId\x12A\n" +
-	"\acolumns\x18\x02 \x03(\v2'.taxinomia.datasources.ColumnAnnotationR\acolumns\"\xbb\x02\n" +
+	"\acolumns\x18\x02 \x03(\v2'.taxinomia.datasources.ColumnAnnotationR\acolumns\x12G\n" +
+	"\vvalidations\x18\x03 \x03(\v2%.taxinomia.datasources.ValidationRuleR\vvalidations\x12+\n" +
+	"\x11violations_column\x18\x04 \x01(\tR\x10violationsColumn\x12=\n" +
+	"\apresets\x18\x05 \x03(\v2#.taxinomia.datasources.ColumnPresetR\apresets\"D\n" +
+	"\x0eValidationRule\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1e\n" +
+	"\n" +
+	"expression\x18\x02 \x01(\tR\n" +
+	"expression\"<\n" +
+	"\fColumnPreset\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\acolumns\x18\x02 \x03(\tR\acolumns\"\x86\x05\n" +
 	"\n" +
 	"DataSource\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12%\n" +
@@ -563,19 +1112,49 @@ const file_datasource_proto_rawDesc = "" +
 	"\vsource_type\x18\x04 \x01(\tR\n" +
 	"sourceType\x12E\n" +
 	"\x06config\x18\x05 \x03(\v2-.taxinomia.datasources.DataSource.ConfigEntryR\x06config\x125\n" +
-	"\x17primary_key_entity_type\x18\x06 \x01(\tR\x14primaryKeyEntityType\x1a9\n" +
+	"\x17primary_key_entity_type\x18\x06 \x01(\tR\x14primaryKeyEntityType\x12&\n" +
+	"\x0frow_link_column\x18\a \x01(\tR\rrowLinkColumn\x12\x16\n" +
+	"\x06readme\x18\b \x01(\tR\x06readme\x12\x1f\n" +
+	"\vreadme_file\x18\t \x01(\tR\n" +
+	"readmeFile\x12D\n" +
+	"\tretention\x18\n" +
+	" \x01(\v2&.taxinomia.datasources.RetentionPolicyR\tretention\x12E\n" +
+	"\fdefault_view\x18\v \x01(\v2\".taxinomia.datasources.DefaultViewR\vdefaultView\x12G\n" +
+	"\rhot_groupings\x18\f \x03(\v2\".taxinomia.datasources.HotGroupingR\fhotGroupings\x12\x12\n" +
+	"\x04husk\x18\r \x01(\bR\x04husk\x1a9\n" +
 	"\vConfigEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\\\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"C\n" +
+	"\vHotGrouping\x12\x18\n" +
+	"\acolumns\x18\x01 \x03(\tR\acolumns\x12\x1a\n" +
+	"\bpriority\x18\x02 \x01(\x05R\bpriority\"\xf7\x01\n" +
+	"\vDefaultView\x12\x18\n" +
+	"\acolumns\x18\x01 \x03(\tR\acolumns\x12'\n" +
+	"\x0fgrouped_columns\x18\x02 \x03(\tR\x0egroupedColumns\x12\x12\n" +
+	"\x04sort\x18\x03 \x03(\tR\x04sort\x12R\n" +
+	"\n" +
+	"aggregates\x18\x04 \x03(\v22.taxinomia.datasources.DefaultView.AggregatesEntryR\n" +
+	"aggregates\x1a=\n" +
+	"\x0fAggregatesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"V\n" +
+	"\x0fRetentionPolicy\x12!\n" +
+	"\fmax_versions\x18\x01 \x01(\rR\vmaxVersions\x12 \n" +
+	"\fmax_age_days\x18\x02 \x01(\rR\n" +
+	"maxAgeDays\"\\\n" +
 	"\vURLTemplate\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
 	"\btemplate\x18\x02 \x01(\tR\btemplate\x12\x1d\n" +
 	"\n" +
-	"is_default\x18\x03 \x01(\bR\tisDefault\"\x84\x01\n" +
+	"is_default\x18\x03 \x01(\bR\tisDefault\"\xc5\x01\n" +
 	"\x14EntityTypeDefinition\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\x126\n" +
-	"\x04urls\x18\x03 \x03(\v2\".taxinomia.datasources.URLTemplateR\x04urls\"Y\n" +
+	"\x04urls\x18\x03 \x03(\v2\".taxinomia.datasources.URLTemplateR\x04urls\x12?\n" +
+	"\n" +
+	"fuzzy_join\x18\x04 \x01(\v2 .taxinomia.datasources.FuzzyJoinR\tfuzzyJoin\">\n" +
+	"\tFuzzyJoin\x121\n" +
+	"\x14similarity_threshold\x18\x01 \x01(\x01R\x13similarityThreshold\"Y\n" +
 	"\tHierarchy\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x16\n" +
@@ -598,30 +1177,48 @@ func file_datasource_proto_rawDescGZIP() []byte {
 	return file_datasource_proto_rawDescData
 }
 
-var file_datasource_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_datasource_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
 var file_datasource_proto_goTypes = []any{
 	(*ColumnAnnotation)(nil),     // 0: taxinomia.datasources.ColumnAnnotation
 	(*ColumnAnnotations)(nil),    // 1: taxinomia.datasources.ColumnAnnotations
-	(*DataSource)(nil),           // 2: taxinomia.datasources.DataSource
-	(*URLTemplate)(nil),          // 3: taxinomia.datasources.URLTemplate
-	(*EntityTypeDefinition)(nil), // 4: taxinomia.datasources.EntityTypeDefinition
-	(*Hierarchy)(nil),            // 5: taxinomia.datasources.Hierarchy
-	(*DataSourcesConfig)(nil),    // 6: taxinomia.datasources.DataSourcesConfig
-	nil,                          // 7: taxinomia.datasources.DataSource.ConfigEntry
+	(*ValidationRule)(nil),       // 2: taxinomia.datasources.ValidationRule
+	(*ColumnPreset)(nil),         // 3: taxinomia.datasources.ColumnPreset
+	(*DataSource)(nil),           // 4: taxinomia.datasources.DataSource
+	(*HotGrouping)(nil),          // 5: taxinomia.datasources.HotGrouping
+	(*DefaultView)(nil),          // 6: taxinomia.datasources.DefaultView
+	(*RetentionPolicy)(nil),      // 7: taxinomia.datasources.RetentionPolicy
+	(*URLTemplate)(nil),          // 8: taxinomia.datasources.URLTemplate
+	(*EntityTypeDefinition)(nil), // 9: taxinomia.datasources.EntityTypeDefinition
+	(*FuzzyJoin)(nil),            // 10: taxinomia.datasources.FuzzyJoin
+	(*Hierarchy)(nil),            // 11: taxinomia.datasources.Hierarchy
+	(*DataSourcesConfig)(nil),    // 12: taxinomia.datasources.DataSourcesConfig
+	nil,                          // 13: taxinomia.datasources.ColumnAnnotation.ValueLabelsEntry
+	nil,                          // 14: taxinomia.datasources.ColumnAnnotation.AggregateFormatsEntry
+	nil,                          // 15: taxinomia.datasources.DataSource.ConfigEntry
+	nil,                          // 16: taxinomia.datasources.DefaultView.AggregatesEntry
 }
 var file_datasource_proto_depIdxs = []int32{
-	0, // 0: taxinomia.datasources.ColumnAnnotations.columns:type_name -> taxinomia.datasources.ColumnAnnotation
-	7, // 1: taxinomia.datasources.DataSource.config:type_name -> taxinomia.datasources.DataSource.ConfigEntry
-	3, // 2: taxinomia.datasources.EntityTypeDefinition.urls:type_name -> taxinomia.datasources.URLTemplate
-	1, // 3: taxinomia.datasources.DataSourcesConfig.annotations:type_name -> taxinomia.datasources.ColumnAnnotations
-	2, // 4: taxinomia.datasources.DataSourcesConfig.sources:type_name -> taxinomia.datasources.DataSource
-	4, // 5: taxinomia.datasources.DataSourcesConfig.entity_types:type_name -> taxinomia.datasources.EntityTypeDefinition
-	5, // 6: taxinomia.datasources.DataSourcesConfig.hierarchies:type_name -> taxinomia.datasources.Hierarchy
-	7, // [7:7] is the sub-list for method output_type
-	7, // [7:7] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	13, // 0: taxinomia.datasources.ColumnAnnotation.value_labels:type_name -> taxinomia.datasources.ColumnAnnotation.ValueLabelsEntry
+	14, // 1: taxinomia.datasources.ColumnAnnotation.aggregate_formats:type_name -> taxinomia.datasources.ColumnAnnotation.AggregateFormatsEntry
+	0,  // 2: taxinomia.datasources.ColumnAnnotations.columns:type_name -> taxinomia.datasources.ColumnAnnotation
+	2,  // 3: taxinomia.datasources.ColumnAnnotations.validations:type_name -> taxinomia.datasources.ValidationRule
+	3,  // 4: taxinomia.datasources.ColumnAnnotations.presets:type_name -> taxinomia.datasources.ColumnPreset
+	15, // 5: taxinomia.datasources.DataSource.config:type_name -> taxinomia.datasources.DataSource.ConfigEntry
+	7,  // 6: taxinomia.datasources.DataSource.retention:type_name -> taxinomia.datasources.RetentionPolicy
+	6,  // 7: taxinomia.datasources.DataSource.default_view:type_name -> taxinomia.datasources.DefaultView
+	5,  // 8: taxinomia.datasources.DataSource.hot_groupings:type_name -> taxinomia.datasources.HotGrouping
+	16, // 9: taxinomia.datasources.DefaultView.aggregates:type_name -> taxinomia.datasources.DefaultView.AggregatesEntry
+	8,  // 10: taxinomia.datasources.EntityTypeDefinition.urls:type_name -> taxinomia.datasources.URLTemplate
+	10, // 11: taxinomia.datasources.EntityTypeDefinition.fuzzy_join:type_name -> taxinomia.datasources.FuzzyJoin
+	1,  // 12: taxinomia.datasources.DataSourcesConfig.annotations:type_name -> taxinomia.datasources.ColumnAnnotations
+	4,  // 13: taxinomia.datasources.DataSourcesConfig.sources:type_name -> taxinomia.datasources.DataSource
+	9,  // 14: taxinomia.datasources.DataSourcesConfig.entity_types:type_name -> taxinomia.datasources.EntityTypeDefinition
+	11, // 15: taxinomia.datasources.DataSourcesConfig.hierarchies:type_name -> taxinomia.datasources.Hierarchy
+	16, // [16:16] is the sub-list for method output_type
+	16, // [16:16] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_datasource_proto_init() }
@@ -629,13 +1226,14 @@ func file_datasource_proto_init() {
 	if File_datasource_proto != nil {
 		return
 	}
+	file_datasource_proto_msgTypes[0].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_datasource_proto_rawDesc), len(file_datasource_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   17,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
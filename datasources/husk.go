@@ -0,0 +1,213 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasources
+
+import (
+	"slices"
+
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/sqlquery"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// HuskLoader is implemented by loaders of sources too large to load, which can be
+// registered as husks: only their schema and row count are kept, and their rows are
+// fetched from the source for each query. Files of husks are read through a FileOpener,
+// so that loaders can stream them.
+type HuskLoader interface {
+	AggregateLoader
+
+	// SampleSchema discovers the schema of the source from its first rows, without
+	// reading all of it.
+	SampleSchema(config map[string]string, openFile FileOpener) (*TableSchema, error)
+
+	// CountRows returns the number of rows of the source.
+	CountRows(config map[string]string, openFile FileOpener) (int, error)
+
+	// LoadRows returns the rows of the source for a statement that doesn't aggregate
+	// rows, with the given columns. The statement is executed again on the returned
+	// rows, so the source may return rows that don't match its WHERE conditions, but
+	// must return every row that does, up to its LIMIT in its ORDER BY order.
+	LoadRows(config map[string]string, stmt *sqlquery.Statement, columns []*EnrichedColumn, openFile FileOpener) (*tables.DataTable, error)
+}
+
+// Husk is a source registered with only its schema and row count. Statements on a husk
+// are run at the source: grouped and aggregated statements with LoadAggregates, as long as
+// they group on columns annotated as low cardinality, and other statements with LoadRows.
+type Husk struct {
+	Columns  []*EnrichedColumn // Schema discovered from the source, with annotations
+	RowCount int               // Number of rows of the source when registered
+}
+
+// huskSource is a husk with what it takes to run statements at its source
+type huskSource struct {
+	*Husk
+	loader   HuskLoader
+	config   map[string]string
+	openFile FileOpener
+}
+
+// GetHusk returns the husk of a husk source, registering it on first use from the schema
+// and the row count of the source.
+func (m *Manager) GetHusk(sourceName string) (*Husk, error) {
+	source, err := m.huskSource(sourceName)
+	if err != nil {
+		return nil, err
+	}
+	return source.Husk, nil
+}
+
+// huskSource returns the husk of a source with its loader and configuration
+func (m *Manager) huskSource(sourceName string) (*huskSource, error) {
+	m.mu.RLock()
+	source, ok := m.sources[sourceName]
+	husk := m.husks[sourceName]
+	loader, isHuskLoader := m.loaders[source.GetSourceType()].(HuskLoader)
+	annotations := m.annotations[source.GetAnnotationsId()]
+	baseDir := m.baseDir
+	openFile := m.openerLocked()
+	m.mu.RUnlock()
+
+	switch {
+	case !ok:
+		return nil, errs.New(errs.ErrUnknownTable, "source %q not found", sourceName)
+	case !source.GetHusk():
+		return nil, errs.New(errs.ErrSourceUnavailable, "source %q is not a husk", sourceName)
+	case !isHuskLoader:
+		return nil, errs.New(errs.ErrSourceUnavailable, "loader of source type %q can't fetch rows for husk source %q", source.GetSourceType(), sourceName)
+	case openFile == nil:
+		return nil, errs.New(errs.ErrSourceUnavailable, "file reader not set; call SetFileOpener or SetFileReader before querying husks")
+	}
+	config := m.resolveConfigPaths(source.GetConfig(), baseDir)
+	if husk != nil {
+		return &huskSource{Husk: husk, loader: loader, config: config, openFile: openFile}, nil
+	}
+
+	schema, err := loader.SampleSchema(config, openFile)
+	if err != nil {
+		return nil, errs.Wrap(errs.ErrSourceUnavailable, err, "failed to discover schema for husk source %q", sourceName)
+	}
+	rowCount, err := loader.CountRows(config, openFile)
+	if err != nil {
+		return nil, errs.Wrap(errs.ErrSourceUnavailable, err, "failed to count rows of husk source %q", sourceName)
+	}
+	husk = &Husk{Columns: EnrichSchema(schema, annotations), RowCount: rowCount}
+
+	m.mu.Lock()
+	m.husks[sourceName] = husk
+	m.mu.Unlock()
+	return &huskSource{Husk: husk, loader: loader, config: config, openFile: openFile}, nil
+}
+
+// queryHusk runs a statement at the source of a husk
+func (m *Manager) queryHusk(stmt *sqlquery.Statement) (*sqlquery.Result, error) {
+	husk, err := m.huskSource(stmt.Table)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := sqlquery.ResolveTable(schemaTable(husk.Columns), stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	// The row count of the husk answers counts of all rows
+	if len(stmt.Where) == 0 && len(stmt.GroupBy) == 0 && len(stmt.Items) == 1 && stmt.Items[0].Star && stmt.Items[0].Func == sqlquery.FuncCount {
+		return plan.ExecuteRows([][]any{{int64(husk.RowCount)}})
+	}
+
+	if !isAggregate(stmt) {
+		return husk.loadRows(stmt)
+	}
+	if !plan.Pushable() {
+		return nil, errs.New(errs.ErrInvalidQuery, "Aggregates of husk table '%s' can't be filtered with LIKE", stmt.Table)
+	}
+	for _, col := range stmt.GroupBy {
+		if !husk.column(col).LowCardinality {
+			return nil, errs.New(errs.ErrInvalidQuery, "Husk table '%s' can only be grouped on low-cardinality columns, not '%s'", stmt.Table, col)
+		}
+	}
+	rows, err := husk.loader.LoadAggregates(husk.config, plan.PushdownStatement(), husk.Columns, husk.openFile)
+	if err != nil {
+		return nil, errs.Wrap(errs.ErrSourceUnavailable, err, "failed to aggregate rows of husk source %q", stmt.Table)
+	}
+	return plan.ExecuteRows(rows)
+}
+
+// loadRows fetches the rows of the source for a statement that doesn't aggregate rows, and
+// executes the statement on them
+func (husk *huskSource) loadRows(stmt *sqlquery.Statement) (*sqlquery.Result, error) {
+	var names []string
+	for _, item := range stmt.Items {
+		if item.Star {
+			names = nil
+			for _, col := range husk.Columns {
+				names = append(names, col.Name)
+			}
+			break
+		}
+		names = append(names, item.Column)
+	}
+	for _, cond := range stmt.Where {
+		names = append(names, cond.Column)
+	}
+	var cols []*EnrichedColumn
+	for _, col := range husk.Columns {
+		if slices.Contains(names, col.Name) {
+			cols = append(cols, col)
+		}
+	}
+
+	table, err := husk.loader.LoadRows(husk.config, stmt, cols, husk.openFile)
+	if err != nil {
+		return nil, errs.Wrap(errs.ErrSourceUnavailable, err, "failed to fetch rows of husk source %q", stmt.Table)
+	}
+	plan, err := sqlquery.ResolveTable(table, stmt)
+	if err != nil {
+		return nil, errs.Wrap(errs.ErrSourceUnavailable, err, "husk source %q returned rows without the requested columns", stmt.Table)
+	}
+	result, err := plan.Execute()
+	if err != nil {
+		return nil, err
+	}
+	result.PushedDown = true
+	return result, nil
+}
+
+// isAggregate reports whether a statement groups or aggregates rows
+func isAggregate(stmt *sqlquery.Statement) bool {
+	if len(stmt.GroupBy) > 0 {
+		return true
+	}
+	for _, item := range stmt.Items {
+		if item.Func != sqlquery.FuncNone {
+			return true
+		}
+	}
+	return false
+}
+
+// column returns the column of the husk with the given name
+func (husk *Husk) column(name string) *EnrichedColumn {
+	for _, col := range husk.Columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasources
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/sqlquery"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// huskLoader is an aggregating loader whose rows are fetched for each query
+type huskLoader struct {
+	aggregatingLoader
+	counts  int // Number of row counts
+	fetches [][]*EnrichedColumn
+}
+
+func (l *huskLoader) CountRows(config map[string]string, openFile FileOpener) (int, error) {
+	l.counts++
+	return 3, nil
+}
+
+// LoadRows returns all rows of the source, as sources may ignore the conditions
+func (l *huskLoader) LoadRows(config map[string]string, stmt *sqlquery.Statement, columns []*EnrichedColumn, openFile FileOpener) (*tables.DataTable, error) {
+	l.fetches = append(l.fetches, columns)
+	return l.Load(config, columns, func(path string) ([]byte, error) {
+		file, err := openFile(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return io.ReadAll(file)
+	})
+}
+
+func TestManagerQueryHusk(t *testing.T) {
+	loader := &huskLoader{aggregatingLoader: aggregatingLoader{CsvLoaderTyped: NewCsvLoaderTyped(), rows: [][]any{{"north", int64(2)}}}}
+	manager := newPushdownManager(&loader.aggregatingLoader)
+	manager.RegisterLoader(loader)
+	manager.AddAnnotations(&ColumnAnnotations{AnnotationsId: "orders", Columns: []*ColumnAnnotation{{Name: "region", LowCardinality: true}}})
+	manager.AddSource(&DataSource{Name: "orders", AnnotationsId: "orders", SourceType: "remote", Husk: true, Config: map[string]string{"file_path": "orders.csv"}})
+
+	husk, err := manager.GetHusk("orders")
+	if err != nil {
+		t.Fatalf("GetHusk: %v", err)
+	}
+	if husk.RowCount != 3 || len(husk.Columns) != 2 {
+		t.Errorf("expected the schema and row count of the source, got %+v", husk)
+	}
+
+	// Counts of all rows are answered by the husk
+	result, err := manager.Query("SELECT COUNT(*) FROM orders")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if !reflect.DeepEqual(result.Rows, [][]any{{int64(3)}}) || loader.counts != 1 || len(loader.stmts) != 0 {
+		t.Errorf("expected the row count of the husk, got %v", result.Rows)
+	}
+
+	// Groups of low-cardinality columns are aggregated at the source
+	result, err = manager.Query("SELECT region, COUNT(*) FROM orders WHERE amount IN ('100', '250') GROUP BY region")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if !reflect.DeepEqual(result.Rows, [][]any{{"north", int64(2)}}) || len(loader.stmts) != 1 {
+		t.Errorf("expected the groups of the source, got %v", result.Rows)
+	}
+	for _, sql := range []string{
+		"SELECT amount, COUNT(*) FROM orders GROUP BY amount",
+		"SELECT COUNT(*) FROM orders WHERE region LIKE '%th%'",
+	} {
+		if _, err := manager.Query(sql); !errors.Is(err, errs.ErrInvalidQuery) {
+			t.Errorf("Query(%q): expected an invalid query error, got %v", sql, err)
+		}
+	}
+
+	// Rows are fetched with the columns of the statement and filtered again
+	result, err = manager.Query("SELECT amount FROM orders WHERE region = 'north' ORDER BY amount DESC")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if !reflect.DeepEqual(result.Rows, [][]any{{int64(250)}, {int64(100)}}) {
+		t.Errorf("expected the amounts of the north, got %v", result.Rows)
+	}
+	if len(loader.fetches) != 1 || len(loader.fetches[0]) != 2 {
+		t.Errorf("expected the rows to be fetched with the amount and region columns, got %v", loader.fetches)
+	}
+	if manager.IsLoaded("orders") {
+		t.Error("expected the rows of the husk not to be kept")
+	}
+	if _, err := manager.LoadData("orders"); !errors.Is(err, errs.ErrSourceUnavailable) {
+		t.Errorf("expected husks not to be loaded, got %v", err)
+	}
+}
+
+func TestCsvLoaderTypedHusk(t *testing.T) {
+	saved := csvChunkRows
+	defer func() { csvChunkRows = saved }()
+	csvChunkRows = 2
+
+	manager := NewManager()
+	manager.RegisterLoader(NewCsvLoaderTyped())
+	manager.SetFileReader(func(string) ([]byte, error) {
+		return []byte("region,amount,status\nnorth,100,shipped\nsouth,75,pending\nnorth,250,shipped\nwest,300,cancelled\nnorth,50,pending\n"), nil
+	})
+	manager.AddAnnotations(&ColumnAnnotations{AnnotationsId: "orders", Columns: []*ColumnAnnotation{{Name: "region", LowCardinality: true}}})
+	manager.AddSource(&DataSource{Name: "orders", AnnotationsId: "orders", SourceType: "csv_typed", Husk: true, Config: map[string]string{"file_path": "orders.csv"}})
+
+	husk, err := manager.GetHusk("orders")
+	if err != nil {
+		t.Fatalf("GetHusk: %v", err)
+	}
+	if husk.RowCount != 5 || len(husk.Columns) != 3 {
+		t.Errorf("expected 5 rows and 3 columns, got %+v", husk)
+	}
+
+	for sql, want := range map[string][][]any{
+		"SELECT region, COUNT(*), SUM(amount) FROM orders WHERE status IN ('shipped', 'pending') GROUP BY region ORDER BY region": {
+			{"north", int64(3), 400.0}, {"south", int64(1), 75.0},
+		},
+		"SELECT region, amount FROM orders WHERE status = 'pending' ORDER BY amount": {{"north", int64(50)}, {"south", int64(75)}},
+		"SELECT amount FROM orders WHERE region = 'north' LIMIT 2":                   {{int64(100)}, {int64(250)}},
+		"SELECT status FROM orders WHERE status LIKE '%cancel%'":                     {{"cancelled"}},
+		"SELECT amount FROM orders WHERE region = 'east'":                            nil,
+	} {
+		result, err := manager.Query(sql)
+		if err != nil {
+			t.Fatalf("Query(%q): %v", sql, err)
+		}
+		if !result.PushedDown || !reflect.DeepEqual(result.Rows, want) {
+			t.Errorf("Query(%q) = %v, want %v (pushed down: %v)", sql, result.Rows, want, result.PushedDown)
+		}
+	}
+	if manager.IsLoaded("orders") {
+		t.Error("expected the rows of the husk not to be kept")
+	}
+}
+
+// generatedCsv is a CSV file of orders generated as it is read, recording how far it was
+// read and the most bytes asked for at once
+type generatedCsv struct {
+	rows, next int
+	pending    []byte
+	read       int
+	maxRead    int
+}
+
+func (f *generatedCsv) Read(p []byte) (int, error) {
+	f.maxRead = max(f.maxRead, len(p))
+	for len(f.pending) < len(p) && f.next < f.rows {
+		if f.next == 0 {
+			f.pending = append(f.pending, "region,amount\n"...)
+		}
+		f.pending = fmt.Appendf(f.pending, "r%d,%d\n", f.next%3, f.next)
+		f.next++
+	}
+	if len(f.pending) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, f.pending)
+	f.pending = f.pending[n:]
+	f.read += n
+	return n, nil
+}
+
+func (f *generatedCsv) Close() error { return nil }
+
+func TestCsvLoaderTypedHuskStreamsFile(t *testing.T) {
+	saved := csvChunkRows
+	defer func() { csvChunkRows = saved }()
+	csvChunkRows = 1000
+
+	const rows = 200000
+	var files []*generatedCsv
+	manager := NewManager()
+	manager.RegisterLoader(NewCsvLoaderTyped())
+	manager.SetFileOpener(func(string) (io.ReadCloser, error) {
+		files = append(files, &generatedCsv{rows: rows})
+		return files[len(files)-1], nil
+	})
+	manager.AddAnnotations(&ColumnAnnotations{AnnotationsId: "orders", Columns: []*ColumnAnnotation{{Name: "region", LowCardinality: true}}})
+	manager.AddSource(&DataSource{Name: "orders", AnnotationsId: "orders", SourceType: "csv_typed", Husk: true, Config: map[string]string{"file_path": "orders.csv"}})
+
+	husk, err := manager.GetHusk("orders")
+	if err != nil {
+		t.Fatalf("GetHusk: %v", err)
+	}
+	if husk.RowCount != rows {
+		t.Errorf("expected %d rows, got %d", rows, husk.RowCount)
+	}
+	result, err := manager.Query("SELECT region, COUNT(*) FROM orders GROUP BY region ORDER BY region")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if want := [][]any{{"r0", int64(66667)}, {"r1", int64(66667)}, {"r2", int64(66666)}}; !reflect.DeepEqual(result.Rows, want) {
+		t.Errorf("got %v, want %v", result.Rows, want)
+	}
+
+	// Rows are fetched until the LIMIT is reached, not from the whole file
+	files = nil
+	if _, err := manager.Query("SELECT amount FROM orders WHERE region = 'r1' LIMIT 10"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(files) != 1 || files[0].next == rows {
+		t.Errorf("expected the file to be read up to the first chunk, read %d of %d rows", files[0].next, rows)
+	}
+	for _, f := range files {
+		if f.maxRead > 1<<20 {
+			t.Errorf("expected the file to be read in small buffers, got a read of %d bytes", f.maxRead)
+		}
+	}
+}
@@ -22,6 +22,9 @@ limitations under the License.
 package datasources
 
 import (
+	"bytes"
+	"io"
+
 	"github.com/google/taxinomia/core/columns"
 	"github.com/google/taxinomia/core/tables"
 )
@@ -31,6 +34,23 @@ import (
 // keeping file I/O outside the library core.
 type FileReader func(path string) ([]byte, error)
 
+// FileOpener is a function that opens a file for reading. Loaders that process a file in
+// a single pass, such as the husk and pushdown paths of CsvLoaderTyped, read it through a
+// FileOpener so that files larger than memory are streamed instead of read whole.
+type FileOpener func(path string) (io.ReadCloser, error)
+
+// readerOpener returns a FileOpener serving files read whole with readFile, for callers
+// that only inject a FileReader.
+func readerOpener(readFile FileReader) FileOpener {
+	return func(path string) (io.ReadCloser, error) {
+		data, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
+
 // DirEntry represents a directory entry returned by DirReader.
 type DirEntry struct {
 	Name  string
@@ -83,6 +103,9 @@ func (t ColumnType) String() string {
 type ColumnSchema struct {
 	Name string
 	Type ColumnType
+	// Ambiguous is set when the loader could not infer the type reliably,
+	// e.g. the sampled values are all empty or only partly numeric.
+	Ambiguous bool
 }
 
 // TableSchema represents the full table schema discovered from a data source.
@@ -96,6 +119,18 @@ type EnrichedColumn struct {
 	Type        ColumnType
 	DisplayName string
 	EntityType  string
+	Category    string
+	// Uncurated is set for columns without annotation whose type is ambiguous.
+	// They are loaded as pass-through string columns.
+	Uncurated bool
+	// ValueLabels maps stored codes to display labels.
+	ValueLabels map[string]string
+	// Identifier marks the column as an identifier or a measure (nil = detect).
+	Identifier *bool
+	// LowCardinality marks columns with few distinct values, which husk tables can be grouped on.
+	LowCardinality bool
+	// AggregateFormats maps aggregate types to the format rules of their values.
+	AggregateFormats map[string]string
 }
 
 // DataSourceLoader is the interface that all data source loaders must implement.
@@ -118,6 +153,7 @@ type DataSourceLoader interface {
 
 // EnrichSchema combines a discovered TableSchema with ColumnAnnotations.
 // For each column in the schema, it applies display_name and entity_type from annotations.
+// Columns without annotation and with an ambiguous type are kept as uncurated string columns.
 func EnrichSchema(schema *TableSchema, annotations *ColumnAnnotations) []*EnrichedColumn {
 	annotationMap := AnnotationsToColumnMap(annotations)
 
@@ -135,6 +171,14 @@ func EnrichSchema(schema *TableSchema, annotations *ColumnAnnotations) []*Enrich
 				enriched.DisplayName = ann.GetDisplayName()
 			}
 			enriched.EntityType = ann.GetEntityType()
+			enriched.Category = ann.GetCategory()
+			enriched.ValueLabels = ann.GetValueLabels()
+			enriched.Identifier = ann.Identifier
+			enriched.LowCardinality = ann.GetLowCardinality()
+			enriched.AggregateFormats = ann.GetAggregateFormats()
+		} else if col.Ambiguous {
+			enriched.Type = TypeString
+			enriched.Uncurated = true
 		}
 
 		result[i] = enriched
@@ -0,0 +1,181 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+*/
+
+package datasources_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/google/taxinomia/datasources"
+	"github.com/google/taxinomia/datasources/loadertest"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// encodeCSV writes a dataset as a CSV file with a header row.
+func encodeCSV(ds *loadertest.Dataset) (map[string]string, datasources.FileReader, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := make([]string, len(ds.Columns))
+	for i, col := range ds.Columns {
+		header[i] = col.Name
+	}
+	if err := w.Write(header); err != nil {
+		return nil, nil, err
+	}
+	if err := w.WriteAll(ds.Rows); err != nil {
+		return nil, nil, err
+	}
+
+	const path = "conformance.csv"
+	data := buf.Bytes()
+	readFile := func(p string) ([]byte, error) {
+		if p != path {
+			return nil, fmt.Errorf("unexpected path %q", p)
+		}
+		return data, nil
+	}
+	return map[string]string{"file_path": path}, readFile, nil
+}
+
+// protoFieldTypes maps the column types of the suite's datasets to proto field types
+var protoFieldTypes = map[datasources.ColumnType]descriptorpb.FieldDescriptorProto_Type{
+	datasources.TypeString:  descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	datasources.TypeInt64:   descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	datasources.TypeFloat64: descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+	datasources.TypeBool:    descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+}
+
+// protoDatasets numbers the datasets encoded as protos. Each dataset gets its own proto
+// package and descriptor set file, since ProtoLoader loads a descriptor set path once.
+var protoDatasets int
+
+// encodeProto writes a dataset as a binary proto of a Table message with a repeated Row
+// field, along with its descriptor set. Missing values are left unset.
+func encodeProto(ds *loadertest.Dataset) (map[string]string, datasources.FileReader, error) {
+	protoDatasets++
+	pkg := fmt.Sprintf("conformance.d%d", protoDatasets)
+	row := &descriptorpb.DescriptorProto{Name: proto.String("Row")}
+	for i, col := range ds.Columns {
+		row.Field = append(row.Field, &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(col.Name),
+			Number: proto.Int32(int32(i + 1)),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   protoFieldTypes[col.Type].Enum(),
+		})
+	}
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String(fmt.Sprintf("conformance_d%d.proto", protoDatasets)),
+		Package: proto.String(pkg),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{row, {
+			Name: proto.String("Table"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("rows"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: proto.String("." + pkg + ".Row"),
+			}},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	descriptorSet, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{fdp}})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tableDesc := fd.Messages().ByName("Table")
+	rowDesc := fd.Messages().ByName("Row")
+	table := dynamicpb.NewMessage(tableDesc)
+	rows := table.Mutable(tableDesc.Fields().ByName("rows")).List()
+	for _, values := range ds.Rows {
+		msg := dynamicpb.NewMessage(rowDesc)
+		for i, v := range values {
+			if v == "" {
+				continue
+			}
+			value, err := protoValue(ds.Columns[i].Type, v)
+			if err != nil {
+				return nil, nil, err
+			}
+			msg.Set(rowDesc.Fields().Get(i), value)
+		}
+		rows.Append(protoreflect.ValueOfMessage(msg))
+	}
+	data, err := proto.Marshal(table)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := map[string][]byte{
+		"conformance.binpb": data,
+		fmt.Sprintf("conformance_d%d.pb", protoDatasets): descriptorSet,
+	}
+	config := map[string]string{
+		"proto_file":     "conformance.binpb",
+		"message_type":   pkg + ".Table",
+		"descriptor_set": fmt.Sprintf("conformance_d%d.pb", protoDatasets),
+	}
+	readFile := func(p string) ([]byte, error) {
+		data, ok := files[p]
+		if !ok {
+			return nil, fmt.Errorf("unexpected path %q", p)
+		}
+		return data, nil
+	}
+	return config, readFile, nil
+}
+
+// protoValue parses the canonical text of a value as a proto value of the column type
+func protoValue(typ datasources.ColumnType, s string) (protoreflect.Value, error) {
+	switch typ {
+	case datasources.TypeInt64:
+		v, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case datasources.TypeFloat64:
+		v, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case datasources.TypeBool:
+		v, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(v), err
+	default:
+		return protoreflect.ValueOfString(s), nil
+	}
+}
+
+func TestCsvLoaderConformance(t *testing.T) {
+	loadertest.Run(t, loadertest.Harness{
+		Loader: datasources.NewCsvLoader(),
+		Encode: encodeCSV,
+	})
+}
+
+func TestCsvLoaderTypedConformance(t *testing.T) {
+	loadertest.Run(t, loadertest.Harness{
+		Loader: datasources.NewCsvLoaderTyped(),
+		Encode: encodeCSV,
+		Typed:  true,
+	})
+}
+
+func TestProtoLoaderConformance(t *testing.T) {
+	loadertest.Run(t, loadertest.Harness{
+		Loader: datasources.NewProtoLoader(),
+		Encode: encodeProto,
+		Typed:  true,
+	})
+}
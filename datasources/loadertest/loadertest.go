@@ -0,0 +1,367 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loadertest implements a conformance suite for datasources.DataSourceLoader
+// implementations. A loader's tests call Run with a Harness that knows how to encode
+// a Dataset in the loader's source format; the suite then checks schema discovery,
+// type coercion, annotation enrichment, null handling, error kinds, and large inputs
+// the same way for every loader. Loaders that implement datasources.HuskLoader are also
+// checked to stream large files rather than read them whole.
+package loadertest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/tables"
+	"github.com/google/taxinomia/datasources"
+)
+
+// Column describes one column of a Dataset.
+type Column struct {
+	Name string
+	Type datasources.ColumnType
+}
+
+// Dataset is a table the suite asks the harness to encode. Values are given in
+// their canonical text form ("42", "2.5", "true"); an empty string is a missing
+// (null) value.
+type Dataset struct {
+	Columns []Column
+	Rows    [][]string
+}
+
+// Harness adapts a loader under test to the suite.
+type Harness struct {
+	// Loader is the loader under test.
+	Loader datasources.DataSourceLoader
+
+	// Encode writes the dataset in the loader's source format and returns the
+	// loader config and a FileReader serving the encoded data.
+	Encode func(ds *Dataset) (map[string]string, datasources.FileReader, error)
+
+	// Typed reports whether the loader discovers column types. Loaders that are
+	// not typed must discover every column as datasources.TypeString.
+	Typed bool
+}
+
+// Run runs the conformance suite against the harness.
+func Run(t *testing.T, h Harness) {
+	t.Run("SourceType", func(t *testing.T) {
+		if h.Loader.SourceType() == "" {
+			t.Error("SourceType must not be empty")
+		}
+	})
+	t.Run("DiscoverSchema", func(t *testing.T) { testDiscoverSchema(t, h) })
+	t.Run("TypeCoercion", func(t *testing.T) { testLoad(t, h, basicDataset()) })
+	t.Run("Annotations", func(t *testing.T) { testAnnotations(t, h) })
+	t.Run("NullValues", func(t *testing.T) { testLoad(t, h, nullDataset()) })
+	t.Run("SourceUnavailable", func(t *testing.T) { testSourceUnavailable(t, h) })
+	t.Run("LargeInput", func(t *testing.T) {
+		rows := 100000
+		if testing.Short() {
+			rows = 10000
+		}
+		ds := largeDataset(rows)
+		testLoad(t, h, ds)
+		t.Run("Streaming", func(t *testing.T) { testStreaming(t, h, ds) })
+	})
+}
+
+// basicDataset has one column of each type the suite checks coercion for.
+func basicDataset() *Dataset {
+	return &Dataset{
+		Columns: []Column{
+			{Name: "name", Type: datasources.TypeString},
+			{Name: "count", Type: datasources.TypeInt64},
+			{Name: "ratio", Type: datasources.TypeFloat64},
+			{Name: "active", Type: datasources.TypeBool},
+		},
+		Rows: [][]string{
+			{"alpha", "1", "0.5", "true"},
+			{"beta", "-20", "2.25", "false"},
+			{"gamma", "300", "-1.5", "true"},
+		},
+	}
+}
+
+// nullDataset has missing values in every column.
+func nullDataset() *Dataset {
+	return &Dataset{
+		Columns: []Column{
+			{Name: "name", Type: datasources.TypeString},
+			{Name: "count", Type: datasources.TypeInt64},
+			{Name: "ratio", Type: datasources.TypeFloat64},
+		},
+		Rows: [][]string{
+			{"alpha", "1", "0.5"},
+			{"", "", ""},
+			{"gamma", "3", "1.5"},
+		},
+	}
+}
+
+// largeDataset has the given number of rows with distinct values, padded with a long
+// note so that the encoded file is large next to the memory a streaming loader needs.
+func largeDataset(rows int) *Dataset {
+	ds := &Dataset{
+		Columns: []Column{
+			{Name: "id", Type: datasources.TypeString},
+			{Name: "value", Type: datasources.TypeInt64},
+			{Name: "note", Type: datasources.TypeString},
+		},
+		Rows: make([][]string, rows),
+	}
+	note := strings.Repeat("streamed ", 28)
+	for i := range ds.Rows {
+		ds.Rows[i] = []string{fmt.Sprintf("row-%d", i), strconv.Itoa(i), note}
+	}
+	return ds
+}
+
+// expectedType returns the type the loader must discover for a column.
+func (h Harness) expectedType(col Column) datasources.ColumnType {
+	if !h.Typed {
+		return datasources.TypeString
+	}
+	return col.Type
+}
+
+func (h Harness) encode(t *testing.T, ds *Dataset) (map[string]string, datasources.FileReader) {
+	t.Helper()
+	config, readFile, err := h.Encode(ds)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	return config, readFile
+}
+
+func testDiscoverSchema(t *testing.T, h Harness) {
+	ds := basicDataset()
+	config, readFile := h.encode(t, ds)
+
+	schema, err := h.Loader.DiscoverSchema(config, readFile)
+	if err != nil {
+		t.Fatalf("DiscoverSchema failed: %v", err)
+	}
+	if len(schema.Columns) != len(ds.Columns) {
+		t.Fatalf("expected %d columns, got %d", len(ds.Columns), len(schema.Columns))
+	}
+	for i, want := range ds.Columns {
+		got := schema.Columns[i]
+		if got.Name != want.Name {
+			t.Errorf("column %d: expected name %q, got %q", i, want.Name, got.Name)
+		}
+		if got.Type != h.expectedType(want) {
+			t.Errorf("column %q: expected type %s, got %s", want.Name, h.expectedType(want), got.Type)
+		}
+	}
+}
+
+// load discovers, enriches and loads the dataset the way datasources.Manager does.
+func (h Harness) load(t *testing.T, ds *Dataset, annotations *datasources.ColumnAnnotations) *tables.DataTable {
+	t.Helper()
+	config, readFile := h.encode(t, ds)
+
+	schema, err := h.Loader.DiscoverSchema(config, readFile)
+	if err != nil {
+		t.Fatalf("DiscoverSchema failed: %v", err)
+	}
+	table, err := h.Loader.Load(config, datasources.EnrichSchema(schema, annotations), readFile)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return table
+}
+
+func testLoad(t *testing.T, h Harness, ds *Dataset) {
+	table := h.load(t, ds, nil)
+
+	if table.Length() != len(ds.Rows) {
+		t.Fatalf("expected %d rows, got %d", len(ds.Rows), table.Length())
+	}
+	for c, colSpec := range ds.Columns {
+		col := table.GetColumn(colSpec.Name)
+		if col == nil {
+			t.Errorf("column %q missing from loaded table", colSpec.Name)
+			continue
+		}
+		typ := h.expectedType(colSpec)
+		mismatches := 0
+		for r, row := range ds.Rows {
+			got, err := col.GetString(uint32(r))
+			if err != nil {
+				t.Fatalf("column %q row %d: %v", colSpec.Name, r, err)
+			}
+			if !sameValue(typ, row[c], got) {
+				t.Errorf("column %q row %d: expected %q (%s), got %q", colSpec.Name, r, row[c], typ, got)
+				if mismatches++; mismatches >= 5 {
+					break
+				}
+			}
+		}
+	}
+}
+
+func testAnnotations(t *testing.T, h Harness) {
+	annotations := &datasources.ColumnAnnotations{
+		Columns: []*datasources.ColumnAnnotation{
+			{Name: "name", DisplayName: "Item Name", EntityType: "test.item"},
+			{Name: "count", DisplayName: "Item Count"},
+		},
+	}
+	table := h.load(t, basicDataset(), annotations)
+
+	for _, want := range annotations.Columns {
+		col := table.GetColumn(want.GetName())
+		if col == nil {
+			t.Errorf("column %q missing from loaded table", want.GetName())
+			continue
+		}
+		def := col.ColumnDef()
+		if def.DisplayName() != want.GetDisplayName() {
+			t.Errorf("column %q: expected display name %q, got %q", want.GetName(), want.GetDisplayName(), def.DisplayName())
+		}
+		if def.EntityType() != want.GetEntityType() {
+			t.Errorf("column %q: expected entity type %q, got %q", want.GetName(), want.GetEntityType(), def.EntityType())
+		}
+	}
+
+	// Columns without annotations default their display name to the column name
+	if col := table.GetColumn("ratio"); col != nil && col.ColumnDef().DisplayName() != "ratio" {
+		t.Errorf("column %q: expected default display name %q, got %q", "ratio", "ratio", col.ColumnDef().DisplayName())
+	}
+}
+
+func testSourceUnavailable(t *testing.T, h Harness) {
+	config, _ := h.encode(t, basicDataset())
+	missing := func(path string) ([]byte, error) {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+
+	_, err := h.Loader.DiscoverSchema(config, missing)
+	if err == nil {
+		t.Fatal("expected DiscoverSchema to fail when the source cannot be read")
+	}
+	if !errors.Is(err, errs.ErrSourceUnavailable) {
+		t.Errorf("expected error of kind ErrSourceUnavailable, got %v", err)
+	}
+}
+
+// testStreaming counts the rows of a large file through a FileOpener and checks that the
+// heap never grows by as much as half the file, i.e. that the loader doesn't read it whole.
+func testStreaming(t *testing.T, h Harness, ds *Dataset) {
+	husk, ok := h.Loader.(datasources.HuskLoader)
+	if !ok {
+		t.Skip("loader does not implement datasources.HuskLoader")
+	}
+	if testing.Short() {
+		t.Skip("large file is too small to measure in short mode")
+	}
+	config, readFile := h.encode(t, ds)
+	var size int
+	var peak uint64
+	openFile := func(path string) (io.ReadCloser, error) {
+		data, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		size = len(data)
+		return io.NopCloser(&heapSampler{r: bytes.NewReader(data), peak: &peak}), nil
+	}
+
+	// Keep garbage low so that the heap mostly holds what the loader keeps alive
+	defer debug.SetGCPercent(debug.SetGCPercent(10))
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	baseline := stats.HeapAlloc
+	peak = baseline
+
+	count, err := husk.CountRows(config, openFile)
+	if err != nil {
+		t.Fatalf("CountRows failed: %v", err)
+	}
+	if count != len(ds.Rows) {
+		t.Errorf("expected %d rows, got %d", len(ds.Rows), count)
+	}
+	if growth := peak - baseline; growth > uint64(size/2) {
+		t.Errorf("heap grew by %d bytes while counting the rows of a %d byte file; the file must be streamed", growth, size)
+	}
+}
+
+// heapSampler is a reader that records the largest heap size seen after every megabyte read
+type heapSampler struct {
+	r      io.Reader
+	unread int
+	peak   *uint64
+}
+
+func (s *heapSampler) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if s.unread -= n; s.unread <= 0 {
+		s.unread = 1 << 20
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		*s.peak = max(*s.peak, stats.HeapAlloc)
+	}
+	return n, err
+}
+
+// sameValue reports whether a loaded value matches the expected canonical text.
+// Missing values must load as the zero value of the column type.
+func sameValue(typ datasources.ColumnType, want, got string) bool {
+	switch typ {
+	case datasources.TypeInt64, datasources.TypeUint32, datasources.TypeUint64, datasources.TypeFloat64:
+		w, g := parseNumber(want), parseNumber(got)
+		return w != nil && g != nil && *w == *g
+	case datasources.TypeBool:
+		return parseBool(want) == parseBool(got)
+	default:
+		return want == got
+	}
+}
+
+func parseNumber(s string) *float64 {
+	if s == "" {
+		zero := 0.0
+		return &zero
+	}
+	v, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+func parseBool(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "1", "yes":
+		return true
+	}
+	return false
+}
@@ -21,10 +21,16 @@ package datasources
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/google/taxinomia/core/aggregates"
+	"github.com/google/taxinomia/core/chaos"
 	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/query"
 	"github.com/google/taxinomia/core/tables"
 	"google.golang.org/protobuf/encoding/prototext"
 )
@@ -61,11 +67,34 @@ type Manager struct {
 	// Registered loaders indexed by source_type
 	loaders map[string]DataSourceLoader
 
+	// Table documentation read from readme files, indexed by source name
+	readmes map[string]string
+
+	// Time each loaded or registered table's data was loaded, indexed by name
+	loadedAt map[string]time.Time
+
+	// Retained versions of each loaded source, oldest first, indexed by source name
+	versions map[string][]*TableVersion
+
+	// Validation rule results of each loaded source, indexed by source name
+	validations map[string][]ValidationResult
+	// Values that could not be converted to the type of their column, indexed by source name
+	coercions map[string][]CoercionFailure
+
+	// Schema and row count of each registered husk source, indexed by source name
+	husks map[string]*Husk
+
 	// Base directory for resolving relative paths
 	baseDir string
 
 	// File reader for reading files (injected by caller)
 	fileReader FileReader
+
+	// File opener for streaming files (injected by caller, optional)
+	fileOpener FileOpener
+
+	// Optional fault injection for resilience testing (nil = disabled)
+	faults *chaos.Injector
 }
 
 // NewManager creates a new data source manager.
@@ -79,6 +108,12 @@ func NewManager() *Manager {
 		tables:                make(map[string]*tables.DataTable),
 		registeredTables:      make(map[string]*tables.DataTable),
 		loaders:               make(map[string]DataSourceLoader),
+		readmes:               make(map[string]string),
+		loadedAt:              make(map[string]time.Time),
+		versions:              make(map[string][]*TableVersion),
+		validations:           make(map[string][]ValidationResult),
+		coercions:             make(map[string][]CoercionFailure),
+		husks:                 make(map[string]*Husk),
 	}
 }
 
@@ -98,6 +133,32 @@ func (m *Manager) SetFileReader(reader FileReader) {
 	m.fileReader = reader
 }
 
+// SetFileOpener sets the file opener used to stream files that are not loaded, e.g. the
+// files of husk sources. Without one, such files are read whole with the file reader,
+// which bounds them by the available memory.
+func (m *Manager) SetFileOpener(opener FileOpener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fileOpener = opener
+}
+
+// openerLocked returns the file opener, or one reading whole files with the file reader
+// if none is set. The caller must hold m.mu.
+func (m *Manager) openerLocked() FileOpener {
+	if m.fileOpener == nil && m.fileReader != nil {
+		return readerOpener(m.fileReader)
+	}
+	return m.fileOpener
+}
+
+// SetFaultInjector sets the injector of artificial latency and failures
+// before data sources are loaded (for resilience testing).
+func (m *Manager) SetFaultInjector(injector *chaos.Injector) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.faults = injector
+}
+
 // LoadConfigFromBytes parses and loads a DataSourcesConfig from raw bytes.
 // The baseDir is used to resolve relative paths in source configurations.
 // Annotations are loaded eagerly; source metadata is registered for lazy loading.
@@ -204,29 +265,51 @@ func (m *Manager) GetSource(name string) *DataSource {
 func (m *Manager) LoadData(sourceName string) (*tables.DataTable, error) {
 	// Check cache first (with read lock)
 	m.mu.RLock()
-	if table, ok := m.tables[sourceName]; ok {
-		m.mu.RUnlock()
+	table, ok := m.tables[sourceName]
+	m.mu.RUnlock()
+	if ok {
 		return table, nil
 	}
+	return m.load(sourceName)
+}
+
+// Reload loads a source again, even if it is cached, and caches the result as a new
+// version. If the reload fails, the previously loaded data stays cached.
+func (m *Manager) Reload(sourceName string) (*tables.DataTable, error) {
+	return m.load(sourceName)
+}
+
+// load loads a source from its loader and caches the result.
+func (m *Manager) load(sourceName string) (*tables.DataTable, error) {
+	m.mu.RLock()
 	source, ok := m.sources[sourceName]
 	if !ok {
 		m.mu.RUnlock()
-		return nil, fmt.Errorf("source %q not found", sourceName)
+		return nil, errs.New(errs.ErrUnknownTable, "source %q not found", sourceName)
 	}
 	annotations := m.annotations[source.GetAnnotationsId()]
 	loader, hasLoader := m.loaders[source.GetSourceType()]
 	baseDir := m.baseDir
 	fileReader := m.fileReader
+	faults := m.faults
 	m.mu.RUnlock()
 
+	if err := faults.Inject(chaos.Loader, sourceName); err != nil {
+		return nil, err
+	}
+
+	if source.GetHusk() {
+		return nil, errs.New(errs.ErrSourceUnavailable, "source %q is a husk, register it with GetHusk and run statements on it with Query", sourceName)
+	}
+
 	// Check if loader is registered
 	if !hasLoader {
-		return nil, fmt.Errorf("no loader registered for source type %q", source.GetSourceType())
+		return nil, errs.New(errs.ErrSourceUnavailable, "no loader registered for source type %q", source.GetSourceType())
 	}
 
 	// Check if file reader is set
 	if fileReader == nil {
-		return nil, fmt.Errorf("file reader not set; call SetFileReader before LoadData")
+		return nil, errs.New(errs.ErrSourceUnavailable, "file reader not set; call SetFileReader before LoadData")
 	}
 
 	// Prepare config with resolved paths
@@ -241,20 +324,74 @@ func (m *Manager) LoadData(sourceName string) (*tables.DataTable, error) {
 	// Step 2: Enrich schema with annotations
 	enrichedColumns := EnrichSchema(schema, annotations)
 
-	// Step 3: Load data with enriched schema
-	table, err := loader.Load(config, enrichedColumns, fileReader)
+	// Step 3: Load data with enriched schema, collecting the values that could not be
+	// converted if the loader reports them
+	var table *tables.DataTable
+	var coercions []CoercionFailure
+	if reporter, ok := loader.(CoercionReporter); ok {
+		table, coercions, err = reporter.LoadWithCoercionReport(config, enrichedColumns, fileReader)
+	} else {
+		table, err = loader.Load(config, enrichedColumns, fileReader)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to load source %q: %w", sourceName, err)
 	}
 
+	// Step 4: Apply annotation categories, value labels, identifier marks, aggregate formats and uncurated flags, which loaders don't need to know about
+	for _, enriched := range enrichedColumns {
+		if _, err := aggregates.ParseAggregateFormats(enriched.AggregateFormats); err != nil {
+			return nil, fmt.Errorf("invalid aggregate formats of column %q of source %q: %w", enriched.Name, sourceName, err)
+		}
+		if enriched.Category == "" && !enriched.Uncurated && len(enriched.ValueLabels) == 0 && enriched.Identifier == nil && len(enriched.AggregateFormats) == 0 {
+			continue
+		}
+		if col := table.GetColumn(enriched.Name); col != nil {
+			col.ColumnDef().SetCategory(enriched.Category)
+			col.ColumnDef().SetUncurated(enriched.Uncurated)
+			col.ColumnDef().SetValueLabels(enriched.ValueLabels)
+			col.ColumnDef().SetAggregateFormats(enriched.AggregateFormats)
+			if enriched.Identifier != nil {
+				col.ColumnDef().SetIdentifier(*enriched.Identifier)
+			}
+		}
+	}
+
+	// Step 5: Check validation rules across columns, optionally flagging violating rows
+	var validations []ValidationResult
+	if rules := annotations.GetValidations(); len(rules) > 0 {
+		var violated [][]string
+		validations, violated = ValidateRows(table, rules)
+		if name := annotations.GetViolationsColumn(); name != "" {
+			table.AddColumn(violationsColumn(name, violated))
+		}
+	}
+
+	// Columns are as fresh as the load, unless the loader assembled them from sources
+	// updated at different times and recorded when
+	now := time.Now()
+	setColumnsUpdatedAt(table, now)
+
 	// Cache the result
 	m.mu.Lock()
 	m.tables[sourceName] = table
+	m.validations[sourceName] = validations
+	m.coercions[sourceName] = coercions
+	m.loadedAt[sourceName] = now
+	m.recordVersion(sourceName, table, now)
 	m.mu.Unlock()
 
 	return table, nil
 }
 
+// setColumnsUpdatedAt sets the update time of the columns of a table that have none
+func setColumnsUpdatedAt(table *tables.DataTable, t time.Time) {
+	for _, name := range table.GetColumnNames() {
+		if def := table.GetColumn(name).ColumnDef(); def.UpdatedAt().IsZero() {
+			def.SetUpdatedAt(t)
+		}
+	}
+}
+
 // resolveConfigPaths resolves relative file paths in config to absolute paths.
 // It cleans paths to normalize traversals and prevent injection attacks.
 //
@@ -289,10 +426,16 @@ func (m *Manager) resolveConfigPaths(config map[string]string, baseDir string) m
 }
 
 // InvalidateCache removes a source from the cache, forcing reload on next access.
+// Retained versions are kept; the reload becomes a new version.
 func (m *Manager) InvalidateCache(sourceName string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	delete(m.tables, sourceName)
+	delete(m.readmes, sourceName)
+	delete(m.loadedAt, sourceName)
+	delete(m.validations, sourceName)
+	delete(m.coercions, sourceName)
+	delete(m.husks, sourceName)
 }
 
 // InvalidateAllCaches removes all sources from the cache.
@@ -300,6 +443,15 @@ func (m *Manager) InvalidateAllCaches() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.tables = make(map[string]*tables.DataTable)
+	m.readmes = make(map[string]string)
+	m.validations = make(map[string][]ValidationResult)
+	m.coercions = make(map[string][]CoercionFailure)
+	m.husks = make(map[string]*Husk)
+	for name := range m.loadedAt {
+		if _, registered := m.registeredTables[name]; !registered {
+			delete(m.loadedAt, name)
+		}
+	}
 }
 
 // IsLoaded returns whether data for a source is currently cached.
@@ -310,6 +462,31 @@ func (m *Manager) IsLoaded(sourceName string) bool {
 	return ok
 }
 
+// GetLoadTime returns when the data of a loaded source or registered table was loaded.
+// Returns the zero time if it is not loaded.
+func (m *Manager) GetLoadTime(name string) time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.loadedAt[name]
+}
+
+// GetValidationResults returns the results of the validation rules of a loaded source,
+// in rule order. Returns nil if the source is not loaded or has no rules.
+func (m *Manager) GetValidationResults(sourceName string) []ValidationResult {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.validations[sourceName]
+}
+
+// GetCoercionFailures returns, per column, the values of a loaded source that could
+// not be converted to the type of their column. Returns nil if the source is not
+// loaded, every value was converted, or its loader does not report coercions.
+func (m *Manager) GetCoercionFailures(sourceName string) []CoercionFailure {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.coercions[sourceName]
+}
+
 // GetLoadedSources returns names of all currently loaded (cached) sources.
 func (m *Manager) GetLoadedSources() []string {
 	m.mu.RLock()
@@ -457,6 +634,30 @@ func (m *Manager) ResolveDefaultURL(entityType, value string) string {
 	return m.ResolveURL(entityType, value, "")
 }
 
+// ResolveDefaultURLs resolves the default URL template for many values of an entity type.
+// The template is looked up once, so this is cheaper than calling ResolveDefaultURL per value.
+// Returns a map from value to URL, or nil if the entity type has no URL templates.
+func (m *Manager) ResolveDefaultURLs(entityType string, values []string) map[string]string {
+	m.mu.RLock()
+	et := m.entityTypes[entityType]
+	m.mu.RUnlock()
+
+	if et == nil || len(et.GetUrls()) == 0 {
+		return nil
+	}
+
+	template := getDefaultTemplate(et.GetUrls())
+	if template == "" {
+		return nil
+	}
+
+	result := make(map[string]string, len(values))
+	for _, value := range values {
+		result[value] = replacePlaceholders(template, value, entityType)
+	}
+	return result
+}
+
 // ResolvedURL represents a resolved URL with its name.
 type ResolvedURL struct {
 	Name string // Display name for the URL
@@ -474,6 +675,82 @@ func (m *Manager) GetPrimaryKeyEntityType(sourceName string) string {
 	return ""
 }
 
+// GetRowLinkColumn returns the column whose entity URL a row click navigates to for a source.
+// Returns empty string if the source doesn't exist or has no row link column defined.
+func (m *Manager) GetRowLinkColumn(sourceName string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if source, ok := m.sources[sourceName]; ok {
+		return source.GetRowLinkColumn()
+	}
+	return ""
+}
+
+// GetDefaultView returns the view a source's table opens with when no columns are requested.
+// Returns nil if the source doesn't exist or has no default view.
+func (m *Manager) GetDefaultView(sourceName string) *query.DefaultView {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	source, ok := m.sources[sourceName]
+	if !ok || source.GetDefaultView() == nil {
+		return nil
+	}
+	view := source.GetDefaultView()
+	return query.NewDefaultView(view.GetColumns(), view.GetGroupedColumns(), view.GetSort(), view.GetAggregates())
+}
+
+// GetColumnPresets returns the column presets of the annotations of a source.
+// Returns nil if the source doesn't exist or its annotations define no presets.
+func (m *Manager) GetColumnPresets(sourceName string) []query.ColumnPreset {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	source, ok := m.sources[sourceName]
+	if !ok {
+		return nil
+	}
+	var presets []query.ColumnPreset
+	for _, preset := range m.annotations[source.GetAnnotationsId()].GetPresets() {
+		presets = append(presets, query.ColumnPreset{Name: preset.GetName(), Columns: slices.Clone(preset.GetColumns())})
+	}
+	return presets
+}
+
+// GetReadme returns the Markdown documentation for a source: the inline readme if set,
+// otherwise the contents of readme_file. Files are read once and cached.
+// Returns empty string if the source doesn't exist or has no documentation.
+func (m *Manager) GetReadme(sourceName string) (string, error) {
+	m.mu.RLock()
+	source, ok := m.sources[sourceName]
+	readme, cached := m.readmes[sourceName]
+	baseDir := m.baseDir
+	fileReader := m.fileReader
+	m.mu.RUnlock()
+
+	if !ok {
+		return "", nil
+	}
+	if source.GetReadme() != "" {
+		return source.GetReadme(), nil
+	}
+	if source.GetReadmeFile() == "" || cached {
+		return readme, nil
+	}
+	if fileReader == nil {
+		return "", errs.New(errs.ErrSourceUnavailable, "file reader not set; call SetFileReader before GetReadme")
+	}
+
+	path := m.resolveConfigPaths(map[string]string{"file_path": source.GetReadmeFile()}, baseDir)["file_path"]
+	data, err := fileReader(path)
+	if err != nil {
+		return "", errs.Wrap(errs.ErrSourceUnavailable, err, "failed to read readme for source %q", sourceName)
+	}
+
+	m.mu.Lock()
+	m.readmes[sourceName] = string(data)
+	m.mu.Unlock()
+	return string(data), nil
+}
+
 // GetEntityTypeDescription returns the description for an entity type.
 // Returns empty string if the entity type doesn't exist or has no description.
 func (m *Manager) GetEntityTypeDescription(entityType string) string {
@@ -514,6 +791,17 @@ func (m *Manager) GetAllHierarchies() []*Hierarchy {
 	return result
 }
 
+// GetHierarchyLevels returns the entity types of each registered hierarchy, from root to
+// leaf, in definition order.
+func (m *Manager) GetHierarchyLevels() [][]string {
+	hierarchies := m.GetAllHierarchies()
+	levels := make([][]string, len(hierarchies))
+	for i, h := range hierarchies {
+		levels[i] = h.GetLevels()
+	}
+	return levels
+}
+
 // GetAllURLs returns all resolved URLs for a given entity type and value.
 // Returns an empty slice if the entity type has no URL templates.
 func (m *Manager) GetAllURLs(entityType, value string) []ResolvedURL {
@@ -587,7 +875,12 @@ func indexOf(s, substr string) int {
 func (m *Manager) RegisterTable(name string, table *tables.DataTable) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	now := time.Now()
+	if table != nil {
+		setColumnsUpdatedAt(table, now)
+	}
 	m.registeredTables[name] = table
+	m.loadedAt[name] = now
 }
 
 // HierarchyAncestorColumnPrefix is the prefix used for hierarchy ancestor column names
@@ -865,6 +1158,7 @@ func (m *Manager) buildHierarchyJoinedColumn(
 		displayName,
 		ancestorEntityType,
 	)
+	colDef.SetUpdatedAt(targetColumn.ColumnDef().UpdatedAt())
 
 	var joiner columns.IJoiner
 	if len(joiners) == 1 {
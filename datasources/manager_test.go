@@ -7,10 +7,21 @@ Copyright 2024 The Taxinomia Authors
 package datasources
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/taxinomia/core/chaos"
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/tables"
 )
 
 func TestManagerLoadConfig(t *testing.T) {
@@ -147,6 +158,140 @@ func TestManagerFindJoinableColumns(t *testing.T) {
 	}
 }
 
+func TestManagerResolveDefaultURLs(t *testing.T) {
+	manager := NewManager()
+	manager.AddEntityType(&EntityTypeDefinition{
+		Name: "user_id",
+		Urls: []*URLTemplate{
+			{Name: "Profile", Template: "/users/{value}"},
+			{Name: "Orders", Template: "/orders?user={value}", IsDefault: true},
+		},
+	})
+
+	urls := manager.ResolveDefaultURLs("user_id", []string{"u1", "u2"})
+	if len(urls) != 2 {
+		t.Fatalf("expected 2 URLs, got %d", len(urls))
+	}
+	for _, value := range []string{"u1", "u2"} {
+		if urls[value] != manager.ResolveDefaultURL("user_id", value) {
+			t.Errorf("batch URL for %s = %q, want %q", value, urls[value], manager.ResolveDefaultURL("user_id", value))
+		}
+	}
+
+	if urls := manager.ResolveDefaultURLs("unknown", []string{"u1"}); urls != nil {
+		t.Errorf("expected nil for unknown entity type, got %v", urls)
+	}
+}
+
+func TestManagerGetReadme(t *testing.T) {
+	manager := NewManager()
+	manager.SetBaseDir("/config")
+	reads := 0
+	manager.SetFileReader(func(path string) ([]byte, error) {
+		reads++
+		if path != "/config/docs/orders.md" {
+			return nil, fmt.Errorf("unexpected path %q", path)
+		}
+		return []byte("# Orders"), nil
+	})
+	manager.AddSource(&DataSource{Name: "inline", Readme: "Inline *docs*", ReadmeFile: "ignored.md"})
+	manager.AddSource(&DataSource{Name: "file", ReadmeFile: "docs/orders.md"})
+	manager.AddSource(&DataSource{Name: "missing", ReadmeFile: "missing.md"})
+	manager.AddSource(&DataSource{Name: "none"})
+
+	tests := []struct {
+		source  string
+		want    string
+		wantErr bool
+	}{
+		{source: "inline", want: "Inline *docs*"},
+		{source: "file", want: "# Orders"},
+		{source: "file", want: "# Orders"},
+		{source: "missing", wantErr: true},
+		{source: "none", want: ""},
+		{source: "unknown", want: ""},
+	}
+	for _, tt := range tests {
+		got, err := manager.GetReadme(tt.source)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("GetReadme(%q) error = %v, wantErr %v", tt.source, err, tt.wantErr)
+		}
+		if tt.wantErr && !errors.Is(err, errs.ErrSourceUnavailable) {
+			t.Errorf("GetReadme(%q) error = %v, want ErrSourceUnavailable", tt.source, err)
+		}
+		if got != tt.want {
+			t.Errorf("GetReadme(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+	// The readme file is cached after the first read; the missing file is retried
+	if reads != 2 {
+		t.Errorf("expected 2 file reads, got %d", reads)
+	}
+}
+
+func TestManagerGetLoadTime(t *testing.T) {
+	manager := NewManager()
+	manager.RegisterLoader(NewCsvLoader())
+	manager.SetFileReader(func(string) ([]byte, error) { return []byte("a\n1\n"), nil })
+	manager.AddSource(&DataSource{Name: "src", SourceType: "csv", Config: map[string]string{"file_path": "a.csv"}})
+	manager.RegisterTable("registered", nil)
+
+	if !manager.GetLoadTime("src").IsZero() {
+		t.Fatalf("expected no load time before loading")
+	}
+	before := time.Now()
+	if _, err := manager.LoadData("src"); err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+	if loadedAt := manager.GetLoadTime("src"); loadedAt.Before(before) {
+		t.Errorf("expected load time after %v, got %v", before, loadedAt)
+	}
+	table, _ := manager.LoadData("src")
+	if updatedAt := table.GetColumn("a").ColumnDef().UpdatedAt(); !updatedAt.Equal(manager.GetLoadTime("src")) {
+		t.Errorf("column update time = %v, want the load time %v", updatedAt, manager.GetLoadTime("src"))
+	}
+
+	manager.InvalidateAllCaches()
+	if !manager.GetLoadTime("src").IsZero() {
+		t.Errorf("expected load time to be cleared with the cache")
+	}
+	if manager.GetLoadTime("registered").IsZero() {
+		t.Errorf("expected registered table to keep its load time")
+	}
+}
+
+func TestManagerUnknownSource(t *testing.T) {
+	manager := NewManager()
+	manager.RegisterLoader(NewCsvLoader())
+	manager.SetFileReader(func(string) ([]byte, error) { return []byte("a\n1\n"), nil })
+
+	if _, err := manager.LoadData("unknown"); !errors.Is(err, errs.ErrUnknownTable) {
+		t.Errorf("expected LoadData of an unknown source to fail with ErrUnknownTable, got %v", err)
+	}
+	if _, err := manager.GetHusk("unknown"); !errors.Is(err, errs.ErrUnknownTable) {
+		t.Errorf("expected GetHusk of an unknown source to fail with ErrUnknownTable, got %v", err)
+	}
+}
+
+func TestManagerInjectedLoaderFailure(t *testing.T) {
+	manager := NewManager()
+	manager.RegisterLoader(NewCsvLoader())
+	manager.SetFileReader(func(string) ([]byte, error) { return []byte("a\n1\n"), nil })
+	manager.AddSource(&DataSource{Name: "src", SourceType: "csv", Config: map[string]string{"file_path": "a.csv"}})
+
+	faults := chaos.NewInjector()
+	faults.Set(chaos.Loader, chaos.Fault{FailureRate: 1})
+	manager.SetFaultInjector(faults)
+	if _, err := manager.LoadData("src"); !errors.Is(err, errs.ErrSourceUnavailable) {
+		t.Fatalf("expected injected loader failure, got %v", err)
+	}
+
+	faults.Clear(chaos.Loader)
+	if _, err := manager.LoadData("src"); err != nil {
+		t.Errorf("expected load to succeed once the fault is cleared, got %v", err)
+	}
+}
+
 func TestCsvLoader(t *testing.T) {
 	// Create a temporary CSV file
 	tmpDir := t.TempDir()
@@ -330,3 +475,291 @@ Charlie,35,92.3,true`
 		t.Errorf("expected age '30', got %q", val)
 	}
 }
+
+func TestCsvLoaderTypedUncuratedColumns(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "uncurated.csv")
+
+	csvContent := `name,age,code,notes
+Alice,30,12,
+Bob,25,n/a,
+Charlie,35,7,`
+
+	if err := os.WriteFile(csvPath, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	manager := NewManager()
+	manager.RegisterLoader(NewCsvLoaderTyped())
+	manager.SetFileReader(os.ReadFile)
+	manager.AddSource(&DataSource{
+		Name:       "uncurated",
+		SourceType: "csv_typed",
+		Config:     map[string]string{"file_path": csvPath},
+	})
+
+	table, err := manager.LoadData("uncurated")
+	if err != nil {
+		t.Fatalf("failed to load CSV: %v", err)
+	}
+
+	// Partly numeric and empty columns are kept as uncurated strings
+	for _, name := range []string{"code", "notes"} {
+		col, ok := table.GetColumn(name).(*columns.StringColumn)
+		if !ok {
+			t.Fatalf("expected %s to be a string column, got %T", name, table.GetColumn(name))
+		}
+		if !col.ColumnDef().IsUncurated() {
+			t.Errorf("expected %s to be uncurated", name)
+		}
+	}
+	if val, _ := table.GetColumn("code").GetString(1); val != "n/a" {
+		t.Errorf("expected the non-numeric code to be passed through, got %q", val)
+	}
+
+	// Unambiguous columns keep their inferred type
+	for _, name := range []string{"name", "age"} {
+		if table.GetColumn(name).ColumnDef().IsUncurated() {
+			t.Errorf("expected %s not to be uncurated", name)
+		}
+	}
+	if _, ok := table.GetColumn("age").(*columns.Int64Column); !ok {
+		t.Errorf("expected age to be an int64 column, got %T", table.GetColumn("age"))
+	}
+}
+
+func TestManagerValidationRules(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "quota.csv")
+
+	csvContent := `name,used,limit
+alpha,5,10
+beta,12,10
+gamma,3,n/a`
+
+	if err := os.WriteFile(csvPath, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	manager := NewManager()
+	manager.RegisterLoader(NewCsvLoaderTyped())
+	manager.SetFileReader(os.ReadFile)
+	manager.AddAnnotations(&ColumnAnnotations{
+		AnnotationsId: "quota",
+		Validations: []*ValidationRule{
+			{Name: "within_limit", Expression: "used <= limit"},
+			{Expression: "used > 0"},
+			{Name: "broken", Expression: "used <= quota"},
+		},
+		ViolationsColumn: "violations",
+	})
+	manager.AddSource(&DataSource{
+		Name:          "quota",
+		AnnotationsId: "quota",
+		SourceType:    "csv_typed",
+		Config:        map[string]string{"file_path": csvPath},
+	})
+
+	table, err := manager.LoadData("quota")
+	if err != nil {
+		t.Fatalf("failed to load CSV: %v", err)
+	}
+
+	want := []ValidationResult{
+		{Rule: "within_limit", Expression: "used <= limit", Rows: 3, Violations: 1, Errors: 1},
+		{Rule: "used > 0", Expression: "used > 0", Rows: 3},
+		{Rule: "broken", Expression: "used <= quota", Err: "unknown columns: quota"},
+	}
+	if got := manager.GetValidationResults("quota"); !reflect.DeepEqual(got, want) {
+		t.Errorf("validation results = %+v, want %+v", got, want)
+	}
+
+	violations := table.GetColumn("violations")
+	if violations == nil {
+		t.Fatal("violations column not found")
+	}
+	for row, want := range []string{"", "within_limit", ""} {
+		if got, _ := violations.GetString(uint32(row)); got != want {
+			t.Errorf("row %d violations = %q, want %q", row, got, want)
+		}
+	}
+
+	manager.InvalidateCache("quota")
+	if got := manager.GetValidationResults("quota"); got != nil {
+		t.Errorf("expected no validation results after invalidation, got %+v", got)
+	}
+}
+
+func TestManagerCoercionPolicies(t *testing.T) {
+	// Types are inferred from the first 100 rows, so the values after them may
+	// not parse
+	var csvContent strings.Builder
+	csvContent.WriteString("name,count,size\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&csvContent, "row%d,%d,%d\n", i, i, i)
+	}
+	csvContent.WriteString("bad1,n/a,1\nbad2,,2\nbad3,12x,3\nbad4,n/a,4\n")
+
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "counts.csv")
+	if err := os.WriteFile(csvPath, []byte(csvContent.String()), 0644); err != nil {
+		t.Fatalf("failed to write test CSV: %v", err)
+	}
+
+	load := func(policy string) (*Manager, *tables.DataTable, error) {
+		manager := NewManager()
+		manager.RegisterLoader(NewCsvLoaderTyped())
+		manager.SetFileReader(os.ReadFile)
+		manager.AddSource(&DataSource{
+			Name:       "counts",
+			SourceType: "csv_typed",
+			Config:     map[string]string{"file_path": csvPath, CoercionPolicyKey: policy},
+		})
+		table, err := manager.LoadData("counts")
+		return manager, table, err
+	}
+
+	want := []CoercionFailure{
+		{Column: "count", Type: TypeInt64, Rows: 104, Count: 3, Examples: []string{"n/a", "12x"}},
+	}
+
+	// The default policy loads the values as zero values
+	manager, table, err := load("")
+	if err != nil {
+		t.Fatalf("failed to load CSV: %v", err)
+	}
+	if got := manager.GetCoercionFailures("counts"); !reflect.DeepEqual(got, want) {
+		t.Errorf("coercion failures = %+v, want %+v", got, want)
+	}
+	if got, _ := table.GetColumn("count").GetString(100); got != "0" {
+		t.Errorf("expected the failed value to be loaded as 0, got %q", got)
+	}
+	if table.GetColumn("count"+ShadowColumnSuffix) != nil {
+		t.Error("expected no shadow column under the zero policy")
+	}
+
+	// Aggregates leave the failed rows out, and statements return them as NULL
+	for sql, want := range map[string][][]any{
+		"SELECT COUNT(*), COUNT(count), SUM(count) FROM counts":   {{int64(104), int64(101), 4950.0}},
+		"SELECT count FROM counts WHERE name IN ('bad1', 'bad2')": {{nil}, {int64(0)}},
+	} {
+		result, err := manager.Query(sql)
+		if err != nil {
+			t.Fatalf("Query(%q): %v", sql, err)
+		}
+		if !reflect.DeepEqual(result.Rows, want) {
+			t.Errorf("Query(%q) = %v, want %v", sql, result.Rows, want)
+		}
+	}
+
+	// The shadow policy keeps the raw values of the failed rows
+	manager, table, err = load("shadow")
+	if err != nil {
+		t.Fatalf("failed to load CSV: %v", err)
+	}
+	if got := manager.GetCoercionFailures("counts"); !reflect.DeepEqual(got, want) {
+		t.Errorf("coercion failures = %+v, want %+v", got, want)
+	}
+	shadow := table.GetColumn("count" + ShadowColumnSuffix)
+	if shadow == nil {
+		t.Fatal("shadow column not found")
+	}
+	for row, want := range map[uint32]string{0: "", 100: "n/a", 101: "", 102: "12x"} {
+		if got, _ := shadow.GetString(row); got != want {
+			t.Errorf("row %d shadow value = %q, want %q", row, got, want)
+		}
+	}
+	if table.GetColumn("size"+ShadowColumnSuffix) != nil {
+		t.Error("expected no shadow column for a column without failed values")
+	}
+
+	// The fail policy fails the load
+	if _, _, err := load("fail"); !errors.Is(err, errs.ErrTypeMismatch) {
+		t.Errorf("expected a type mismatch error, got %v", err)
+	}
+	if _, _, err := load("drop"); err == nil {
+		t.Error("expected an unknown policy to fail the load")
+	}
+
+	manager.InvalidateCache("counts")
+	if got := manager.GetCoercionFailures("counts"); got != nil {
+		t.Errorf("expected no coercion failures after invalidation, got %+v", got)
+	}
+}
+
+func TestEnrichSchemaKeepsAnnotatedAmbiguousColumns(t *testing.T) {
+	schema := &TableSchema{Columns: []*ColumnSchema{
+		{Name: "code", Type: TypeString, Ambiguous: true},
+		{Name: "score", Type: TypeInt64, Ambiguous: true},
+	}}
+	annotations := &ColumnAnnotations{Columns: []*ColumnAnnotation{{Name: "score", DisplayName: "Score"}}}
+
+	enriched := EnrichSchema(schema, annotations)
+	if !enriched[0].Uncurated || enriched[0].Type != TypeString {
+		t.Errorf("expected code to be an uncurated string column, got %+v", enriched[0])
+	}
+	if enriched[1].Uncurated || enriched[1].Type != TypeInt64 {
+		t.Errorf("expected annotated score to keep its type, got %+v", enriched[1])
+	}
+}
+
+func TestEnrichSchemaValueLabels(t *testing.T) {
+	schema := &TableSchema{Columns: []*ColumnSchema{{Name: "exit_code", Type: TypeInt64}}}
+	annotations := &ColumnAnnotations{Columns: []*ColumnAnnotation{
+		{Name: "exit_code", ValueLabels: map[string]string{"137": "OOMKilled"}},
+	}}
+
+	enriched := EnrichSchema(schema, annotations)
+	if got := enriched[0].ValueLabels["137"]; got != "OOMKilled" {
+		t.Errorf("expected the value label of 137 to be OOMKilled, got %q", got)
+	}
+}
+
+func TestManagerGetColumnPresets(t *testing.T) {
+	manager := NewManager()
+	manager.AddAnnotations(&ColumnAnnotations{
+		AnnotationsId: "jobs",
+		Presets: []*ColumnPreset{
+			{Name: "capacity", Columns: []string{"cpu", "memory"}},
+			{Name: "billing", Columns: []string{"cost"}},
+		},
+	})
+	manager.AddSource(&DataSource{Name: "jobs", AnnotationsId: "jobs"})
+	manager.AddSource(&DataSource{Name: "plain"})
+
+	presets := manager.GetColumnPresets("jobs")
+	if len(presets) != 2 || presets[0].Name != "capacity" || !slices.Equal(presets[0].Columns, []string{"cpu", "memory"}) {
+		t.Errorf("expected the capacity and billing presets, got %v", presets)
+	}
+	if manager.GetColumnPresets("plain") != nil || manager.GetColumnPresets("unknown") != nil {
+		t.Error("expected no presets for sources without any")
+	}
+}
+
+func TestManagerGetDefaultView(t *testing.T) {
+	manager := NewManager()
+	manager.AddSource(&DataSource{
+		Name: "orders",
+		DefaultView: &DefaultView{
+			Columns:        []string{"status", "amount"},
+			GroupedColumns: []string{"status"},
+			Sort:           []string{"-amount", "bogus"},
+			Aggregates:     map[string]string{"amount": "sum,median,avg"},
+		},
+	})
+	manager.AddSource(&DataSource{Name: "plain"})
+
+	view := manager.GetDefaultView("orders")
+	if view == nil {
+		t.Fatal("expected a default view for orders")
+	}
+	if len(view.SortOrder) != 1 || view.SortOrder[0].Name != "amount" || !view.SortOrder[0].Descending {
+		t.Errorf("expected sort by descending amount, got %v", view.SortOrder)
+	}
+	if aggs := view.AggregateSettings["amount"]; len(aggs) != 2 {
+		t.Errorf("expected unknown aggregates to be dropped, got %v", aggs)
+	}
+	if manager.GetDefaultView("plain") != nil || manager.GetDefaultView("unknown") != nil {
+		t.Error("expected no default view for sources without one")
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/errs"
 	"github.com/google/taxinomia/core/protoloader"
 	"github.com/google/taxinomia/core/tables"
 	"google.golang.org/protobuf/proto"
@@ -155,7 +156,7 @@ func (l *ProtoLoader) Load(config map[string]string, enrichedColumns []*Enriched
 	// Read proto file
 	data, err := readFile(protoFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read proto file: %w", err)
+		return nil, errs.Wrap(errs.ErrSourceUnavailable, err, "failed to read proto file")
 	}
 
 	// Parse the message
@@ -320,7 +321,7 @@ func (l *ProtoLoader) loadDescriptorSetWithReader(path string, readFile FileRead
 
 	data, err := readFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to read descriptor set: %w", err)
+		return errs.Wrap(errs.ErrSourceUnavailable, err, "failed to read descriptor set")
 	}
 
 	if err := l.loadDescriptorSetFromBytes(data); err != nil {
@@ -0,0 +1,138 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasources
+
+import (
+	"log"
+
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/sqlquery"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// AggregateLoader is implemented by loaders of remote sources (SQL databases, BigQuery,
+// other Taxinomia servers) that can group and aggregate rows at the source, so that
+// aggregate queries on a source don't need its rows to be loaded.
+type AggregateLoader interface {
+	DataSourceLoader
+
+	// LoadAggregates runs a grouped or aggregated statement at the source and returns its
+	// rows: one value per select item, of the Go type of the item's sqlquery.Kind, or nil
+	// for a missing value. The columns are the enriched schema of the source the statement
+	// was resolved against. The statement has no ORDER BY or LIMIT clause; they are applied
+	// to the returned rows. Returning an error has the statement executed on the loaded
+	// rows of the source instead, e.g. for aggregates the source can't compute exactly.
+	LoadAggregates(config map[string]string, stmt *sqlquery.Statement, columns []*EnrichedColumn, openFile FileOpener) ([][]any, error)
+}
+
+// Query runs a SQL statement (see package sqlquery) on the source it names. While the
+// data of the source isn't loaded, grouped and aggregated statements are pushed down to
+// its loader if it implements AggregateLoader. Other statements, and statements the
+// loader fails to run, are executed on the data of the source, which is loaded if needed.
+// Statements on husk sources are always run at the source (see Husk).
+func (m *Manager) Query(sql string) (*sqlquery.Result, error) {
+	stmt, err := sqlquery.Parse(sql)
+	if err != nil {
+		return nil, err
+	}
+	if m.GetSource(stmt.Table).GetHusk() {
+		return m.queryHusk(stmt)
+	}
+	if result, ok := m.pushDown(stmt); ok {
+		return result, nil
+	}
+	table, err := m.LoadData(stmt.Table)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := sqlquery.ResolveTable(table, stmt)
+	if err != nil {
+		return nil, err
+	}
+	return plan.Execute()
+}
+
+// pushDown runs a statement at the source it names. It returns false if the statement has
+// to be executed on the loaded data of the source instead.
+func (m *Manager) pushDown(stmt *sqlquery.Statement) (*sqlquery.Result, bool) {
+	m.mu.RLock()
+	_, loaded := m.tables[stmt.Table]
+	source := m.sources[stmt.Table]
+	loader, canAggregate := m.loaders[source.GetSourceType()].(AggregateLoader)
+	annotations := m.annotations[source.GetAnnotationsId()]
+	baseDir := m.baseDir
+	fileReader := m.fileReader
+	openFile := m.openerLocked()
+	m.mu.RUnlock()
+	if loaded || !canAggregate || fileReader == nil {
+		return nil, false
+	}
+
+	// Resolve the statement against the schema of the source, without its rows
+	config := m.resolveConfigPaths(source.GetConfig(), baseDir)
+	schema, err := loader.DiscoverSchema(config, fileReader)
+	if err != nil {
+		return nil, false
+	}
+	enrichedColumns := EnrichSchema(schema, annotations)
+	plan, err := sqlquery.ResolveTable(schemaTable(enrichedColumns), stmt)
+	if err != nil || !plan.Pushable() {
+		return nil, false
+	}
+
+	rows, err := loader.LoadAggregates(config, plan.PushdownStatement(), enrichedColumns, openFile)
+	if err != nil {
+		log.Printf("Executing the query on %s locally, the source failed to run it: %v", stmt.Table, err)
+		return nil, false
+	}
+	result, err := plan.ExecuteRows(rows)
+	if err != nil {
+		log.Printf("Executing the query on %s locally, the source returned malformed rows: %v", stmt.Table, err)
+		return nil, false
+	}
+	return result, true
+}
+
+// schemaTable returns a table without rows with the columns of a schema, to resolve
+// statements against
+func schemaTable(enrichedColumns []*EnrichedColumn) *tables.DataTable {
+	table := tables.NewDataTable()
+	for _, enriched := range enrichedColumns {
+		colDef := columns.NewColumnDef(enriched.Name, enriched.DisplayName, enriched.EntityType)
+		switch enriched.Type {
+		case TypeInt64:
+			table.AddColumn(columns.NewInt64Column(colDef))
+		case TypeUint32:
+			table.AddColumn(columns.NewUint32Column(colDef))
+		case TypeUint64:
+			table.AddColumn(columns.NewUint64Column(colDef))
+		case TypeFloat64:
+			table.AddColumn(columns.NewFloat64Column(colDef))
+		case TypeBool:
+			table.AddColumn(columns.NewBoolColumn(colDef))
+		case TypeDatetime:
+			table.AddColumn(columns.NewDatetimeColumn(colDef))
+		case TypeDuration:
+			table.AddColumn(columns.NewDurationColumn(colDef))
+		default:
+			table.AddColumn(columns.NewStringColumn(colDef))
+		}
+	}
+	return table
+}
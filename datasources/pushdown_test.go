@@ -0,0 +1,160 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasources
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/taxinomia/core/sqlquery"
+)
+
+// aggregatingLoader is a typed CSV loader whose source computes aggregates
+type aggregatingLoader struct {
+	*CsvLoaderTyped
+	rows  [][]any // Rows returned for pushed down statements
+	err   error   // Error returned for pushed down statements
+	stmts []*sqlquery.Statement
+}
+
+func (l *aggregatingLoader) SourceType() string {
+	return "remote"
+}
+
+func (l *aggregatingLoader) LoadAggregates(config map[string]string, stmt *sqlquery.Statement, columns []*EnrichedColumn, openFile FileOpener) ([][]any, error) {
+	l.stmts = append(l.stmts, stmt)
+	return l.rows, l.err
+}
+
+func newPushdownManager(loader *aggregatingLoader) *Manager {
+	manager := NewManager()
+	manager.RegisterLoader(loader)
+	manager.SetFileReader(func(string) ([]byte, error) {
+		return []byte("region,amount\nnorth,100\nsouth,75\nnorth,250\n"), nil
+	})
+	manager.AddSource(&DataSource{Name: "orders", SourceType: "remote", Config: map[string]string{"file_path": "orders.csv"}})
+	return manager
+}
+
+func TestManagerQueryPushdown(t *testing.T) {
+	loader := &aggregatingLoader{CsvLoaderTyped: NewCsvLoaderTyped(), rows: [][]any{{"south", int64(75)}, {"north", int64(350)}}}
+	manager := newPushdownManager(loader)
+
+	result, err := manager.Query("SELECT region, SUM(amount) AS total FROM orders GROUP BY region ORDER BY total DESC")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if want := [][]any{{"north", 350.0}, {"south", 75.0}}; !result.PushedDown || !reflect.DeepEqual(result.Rows, want) {
+		t.Errorf("expected the rows of the source %v, got %v (pushed down: %v)", want, result.Rows, result.PushedDown)
+	}
+	if len(loader.stmts) != 1 || loader.stmts[0].OrderBy != nil {
+		t.Errorf("expected the statement to be pushed down without ORDER BY, got %+v", loader.stmts)
+	}
+	if manager.IsLoaded("orders") {
+		t.Error("expected the rows of the source not to be loaded")
+	}
+
+	// Statements that don't aggregate load the rows of the source
+	result, err = manager.Query("SELECT region FROM orders WHERE region = 'south'")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if result.PushedDown || len(result.Rows) != 1 || !manager.IsLoaded("orders") {
+		t.Errorf("expected the statement to run on the loaded rows, got %v", result.Rows)
+	}
+
+	// Once loaded, aggregates are computed from the loaded rows
+	result, err = manager.Query("SELECT region, SUM(amount) AS total FROM orders GROUP BY region ORDER BY total DESC")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if result.PushedDown || len(loader.stmts) != 1 {
+		t.Errorf("expected the statement not to be pushed down once the rows are loaded")
+	}
+}
+
+func TestManagerQueryPushdownFallback(t *testing.T) {
+	for name, loader := range map[string]*aggregatingLoader{
+		"failing":   {CsvLoaderTyped: NewCsvLoaderTyped(), err: errors.New("unsupported aggregate")},
+		"malformed": {CsvLoaderTyped: NewCsvLoaderTyped(), rows: [][]any{{"north", "350"}}},
+	} {
+		manager := newPushdownManager(loader)
+		result, err := manager.Query("SELECT region, SUM(amount) AS total FROM orders GROUP BY region ORDER BY total DESC")
+		if err != nil {
+			t.Fatalf("%s: Query: %v", name, err)
+		}
+		if want := [][]any{{"north", 350.0}, {"south", 75.0}}; result.PushedDown || !reflect.DeepEqual(result.Rows, want) {
+			t.Errorf("%s: expected the rows computed locally %v, got %v", name, want, result.Rows)
+		}
+	}
+}
+
+func TestCsvLoaderTypedLoadAggregates(t *testing.T) {
+	saved := csvChunkRows
+	defer func() { csvChunkRows = saved }()
+	csvChunkRows = 2
+
+	config := map[string]string{"file_path": "orders.csv"}
+	readFile := func(string) ([]byte, error) {
+		return []byte("region,amount,name\nnorth,100,a\nsouth,75,b\nnorth,250,a\nwest,,c\nnorth,5,d\n"), nil
+	}
+	loader := NewCsvLoaderTyped()
+	schema, err := loader.DiscoverSchema(config, readFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enrichedColumns := EnrichSchema(schema, nil)
+	table, err := loader.Load(config, enrichedColumns, readFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The aggregates merged from the chunks are those of the loaded rows
+	for _, sql := range []string{
+		"SELECT region, COUNT(*), COUNT(amount), SUM(amount), AVG(amount), MIN(amount), MAX(amount), COUNT(DISTINCT name), MAX(name) FROM orders GROUP BY region ORDER BY region",
+		"SELECT COUNT(*) AS n, AVG(amount), COUNT(DISTINCT region) FROM orders WHERE region IN ('north', 'west')",
+		"SELECT COUNT(*), SUM(amount) FROM orders WHERE region = 'east'",
+		"SELECT region, name, COUNT(*) FROM orders WHERE amount = '100' GROUP BY region, name ORDER BY 1",
+	} {
+		stmt, err := sqlquery.Parse(sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plan, err := sqlquery.ResolveTable(table, stmt)
+		if err != nil {
+			t.Fatalf("%s: %v", sql, err)
+		}
+		want, err := plan.Execute()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := loader.LoadAggregates(config, plan.PushdownStatement(), enrichedColumns, readerOpener(readFile))
+		if err != nil {
+			t.Fatalf("%s: LoadAggregates: %v", sql, err)
+		}
+		got, err := plan.ExecuteRows(rows)
+		if err != nil {
+			t.Fatalf("%s: ExecuteRows: %v", sql, err)
+		}
+		if !reflect.DeepEqual(got.Rows, want.Rows) {
+			t.Errorf("%s:\n got %v\nwant %v", sql, got.Rows, want.Rows)
+		}
+	}
+}
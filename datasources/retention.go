@@ -0,0 +1,142 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasources
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// TableVersion is one loaded version of a data source.
+type TableVersion struct {
+	Version  int       // Sequence number, starting at 1 for the first load
+	LoadedAt time.Time // When this version was loaded
+	Table    *tables.DataTable
+}
+
+// recordVersion adds a newly loaded table as the latest version of a source
+// and prunes versions that fall outside its retention policy.
+// Must be called with m.mu held for writing.
+func (m *Manager) recordVersion(sourceName string, table *tables.DataTable, loadedAt time.Time) {
+	versions := m.versions[sourceName]
+	next := 1
+	if len(versions) > 0 {
+		next = versions[len(versions)-1].Version + 1
+	}
+	versions = append(versions, &TableVersion{Version: next, LoadedAt: loadedAt, Table: table})
+	m.versions[sourceName] = pruneVersions(versions, m.sources[sourceName].GetRetention(), loadedAt)
+}
+
+// pruneVersions drops versions (ordered oldest first) that exceed the retention
+// policy at time now. The latest version is always kept; without a policy only
+// the latest version is kept.
+func pruneVersions(versions []*TableVersion, policy *RetentionPolicy, now time.Time) []*TableVersion {
+	if len(versions) == 0 {
+		return versions
+	}
+	start := len(versions) - 1
+	if policy != nil {
+		start = 0
+	}
+	if maxVersions := int(policy.GetMaxVersions()); maxVersions > 0 && len(versions)-start > maxVersions {
+		start = len(versions) - maxVersions
+	}
+	if days := policy.GetMaxAgeDays(); days > 0 {
+		cutoff := now.Add(-time.Duration(days) * 24 * time.Hour)
+		for start < len(versions)-1 && versions[start].LoadedAt.Before(cutoff) {
+			start++
+		}
+	}
+	if start == 0 {
+		return versions
+	}
+	// Copy so the pruned tables are no longer referenced by the backing array
+	return slices.Clone(versions[start:])
+}
+
+// ListVersions returns the retained versions of a source, oldest first.
+// Versions that have aged out of the retention policy are pruned first.
+// Returns an empty slice if the source has never been loaded.
+func (m *Manager) ListVersions(sourceName string) []TableVersion {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	versions := pruneVersions(m.versions[sourceName], m.sources[sourceName].GetRetention(), time.Now())
+	m.versions[sourceName] = versions
+
+	result := make([]TableVersion, len(versions))
+	for i, v := range versions {
+		result[i] = *v
+	}
+	return result
+}
+
+// VersionAsOf returns the retained version of a source that was current at the
+// given time: the latest version loaded at or before it. Versions that have aged
+// out of the retention policy are pruned first.
+func (m *Manager) VersionAsOf(sourceName string, at time.Time) (TableVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	versions := pruneVersions(m.versions[sourceName], m.sources[sourceName].GetRetention(), time.Now())
+	m.versions[sourceName] = versions
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !versions[i].LoadedAt.After(at) {
+			return *versions[i], nil
+		}
+	}
+	return TableVersion{}, errs.New(errs.ErrSourceUnavailable, "no retained version of source %q as of %s", sourceName, at.Format(time.RFC3339))
+}
+
+// RestoreVersion makes a retained version the current data of a source and
+// returns its table. Callers that registered the previous table elsewhere (for
+// example in a DataModel) must register the returned table in its place.
+func (m *Manager) RestoreVersion(sourceName string, version int) (*tables.DataTable, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	versions := pruneVersions(m.versions[sourceName], m.sources[sourceName].GetRetention(), time.Now())
+	m.versions[sourceName] = versions
+	for _, v := range versions {
+		if v.Version == version {
+			m.tables[sourceName] = v.Table
+			m.loadedAt[sourceName] = v.LoadedAt
+			return v.Table, nil
+		}
+	}
+	return nil, errs.New(errs.ErrSourceUnavailable, "version %d of source %q is not retained", version, sourceName)
+}
+
+// CurrentVersion returns the version number of the current data of a source,
+// or 0 if the source has not been loaded.
+func (m *Manager) CurrentVersion(sourceName string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	current := m.tables[sourceName]
+	for _, v := range m.versions[sourceName] {
+		if v.Table == current {
+			return v.Version
+		}
+	}
+	return 0
+}
@@ -0,0 +1,180 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasources
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/taxinomia/core/errs"
+)
+
+func TestPruneVersions(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	versions := make([]*TableVersion, 5)
+	for i := range versions {
+		// Versions 1..5 loaded 4, 3, 2, 1, and 0 days ago
+		versions[i] = &TableVersion{Version: i + 1, LoadedAt: now.Add(-time.Duration(4-i) * 24 * time.Hour)}
+	}
+
+	tests := []struct {
+		name   string
+		policy *RetentionPolicy
+		want   []int
+	}{
+		{"no policy keeps current only", nil, []int{5}},
+		{"unlimited policy keeps all", &RetentionPolicy{}, []int{1, 2, 3, 4, 5}},
+		{"max versions", &RetentionPolicy{MaxVersions: 2}, []int{4, 5}},
+		{"max age", &RetentionPolicy{MaxAgeDays: 2}, []int{3, 4, 5}},
+		{"stricter limit wins", &RetentionPolicy{MaxVersions: 4, MaxAgeDays: 1}, []int{4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pruneVersions(versions, tt.policy, now)
+			var gotVersions []int
+			for _, v := range got {
+				gotVersions = append(gotVersions, v.Version)
+			}
+			if len(gotVersions) != len(tt.want) {
+				t.Fatalf("got versions %v, want %v", gotVersions, tt.want)
+			}
+			for i := range tt.want {
+				if gotVersions[i] != tt.want[i] {
+					t.Fatalf("got versions %v, want %v", gotVersions, tt.want)
+				}
+			}
+		})
+	}
+
+	// The current version is kept even when it is older than the age limit
+	if got := pruneVersions(versions[:1], &RetentionPolicy{MaxAgeDays: 1}, now); len(got) != 1 {
+		t.Errorf("expected the current version to be kept, got %d versions", len(got))
+	}
+}
+
+func TestManagerListAndRestoreVersions(t *testing.T) {
+	manager := NewManager()
+	manager.RegisterLoader(NewCsvLoader())
+	content := "a\n1\n"
+	manager.SetFileReader(func(string) ([]byte, error) { return []byte(content), nil })
+	manager.AddSource(&DataSource{
+		Name:       "src",
+		SourceType: "csv",
+		Config:     map[string]string{"file_path": "a.csv"},
+		Retention:  &RetentionPolicy{MaxVersions: 2},
+	})
+
+	for _, c := range []string{"a\n1\n", "a\n1\n2\n", "a\n1\n2\n3\n"} {
+		content = c
+		manager.InvalidateCache("src")
+		if _, err := manager.LoadData("src"); err != nil {
+			t.Fatalf("LoadData failed: %v", err)
+		}
+	}
+
+	versions := manager.ListVersions("src")
+	if len(versions) != 2 || versions[0].Version != 2 || versions[1].Version != 3 {
+		t.Fatalf("expected versions 2 and 3, got %+v", versions)
+	}
+
+	if got := manager.CurrentVersion("src"); got != 3 {
+		t.Errorf("expected current version 3, got %d", got)
+	}
+
+	table, err := manager.RestoreVersion("src", 2)
+	if err != nil {
+		t.Fatalf("RestoreVersion failed: %v", err)
+	}
+	if got := manager.CurrentVersion("src"); got != 2 {
+		t.Errorf("expected current version 2 after restore, got %d", got)
+	}
+	if table.Length() != 2 {
+		t.Errorf("expected restored version to have 2 rows, got %d", table.Length())
+	}
+	if current, _ := manager.LoadData("src"); current != table {
+		t.Errorf("expected LoadData to return the restored version")
+	}
+
+	if _, err := manager.RestoreVersion("src", 1); !errors.Is(err, errs.ErrSourceUnavailable) {
+		t.Errorf("expected pruned version to be unavailable, got %v", err)
+	}
+}
+
+func TestManagerReloadKeepsDataOnFailure(t *testing.T) {
+	manager := NewManager()
+	manager.RegisterLoader(NewCsvLoader())
+	content, readErr := "a\n1\n", error(nil)
+	manager.SetFileReader(func(string) ([]byte, error) { return []byte(content), readErr })
+	manager.AddSource(&DataSource{Name: "src", SourceType: "csv", Config: map[string]string{"file_path": "a.csv"}})
+	if _, err := manager.LoadData("src"); err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+
+	content = "a\n1\n2\n"
+	reloaded, err := manager.Reload("src")
+	if err != nil || reloaded.Length() != 2 || manager.CurrentVersion("src") != 2 {
+		t.Fatalf("expected a second version with 2 rows, got %v rows, version %d, err %v", reloaded, manager.CurrentVersion("src"), err)
+	}
+
+	readErr = errors.New("file is gone")
+	if _, err := manager.Reload("src"); err == nil {
+		t.Fatal("expected the reload to fail")
+	}
+	if current, _ := manager.LoadData("src"); current != reloaded {
+		t.Errorf("expected a failed reload to keep the loaded data")
+	}
+}
+
+func TestManagerVersionAsOf(t *testing.T) {
+	manager := NewManager()
+	manager.AddSource(&DataSource{Name: "src", SourceType: "csv", Retention: &RetentionPolicy{}})
+	day := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	manager.versions["src"] = []*TableVersion{
+		{Version: 1, LoadedAt: day},
+		{Version: 2, LoadedAt: day.Add(24 * time.Hour)},
+		{Version: 3, LoadedAt: day.Add(48 * time.Hour)},
+	}
+
+	tests := []struct {
+		at   time.Time
+		want int
+	}{
+		{day, 1},
+		{day.Add(23 * time.Hour), 1},
+		{day.Add(24 * time.Hour), 2},
+		{day.Add(72 * time.Hour), 3},
+	}
+	for _, tt := range tests {
+		got, err := manager.VersionAsOf("src", tt.at)
+		if err != nil {
+			t.Fatalf("VersionAsOf(%v) failed: %v", tt.at, err)
+		}
+		if got.Version != tt.want {
+			t.Errorf("VersionAsOf(%v) = version %d, want %d", tt.at, got.Version, tt.want)
+		}
+	}
+
+	if _, err := manager.VersionAsOf("src", day.Add(-time.Hour)); !errors.Is(err, errs.ErrSourceUnavailable) {
+		t.Errorf("expected no version before the first load, got %v", err)
+	}
+	if _, err := manager.VersionAsOf("unknown", day); !errors.Is(err, errs.ErrSourceUnavailable) {
+		t.Errorf("expected no version of an unknown source, got %v", err)
+	}
+}
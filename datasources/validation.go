@@ -0,0 +1,108 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasources
+
+import (
+	"strings"
+
+	"github.com/google/taxinomia/core/columns"
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/expr"
+	"github.com/google/taxinomia/core/tables"
+)
+
+// ValidationResult counts the rows of a loaded source that violate one validation rule.
+type ValidationResult struct {
+	Rule       string
+	Expression string
+	Rows       int    // Rows checked
+	Violations int    // Rows for which the expression is false
+	Errors     int    // Rows for which the expression failed or is not a boolean
+	Err        string // Why the rule could not be checked at all (syntax error, unknown column)
+}
+
+// ValidateRows evaluates the rules against every row of the table. It returns a
+// result per rule, in rule order, and the names of the rules each row violates.
+// A rule without a name is reported under its expression.
+func ValidateRows(table *tables.DataTable, rules []*ValidationRule) ([]ValidationResult, [][]string) {
+	length := table.Length()
+	results := make([]ValidationResult, len(rules))
+	violated := make([][]string, length)
+
+	getColumn := func(colName string, rowIndex uint32) (expr.Value, error) {
+		col := table.GetColumn(colName)
+		if col == nil {
+			return expr.NilValue(), errs.New(errs.ErrUnknownColumn, "column '%s' not found", colName)
+		}
+		return expr.ColumnValue(col, rowIndex)
+	}
+
+	for i, rule := range rules {
+		name := rule.GetName()
+		if name == "" {
+			name = rule.GetExpression()
+		}
+		results[i] = ValidationResult{Rule: name, Expression: rule.GetExpression()}
+
+		compiled, err := expr.Compile(rule.GetExpression())
+		if err != nil {
+			results[i].Err = err.Error()
+			continue
+		}
+		if missing := missingColumns(table, compiled.Columns()); len(missing) > 0 {
+			results[i].Err = "unknown columns: " + strings.Join(missing, ", ")
+			continue
+		}
+
+		bound := compiled.Bind(getColumn)
+		results[i].Rows = length
+		for row := 0; row < length; row++ {
+			val, err := bound.Eval(uint32(row))
+			switch {
+			case err != nil || !val.IsBool():
+				results[i].Errors++
+			case !val.AsBool():
+				results[i].Violations++
+				violated[row] = append(violated[row], name)
+			}
+		}
+	}
+	return results, violated
+}
+
+// missingColumns returns the names that are not columns of the table.
+func missingColumns(table *tables.DataTable, names []string) []string {
+	var missing []string
+	for _, name := range names {
+		if table.GetColumn(name) == nil {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// violationsColumn builds a string column listing, for each row, the rules it violates.
+func violationsColumn(name string, violated [][]string) *columns.StringColumn {
+	col := columns.NewStringColumn(columns.NewColumnDef(name, name, ""))
+	for _, rules := range violated {
+		col.Append(strings.Join(rules, ", "))
+	}
+	col.FinalizeColumn()
+	return col
+}
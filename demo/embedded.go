@@ -0,0 +1,82 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package demo
+
+import (
+	"embed"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/google/taxinomia/datasources"
+)
+
+// The demo reads its configuration, products and user profiles from the directories
+// next to its sources. Copies are embedded so that the demo also runs from a binary
+// moved away from the source tree.
+//
+//go:embed customer_orders.pb data products users
+var embeddedFiles embed.FS
+
+// EmbeddedReaders wraps fileReader and dirReader to fall back on the embedded copies of
+// the demo files when the files cannot be read from disk.
+func EmbeddedReaders(fileReader datasources.FileReader, dirReader datasources.DirReader) (datasources.FileReader, datasources.DirReader) {
+	_, currentFile, _, _ := runtime.Caller(0)
+	demoDir := filepath.Dir(currentFile)
+
+	// embeddedPath returns the path of a demo file in embeddedFiles
+	embeddedPath := func(path string) (string, bool) {
+		rel, err := filepath.Rel(demoDir, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", false
+		}
+		return filepath.ToSlash(rel), true
+	}
+
+	readFile := func(path string) ([]byte, error) {
+		data, err := fileReader(path)
+		if err == nil {
+			return data, nil
+		}
+		if name, ok := embeddedPath(path); ok {
+			if data, embeddedErr := embeddedFiles.ReadFile(name); embeddedErr == nil {
+				return data, nil
+			}
+		}
+		return nil, err
+	}
+	readDir := func(path string) ([]datasources.DirEntry, error) {
+		entries, err := dirReader(path)
+		if err == nil {
+			return entries, nil
+		}
+		if name, ok := embeddedPath(path); ok {
+			if embedded, embeddedErr := fs.ReadDir(embeddedFiles, name); embeddedErr == nil {
+				entries = make([]datasources.DirEntry, len(embedded))
+				for i, e := range embedded {
+					entries[i] = datasources.DirEntry{Name: e.Name(), IsDir: e.IsDir()}
+				}
+				return entries, nil
+			}
+		}
+		return nil, err
+	}
+	return readFile, readDir
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/google/taxinomia/core/server"
 	"github.com/google/taxinomia/core/views"
 	"github.com/google/taxinomia/datasources"
 	"google.golang.org/protobuf/encoding/prototext"
@@ -157,6 +158,15 @@ func (r *ProductRegistry) Get(name string) *Product {
 	return r.products[r.fallback]
 }
 
+// Lookup returns a product by name like Get, as a server.ProductConfig for
+// Server.Handler. It returns nil if neither the product nor the fallback exists.
+func (r *ProductRegistry) Lookup(name string) server.ProductConfig {
+	if product := r.Get(name); product != nil {
+		return product
+	}
+	return nil
+}
+
 // GetAll returns all registered products.
 func (r *ProductRegistry) GetAll() []*Product {
 	result := make([]*Product, 0, len(r.products))
@@ -20,13 +20,17 @@ package demo
 
 import (
 	"fmt"
+	"log"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
+	"github.com/google/taxinomia/core/chaos"
 	"github.com/google/taxinomia/core/models"
 	"github.com/google/taxinomia/core/query"
 	"github.com/google/taxinomia/core/server"
+	"github.com/google/taxinomia/core/tables"
 	"github.com/google/taxinomia/core/views"
 	"github.com/google/taxinomia/datasources"
 )
@@ -113,6 +117,16 @@ func SetupDemoServer(fileReader datasources.FileReader, dirReader datasources.Di
 	dsManager.RegisterLoader(datasources.NewProtoLoader())
 	dsManager.SetFileReader(fileReader)
 
+	// Fault injection for resilience testing (only in binaries built with -tags chaos)
+	faults, err := chaos.FromEnv()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s: %w", chaos.EnvVar, err)
+	}
+	if faults != nil {
+		fmt.Printf("Chaos: injecting faults %s\n", faults)
+		dsManager.SetFaultInjector(faults)
+	}
+
 	// Register Google tables with dsManager for hierarchy lookups
 	// This allows BuildHierarchyLookups to scan them for parent-child relationships
 	dsManager.RegisterTable("google_regions", googleRegionsTable)
@@ -182,10 +196,6 @@ func SetupDemoServer(fileReader datasources.FileReader, dirReader datasources.Di
 	dsManager.AddHierarchyAncestorColumns(googleCellsTable, "google.cell")
 	fmt.Println("=== Hierarchy Ancestor Columns Added ===")
 
-	// Print reports
-	printEntityTypeUsageReport(dataModel)
-	printJoinDiscoveryReport(dataModel)
-
 	// Add system tables (must be after all user tables are added)
 	fmt.Println("\n=== Creating System Tables ===")
 	models.AddSystemTables(dataModel)
@@ -196,24 +206,18 @@ func SetupDemoServer(fileReader datasources.FileReader, dirReader datasources.Di
 	if err != nil {
 		return nil, nil, err
 	}
+	srv.SetFaultInjector(faults)
 
-	// Set up URL resolver for entity type links
-	srv.SetURLResolver(dsManager.ResolveDefaultURL)
+	// Set up the resolvers reading the datasources config, shared with serve. They set
+	// the fuzzy joins too, so the join report is printed after them.
+	SetupDataSourceResolvers(srv, dataModel, dsManager)
+	printEntityTypeUsageReport(dataModel)
+	printJoinDiscoveryReport(dataModel)
 
-	// Set up all URLs resolver for detail panel
-	srv.SetAllURLsResolver(func(entityType, value string) []views.EntityURL {
-		resolved := dsManager.GetAllURLs(entityType, value)
-		if len(resolved) == 0 {
-			return nil
-		}
-		result := make([]views.EntityURL, len(resolved))
-		for i, r := range resolved {
-			result[i] = views.EntityURL{Name: r.Name, URL: r.URL}
-		}
-		return result
-	})
+	// Release the filter masks and groupings of table views nobody looked at for a while
+	srv.StartCacheJanitor(server.DefaultCacheIdleWindow)
 
-	// Set up primary key resolver for table metadata
+	// Replace the primary key resolver of the datasources config for table metadata
 	// First try the datasources config, then programmatic tables, then IsKey columns
 	googleTablePrimaryKeys := map[string]string{
 		"google_regions":  "google.region",
@@ -248,8 +252,27 @@ func SetupDemoServer(fileReader datasources.FileReader, dirReader datasources.Di
 		return ""
 	})
 
-	// Set up entity type description resolver
-	srv.SetEntityTypeDescriptionResolver(dsManager.GetEntityTypeDescription)
+	// Precompute the most common groupings so that their views are fast from the first request
+	hotGroupings := []server.HotGrouping{
+		{Table: "transactions_perf", Columns: []string{"status"}, Priority: 100},
+		{Table: "transactions_perf", Columns: []string{"category_id", "status"}, Priority: 50},
+	}
+	for _, name := range dsManager.GetLoadedSources() {
+		for _, hot := range dsManager.GetSource(name).GetHotGroupings() {
+			hotGroupings = append(hotGroupings, server.HotGrouping{Table: name, Columns: hot.GetColumns(), Priority: int(hot.GetPriority())})
+		}
+	}
+	srv.SetHotGroupings(hotGroupings)
+
+	// Replace the table freshness resolver for the overview page. Tables built in code
+	// (rather than loaded through the datasources manager) date from startup.
+	startedAt := time.Now()
+	srv.SetTableFreshnessResolver(func(tableName string) time.Time {
+		if loadedAt := dsManager.GetLoadTime(tableName); !loadedAt.IsZero() {
+			return loadedAt
+		}
+		return startedAt
+	})
 
 	// Set up hierarchy context builder for the detail panel
 	// Shows ALL hierarchies, not just those containing the primary key entity type.
@@ -760,6 +783,80 @@ func SetupDemoServer(fileReader datasources.FileReader, dirReader datasources.Di
 	return srv, products, nil
 }
 
+// SetupDataSourceResolvers sets up srv to read the datasources config of dsManager:
+// entity type links, descriptions and hierarchies, the metadata, versions, snapshots,
+// readmes and data quality of the sources, and SQL statements on tables outside the
+// data model. Entity types configured with fuzzy_join get fuzzy joins in dataModel.
+func SetupDataSourceResolvers(srv *server.Server, dataModel *models.DataModel, dsManager *datasources.Manager) {
+	// Match string joins approximately for entity types configured with fuzzy_join
+	for _, name := range dsManager.GetEntityTypeNames() {
+		if fuzzy := dsManager.GetEntityType(name).GetFuzzyJoin(); fuzzy != nil {
+			dataModel.SetFuzzyJoin(name, fuzzy.GetSimilarityThreshold())
+		}
+	}
+
+	srv.SetURLResolver(dsManager.ResolveDefaultURL)
+	srv.SetBatchURLResolver(dsManager.ResolveDefaultURLs)
+	srv.SetAllURLsResolver(func(entityType, value string) []views.EntityURL {
+		resolved := dsManager.GetAllURLs(entityType, value)
+		if len(resolved) == 0 {
+			return nil
+		}
+		result := make([]views.EntityURL, len(resolved))
+		for i, r := range resolved {
+			result[i] = views.EntityURL{Name: r.Name, URL: r.URL}
+		}
+		return result
+	})
+	srv.SetPrimaryKeyResolver(dsManager.GetPrimaryKeyEntityType)
+	srv.SetEntityTypeDescriptionResolver(dsManager.GetEntityTypeDescription)
+	srv.SetRowLinkColumnResolver(dsManager.GetRowLinkColumn)
+	srv.SetTableDefaultViewResolver(dsManager.GetDefaultView)
+	srv.SetColumnPresetsResolver(dsManager.GetColumnPresets)
+	srv.SetEntityHierarchiesResolver(dsManager.GetHierarchyLevels)
+	srv.SetTableVersionResolver(dsManager.CurrentVersion)
+	srv.SetTableSnapshotResolver(func(tableName string, at time.Time) (*tables.DataTable, time.Time, error) {
+		version, err := dsManager.VersionAsOf(tableName, at)
+		return version.Table, version.LoadedAt, err
+	})
+	srv.SetTableFreshnessResolver(dsManager.GetLoadTime)
+	srv.SetSQLResolver(dsManager.Query)
+	srv.SetTableReadmeResolver(func(tableName string) string {
+		readme, err := dsManager.GetReadme(tableName)
+		if err != nil {
+			log.Printf("Failed to load readme for %s: %v", tableName, err)
+		}
+		return readme
+	})
+
+	// Report the validation rule results and type coercion failures in the _data_quality table
+	srv.SetDataQualityResolver(func(tableName string) []models.DataQualityRecord {
+		var records []models.DataQualityRecord
+		for _, r := range dsManager.GetValidationResults(tableName) {
+			records = append(records, models.DataQualityRecord{
+				Table:      tableName,
+				Rule:       r.Rule,
+				Expression: r.Expression,
+				Rows:       uint32(r.Rows),
+				Violations: uint32(r.Violations),
+				Errors:     uint32(r.Errors),
+				Error:      r.Err,
+			})
+		}
+		for _, f := range dsManager.GetCoercionFailures(tableName) {
+			records = append(records, models.DataQualityRecord{
+				Table:      tableName,
+				Rule:       "coerce to " + f.Type.String(),
+				Column:     f.Column,
+				Rows:       uint32(f.Rows),
+				Violations: uint32(f.Count),
+				Examples:   f.QuotedExamples(),
+			})
+		}
+		return records
+	})
+}
+
 // printEntityTypeUsageReport prints a comprehensive report of all entity types
 func printEntityTypeUsageReport(dm *models.DataModel) {
 	fmt.Println("\n=== Entity Type Usage Report ===")
@@ -0,0 +1,68 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command taxinomia-flightsql serves the demo tables over Arrow Flight SQL.
+//
+// Connect a Flight SQL client to the printed address, e.g. with the Arrow Flight SQL JDBC
+// driver: jdbc:arrow-flight-sql://localhost:31337?useEncryption=false
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/flight/flightsql"
+	"github.com/google/taxinomia/core/models"
+	"github.com/google/taxinomia/demo"
+	taxflightsql "github.com/google/taxinomia/flightsql"
+)
+
+func main() {
+	host := flag.String("host", "localhost", "hostname to bind to")
+	port := flag.Int("port", 31337, "port to bind to")
+	flag.Parse()
+
+	dataModel := models.NewDataModel()
+	dataModel.AddTable("orders", demo.CreateDemoTable())
+	dataModel.AddTable("regions", demo.CreateRegionsTable())
+	dataModel.AddTable("capitals", demo.CreateCapitalsTable())
+	dataModel.AddTable("items", demo.CreateItemsTable())
+	dataModel.AddTable("events", demo.CreateEventsTable())
+	dataModel.AddTable("metrics", demo.CreateMetricsTable())
+
+	srv, err := taxflightsql.NewServer(dataModel)
+	if err != nil {
+		log.Fatalf("Failed to create Flight SQL server: %v", err)
+	}
+	server := flight.NewServerWithMiddleware(nil)
+	server.RegisterFlightService(flightsql.NewFlightServer(srv))
+	if err := server.Init(net.JoinHostPort(*host, strconv.Itoa(*port))); err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
+	server.SetShutdownOnSignals(os.Interrupt)
+
+	fmt.Printf("Flight SQL server listening on %s\n", server.Addr())
+	if err := server.Serve(); err != nil {
+		log.Fatal(err)
+	}
+}
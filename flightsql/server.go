@@ -0,0 +1,358 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package flightsql serves the tables of a data model over Arrow Flight SQL, so that BI
+// tools with Flight SQL drivers can query them directly. Statements are translated to the
+// filter, group and aggregate pipeline by the sqlquery package, and their results are
+// streamed back as Arrow record batches.
+//
+// The package is a separate module so that the Arrow and gRPC dependencies stay out of
+// the core module.
+package flightsql
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/flight/flightsql"
+	"github.com/apache/arrow-go/v18/arrow/flight/flightsql/schema_ref"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/google/taxinomia/core/errs"
+	"github.com/google/taxinomia/core/models"
+	"github.com/google/taxinomia/core/sqlquery"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BatchSize is the number of rows per streamed record batch
+const BatchSize = 4096
+
+// tableType is the type reported for all tables
+const tableType = "TABLE"
+
+// Server is a read-only Flight SQL server over the tables of a data model.
+// Statements and prepared statements are stateless: their handle is the query itself.
+type Server struct {
+	flightsql.BaseServer
+	dataModel *models.DataModel
+}
+
+// NewServer creates a Flight SQL server for the tables of the data model.
+// Register it with flightsql.NewFlightServer.
+func NewServer(dataModel *models.DataModel) (*Server, error) {
+	s := &Server{dataModel: dataModel}
+	s.Alloc = memory.DefaultAllocator
+	for id, value := range map[flightsql.SqlInfo]any{
+		flightsql.SqlInfoFlightSqlServerName:     "taxinomia",
+		flightsql.SqlInfoFlightSqlServerVersion:  "1",
+		flightsql.SqlInfoFlightSqlServerReadOnly: true,
+	} {
+		if err := s.RegisterSqlInfo(id, value); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// GetFlightInfoStatement checks a query and returns a ticket to execute it
+func (s *Server) GetFlightInfoStatement(_ context.Context, cmd flightsql.StatementQuery, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	plan, err := sqlquery.Prepare(s.dataModel, cmd.GetQuery())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	ticket, err := flightsql.CreateStatementQueryTicket([]byte(cmd.GetQuery()))
+	if err != nil {
+		return nil, err
+	}
+	return &flight.FlightInfo{
+		Endpoint:         []*flight.FlightEndpoint{{Ticket: &flight.Ticket{Ticket: ticket}}},
+		FlightDescriptor: desc,
+		Schema:           flight.SerializeSchema(arrowSchema(plan.Columns), s.Alloc),
+		TotalRecords:     -1,
+		TotalBytes:       -1,
+	}, nil
+}
+
+// GetSchemaStatement returns the schema of a query's result without executing it
+func (s *Server) GetSchemaStatement(_ context.Context, cmd flightsql.StatementQuery, _ *flight.FlightDescriptor) (*flight.SchemaResult, error) {
+	plan, err := sqlquery.Prepare(s.dataModel, cmd.GetQuery())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &flight.SchemaResult{Schema: flight.SerializeSchema(arrowSchema(plan.Columns), s.Alloc)}, nil
+}
+
+// DoGetStatement executes the query of a ticket and streams its result
+func (s *Server) DoGetStatement(ctx context.Context, cmd flightsql.StatementQueryTicket) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	return s.execute(ctx, string(cmd.GetStatementHandle()))
+}
+
+// CreatePreparedStatement checks a query and returns it as the statement handle
+func (s *Server) CreatePreparedStatement(_ context.Context, req flightsql.ActionCreatePreparedStatementRequest) (flightsql.ActionCreatePreparedStatementResult, error) {
+	plan, err := sqlquery.Prepare(s.dataModel, req.GetQuery())
+	if err != nil {
+		return flightsql.ActionCreatePreparedStatementResult{}, statusError(err)
+	}
+	return flightsql.ActionCreatePreparedStatementResult{
+		Handle:        []byte(req.GetQuery()),
+		DatasetSchema: arrowSchema(plan.Columns),
+	}, nil
+}
+
+// ClosePreparedStatement releases nothing, since prepared statements hold no state
+func (s *Server) ClosePreparedStatement(context.Context, flightsql.ActionClosePreparedStatementRequest) error {
+	return nil
+}
+
+// GetFlightInfoPreparedStatement returns a ticket to execute a prepared statement
+func (s *Server) GetFlightInfoPreparedStatement(_ context.Context, cmd flightsql.PreparedStatementQuery, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	plan, err := sqlquery.Prepare(s.dataModel, string(cmd.GetPreparedStatementHandle()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &flight.FlightInfo{
+		Endpoint:         []*flight.FlightEndpoint{{Ticket: &flight.Ticket{Ticket: desc.Cmd}}},
+		FlightDescriptor: desc,
+		Schema:           flight.SerializeSchema(arrowSchema(plan.Columns), s.Alloc),
+		TotalRecords:     -1,
+		TotalBytes:       -1,
+	}, nil
+}
+
+// DoGetPreparedStatement executes a prepared statement and streams its result
+func (s *Server) DoGetPreparedStatement(ctx context.Context, cmd flightsql.PreparedStatementQuery) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	return s.execute(ctx, string(cmd.GetPreparedStatementHandle()))
+}
+
+// execute runs a query and streams its rows in batches of BatchSize
+func (s *Server) execute(ctx context.Context, query string) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	plan, err := sqlquery.Prepare(s.dataModel, query)
+	if err != nil {
+		return nil, nil, statusError(err)
+	}
+	result, err := plan.Execute()
+	if err != nil {
+		return nil, nil, statusError(err)
+	}
+
+	schema := arrowSchema(result.Columns)
+	ch := make(chan flight.StreamChunk)
+	go func() {
+		defer close(ch)
+		for start := 0; start < len(result.Rows); start += BatchSize {
+			end := min(start+BatchSize, len(result.Rows))
+			batch := recordBatch(s.Alloc, schema, result.Columns, result.Rows[start:end])
+			select {
+			case ch <- flight.StreamChunk{Data: batch}:
+			case <-ctx.Done():
+				batch.Release()
+				return
+			}
+		}
+	}()
+	return schema, ch, nil
+}
+
+// GetFlightInfoCatalogs returns a ticket to list the catalogs (there are none)
+func (s *Server) GetFlightInfoCatalogs(_ context.Context, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	return s.flightInfoForCommand(desc, schema_ref.Catalogs), nil
+}
+
+// DoGetCatalogs streams an empty list of catalogs
+func (s *Server) DoGetCatalogs(context.Context) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	return singleBatch(s.Alloc, schema_ref.Catalogs, nil)
+}
+
+// GetFlightInfoSchemas returns a ticket to list the database schemas (there are none)
+func (s *Server) GetFlightInfoSchemas(_ context.Context, _ flightsql.GetDBSchemas, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	return s.flightInfoForCommand(desc, schema_ref.DBSchemas), nil
+}
+
+// DoGetDBSchemas streams an empty list of database schemas
+func (s *Server) DoGetDBSchemas(context.Context, flightsql.GetDBSchemas) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	return singleBatch(s.Alloc, schema_ref.DBSchemas, nil)
+}
+
+// GetFlightInfoTableTypes returns a ticket to list the table types
+func (s *Server) GetFlightInfoTableTypes(_ context.Context, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	return s.flightInfoForCommand(desc, schema_ref.TableTypes), nil
+}
+
+// DoGetTableTypes streams the single table type
+func (s *Server) DoGetTableTypes(context.Context) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	return singleBatch(s.Alloc, schema_ref.TableTypes, [][]any{{tableType}})
+}
+
+// GetFlightInfoTables returns a ticket to list the tables
+func (s *Server) GetFlightInfoTables(_ context.Context, cmd flightsql.GetTables, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	schema := schema_ref.Tables
+	if cmd.GetIncludeSchema() {
+		schema = schema_ref.TablesWithIncludedSchema
+	}
+	return s.flightInfoForCommand(desc, schema), nil
+}
+
+// DoGetTables streams the tables matching the request's name pattern and types
+func (s *Server) DoGetTables(_ context.Context, cmd flightsql.GetTables) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	schema := schema_ref.Tables
+	if cmd.GetIncludeSchema() {
+		schema = schema_ref.TablesWithIncludedSchema
+	}
+	if types := cmd.GetTableTypes(); len(types) > 0 && !containsFold(types, tableType) {
+		return singleBatch(s.Alloc, schema, nil)
+	}
+
+	allTables := s.dataModel.GetAllTables()
+	names := make([]string, 0, len(allTables))
+	for name := range allTables {
+		if pattern := cmd.GetTableNameFilterPattern(); pattern == nil || matchesPattern(name, *pattern) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var rows [][]any
+	for _, name := range names {
+		row := []any{nil, nil, name, tableType}
+		if cmd.GetIncludeSchema() {
+			row = append(row, flight.SerializeSchema(arrowSchema(sqlquery.TableColumns(allTables[name])), s.Alloc))
+		}
+		rows = append(rows, row)
+	}
+	return singleBatch(s.Alloc, schema, rows)
+}
+
+func (s *Server) flightInfoForCommand(desc *flight.FlightDescriptor, schema *arrow.Schema) *flight.FlightInfo {
+	return &flight.FlightInfo{
+		Endpoint:         []*flight.FlightEndpoint{{Ticket: &flight.Ticket{Ticket: desc.Cmd}}},
+		FlightDescriptor: desc,
+		Schema:           flight.SerializeSchema(schema, s.Alloc),
+		TotalRecords:     -1,
+		TotalBytes:       -1,
+	}
+}
+
+// statusError converts an error to a gRPC status with a code matching its kind
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, errs.ErrUnknownTable), errors.Is(err, errs.ErrUnknownColumn):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, errs.ErrInvalidQuery), errors.Is(err, errs.ErrTypeMismatch):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// matchesPattern matches a name against a SQL LIKE pattern ('%' any text, '_' one character)
+func matchesPattern(name, pattern string) bool {
+	glob := strings.NewReplacer("*", `\*`, "?", `\?`, "[", `\[`, `\`, `\\`, "%", "*", "_", "?").Replace(pattern)
+	ok, err := path.Match(glob, name)
+	return err == nil && ok
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// arrowType returns the Arrow type of a result column kind
+func arrowType(kind sqlquery.Kind) arrow.DataType {
+	switch kind {
+	case sqlquery.KindInt64:
+		return arrow.PrimitiveTypes.Int64
+	case sqlquery.KindFloat64:
+		return arrow.PrimitiveTypes.Float64
+	case sqlquery.KindBool:
+		return arrow.FixedWidthTypes.Boolean
+	case sqlquery.KindTimestamp:
+		return &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+	}
+	return arrow.BinaryTypes.String
+}
+
+// arrowSchema returns the Arrow schema of result columns. All columns are nullable.
+func arrowSchema(cols []sqlquery.Column) *arrow.Schema {
+	fields := make([]arrow.Field, len(cols))
+	for i, col := range cols {
+		fields[i] = arrow.Field{Name: col.Name, Type: arrowType(col.Kind), Nullable: true}
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+// recordBatch builds a record batch from result rows
+func recordBatch(mem memory.Allocator, schema *arrow.Schema, cols []sqlquery.Column, rows [][]any) arrow.RecordBatch {
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+	for c := range cols {
+		field := builder.Field(c)
+		for _, row := range rows {
+			appendValue(field, row[c])
+		}
+	}
+	return builder.NewRecordBatch()
+}
+
+// singleBatch streams rows with a schema of string, binary and nil values as one batch
+func singleBatch(mem memory.Allocator, schema *arrow.Schema, rows [][]any) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+	for _, row := range rows {
+		for c, value := range row {
+			appendValue(builder.Field(c), value)
+		}
+	}
+	ch := make(chan flight.StreamChunk, 1)
+	ch <- flight.StreamChunk{Data: builder.NewRecordBatch()}
+	close(ch)
+	return schema, ch, nil
+}
+
+// appendValue appends a value of the Go type matching the builder, or a null for nil
+func appendValue(builder array.Builder, value any) {
+	if value == nil {
+		builder.AppendNull()
+		return
+	}
+	switch b := builder.(type) {
+	case *array.StringBuilder:
+		b.Append(value.(string))
+	case *array.BinaryBuilder:
+		b.Append(value.([]byte))
+	case *array.Int64Builder:
+		b.Append(value.(int64))
+	case *array.Float64Builder:
+		b.Append(value.(float64))
+	case *array.BooleanBuilder:
+		b.Append(value.(bool))
+	case *array.TimestampBuilder:
+		b.Append(arrow.Timestamp(value.(time.Time).UnixMicro()))
+	default:
+		builder.AppendNull()
+	}
+}
@@ -0,0 +1,154 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright 2024 The Taxinomia Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flightsql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/flight/flightsql"
+	"github.com/google/taxinomia/core/testsupport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// startServer serves the test data model over Flight SQL and returns a connected client
+func startServer(t *testing.T) *flightsql.Client {
+	t.Helper()
+	srv, err := NewServer(testsupport.NewDataModel())
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	server := flight.NewServerWithMiddleware(nil)
+	server.RegisterFlightService(flightsql.NewFlightServer(srv))
+	if err := server.Init("127.0.0.1:0"); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	go server.Serve()
+	t.Cleanup(server.Shutdown)
+
+	client, err := flightsql.NewClient(server.Addr().String(), nil, nil, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// fetch reads all record batches of the first endpoint of a flight
+func fetch(t *testing.T, client *flightsql.Client, info *flight.FlightInfo) (*arrow.Schema, []arrow.RecordBatch) {
+	t.Helper()
+	reader, err := client.DoGet(context.Background(), info.Endpoint[0].Ticket)
+	if err != nil {
+		t.Fatalf("DoGet: %v", err)
+	}
+	defer reader.Release()
+	var batches []arrow.RecordBatch
+	for reader.Next() {
+		batch := reader.RecordBatch()
+		batch.Retain()
+		batches = append(batches, batch)
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return reader.Schema(), batches
+}
+
+func TestExecuteStatement(t *testing.T) {
+	client := startServer(t)
+	ctx := context.Background()
+
+	info, err := client.Execute(ctx, "SELECT region, SUM(amount) AS total, COUNT(*) AS orders FROM orders GROUP BY region ORDER BY total DESC")
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	schema, batches := fetch(t, client, info)
+	if got := schema.String(); got != "schema:\n  fields: 3\n    - region: type=utf8, nullable\n    - total: type=float64, nullable\n    - orders: type=int64, nullable" {
+		t.Errorf("unexpected schema:\n%s", got)
+	}
+	if len(batches) != 1 || batches[0].NumRows() != 3 {
+		t.Fatalf("expected one batch of 3 rows, got %d batches", len(batches))
+	}
+	regions := batches[0].Column(0).(*array.String)
+	totals := batches[0].Column(1).(*array.Float64)
+	counts := batches[0].Column(2).(*array.Int64)
+	if regions.Value(0) != "north" || totals.Value(0) != 400 || counts.Value(0) != 3 {
+		t.Errorf("expected north with 400 over 3 orders first, got %s with %v over %d",
+			regions.Value(0), totals.Value(0), counts.Value(0))
+	}
+
+	_, err = client.Execute(ctx, "SELECT region FROM customers")
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown table, got %v", err)
+	}
+	_, err = client.Execute(ctx, "DELETE FROM orders")
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an unsupported statement, got %v", err)
+	}
+}
+
+func TestPreparedStatement(t *testing.T) {
+	client := startServer(t)
+	ctx := context.Background()
+
+	prepared, err := client.Prepare(ctx, "SELECT order_id FROM orders WHERE status = 'pending' ORDER BY order_id")
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	defer prepared.Close(ctx)
+	if got := prepared.DatasetSchema().Field(0).Name; got != "order_id" {
+		t.Errorf("expected the dataset schema to have order_id, got %s", got)
+	}
+	info, err := prepared.Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	_, batches := fetch(t, client, info)
+	ids := batches[0].Column(0).(*array.String)
+	if ids.Len() != 2 || ids.Value(0) != "o2" || ids.Value(1) != "o6" {
+		t.Errorf("expected pending orders o2 and o6, got %v", ids)
+	}
+}
+
+func TestGetTables(t *testing.T) {
+	client := startServer(t)
+	pattern := "reg%"
+	info, err := client.GetTables(context.Background(), &flightsql.GetTablesOpts{TableNameFilterPattern: &pattern, IncludeSchema: true})
+	if err != nil {
+		t.Fatalf("GetTables: %v", err)
+	}
+	_, batches := fetch(t, client, info)
+	names := batches[0].Column(2).(*array.String)
+	if names.Len() != 1 || names.Value(0) != "regions" {
+		t.Fatalf("expected only the regions table, got %v", names)
+	}
+	schema, err := flight.DeserializeSchema(batches[0].Column(4).(*array.Binary).Value(0), nil)
+	if err != nil {
+		t.Fatalf("DeserializeSchema: %v", err)
+	}
+	if schema.NumFields() != 3 || schema.Field(1).Name != "population" || schema.Field(1).Type.ID() != arrow.INT64 {
+		t.Errorf("unexpected regions schema: %s", schema)
+	}
+}
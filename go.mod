@@ -4,9 +4,9 @@ go 1.23
 
 toolchain go1.24.12
 
-require github.com/google/safehtml v0.1.0
-
 require (
-	golang.org/x/text v0.3.8 // indirect
-	google.golang.org/protobuf v1.36.11 // indirect
+	github.com/google/safehtml v0.1.0
+	google.golang.org/protobuf v1.36.11
 )
+
+require golang.org/x/text v0.3.8 // indirect
@@ -22,7 +22,6 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"strings"
 
 	"github.com/google/taxinomia/demo"
 )
@@ -39,35 +38,7 @@ func main() {
 	}
 
 	// Handle all requests and route based on product path
-	// URL format: /{product}/ or /{product}/table
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		path := r.URL.Path
-
-		// Parse product name and action from path
-		productName, action := parseProductPath(path)
-
-		// Redirect root to default product
-		if productName == "" {
-			http.Redirect(w, r, "/default/", http.StatusFound)
-			return
-		}
-
-		product := products.Get(productName)
-		if product == nil {
-			http.NotFound(w, r)
-			return
-		}
-
-		switch action {
-		case "table":
-			result := srv.HandleTableRequest(w, r.URL, product, w.Header().Set)
-			if result != nil {
-				http.Error(w, result.Message, result.StatusCode)
-			}
-		default:
-			srv.HandleLandingRequest(w, r.URL, product, w.Header().Set)
-		}
-	})
+	http.Handle("/", srv.Handler("default", products.Lookup))
 
 	fmt.Printf("\nServer starting on http://%s\n", serverAddress)
 	fmt.Printf("Products available:\n")
@@ -76,32 +47,3 @@ func main() {
 	}
 	log.Fatal(http.ListenAndServe(serverAddress, nil))
 }
-
-// parseProductPath extracts the product name and action from a URL path.
-// URL format: /{product}/ or /{product}/table
-// Returns (productName, action) where action is "landing" or "table".
-// Returns empty productName if path is "/" to trigger redirect.
-func parseProductPath(path string) (string, string) {
-	// Remove leading slash and split
-	path = strings.TrimPrefix(path, "/")
-	parts := strings.SplitN(path, "/", 2)
-
-	// Root path - return empty to trigger redirect
-	if len(parts) == 0 || parts[0] == "" {
-		return "", "landing"
-	}
-
-	// First part is always the product name
-	productName := parts[0]
-	action := "landing"
-
-	if len(parts) > 1 && parts[1] != "" {
-		// Remove trailing slash and check for action
-		secondPart := strings.TrimSuffix(parts[1], "/")
-		if secondPart == "table" {
-			action = "table"
-		}
-	}
-
-	return productName, action
-}